@@ -6,6 +6,7 @@ package main
 
 import (
 	"flag"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,11 +18,30 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" { // headless: no display needed
+		serve(os.Args[2:])
+		return
+	}
 	gimain.Main(func() {
 		mainrun()
 	})
 }
 
+// serve runs gide serve with given args: gide serve [-addr host:port] [-edit] [path]
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to serve on -- keep it on localhost and use ssh port forwarding to reach it from another box")
+	edit := fs.Bool("edit", false, "allow saving edits to existing files -- read-only otherwise")
+	fs.Parse(args)
+	path := "."
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+	if err := gide.Serve(path, *addr, *edit); err != nil {
+		log.Fatalln(err)
+	}
+}
+
 func mainrun() {
 	oswin.TheApp.SetName("gide")
 	oswin.TheApp.SetAbout(`<code>Gide</code> is a graphical-interface (gi) integrated-development-environment (ide) written in the <b>GoGi</b> graphical interface system, within the <b>GoKi</b> tree framework.  See <a href="https://github.com/goki/gide/gide">Gide on GitHub</a> and <a href="https://github.com/goki/gide/wiki">Gide wiki</a> for documentation.<br>
@@ -33,18 +53,22 @@ Gide is based on "projects" which are just directories containing files<br>
 	// 	fmt.Printf("Doing final Quit cleanup here..\n")
 	// })
 
-	gide.InitPrefs()
-
 	var path string
 	var proj string
+	var file string
+	var kiosk string
+	var uitest string
 
 	// process command args
 	if len(os.Args) > 1 {
 		flag.StringVar(&path, "path", "", "path to open -- can be to a directory or a filename within the directory")
 		flag.StringVar(&proj, "proj", "", "project file to open -- typically has .gide extension")
+		flag.StringVar(&file, "file", "", "single file to open in a lightweight window, without a file browser or project")
+		flag.StringVar(&kiosk, "kiosk", "", "kiosk / classroom mode configuration file -- locks the preferences and opens a fresh copy of its project template")
+		flag.StringVar(&uitest, "uitest", "", "UI test script to run on the project opened, exiting with status 1 if it fails -- see gide.RunUIScript")
 		// todo: other args?
 		flag.Parse()
-		if path == "" && proj == "" {
+		if path == "" && proj == "" && file == "" {
 			if flag.NArg() > 0 {
 				ext := strings.ToLower(filepath.Ext(flag.Arg(0)))
 				if ext == ".gide" {
@@ -56,6 +80,18 @@ Gide is based on "projects" which are just directories containing files<br>
 		}
 	}
 
+	if err := gide.InitKiosk(kiosk); err != nil {
+		log.Println(err)
+	}
+	gide.InitPrefs()
+	if gide.Kiosk != nil { // only the session project
+		var err error
+		if path, err = gide.KioskSession(); err != nil {
+			log.Println(err)
+		}
+		proj, file = "", ""
+	}
+
 	recv := gi.Node2DBase{}
 	recv.InitName(&recv, "gide_dummy")
 
@@ -71,15 +107,46 @@ Gide is based on "projects" which are just directories containing files<br>
 		}
 	})
 
+	var ge *gide.Gide
 	if proj != "" {
 		proj, _ = filepath.Abs(proj)
-		gide.OpenGideProj(proj)
+		_, ge = gide.OpenGideProj(proj)
+	} else if file != "" {
+		file, _ = filepath.Abs(file)
+		_, ge = gide.NewGideFile(file)
+	} else if path != "" {
+		path, _ = filepath.Abs(path)
+		_, ge = gide.NewGideProjPath(path)
 	} else {
-		if path != "" {
-			path, _ = filepath.Abs(path)
+		gide.WelcomeWindow()
+	}
+
+	uierr := make(chan error, 1) // the result of the ui test, once it is done
+	if uitest != "" {
+		if ge == nil {
+			log.Fatalln("gide: -uitest needs a project, path or file to open")
 		}
-		gide.NewGideProjPath(path)
+		go func() {
+			err := gide.RunUIScript(ge, uitest)
+			if err != nil {
+				log.Println(err)
+			}
+			uierr <- err
+			oswin.TheApp.Quit()
+		}()
 	}
-	// above NewGideProj calls will have added to WinWait..
+	// above NewGideProj / Welcome calls will have added to WinWait..
 	gi.WinWait.Wait()
+	gide.KioskEnd()
+	if uitest != "" {
+		select {
+		case err := <-uierr:
+			if err != nil {
+				os.Exit(1)
+			}
+		default:
+			log.Println("gide: the ui test did not finish before the windows were closed")
+			os.Exit(1)
+		}
+	}
 }
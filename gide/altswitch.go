@@ -0,0 +1,94 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/gi/giv"
+)
+
+// AltPair is the current and previous of something that is switched
+// between, e.g., the focused panel, by name -- for switching back and forth
+// between the last two, as with Alt-Tab
+type AltPair [2]string
+
+// Touch records that given one is now the current one, the current one
+// becoming the previous one if different
+func (ap *AltPair) Touch(nm string) {
+	if nm == "" || nm == ap[0] {
+		return
+	}
+	ap[1], ap[0] = ap[0], nm
+}
+
+// Alt returns the previous one, or "" if there is none
+func (ap *AltPair) Alt() string {
+	return ap[1]
+}
+
+// OpenNodeTouch moves the open node viewed in given view to the top of the
+// open nodes, so that they stay in order of use as views are switched --
+// called when the active view changes
+func (ge *Gide) OpenNodeTouch(tv *giv.TextView) {
+	if tv == nil {
+		return
+	}
+	if ond, _, ok := ge.OpenNodeForTextView(tv); ok {
+		ge.OpenNodes.Add(ond)
+	}
+}
+
+// BufAltToggle switches the active view to the most recently used open
+// file other than the one it is viewing -- doing it again switches back
+func (ge *Gide) BufAltToggle() {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
+	}
+	ge.OpenNodes.DeleteDeleted()
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil && ond.Buf != tv.Buf {
+			ge.ViewFileNode(tv, ge.ActiveTextViewIdx, ond)
+			return
+		}
+	}
+	ge.SetStatus("No other open file to switch to")
+}
+
+// PanelTouch records that given panel has the focus, for PanelAltToggle
+func (ge *Gide) PanelTouch(panel int) {
+	sv := ge.SplitView()
+	if sv == nil || panel < 0 || panel >= len(sv.Kids) {
+		return
+	}
+	ge.panelAlt.Touch(sv.Kids[panel].Name())
+	ge.AutoHideSync(panel)
+}
+
+// PanelAltToggle moves the focus to the panel that had it before the
+// current one -- doing it again moves it back
+func (ge *Gide) PanelAltToggle() {
+	sv := ge.SplitView()
+	if sv == nil {
+		return
+	}
+	ge.PanelTouch(ge.CurPanel())
+	nm := ge.panelAlt.Alt()
+	if nm == "" {
+		ge.SetStatus("No other panel to go back to")
+		return
+	}
+	idx := -1
+	for i, k := range sv.Kids {
+		if k.Name() == nm {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 || sv.Splits[idx] <= 0.01 {
+		ge.SetStatus("The last panel is closed: " + nm)
+		return
+	}
+	ge.FocusOnPanel(idx)
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import "testing"
+
+func TestAltPair(t *testing.T) {
+	var ap AltPair
+	if ap.Alt() != "" {
+		t.Errorf("empty: alt %q", ap.Alt())
+	}
+	ap.Touch("filetree")
+	if ap.Alt() != "" {
+		t.Errorf("one: alt %q", ap.Alt())
+	}
+	ap.Touch("textview-0")
+	ap.Touch("textview-0") // same one again: no change
+	ap.Touch("")
+	if ap.Alt() != "filetree" {
+		t.Errorf("two: alt %q", ap.Alt())
+	}
+	ap.Touch(ap.Alt()) // toggle
+	if ap[0] != "filetree" || ap.Alt() != "textview-0" {
+		t.Errorf("toggle: %v", ap)
+	}
+	ap.Touch("main-tabs")
+	if ap.Alt() != "filetree" {
+		t.Errorf("three: alt %q", ap.Alt())
+	}
+}
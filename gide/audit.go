@@ -0,0 +1,402 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// AuditDirName is the name of the directory in the GoGi prefs directory
+// where the audit logs of projects are stored
+var AuditDirName = "gide_audit"
+
+// AuditMaxEntries is the maximum number of entries kept in the audit log of
+// a project -- the oldest ones are removed beyond that
+var AuditMaxEntries = 10000
+
+// AuditEntry is one write to a file that gide made, in the audit log
+type AuditEntry struct {
+	Time   time.Time `desc:"when the file was written"`
+	Kind   string    `desc:"what wrote the file: save, save as, gorename, replace, hex, scratch save as"`
+	Path   string    `desc:"path of the file, relative to the project root"`
+	Before int64     `desc:"size of the file before it was written, in bytes -- 0 if it did not exist, or for a save as"`
+	After  int64     `desc:"size of the file after it was written, in bytes"`
+	Detail string    `desc:"the changes to the buffer that the write includes, if gide made them, e.g., formatted, replace"`
+}
+
+// Delta returns the change in the size of the file, in bytes
+func (ae *AuditEntry) Delta() int64 {
+	return ae.After - ae.Before
+}
+
+// AppendAuditLog appends given entry to the audit log in given file, which
+// has one JSON entry per line
+func AppendAuditLog(fnm string, ae *AuditEntry) error {
+	if err := os.MkdirAll(filepath.Dir(fnm), 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(ae)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(fnm, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ReadAuditLog returns the entries in the audit log in given file, oldest
+// first -- lines that can not be read are skipped
+func ReadAuditLog(fnm string) ([]AuditEntry, error) {
+	b, err := ioutil.ReadFile(fnm)
+	if err != nil {
+		return nil, err
+	}
+	var aes []AuditEntry
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var ae AuditEntry
+		if json.Unmarshal(sc.Bytes(), &ae) == nil {
+			aes = append(aes, ae)
+		}
+	}
+	return aes, sc.Err()
+}
+
+// TrimAuditLog removes the oldest entries in the audit log in given file
+// beyond max, given its current entries -- returns the entries kept
+func TrimAuditLog(fnm string, aes []AuditEntry, max int) ([]AuditEntry, error) {
+	if max <= 0 || len(aes) <= max {
+		return aes, nil
+	}
+	aes = aes[len(aes)-max:]
+	var buf bytes.Buffer
+	for i := range aes {
+		b, err := json.Marshal(&aes[i])
+		if err != nil {
+			return aes, err
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	return aes, ioutil.WriteFile(fnm, buf.Bytes(), 0644)
+}
+
+// DiffFilePaths returns the paths of the files changed in given unified
+// diff, from the --- lines of its file headers
+func DiffFilePaths(dif []byte) []string {
+	var fps []string
+	lns := strings.Split(string(dif), "\n")
+	for i, ln := range lns {
+		if !strings.HasPrefix(ln, "--- ") || i+1 >= len(lns) || !strings.HasPrefix(lns[i+1], "+++ ") {
+			continue
+		}
+		fp := strings.TrimPrefix(ln, "--- ")
+		if ti := strings.Index(fp, "\t"); ti >= 0 {
+			fp = fp[:ti]
+		}
+		fp = strings.TrimSpace(fp)
+		if fp == "" || fp == "/dev/null" {
+			continue
+		}
+		fps = append(fps, fp)
+	}
+	return fps
+}
+
+// AuditNotes returns given notes with note added, unless it is already there
+func AuditNotes(notes []string, note string) []string {
+	for _, n := range notes {
+		if n == note {
+			return notes
+		}
+	}
+	return append(notes, note)
+}
+
+// fileSize returns the size of given file, or 0 if it does not exist
+func fileSize(fpath string) int64 {
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
+// AuditState is the state of an open file buffer for the audit log
+type AuditState struct {
+	Path  string   `desc:"file the buffer was last opened from or saved to"`
+	Size  int64    `desc:"size of the file when the buffer was last opened or saved"`
+	Notes []string `desc:"changes gide made to the buffer since then, e.g., formatted, replace"`
+}
+
+// AuditLogFile returns the file that the audit log of the project is stored
+// in, by the hash of its root path
+func (ge *Gide) AuditLogFile() string {
+	h := sha256.Sum256([]byte(ge.ProjRoot))
+	return filepath.Join(oswin.TheApp.AppPrefsDir(), AuditDirName, hex.EncodeToString(h[:8])+".jsonl")
+}
+
+// AuditWrite records a write to given file of given kind in the audit log
+// of the project, given its size before the write
+func (ge *Gide) AuditWrite(kind, fpath string, before int64, detail string) {
+	if ge.ProjRoot == "" {
+		return
+	}
+	rp, err := filepath.Rel(string(ge.ProjRoot), fpath)
+	if err != nil {
+		rp = fpath
+	}
+	ae := &AuditEntry{Time: time.Now(), Kind: kind, Path: rp, Before: before, After: fileSize(fpath), Detail: detail}
+	AppendAuditLog(ge.AuditLogFile(), ae)
+}
+
+// AuditOpen notes the size of the file of given buffer, just opened from
+// it, for the audit log
+func (ge *Gide) AuditOpen(tb *giv.TextBuf) {
+	if ge.audits == nil {
+		ge.audits = make(map[*giv.TextBuf]*AuditState)
+	}
+	if tb.Filename == "" {
+		return
+	}
+	fpath := string(tb.Filename)
+	ge.audits[tb] = &AuditState{Path: fpath, Size: fileSize(fpath)}
+}
+
+// AuditNote notes a change that gide made to given buffer, e.g., formatting
+// or a replace, to record in the audit log when it is saved
+func (ge *Gide) AuditNote(tb *giv.TextBuf, note string) {
+	as := ge.audits[tb]
+	if as == nil {
+		return
+	}
+	as.Notes = AuditNotes(as.Notes, note)
+}
+
+// AuditSaved records the save of given buffer, just saved, in the audit log
+// of the project, with the changes gide made to it since it was opened or
+// last saved
+func (ge *Gide) AuditSaved(tb *giv.TextBuf) {
+	if tb.Filename == "" {
+		return
+	}
+	if ge.audits == nil {
+		ge.audits = make(map[*giv.TextBuf]*AuditState)
+	}
+	fpath := string(tb.Filename)
+	as := ge.audits[tb]
+	kind := "save"
+	var before int64
+	switch {
+	case as == nil:
+		as = &AuditState{}
+		ge.audits[tb] = as
+	case as.Path != fpath:
+		kind = "save as"
+	default:
+		before = as.Size
+	}
+	ge.AuditWrite(kind, fpath, before, strings.Join(as.Notes, ", "))
+	as.Path = fpath
+	as.Size = fileSize(fpath)
+	as.Notes = nil
+}
+
+// AuditLog shows the audit log of the project in the Audit panel: every
+// write to a file that gide made -- saves, save as, renames, with the
+// formatting and replaces in them -- with its time and change in size,
+// newest first
+func (ge *Gide) AuditLog() {
+	abuf, _ := ge.FindOrMakeCmdBuf("Audit", true)
+	avi, _ := ge.FindOrMakeMainTab("Audit", KiT_AuditView, true) // sel
+	av := avi.Embed(KiT_AuditView).(*AuditView)
+	av.UpdateView(ge)
+	atv := av.TextView()
+	atv.SetInactive()
+	atv.SetBuf(abuf)
+	av.Refresh()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    AuditView
+
+// AuditView is a widget that shows the audit log of a project, with links
+// to the files that were written
+type AuditView struct {
+	gi.Layout
+	Gide    *Gide        `json:"-" xml:"-" desc:"parent gide project"`
+	Entries []AuditEntry `json:"-" xml:"-" desc:"entries in the log as of the last refresh, oldest first"`
+	Filter  string       `desc:"only entries whose path contains this are shown"`
+}
+
+var KiT_AuditView = kit.Types.AddType(&AuditView{}, AuditViewProps)
+
+// Refresh reads the audit log again, and shows it
+func (av *AuditView) Refresh() {
+	fnm := av.Gide.AuditLogFile()
+	aes, _ := ReadAuditLog(fnm)
+	aes, _ = TrimAuditLog(fnm, aes, AuditMaxEntries)
+	av.Entries = aes
+	av.ShowResults()
+}
+
+// ShowResults renders the entries that pass the filter into the results
+// buffer, newest first
+func (av *AuditView) ShowResults() {
+	tbuf, _ := av.Gide.FindOrMakeCmdBuf("Audit", true)
+	root := string(av.Gide.ProjRoot)
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	n := 0
+	for i := len(av.Entries) - 1; i >= 0; i-- {
+		ae := &av.Entries[i]
+		if av.Filter != "" && !strings.Contains(ae.Path, av.Filter) {
+			continue
+		}
+		n++
+		lstr := fmt.Sprintf("%v  %v  %v  %d -> %d bytes (%+d)", ae.Time.Format("2006-01-02 15:04:05"), ae.Kind, ae.Path, ae.Before, ae.After, ae.Delta())
+		if ae.Detail != "" {
+			lstr += "  " + ae.Detail
+		}
+		fp := ae.Path
+		if !filepath.IsAbs(fp) {
+			fp = filepath.Join(root, fp)
+		}
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte(fmt.Sprintf(`<a href="file:///%v">%v</a>`, fp, html.EscapeString(lstr))))
+	}
+	hdr := fmt.Sprintf("%d writes to files", n)
+	if av.Filter != "" {
+		hdr += fmt.Sprintf(" matching: %v", av.Filter)
+	}
+	ltxt := bytes.Join(append([][]byte{[]byte(hdr), []byte("")}, outlns...), []byte("\n"))
+	mtxt := bytes.Join(append([][]byte{[]byte("<b>" + html.EscapeString(hdr) + "</b>"), []byte("")}, outmus...), []byte("\n"))
+	tbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (av *AuditView) UpdateView(ge *Gide) {
+	av.Gide = ge
+	mods, updt := av.StdAuditConfig()
+	av.ConfigToolbar()
+	tvly := av.TextViewLay()
+	av.Gide.ConfigOutputTextView(tvly)
+	if mods {
+		av.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (av *AuditView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "auditbar")
+	config.Add(gi.KiT_Layout, "audittext")
+	return config
+}
+
+// StdAuditConfig configures a standard setup of the overall layout -- returns
+// mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (av *AuditView) StdAuditConfig() (mods, updt bool) {
+	av.Lay = gi.LayoutVert
+	av.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := av.StdConfig()
+	mods, updt = av.ConfigChildren(config, false)
+	return
+}
+
+// AuditBar returns the audit toolbar
+func (av *AuditView) AuditBar() *gi.ToolBar {
+	tbi, ok := av.ChildByName("auditbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// TextViewLay returns the audit log TextView layout
+func (av *AuditView) TextViewLay() *gi.Layout {
+	tvi, ok := av.ChildByName("audittext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the audit log TextView
+func (av *AuditView) TextView() *giv.TextView {
+	tvly := av.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (av *AuditView) ConfigToolbar() {
+	tb := av.AuditBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	refresh := tb.AddNewChild(gi.KiT_Action, "refresh").(*gi.Action)
+	refresh.SetText("Refresh")
+	refresh.Tooltip = "read the audit log again"
+	refresh.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		avv, _ := recv.Embed(KiT_AuditView).(*AuditView)
+		avv.Refresh()
+	})
+
+	fl := tb.AddNewChild(gi.KiT_Label, "filter-lbl").(*gi.Label)
+	fl.SetText("File:")
+	fl.Tooltip = "only show writes to files whose path contains this"
+	ff := tb.AddNewChild(gi.KiT_TextField, "filter").(*gi.TextField)
+	ff.SetStretchMaxWidth()
+	ff.Tooltip = fl.Tooltip
+	ff.TextFieldSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			avv, _ := recv.Embed(KiT_AuditView).(*AuditView)
+			tf := send.(*gi.TextField)
+			avv.Filter = tf.Text()
+			avv.ShowResults()
+		}
+	})
+}
+
+var AuditViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
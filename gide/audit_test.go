@@ -0,0 +1,81 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fnm := filepath.Join(dir, "sub", "proj.jsonl")
+	tm := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	var want []AuditEntry
+	for i := 0; i < 5; i++ {
+		ae := AuditEntry{Time: tm.Add(time.Duration(i) * time.Minute), Kind: "save", Path: "main.go", Before: int64(100 + i), After: int64(101 + i), Detail: "formatted"}
+		if err := AppendAuditLog(fnm, &ae); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, ae)
+	}
+	aes, err := ReadAuditLog(fnm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(aes, want) {
+		t.Errorf("read: %v, want %v", aes, want)
+	}
+	if aes[0].Delta() != 1 {
+		t.Errorf("delta: %v, want 1", aes[0].Delta())
+	}
+	aes, err = TrimAuditLog(fnm, aes, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(aes, want[2:]) {
+		t.Errorf("trim: %v, want %v", aes, want[2:])
+	}
+	aes, _ = ReadAuditLog(fnm)
+	if !reflect.DeepEqual(aes, want[2:]) {
+		t.Errorf("read after trim: %v, want %v", aes, want[2:])
+	}
+}
+
+func TestDiffFilePaths(t *testing.T) {
+	dif := []byte(`--- /proj/a.go	2018-10-01 12:00:00.000000000 +0000
++++ /tmp/gorename123	2018-10-01 12:00:01.000000000 +0000
+@@ -1,3 +1,3 @@
+--- not a header
+-old
++new
+--- /proj/b/c.go
++++ /tmp/gorename456
+`)
+	fps := DiffFilePaths(dif)
+	want := []string{"/proj/a.go", "/proj/b/c.go"}
+	if !reflect.DeepEqual(fps, want) {
+		t.Errorf("paths: %v, want %v", fps, want)
+	}
+}
+
+func TestAuditNotes(t *testing.T) {
+	var notes []string
+	notes = AuditNotes(notes, "replace")
+	notes = AuditNotes(notes, "formatted")
+	notes = AuditNotes(notes, "replace")
+	want := []string{"replace", "formatted"}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("notes: %v, want %v", notes, want)
+	}
+}
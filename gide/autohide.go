@@ -0,0 +1,202 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki"
+)
+
+// AutoHideStrip is the size of the thin strip that an auto-hidden panel is
+// collapsed to, as a fraction of the width of the window -- it stays big
+// enough to be hovered over, and is above the size at which a panel counts
+// as closed
+var AutoHideStrip = float32(0.015)
+
+// AutoHideSize is the size an auto-hidden panel is revealed at if it has
+// not been shown before
+var AutoHideSize = float32(0.2)
+
+// AutoHideSplits returns a copy of given splits with the panels in hide
+// collapsed to AutoHideStrip, recording their sizes in sizes, and the
+// panels in show revealed at their sizes in sizes, removing them from it --
+// sizes thus has the panels that are hidden
+func AutoHideSplits(splits []float32, hide, show []int, sizes map[int]float32) []float32 {
+	sp := make([]float32, len(splits))
+	copy(sp, splits)
+	for _, p := range hide {
+		if p < 0 || p >= len(sp) {
+			continue
+		}
+		if _, hid := sizes[p]; hid {
+			continue
+		}
+		sizes[p] = sp[p]
+		sp[p] = AutoHideStrip
+	}
+	for _, p := range show {
+		if p < 0 || p >= len(sp) {
+			continue
+		}
+		sz, hid := sizes[p]
+		if !hid {
+			continue
+		}
+		if sz <= AutoHideStrip {
+			sz = AutoHideSize
+		}
+		sp[p] = sz
+		delete(sizes, p)
+	}
+	return sp
+}
+
+// AutoHidePanels returns the panels that auto-hide in this project, as set
+// in the project prefs
+func (ge *Gide) AutoHidePanels() []int {
+	var ps []int
+	if ge.Prefs.AutoHideTree {
+		ps = append(ps, FileTreeIdx)
+	}
+	if ge.Prefs.AutoHideTabs {
+		ps = append(ps, MainTabsIdx)
+	}
+	return ps
+}
+
+// AutoHides returns true if given panel auto-hides
+func (ge *Gide) AutoHides(panel int) bool {
+	for _, p := range ge.AutoHidePanels() {
+		if p == panel {
+			return true
+		}
+	}
+	return false
+}
+
+// PanelAutoHidden returns true if given panel is auto-hidden now
+func (ge *Gide) PanelAutoHidden(panel int) bool {
+	_, hid := ge.autoHidden[panel]
+	return hid
+}
+
+// AutoHideSet hides and reveals given panels
+func (ge *Gide) AutoHideSet(hide, show []int) {
+	sv := ge.SplitView()
+	if sv == nil {
+		return
+	}
+	if ge.autoHidden == nil {
+		ge.autoHidden = map[int]float32{}
+	}
+	sp := AutoHideSplits(sv.Splits, hide, show, ge.autoHidden)
+	for i := range sp {
+		if sp[i] != sv.Splits[i] {
+			sv.SetSplitsAction(sp...)
+			return
+		}
+	}
+}
+
+// AutoHideSync reveals given panel, which has the focus, if it is
+// auto-hidden, and hides the other auto-hide panels -- panels that no longer
+// auto-hide are revealed -- called when the focus moves to another panel
+func (ge *Gide) AutoHideSync(focus int) {
+	if len(ge.autoHidden) == 0 && len(ge.AutoHidePanels()) == 0 {
+		return
+	}
+	var hide, show []int
+	for _, p := range ge.AutoHidePanels() {
+		switch {
+		case p == focus && ge.PanelAutoHidden(p):
+			show = append(show, p)
+		case p != focus && !ge.PanelAutoHidden(p):
+			hide = append(hide, p)
+		}
+	}
+	for p := range ge.autoHidden {
+		if !ge.AutoHides(p) {
+			show = append(show, p)
+		}
+	}
+	if len(hide)+len(show) > 0 {
+		ge.AutoHideSet(hide, show)
+	}
+}
+
+// PanelsReveal reveals the first auto-hidden panel, the file tree or the
+// tabs, and moves the focus to it -- if the focus is already in an
+// auto-hide panel, it goes back to the panel that had it before, hiding the
+// auto-hide panel again
+func (ge *Gide) PanelsReveal() {
+	ps := ge.AutoHidePanels()
+	if len(ps) == 0 {
+		ge.SetStatus("No panels auto-hide -- set AutoHideTree or AutoHideTabs in the project prefs")
+		return
+	}
+	if ge.AutoHides(ge.CurPanel()) {
+		ge.PanelAltToggle()
+		return
+	}
+	for _, p := range ps {
+		if ge.PanelAutoHidden(p) {
+			ge.AutoHideSet(nil, []int{p})
+			ge.FocusOnPanel(p)
+			return
+		}
+	}
+	ge.FocusOnPanel(ps[0])
+}
+
+// AutoHideGrab returns given splits with the auto-hidden panels at the
+// sizes they are revealed at, for saving in the prefs
+func (ge *Gide) AutoHideGrab(splits []float32) []float32 {
+	if len(ge.autoHidden) == 0 {
+		return splits
+	}
+	sp := make([]float32, len(splits))
+	copy(sp, splits)
+	for p, sz := range ge.autoHidden {
+		if p < len(sp) {
+			sp[p] = sz
+		}
+	}
+	return sp
+}
+
+// AutoHideEvents reveals the auto-hide panels while the mouse hovers over
+// them, hiding them again when it leaves, unless they have the focus
+func (ge *Gide) AutoHideEvents() {
+	sv := ge.SplitView()
+	if sv == nil {
+		return
+	}
+	for _, p := range []int{FileTreeIdx, MainTabsIdx} {
+		if p >= len(sv.Kids) {
+			continue
+		}
+		_, pn := gi.KiToNode2D(sv.Kids[p])
+		if pn == nil {
+			continue
+		}
+		panel := p
+		pn.ConnectEvent(oswin.MouseFocusEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			if !ge.AutoHides(panel) { // recv is the panel, not ge
+				return
+			}
+			me := d.(*mouse.FocusEvent)
+			switch me.Action {
+			case mouse.Enter:
+				ge.AutoHideSet(nil, []int{panel})
+			case mouse.Exit:
+				if ge.CurPanel() != panel {
+					ge.AutoHideSet([]int{panel}, nil)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAutoHideSplits(t *testing.T) {
+	splits := []float32{.1, .325, .325, .25, 0}
+	sizes := map[int]float32{}
+	sp := AutoHideSplits(splits, []int{FileTreeIdx, MainTabsIdx}, nil, sizes)
+	if want := []float32{AutoHideStrip, .325, .325, AutoHideStrip, 0}; !reflect.DeepEqual(sp, want) {
+		t.Errorf("hide: got %v, want %v", sp, want)
+	}
+	if splits[0] != .1 {
+		t.Errorf("splits changed: %v", splits)
+	}
+	if want := map[int]float32{FileTreeIdx: .1, MainTabsIdx: .25}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("sizes: got %v, want %v", sizes, want)
+	}
+
+	again := AutoHideSplits(sp, []int{FileTreeIdx}, nil, sizes) // already hidden
+	if !reflect.DeepEqual(again, sp) || sizes[FileTreeIdx] != .1 {
+		t.Errorf("hide again: got %v, sizes %v", again, sizes)
+	}
+
+	sp = AutoHideSplits(sp, nil, []int{MainTabsIdx, TextView1Idx}, sizes)
+	if want := []float32{AutoHideStrip, .325, .325, .25, 0}; !reflect.DeepEqual(sp, want) {
+		t.Errorf("show: got %v, want %v", sp, want)
+	}
+	if _, hid := sizes[MainTabsIdx]; hid || len(sizes) != 1 {
+		t.Errorf("show: sizes %v", sizes)
+	}
+
+	sizes = map[int]float32{FileTreeIdx: 0} // was closed when hidden
+	sp = AutoHideSplits([]float32{AutoHideStrip, .5, .5, 0, 0}, nil, []int{FileTreeIdx, 9}, sizes)
+	if sp[0] != AutoHideSize || len(sizes) != 0 {
+		t.Errorf("show closed: got %v, sizes %v", sp, sizes)
+	}
+}
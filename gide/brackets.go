@@ -0,0 +1,259 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+)
+
+// DefaultAutoPairs are the bracket pairs used for files that have no
+// language, or whose language has no AutoPairs
+var DefaultAutoPairs = "()[]{}"
+
+// MaxBracketLines is the maximum number of lines searched for a matching
+// bracket in either direction
+var MaxBracketLines = 5000
+
+// BracketPair returns the other delimiter of the pair in pairs (a string of
+// opening and closing delimiter pairs, e.g., "()[]{}") that r belongs to,
+// and whether r is the opening one -- ok is false if r is not in pairs
+func BracketPair(pairs string, r rune) (other rune, open bool, ok bool) {
+	prs := []rune(pairs)
+	for i := 0; i+1 < len(prs); i += 2 {
+		if prs[i] == r {
+			return prs[i+1], true, true
+		}
+		if prs[i+1] == r {
+			return prs[i], false, true
+		}
+	}
+	return 0, false, false
+}
+
+// codeMask returns for each rune in line whether it is code, as opposed to
+// being within a string or rune literal quoted by one of the same-delimiter
+// pairs in pairs, or within a line comment starting with cmt
+func codeMask(line []rune, pairs string, cmt string) []bool {
+	cm := []rune(strings.TrimSpace(cmt))
+	msk := make([]bool, len(line))
+	var q rune
+	for i := 0; i < len(line); i++ {
+		r := line[i]
+		if q != 0 {
+			if r == '\\' && q != '`' && i+1 < len(line) {
+				i++
+			} else if r == q {
+				q = 0
+			}
+			continue
+		}
+		if len(cm) > 0 && i+len(cm) <= len(line) && string(line[i:i+len(cm)]) == string(cm) {
+			break
+		}
+		if o, _, ok := BracketPair(pairs, r); ok && o == r {
+			q = r
+			continue
+		}
+		msk[i] = true
+	}
+	return msk
+}
+
+// MatchBracket returns the position of the bracket matching the one at pos
+// in lines, or if there is none there, the one just before pos, using the
+// bracket pairs in pairs and skipping those in strings and comments (see
+// codeMask) -- at is the position of the bracket at or before pos
+func MatchBracket(lines [][]rune, pos giv.TextPos, pairs string, cmt string) (at, match giv.TextPos, ok bool) {
+	if pos.Ln < 0 || pos.Ln >= len(lines) {
+		return
+	}
+	msk := codeMask(lines[pos.Ln], pairs, cmt)
+	var r, other rune
+	var open bool
+	found := false
+	for _, ch := range []int{pos.Ch, pos.Ch - 1} {
+		if ch < 0 || ch >= len(msk) || !msk[ch] {
+			continue
+		}
+		r = lines[pos.Ln][ch]
+		var isp bool
+		other, open, isp = BracketPair(pairs, r)
+		if isp && other != r {
+			at = giv.TextPos{Ln: pos.Ln, Ch: ch}
+			found = true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+	depth := 0
+	if open {
+		for ln := at.Ln; ln < len(lines) && ln <= at.Ln+MaxBracketLines; ln++ {
+			if ln != at.Ln {
+				msk = codeMask(lines[ln], pairs, cmt)
+			}
+			st := 0
+			if ln == at.Ln {
+				st = at.Ch
+			}
+			for ch := st; ch < len(lines[ln]); ch++ {
+				if !msk[ch] {
+					continue
+				}
+				switch lines[ln][ch] {
+				case r:
+					depth++
+				case other:
+					depth--
+					if depth == 0 {
+						return at, giv.TextPos{Ln: ln, Ch: ch}, true
+					}
+				}
+			}
+		}
+		return
+	}
+	for ln := at.Ln; ln >= 0 && ln >= at.Ln-MaxBracketLines; ln-- {
+		if ln != at.Ln {
+			msk = codeMask(lines[ln], pairs, cmt)
+		}
+		st := len(lines[ln]) - 1
+		if ln == at.Ln {
+			st = at.Ch
+		}
+		for ch := st; ch >= 0; ch-- {
+			if !msk[ch] {
+				continue
+			}
+			switch lines[ln][ch] {
+			case r:
+				depth++
+			case other:
+				depth--
+				if depth == 0 {
+					return at, giv.TextPos{Ln: ln, Ch: ch}, true
+				}
+			}
+		}
+	}
+	return
+}
+
+// LangPairs returns the auto pairs and line comment string for the language
+// of given file, or DefaultAutoPairs and no comment if it has none
+func LangPairs(filename string) (pairs, cmt string) {
+	pairs = DefaultAutoPairs
+	ls := LangsForFilename(filename)
+	if len(ls) > 0 {
+		cmt = ls[0].Comment
+		if ls[0].AutoPairs != "" {
+			pairs = ls[0].AutoPairs
+		}
+	}
+	return
+}
+
+// BracketShow highlights the bracket at or just before the cursor in given
+// view and its matching bracket, replacing any previously shown by it --
+// called when the cursor moves
+func (ge *Gide) BracketShow(tv *giv.TextView) {
+	if tv.Buf == nil {
+		return
+	}
+	old := ge.brackHls[tv]
+	var nw []giv.TextRegion
+	pairs, cmt := LangPairs(string(tv.Buf.Filename))
+	if at, mt, ok := MatchBracket(tv.Buf.Lines, tv.CursorPos, pairs, cmt); ok {
+		for _, p := range []giv.TextPos{at, mt} {
+			nw = append(nw, giv.TextRegion{Start: p, End: giv.TextPos{Ln: p.Ln, Ch: p.Ch + 1}})
+		}
+	}
+	if len(old) == 0 && len(nw) == 0 {
+		return
+	}
+	ReplaceHighlights(tv, old, nw)
+	if ge.brackHls == nil {
+		ge.brackHls = make(map[*giv.TextView][]giv.TextRegion)
+	}
+	ge.brackHls[tv] = nw
+}
+
+// JumpToMatch moves the cursor in the active view to the bracket matching
+// the one at or just before the cursor
+func (ge *Gide) JumpToMatch() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil {
+		return false
+	}
+	pairs, cmt := LangPairs(string(tv.Buf.Filename))
+	_, mt, ok := MatchBracket(tv.Buf.Lines, tv.CursorPos, pairs, cmt)
+	if !ok {
+		ge.SetStatus("No matching bracket")
+		return false
+	}
+	tv.SetCursorShow(mt)
+	return true
+}
+
+// AutoClose handles auto-closing of delimiters typed into the active view,
+// per EditorPrefs.AutoClose and the AutoPairs of the file's language:
+// typing an opening delimiter also inserts the closing one after the
+// cursor, and typing a closing delimiter that is already next to the
+// cursor just moves past it -- returns true if the key was handled
+func (ge *Gide) AutoClose(kt *key.ChordEvent) bool {
+	if !ge.Prefs.Editor.AutoClose {
+		return false
+	}
+	kr := []rune(strings.TrimPrefix(string(kt.Chord()), "Shift+"))
+	if len(kr) != 1 || !unicode.IsPrint(kr[0]) {
+		return false
+	}
+	r := kr[0]
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil || tv.IsInactive() || !tv.HasFocus() || tv.HasSelection() || ge.Cursors.Has(tv) {
+		return false
+	}
+	pairs, _ := LangPairs(string(tv.Buf.Filename))
+	other, open, ok := BracketPair(pairs, r)
+	if !ok {
+		return false
+	}
+	pos := tv.CursorPos
+	line := tv.Buf.Lines[pos.Ln]
+	var prv, nxt rune
+	if pos.Ch > 0 && pos.Ch <= len(line) {
+		prv = line[pos.Ch-1]
+	}
+	if pos.Ch < len(line) {
+		nxt = line[pos.Ch]
+	}
+	if (!open || other == r) && nxt == r {
+		tv.SetCursorShow(giv.TextPos{Ln: pos.Ln, Ch: pos.Ch + 1})
+		return true
+	}
+	if !open {
+		return false
+	}
+	isWord := func(c rune) bool {
+		return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_'
+	}
+	if other == r { // quote
+		if isWord(prv) || prv == '\\' || isWord(nxt) {
+			return false
+		}
+	} else if nxt != 0 && !unicode.IsSpace(nxt) {
+		if _, nopen, isp := BracketPair(pairs, nxt); !isp || nopen {
+			return false
+		}
+	}
+	tv.Buf.InsertText(pos, []byte(string([]rune{r, other})), true, true)
+	tv.SetCursorShow(giv.TextPos{Ln: pos.Ln, Ch: pos.Ch + 1})
+	return true
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/giv"
+)
+
+func TestMatchBracket(t *testing.T) {
+	src := `func Foo(a int) {
+	s := "}" // )
+	if a > 0 {
+		x := '{'
+	}
+}`
+	var lines [][]rune
+	for _, l := range strings.Split(src, "\n") {
+		lines = append(lines, []rune(l))
+	}
+	pairs := "()[]{}\"\"''``"
+	tests := []struct {
+		pos, at, match giv.TextPos
+		ok             bool
+	}{
+		{giv.TextPos{Ln: 0, Ch: 16}, giv.TextPos{Ln: 0, Ch: 16}, giv.TextPos{Ln: 5, Ch: 0}, true},
+		{giv.TextPos{Ln: 5, Ch: 1}, giv.TextPos{Ln: 5, Ch: 0}, giv.TextPos{Ln: 0, Ch: 16}, true},
+		{giv.TextPos{Ln: 0, Ch: 8}, giv.TextPos{Ln: 0, Ch: 8}, giv.TextPos{Ln: 0, Ch: 14}, true},
+		{giv.TextPos{Ln: 2, Ch: 10}, giv.TextPos{Ln: 2, Ch: 10}, giv.TextPos{Ln: 4, Ch: 1}, true},
+		{giv.TextPos{Ln: 1, Ch: 7}, giv.TextPos{}, giv.TextPos{}, false},
+		{giv.TextPos{Ln: 1, Ch: 3}, giv.TextPos{}, giv.TextPos{}, false},
+	}
+	for i, ts := range tests {
+		at, mt, ok := MatchBracket(lines, ts.pos, pairs, "// ")
+		if ok != ts.ok || (ok && (at != ts.at || mt != ts.match)) {
+			t.Errorf("MatchBracket %d at %v: got %v %v %v, want %v %v %v", i, ts.pos, at, mt, ok, ts.at, ts.match, ts.ok)
+		}
+	}
+}
+
+func TestBracketPair(t *testing.T) {
+	o, open, ok := BracketPair("()[]{}", ']')
+	if !ok || open || o != '[' {
+		t.Errorf("BracketPair ]: got %q %v %v", o, open, ok)
+	}
+	if _, _, ok := BracketPair("()[]{}", 'x'); ok {
+		t.Errorf("BracketPair x: should not be a bracket")
+	}
+}
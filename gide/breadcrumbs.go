@@ -0,0 +1,238 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+)
+
+// OutlinePath returns the chain of symbols of given outline that enclose
+// given line, outermost first, e.g., a type and then its method -- the kids
+// of a symbol are searched even if it does not enclose the line, as the
+// methods of a Go type are kept as its kids
+func OutlinePath(syms []OutlineSym, ln int) []OutlineSym {
+	for _, s := range syms {
+		ed := s.EdLn
+		if ed < s.Ln {
+			ed = s.Ln
+		}
+		if ln >= s.Ln && ln <= ed {
+			return append([]OutlineSym{s}, OutlinePath(s.Kids, ln)...)
+		}
+		if kp := OutlinePath(s.Kids, ln); len(kp) > 0 {
+			return append([]OutlineSym{s}, kp...)
+		}
+	}
+	return nil
+}
+
+// GoPackageClause returns the name of the package of given Go lines, and
+// the line of its package clause, or "" and -1 if there is none
+func GoPackageClause(lines [][]rune) (string, int) {
+	cmt := false
+	for i, lr := range lines {
+		ln := strings.TrimSpace(string(lr))
+		if cmt {
+			ci := strings.Index(ln, "*/")
+			if ci < 0 {
+				continue
+			}
+			cmt = false
+			ln = strings.TrimSpace(ln[ci+2:])
+		}
+		if strings.HasPrefix(ln, "/*") && !strings.Contains(ln, "*/") {
+			cmt = true
+			continue
+		}
+		if !strings.HasPrefix(ln, "package") {
+			continue
+		}
+		fs := strings.Fields(ln)
+		if len(fs) < 2 || fs[0] != "package" {
+			continue
+		}
+		return strings.TrimSuffix(fs[1], ";"), i
+	}
+	return "", -1
+}
+
+// crumbState is the state of the breadcrumbs bar: the outline of the
+// buffer it shows, and the symbols shown for the cursor
+type crumbState struct {
+	buf   *giv.TextBuf
+	syms  []OutlineSym
+	pkg   string
+	pkgLn int
+	path  []OutlineSym
+	key   string
+	stale bool
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+// Breadcrumbs returns the breadcrumbs bar, above the text views, or nil if
+// it is turned off in the prefs
+func (ge *Gide) Breadcrumbs() *gi.ToolBar {
+	tbi, ok := ge.ChildByName("breadcrumbs", 1)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// ConfigBreadcrumbs configures the breadcrumbs bar
+func (ge *Gide) ConfigBreadcrumbs() {
+	bc := ge.Breadcrumbs()
+	if bc == nil {
+		return
+	}
+	bc.SetStretchMaxWidth()
+	bc.SetMinPrefHeight(units.NewValue(1.2, units.Em))
+	bc.SetProp("overflow", "hidden")
+	bc.SetProp("spacing", 0)
+	bc.SetProp("padding", 0)
+	ge.CrumbsUpdate()
+}
+
+// CrumbsUpdate shows the package, type and function (or the sections) that
+// enclose the cursor of the active view in the breadcrumbs bar, each one
+// jumping to its declaration when clicked -- they come from the same outline
+// as the Outline panel, which is gotten again when the active view shows
+// another buffer, and shortly after edits -- called when the cursor moves
+func (ge *Gide) CrumbsUpdate() {
+	bc := ge.Breadcrumbs()
+	if bc == nil {
+		return
+	}
+	if ge.crumbs == nil {
+		ge.crumbs = &crumbState{}
+	}
+	cs := ge.crumbs
+	tv := ge.ActiveTextView()
+	var tb *giv.TextBuf
+	if tv != nil {
+		tb = tv.Buf
+	}
+	cs.mu.Lock()
+	if tb != cs.buf || cs.stale {
+		cs.buf = tb
+		cs.stale = false
+		cs.syms, cs.pkg, cs.pkgLn = nil, "", -1
+		if tb != nil {
+			cs.syms, _, _ = ge.BufOutline(tb) // partial on errors
+			if LangNamesMatchFilename(string(tb.Filename), LangNames{"Go"}) {
+				cs.pkg, cs.pkgLn = GoPackageClause(tb.Lines)
+			} else {
+				cs.pkg = filepath.Base(string(tb.Filename))
+			}
+		}
+		cs.key = "\n" // always redrawn
+	}
+	var path []OutlineSym
+	if tv != nil {
+		path = OutlinePath(cs.syms, tv.CursorPos.Ln)
+	}
+	key := cs.pkg
+	for _, s := range path {
+		key += fmt.Sprintf("/%v:%v", s.Name, s.Ln)
+	}
+	if key == cs.key {
+		cs.mu.Unlock()
+		return
+	}
+	cs.key = key
+	cs.path = path
+	pkg, pkgLn := cs.pkg, cs.pkgLn
+	cs.mu.Unlock()
+
+	updt := bc.UpdateStart()
+	bc.SetFullReRender()
+	bc.DeleteChildren(true)
+	if pkg != "" {
+		ac := bc.AddNewChild(gi.KiT_Action, "crumb-pkg").(*gi.Action)
+		ac.SetText(pkg)
+		if pkgLn >= 0 {
+			ac.Tooltip = fmt.Sprintf("package %v -- click to go to its package clause", pkg)
+		} else {
+			ac.Tooltip = "click to go to the start of the file"
+		}
+		ln := pkgLn
+		if ln < 0 {
+			ln = 0
+		}
+		ac.ActionSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_Gide).(*Gide)
+			if tv := gee.ActiveTextView(); tv != nil {
+				tv.SetCursorShow(giv.TextPos{Ln: ln})
+				tv.GrabFocus()
+			}
+		})
+	}
+	for i, s := range path {
+		if i > 0 || pkg != "" {
+			sep := bc.AddNewChild(gi.KiT_Label, fmt.Sprintf("crumb-sep-%d", i)).(*gi.Label)
+			sep.SetText("›")
+		}
+		ac := bc.AddNewChild(gi.KiT_Action, fmt.Sprintf("crumb-%d", i)).(*gi.Action)
+		ac.SetText(s.Name)
+		ac.Tooltip = strings.TrimSpace(fmt.Sprintf("%v %v %v", s.Kind, s.Name, s.Detail)) + fmt.Sprintf(" -- line %d, click to go to it", s.Ln+1)
+		ac.Data = i
+		ac.ActionSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_Gide).(*Gide)
+			gee.CrumbJump(send.(*gi.Action).Data.(int))
+		})
+	}
+	bc.UpdateEnd(updt)
+}
+
+// CrumbJump moves the cursor of the active view to the symbol at given
+// level of the breadcrumbs, 0 being the outermost one after the package
+func (ge *Gide) CrumbJump(lev int) {
+	cs := ge.crumbs
+	tv := ge.ActiveTextView()
+	if cs == nil || tv == nil {
+		return
+	}
+	cs.mu.Lock()
+	if lev < 0 || lev >= len(cs.path) || cs.buf != tv.Buf {
+		cs.mu.Unlock()
+		return
+	}
+	s := cs.path[lev]
+	cs.mu.Unlock()
+	ge.outlineJump(tv, s)
+}
+
+// CrumbsEdit updates the breadcrumbs shortly after given buffer is edited,
+// if they are showing it, so that the outline is not gotten on each key
+func (ge *Gide) CrumbsEdit(tb *giv.TextBuf) {
+	cs := ge.crumbs
+	if cs == nil {
+		return
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.buf != tb {
+		return
+	}
+	if cs.timer != nil {
+		cs.timer.Stop()
+	}
+	cs.timer = time.AfterFunc(OutlineDelay, func() {
+		cs.mu.Lock()
+		cs.stale = true
+		cs.mu.Unlock()
+		ge.CrumbsUpdate()
+	})
+}
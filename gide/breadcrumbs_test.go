@@ -0,0 +1,98 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"testing"
+)
+
+func crumbNames(path []OutlineSym) string {
+	var nms []string
+	for _, s := range path {
+		nms = append(nms, s.Name)
+	}
+	return strings.Join(nms, " > ")
+}
+
+func TestOutlinePathGo(t *testing.T) {
+	src := `package x
+
+type Buf struct {
+	Lines []string
+}
+
+func (b *Buf) Len() int {
+	n := len(b.Lines)
+	return n
+}
+
+func New() *Buf {
+	return &Buf{}
+}
+`
+	syms, err := GoOutline([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ln, want := range map[int]string{
+		0:  "",
+		2:  "Buf",
+		3:  "Buf > Lines",
+		4:  "Buf",
+		5:  "",
+		7:  "Buf > Len",
+		9:  "Buf > Len",
+		12: "New",
+		14: "",
+	} {
+		if got := crumbNames(OutlinePath(syms, ln)); got != want {
+			t.Errorf("line %d: got %q, want %q", ln, got, want)
+		}
+	}
+}
+
+func TestOutlinePathMarkdown(t *testing.T) {
+	md := "# Top\n\nintro\n\n## One\n\ntext\n\n### Deep\n\nmore\n\n## Two\n\nlast\n"
+	var lines [][]rune
+	for _, l := range strings.Split(md, "\n") {
+		lines = append(lines, []rune(l))
+	}
+	syms := MarkdownOutline(lines)
+	for ln, want := range map[int]string{
+		2:  "Top",
+		6:  "Top > One",
+		10: "Top > One > Deep",
+		12: "Top > Two",
+		14: "Top > Two",
+	} {
+		if got := crumbNames(OutlinePath(syms, ln)); got != want {
+			t.Errorf("line %d: got %q, want %q", ln, got, want)
+		}
+	}
+}
+
+func TestGoPackageClause(t *testing.T) {
+	cases := []struct {
+		src  string
+		name string
+		ln   int
+	}{
+		{"package gide\n", "gide", 0},
+		{"// Copyright\n\n// Package x does y\npackage x // import \"x\"\n", "x", 3},
+		{"/*\npackage no\n*/\npackage yes\n", "yes", 3},
+		{"packages are\n", "", -1},
+		{"", "", -1},
+	}
+	for _, c := range cases {
+		var lines [][]rune
+		for _, l := range strings.Split(c.src, "\n") {
+			lines = append(lines, []rune(l))
+		}
+		if nm, ln := GoPackageClause(lines); nm != c.name || ln != c.ln {
+			t.Errorf("%q: got %q, %d, want %q, %d", c.src, nm, ln, c.name, c.ln)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// DebugBreaks is a list of breakpoints
+type DebugBreaks []DebugBreak
+
+// Groups returns the sorted names of the groups of the breakpoints
+func (bs DebugBreaks) Groups() []string {
+	var gps []string
+	has := map[string]bool{}
+	for _, bp := range bs {
+		if bp.Group != "" && !has[bp.Group] {
+			has[bp.Group] = true
+			gps = append(gps, bp.Group)
+		}
+	}
+	sort.Strings(gps)
+	return gps
+}
+
+// SetGroupEnabled enables or disables all the breakpoints in given group,
+// returning the number of breakpoints in the group
+func (bs DebugBreaks) SetGroupEnabled(group string, on bool) int {
+	n := 0
+	for i := range bs {
+		if bs[i].Group == group {
+			bs[i].Disabled = !on
+			n++
+		}
+	}
+	return n
+}
+
+// RelTo returns a copy of the breakpoints with file paths relative to given
+// root directory, where possible, for exporting
+func (bs DebugBreaks) RelTo(root string) DebugBreaks {
+	rb := make(DebugBreaks, len(bs))
+	for i, bp := range bs {
+		bp.ID = 0
+		if rp, err := filepath.Rel(root, bp.File); err == nil {
+			bp.File = filepath.ToSlash(rp)
+		}
+		rb[i] = bp
+	}
+	return rb
+}
+
+// AbsTo makes relative file paths of the breakpoints absolute, relative to
+// given root directory, after importing
+func (bs DebugBreaks) AbsTo(root string) {
+	for i := range bs {
+		if !filepath.IsAbs(bs[i].File) {
+			bs[i].File = filepath.Join(root, filepath.FromSlash(bs[i].File))
+		}
+	}
+}
+
+// OpenJSON opens breakpoints from a JSON-formatted file.
+func (bs *DebugBreaks) OpenJSON(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	*bs = nil // reset
+	return json.Unmarshal(b, bs)
+}
+
+// SaveJSON saves breakpoints to a JSON-formatted file.
+func (bs *DebugBreaks) SaveJSON(filename gi.FileName) error {
+	b, err := json.MarshalIndent(bs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(string(filename), b, 0644)
+}
+
+// DebugSyncBreaks sets the breakpoints in the running debugger to the
+// current enabled breakpoints, after they have been edited
+func (ge *Gide) DebugSyncBreaks() {
+	if ge.Dbg == nil || ge.DbgRunning {
+		return
+	}
+	for i := range ge.Prefs.Breaks {
+		bp := &ge.Prefs.Breaks[i]
+		if bp.ID != 0 {
+			ge.Dbg.ClearBreakpoint(bp.ID)
+			bp.ID = 0
+		}
+		ge.debugSetBreak(bp)
+	}
+}
+
+// DebugGroupEnabled enables or disables all the breakpoints in given group
+func (ge *Gide) DebugGroupEnabled(group string, on bool) {
+	n := ge.Prefs.Breaks.SetGroupEnabled(group, on)
+	if n == 0 {
+		ge.SetStatus("No breakpoints in group: " + group)
+		return
+	}
+	ge.Prefs.Changed = true
+	ge.DebugSyncBreaks()
+	act := "Disabled"
+	if on {
+		act = "Enabled"
+	}
+	ge.SetStatus(fmt.Sprintf("%v %d breakpoints in group: %v", act, n, group))
+}
+
+// DebugExportBreaks saves the breakpoints to given file, with file paths
+// relative to the project root, so they can be shared
+func (ge *Gide) DebugExportBreaks(filename gi.FileName) error {
+	rb := ge.Prefs.Breaks.RelTo(string(ge.ProjRoot))
+	return rb.SaveJSON(filename)
+}
+
+// DebugImportBreaks adds the breakpoints in given file, as saved by
+// DebugExportBreaks, to the current ones, replacing any at the same lines
+func (ge *Gide) DebugImportBreaks(filename gi.FileName) error {
+	var nb DebugBreaks
+	if err := nb.OpenJSON(filename); err != nil {
+		return err
+	}
+	nb.AbsTo(string(ge.ProjRoot))
+	for _, bp := range nb {
+		bp.ID = 0
+		found := false
+		for i := range ge.Prefs.Breaks {
+			ob := &ge.Prefs.Breaks[i]
+			if ob.File == bp.File && ob.Line == bp.Line {
+				bp.ID = ob.ID
+				*ob = bp
+				found = true
+				break
+			}
+		}
+		if !found {
+			ge.Prefs.Breaks = append(ge.Prefs.Breaks, bp)
+		}
+	}
+	ge.Prefs.Changed = true
+	ge.DebugSyncBreaks()
+	return nil
+}
+
+// DebugBreaksView shows the breakpoints panel, listing all the breakpoints,
+// with their group, condition and enabled state editable
+func (ge *Gide) DebugBreaksView() {
+	bvi, _ := ge.FindOrMakeMainTab("Breakpoints", KiT_BreaksView, true) // sel
+	bv := bvi.Embed(KiT_BreaksView).(*BreaksView)
+	bv.UpdateView(ge)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// BreaksView is a widget that lists the debugger breakpoints of the project,
+// for editing, with actions for groups and import / export
+type BreaksView struct {
+	gi.Layout
+	Gide *Gide `json:"-" xml:"-" desc:"parent gide project"`
+}
+
+var KiT_BreaksView = kit.Types.AddType(&BreaksView{}, BreaksViewProps)
+
+// GroupDialog prompts for the name of a group of breakpoints to enable or
+// disable
+func (bv *BreaksView) GroupDialog(on bool) {
+	gps := bv.Gide.Prefs.Breaks.Groups()
+	if len(gps) == 0 {
+		bv.Gide.SetStatus("No breakpoint groups -- set the Group of breakpoints in the table")
+		return
+	}
+	ttl := "Disable Breakpoint Group"
+	if on {
+		ttl = "Enable Breakpoint Group"
+	}
+	gi.StringPromptDialog(bv.Gide.Viewport, gps[0], "group",
+		gi.DlgOpts{Title: ttl, Prompt: fmt.Sprintf("Group name, one of: %v", gps)},
+		bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dlg := send.(*gi.Dialog)
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			bvv, _ := recv.Embed(KiT_BreaksView).(*BreaksView)
+			bvv.Gide.DebugGroupEnabled(gi.StringPromptDialogValue(dlg), on)
+			bvv.TableView().UpdateFromSlice()
+		})
+}
+
+// FileDialog prompts for a file to export breakpoints to, or import from
+func (bv *BreaksView) FileDialog(export bool) {
+	ge := bv.Gide
+	vp := ge.Viewport
+	ttl := "Import Breakpoints"
+	if export {
+		ttl = "Export Breakpoints"
+	}
+	giv.FileViewDialog(vp, string(ge.ProjRoot), ".json", giv.DlgOpts{Title: ttl}, nil,
+		vp.Win, func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			dlg, _ := send.(*gi.Dialog)
+			fn := giv.FileViewDialogValue(dlg)
+			var err error
+			if export {
+				if filepath.Ext(fn) == "" {
+					fn += ".json"
+				}
+				err = ge.DebugExportBreaks(gi.FileName(fn))
+			} else {
+				err = ge.DebugImportBreaks(gi.FileName(fn))
+				bv.TableView().UpdateFromSlice()
+			}
+			if err != nil {
+				gi.PromptDialog(vp, gi.DlgOpts{Title: "Could not " + ttl, Prompt: err.Error()}, true, false, nil, nil)
+				return
+			}
+			ge.SetStatus(ttl + ": " + fn)
+		})
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (bv *BreaksView) UpdateView(ge *Gide) {
+	bv.Gide = ge
+	bv.Lay = gi.LayoutVert
+	bv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "breaksbar")
+	config.Add(giv.KiT_TableView, "breaks")
+	mods, updt := bv.ConfigChildren(config, false)
+	bv.ConfigToolbar()
+	tv := bv.TableView()
+	tv.SetSlice(&ge.Prefs.Breaks, nil)
+	tv.SetStretchMaxWidth()
+	tv.SetStretchMaxHeight()
+	if mods {
+		tv.ViewSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			bvv, _ := recv.Embed(KiT_BreaksView).(*BreaksView)
+			bvv.Gide.Prefs.Changed = true
+			bvv.Gide.DebugSyncBreaks()
+		})
+		bv.UpdateEnd(updt)
+	}
+}
+
+// BreaksBar returns the breakpoints toolbar
+func (bv *BreaksView) BreaksBar() *gi.ToolBar {
+	tbi, ok := bv.ChildByName("breaksbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// TableView returns the table of breakpoints
+func (bv *BreaksView) TableView() *giv.TableView {
+	tvi, ok := bv.ChildByName("breaks", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*giv.TableView)
+}
+
+// ConfigToolbar adds toolbar.
+func (bv *BreaksView) ConfigToolbar() {
+	tb := bv.BreaksBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	en := tb.AddNewChild(gi.KiT_Action, "enable-group").(*gi.Action)
+	en.SetText("Enable Group")
+	en.Tooltip = "enable all the breakpoints in a group"
+	en.ActionSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		bvv, _ := recv.Embed(KiT_BreaksView).(*BreaksView)
+		bvv.GroupDialog(true)
+	})
+
+	ds := tb.AddNewChild(gi.KiT_Action, "disable-group").(*gi.Action)
+	ds.SetText("Disable Group")
+	ds.Tooltip = "disable all the breakpoints in a group"
+	ds.ActionSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		bvv, _ := recv.Embed(KiT_BreaksView).(*BreaksView)
+		bvv.GroupDialog(false)
+	})
+
+	ex := tb.AddNewChild(gi.KiT_Action, "export").(*gi.Action)
+	ex.SetText("Export")
+	ex.Tooltip = "save the breakpoints to a JSON file, with paths relative to the project root"
+	ex.ActionSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		bvv, _ := recv.Embed(KiT_BreaksView).(*BreaksView)
+		bvv.FileDialog(true)
+	})
+
+	im := tb.AddNewChild(gi.KiT_Action, "import").(*gi.Action)
+	im.SetText("Import")
+	im.Tooltip = "add the breakpoints from a JSON file saved by Export"
+	im.ActionSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		bvv, _ := recv.Embed(KiT_BreaksView).(*BreaksView)
+		bvv.FileDialog(false)
+	})
+}
+
+var BreaksViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
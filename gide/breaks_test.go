@@ -0,0 +1,51 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDebugBreaksGroups(t *testing.T) {
+	bs := DebugBreaks{
+		{File: "/p/a.go", Line: 3, Group: "io"},
+		{File: "/p/a.go", Line: 9},
+		{File: "/p/b.go", Line: 1, Group: "db"},
+		{File: "/p/c.go", Line: 7, Group: "io"},
+	}
+	gps := bs.Groups()
+	if len(gps) != 2 || gps[0] != "db" || gps[1] != "io" {
+		t.Errorf("Groups: got %v", gps)
+	}
+	if n := bs.SetGroupEnabled("io", false); n != 2 {
+		t.Errorf("SetGroupEnabled: got %d, want 2", n)
+	}
+	if !bs[0].Disabled || bs[1].Disabled || bs[2].Disabled || !bs[3].Disabled {
+		t.Errorf("SetGroupEnabled: wrong breakpoints disabled: %v", bs)
+	}
+	bs.SetGroupEnabled("io", true)
+	if bs[0].Disabled || bs[3].Disabled {
+		t.Errorf("SetGroupEnabled: not re-enabled: %v", bs)
+	}
+}
+
+func TestDebugBreaksRelAbs(t *testing.T) {
+	root := filepath.FromSlash("/proj")
+	bs := DebugBreaks{
+		{File: filepath.Join(root, "sub", "a.go"), Line: 3, ID: 5},
+	}
+	rb := bs.RelTo(root)
+	if rb[0].File != "sub/a.go" || rb[0].ID != 0 {
+		t.Errorf("RelTo: got %+v", rb[0])
+	}
+	if bs[0].ID != 5 {
+		t.Errorf("RelTo modified the original")
+	}
+	rb.AbsTo(root)
+	if rb[0].File != bs[0].File {
+		t.Errorf("AbsTo: got %v, want %v", rb[0].File, bs[0].File)
+	}
+}
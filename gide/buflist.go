@@ -0,0 +1,474 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// BufInfo is the info about one open buffer shown in the buffer list
+type BufInfo struct {
+	Path     string `desc:"full path of the file"`
+	Modified bool   `desc:"true if the buffer has unsaved changes"`
+	Size     int    `desc:"size of the buffer text, in bytes"`
+	Lang     string `desc:"language of the file, if known"`
+	Recent   int    `desc:"position in the open buffers, most recently viewed first"`
+}
+
+// BufListSorts are the columns that the buffer list can be sorted by --
+// Recent is the order in which they were last viewed
+var BufListSorts = []string{"Recent", "Path", "Name", "Modified", "Size", "Lang"}
+
+// SortBufInfos sorts the buffer infos by the given column, one of
+// BufListSorts, in descending order if desc -- ties are kept in Recent order
+func SortBufInfos(bis []BufInfo, by string, desc bool) {
+	less := func(a, b *BufInfo) bool { return a.Recent < b.Recent }
+	switch by {
+	case "Path":
+		less = func(a, b *BufInfo) bool { return a.Path < b.Path }
+	case "Name":
+		less = func(a, b *BufInfo) bool { return filepath.Base(a.Path) < filepath.Base(b.Path) }
+	case "Modified":
+		less = func(a, b *BufInfo) bool { return a.Modified && !b.Modified }
+	case "Size":
+		less = func(a, b *BufInfo) bool { return a.Size < b.Size }
+	case "Lang":
+		less = func(a, b *BufInfo) bool { return a.Lang < b.Lang }
+	}
+	sort.SliceStable(bis, func(i, j int) bool {
+		if desc {
+			return less(&bis[j], &bis[i])
+		}
+		return less(&bis[i], &bis[j])
+	})
+}
+
+// FilterBufInfos returns the buffer infos whose path or language contains
+// the filter, ignoring case -- all of them if the filter is empty
+func FilterBufInfos(bis []BufInfo, filter string) []BufInfo {
+	if filter == "" {
+		return bis
+	}
+	lf := strings.ToLower(filter)
+	var fbs []BufInfo
+	for _, bi := range bis {
+		if strings.Contains(strings.ToLower(bi.Path), lf) || strings.Contains(strings.ToLower(bi.Lang), lf) {
+			fbs = append(fbs, bi)
+		}
+	}
+	return fbs
+}
+
+// BufInfos returns the info about each of the open buffers, most recently
+// viewed first
+func (ge *Gide) BufInfos() []BufInfo {
+	bis := make([]BufInfo, 0, len(ge.OpenNodes))
+	for i, ond := range ge.OpenNodes {
+		if ond.Buf == nil {
+			continue
+		}
+		bi := BufInfo{Path: string(ond.FPath), Modified: ond.Buf.IsChanged(), Recent: i}
+		bi.Size = len(ond.Buf.LinesToBytesCopy())
+		if lns := LangNamesForFilename(bi.Path); len(lns) > 0 {
+			bi.Lang = string(lns[0])
+		}
+		bis = append(bis, bi)
+	}
+	return bis
+}
+
+// OpenNodeByPath returns the open node for the file at the given path
+func (ge *Gide) OpenNodeByPath(path string) (*giv.FileNode, bool) {
+	for _, ond := range ge.OpenNodes {
+		if string(ond.FPath) == path {
+			return ond, true
+		}
+	}
+	return nil, false
+}
+
+// BufList shows the list of open buffers in the Buffers panel, with their
+// paths, unsaved state, sizes and languages, sortable and filterable, and
+// actions to close, save or revert the selected ones
+func (ge *Gide) BufList() {
+	if len(ge.OpenNodes) == 0 {
+		ge.SetStatus("No open buffers to list")
+		return
+	}
+	tbuf, _ := ge.FindOrMakeCmdBuf("Buffers", true)
+	bvi, _ := ge.FindOrMakeMainTab("Buffers", KiT_BufListView, true) // sel
+	bv := bvi.Embed(KiT_BufListView).(*BufListView)
+	bv.UpdateView(ge)
+	btv := bv.TextView()
+	btv.SetInactive()
+	btv.SetBuf(tbuf)
+	bv.Refresh()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// OpenBufListURL acts on the buffer in given buflist:/// url from the
+// Buffers panel -- delegates to BufListView
+func (ge *Gide) OpenBufListURL(ur string, btv *giv.TextView) bool {
+	bvk, ok := btv.ParentByType(KiT_BufListView, true)
+	if !ok {
+		return false
+	}
+	bv := bvk.(*BufListView)
+	return bv.OpenBufListURL(ur, btv)
+}
+
+// BufListView is a widget that lists the open buffers of the project, with
+// links to view each one and to select them, for closing, saving or
+// reverting several at once
+type BufListView struct {
+	gi.Layout
+	Gide   *Gide           `json:"-" xml:"-" desc:"parent gide project"`
+	Bufs   []BufInfo       `json:"-" xml:"-" desc:"buffers as of the last refresh, sorted and filtered"`
+	Sel    map[string]bool `json:"-" xml:"-" desc:"paths of the selected buffers"`
+	SortBy string          `json:"-" xml:"-" desc:"column the list is sorted by, one of BufListSorts"`
+	Desc   bool            `json:"-" xml:"-" desc:"sort in descending order"`
+	Filter string          `json:"-" xml:"-" desc:"only show buffers whose path or language contains this"`
+}
+
+var KiT_BufListView = kit.Types.AddType(&BufListView{}, BufListViewProps)
+
+// Refresh gathers the open buffers again, and shows them -- selected
+// buffers that have since been closed are dropped from the selection
+func (bv *BufListView) Refresh() {
+	bis := FilterBufInfos(bv.Gide.BufInfos(), bv.Filter)
+	SortBufInfos(bis, bv.SortBy, bv.Desc)
+	bv.Bufs = bis
+	for p := range bv.Sel {
+		if _, ok := bv.Gide.OpenNodeByPath(p); !ok {
+			delete(bv.Sel, p)
+		}
+	}
+	bv.ShowResults()
+}
+
+// ShowResults renders the current buffers into the results buffer
+func (bv *BufListView) ShowResults() {
+	ge := bv.Gide
+	tbuf, _ := ge.FindOrMakeCmdBuf("Buffers", true)
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	lstr := fmt.Sprintf("%d open buffers, %d selected", len(bv.Bufs), len(bv.Sel))
+	if bv.Filter != "" {
+		lstr += fmt.Sprintf(", matching: %v", bv.Filter)
+	}
+	outlns = append(outlns, []byte(lstr))
+	outmus = append(outmus, []byte(fmt.Sprintf(`<b>%v</b>`, html.EscapeString(lstr))))
+
+	hdr := make([]string, len(BufListSorts))
+	hmu := make([]string, len(BufListSorts))
+	for i, col := range BufListSorts {
+		hdr[i] = col
+		if col == bv.SortBy || (bv.SortBy == "" && i == 0) {
+			hdr[i] += " ^"
+			if bv.Desc {
+				hdr[i] = col + " v"
+			}
+		}
+		hmu[i] = fmt.Sprintf(`<a href="buflist:///sort?by=%v">%v</a>`, col, hdr[i])
+	}
+	lstr = "sort by: " + strings.Join(hdr, "  ")
+	outlns = append(outlns, []byte(lstr), []byte(""))
+	outmus = append(outmus, []byte("sort by: "+strings.Join(hmu, "  ")), []byte(""))
+
+	for _, bi := range bv.Bufs {
+		pe := url.QueryEscape(bi.Path)
+		chk := "[ ]"
+		if bv.Sel[bi.Path] {
+			chk = "[x]"
+		}
+		mod := " "
+		if bi.Modified {
+			mod = "*"
+		}
+		rp := ge.Files.RelPath(gi.FileName(bi.Path))
+		info := fmt.Sprintf("%d bytes  %v", bi.Size, bi.Lang)
+		outlns = append(outlns, []byte(fmt.Sprintf("%v %v %v  %v", chk, mod, rp, info)))
+		outmus = append(outmus, []byte(fmt.Sprintf(`<a href="buflist:///select?path=%v">%v</a> %v <a href="buflist:///view?path=%v">%v</a>  %v`, pe, chk, mod, pe, html.EscapeString(rp), html.EscapeString(info))))
+	}
+
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	tbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+}
+
+// OpenBufListURL views, selects or sorts by what is in given buflist:/// url
+func (bv *BufListView) OpenBufListURL(ur string, btv *giv.TextView) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("BufListView OpenBufListURL parse err: %v\n", err)
+		return false
+	}
+	q := up.Query()
+	switch up.Path[1:] { // has double //
+	case "view":
+		ond, ok := bv.Gide.OpenNodeByPath(q.Get("path"))
+		if !ok {
+			bv.Refresh()
+			return false
+		}
+		bv.Gide.NextViewFileNode(ond)
+	case "select":
+		p := q.Get("path")
+		if bv.Sel == nil {
+			bv.Sel = make(map[string]bool)
+		}
+		if bv.Sel[p] {
+			delete(bv.Sel, p)
+		} else {
+			bv.Sel[p] = true
+		}
+		bv.ShowResults()
+	case "sort":
+		by := q.Get("by")
+		if by == bv.SortBy || (bv.SortBy == "" && by == BufListSorts[0]) {
+			bv.Desc = !bv.Desc
+		} else {
+			bv.SortBy = by
+			bv.Desc = false
+		}
+		bv.Refresh()
+	default:
+		return false
+	}
+	return true
+}
+
+// SelectAll selects all of the listed buffers, or none of them if they are
+// all selected already
+func (bv *BufListView) SelectAll() {
+	all := len(bv.Bufs) > 0
+	for _, bi := range bv.Bufs {
+		if !bv.Sel[bi.Path] {
+			all = false
+			break
+		}
+	}
+	bv.Sel = make(map[string]bool)
+	if !all {
+		for _, bi := range bv.Bufs {
+			bv.Sel[bi.Path] = true
+		}
+	}
+	bv.ShowResults()
+}
+
+// SelOpenNodes returns the open nodes of the selected buffers, in the
+// order they are listed
+func (bv *BufListView) SelOpenNodes() []*giv.FileNode {
+	var onds []*giv.FileNode
+	for _, bi := range bv.Bufs {
+		if !bv.Sel[bi.Path] {
+			continue
+		}
+		if ond, ok := bv.Gide.OpenNodeByPath(bi.Path); ok {
+			onds = append(onds, ond)
+		}
+	}
+	if len(onds) == 0 {
+		bv.Gide.SetStatus("No buffers selected -- click on the [ ] in front of them to select them")
+	}
+	return onds
+}
+
+// CloseSel closes the selected buffers, asking to save those with unsaved
+// changes
+func (bv *BufListView) CloseSel() {
+	for _, ond := range bv.SelOpenNodes() {
+		bv.Gide.CloseOpenNode(ond)
+	}
+	bv.Refresh()
+}
+
+// SaveSel saves the selected buffers that have unsaved changes
+func (bv *BufListView) SaveSel() {
+	n := 0
+	for _, ond := range bv.SelOpenNodes() {
+		if ond.Buf.IsChanged() {
+			bv.Gide.SaveOpenNode(ond)
+			n++
+		}
+	}
+	bv.Gide.SetStatus(fmt.Sprintf("Saved %d buffers", n))
+	bv.Refresh()
+}
+
+// RevertSel reverts the selected buffers to their saved versions, after
+// confirming if any of them have unsaved changes, which are lost
+func (bv *BufListView) RevertSel() {
+	onds := bv.SelOpenNodes()
+	if len(onds) == 0 {
+		return
+	}
+	revert := func() {
+		ge := bv.Gide
+		for _, ond := range onds {
+			ge.ConfigTextBuf(ond.Buf)
+			ond.Buf.Revert()
+			ge.BufOpened(ond.Buf)
+		}
+		ge.SetStatus(fmt.Sprintf("Reverted %d buffers", len(onds)))
+		bv.Refresh()
+	}
+	nch := 0
+	for _, ond := range onds {
+		if ond.Buf.IsChanged() {
+			nch++
+		}
+	}
+	if nch == 0 {
+		revert()
+		return
+	}
+	gi.PromptDialog(bv.Gide.Viewport, gi.DlgOpts{Title: "Revert Buffers", Prompt: fmt.Sprintf("Revert %d buffers to their saved versions?  The unsaved changes in %d of them will be lost.", len(onds), nch)}, true, true, bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.DialogAccepted) {
+			revert()
+		}
+	})
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (bv *BufListView) UpdateView(ge *Gide) {
+	bv.Gide = ge
+	mods, updt := bv.StdBufListConfig()
+	bv.ConfigToolbar()
+	tvly := bv.TextViewLay()
+	bv.Gide.ConfigOutputTextView(tvly)
+	if mods {
+		bv.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (bv *BufListView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "buflistbar")
+	config.Add(gi.KiT_Layout, "buflisttext")
+	return config
+}
+
+// StdBufListConfig configures a standard setup of the overall layout --
+// returns mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (bv *BufListView) StdBufListConfig() (mods, updt bool) {
+	bv.Lay = gi.LayoutVert
+	bv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := bv.StdConfig()
+	mods, updt = bv.ConfigChildren(config, false)
+	return
+}
+
+// BufListBar returns the buffer list toolbar
+func (bv *BufListView) BufListBar() *gi.ToolBar {
+	tbi, ok := bv.ChildByName("buflistbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// TextViewLay returns the buffer list TextView layout
+func (bv *BufListView) TextViewLay() *gi.Layout {
+	tvi, ok := bv.ChildByName("buflisttext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the buffer list TextView
+func (bv *BufListView) TextView() *giv.TextView {
+	tvly := bv.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (bv *BufListView) ConfigToolbar() {
+	tb := bv.BufListBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	refresh := tb.AddNewChild(gi.KiT_Action, "refresh").(*gi.Action)
+	refresh.SetText("Refresh")
+	refresh.Tooltip = "list the open buffers again"
+	refresh.ActionSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		bvv, _ := recv.Embed(KiT_BufListView).(*BufListView)
+		bvv.Refresh()
+	})
+
+	all := tb.AddNewChild(gi.KiT_Action, "select-all").(*gi.Action)
+	all.SetText("Select All")
+	all.Tooltip = "select all of the listed buffers, or none if they are all selected"
+	all.ActionSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		bvv, _ := recv.Embed(KiT_BufListView).(*BufListView)
+		bvv.SelectAll()
+	})
+
+	save := tb.AddNewChild(gi.KiT_Action, "save").(*gi.Action)
+	save.SetText("Save")
+	save.Tooltip = "save the selected buffers that have unsaved changes"
+	save.ActionSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		bvv, _ := recv.Embed(KiT_BufListView).(*BufListView)
+		bvv.SaveSel()
+	})
+
+	revert := tb.AddNewChild(gi.KiT_Action, "revert").(*gi.Action)
+	revert.SetText("Revert")
+	revert.Tooltip = "revert the selected buffers to their saved versions, losing any unsaved changes"
+	revert.ActionSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		bvv, _ := recv.Embed(KiT_BufListView).(*BufListView)
+		bvv.RevertSel()
+	})
+
+	cls := tb.AddNewChild(gi.KiT_Action, "close").(*gi.Action)
+	cls.SetText("Close")
+	cls.Tooltip = "close the selected buffers, asking to save those with unsaved changes"
+	cls.ActionSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		bvv, _ := recv.Embed(KiT_BufListView).(*BufListView)
+		bvv.CloseSel()
+	})
+
+	filter := tb.AddNewChild(gi.KiT_TextField, "filter").(*gi.TextField)
+	filter.SetStretchMaxWidth()
+	filter.Tooltip = "Only show the buffers whose path or language contains this, ignoring case -- hit enter to filter, clear to show them all"
+	filter.TextFieldSig.Connect(bv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			bvv, _ := recv.Embed(KiT_BufListView).(*BufListView)
+			bvv.Filter = strings.TrimSpace(send.(*gi.TextField).Text())
+			bvv.Refresh()
+		}
+	})
+}
+
+var BufListViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"testing"
+)
+
+func TestSortFilterBufInfos(t *testing.T) {
+	bis := []BufInfo{
+		{Path: "/p/b.go", Size: 30, Lang: "Go", Recent: 0},
+		{Path: "/p/a.md", Size: 10, Modified: true, Lang: "Markdown", Recent: 1},
+		{Path: "/q/c.go", Size: 20, Lang: "Go", Recent: 2},
+	}
+	paths := func(bis []BufInfo) string {
+		s := ""
+		for _, bi := range bis {
+			s += bi.Path + " "
+		}
+		return s
+	}
+	tests := []struct {
+		by   string
+		desc bool
+		want string
+	}{
+		{"Path", false, "/p/a.md /p/b.go /q/c.go "},
+		{"Size", true, "/p/b.go /q/c.go /p/a.md "},
+		{"Modified", false, "/p/a.md /p/b.go /q/c.go "},
+		{"Lang", false, "/p/b.go /q/c.go /p/a.md "},
+		{"Recent", false, "/p/b.go /p/a.md /q/c.go "},
+	}
+	for _, tt := range tests {
+		SortBufInfos(bis, tt.by, tt.desc)
+		if got := paths(bis); got != tt.want {
+			t.Errorf("SortBufInfos(%v, %v) = %v, want %v", tt.by, tt.desc, got, tt.want)
+		}
+	}
+	if got := paths(FilterBufInfos(bis, "GO")); got != "/p/b.go /q/c.go " {
+		t.Errorf("FilterBufInfos GO = %v", got)
+	}
+	if got := paths(FilterBufInfos(bis, "/q/")); got != "/q/c.go " {
+		t.Errorf("FilterBufInfos /q/ = %v", got)
+	}
+	if got := FilterBufInfos(bis, ""); len(got) != 3 {
+		t.Errorf("FilterBufInfos empty = %v", got)
+	}
+}
@@ -0,0 +1,428 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+)
+
+// BufTabs is the order of the tabs of the buffer tab bar, by the paths of
+// their files: the pinned tabs first, then the others, in the order they
+// were opened or dragged to
+type BufTabs struct {
+	Paths  []string        `desc:"paths of the files of the tabs, in order"`
+	Pinned map[string]bool `desc:"paths of the pinned tabs"`
+}
+
+// NPinned returns the number of pinned tabs, which are the first ones
+func (bt *BufTabs) NPinned() int {
+	n := 0
+	for _, p := range bt.Paths {
+		if bt.Pinned[p] {
+			n++
+		}
+	}
+	return n
+}
+
+// Index returns the index of the tab of given path, or -1 if there is none
+func (bt *BufTabs) Index(path string) int {
+	for i, p := range bt.Paths {
+		if p == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// Sync updates the tabs to given paths of the open buffers, in any order:
+// the tabs of closed buffers are removed, and those of new ones are added
+// at the end -- pinned returns whether a new one was pinned before, e.g.,
+// in an earlier session, in which case it is added after the pinned tabs
+func (bt *BufTabs) Sync(open []string, pinned func(path string) bool) {
+	isOpen := make(map[string]bool, len(open))
+	for _, p := range open {
+		isOpen[p] = true
+	}
+	keep := bt.Paths[:0]
+	for _, p := range bt.Paths {
+		if isOpen[p] {
+			keep = append(keep, p)
+		} else {
+			delete(bt.Pinned, p)
+		}
+	}
+	bt.Paths = keep
+	for _, p := range open {
+		if bt.Index(p) >= 0 {
+			continue
+		}
+		bt.Paths = append(bt.Paths, p)
+		if pinned != nil && pinned(p) {
+			bt.SetPinned(p, true)
+		}
+	}
+}
+
+// SetPinned pins or unpins the tab of given path, moving it to the end of
+// the pinned tabs, or to the start of the others
+func (bt *BufTabs) SetPinned(path string, pin bool) {
+	i := bt.Index(path)
+	if i < 0 || bt.Pinned[path] == pin {
+		return
+	}
+	if bt.Pinned == nil {
+		bt.Pinned = make(map[string]bool)
+	}
+	bt.Paths = append(bt.Paths[:i], bt.Paths[i+1:]...)
+	np := bt.NPinned()
+	if pin {
+		bt.Pinned[path] = true
+	} else {
+		delete(bt.Pinned, path)
+	}
+	bt.Paths = append(bt.Paths, "")
+	copy(bt.Paths[np+1:], bt.Paths[np:])
+	bt.Paths[np] = path
+}
+
+// Move moves the tab of given path to given index, within the pinned tabs
+// if it is pinned, and within the others if not
+func (bt *BufTabs) Move(path string, to int) {
+	i := bt.Index(path)
+	if i < 0 {
+		return
+	}
+	np := bt.NPinned()
+	lo, hi := np, len(bt.Paths)-1
+	if bt.Pinned[path] {
+		lo, hi = 0, np-1
+	}
+	if to < lo {
+		to = lo
+	}
+	if to > hi {
+		to = hi
+	}
+	if to == i {
+		return
+	}
+	bt.Paths = append(bt.Paths[:i], bt.Paths[i+1:]...)
+	bt.Paths = append(bt.Paths, "")
+	copy(bt.Paths[to+1:], bt.Paths[to:])
+	bt.Paths[to] = path
+}
+
+// Step returns the path of the tab delta tabs from that of given path,
+// wrapping around -- the first tab if given path has none
+func (bt *BufTabs) Step(path string, delta int) string {
+	n := len(bt.Paths)
+	if n == 0 {
+		return ""
+	}
+	i := bt.Index(path)
+	if i < 0 {
+		return bt.Paths[0]
+	}
+	return bt.Paths[((i+delta)%n+n)%n]
+}
+
+// Others returns the paths of the tabs that are not pinned, other than
+// that of given path, for Close Other Tabs
+func (bt *BufTabs) Others(path string) []string {
+	var ps []string
+	for _, p := range bt.Paths {
+		if p != path && !bt.Pinned[p] {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// BufTabBar returns the buffer tab bar, above the text views, or nil if it
+// is turned off in the prefs
+func (ge *Gide) BufTabBar() *gi.ToolBar {
+	tbi, ok := ge.ChildByName("buftabs", 1)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// ConfigBufTabBar configures the buffer tab bar
+func (ge *Gide) ConfigBufTabBar() {
+	tb := ge.BufTabBar()
+	if tb == nil {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.SetMinPrefHeight(units.NewValue(1.5, units.Em))
+	tb.SetProp("overflow", "hidden")
+	tb.SetProp("spacing", 0)
+	tb.SetProp("padding", 0)
+	ge.bufTabsKey = ""
+	ge.BufTabsUpdate()
+}
+
+// BufTabs returns the order of the tabs of the buffer tab bar, synced to
+// the open buffers
+func (ge *Gide) BufTabs() *BufTabs {
+	if ge.bufTabs == nil {
+		ge.bufTabs = &BufTabs{}
+	}
+	ge.OpenNodes.DeleteDeleted()
+	open := make([]string, 0, len(ge.OpenNodes))
+	for i := len(ge.OpenNodes) - 1; i >= 0; i-- { // least recent first
+		open = append(open, string(ge.OpenNodes[i].FPath))
+	}
+	ge.bufTabs.Sync(open, func(path string) bool {
+		return ge.TabPinnedPref(path) >= 0
+	})
+	return ge.bufTabs
+}
+
+// TabPinnedPref returns the index of given path in the pinned tabs of the
+// project prefs, or -1 if it is not there
+func (ge *Gide) TabPinnedPref(path string) int {
+	rp := refRelPath(string(ge.ProjRoot), path)
+	for i, p := range ge.Prefs.PinnedTabs {
+		if p == rp {
+			return i
+		}
+	}
+	return -1
+}
+
+// bufTabNode returns the open node of given path
+func (ge *Gide) bufTabNode(path string) *giv.FileNode {
+	for _, ond := range ge.OpenNodes {
+		if string(ond.FPath) == path {
+			return ond
+		}
+	}
+	return nil
+}
+
+// BufTabsUpdate shows the open buffers in the buffer tab bar, marking the
+// one in the active view, the pinned ones, and those with unsaved changes
+// -- it is only redrawn if any of that has changed -- called when buffers
+// are opened, closed, edited, saved, and viewed
+func (ge *Gide) BufTabsUpdate() {
+	tb := ge.BufTabBar()
+	if tb == nil {
+		return
+	}
+	bt := ge.BufTabs()
+	act := ""
+	if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil {
+		act = string(tv.Buf.Filename)
+	}
+	key := act
+	for _, p := range bt.Paths {
+		key += fmt.Sprintf("\n%v %v", p, bt.Pinned[p])
+		if ond := ge.bufTabNode(p); ond != nil && ond.IsChanged() {
+			key += " *"
+		}
+	}
+	if key == ge.bufTabsKey {
+		return
+	}
+	ge.bufTabsKey = key
+
+	updt := tb.UpdateStart()
+	tb.SetFullReRender()
+	tb.DeleteChildren(true)
+	for i, p := range bt.Paths {
+		ond := ge.bufTabNode(p)
+		if ond == nil {
+			continue
+		}
+		path := p
+		lbl := html.EscapeString(ond.Nm)
+		if ond.IsChanged() {
+			lbl += " *"
+		}
+		if p == act {
+			lbl = "<b>" + lbl + "</b>"
+		}
+		ac := tb.AddNewChild(gi.KiT_Action, fmt.Sprintf("tab-%d", i)).(*gi.Action)
+		ac.SetText(lbl)
+		ac.Tooltip = ge.Files.RelPath(gi.FileName(p)) + " -- click to view, drag to reorder, middle-click to close, right-click for more"
+		if bt.Pinned[p] {
+			ac.SetIcon("star")
+			ac.Tooltip += " (pinned)"
+		}
+		ac.ActionSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_Gide).(*Gide)
+			gee.ViewBufTab(path)
+		})
+		ac.ConnectEvent(oswin.MouseEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			me := d.(*mouse.Event)
+			ge.BufTabMouse(path, me)
+		})
+		if bt.Pinned[p] {
+			continue
+		}
+		cl := tb.AddNewChild(gi.KiT_Action, fmt.Sprintf("tab-close-%d", i)).(*gi.Action)
+		cl.SetIcon("close")
+		cl.Tooltip = "close " + ond.Nm
+		cl.ActionSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_Gide).(*Gide)
+			gee.CloseBufTab(path)
+		})
+	}
+	tb.UpdateEnd(updt)
+}
+
+// BufTabMouse handles the mouse events on the tab of given path that are
+// not clicks: middle-click closes it, right-click shows its menu, and a
+// left button released on it after being pressed on another tab moves that
+// tab to it
+func (ge *Gide) BufTabMouse(path string, me *mouse.Event) {
+	switch {
+	case me.Button == mouse.Middle && me.Action == mouse.Release:
+		me.SetProcessed()
+		ge.CloseBufTab(path)
+	case me.Button == mouse.Right && me.Action == mouse.Press:
+		me.SetProcessed()
+		ge.BufTabMenu(path, me.Where.X, me.Where.Y)
+	case me.Button == mouse.Left && me.Action == mouse.Press:
+		ge.bufTabDrag = path
+	case me.Button == mouse.Left && me.Action == mouse.Release:
+		from := ge.bufTabDrag
+		ge.bufTabDrag = ""
+		if from == "" || from == path {
+			return
+		}
+		me.SetProcessed()
+		bt := ge.BufTabs()
+		bt.Move(from, bt.Index(path))
+		ge.BufTabsUpdate()
+	}
+}
+
+// BufTabMenu shows the menu of the tab of given path at given position
+func (ge *Gide) BufTabMenu(path string, x, y int) {
+	tb := ge.BufTabBar()
+	if tb == nil {
+		return
+	}
+	pin := "Pin Tab"
+	if ge.BufTabs().Pinned[path] {
+		pin = "Unpin Tab"
+	}
+	var m gi.Menu
+	m.AddAction(gi.ActOpts{Label: pin}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ge.PinBufTab(path)
+	})
+	m.AddAction(gi.ActOpts{Label: "Close Tab"}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ge.CloseBufTab(path)
+	})
+	m.AddAction(gi.ActOpts{Label: "Close Other Tabs"}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ge.CloseOtherBufTabs(path)
+	})
+	gi.PopupMenu(m, x, y, tb.Viewport, "buf-tab-menu")
+}
+
+// ViewBufTab views the buffer of the tab of given path in the active view
+func (ge *Gide) ViewBufTab(path string) {
+	ond := ge.bufTabNode(path)
+	if ond == nil {
+		return
+	}
+	tv := ge.ActiveTextView()
+	ge.ViewFileNode(tv, ge.ActiveTextViewIdx, ond)
+	tv.GrabFocus()
+}
+
+// CloseBufTab closes the buffer of the tab of given path, asking to save it
+// if it has unsaved changes
+func (ge *Gide) CloseBufTab(path string) {
+	if ond := ge.bufTabNode(path); ond != nil {
+		ge.CloseOpenNode(ond)
+	}
+}
+
+// PinBufTab pins the tab of given path, or unpins it if it is pinned:
+// pinned tabs are kept first, are not closed by Close Other Tabs, and are
+// pinned again when their files are next opened in the project
+func (ge *Gide) PinBufTab(path string) {
+	bt := ge.BufTabs()
+	pin := !bt.Pinned[path]
+	bt.SetPinned(path, pin)
+	if pi := ge.TabPinnedPref(path); pin && pi < 0 {
+		ge.Prefs.PinnedTabs = append(ge.Prefs.PinnedTabs, refRelPath(string(ge.ProjRoot), path))
+	} else if !pin && pi >= 0 {
+		ge.Prefs.PinnedTabs = append(ge.Prefs.PinnedTabs[:pi], ge.Prefs.PinnedTabs[pi+1:]...)
+	}
+	ge.Prefs.Changed = true
+	ge.BufTabsUpdate()
+}
+
+// CloseOtherBufTabs closes the buffers of the tabs other than that of
+// given path, except for the pinned ones
+func (ge *Gide) CloseOtherBufTabs(path string) {
+	for _, p := range ge.BufTabs().Others(path) {
+		ge.CloseBufTab(p)
+	}
+}
+
+// activeBufPath returns the path of the buffer of the active view, if any
+func (ge *Gide) activeBufPath() string {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return ""
+	}
+	return string(tv.Buf.Filename)
+}
+
+// NextBufTab views the buffer of the next tab in the active view, wrapping
+// around to the first one
+func (ge *Gide) NextBufTab() {
+	if p := ge.BufTabs().Step(ge.activeBufPath(), 1); p != "" {
+		ge.ViewBufTab(p)
+	}
+}
+
+// PrevBufTab views the buffer of the previous tab in the active view,
+// wrapping around to the last one
+func (ge *Gide) PrevBufTab() {
+	if p := ge.BufTabs().Step(ge.activeBufPath(), -1); p != "" {
+		ge.ViewBufTab(p)
+	}
+}
+
+// CloseOtherTabs closes the buffers other than that of the active view,
+// except for those of pinned tabs
+func (ge *Gide) CloseOtherTabs() {
+	act := ge.activeBufPath()
+	if act == "" {
+		return
+	}
+	n := len(ge.BufTabs().Others(act))
+	ge.CloseOtherBufTabs(act)
+	ge.SetStatus(fmt.Sprintf("Closing %d other buffers, keeping %v and the pinned ones", n, filepath.Base(act)))
+}
+
+// PinActiveTab pins the tab of the buffer of the active view, or unpins it
+func (ge *Gide) PinActiveTab() {
+	if act := ge.activeBufPath(); act != "" {
+		ge.PinBufTab(act)
+		if ge.BufTabs().Pinned[act] {
+			ge.SetStatus("Pinned " + filepath.Base(act))
+		} else {
+			ge.SetStatus("Unpinned " + filepath.Base(act))
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBufTabs(t *testing.T) {
+	bt := &BufTabs{}
+	bt.Sync([]string{"a", "b", "c"}, nil)
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(bt.Paths, want) {
+		t.Errorf("Sync: %v, want %v", bt.Paths, want)
+	}
+	bt.SetPinned("c", true)
+	if want := []string{"c", "a", "b"}; !reflect.DeepEqual(bt.Paths, want) || bt.NPinned() != 1 {
+		t.Errorf("SetPinned: %v, want %v", bt.Paths, want)
+	}
+	bt.Move("c", 2) // pinned tabs stay first
+	if want := []string{"c", "a", "b"}; !reflect.DeepEqual(bt.Paths, want) {
+		t.Errorf("Move pinned: %v, want %v", bt.Paths, want)
+	}
+	bt.Move("b", 0) // not before the pinned tabs
+	if want := []string{"c", "b", "a"}; !reflect.DeepEqual(bt.Paths, want) {
+		t.Errorf("Move: %v, want %v", bt.Paths, want)
+	}
+	if got := bt.Others("b"); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("Others: %v, want [a]", got)
+	}
+	if got := bt.Step("c", -1); got != "a" {
+		t.Errorf("Step back: %v, want a", got)
+	}
+	if got := bt.Step("a", 1); got != "c" {
+		t.Errorf("Step: %v, want c", got)
+	}
+	bt.Sync([]string{"d", "a", "c"}, func(p string) bool { return p == "d" })
+	if want := []string{"c", "d", "a"}; !reflect.DeepEqual(bt.Paths, want) || !bt.Pinned["d"] {
+		t.Errorf("Sync closed and pinned: %v, want %v", bt.Paths, want)
+	}
+	bt.SetPinned("c", false)
+	if want := []string{"d", "c", "a"}; !reflect.DeepEqual(bt.Paths, want) || bt.NPinned() != 1 {
+		t.Errorf("unpin: %v, want %v", bt.Paths, want)
+	}
+}
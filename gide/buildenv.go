@@ -0,0 +1,121 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// BuildEnv contains the build environment settings for a project, which are
+// applied to all the commands (builds, tests, lints, etc) run from Gide
+type BuildEnv struct {
+	GOOS    string   `desc:"target operating system (GOOS) -- leave empty for the host system"`
+	GOARCH  string   `desc:"target architecture (GOARCH) -- leave empty for the host architecture"`
+	Tags    []string `desc:"build tags -- passed to the go tool via GOFLAGS as -tags"`
+	GOFLAGS string   `desc:"additional flags for the go tool (GOFLAGS), e.g., -mod=vendor"`
+}
+
+// BuildTargets are the standard GOOS/GOARCH targets available in the target
+// switcher -- "host" clears the target
+var BuildTargets = []string{"host", "linux/amd64", "linux/arm64", "linux/arm", "darwin/amd64", "darwin/arm64", "windows/amd64", "windows/386", "freebsd/amd64", "js/wasm"}
+
+// CmdBuildEnv is the build environment applied to commands when they are
+// run -- set from project prefs along with the ArgVarVals
+var CmdBuildEnv BuildEnv
+
+// IsEmpty returns true if nothing is set, so the host environment is used as-is
+func (be *BuildEnv) IsEmpty() bool {
+	return be.GOOS == "" && be.GOARCH == "" && len(be.Tags) == 0 && be.GOFLAGS == ""
+}
+
+// Target returns the GOOS/GOARCH target, with "host" for any unset part
+func (be *BuildEnv) Target() string {
+	if be.GOOS == "" && be.GOARCH == "" {
+		return "host"
+	}
+	gos, arch := be.GOOS, be.GOARCH
+	if gos == "" {
+		gos = "host"
+	}
+	if arch == "" {
+		arch = "host"
+	}
+	return gos + "/" + arch
+}
+
+// SetTarget sets the GOOS/GOARCH from a target string of the form os/arch --
+// "host" or empty clears both, and a "host" os or arch clears that one, as
+// in the targets returned by Target
+func (be *BuildEnv) SetTarget(targ string) {
+	targ = strings.TrimSpace(targ)
+	gos, arch := targ, ""
+	if ci := strings.Index(targ, "/"); ci >= 0 {
+		gos, arch = targ[:ci], targ[ci+1:]
+	}
+	host := func(s string) string {
+		if s = strings.TrimSpace(s); s == "host" {
+			return ""
+		}
+		return s
+	}
+	be.GOOS = host(gos)
+	be.GOARCH = host(arch)
+}
+
+// Label returns a short label for the environment, e.g., for the status bar,
+// as target plus any tags, e.g., linux/arm64 +netgo,osusergo
+func (be *BuildEnv) Label() string {
+	lbl := be.Target()
+	if len(be.Tags) > 0 {
+		lbl += " +" + strings.Join(be.Tags, ",")
+	}
+	return lbl
+}
+
+// Flags returns the full GOFLAGS value, including the tags
+func (be *BuildEnv) Flags() string {
+	fl := be.GOFLAGS
+	if len(be.Tags) > 0 {
+		tf := "-tags=" + strings.Join(be.Tags, ",")
+		if fl != "" {
+			fl += " " + tf
+		} else {
+			fl = tf
+		}
+	}
+	return fl
+}
+
+// Env returns the environment variables (as NAME=value) for the settings
+// that are set -- empty if none
+func (be *BuildEnv) Env() []string {
+	var env []string
+	if be.GOOS != "" {
+		env = append(env, "GOOS="+be.GOOS)
+	}
+	if be.GOARCH != "" {
+		env = append(env, "GOARCH="+be.GOARCH)
+	}
+	if fl := be.Flags(); fl != "" {
+		env = append(env, "GOFLAGS="+fl)
+	}
+	return env
+}
+
+// SetCmdEnv sets the environment of given command to the current process
+// environment plus these settings, and those for working offline (see
+// OfflineEnv), which take precedence -- does nothing if they are empty
+func (be *BuildEnv) SetCmdEnv(cmd *exec.Cmd) {
+	env := append(be.Env(), OfflineEnv()...)
+	if len(env) == 0 {
+		return
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, env...) // later values override earlier ones
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestBuildEnv(t *testing.T) {
+	be := BuildEnv{}
+	if !be.IsEmpty() || be.Label() != "host" {
+		t.Errorf("empty BuildEnv: %v", be.Label())
+	}
+	cmd := exec.Command("go", "build")
+	be.SetCmdEnv(cmd)
+	if cmd.Env != nil {
+		t.Errorf("empty BuildEnv should not set cmd.Env: %v", cmd.Env)
+	}
+
+	be.SetTarget("linux/arm64")
+	be.Tags = []string{"netgo", "osusergo"}
+	be.GOFLAGS = "-mod=vendor"
+	if be.GOOS != "linux" || be.GOARCH != "arm64" {
+		t.Errorf("SetTarget: %v %v", be.GOOS, be.GOARCH)
+	}
+	lbl := be.Label()
+	if lbl != "linux/arm64 +netgo,osusergo" {
+		t.Errorf("Label: %v", lbl)
+	}
+	env := strings.Join(be.Env(), " ")
+	cv := "GOOS=linux GOARCH=arm64 GOFLAGS=-mod=vendor -tags=netgo,osusergo"
+	if env != cv {
+		t.Errorf("Env: %v != %v", env, cv)
+	}
+	be.SetCmdEnv(cmd)
+	if n := len(cmd.Env); n < 3 || cmd.Env[n-3] != "GOOS=linux" {
+		t.Errorf("SetCmdEnv: %v", cmd.Env)
+	}
+
+	be.SetTarget("host")
+	if be.Target() != "host" {
+		t.Errorf("SetTarget host: %v", be.Target())
+	}
+	be.GOOS = "linux"
+	be.SetTarget(be.Target())
+	if be.GOOS != "linux" || be.GOARCH != "" {
+		t.Errorf("SetTarget of Target %v: %q %q", be.Target(), be.GOOS, be.GOARCH)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// CgoPreamble returns the range of lines [st, ed) of the cgo preamble in
+// given lines of a Go file: the comment immediately before import "C",
+// either a /* */ block or a run of // lines -- for a block, the lines of
+// the /* and */ are included, and ok is false if there is no import "C"
+func CgoPreamble(lines [][]rune) (st, ed int, block bool, ok bool) {
+	imp := -1
+	for i, l := range lines {
+		s := strings.TrimSpace(string(l))
+		if s == `import "C"` {
+			imp = i
+			break
+		}
+		if strings.HasPrefix(s, "func ") || strings.HasPrefix(s, "type ") {
+			break // past the imports
+		}
+	}
+	if imp <= 0 {
+		return
+	}
+	ed = imp
+	last := strings.TrimSpace(string(lines[imp-1]))
+	switch {
+	case strings.HasSuffix(last, "*/"):
+		for st = imp - 1; st >= 0; st-- {
+			if strings.HasPrefix(strings.TrimSpace(string(lines[st])), "/*") {
+				return st, ed, true, true
+			}
+		}
+		return 0, 0, false, false
+	case strings.HasPrefix(last, "//"):
+		for st = imp - 1; st > 0 && strings.HasPrefix(strings.TrimSpace(string(lines[st-1])), "//"); st-- {
+		}
+		return st, ed, false, true
+	}
+	return
+}
+
+// CMarkup returns the highlighting markup for given C source, one per line,
+// using the same css classes as for the highlighting of whole files
+func CMarkup(src string) [][]byte {
+	lx := lexers.Get("c")
+	if lx == nil {
+		return nil
+	}
+	hs := &HiState{Tokenize: ChromaTokenizer(lx)}
+	hs.Update(strings.Split(src, "\n"))
+	return hs.Markup
+}
+
+// CgoMarkup re-highlights the cgo preamble of given buffer, if it is a Go
+// file with one, as C instead of as a Go comment -- called when the markup
+// of the buffer is updated
+func (ge *Gide) CgoMarkup(tb *giv.TextBuf) {
+	if filepath.Ext(string(tb.Filename)) != ".go" {
+		return
+	}
+	st, ed, block, ok := CgoPreamble(tb.Lines)
+	if !ok {
+		return
+	}
+	if block { // leave the /* and */ lines as comments
+		st++
+		ed--
+	}
+	if st >= ed || ed > len(tb.Markup) {
+		return
+	}
+	pfx := make([]string, ed-st)
+	src := make([]string, ed-st)
+	for i := range src {
+		l := string(tb.Lines[st+i])
+		if !block {
+			ci := strings.Index(l, "//") + 2
+			pfx[i] = `<span class="c1">` + html.EscapeString(l[:ci]) + `</span>`
+			l = l[ci:]
+		}
+		src[i] = l
+	}
+	mu := CMarkup(strings.Join(src, "\n"))
+	if len(mu) != len(src) {
+		return
+	}
+	for i := range mu {
+		tb.Markup[st+i] = append([]byte(pfx[i]), mu[i]...)
+	}
+}
+
+// cgoLineRe matches the #line directives that cgo puts in the C files it
+// generates, giving the Go file and line of the preamble code that follows
+var cgoLineRe = regexp.MustCompile(`^#line (\d+) "([^"]+)"`)
+
+// CgoLineMap returns the Go file and 1-based line of the preamble code at
+// given 1-based line of a C file generated by cgo, with the given contents,
+// using its #line directives -- ok is false if the line is not from a Go file
+func CgoLineMap(src []byte, line int) (file string, ln int, ok bool) {
+	for i, l := range bytes.Split(src, []byte("\n")) {
+		if i+1 >= line {
+			break
+		}
+		m := cgoLineRe.FindSubmatch(l)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(string(m[1]))
+		file, ln = string(m[2]), n-(i+2)
+	}
+	if file == "" || !strings.HasSuffix(file, ".go") {
+		return "", 0, false
+	}
+	return file, ln + line, true
+}
+
+// cgoIncludedRe matches the "In file included from" lines of gcc and clang,
+// which give the location of the #include in the preamble of a Go file
+var cgoIncludedRe = regexp.MustCompile(`^In file included from ([^\s:]+\.go):(\d+)[:,]`)
+
+// CgoFixErrors routes the errors in given list that are in C files
+// generated by cgo back to the preamble lines of the Go files they came
+// from, when the generated files are still around (e.g., go build -work),
+// and adds the #include locations in Go files from gcc output
+func CgoFixErrors(errs []CmdError, out []byte, dir string) []CmdError {
+	for i := range errs {
+		ce := &errs[i]
+		if !strings.Contains(filepath.Base(ce.Path), ".cgo") && filepath.Ext(ce.Path) != ".c" {
+			continue
+		}
+		src, err := ioutil.ReadFile(ce.Path)
+		if err != nil {
+			continue
+		}
+		if fn, ln, ok := CgoLineMap(src, ce.Line); ok {
+			if !filepath.IsAbs(fn) {
+				fn = filepath.Join(dir, fn)
+			}
+			ce.Path, ce.Line = fn, ln
+		}
+	}
+	for _, l := range bytes.Split(out, []byte("\n")) {
+		m := cgoIncludedRe.FindSubmatch(l)
+		if m == nil {
+			continue
+		}
+		fn := string(m[1])
+		if !filepath.IsAbs(fn) {
+			fn = filepath.Join(dir, fn)
+		}
+		ln, _ := strconv.Atoi(string(m[2]))
+		errs = append(errs, CmdError{Path: fn, Line: ln, Msg: "C header included from here has errors"})
+	}
+	return errs
+}
+
+// cgoSymRe matches a reference to a C symbol from Go, e.g., C.malloc
+var cgoSymRe = regexp.MustCompile(`\bC\.([A-Za-z_]\w*)$`)
+
+// CgoSymbolAt returns the C name of the cgo reference (e.g., C.malloc or
+// C.struct_stat) at given position in line, if any -- struct_, union_ and
+// enum_ types are returned as C writes them, e.g., struct stat
+func CgoSymbolAt(line []rune, ch int) (string, bool) {
+	_, _, ed := WordAtPos(line, ch)
+	m := cgoSymRe.FindStringSubmatch(string(line[:ed]))
+	if m == nil {
+		return "", false
+	}
+	nm := m[1]
+	for _, kw := range []string{"struct", "union", "enum"} {
+		if strings.HasPrefix(nm, kw+"_") {
+			return kw + " " + strings.TrimPrefix(nm, kw+"_"), true
+		}
+	}
+	return nm, true
+}
+
+// IsCgoGenerated returns true if given file is Go code generated by cgo,
+// where definitions of C symbols found by Go tools are
+func IsCgoGenerated(fpath string) bool {
+	base := filepath.Base(fpath)
+	return strings.HasPrefix(base, "_cgo_") || strings.HasSuffix(base, ".cgo1.go")
+}
+
+// CgoDefAt finds the definition of the C symbol referenced at given
+// position of a Go file, if any, in the C headers included by its preamble,
+// using the language server configured for C (e.g., clangd) on a C file
+// made from the preamble
+func (ge *Gide) CgoDefAt(tb *giv.TextBuf, pos giv.TextPos) (NavPos, bool) {
+	if filepath.Ext(string(tb.Filename)) != ".go" || pos.Ln >= len(tb.Lines) {
+		return NavPos{}, false
+	}
+	sym, ok := CgoSymbolAt(tb.Lines[pos.Ln], pos.Ch)
+	if !ok {
+		return NavPos{}, false
+	}
+	st, ed, block, ok := CgoPreamble(tb.Lines)
+	if !ok {
+		return NavPos{}, false
+	}
+	var src bytes.Buffer
+	for ln := st; ln < ed; ln++ {
+		l := strings.TrimSpace(string(tb.Lines[ln]))
+		if block {
+			if ln == st || ln == ed-1 {
+				continue
+			}
+		} else {
+			l = strings.TrimPrefix(l, "//")
+		}
+		if strings.HasPrefix(strings.TrimSpace(l), "#cgo ") {
+			continue
+		}
+		src.WriteString(l + "\n")
+	}
+	refln := bytes.Count(src.Bytes(), []byte("\n"))
+	fmt.Fprintf(&src, "static void gide_cgo_ref(void) { %s; }\n", sym)
+	refch := len("static void gide_cgo_ref(void) { ") + strings.LastIndex(sym, " ") + 1
+
+	fpath := string(tb.Filename)
+	cpath := filepath.Join(filepath.Dir(fpath), ".gide-cgo-"+strings.TrimSuffix(filepath.Base(fpath), ".go")+".c")
+	lc := ge.Lsp.ClientForFile(cpath, string(ge.ProjRoot))
+	if lc == nil {
+		ge.SetStatus("No language server is running for C -- if one is configured it may still be starting, else add one for the C language (e.g., clangd) in Preferences LangServers to find C definitions")
+		return NavPos{}, false
+	}
+	lc.DidOpen(cpath, src.String())
+	defer lc.DidClose(cpath)
+	locs, err := lc.Definition(cpath, refln, refch)
+	if err != nil || len(locs) == 0 {
+		return NavPos{}, false
+	}
+	dpath := LspPath(locs[0].URI)
+	dp := locs[0].Range.Start
+	if dpath == cpath { // defined in the preamble itself
+		return NavPos{Filename: gi.FileName(fpath), Pos: giv.TextPos{Ln: ge.cgoPreambleLine(tb, dp.Line), Ch: dp.Character}}, true
+	}
+	return NavPos{Filename: gi.FileName(dpath), Pos: giv.TextPos{Ln: dp.Line, Ch: dp.Character}}, true
+}
+
+// cgoPreambleLine returns the line in given Go file of given line of the C
+// file made from its preamble by CgoDefAt
+func (ge *Gide) cgoPreambleLine(tb *giv.TextBuf, cln int) int {
+	st, ed, block, _ := CgoPreamble(tb.Lines)
+	if block {
+		st++
+		ed--
+	}
+	n := 0
+	for ln := st; ln < ed; ln++ {
+		if strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(tb.Lines[ln])), "//")), "#cgo ") {
+			continue
+		}
+		if n == cln {
+			return ln
+		}
+		n++
+	}
+	return st
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func cgoTestLines(src string) [][]rune {
+	var lines [][]rune
+	for _, l := range strings.Split(src, "\n") {
+		lines = append(lines, []rune(l))
+	}
+	return lines
+}
+
+func TestCgoPreamble(t *testing.T) {
+	block := cgoTestLines(`package foo
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+`)
+	st, ed, blk, ok := CgoPreamble(block)
+	if !ok || !blk || st != 2 || ed != 5 {
+		t.Errorf("CgoPreamble block: got %d %d %v %v", st, ed, blk, ok)
+	}
+	lns := cgoTestLines(`package foo
+
+// Foo is not in the preamble
+
+// #cgo LDFLAGS: -lm
+// #include <math.h>
+import "C"
+`)
+	st, ed, blk, ok = CgoPreamble(lns)
+	if !ok || blk || st != 4 || ed != 6 {
+		t.Errorf("CgoPreamble lines: got %d %d %v %v", st, ed, blk, ok)
+	}
+	if _, _, _, ok := CgoPreamble(cgoTestLines("package foo\n\nimport \"fmt\"\n")); ok {
+		t.Errorf("CgoPreamble: found one without import \"C\"")
+	}
+}
+
+func TestCgoSymbolAt(t *testing.T) {
+	ln := []rune("	p := C.malloc(C.size_t(n)) + C.struct_stat{}")
+	tests := map[int]string{8: "malloc", 17: "size_t", 38: "struct stat"}
+	for ch, want := range tests {
+		if got, ok := CgoSymbolAt(ln, ch); !ok || got != want {
+			t.Errorf("CgoSymbolAt %d: got %q %v, want %q", ch, got, ok, want)
+		}
+	}
+	if _, ok := CgoSymbolAt(ln, 1); ok {
+		t.Errorf("CgoSymbolAt: p is not a C symbol")
+	}
+}
+
+func TestCgoFixErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-cgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	gen := filepath.Join(dir, "foo.cgo2.c")
+	src := "// generated\n#line 4 \"/src/foo.go\"\n#include <stdlib.h>\nint bad = ;\n"
+	if err := ioutil.WriteFile(gen, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if fn, ln, ok := CgoLineMap([]byte(src), 4); !ok || fn != "/src/foo.go" || ln != 5 {
+		t.Errorf("CgoLineMap: got %v %v %v", fn, ln, ok)
+	}
+	if _, _, ok := CgoLineMap([]byte(src), 1); ok {
+		t.Errorf("CgoLineMap: line before any #line should not map")
+	}
+	out := []byte("In file included from ./bar.go:6:\n" + gen + ":4:11: error: expected expression\n")
+	errs := CgoFixErrors(ParseCmdErrors(out, "/src"), out, "/src")
+	if len(errs) != 2 {
+		t.Fatalf("CgoFixErrors: got %v", errs)
+	}
+	if errs[0].Path != "/src/foo.go" || errs[0].Line != 5 || errs[0].Col != 11 {
+		t.Errorf("CgoFixErrors mapped: got %v", errs[0])
+	}
+	if errs[1].Path != "/src/bar.go" || errs[1].Line != 6 {
+		t.Errorf("CgoFixErrors included: got %v", errs[1])
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki"
+)
+
+// ClipHistoryMax is the most copies shown in the clipboard history, after
+// the letter registers
+var ClipHistoryMax = 20
+
+// ClipPreviewLines is the most lines of the text of a register or copy
+// shown in its preview in the clipboard history
+var ClipPreviewLines = 20
+
+// RegisterLetter returns the letter register, a to z, for given key chord
+// typed after RegCopy or RegPaste, and whether the copy is appended to it,
+// for an uppercase letter -- ok is false for any other key
+func RegisterLetter(kc string) (reg string, appnd bool, ok bool) {
+	if strings.HasPrefix(kc, "Shift+") {
+		kc = strings.ToUpper(strings.TrimPrefix(kc, "Shift+"))
+	}
+	if len(kc) != 1 {
+		return "", false, false
+	}
+	c := kc[0]
+	switch {
+	case c >= 'a' && c <= 'z':
+		return kc, false, true
+	case c >= 'A' && c <= 'Z':
+		return strings.ToLower(kc), true, true
+	}
+	return "", false, false
+}
+
+// ClipItem is a register or copy in the clipboard history
+type ClipItem struct {
+	Reg  string `desc:"letter of the register -- empty for a copy"`
+	Text string `desc:"text of the register or copy"`
+}
+
+// Label returns the label of the item in the clipboard history: its
+// register and a one-line summary of its text, of at most max runes
+func (ci *ClipItem) Label(max int) string {
+	if ci.Reg == "" {
+		return KillSummary(ci.Text, max)
+	}
+	return ci.Reg + ": " + KillSummary(ci.Text, max)
+}
+
+// ClipItems returns the items of the clipboard history: the letter
+// registers that are set, a to z, then the copies in given kill ring,
+// newest first, up to max of them, each only once
+func ClipItems(regs Registers, kills []string, max int) []ClipItem {
+	var its []ClipItem
+	for c := 'a'; c <= 'z'; c++ {
+		if txt, ok := regs[string(c)]; ok && txt != "" {
+			its = append(its, ClipItem{Reg: string(c), Text: txt})
+		}
+	}
+	seen := make(map[string]bool)
+	n := 0
+	for _, k := range kills {
+		if n >= max {
+			break
+		}
+		if seen[k] || strings.TrimSpace(k) == "" {
+			continue
+		}
+		seen[k] = true
+		its = append(its, ClipItem{Text: k})
+		n++
+	}
+	return its
+}
+
+// ClipPreview returns the preview of given text: at most maxLines of its
+// lines, with a note of how many more there are
+func ClipPreview(txt string, maxLines int) string {
+	lns := strings.Split(strings.TrimRight(txt, "\n"), "\n")
+	if len(lns) <= maxLines {
+		return strings.Join(lns, "\n")
+	}
+	return strings.Join(lns[:maxLines], "\n") + fmt.Sprintf("\n... (%d more lines)", len(lns)-maxLines)
+}
+
+// RegisterPrompt starts copying the selection of the active view to a
+// letter register, or pasting one, for given op, copy or paste: the next
+// key typed is the letter of the register, A to Z appending to it for a
+// copy, or Enter to choose a register by name
+func (ge *Gide) RegisterPrompt(op string) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if op == "copy" && !tv.HasSelection() {
+		ge.SetStatus("Select the text to copy to a register")
+		return
+	}
+	ge.regPending = op
+	if op == "copy" {
+		ge.SetStatus("Copy to register: type a-z, A-Z to append, Enter to name it, Escape to cancel")
+	} else {
+		ge.SetStatus("Paste register: type a-z, Enter to choose one by name, Escape to cancel")
+	}
+}
+
+// RegisterKey completes the copy or paste started by RegisterPrompt with
+// the register of given key -- returns false if none was started
+func (ge *Gide) RegisterKey(kt *key.ChordEvent) bool {
+	op := ge.regPending
+	if op == "" {
+		return false
+	}
+	ge.regPending = ""
+	kt.SetProcessed()
+	kc := string(kt.Chord())
+	switch kc {
+	case "Escape":
+		ge.SetStatus("Register " + op + " aborted")
+		return true
+	case "ReturnEnter":
+		if op == "copy" {
+			giv.CallMethod(ge, "RegisterCopy", ge.Viewport)
+		} else {
+			giv.CallMethod(ge, "RegisterPaste", ge.Viewport)
+		}
+		return true
+	}
+	reg, appnd, ok := RegisterLetter(kc)
+	if !ok {
+		ge.SetStatus(fmt.Sprintf("Not a register: %v -- register %v aborted", kc, op))
+		return true
+	}
+	if op == "copy" {
+		ge.RegisterCopyTo(reg, appnd)
+	} else if !ge.RegisterPaste(RegisterName(reg)) {
+		ge.SetStatus(fmt.Sprintf("Register %v is empty", reg))
+	}
+	return true
+}
+
+// RegisterCopyTo copies the selection of the active view to the register of
+// given name, or appends it to the register if appnd is true
+func (ge *Gide) RegisterCopyTo(name string, appnd bool) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	sel := tv.Selection()
+	if sel == nil {
+		return
+	}
+	if AvailRegisters == nil {
+		AvailRegisters = make(Registers, 100)
+	}
+	txt := string(sel.ToBytes())
+	verb := "Copied"
+	if appnd {
+		txt = AvailRegisters[name] + txt
+		verb = "Appended"
+	}
+	AvailRegisters[name] = txt
+	AvailRegisters.SavePrefs()
+	ge.Prefs.Register = RegisterName(name)
+	tv.SelectReset()
+	ge.SetStatus(fmt.Sprintf("%v %d lines to register %v", verb, sel.Reg.End.Ln-sel.Reg.Start.Ln+1, name))
+}
+
+// ClipboardHistory pops up a menu at the cursor in the active view with the
+// letter registers and the last ClipHistoryMax copies, newest first, each
+// with a preview of its text in its tooltip -- the one chosen is pasted at
+// the cursor, and put on the clipboard, for pasting elsewhere
+func (ge *Gide) ClipboardHistory() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.IsInactive() {
+		return
+	}
+	its := ClipItems(AvailRegisters, TheKillRing.Kills, ClipHistoryMax)
+	var m gi.Menu
+	if len(its) == 0 {
+		m.AddAction(gi.ActOpts{Label: "(no registers or copies yet)"}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {})
+	}
+	for i, it := range its {
+		it := it
+		if i > 0 && it.Reg == "" && its[i-1].Reg != "" {
+			m.AddSeparator("sep-copies")
+		}
+		m.AddAction(gi.ActOpts{Label: it.Label(60), Tooltip: ClipPreview(it.Text, ClipPreviewLines)}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_Gide).(*Gide)
+			oswin.TheApp.ClipBoard(gee.ParentWindow().OSWin).Write(mimedata.NewTextBytes([]byte(it.Text)))
+			tv.InsertAtCursor([]byte(it.Text))
+		})
+	}
+	cpos := tv.CharStartPos(tv.CursorPos).ToPoint()
+	gi.PopupMenu(m, cpos.X, cpos.Y+int(tv.LineHeight), tv.Viewport, "clip-history-menu")
+}
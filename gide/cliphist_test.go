@@ -0,0 +1,63 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisterLetter(t *testing.T) {
+	tests := []struct {
+		kc    string
+		reg   string
+		appnd bool
+		ok    bool
+	}{
+		{"a", "a", false, true},
+		{"z", "z", false, true},
+		{"Q", "q", true, true},
+		{"Shift+Q", "q", true, true},
+		{"Shift+q", "q", true, true},
+		{"1", "", false, false},
+		{"Control+A", "", false, false},
+		{"ReturnEnter", "", false, false},
+		{"", "", false, false},
+	}
+	for _, tt := range tests {
+		reg, appnd, ok := RegisterLetter(tt.kc)
+		if reg != tt.reg || appnd != tt.appnd || ok != tt.ok {
+			t.Errorf("%q: got %q %v %v, want %q %v %v", tt.kc, reg, appnd, ok, tt.reg, tt.appnd, tt.ok)
+		}
+	}
+}
+
+func TestClipItems(t *testing.T) {
+	regs := Registers{"b": "bee", "a": "ay\nsecond", "named": "not a letter", "c": ""}
+	kills := []string{"new", "  ", "old", "new", "older", "oldest"}
+	want := []ClipItem{{Reg: "a", Text: "ay\nsecond"}, {Reg: "b", Text: "bee"}, {Text: "new"}, {Text: "old"}, {Text: "older"}}
+	got := ClipItems(regs, kills, 3)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := ClipItems(nil, nil, 10); len(got) != 0 {
+		t.Errorf("empty: got %v", got)
+	}
+	if lb := want[0].Label(60); lb != "a: ay  (2 lines)" {
+		t.Errorf("register label: got %q", lb)
+	}
+	if lb := want[2].Label(60); lb != "new" {
+		t.Errorf("copy label: got %q", lb)
+	}
+}
+
+func TestClipPreview(t *testing.T) {
+	if got := ClipPreview("a\nb\n", 2); got != "a\nb" {
+		t.Errorf("got %q", got)
+	}
+	if got, want := ClipPreview("a\nb\nc\nd", 2), "a\nb\n... (2 more lines)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+)
+
+// CloneParams are the parameters for cloning a git repository
+type CloneParams struct {
+	URL    string `desc:"url of the repository to clone"`
+	Parent string `desc:"parent folder to clone into -- the clone is made in its own folder within it"`
+	Dir    string `desc:"name of the folder for the clone -- defaults to the name of the repository"`
+	Depth  int    `desc:"if > 0, make a shallow clone with history truncated to this many commits"`
+	Branch string `desc:"branch (or tag) to check out instead of the default branch of the remote"`
+	User   string `json:"-" xml:"-" view:"-" desc:"user name for authentication -- only used for the clone itself, not saved"`
+	Pass   string `json:"-" xml:"-" view:"-" desc:"password or access token for authentication -- only used for the clone itself, not saved"`
+}
+
+// RepoDirName returns the default directory name for a clone of the
+// repository at given url, as git does, e.g., "gide" for
+// https://github.com/goki/gide.git
+func RepoDirName(repoURL string) string {
+	ru := strings.TrimRight(strings.TrimSpace(repoURL), "/")
+	ru = strings.TrimSuffix(ru, ".git")
+	if ci := strings.LastIndexAny(ru, "/:"); ci >= 0 {
+		ru = ru[ci+1:]
+	}
+	return ru
+}
+
+// Path returns the full path to the folder for the clone
+func (cp *CloneParams) Path() string {
+	dir := cp.Dir
+	if dir == "" {
+		dir = RepoDirName(cp.URL)
+	}
+	return filepath.Join(cp.Parent, dir)
+}
+
+// cloneCredHelper is the git credential helper that gives git the user and
+// password for a clone from the environment of the git process (see Env),
+// so that they are neither on its command line, where other users can see
+// them, nor saved in the config of the clone
+const cloneCredHelper = `!f() { test "$1" = get && echo "username=$GIDE_CLONE_USER" && echo "password=$GIDE_CLONE_PASS"; }; f`
+
+// HasCreds returns true if a user or password is set for the clone
+func (cp *CloneParams) HasCreds() bool {
+	return cp.User != "" || cp.Pass != ""
+}
+
+// URLCreds moves a user and password in the url, if it has a password, to
+// User and Pass, so that they are given to git as they are, and not saved
+// in the config of the clone
+func (cp *CloneParams) URLCreds() {
+	up, err := url.Parse(cp.URL)
+	if err != nil || up.User == nil {
+		return
+	}
+	if pass, ok := up.User.Password(); ok {
+		cp.User, cp.Pass = up.User.Username(), pass
+		up.User = nil
+		cp.URL = up.String()
+	}
+}
+
+// Args returns the git args for doing the clone -- with credentials set,
+// only cloneCredHelper, which reads them from the environment, is used for
+// them, so no other credential helper saves them
+func (cp *CloneParams) Args() []string {
+	var args []string
+	if cp.HasCreds() {
+		args = append(args, "-c", "credential.helper=", "-c", "credential.helper="+cloneCredHelper)
+	}
+	args = append(args, "clone", "--progress")
+	if cp.Depth > 0 {
+		args = append(args, "--depth", fmt.Sprintf("%d", cp.Depth))
+	}
+	if cp.Branch != "" {
+		args = append(args, "--branch", cp.Branch)
+	}
+	return append(args, cp.URL, cp.Path())
+}
+
+// Env returns the environment for the git process of the clone: that of
+// gide, with terminal prompts disabled, and the credentials, if set, for
+// cloneCredHelper
+func (cp *CloneParams) Env() []string {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if cp.HasCreds() {
+		env = append(env, "GIDE_CLONE_USER="+cp.User, "GIDE_CLONE_PASS="+cp.Pass)
+	}
+	return env
+}
+
+// CloneAuthFailed returns true if given clone output indicates that
+// authentication is required or failed
+func CloneAuthFailed(out string) bool {
+	lo := strings.ToLower(out)
+	return strings.Contains(lo, "authentication failed") || strings.Contains(lo, "could not read username") || strings.Contains(lo, "terminal prompts disabled")
+}
+
+// scanProgressLines is a bufio.SplitFunc that splits at either \r or \n, as
+// git uses \r to update progress lines in place
+func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Clone runs git clone with the given params, calling progress (if non-nil)
+// for each line of progress output as it comes in -- does not prompt for
+// credentials on the terminal: use CloneAuthFailed on the returned output to
+// determine if User / Pass need to be set.  Credentials are given to git
+// through its environment, never in the url, so they are not saved in the
+// clone (see URLCreds).
+func (cp *CloneParams) Clone(progress func(line string)) (string, error) {
+	cp.URLCreds()
+	if !filepath.IsAbs(cp.URL) && !strings.HasPrefix(cp.URL, "file://") {
+		if err := OfflineCheck("Cloning " + cp.URL); err != nil {
+			return "", err
+		}
+	}
+	cmd := exec.Command("git", cp.Args()...)
+	cmd.Env = cp.Env()
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return "", err
+	}
+	cmd.Stdout = cmd.Stderr
+	if err = cmd.Start(); err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	scan := bufio.NewScanner(stderr)
+	scan.Split(scanProgressLines)
+	for scan.Scan() {
+		ln := scan.Text()
+		if ln == "" {
+			continue
+		}
+		out.WriteString(ln + "\n")
+		if progress != nil {
+			progress(ln)
+		}
+	}
+	err = cmd.Wait()
+	return out.String(), err
+}
+
+// CloneGui runs the clone in the background, calling progress with progress
+// output (throttled to a few updates per second), and done with the path to
+// the clone if successful.  If authentication is needed, the user is prompted
+// for credentials and the clone is retried.  Errors are reported in a dialog.
+// The callbacks and dialogs are run on the gui with gui, e.g., Gide.RunOnGui.
+func (cp *CloneParams) CloneGui(vp *gi.Viewport2D, recv ki.Ki, gui func(fun func()), progress func(line string), done func(path string)) {
+	type creds struct {
+		user, pass string
+		ok         bool
+	}
+	go func() {
+		for {
+			ts := time.Time{}
+			out, err := cp.Clone(func(line string) {
+				now := time.Now()
+				if progress != nil && (now.Sub(ts) > 200*time.Millisecond || !strings.HasSuffix(line, "%")) {
+					ts = now
+					gui(func() { progress(line) })
+				}
+			})
+			auth := CloneAuthFailed(out) && !cp.HasCreds()
+			cp.User, cp.Pass = "", ""
+			path := cp.Path()
+			if err == nil {
+				gui(func() {
+					if progress != nil {
+						progress("Clone done: " + path)
+					}
+					if done != nil {
+						done(path)
+					}
+				})
+				return
+			}
+			if !auth {
+				gui(func() {
+					gi.PromptDialog(vp, gi.DlgOpts{Title: "Clone Failed", Prompt: fmt.Sprintf("Could not clone %v into %v: %v<br>%v", cp.URL, path, err, out)}, true, false, nil, nil)
+				})
+				return
+			}
+			ans := make(chan creds, 1)
+			gui(func() {
+				CredentialsDialog(vp, gi.DlgOpts{Title: "Repository Credentials", Prompt: fmt.Sprintf("Authentication is required to clone: %v -- enter user name and password (or access token).  Credentials are only used for this clone and are not saved.", cp.URL)},
+					recv, func(user, pass string, ok bool) {
+						ans <- creds{user, pass, ok}
+					})
+			})
+			cr := <-ans
+			if !cr.ok {
+				return
+			}
+			cp.User, cp.Pass = cr.user, cr.pass
+		}
+	}()
+}
+
+// CredentialsDialog prompts for a user name and a password or access token,
+// which is hidden as it is typed, calling fun with them and true if
+// accepted, or false if canceled
+func CredentialsDialog(vp *gi.Viewport2D, opts gi.DlgOpts, recv ki.Ki, fun func(user, pass string, ok bool)) {
+	dlg := gi.NewStdDialog(opts, true, true)
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	uf := frame.InsertNewChild(gi.KiT_TextField, prIdx+1, "user").(*gi.TextField)
+	uf.Placeholder = "user"
+	uf.SetStretchMaxWidth()
+	uf.SetMinPrefWidth(units.NewValue(20, units.Em))
+	pf := frame.InsertNewChild(gi.KiT_TextField, prIdx+2, "pass").(*gi.TextField)
+	pf.Placeholder = "password or access token"
+	pf.NoEcho = true
+	pf.SetStretchMaxWidth()
+	pf.SetMinPrefWidth(units.NewValue(20, units.Em))
+	dlg.DialogSig.Connect(recv, func(recv, send ki.Ki, sig int64, data interface{}) {
+		switch sig {
+		case int64(gi.DialogAccepted):
+			fun(uf.Text(), pf.Text(), true)
+		case int64(gi.DialogCanceled):
+			fun("", "", false)
+		}
+	})
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, vp, nil)
+}
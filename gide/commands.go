@@ -17,6 +17,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/goki/gi/complete"
@@ -105,6 +106,7 @@ func (cm *CmdAndArgs) PrepCmd() (*exec.Cmd, string) {
 			args = nil
 		}
 		cmd := exec.Command(cstr, args...)
+		CmdBuildEnv.SetCmdEnv(cmd)
 		return cmd, cmdstr
 	case "open":
 		switch oswin.TheApp.Platform() {
@@ -122,6 +124,7 @@ func (cm *CmdAndArgs) PrepCmd() (*exec.Cmd, string) {
 			cmdstr += " " + astr
 		}
 		cmd := exec.Command(cstr, args...)
+		CmdBuildEnv.SetCmdEnv(cmd)
 		return cmd, cmdstr
 	default:
 		cmdstr := cstr
@@ -131,6 +134,7 @@ func (cm *CmdAndArgs) PrepCmd() (*exec.Cmd, string) {
 			cmdstr += " " + astr
 		}
 		cmd := exec.Command(cstr, args...)
+		CmdBuildEnv.SetCmdEnv(cmd)
 		return cmd, cmdstr
 	}
 }
@@ -140,10 +144,11 @@ func (cm *CmdAndArgs) PrepCmd() (*exec.Cmd, string) {
 
 // CmdRun tracks running commands
 type CmdRun struct {
-	Name    string      `desc:"Name of command being run -- same as Command.Name"`
-	CmdStr  string      `desc:"command string"`
-	CmdArgs *CmdAndArgs `desc:"Details of the command and args"`
-	Exec    *exec.Cmd   `desc:"exec.Cmd for the command"`
+	Name    string        `desc:"Name of command being run -- same as Command.Name"`
+	CmdStr  string        `desc:"command string"`
+	CmdArgs *CmdAndArgs   `desc:"Details of the command and args"`
+	Exec    *exec.Cmd     `desc:"exec.Cmd for the command"`
+	Done    chan struct{} `desc:"closed by the goroutine that waits for the command, once it has exited"`
 }
 
 // Kill kills the process
@@ -153,6 +158,49 @@ func (cm *CmdRun) Kill() {
 	}
 }
 
+// Running returns true if the process of the command has started and not
+// yet exited
+func (cm *CmdRun) Running() bool {
+	if cm.Exec == nil || cm.Exec.Process == nil {
+		return false
+	}
+	select {
+	case <-cm.Done:
+		return false
+	default:
+		return true
+	}
+}
+
+// ProcTermTimeout is how long a process is given to exit after it is asked
+// to terminate, before it is killed
+var ProcTermTimeout = 3 * time.Second
+
+// TerminateProc terminates the process of given command gracefully: it is
+// sent SIGTERM, and then killed if it has not exited within timeout -- it is
+// killed right away where SIGTERM can not be sent, e.g., on Windows -- the
+// process must be waited for elsewhere, as commands are when they are run,
+// by the goroutine that closes done once it has exited
+func TerminateProc(cmd *exec.Cmd, done <-chan struct{}, timeout time.Duration) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	select {
+	case <-done:
+		return
+	default:
+	}
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		cmd.Process.Kill()
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+	}
+}
+
 // CmdRuns is a slice list of running commands
 type CmdRuns []*CmdRun
 
@@ -164,10 +212,12 @@ func (rc *CmdRuns) Add(cm *CmdRun) {
 	*rc = append(*rc, cm)
 }
 
-// AddCmd adds a new running command, creating CmdRun via args
-func (rc *CmdRuns) AddCmd(name, cmdstr string, cmdargs *CmdAndArgs, ex *exec.Cmd) {
-	cm := &CmdRun{name, cmdstr, cmdargs, ex}
+// AddCmd adds a new running command, creating CmdRun via args -- its Done
+// channel must be closed once the command has been waited for
+func (rc *CmdRuns) AddCmd(name, cmdstr string, cmdargs *CmdAndArgs, ex *exec.Cmd) *CmdRun {
+	cm := &CmdRun{name, cmdstr, cmdargs, ex, make(chan struct{})}
 	rc.Add(cm)
+	return cm
 }
 
 // DeleteIdx delete command at given index
@@ -207,6 +257,17 @@ func (rc *CmdRuns) KillByName(name string) bool {
 	return false
 }
 
+// Running returns the commands whose processes are still running
+func (rc *CmdRuns) Running() CmdRuns {
+	var rn CmdRuns
+	for _, cm := range *rc {
+		if cm.Running() {
+			rn = append(rn, cm)
+		}
+	}
+	return rn
+}
+
 ///////////////////////////////////////////////////////////////////////////
 //  Command
 
@@ -251,6 +312,18 @@ func (cm *Command) HasPrompts() (map[string]struct{}, bool) {
 	}
 }
 
+// NeedsNetwork returns true if any of the programs run by the command use
+// the network (see NetworkCmd)
+func (cm *Command) NeedsNetwork() bool {
+	for i := range cm.Cmds {
+		cma := &cm.Cmds[i]
+		if NetworkCmd(cma.Cmd, cma.Args) {
+			return true
+		}
+	}
+	return false
+}
+
 // CmdNoUserPrompt can be set to true to prevent user from being prompted for strings
 // this is useful when a custom outer-loop has already set the string values.
 // this will be reset automatically after command is run.
@@ -316,6 +389,13 @@ func (cm *Command) Run(ge *Gide, buf *giv.TextBuf) {
 func (cm *Command) RunAfterPrompts(ge *Gide, buf *giv.TextBuf) {
 	ge.RunningCmds.KillByName(cm.Name) // make sure nothing still running for us..
 	CmdNoUserPrompt = false
+	if cm.NeedsNetwork() {
+		if err := OfflineCheck("Command: " + cm.Name); err != nil {
+			cm.AppendCmdOut(ge, buf, []byte(err.Error()+"\n"))
+			ge.SetStatus(err.Error())
+			return
+		}
+	}
 	cdir := "{ProjPath}"
 	if cm.Dir != "" {
 		cdir = cm.Dir
@@ -355,8 +435,10 @@ func (cm *Command) RunAfterPrompts(ge *Gide, buf *giv.TextBuf) {
 // line of the command output to gide statusbar
 func (cm *Command) RunBufWait(ge *Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
 	cmd, cmdstr := cma.PrepCmd()
-	ge.RunningCmds.AddCmd(cm.Name, cmdstr, cma, cmd)
+	cmd = cm.Limit(cmd)
+	cr := ge.RunningCmds.AddCmd(cm.Name, cmdstr, cma, cmd)
 	out, err := cmd.CombinedOutput()
+	close(cr.Done)
 	cm.AppendCmdOut(ge, buf, out)
 	return cm.RunStatus(ge, buf, cmdstr, err, out)
 }
@@ -365,7 +447,8 @@ func (cm *Command) RunBufWait(ge *Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool
 // buffer with new results line-by-line as they come in
 func (cm *Command) RunBuf(ge *Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
 	cmd, cmdstr := cma.PrepCmd()
-	ge.RunningCmds.AddCmd(cm.Name, cmdstr, cma, cmd)
+	cmd = cm.Limit(cmd)
+	cr := ge.RunningCmds.AddCmd(cm.Name, cmdstr, cma, cmd)
 	stdout, err := cmd.StdoutPipe()
 	lfb := []byte("\n")
 	if err == nil {
@@ -409,6 +492,7 @@ func (cm *Command) RunBuf(ge *Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
 		}
 		err = cmd.Wait()
 	}
+	close(cr.Done)
 	return cm.RunStatus(ge, buf, cmdstr, err, nil)
 }
 
@@ -417,8 +501,10 @@ func (cm *Command) RunBuf(ge *Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
 // logs one line of the command output to gide statusbar
 func (cm *Command) RunNoBuf(ge *Gide, cma *CmdAndArgs) bool {
 	cmd, cmdstr := cma.PrepCmd()
-	ge.RunningCmds.AddCmd(cm.Name, cmdstr, cma, cmd)
+	cmd = cm.Limit(cmd)
+	cr := ge.RunningCmds.AddCmd(cm.Name, cmdstr, cma, cmd)
 	out, err := cmd.CombinedOutput()
+	close(cr.Done)
 	return cm.RunStatus(ge, nil, cmdstr, err, out)
 }
 
@@ -471,7 +557,10 @@ func (cm *Command) RunStatus(ge *Gide, buf *giv.TextBuf, cmdstr string, err erro
 	if buf != nil {
 		if err != nil {
 			ge.SelectMainTabByName(cm.Name) // sometimes it isn't
+			ge.SetCmdErrors(cm, buf)
 		}
+		ge.DiagCmdDone(cm, err == nil)
+		ge.AsmVetResult(cm, buf)
 		fsb := []byte(finstat)
 		buf.AppendTextLineMarkup([]byte(""), []byte(""), false, true) // no save undo, yes signal
 		buf.AppendTextLineMarkup(fsb, MarkupCmdOutput(fsb), false, true)
@@ -666,7 +755,26 @@ func (cm *Commands) OpenJSON(filename gi.FileName) error {
 		return err
 	}
 	*cm = make(Commands, 0, 10) // reset
-	return json.Unmarshal(b, cm)
+	errs := JSONLoad(b, cm, CheckCommand)
+	if len(errs) == 0 {
+		return nil
+	}
+	errs.SetFile(string(filename))
+	errs.Report("Errors in Commands")
+	return errs
+}
+
+// CheckCommand checks a command loaded from JSON: it must have a name and
+// something to run
+func CheckCommand(elem interface{}) error {
+	cm := elem.(*Command)
+	if cm.Name == "" {
+		return fmt.Errorf("command has no Name")
+	}
+	if len(cm.Cmds) == 0 {
+		return fmt.Errorf("command %v has no Cmds to run", cm.Name)
+	}
+	return nil
 }
 
 // SaveJSON saves commands to a JSON-formatted file.
@@ -691,7 +799,7 @@ func (cm *Commands) OpenPrefs() error {
 	pnm := filepath.Join(pdir, PrefsCmdsFileName)
 	CustomCmdsChanged = false
 	err := cm.OpenJSON(gi.FileName(pnm))
-	if err == nil {
+	if _, part := err.(JSONErrors); err == nil || part {
 		MergeAvailCmds()
 	}
 	return err
@@ -848,6 +956,8 @@ var StdCmds = Commands{
 		[]CmdAndArgs{CmdAndArgs{"go", []string{"test", "-v"}}}, "{FileDirPath}", false, false, false},
 	{"Vet Go", "run go vet in current dir", LangNames{"Go"},
 		[]CmdAndArgs{CmdAndArgs{"go", []string{"vet"}}}, "{FileDirPath}", false, false, false},
+	{"Vet Go Asm", "run go vet to check the assembly in current dir against the Go declarations of its functions", LangNames{"Go", "Go Asm"},
+		[]CmdAndArgs{CmdAndArgs{"go", []string{"vet", "-asmdecl"}}}, "{FileDirPath}", true, false, false},
 	{"Get Go", "run go get on package you enter at prompt", LangNames{"Go"},
 		[]CmdAndArgs{CmdAndArgs{"go", []string{"get", "{PromptString1}"}}}, "{FileDirPath}", false, false, false},
 	{"Get Go Updt", "run go get -u (updt) on package you enter at prompt", LangNames{"Go"},
@@ -0,0 +1,50 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestTerminateProc(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no SIGTERM on windows")
+	}
+	start := func(args ...string) (*exec.Cmd, chan struct{}) {
+		cmd := exec.Command(args[0], args[1:]...)
+		if err := cmd.Start(); err != nil {
+			t.Skip(err)
+		}
+		done := make(chan struct{})
+		go func() {
+			cmd.Wait()
+			close(done)
+		}()
+		return cmd, done
+	}
+
+	cmd, done := start("sleep", "30")
+	st := time.Now()
+	TerminateProc(cmd, done, 5*time.Second)
+	if d := time.Since(st); d > 2*time.Second {
+		t.Errorf("sleep took %v to terminate, want it to exit on SIGTERM", d)
+	}
+
+	cmd, done = start("sh", "-c", "trap '' TERM; sleep 3")
+	time.Sleep(200 * time.Millisecond) // for the trap to be set
+	st = time.Now()
+	TerminateProc(cmd, done, 300*time.Millisecond)
+	if d := time.Since(st); d < 300*time.Millisecond || d > 2*time.Second {
+		t.Errorf("process ignoring SIGTERM took %v to terminate, want it killed after the timeout", d)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("process ignoring SIGTERM is still running")
+	}
+}
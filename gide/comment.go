@@ -0,0 +1,174 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+)
+
+// commentIndent returns the shortest indentation of the non-blank lines in
+// given lines, where the comment markers are aligned, and false if they are
+// all blank
+func commentIndent(lines []string) (string, bool) {
+	ind, any := "", false
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if li := leadingSpace(l); !any || len(li) < len(ind) {
+			ind = li
+		}
+		any = true
+	}
+	return ind, any
+}
+
+// uncommentAt returns given line with given marker, which it starts with
+// after its indentation, removed, along with the space after it if the
+// marker is followed by one -- false if it does not start with it
+func uncommentAt(l, marker string) (string, bool) {
+	mk := strings.TrimSpace(marker)
+	ind := leadingSpace(l)
+	rest := l[len(ind):]
+	if mk == "" || !strings.HasPrefix(rest, mk) {
+		return l, false
+	}
+	rest = rest[len(mk):]
+	if strings.HasSuffix(marker, " ") {
+		rest = strings.TrimPrefix(rest, " ")
+	}
+	return ind + rest, true
+}
+
+// CommentToggleLines returns given lines commented out with given line
+// comment marker, e.g., "// ", or if they are all commented out already,
+// uncommented -- markers are aligned at the shortest indentation, after
+// which the indentation of each line is kept, and blank lines are left as
+// they are -- if there is no line comment, the block comment start and end
+// markers are used, around all the lines -- returns false if there is
+// nothing to do
+func CommentToggleLines(lines []string, line, blockSt, blockEd string) ([]string, bool) {
+	ind, any := commentIndent(lines)
+	if !any {
+		return lines, false
+	}
+	out := make([]string, len(lines))
+	copy(out, lines)
+	if strings.TrimSpace(line) != "" {
+		all := true
+		for i, l := range lines {
+			if strings.TrimSpace(l) == "" {
+				continue
+			}
+			if out[i], all = uncommentAt(l, line); !all {
+				break
+			}
+		}
+		if all {
+			return out, true
+		}
+		for i, l := range lines {
+			if strings.TrimSpace(l) != "" {
+				out[i] = ind + line + l[len(ind):]
+			}
+		}
+		return out, true
+	}
+	if strings.TrimSpace(blockSt) == "" || strings.TrimSpace(blockEd) == "" {
+		return lines, false
+	}
+	first, last := -1, -1
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	ust, sok := uncommentAt(lines[first], blockSt)
+	mke := strings.TrimSpace(blockEd)
+	tl := strings.TrimRightFunc(lines[last], unicode.IsSpace)
+	if first == last {
+		tl = strings.TrimRightFunc(ust, unicode.IsSpace)
+	}
+	if sok && strings.HasSuffix(tl, mke) && (first != last || len(strings.TrimSpace(ust)) >= len(mke)) {
+		tl = strings.TrimSuffix(tl, mke)
+		if strings.HasPrefix(blockEd, " ") {
+			tl = strings.TrimSuffix(tl, " ")
+		}
+		out[first] = ust
+		out[last] = tl
+		return out, true
+	}
+	out[first] = ind + blockSt + lines[first][len(ind):]
+	out[last] = strings.TrimRightFunc(out[last], unicode.IsSpace) + blockEd
+	return out, true
+}
+
+// CommentMarkers returns the line comment marker and block comment start
+// and end markers for the language of given file -- the line one is "// "
+// if it has no language
+func CommentMarkers(fname string) (line, blockSt, blockEd string) {
+	ls := LangsForFilename(fname)
+	if len(ls) == 0 {
+		return "// ", "", ""
+	}
+	return ls[0].Comment, ls[0].CommentSt, ls[0].CommentEd
+}
+
+// CommentToggle comments out the current line or the lines of the selection
+// in the active view, or uncomments them if they are all commented out,
+// using the line or block comment markers of its language (see Comment,
+// CommentSt and CommentEd in Langs) -- the markers are aligned at the
+// shortest indentation, keeping the indentation of each line
+func (ge *Gide) CommentToggle() bool {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.IsInactive() {
+		return false
+	}
+	tb := tv.Buf
+	stl, edl := tv.CursorPos.Ln, tv.CursorPos.Ln
+	if sel := tv.Selection(); sel != nil {
+		stl, edl = sel.Reg.Start.Ln, sel.Reg.End.Ln
+		if sel.Reg.End.Ch == 0 && edl > stl {
+			edl-- // selection of whole lines ends at the start of the next
+		}
+	}
+	if edl >= len(tb.Lines) {
+		return false
+	}
+	lines := make([]string, edl-stl+1)
+	for i := range lines {
+		lines[i] = string(tb.Lines[stl+i])
+	}
+	line, bst, bed := CommentMarkers(string(tb.Filename))
+	out, ok := CommentToggleLines(lines, line, bst, bed)
+	if !ok {
+		ge.SetStatus("Nothing to comment, or no comment syntax for this language -- set it in Preferences Edit Langs")
+		return false
+	}
+	cp := tv.CursorPos
+	for i := range lines {
+		if out[i] == lines[i] {
+			continue
+		}
+		ln := stl + i
+		tb.DeleteText(giv.TextPos{Ln: ln}, giv.TextPos{Ln: ln, Ch: len(tb.Lines[ln])}, true, true)
+		tb.InsertText(giv.TextPos{Ln: ln}, []byte(out[i]), true, true)
+	}
+	if cp.Ln >= stl && cp.Ln <= edl {
+		cp.Ch += len([]rune(out[cp.Ln-stl])) - len([]rune(lines[cp.Ln-stl]))
+		if cp.Ch < 0 {
+			cp.Ch = 0
+		}
+	}
+	tv.SelectReset()
+	tv.SetCursorShow(cp)
+	return true
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommentToggleLines(t *testing.T) {
+	tests := []struct {
+		name         string
+		in           string
+		line, st, ed string
+		want         string
+	}{
+		{"line", "\tif x {\n\t\ty()\n\n\t}", "// ", "/* ", " */", "\t// if x {\n\t// \ty()\n\n\t// }"},
+		{"uncomment", "\t// if x {\n\t// \ty()\n\n\t// }", "// ", "/* ", " */", "\tif x {\n\t\ty()\n\n\t}"},
+		{"no space", "\t//if x {\n\t//}", "// ", "", "", "\tif x {\n\t}"},
+		{"some", "# a\nb", "# ", "", "", "# # a\n# b"},
+		{"block", "  <p>\n    hi\n  </p>", "", "<!-- ", " -->", "  <!-- <p>\n    hi\n  </p> -->"},
+		{"unblock", "  <!-- <p>\n    hi\n  </p> -->", "", "<!-- ", " -->", "  <p>\n    hi\n  </p>"},
+		{"block one", "<b>x</b>", "", "<!-- ", " -->", "<!-- <b>x</b> -->"},
+		{"unblock one", "<!-- <b>x</b> -->", "", "<!-- ", " -->", "<b>x</b>"},
+	}
+	for _, tt := range tests {
+		got, ok := CommentToggleLines(strings.Split(tt.in, "\n"), tt.line, tt.st, tt.ed)
+		if !ok {
+			t.Errorf("%v: nothing done", tt.name)
+			continue
+		}
+		if want := strings.Split(tt.want, "\n"); !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: got %q, want %q", tt.name, got, want)
+		}
+	}
+	if _, ok := CommentToggleLines([]string{"", "  "}, "// ", "", ""); ok {
+		t.Errorf("blank lines were commented")
+	}
+	if _, ok := CommentToggleLines([]string{"x"}, "", "", ""); ok {
+		t.Errorf("commented without comment syntax")
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/complete"
+	"github.com/goki/gi/giv"
+)
+
+// CompleteCtx is the context passed to the Gide completion functions, which
+// are used for all text buffers opened in a Gide project
+type CompleteCtx struct {
+	Gide *Gide
+	Buf  *giv.TextBuf
+}
+
+// CompleteMaxWords is the maximum number of word (dabbrev) completions returned
+var CompleteMaxWords = 50
+
+// LspCompletionIcons are the icons for LSP completion item kinds
+var LspCompletionIcons = map[int]string{
+	2:  "function", // method
+	3:  "function",
+	4:  "function", // constructor
+	5:  "field",
+	6:  "variable",
+	7:  "type",    // class
+	8:  "type",    // interface
+	9:  "package", // module
+	10: "field",   // property
+	14: "keyword",
+	21: "const",
+	22: "type", // struct
+}
+
+// CompleteSeed returns the identifier-like word at the end of the given text
+// (the current line up to the cursor), which is what is being completed
+func CompleteSeed(text string) string {
+	rs := []rune(text)
+	st := len(rs)
+	for st > 0 && completeIsWordRune(rs[st-1]) {
+		st--
+	}
+	return string(rs[st:])
+}
+
+func completeIsWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// CompleteWords returns the words in the given lines that start with seed
+// (case-sensitive), excluding seed itself, ordered by distance from the given
+// current line -- this is the dynamic abbreviation (dabbrev) fallback when
+// there is no language server
+func CompleteWords(lines [][]rune, curLn int, seed string, max int) []string {
+	if seed == "" {
+		return nil
+	}
+	type wdist struct {
+		wd   string
+		dist int
+	}
+	found := make(map[string]int)
+	rseed := []rune(seed)
+	for ln, lr := range lines {
+		dist := ln - curLn
+		if dist < 0 {
+			dist = -dist
+		}
+		for i := 0; i < len(lr); {
+			if !completeIsWordRune(lr[i]) {
+				i++
+				continue
+			}
+			st := i
+			for i < len(lr) && completeIsWordRune(lr[i]) {
+				i++
+			}
+			wr := lr[st:i]
+			if len(wr) <= len(rseed) || string(wr[:len(rseed)]) != seed {
+				continue
+			}
+			wd := string(wr)
+			if od, has := found[wd]; !has || dist < od {
+				found[wd] = dist
+			}
+		}
+	}
+	wds := make([]wdist, 0, len(found))
+	for wd, dist := range found {
+		wds = append(wds, wdist{wd, dist})
+	}
+	sort.Slice(wds, func(i, j int) bool {
+		if wds[i].dist == wds[j].dist {
+			return wds[i].wd < wds[j].wd
+		}
+		return wds[i].dist < wds[j].dist
+	})
+	if max > 0 && len(wds) > max {
+		wds = wds[:max]
+	}
+	res := make([]string, len(wds))
+	for i := range wds {
+		res[i] = wds[i].wd
+	}
+	return res
+}
+
+// CompleteGide is the completion function for Gide text buffers: it uses the
+// language server for the file if there is one, falling back on the type
+// information from go/packages for Go (see GoPkgInfo) and then on words in
+// the buffer (dabbrev).
+func CompleteGide(data interface{}, text string, pos token.Position) (md complete.MatchData) {
+	cc, ok := data.(*CompleteCtx)
+	if !ok || cc.Buf == nil {
+		return
+	}
+	md.Seed = CompleteSeed(text)
+	if lc := cc.Gide.LspClientForBuf(cc.Buf); lc != nil {
+		fpath := string(cc.Buf.Filename)
+		if !lc.IsOpen(fpath) {
+			lc.DidOpen(fpath, string(cc.Buf.LinesToBytesCopy()))
+		}
+		items, err := lc.Completion(fpath, pos.Line, pos.Column)
+		if err == nil && len(items) > 0 {
+			lseed := strings.ToLower(md.Seed)
+			for _, it := range items {
+				txt := it.InsertText
+				if txt == "" {
+					txt = it.Label
+				}
+				if !strings.HasPrefix(strings.ToLower(txt), lseed) {
+					continue
+				}
+				md.Matches = append(md.Matches, complete.Completion{Text: txt, Icon: LspCompletionIcons[it.Kind], Desc: it.Detail})
+			}
+			return
+		}
+	}
+	if fpath := string(cc.Buf.Filename); strings.HasSuffix(fpath, ".go") {
+		if gp, err := GoLoadFile(fpath, cc.Buf.LinesToBytesCopy()); err == nil {
+			off := TextPosByteOffset(cc.Buf.Lines, giv.TextPos{Ln: pos.Line, Ch: pos.Column})
+			md.Matches = gp.CompleteAt(fpath, off, md.Seed)
+			if len(md.Matches) > 0 {
+				return
+			}
+		}
+	}
+	for _, wd := range CompleteWords(cc.Buf.Lines, pos.Line, md.Seed, CompleteMaxWords) {
+		md.Matches = append(md.Matches, complete.Completion{Text: wd})
+	}
+	return
+}
+
+// CompleteGideEdit is the edit function for CompleteGide, replacing the
+// seed with the selected completion
+func CompleteGideEdit(data interface{}, text string, cursorPos int, c complete.Completion, seed string) (ed complete.EditData) {
+	ed = complete.EditWord(text, cursorPos, c.Text, seed)
+	return ed
+}
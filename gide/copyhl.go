@@ -0,0 +1,370 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/alecthomas/chroma"
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
+)
+
+// HiFormat is how the text of a highlighting class is formatted, as given
+// by a highlighting style -- colors are #rrggbb, empty for the default
+type HiFormat struct {
+	Color     string
+	Bg        string
+	Bold      bool
+	Italic    bool
+	Underline bool
+}
+
+// HiFormats are the formats of the highlighting classes, by css class --
+// the "bg" class is the format of the text as a whole
+type HiFormats map[string]HiFormat
+
+// mime types of the highlighted text copied to the clipboard, for pasting
+// into word processors, slides and web pages
+const (
+	HiMimeHTML = "text/html"
+	HiMimeRTF  = "text/rtf"
+)
+
+// hiHexColor returns the #rrggbb string for given color
+func hiHexColor(c gi.Color) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// HiStyleFormats returns the formats of the highlighting classes of the
+// highlighting style with given name
+func HiStyleFormats(nm histyle.StyleName) HiFormats {
+	fm := HiFormats{}
+	st := histyle.AvailStyle(nm)
+	if st == nil {
+		return fm
+	}
+	for tt, cls := range chroma.StandardTypes {
+		se := st.Tag(tt)
+		if se.IsZero() {
+			continue
+		}
+		f := HiFormat{Bold: se.Bold == histyle.Yes, Italic: se.Italic == histyle.Yes, Underline: se.Underline == histyle.Yes}
+		if !se.Color.IsNil() {
+			f.Color = hiHexColor(se.Color)
+		}
+		if !se.Background.IsNil() {
+			f.Bg = hiHexColor(se.Background)
+		}
+		fm[cls] = f
+	}
+	return fm
+}
+
+// Of returns the format of given class, with the colors of the text as a
+// whole where it has none
+func (fm HiFormats) Of(cls string) HiFormat {
+	bg := fm["bg"]
+	f, ok := fm[cls]
+	if !ok || cls == "" {
+		f = HiFormat{}
+	}
+	if f.Color == "" {
+		f.Color = bg.Color
+	}
+	if f.Bg == "" {
+		f.Bg = bg.Bg
+	}
+	return f
+}
+
+// CSS returns the inline css of the format
+func (f HiFormat) CSS() string {
+	var cs []string
+	if f.Color != "" {
+		cs = append(cs, "color:"+f.Color)
+	}
+	if f.Bg != "" {
+		cs = append(cs, "background-color:"+f.Bg)
+	}
+	if f.Bold {
+		cs = append(cs, "font-weight:bold")
+	}
+	if f.Italic {
+		cs = append(cs, "font-style:italic")
+	}
+	if f.Underline {
+		cs = append(cs, "text-decoration:underline")
+	}
+	return strings.Join(cs, ";")
+}
+
+// hiLineSpans calls fun for each run of the runes of given line with the
+// same class, by given spans, in order
+func hiLineSpans(line string, spans []HiSpan, fun func(cls, s string)) {
+	rs := []rune(line)
+	cls := make([]string, len(rs))
+	for _, sp := range spans {
+		for i := sp.St; i < sp.Ed && i < len(rs); i++ {
+			if i >= 0 {
+				cls[i] = sp.Cls
+			}
+		}
+	}
+	st := 0
+	for i := 1; i <= len(rs); i++ {
+		if i == len(rs) || cls[i] != cls[st] {
+			fun(cls[st], string(rs[st:i]))
+			st = i
+		}
+	}
+}
+
+// HiClip returns given lines, with the spans of their highlighting, cut to
+// start at rune stCh of the first line and end before rune edCh of the last
+// one -- edCh < 0 for the whole last line
+func HiClip(lines []string, spans [][]HiSpan, stCh, edCh int) ([]string, [][]HiSpan) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	ol := append([]string(nil), lines...)
+	osp := make([][]HiSpan, len(lines))
+	for i := range osp {
+		if i < len(spans) {
+			osp[i] = append([]HiSpan(nil), spans[i]...)
+		}
+	}
+	last := len(ol) - 1
+	if rs := []rune(ol[last]); edCh >= 0 && edCh < len(rs) {
+		ol[last] = string(rs[:edCh])
+	}
+	if rs := []rune(ol[0]); stCh > 0 {
+		if stCh > len(rs) {
+			stCh = len(rs)
+		}
+		ol[0] = string(rs[stCh:])
+		for j := range osp[0] {
+			osp[0][j].St -= stCh
+			osp[0][j].Ed -= stCh
+		}
+	}
+	return ol, osp
+}
+
+// NumberLines returns given lines with their line numbers in front of them,
+// starting with given one, right-aligned to the width of the last one
+func NumberLines(lines []string, first int) []string {
+	w := len(fmt.Sprint(first + len(lines) - 1))
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = fmt.Sprintf("%*d  %s", w, first+i, l)
+	}
+	return out
+}
+
+// HiHTML returns the html of given lines, highlighted by given spans in
+// given formats, in a pre block, with line numbers starting at first, or
+// none if first is 0
+func HiHTML(lines []string, spans [][]HiSpan, fm HiFormats, first int) string {
+	var b strings.Builder
+	pre := fm.Of("")
+	fmt.Fprintf(&b, `<pre style="font-family:monospace;%s">`, pre.CSS())
+	w := len(fmt.Sprint(first + len(lines) - 1))
+	for i, l := range lines {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if first > 0 {
+			fmt.Fprintf(&b, `<span style="%s">%*d  </span>`, fm.Of("ln").CSS(), w, first+i)
+		}
+		var sps []HiSpan
+		if i < len(spans) {
+			sps = spans[i]
+		}
+		hiLineSpans(l, sps, func(cls, s string) {
+			f := fm.Of(cls)
+			if cls == "" || f == pre {
+				b.WriteString(html.EscapeString(s))
+				return
+			}
+			fmt.Fprintf(&b, `<span style="%s">%s</span>`, f.CSS(), html.EscapeString(s))
+		})
+	}
+	b.WriteString("</pre>")
+	return b.String()
+}
+
+// rtfEscape returns given text escaped for rtf, with tabs as \tab and
+// non-ASCII characters as \u escapes
+func rtfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '{' || r == '}':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString(`\tab `)
+		case r < 0x80:
+			b.WriteRune(r)
+		case r < 0x10000:
+			fmt.Fprintf(&b, `\u%d?`, int16(r))
+		default: // utf-16 surrogate pair
+			r -= 0x10000
+			fmt.Fprintf(&b, `\u%d?\u%d?`, int16(0xd800+(r>>10)), int16(0xdc00+(r&0x3ff)))
+		}
+	}
+	return b.String()
+}
+
+// HiRTF returns the rtf of given lines, highlighted by given spans in given
+// formats, in a monospace font, with line numbers starting at first, or none
+// if first is 0
+func HiRTF(lines []string, spans [][]HiSpan, fm HiFormats, first int) string {
+	var colors []string
+	cidx := func(c string) int {
+		if c == "" {
+			return 0
+		}
+		for i, ec := range colors {
+			if ec == c {
+				return i + 1
+			}
+		}
+		colors = append(colors, c)
+		return len(colors)
+	}
+	var body strings.Builder
+	run := func(f HiFormat, s string) {
+		body.WriteString("{")
+		if ci := cidx(f.Color); ci > 0 {
+			fmt.Fprintf(&body, `\cf%d`, ci)
+		}
+		if ci := cidx(f.Bg); ci > 0 {
+			fmt.Fprintf(&body, `\highlight%d\cb%d`, ci, ci)
+		}
+		if f.Bold {
+			body.WriteString(`\b`)
+		}
+		if f.Italic {
+			body.WriteString(`\i`)
+		}
+		if f.Underline {
+			body.WriteString(`\ul`)
+		}
+		body.WriteString(" " + rtfEscape(s) + "}")
+	}
+	w := len(fmt.Sprint(first + len(lines) - 1))
+	for i, l := range lines {
+		if first > 0 {
+			run(fm.Of("ln"), fmt.Sprintf("%*d  ", w, first+i))
+		}
+		var sps []HiSpan
+		if i < len(spans) {
+			sps = spans[i]
+		}
+		hiLineSpans(l, sps, func(cls, s string) {
+			run(fm.Of(cls), s)
+		})
+		if i < len(lines)-1 {
+			body.WriteString("\\line\n")
+		}
+	}
+	var b strings.Builder
+	b.WriteString(`{\rtf1\ansi\deff0{\fonttbl{\f0\fmodern Courier New;}}{\colortbl;`)
+	for _, c := range colors {
+		var r, g, bl int
+		fmt.Sscanf(c, "#%02x%02x%02x", &r, &g, &bl)
+		fmt.Fprintf(&b, `\red%d\green%d\blue%d;`, r, g, bl)
+	}
+	b.WriteString("}\n\\f0\\fs20 ")
+	b.WriteString(body.String())
+	b.WriteString("}")
+	return b.String()
+}
+
+// hiSelLines returns the selected text of the active view, or its cursor
+// line if nothing is selected, with the spans of its highlighting, and the
+// number of its first line
+func (ge *Gide) hiSelLines() ([]string, [][]HiSpan, int, bool) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return nil, nil, 0, false
+	}
+	tb := tv.Buf
+	st, ed := giv.TextPos{Ln: tv.CursorPos.Ln}, giv.TextPos{Ln: tv.CursorPos.Ln, Ch: -1}
+	if sel := tv.Selection(); sel != nil {
+		st, ed = sel.Reg.Start, sel.Reg.End
+		if ed.Ch == 0 && ed.Ln > st.Ln {
+			ed = giv.TextPos{Ln: ed.Ln - 1, Ch: -1} // whole lines, without the next one
+		}
+	}
+	if st.Ln < 0 || ed.Ln >= len(tb.Lines) || ed.Ln < st.Ln {
+		return nil, nil, 0, false
+	}
+	lines := make([]string, ed.Ln-st.Ln+1)
+	for i := range lines {
+		lines[i] = string(tb.Lines[st.Ln+i])
+	}
+	var spans [][]HiSpan
+	ge.hiMu.Lock()
+	if hs := ge.hiStates[tb]; hs != nil && len(hs.Spans) == len(tb.Lines) {
+		spans = hs.Spans[st.Ln : ed.Ln+1]
+	}
+	lines, spans = HiClip(lines, spans, st.Ch, ed.Ch)
+	ge.hiMu.Unlock()
+	return lines, spans, st.Ln + 1, true
+}
+
+// CopyHighlighted copies the selected text of the active view, or the cursor
+// line, to the clipboard with its syntax highlighting, as both html and rtf,
+// in the current highlighting style -- for pasting colored code into slides
+// and documents
+func (ge *Gide) CopyHighlighted() {
+	ge.CopyHighlightedStyle(Prefs.HiStyle, false)
+}
+
+// CopyHighlightedStyle copies the selected text of the active view, or the
+// cursor line, to the clipboard with its syntax highlighting in given
+// highlighting style, as both html and rtf, and plain text, optionally with
+// line numbers
+func (ge *Gide) CopyHighlightedStyle(style histyle.StyleName, lineNos bool) {
+	lines, spans, first, ok := ge.hiSelLines()
+	if !ok {
+		return
+	}
+	if !lineNos {
+		first = 0
+	}
+	fm := HiStyleFormats(style)
+	plain := lines
+	if first > 0 {
+		plain = NumberLines(lines, first)
+	}
+	md := mimedata.Mimes{
+		&mimedata.Data{Type: HiMimeHTML, Data: []byte(HiHTML(lines, spans, fm, first))},
+		&mimedata.Data{Type: HiMimeRTF, Data: []byte(HiRTF(lines, spans, fm, first))},
+		&mimedata.Data{Type: mimedata.TextPlain, Data: []byte(strings.Join(plain, "\n"))},
+	}
+	oswin.TheApp.ClipBoard(ge.ParentWindow().OSWin).Write(md)
+	ge.SetStatus(fmt.Sprintf("Copied %v lines with %v highlighting", len(lines), style))
+}
+
+// CopyWithLineNumbers copies the selected text of the active view, or the
+// cursor line, to the clipboard as plain text with the line numbers in front
+// of each line
+func (ge *Gide) CopyWithLineNumbers() {
+	lines, _, first, ok := ge.hiSelLines()
+	if !ok {
+		return
+	}
+	oswin.TheApp.ClipBoard(ge.ParentWindow().OSWin).Write(mimedata.NewTextBytes([]byte(strings.Join(NumberLines(lines, first), "\n"))))
+	ge.SetStatus(fmt.Sprintf("Copied %v lines with line numbers", len(lines)))
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"testing"
+)
+
+var hiTestFormats = HiFormats{
+	"bg": {Color: "#000000", Bg: "#ffffff"},
+	"kd": {Color: "#aa22ff", Bold: true},
+	"s":  {Color: "#ba2121"},
+	"ln": {Color: "#7f7f7f"},
+}
+
+func TestHiClip(t *testing.T) {
+	lines := []string{"func f() {", `	s := "x"`}
+	spans := [][]HiSpan{{{0, 4, "kd"}}, {{6, 9, "s"}}}
+	ol, osp := HiClip(lines, spans, 5, 6)
+	if ol[0] != "f() {" || ol[1] != "\ts := " {
+		t.Errorf("lines: got %q", ol)
+	}
+	if osp[0][0].St != -5 || osp[0][0].Ed != -1 || spans[0][0].St != 0 {
+		t.Errorf("spans: got %v, original %v", osp, spans)
+	}
+	ol, _ = HiClip(lines[:1], spans[:1], 0, -1)
+	if ol[0] != lines[0] {
+		t.Errorf("whole line: got %q", ol[0])
+	}
+}
+
+func TestNumberLines(t *testing.T) {
+	got := NumberLines([]string{"a", "b", "c"}, 9)
+	want := []string{" 9  a", "10  b", "11  c"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHiHTML(t *testing.T) {
+	lines := []string{`func f() <x>`, `s := "y"`}
+	spans := [][]HiSpan{{{0, 4, "kd"}}, {{5, 8, "s"}}}
+	got := HiHTML(lines, spans, hiTestFormats, 0)
+	want := `<pre style="font-family:monospace;color:#000000;background-color:#ffffff">` +
+		`<span style="color:#aa22ff;background-color:#ffffff;font-weight:bold">func</span> f() &lt;x&gt;` + "\n" +
+		`s := <span style="color:#ba2121;background-color:#ffffff">&#34;y&#34;</span></pre>`
+	if got != want {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+	got = HiHTML(lines[:1], spans[:1], hiTestFormats, 7)
+	if !strings.Contains(got, `<span style="color:#7f7f7f;background-color:#ffffff">7  </span><span`) {
+		t.Errorf("line numbers: got %v", got)
+	}
+}
+
+func TestHiRTF(t *testing.T) {
+	lines := []string{"func {é}", "\tx"}
+	spans := [][]HiSpan{{{0, 4, "kd"}}}
+	got := HiRTF(lines, spans, hiTestFormats, 1)
+	for _, want := range []string{
+		`{\colortbl;\red127\green127\blue127;\red255\green255\blue255;\red170\green34\blue255;\red0\green0\blue0;}`,
+		`{\cf1\highlight2\cb2 1  }{\cf3\highlight2\cb2\b func}{\cf4\highlight2\cb2  \{\u233?\}}\line`,
+		`{\cf4\highlight2\cb2 \tab x}}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %v in\n%v", want, got)
+		}
+	}
+	if !strings.HasPrefix(got, `{\rtf1\ansi`) {
+		t.Errorf("not rtf: %v", got)
+	}
+	if e := rtfEscape("😀"); e != `\u-10179?\u-8704?` {
+		t.Errorf("surrogates: got %v", e)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
+)
+
+// CodeRef returns a reference to given 0-based line range of a file, with
+// the path relative to given root where possible, e.g., gide/gide.go:123,
+// or gide/gide.go:123-130 for several lines
+func CodeRef(root, fpath string, st, ed int) string {
+	if rp, err := filepath.Rel(root, fpath); err == nil && !strings.HasPrefix(rp, "..") {
+		fpath = rp
+	}
+	ref := fmt.Sprintf("%v:%d", filepath.ToSlash(fpath), st+1)
+	if ed > st {
+		ref += fmt.Sprintf("-%d", ed+1)
+	}
+	return ref
+}
+
+// RemoteWebURL returns the web url of the repository at given git remote
+// url, e.g., https://github.com/goki/gide for git@github.com:goki/gide.git,
+// dropping any credentials -- false if it is not a remote url, e.g., a local
+// path
+func RemoteWebURL(remote string) (string, bool) {
+	remote = strings.TrimSpace(remote)
+	var host, path string
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		switch u.Scheme {
+		case "http", "https", "ssh", "git", "git+ssh", "ssh+git":
+		default:
+			return "", false
+		}
+		host, path = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		ci := strings.Index(remote, ":")
+		if ci <= 0 || strings.Contains(remote[:ci], "/") {
+			return "", false
+		}
+		host, path = remote[:ci], remote[ci+1:]
+		if ai := strings.LastIndex(host, "@"); ai >= 0 {
+			host = host[ai+1:]
+		}
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return "", false
+	}
+	return "https://" + host + "/" + path, true
+}
+
+// Permalink returns the url of given 0-based line range of given file, with
+// a path relative to the repository, at given commit, in the repository
+// with given web url -- GitLab urls, and those of hosts with gitlab in their
+// name, are in its format, and all others in that of GitHub
+func Permalink(web, commit, relpath string, st, ed int) string {
+	relpath = filepath.ToSlash(relpath)
+	var esc []string
+	for _, p := range strings.Split(relpath, "/") {
+		esc = append(esc, url.PathEscape(p))
+	}
+	relpath = strings.Join(esc, "/")
+	if strings.Contains(strings.ToLower(web), "gitlab") {
+		ur := fmt.Sprintf("%v/-/blob/%v/%v#L%d", web, commit, relpath, st+1)
+		if ed > st {
+			ur += fmt.Sprintf("-%d", ed+1)
+		}
+		return ur
+	}
+	ur := fmt.Sprintf("%v/blob/%v/%v#L%d", web, commit, relpath, st+1)
+	if ed > st {
+		ur += fmt.Sprintf("-L%d", ed+1)
+	}
+	return ur
+}
+
+// refLines returns the 0-based line range of the selection in given view,
+// not counting the line the selection ends at the start of, or the cursor
+// line if there is no selection
+func refLines(tv *giv.TextView) (int, int) {
+	if !tv.HasSelection() {
+		return tv.CursorPos.Ln, tv.CursorPos.Ln
+	}
+	st, ed := tv.SelectReg.Start, tv.SelectReg.End
+	if ed.Ch == 0 && ed.Ln > st.Ln {
+		ed.Ln--
+	}
+	return st.Ln, ed.Ln
+}
+
+// gitOut returns the trimmed output of given git command, run in given
+// directory
+func gitOut(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("git %v: %v", args[0], strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// copyText copies given text to the clipboard, and shows it in the status
+func (ge *Gide) copyText(what, txt string) {
+	oswin.TheApp.ClipBoard(ge.ParentWindow().OSWin).Write(mimedata.NewTextBytes([]byte(txt)))
+	ge.SetStatus(fmt.Sprintf("Copied %v: %v", what, txt))
+}
+
+// CopyReference copies a reference to the selected lines of the active
+// view, or the cursor line, to the clipboard, with the path relative to the
+// project root, e.g., gide/gide.go:123 -- for pasting into issues and chat
+func (ge *Gide) CopyReference() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf.Filename == "" {
+		return
+	}
+	st, ed := refLines(tv)
+	ge.copyText("reference", CodeRef(string(ge.ProjRoot), string(tv.Buf.Filename), st, ed))
+}
+
+// CopyPermalink copies the url of the selected lines of the active view, or
+// the cursor line, on the GitHub or GitLab site of the git remote of the
+// project, at the current commit, to the clipboard -- the lines are those of
+// the committed file, so a warning is shown if it has changed since
+func (ge *Gide) CopyPermalink() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf.Filename == "" {
+		return
+	}
+	fpath := string(tv.Buf.Filename)
+	dir := filepath.Dir(fpath)
+	top, err := gitOut(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		ge.SetStatus("Copy Permalink: the file is not in a git repository: " + err.Error())
+		return
+	}
+	commit, err := gitOut(dir, "rev-parse", "HEAD")
+	if err != nil {
+		ge.SetStatus("Copy Permalink: no current commit: " + err.Error())
+		return
+	}
+	remote, err := gitOut(dir, "remote", "get-url", "origin")
+	if err != nil {
+		rms, _ := gitOut(dir, "remote")
+		if rms == "" {
+			ge.SetStatus("Copy Permalink: the repository has no remote")
+			return
+		}
+		if remote, err = gitOut(dir, "remote", "get-url", strings.Fields(rms)[0]); err != nil {
+			ge.SetStatus("Copy Permalink: " + err.Error())
+			return
+		}
+	}
+	web, ok := RemoteWebURL(remote)
+	if !ok {
+		ge.SetStatus("Copy Permalink: the remote is not a web repository: " + remote)
+		return
+	}
+	rp, err := filepath.Rel(top, fpath)
+	if err != nil {
+		rp = filepath.Base(fpath)
+	}
+	st, ed := refLines(tv)
+	ur := Permalink(web, commit, rp, st, ed)
+	oswin.TheApp.ClipBoard(ge.ParentWindow().OSWin).Write(mimedata.NewTextBytes([]byte(ur)))
+	if chg, _ := gitOut(dir, "status", "--porcelain", "--", filepath.Base(fpath)); chg != "" || tv.Buf.IsChanged() {
+		ge.SetStatus("Copied permalink, but the file has changed since the commit, so its lines may differ: " + ur)
+		return
+	}
+	ge.SetStatus("Copied permalink: " + ur)
+}
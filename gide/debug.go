@@ -0,0 +1,392 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// DebugBreak is a breakpoint set in the editor, saved with the project
+type DebugBreak struct {
+	File     string `inactive:"+" desc:"full path to the file"`
+	Line     int    `inactive:"+" desc:"1-based line number"`
+	Group    string `desc:"group that the breakpoint belongs to, for enabling or disabling the breakpoints in the group together -- empty for none"`
+	Cond     string `desc:"condition for stopping, as a Go expression -- empty to always stop"`
+	Disabled bool   `desc:"if true, the breakpoint is not set in the debugger"`
+	ID       int    `json:"-" xml:"-" view:"-" desc:"id of the breakpoint in the running debugger, 0 if not set"`
+}
+
+// debugOut writes the output of the debugger and the program being debugged
+// to the Debug panel buffer
+type debugOut struct {
+	buf *giv.TextBuf
+}
+
+func (do *debugOut) Write(b []byte) (int, error) {
+	mu := []byte(html.EscapeString(string(b)))
+	do.buf.AppendTextMarkup(b, mu, false, true) // no undo, yes signal
+	do.buf.AutoScrollViews()
+	return len(b), nil
+}
+
+// DebugLog adds given line to the Debug panel output
+func (ge *Gide) DebugLog(msg string) {
+	buf, _ := ge.FindOrMakeCmdBuf("Debug", false)
+	do := &debugOut{buf: buf}
+	fmt.Fprintln(do, msg)
+}
+
+// DebugStart starts debugging the main package in the BuildDir of the project
+// (or the project root), using the Delve debugger (dlv), with the current
+// breakpoints set -- the program then runs to the first breakpoint
+func (ge *Gide) DebugStart() {
+	ge.debugStartTo(nil)
+}
+
+// debugStartTo starts debugging, running to given temporary breakpoint if
+// non-nil, as well as the user breakpoints
+func (ge *Gide) debugStartTo(tmp *DebugBreak) {
+	if ge.Dbg != nil {
+		ge.DebugStop()
+	}
+	dir := string(ge.Prefs.BuildDir)
+	if dir == "" {
+		dir = string(ge.ProjRoot)
+	}
+	buf, _, _, _ := ge.FindOrMakeCmdTab("Debug", true, true)
+	ge.SetStatus("Starting debugger in: " + dir)
+	go func() {
+		dc, err := StartDlv(dir, nil, ge.Prefs.DebugBackend, &debugOut{buf: buf})
+		if err != nil {
+			ge.DebugLog(err.Error())
+			ge.SetStatus("Debugger could not start: " + err.Error())
+			return
+		}
+		ge.Dbg = dc
+		for i := range ge.Prefs.Breaks {
+			ge.debugSetBreak(&ge.Prefs.Breaks[i])
+		}
+		if tmp != nil {
+			ge.debugSetBreak(tmp)
+		}
+		ge.debugRunTmp("continue", tmp)
+	}()
+}
+
+// debugSetBreak sets given breakpoint in the running debugger
+func (ge *Gide) debugSetBreak(bp *DebugBreak) {
+	if bp.Disabled {
+		return
+	}
+	dbp, err := ge.Dbg.CreateBreakpoint(bp.File, bp.Line, bp.Cond)
+	if err != nil {
+		ge.DebugLog(fmt.Sprintf("Breakpoint at %v:%d not set: %v", refRelPath(string(ge.ProjRoot), bp.File), bp.Line, err))
+		bp.ID = 0
+		return
+	}
+	bp.ID = dbp.ID
+}
+
+// DebugIsStopped returns true if the debugger is running and the program is
+// stopped, so it can be inspected, and otherwise sets a status message
+func (ge *Gide) DebugIsStopped() bool {
+	switch {
+	case ge.Dbg == nil:
+		ge.SetStatus("The debugger is not running -- use Debug > Start")
+		return false
+	case ge.DbgRunning:
+		ge.SetStatus("The program is running -- wait for it to stop, or use Debug > Pause")
+		return false
+	}
+	return true
+}
+
+// debugRun runs given debugger command, e.g., continue, in the background,
+// showing where the program stops
+func (ge *Gide) debugRun(cmd string) {
+	ge.debugRunTmp(cmd, nil)
+}
+
+// debugRunTmp runs given debugger command, clearing given temporary
+// breakpoint if non-nil when the program stops
+func (ge *Gide) debugRunTmp(cmd string, tmp *DebugBreak) {
+	if ge.DbgRunning {
+		return
+	}
+	ge.DbgRunning = true
+	ge.SetStatus("Debug: " + cmd + "...")
+	go func() {
+		dc := ge.Dbg
+		st, err := dc.Command(cmd)
+		ge.DbgRunning = false
+		if tmp != nil && tmp.ID != 0 {
+			dc.ClearBreakpoint(tmp.ID)
+		}
+		if err != nil {
+			ge.DebugLog(fmt.Sprintf("%v: %v", cmd, err))
+			ge.SetStatus("Debug: " + err.Error())
+			return
+		}
+		ge.DebugStopped(st)
+	}()
+}
+
+// DebugStopped shows where the program stopped, in the given state
+func (ge *Gide) DebugStopped(st *DlvState) {
+	if st.Exited {
+		msg := fmt.Sprintf("Program exited with status %d", st.ExitStatus)
+		ge.DebugLog(msg)
+		ge.SetStatus(msg)
+		ge.DebugStop()
+		return
+	}
+	ge.DebugRefreshViews()
+	th := st.CurrentThread
+	if th == nil || th.File == "" {
+		ge.SetStatus("Program stopped")
+		return
+	}
+	fn := ""
+	if th.Function != nil {
+		fn = th.Function.Name
+	}
+	msg := fmt.Sprintf("Stopped in %v at %v:%d", fn, refRelPath(string(ge.ProjRoot), th.File), th.Line)
+	ge.DebugLog(msg)
+	ge.SetStatus(msg)
+	tv, _, ok := ge.LinkViewFile(gi.FileName(th.File))
+	if !ok {
+		return
+	}
+	ln := th.Line - 1
+	if ln >= 0 && ln < len(tv.Buf.Lines) {
+		tv.HighlightRegion(giv.TextRegion{Start: giv.TextPos{Ln: ln}, End: giv.TextPos{Ln: ln, Ch: len(tv.Buf.Lines[ln])}})
+		tv.SetCursorShow(giv.TextPos{Ln: ln})
+	}
+}
+
+// DebugContinue continues running the program until the next breakpoint
+func (ge *Gide) DebugContinue() {
+	if ge.Dbg == nil {
+		ge.DebugStart()
+		return
+	}
+	if ge.DebugIsStopped() {
+		ge.debugRun("continue")
+	}
+}
+
+// DebugNext runs to the next line in the current function
+func (ge *Gide) DebugNext() {
+	if ge.DebugIsStopped() {
+		ge.debugRun("next")
+	}
+}
+
+// DebugStep steps into the function called on the current line
+func (ge *Gide) DebugStep() {
+	if ge.DebugIsStopped() {
+		ge.debugRun("step")
+	}
+}
+
+// DebugStepOut runs until the current function returns
+func (ge *Gide) DebugStepOut() {
+	if ge.DebugIsStopped() {
+		ge.debugRun("stepOut")
+	}
+}
+
+// DebugCanReverse returns true if the debugger is stopped and the program
+// is recorded, so it can be run in reverse, and otherwise sets a status
+// message saying why not
+func (ge *Gide) DebugCanReverse() bool {
+	if !ge.DebugIsStopped() {
+		return false
+	}
+	if !ge.Dbg.Recorded {
+		ge.SetStatus("Reverse execution needs a recording of the program -- set the project Debug Backend to rr (Linux only, with rr installed) and restart the debugger")
+		return false
+	}
+	return true
+}
+
+// DebugReverseContinue runs the program backwards to the previous breakpoint,
+// or the start of the recording
+func (ge *Gide) DebugReverseContinue() {
+	if ge.DebugCanReverse() {
+		ge.debugRun("rewind")
+	}
+}
+
+// DebugReverseNext runs backwards to the previous line in the current function
+func (ge *Gide) DebugReverseNext() {
+	if ge.DebugCanReverse() {
+		ge.debugRun("reverseNext")
+	}
+}
+
+// DebugReverseStep runs backwards into the function called on the previous line
+func (ge *Gide) DebugReverseStep() {
+	if ge.DebugCanReverse() {
+		ge.debugRun("reverseStep")
+	}
+}
+
+// DebugReverseStepOut runs backwards to where the current function was called
+func (ge *Gide) DebugReverseStepOut() {
+	if ge.DebugCanReverse() {
+		ge.debugRun("reverseStepOut")
+	}
+}
+
+// DebugPause stops the running program wherever it is
+func (ge *Gide) DebugPause() {
+	if ge.Dbg == nil || !ge.DbgRunning {
+		return
+	}
+	go ge.Dbg.Command("halt") // the running continue returns with the state
+}
+
+// DebugStop ends the debug session, killing the program
+func (ge *Gide) DebugStop() {
+	dc := ge.Dbg
+	if dc == nil {
+		return
+	}
+	ge.Dbg = nil
+	ge.DbgRunning = false
+	for i := range ge.Prefs.Breaks {
+		ge.Prefs.Breaks[i].ID = 0
+	}
+	go dc.Detach()
+	ge.SetStatus("Debugger stopped")
+}
+
+// DebugToggleBreak sets or clears a breakpoint on the line of the cursor in
+// the active view -- breakpoints are saved with the project
+func (ge *Gide) DebugToggleBreak() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	fpath := string(tv.Buf.Filename)
+	ln := tv.CursorPos.Ln + 1
+	for i, bp := range ge.Prefs.Breaks {
+		if bp.File == fpath && bp.Line == ln {
+			if ge.Dbg != nil && bp.ID != 0 {
+				ge.Dbg.ClearBreakpoint(bp.ID)
+			}
+			ge.Prefs.Breaks = append(ge.Prefs.Breaks[:i], ge.Prefs.Breaks[i+1:]...)
+			ge.Prefs.Changed = true
+			ge.SetStatus(fmt.Sprintf("Breakpoint cleared at line %d", ln))
+			return
+		}
+	}
+	ge.Prefs.Breaks = append(ge.Prefs.Breaks, DebugBreak{File: fpath, Line: ln})
+	ge.Prefs.Changed = true
+	if ge.Dbg != nil && !ge.DbgRunning {
+		ge.debugSetBreak(&ge.Prefs.Breaks[len(ge.Prefs.Breaks)-1])
+	}
+	ge.SetStatus(fmt.Sprintf("Breakpoint set at line %d", ln))
+}
+
+// DebugRunToCursor runs the program until it gets to the line of the cursor
+// in the active view (or another breakpoint), starting the debugger if needed
+func (ge *Gide) DebugRunToCursor() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	tmp := &DebugBreak{File: string(tv.Buf.Filename), Line: tv.CursorPos.Ln + 1}
+	for _, bp := range ge.Prefs.Breaks {
+		if bp.File == tmp.File && bp.Line == tmp.Line {
+			tmp = nil // already stops there
+			break
+		}
+	}
+	if ge.Dbg == nil {
+		ge.debugStartTo(tmp)
+		return
+	}
+	if !ge.DebugIsStopped() {
+		return
+	}
+	if tmp != nil {
+		ge.debugSetBreak(tmp)
+		if tmp.ID == 0 {
+			ge.SetStatus("Cannot run to cursor: no code on that line")
+			return
+		}
+	}
+	ge.debugRunTmp("continue", tmp)
+}
+
+// DebugEvalExpr returns the value of given expression in the stopped program,
+// as a string
+func (ge *Gide) DebugEvalExpr(expr string) (string, error) {
+	v, err := ge.Dbg.Eval(expr)
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
+// debugExprAtCursor returns the selection in the active view, or the word at
+// the cursor, for evaluating
+func (ge *Gide) debugExprAtCursor() (*giv.TextView, string) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return nil, ""
+	}
+	if tv.HasSelection() {
+		return tv, strings.TrimSpace(string(tv.Selection().ToBytes()))
+	}
+	if tv.CursorPos.Ln >= len(tv.Buf.Lines) {
+		return tv, ""
+	}
+	word, _, _ := WordAtPos(tv.Buf.Lines[tv.CursorPos.Ln], tv.CursorPos.Ch)
+	return tv, word
+}
+
+// DebugEvalSelection evaluates the selected expression (or the word at the
+// cursor) in the stopped program, and prints the result in the Debug panel
+func (ge *Gide) DebugEvalSelection() {
+	if !ge.DebugIsStopped() {
+		return
+	}
+	_, expr := ge.debugExprAtCursor()
+	if expr == "" {
+		return
+	}
+	val, err := ge.DebugEvalExpr(expr)
+	if err != nil {
+		val = err.Error()
+	}
+	ge.DebugLog("> " + expr + " = " + val)
+	ge.SelectMainTabByName("Debug")
+}
+
+// DebugEvalAtCursor shows the value of the variable at the cursor (or the
+// selected expression) in the stopped program, in a popup
+func (ge *Gide) DebugEvalAtCursor() {
+	if !ge.DebugIsStopped() {
+		return
+	}
+	tv, expr := ge.debugExprAtCursor()
+	if expr == "" {
+		return
+	}
+	val, err := ge.DebugEvalExpr(expr)
+	if err != nil {
+		ge.SetStatus(err.Error())
+		return
+	}
+	ge.PopupAtPos(tv, tv.CursorPos, "<b>"+html.EscapeString(expr)+"</b> = "+DocPopupMarkup(val), "gide-debug-eval")
+}
@@ -0,0 +1,385 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki"
+)
+
+// DiagLensClass is the css class of the messages of the diagnostics shown
+// at the end of their lines -- dimmed, as comments
+var DiagLensClass = "c"
+
+// DiagLensMax is the maximum number of chars of a message shown at the end
+// of its line -- the full message is shown on hovering over it, or with
+// Show Diagnostic
+var DiagLensMax = 100
+
+// DiagInlineMin is the least severe diagnostic shown inline -- hints are
+// only listed in the Problems panel
+var DiagInlineMin = LspSevInfo
+
+// DiagGlyphs are the marks shown before the messages at the end of lines,
+// by severity
+var DiagGlyphs = map[LspDiagSeverity]string{
+	LspSevError:   "✖",
+	LspSevWarning: "▲",
+	LspSevInfo:    "●",
+	LspSevHint:    "…",
+}
+
+// DiagColors are the colors of the line numbers of the lines with
+// diagnostics, by severity
+var DiagColors = map[LspDiagSeverity]string{
+	LspSevError:   "#F44",
+	LspSevWarning: "#EA0",
+	LspSevInfo:    "#4AF",
+	LspSevHint:    "#AAA",
+}
+
+// DiagSev returns the severity of given diagnostic, servers leaving it out
+// for errors
+func DiagSev(dg *LspDiagnostic) LspDiagSeverity {
+	if dg.Severity < LspSevError || dg.Severity > LspSevHint {
+		return LspSevError
+	}
+	return dg.Severity
+}
+
+// DiagCmdErrors returns the errors from the output of a command (e.g., a
+// build or linter) that are for given file, as diagnostics -- those whose
+// messages start with warning: are warnings
+func DiagCmdErrors(errs []CmdError, fpath, source string) []LspDiagnostic {
+	var diags []LspDiagnostic
+	for _, ce := range errs {
+		if ce.Path != fpath || ce.Line < 1 {
+			continue
+		}
+		sev := LspSevError
+		msg := ce.Msg
+		if strings.HasPrefix(strings.ToLower(msg), "warning:") {
+			sev = LspSevWarning
+			msg = strings.TrimSpace(msg[len("warning:"):])
+		}
+		ch := 0
+		if ce.Col > 0 {
+			ch = ce.Col - 1
+		}
+		pos := LspPosition{Line: ce.Line - 1, Character: ch}
+		diags = append(diags, LspDiagnostic{Range: LspRange{Start: pos, End: pos}, Severity: sev, Source: source, Message: msg})
+	}
+	return diags
+}
+
+// DiagSpans returns the spans of the text of each of given lines that are
+// in the ranges of given diagnostics of at least given severity, for
+// underlining -- an empty range underlines the word at its start, or the
+// last char of the line if it is at its end
+func DiagSpans(diags []LspDiagnostic, lines []string, min LspDiagSeverity) [][]HiSpan {
+	var spans [][]HiSpan
+	for i := range diags {
+		dg := &diags[i]
+		if DiagSev(dg) > min {
+			continue
+		}
+		st, ed := dg.Range.Start, dg.Range.End
+		if ed.Line < st.Line || (ed.Line == st.Line && ed.Character < st.Character) {
+			ed = st
+		}
+		for ln := st.Line; ln <= ed.Line && ln < len(lines); ln++ {
+			if ln < 0 {
+				continue
+			}
+			rs := []rune(lines[ln])
+			s, e := 0, len(rs)
+			if ln == st.Line {
+				s = st.Character
+			}
+			if ln == ed.Line {
+				e = ed.Character
+			}
+			if s > len(rs) {
+				s = len(rs)
+			}
+			if e > len(rs) {
+				e = len(rs)
+			}
+			if s >= e && ln == st.Line && ln == ed.Line {
+				s, e = diagWord(rs, s)
+			}
+			if s >= e {
+				continue
+			}
+			if spans == nil {
+				spans = make([][]HiSpan, len(lines))
+			}
+			spans[ln] = append(spans[ln], HiSpan{St: s, Ed: e})
+		}
+	}
+	return spans
+}
+
+// diagWord returns the extent of the word at given position in given line,
+// or of the char there if it is not in a word, or of the last char if it
+// is at the end of the line
+func diagWord(rs []rune, pos int) (int, int) {
+	if len(rs) == 0 {
+		return 0, 0
+	}
+	if pos >= len(rs) {
+		return len(rs) - 1, len(rs)
+	}
+	word := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' }
+	if !word(rs[pos]) {
+		return pos, pos + 1
+	}
+	s, e := pos, pos
+	for s > 0 && word(rs[s-1]) {
+		s--
+	}
+	for e < len(rs) && word(rs[e]) {
+		e++
+	}
+	return s, e
+}
+
+// DiagLineMsgs returns the most severe of given diagnostics of at least
+// given severity that start on each line, along with the number of them on
+// each line -- for showing their messages at the ends of the lines
+func DiagLineMsgs(diags []LspDiagnostic, min LspDiagSeverity) (map[int]LspDiagnostic, map[int]int) {
+	msgs := map[int]LspDiagnostic{}
+	ns := map[int]int{}
+	for i := range diags {
+		dg := &diags[i]
+		sev := DiagSev(dg)
+		if sev > min {
+			continue
+		}
+		ln := dg.Range.Start.Line
+		ns[ln]++
+		if cur, has := msgs[ln]; !has || sev < DiagSev(&cur) {
+			msgs[ln] = *dg
+		}
+	}
+	return msgs, ns
+}
+
+// DiagLensMarkup returns the markup shown at the end of a line for given
+// diagnostic, of n on the line: its first line, cut at DiagLensMax chars
+func DiagLensMarkup(dg LspDiagnostic, n int) []byte {
+	msg := strings.TrimSpace(dg.Message)
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		msg = strings.TrimSpace(msg[:i]) + " …"
+	}
+	if rs := []rune(msg); len(rs) > DiagLensMax {
+		msg = string(rs[:DiagLensMax]) + "…"
+	}
+	if n > 1 {
+		msg += fmt.Sprintf(" (+%d more)", n-1)
+	}
+	var b bytes.Buffer
+	hiWriteSpan(&b, DiagLensClass, "    "+DiagGlyphs[DiagSev(&dg)]+" "+msg)
+	return b.Bytes()
+}
+
+// DiagState is the inline display of the diagnostics of a buffer: the
+// underlined spans of their ranges, and the messages at the ends of lines
+type DiagState struct {
+	Under  [][]HiSpan     `desc:"spans of the ranges of the diagnostics in each line"`
+	Lens   map[int][]byte `desc:"markup of the message shown at the end of each line that has one"`
+	Gutter []int          `desc:"lines whose numbers are colored"`
+}
+
+// DiagsFor returns the diagnostics for given buffer: those reported by its
+// language server, and the errors for it in the output of the last failed
+// run of each command, e.g., a build or linter, until it runs without error
+func (ge *Gide) DiagsFor(tb *giv.TextBuf) []LspDiagnostic {
+	fpath := string(tb.Filename)
+	var diags []LspDiagnostic
+	if lc := ge.LspClientForBuf(tb); lc != nil {
+		diags = append(diags, lc.Diagnostics(fpath)...)
+	}
+	nms := make([]string, 0, len(ge.cmdDiags))
+	for nm := range ge.cmdDiags {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	for _, nm := range nms {
+		diags = append(diags, DiagCmdErrors(ge.cmdDiags[nm], fpath, nm)...)
+	}
+	return diags
+}
+
+// diagsMaybe returns true if given buffer may have diagnostics to show
+// inline
+func (ge *Gide) diagsMaybe(tb *giv.TextBuf) bool {
+	return ge.Prefs.Editor.DiagInline && (ge.diagStates[tb] != nil || len(ge.cmdDiags) > 0 || ge.LspClientForBuf(tb) != nil)
+}
+
+// DiagMarkupBuf updates the inline display of the diagnostics of given
+// buffer, with given lines, for hiOverlay -- the line numbers of the lines
+// with diagnostics are colored by their severity -- must be called with
+// hiMu locked
+func (ge *Gide) DiagMarkupBuf(tb *giv.TextBuf, lines []string) {
+	old := ge.diagStates[tb]
+	if old != nil {
+		for _, ln := range old.Gutter {
+			tb.DeleteLineColor(ln)
+		}
+	}
+	var diags []LspDiagnostic
+	if ge.Prefs.Editor.DiagInline {
+		diags = ge.DiagsFor(tb)
+	}
+	if len(diags) == 0 {
+		delete(ge.diagStates, tb)
+		return
+	}
+	ds := &DiagState{Under: DiagSpans(diags, lines, DiagInlineMin), Lens: map[int][]byte{}}
+	msgs, ns := DiagLineMsgs(diags, DiagInlineMin)
+	for ln, dg := range msgs {
+		if ln < 0 || ln >= len(lines) {
+			continue
+		}
+		ds.Lens[ln] = DiagLensMarkup(dg, ns[ln])
+		tb.SetLineColor(ln, DiagColors[DiagSev(&dg)])
+		ds.Gutter = append(ds.Gutter, ln)
+	}
+	if ge.diagStates == nil {
+		ge.diagStates = make(map[*giv.TextBuf]*DiagState)
+	}
+	ge.diagStates[tb] = ds
+}
+
+// DiagsPublished shows the diagnostics just published by a language server
+// for given document, if it is open, and updates the Problems panel if it
+// is open -- run on the gui by the LspClients DiagFunc
+func (ge *Gide) DiagsPublished(uri string, diags []LspDiagnostic) {
+	fpath := LspPath(uri)
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil && string(ond.Buf.Filename) == fpath {
+			ge.HiMarkupBuf(ond.Buf)
+			ond.Buf.RefreshViews()
+			break
+		}
+	}
+	ge.problemsRefresh()
+}
+
+// problemsRefresh refreshes the Problems panel, if it is open
+func (ge *Gide) problemsRefresh() {
+	if pvi, _, ok := ge.MainTabByName("Problems"); ok {
+		if pv, ok := pvi.Embed(KiT_ProblemsView).(*ProblemsView); ok && pv.Gide != nil {
+			pv.Refresh()
+		}
+	}
+}
+
+// DiagCmdDone records the errors in the output of given command, which
+// failed if !ok, for showing inline in their files, or clears them if it
+// succeeded -- then updates the display of the open files
+func (ge *Gide) DiagCmdDone(cm *Command, ok bool) {
+	if ge.cmdDiags == nil {
+		ge.cmdDiags = make(map[string][]CmdError)
+	}
+	_, had := ge.cmdDiags[cm.Name]
+	if ok {
+		delete(ge.cmdDiags, cm.Name)
+	} else {
+		ge.cmdDiags[cm.Name] = ge.CmdErrs
+	}
+	if !had && (ok || len(ge.CmdErrs) == 0) {
+		return
+	}
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil {
+			ge.HiMarkupBuf(ond.Buf)
+			ond.Buf.RefreshViews()
+		}
+	}
+	ge.problemsRefresh()
+}
+
+// ProjProblems returns all the problems of the project: those reported by
+// the language servers, and the errors in the output of the last failed run
+// of each command, e.g., a build or linter
+func (ge *Gide) ProjProblems() []Problem {
+	diags := ge.Lsp.AllDiags()
+	nms := make([]string, 0, len(ge.cmdDiags))
+	for nm := range ge.cmdDiags {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	for _, nm := range nms {
+		done := map[string]bool{}
+		for _, ce := range ge.cmdDiags[nm] {
+			if done[ce.Path] {
+				continue
+			}
+			done[ce.Path] = true
+			uri := LspURI(ce.Path)
+			diags[uri] = append(diags[uri], DiagCmdErrors(ge.cmdDiags[nm], ce.Path, nm)...)
+		}
+	}
+	return ProblemsFromDiags(diags, string(ge.ProjRoot))
+}
+
+// DiagAt returns the diagnostics of given buffer whose ranges include
+// given position, as shown inline
+func (ge *Gide) DiagAt(tb *giv.TextBuf, pos giv.TextPos) []LspDiagnostic {
+	var at []LspDiagnostic
+	for _, dg := range ge.DiagsFor(tb) {
+		st, ed := dg.Range.Start, dg.Range.End
+		if pos.Ln < st.Line || pos.Ln > ed.Line {
+			continue
+		}
+		if st.Line == ed.Line && st.Character < ed.Character && (pos.Ch < st.Character || pos.Ch > ed.Character) {
+			continue
+		}
+		at = append(at, dg)
+	}
+	return at
+}
+
+// DiagHoverEvents shows the full messages of the diagnostics under the
+// mouse in a popup when it hovers over the text views
+func (ge *Gide) DiagHoverEvents() {
+	for _, tv := range ge.PaneViews {
+		tvv := tv
+		tvv.ConnectEvent(oswin.MouseHoverEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			if !ge.Prefs.Editor.DiagInline || tvv.Buf == nil {
+				return
+			}
+			me := d.(*mouse.HoverEvent)
+			pos := tvv.PixelToCursor(me.Pos())
+			diags := ge.DiagAt(tvv.Buf, pos)
+			if len(diags) == 0 {
+				return
+			}
+			mu := ""
+			for i, dg := range diags {
+				if i > 0 {
+					mu += "<br>"
+				}
+				mu += fmt.Sprintf("<b>%v</b>: %v", DiagSev(&dg), html.EscapeString(dg.Message))
+				if dg.Source != "" {
+					mu += fmt.Sprintf(" (%v)", html.EscapeString(dg.Source))
+				}
+			}
+			ge.PopupAtPos(tvv, pos, mu, "gide-diag")
+		})
+	}
+}
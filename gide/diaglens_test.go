@@ -0,0 +1,82 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func diagAt(ln, st, eln, ed int, sev LspDiagSeverity, msg string) LspDiagnostic {
+	return LspDiagnostic{Range: LspRange{Start: LspPosition{Line: ln, Character: st}, End: LspPosition{Line: eln, Character: ed}}, Severity: sev, Message: msg}
+}
+
+func TestDiagSpans(t *testing.T) {
+	lines := []string{"x := foo(y)", "", "\treturn bar", "end"}
+	diags := []LspDiagnostic{
+		diagAt(0, 5, 0, 8, LspSevError, "undefined: foo"),
+		diagAt(0, 9, 0, 9, LspSevWarning, "empty range in a word"),
+		diagAt(2, 8, 3, 1, 0, "spans lines"),
+		diagAt(3, 9, 3, 9, LspSevHint, "hint, left out"),
+		diagAt(3, 3, 3, 3, LspSevInfo, "at the end"),
+	}
+	sp := DiagSpans(diags, lines, LspSevInfo)
+	want := [][]HiSpan{
+		{{St: 5, Ed: 8}, {St: 9, Ed: 10}},
+		nil,
+		{{St: 8, Ed: 11}},
+		{{St: 0, Ed: 1}, {St: 2, Ed: 3}},
+	}
+	if !reflect.DeepEqual(sp, want) {
+		t.Errorf("got %v\nwant %v", sp, want)
+	}
+	if sp := DiagSpans(diags[3:4], lines, LspSevInfo); sp != nil {
+		t.Errorf("hint only: got %v", sp)
+	}
+}
+
+func TestDiagLineMsgs(t *testing.T) {
+	diags := []LspDiagnostic{
+		diagAt(1, 0, 1, 2, LspSevWarning, "unused"),
+		diagAt(1, 4, 1, 5, LspSevError, "undefined"),
+		diagAt(1, 6, 1, 7, LspSevError, "second error"),
+		diagAt(4, 0, 4, 1, LspSevHint, "hint"),
+		diagAt(5, 0, 5, 1, 0, "no severity"),
+	}
+	msgs, ns := DiagLineMsgs(diags, LspSevInfo)
+	if len(msgs) != 2 || msgs[1].Message != "undefined" || msgs[5].Message != "no severity" {
+		t.Errorf("msgs: %v", msgs)
+	}
+	if ns[1] != 3 || ns[4] != 0 {
+		t.Errorf("counts: %v", ns)
+	}
+	mu := string(DiagLensMarkup(msgs[1], ns[1]))
+	if want := `<span class="c">    ✖ undefined (+2 more)</span>`; mu != want {
+		t.Errorf("markup: got %q, want %q", mu, want)
+	}
+	long := diagAt(0, 0, 0, 0, LspSevWarning, "a <b> & c\nmore detail")
+	if mu := string(DiagLensMarkup(long, 1)); !strings.Contains(mu, "▲ a &lt;b&gt; &amp; c …") || strings.Contains(mu, "detail") {
+		t.Errorf("first line: got %q", mu)
+	}
+}
+
+func TestDiagCmdErrors(t *testing.T) {
+	errs := []CmdError{
+		{Path: "/p/a.go", Line: 3, Col: 7, Msg: "undefined: x"},
+		{Path: "/p/b.go", Line: 1, Col: 1, Msg: "other file"},
+		{Path: "/p/a.go", Line: 9, Msg: "warning: shadowed"},
+	}
+	diags := DiagCmdErrors(errs, "/p/a.go", "Build Go")
+	if len(diags) != 2 {
+		t.Fatalf("got %v", diags)
+	}
+	if d := diags[0]; d.Range.Start != (LspPosition{Line: 2, Character: 6}) || d.Severity != LspSevError || d.Source != "Build Go" {
+		t.Errorf("error: %+v", d)
+	}
+	if d := diags[1]; d.Range.Start != (LspPosition{Line: 8}) || d.Severity != LspSevWarning || d.Message != "shadowed" {
+		t.Errorf("warning: %+v", d)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"sort"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+)
+
+// ApplyLspTextEdits applies given edits to given buffer, as one undoable
+// set of changes per edit -- edits must not overlap
+func ApplyLspTextEdits(tb *giv.TextBuf, edits []LspTextEdit) {
+	se := make([]LspTextEdit, len(edits))
+	copy(se, edits)
+	sort.SliceStable(se, func(i, j int) bool { // last first, so positions stay valid
+		a, b := se[i].Range.Start, se[j].Range.Start
+		if a.Line == b.Line {
+			return a.Character > b.Character
+		}
+		return a.Line > b.Line
+	})
+	for _, ed := range se {
+		st := giv.TextPos{Ln: ed.Range.Start.Line, Ch: ed.Range.Start.Character}
+		en := giv.TextPos{Ln: ed.Range.End.Line, Ch: ed.Range.End.Character}
+		if st != en {
+			tb.DeleteText(st, en, true, true)
+		}
+		if ed.NewText != "" {
+			tb.InsertText(st, []byte(ed.NewText), true, true)
+		}
+	}
+}
+
+// ApplyWorkspaceEdit applies given edits to the buffers of the files in the
+// project, opening them as needed -- returns the buffers that were edited,
+// and an error for any files that are not in the project (not edited)
+func (ge *Gide) ApplyWorkspaceEdit(we *LspWorkspaceEdit) ([]*giv.TextBuf, error) {
+	var tbs []*giv.TextBuf
+	var err error
+	for uri, edits := range we.Changes {
+		fpath := LspPath(uri)
+		tb, inProj, ferr := ge.BufForFile(gi.FileName(fpath))
+		if ferr != nil || !inProj {
+			err = fmt.Errorf("could not edit file outside of project: %v", fpath)
+			continue
+		}
+		ge.OpTouch(tb)
+		ApplyLspTextEdits(tb, edits)
+		tbs = append(tbs, tb)
+	}
+	return tbs, err
+}
+
+// WorkspaceEditDiff returns a unified diff preview of the changes that
+// given edits would make, for all files, without changing anything
+func (ge *Gide) WorkspaceEditDiff(we *LspWorkspaceEdit) []byte {
+	uris := make([]string, 0, len(we.Changes))
+	for uri := range we.Changes {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	var dif []byte
+	for _, uri := range uris {
+		fpath := LspPath(uri)
+		tb, _, err := ge.BufForFile(gi.FileName(fpath))
+		if err != nil {
+			continue
+		}
+		ntb := &giv.TextBuf{}
+		ntb.InitName(ntb, "edit-preview")
+		ntb.SetText([]byte(LspApplyEdits(string(tb.LinesToBytesCopy()), we.Changes[uri])))
+		dif = append(dif, []byte(fmt.Sprintf("%v:\n", refRelPath(string(ge.ProjRoot), fpath)))...)
+		dif = append(dif, tb.DiffBufsUnified(ntb, 2)...)
+	}
+	return dif
+}
+
+// DiagsAtCursor returns the language server diagnostics for the line of the
+// cursor in the active text view, along with the client, view and filename
+func (ge *Gide) DiagsAtCursor() (*LspClient, *giv.TextView, string, []LspDiagnostic) {
+	lc, tv, fpath := ge.LspClientForActive()
+	if lc == nil {
+		return nil, tv, "", nil
+	}
+	ln := tv.CursorPos.Ln
+	var diags []LspDiagnostic
+	for _, dg := range lc.Diagnostics(fpath) {
+		if dg.Range.Start.Line <= ln && ln <= dg.Range.End.Line {
+			diags = append(diags, dg)
+		}
+	}
+	return lc, tv, fpath, diags
+}
+
+// ShowDiag expands the errors and warnings reported by the language server
+// for the line of the cursor in the active view, in a popup just below the
+// line: it shows the full messages, with links to related locations, and
+// a preview of the changes made by any available quick fixes, which can be
+// applied with their Apply button
+func (ge *Gide) ShowDiag() {
+	lc, tv, fpath, diags := ge.DiagsAtCursor()
+	if lc == nil {
+		ge.SetStatus("Errors are reported by the language server for this file -- see LangServers in Preferences")
+		return
+	}
+	if len(diags) == 0 {
+		ge.SetStatus("No errors or warnings on this line")
+		return
+	}
+	rng := diags[0].Range
+	for _, dg := range diags[1:] {
+		if dg.Range.End.Line > rng.End.Line {
+			rng.End = dg.Range.End
+		}
+	}
+	cas, _ := lc.CodeActions(fpath, rng, diags)
+
+	prompt := ""
+	for i, dg := range diags {
+		if i > 0 {
+			prompt += "<br>"
+		}
+		prompt += fmt.Sprintf("<b>%v</b>: %v", dg.Severity, html.EscapeString(dg.Message))
+		if dg.Source != "" {
+			prompt += fmt.Sprintf(" (%v)", html.EscapeString(dg.Source))
+		}
+	}
+	title := fmt.Sprintf("%v:%d", refRelPath(string(ge.ProjRoot), fpath), tv.CursorPos.Ln+1)
+	dlg := gi.NewStdDialog(gi.DlgOpts{Title: title, Prompt: prompt}, false, false)
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	idx := prIdx + 1
+
+	for _, dg := range diags {
+		for _, ri := range dg.Related {
+			loc := ri.Location
+			rfp := LspPath(loc.URI)
+			ra := frame.InsertNewChild(gi.KiT_Action, idx, fmt.Sprintf("related-%d", idx)).(*gi.Action)
+			idx++
+			ra.SetText(fmt.Sprintf("%v:%d: %v", refRelPath(string(ge.ProjRoot), rfp), loc.Range.Start.Line+1, ri.Message))
+			ra.Tooltip = "go to this related location"
+			ra.SetProp("text-align", gi.AlignLeft)
+			ra.ActionSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				gee, _ := recv.Embed(KiT_Gide).(*Gide)
+				dlg.Cancel()
+				gee.NavJump(NavPos{Filename: gi.FileName(rfp), Pos: giv.TextPos{Ln: loc.Range.Start.Line, Ch: loc.Range.Start.Character}})
+			})
+		}
+	}
+
+	for i := range cas {
+		ca := cas[i]
+		hd := frame.InsertNewChild(gi.KiT_Layout, idx, fmt.Sprintf("fix-hdr-%d", i)).(*gi.Layout)
+		idx++
+		hd.Lay = gi.LayoutHoriz
+		hd.SetStretchMaxWidth()
+		lbl := hd.AddNewChild(gi.KiT_Label, "title").(*gi.Label)
+		lbl.SetText("Fix: <b>" + html.EscapeString(ca.Title) + "</b>")
+		lbl.SetStretchMaxWidth()
+		ab := hd.AddNewChild(gi.KiT_Button, "apply").(*gi.Button)
+		ab.SetText("Apply")
+		ab.Tooltip = "apply this fix -- it can be undone in the editor"
+		ab.ButtonSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.ButtonClicked) {
+				return
+			}
+			gee, _ := recv.Embed(KiT_Gide).(*Gide)
+			dlg.Accept()
+			if _, err := gee.ApplyWorkspaceEdit(ca.Edit); err != nil {
+				gee.SetStatus(fmt.Sprintf("Fix applied, but: %v", err))
+			} else {
+				gee.SetStatus("Fix applied: " + ca.Title)
+			}
+		})
+
+		ly := frame.InsertNewChild(gi.KiT_Layout, idx, fmt.Sprintf("fix-diff-%d", i)).(*gi.Layout)
+		idx++
+		ly.SetProp("width", units.NewValue(80, units.Ch))
+		ly.SetProp("height", units.NewValue(10, units.Em))
+		dtv := ge.ConfigOutputTextView(ly)
+		dtv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+		dtb := &giv.TextBuf{}
+		dtb.InitName(dtb, "fix-diff")
+		dtb.SetText(ge.WorkspaceEditDiff(ca.Edit))
+		dtv.SetBuf(dtb)
+	}
+
+	dlg.UpdateEndNoSig(true)
+	cpos := tv.CharStartPos(giv.TextPos{Ln: tv.CursorPos.Ln}).ToPoint()
+	dlg.Open(cpos.X, cpos.Y+int(tv.LineHeight), tv.Viewport, nil)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+// DiffKind is the kind of difference between the two sides in a row of a
+// side-by-side diff
+type DiffKind int
+
+const (
+	// DiffEqual is a line that is the same on both sides
+	DiffEqual DiffKind = iota
+
+	// DiffChanged is a line that is different on the two sides
+	DiffChanged
+
+	// DiffDeleted is a line that is only on the A side
+	DiffDeleted
+
+	// DiffInserted is a line that is only on the B side
+	DiffInserted
+)
+
+// DiffRow is one row of a side-by-side diff, with the index of the line on
+// each side, or -1 where there is a gap on that side
+type DiffRow struct {
+	A, B int
+	Kind DiffKind
+}
+
+// diffMatches returns the pairs of indexes of matching lines in a and b, in
+// order, for a shortest edit script (Myers' algorithm)
+func diffMatches(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	max := n + m
+	off := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+outer:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break outer
+			}
+		}
+	}
+	var rev [][2]int
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		tv := trace[d]
+		k := x - y
+		var pk int
+		if k == -d || (k != d && tv[off+k-1] < tv[off+k+1]) {
+			pk = k + 1
+		} else {
+			pk = k - 1
+		}
+		px := tv[off+pk]
+		py := px - pk
+		for x > px && y > py {
+			x--
+			y--
+			rev = append(rev, [2]int{x, y})
+		}
+		x, y = px, py
+	}
+	ms := make([][2]int, len(rev))
+	for i := range rev {
+		ms[i] = rev[len(rev)-1-i]
+	}
+	return ms
+}
+
+// DiffRows returns the rows of a side-by-side diff of lines a and b: equal
+// lines are aligned, and within each block of differences the lines are
+// paired up as changed, with the rest deleted or inserted
+func DiffRows(a, b []string) []DiffRow {
+	var rows []DiffRow
+	ai, bi := 0, 0
+	block := func(ae, be int) {
+		for ai < ae && bi < be {
+			rows = append(rows, DiffRow{A: ai, B: bi, Kind: DiffChanged})
+			ai++
+			bi++
+		}
+		for ; ai < ae; ai++ {
+			rows = append(rows, DiffRow{A: ai, B: -1, Kind: DiffDeleted})
+		}
+		for ; bi < be; bi++ {
+			rows = append(rows, DiffRow{A: -1, B: bi, Kind: DiffInserted})
+		}
+	}
+	for _, mt := range diffMatches(a, b) {
+		block(mt[0], mt[1])
+		rows = append(rows, DiffRow{A: ai, B: bi, Kind: DiffEqual})
+		ai++
+		bi++
+	}
+	block(len(a), len(b))
+	return rows
+}
+
+// DiffHunks returns the index of the first row of each block of differences
+func DiffHunks(rows []DiffRow) []int {
+	var hks []int
+	for i, r := range rows {
+		if r.Kind != DiffEqual && (i == 0 || rows[i-1].Kind == DiffEqual) {
+			hks = append(hks, i)
+		}
+	}
+	return hks
+}
+
+// DiffIntraLine returns the changed part of two versions of a line, as the
+// start, which is the same in both, and the end in a and in b, leaving out
+// any common prefix and suffix
+func DiffIntraLine(a, b []rune) (st, aed, bed int) {
+	for st < len(a) && st < len(b) && a[st] == b[st] {
+		st++
+	}
+	aed, bed = len(a), len(b)
+	for aed > st && bed > st && a[aed-1] == b[bed-1] {
+		aed--
+		bed--
+	}
+	return
+}
+
+// DiffStat returns the number of lines inserted and deleted in given rows of
+// a diff, with a changed line counting as one of each
+func DiffStat(rows []DiffRow) (ins, del int) {
+	for _, r := range rows {
+		switch r.Kind {
+		case DiffChanged:
+			ins++
+			del++
+		case DiffInserted:
+			ins++
+		case DiffDeleted:
+			del++
+		}
+	}
+	return
+}
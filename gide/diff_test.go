@@ -0,0 +1,67 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffRows(t *testing.T) {
+	a := strings.Split("a\nb\nc\nd\ne", "\n")
+	b := strings.Split("a\nB\nc\nx\ny\ne", "\n")
+	rows := DiffRows(a, b)
+	want := []DiffRow{
+		{0, 0, DiffEqual},
+		{1, 1, DiffChanged},
+		{2, 2, DiffEqual},
+		{3, 3, DiffChanged},
+		{-1, 4, DiffInserted},
+		{4, 5, DiffEqual},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("DiffRows: got %v, want %v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("DiffRows row %d: got %v, want %v", i, rows[i], want[i])
+		}
+	}
+	hks := DiffHunks(rows)
+	if len(hks) != 2 || hks[0] != 1 || hks[1] != 3 {
+		t.Errorf("DiffHunks: got %v", hks)
+	}
+
+	rows = DiffRows(nil, []string{"x"})
+	if len(rows) != 1 || rows[0] != (DiffRow{-1, 0, DiffInserted}) {
+		t.Errorf("DiffRows from empty: got %v", rows)
+	}
+	rows = DiffRows([]string{"x", "y"}, nil)
+	if len(rows) != 2 || rows[1] != (DiffRow{1, -1, DiffDeleted}) {
+		t.Errorf("DiffRows to empty: got %v", rows)
+	}
+}
+
+func TestDiffIntraLine(t *testing.T) {
+	st, aed, bed := DiffIntraLine([]rune("x := foo(a)"), []rune("x := bar(a, b)"))
+	if st != 5 || aed != 10 || bed != 13 {
+		t.Errorf("DiffIntraLine: got %d %d %d", st, aed, bed)
+	}
+	st, aed, bed = DiffIntraLine([]rune("same"), []rune("same"))
+	if st != 4 || aed != 4 || bed != 4 {
+		t.Errorf("DiffIntraLine equal: got %d %d %d", st, aed, bed)
+	}
+}
+
+func TestDiffStat(t *testing.T) {
+	a := strings.Split("a\nb\nc\nd\ne", "\n")
+	b := strings.Split("a\nB\nc\nx\ny", "\n")
+	if ins, del := DiffStat(DiffRows(a, b)); ins != 3 || del != 3 {
+		t.Errorf("DiffStat: got +%d -%d, want +3 -3", ins, del)
+	}
+	if ins, del := DiffStat(DiffRows(a, a)); ins != 0 || del != 0 {
+		t.Errorf("DiffStat of equal lines: got +%d -%d", ins, del)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/goki/gi/giv"
+)
+
+// DiffSaveMinSize is the size, in bytes, from which buffers are saved with
+// a differential save, which only writes the chunks of the file that have
+// changed, in the background with the progress in the status bar
+var DiffSaveMinSize = 32 * 1024 * 1024
+
+// DiffSaveChunk is the size of the chunks that a differential save compares
+// and writes
+var DiffSaveChunk = 1024 * 1024
+
+// DiffSaveFile writes given contents to the existing file at given path,
+// only writing the chunks that differ from those in the file -- if the
+// size changes, everything from the first chunk that differs is written --
+// progress is called after each chunk with the number of bytes done and
+// the total -- returns the number of bytes written
+func DiffSaveFile(fpath string, data []byte, chunk int, progress func(done, total int64)) (int64, error) {
+	f, err := os.OpenFile(fpath, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if chunk <= 0 {
+		chunk = DiffSaveChunk
+	}
+	total := int64(len(data))
+	sameSize := st.Size() == total
+	buf := make([]byte, chunk)
+	var written int64
+	for off := int64(0); off < total; off += int64(chunk) {
+		end := off + int64(chunk)
+		if end > total {
+			end = total
+		}
+		want := data[off:end]
+		n, err := f.ReadAt(buf[:len(want)], off)
+		if err != nil && err != io.EOF {
+			return written, err
+		}
+		if n == len(want) && bytes.Equal(buf[:n], want) {
+			if progress != nil {
+				progress(end, total)
+			}
+			continue
+		}
+		if !sameSize { // the rest of the file shifts
+			want = data[off:]
+			end = total
+		}
+		if _, err := f.WriteAt(want, off); err != nil {
+			return written, err
+		}
+		written += int64(len(want))
+		if progress != nil {
+			progress(end, total)
+		}
+		if !sameSize {
+			break
+		}
+	}
+	if !sameSize {
+		if err := f.Truncate(total); err != nil {
+			return written, err
+		}
+	}
+	return written, f.Sync()
+}
+
+// DiffSaveData returns the text of given buffer, and the contents that it
+// is saved with, in its line endings and encoding, if it is big enough for
+// a differential save, and its file exists
+func (ge *Gide) DiffSaveData(tb *giv.TextBuf) (txt, data []byte, ok bool) {
+	if tb.Filename == "" {
+		return nil, nil, false
+	}
+	if _, err := os.Stat(string(tb.Filename)); err != nil {
+		return nil, nil, false
+	}
+	n := 0
+	for _, l := range tb.Lines {
+		n += len(l) + 1
+		if n >= DiffSaveMinSize {
+			break
+		}
+	}
+	if n < DiffSaveMinSize {
+		return nil, nil, false
+	}
+	txt = tb.LinesToBytesCopy()
+	data = txt
+	if le := ge.LineEndsFor(tb); le != LineEndsLF {
+		data = ConvertLineEnds(data, le)
+	}
+	if enc := ge.EncodingFor(tb); enc != EncodingUTF8 {
+		out, err := EncodeText(data, enc)
+		if err != nil {
+			return nil, nil, false // saved in UTF-8 as usual, and reported then
+		}
+		data = out
+	}
+	return txt, data, true
+}
+
+// SaveBuf saves given buffer to its file, calling done after it is saved
+// and BufSaved has been called for it, with any error converting it -- big
+// buffers (see DiffSaveMinSize) are saved in the background with a
+// differential save, which only writes the parts of the file that have
+// changed, with the progress in the status bar, and done is called on the
+// gui when it is finished -- others are saved right away -- a buffer that
+// is still being saved is saved again when that is done
+func (ge *Gide) SaveBuf(tb *giv.TextBuf, done func(err error)) {
+	fpath := string(tb.Filename)
+	ge.saveMu.Lock()
+	if dones, saving := ge.diffSaving[tb]; saving {
+		ge.diffSaving[tb] = append(dones, done)
+		ge.saveMu.Unlock()
+		ge.SetStatus("Still saving: " + fpath + " -- it will be saved again when done")
+		return
+	}
+	ge.saveMu.Unlock()
+	txt, data, ok := ge.DiffSaveData(tb)
+	if !ok {
+		tb.Save()
+		err := ge.BufSaved(tb)
+		if done != nil {
+			done(err)
+		}
+		return
+	}
+	ge.saveMu.Lock()
+	if ge.diffSaving == nil {
+		ge.diffSaving = make(map[*giv.TextBuf][]func(err error))
+	}
+	ge.diffSaving[tb] = nil
+	ge.saveMu.Unlock()
+	_, fnm := filepath.Split(fpath)
+	go func() {
+		pct := -1
+		wr, err := DiffSaveFile(fpath, data, DiffSaveChunk, func(d, t int64) {
+			if p := int(100 * d / t); p != pct {
+				pct = p
+				ge.SetStatus(fmt.Sprintf("Saving %v: %d%%", fnm, p))
+			}
+		})
+		ge.RunOnGui(func() {
+			ge.diffSaved(tb, txt, len(data), wr, err, done)
+		})
+	}()
+}
+
+// diffSaved finishes the differential save of given buffer, with given text
+// and wr of n bytes written, or err, on the gui -- then saves it again for
+// any saves asked for while it was saving, and calls the WhenSaved
+// functions if there are no more saves running
+func (ge *Gide) diffSaved(tb *giv.TextBuf, txt []byte, n int, wr int64, err error, done func(err error)) {
+	fpath := string(tb.Filename)
+	_, fnm := filepath.Split(fpath)
+	ge.saveMu.Lock()
+	again := ge.diffSaving[tb]
+	delete(ge.diffSaving, tb)
+	ge.saveMu.Unlock()
+	switch {
+	case err != nil:
+		ge.SetStatus(fmt.Sprintf("Differential save of %v failed, saving all of it: %v", fnm, err))
+		tb.Save()
+		err = ge.BufSaved(tb)
+	case !bytes.Equal(txt, tb.LinesToBytesCopy()):
+		tb.Info.InitFile(fpath) // so it is not seen as changed on disk
+		ge.bufSaved(tb, false)
+		if len(again) == 0 {
+			ge.SetStatus(fmt.Sprintf("Saved %v, but it was edited while saving -- save it again to save the edits", fnm))
+		}
+	default:
+		tb.ClearChanged()
+		tb.AutoSaveDelete()
+		tb.Info.InitFile(fpath) // so it is not seen as changed on disk
+		ge.bufSaved(tb, false)  // already in its line endings and encoding
+		ge.SetStatus(fmt.Sprintf("Saved %v: wrote %d of %d bytes", fnm, wr, n))
+	}
+	if done != nil {
+		done(err)
+	}
+	if len(again) > 0 {
+		ge.SaveBuf(tb, func(err error) {
+			for _, dn := range again {
+				if dn != nil {
+					dn(err)
+				}
+			}
+		})
+	}
+	ge.saveMu.Lock()
+	var waits []func()
+	if len(ge.diffSaving) == 0 {
+		waits = ge.saveWaits
+		ge.saveWaits = nil
+	}
+	ge.saveMu.Unlock()
+	for _, fun := range waits {
+		fun()
+	}
+}
+
+// WhenSaved calls given function when all of the buffers being saved in the
+// background have been saved, on the gui -- right away if none are -- so
+// that closing a file or quitting can not cut short writing it
+func (ge *Gide) WhenSaved(fun func()) {
+	ge.saveMu.Lock()
+	if len(ge.diffSaving) > 0 {
+		ge.saveWaits = append(ge.saveWaits, fun)
+		ge.saveMu.Unlock()
+		ge.SetStatus("Waiting for the files being saved...")
+		return
+	}
+	ge.saveMu.Unlock()
+	fun()
+}
+
+// IsDiffSaving returns true if given buffer is being saved in the background
+func (ge *Gide) IsDiffSaving(tb *giv.TextBuf) bool {
+	ge.saveMu.Lock()
+	defer ge.saveMu.Unlock()
+	_, saving := ge.diffSaving[tb]
+	return saving
+}
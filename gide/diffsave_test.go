@@ -0,0 +1,71 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffSaveFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-diffsave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fpath := filepath.Join(dir, "big.txt")
+	orig := bytes.Repeat([]byte("0123456789"), 100) // 1000 bytes, 10 chunks of 100
+
+	tests := []struct {
+		name    string
+		data    func() []byte
+		written int64
+	}{
+		{"same", func() []byte { return orig }, 0},
+		{"one chunk", func() []byte {
+			d := append([]byte{}, orig...)
+			d[450] = 'x'
+			return d
+		}, 100},
+		{"two chunks", func() []byte {
+			d := append([]byte{}, orig...)
+			d[50], d[950] = 'x', 'y'
+			return d
+		}, 200},
+		{"grow", func() []byte {
+			return append(append(append([]byte{}, orig[:720]...), "inserted"...), orig[720:]...)
+		}, 308},
+		{"shrink", func() []byte { return orig[:505] }, 0}, // only truncated
+	}
+	for _, tt := range tests {
+		if err := ioutil.WriteFile(fpath, orig, 0644); err != nil {
+			t.Fatal(err)
+		}
+		data := tt.data()
+		var last int64
+		wr, err := DiffSaveFile(fpath, data, 100, func(done, total int64) {
+			if done < last || total != int64(len(data)) {
+				t.Errorf("%v: progress %d of %d after %d", tt.name, done, total, last)
+			}
+			last = done
+		})
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if wr != tt.written {
+			t.Errorf("%v: wrote %d bytes, want %d", tt.name, wr, tt.written)
+		}
+		if last != int64(len(data)) {
+			t.Errorf("%v: progress ended at %d, want %d", tt.name, last, len(data))
+		}
+		got, _ := ioutil.ReadFile(fpath)
+		if !bytes.Equal(got, data) {
+			t.Errorf("%v: file does not have the new contents", tt.name)
+		}
+	}
+}
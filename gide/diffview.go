@@ -0,0 +1,373 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// diffLinePrefix is the number of runes before the text of each line in the
+// diff views: a change marker, the line number and spaces
+const diffLinePrefix = 8
+
+// DiffView is a widget that shows two versions of a file side by side, with
+// the unchanged lines aligned, the changed part of each changed line
+// highlighted, and navigation between the blocks of changes
+type DiffView struct {
+	gi.Layout
+	Gide    *Gide     `json:"-" xml:"-" desc:"parent gide project"`
+	NameA   string    `desc:"name of the first (left) version"`
+	NameB   string    `desc:"name of the second (right) version"`
+	LinesA  []string  `json:"-" xml:"-" desc:"lines of the first version"`
+	LinesB  []string  `json:"-" xml:"-" desc:"lines of the second version"`
+	Rows    []DiffRow `json:"-" xml:"-" desc:"aligned rows of the diff"`
+	Hunks   []int     `json:"-" xml:"-" desc:"first row of each block of changes"`
+	CurHunk int       `json:"-" xml:"-" desc:"index of the current block of changes, -1 if none"`
+	inSync  bool
+}
+
+var KiT_DiffView = kit.Types.AddType(&DiffView{}, DiffViewProps)
+
+// SetDiff sets the two versions to compare, and computes the diff
+func (dv *DiffView) SetDiff(nameA string, a []byte, nameB string, b []byte) {
+	dv.NameA, dv.NameB = nameA, nameB
+	dv.LinesA = strings.Split(string(a), "\n")
+	dv.LinesB = strings.Split(string(b), "\n")
+	dv.Rows = DiffRows(dv.LinesA, dv.LinesB)
+	dv.Hunks = DiffHunks(dv.Rows)
+	dv.CurHunk = -1
+}
+
+// diffMarkers are the markers shown at the start of each line, by kind of
+// difference, for the A and B sides
+var diffMarkers = [...][2]string{
+	DiffEqual:    {" ", " "},
+	DiffChanged:  {"~", "~"},
+	DiffDeleted:  {"-", " "},
+	DiffInserted: {" ", "+"},
+}
+
+// ShowDiff renders the diff into the two text views
+func (dv *DiffView) ShowDiff() {
+	var ta, tb bytes.Buffer
+	var hla, hlb []giv.TextRegion
+	lnreg := func(ln, st, ed int) giv.TextRegion {
+		return giv.TextRegion{Start: giv.TextPos{Ln: ln, Ch: diffLinePrefix + st}, End: giv.TextPos{Ln: ln, Ch: diffLinePrefix + ed}}
+	}
+	for i, r := range dv.Rows {
+		if r.A >= 0 {
+			fmt.Fprintf(&ta, "%s%5d  %s", diffMarkers[r.Kind][0], r.A+1, dv.LinesA[r.A])
+		}
+		if r.B >= 0 {
+			fmt.Fprintf(&tb, "%s%5d  %s", diffMarkers[r.Kind][1], r.B+1, dv.LinesB[r.B])
+		}
+		if i < len(dv.Rows)-1 {
+			ta.WriteString("\n")
+			tb.WriteString("\n")
+		}
+		switch r.Kind {
+		case DiffChanged:
+			la, lb := []rune(dv.LinesA[r.A]), []rune(dv.LinesB[r.B])
+			st, aed, bed := DiffIntraLine(la, lb)
+			if aed > st {
+				hla = append(hla, lnreg(i, st, aed))
+			}
+			if bed > st {
+				hlb = append(hlb, lnreg(i, st, bed))
+			}
+		case DiffDeleted:
+			hla = append(hla, lnreg(i, 0, len([]rune(dv.LinesA[r.A]))))
+		case DiffInserted:
+			hlb = append(hlb, lnreg(i, 0, len([]rune(dv.LinesB[r.B]))))
+		}
+	}
+	bufa, _ := dv.Gide.FindOrMakeCmdBuf("Diff A", true)
+	bufb, _ := dv.Gide.FindOrMakeCmdBuf("Diff B", true)
+	bufa.SetText(ta.Bytes())
+	bufb.SetText(tb.Bytes())
+	tva, tvb := dv.TextViewA(), dv.TextViewB()
+	tva.SetBuf(bufa)
+	tvb.SetBuf(bufb)
+	updt := tva.UpdateStart()
+	tva.Highlights = hla
+	tva.UpdateEnd(updt)
+	updt = tvb.UpdateStart()
+	tvb.Highlights = hlb
+	tvb.UpdateEnd(updt)
+	dv.SetInfo()
+}
+
+// SetInfo shows the names of the versions and the number of changes
+func (dv *DiffView) SetInfo() {
+	msg := fmt.Sprintf("%v  vs  %v: ", dv.NameA, dv.NameB)
+	switch {
+	case len(dv.Hunks) == 0:
+		msg += "no differences"
+	case dv.CurHunk < 0:
+		msg += fmt.Sprintf("%d changes", len(dv.Hunks))
+	default:
+		msg += fmt.Sprintf("change %d of %d", dv.CurHunk+1, len(dv.Hunks))
+	}
+	dv.InfoLabel().SetText(msg)
+}
+
+// GotoRow moves the cursors in both views to given row
+func (dv *DiffView) GotoRow(row int) {
+	dv.inSync = true
+	dv.TextViewA().SetCursorShow(giv.TextPos{Ln: row})
+	dv.TextViewB().SetCursorShow(giv.TextPos{Ln: row})
+	dv.inSync = false
+}
+
+// NextChange goes to the next block of changes
+func (dv *DiffView) NextChange() {
+	if dv.CurHunk+1 >= len(dv.Hunks) {
+		dv.Gide.SetStatus("No more changes")
+		return
+	}
+	dv.CurHunk++
+	dv.GotoRow(dv.Hunks[dv.CurHunk])
+	dv.SetInfo()
+}
+
+// PrevChange goes to the previous block of changes
+func (dv *DiffView) PrevChange() {
+	if dv.CurHunk <= 0 {
+		dv.Gide.SetStatus("No previous changes")
+		return
+	}
+	dv.CurHunk--
+	dv.GotoRow(dv.Hunks[dv.CurHunk])
+	dv.SetInfo()
+}
+
+// SyncViews moves the other view to the row of the cursor in given view,
+// so the two sides stay aligned
+func (dv *DiffView) SyncViews(tv *giv.TextView) {
+	if dv.inSync {
+		return
+	}
+	ov := dv.TextViewB()
+	if tv == ov {
+		ov = dv.TextViewA()
+	}
+	dv.inSync = true
+	ov.SetCursorShow(giv.TextPos{Ln: tv.CursorPos.Ln})
+	dv.inSync = false
+}
+
+// DiffVersions shows the differences between two versions of a file side by
+// side, in the Diff tab
+func (ge *Gide) DiffVersions(nameA string, a []byte, nameB string, b []byte) {
+	dvi, _ := ge.FindOrMakeMainTab("Diff", KiT_DiffView, true) // sel
+	dv := dvi.Embed(KiT_DiffView).(*DiffView)
+	dv.UpdateView(ge)
+	dv.SetDiff(nameA, a, nameB, b)
+	dv.ShowDiff()
+	ge.FocusOnPanel(MainTabsIdx)
+	if len(dv.Hunks) > 0 {
+		dv.NextChange()
+	}
+}
+
+// DiffActiveDisk shows the differences between the file on disk and the
+// active buffer, i.e., the unsaved changes
+func (ge *Gide) DiffActiveDisk() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf.Filename == "" {
+		return
+	}
+	fpath := string(tv.Buf.Filename)
+	disk, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		ge.SetStatus(err.Error())
+		return
+	}
+	nm := filepath.Base(fpath)
+	disk = ConvertLineEnds(disk, LineEndsLF) // as the buffer has them
+	ge.DiffVersions(nm+" (on disk)", disk, nm+" (edited)", tv.Buf.LinesToBytesCopy())
+}
+
+// VCSFileContent returns the content of given file at given revision in the
+// version control system of the project: a git revision such as HEAD, or an
+// svn revision such as BASE
+func (ge *Gide) VCSFileContent(fpath, rev string) ([]byte, error) {
+	dir, fnm := filepath.Split(fpath)
+	var cmd *exec.Cmd
+	if ge.Prefs.VersCtrl == "SVN" {
+		cmd = exec.Command("svn", "cat", "-r", rev, fnm)
+	} else {
+		cmd = exec.Command("git", "show", rev+":./"+fnm)
+	}
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("%v", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// DiffFileVCS shows the differences between the last committed version of
+// given file and its current contents (including unsaved edits if open)
+func (ge *Gide) DiffFileVCS(fn *giv.FileNode) {
+	fpath := string(fn.FPath)
+	rev := "HEAD"
+	if ge.Prefs.VersCtrl == "SVN" {
+		rev = "BASE"
+	}
+	old, err := ge.VCSFileContent(fpath, rev)
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not get committed version", Prompt: err.Error()}, true, false, nil, nil)
+		return
+	}
+	var cur []byte
+	if fn.Buf != nil {
+		cur = fn.Buf.LinesToBytesCopy()
+	} else if cur, err = ioutil.ReadFile(fpath); err != nil {
+		ge.SetStatus(err.Error())
+		return
+	}
+	nm := filepath.Base(fpath)
+	ge.DiffVersions(nm+" ("+rev+")", old, nm, cur)
+}
+
+// DiffActiveVCS shows the differences between the last committed version of
+// the file in the active view and its current contents
+func (ge *Gide) DiffActiveVCS() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	fn, _, ok := ge.OpenNodeForTextView(tv)
+	if !ok {
+		return
+	}
+	ge.DiffFileVCS(fn)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (dv *DiffView) UpdateView(ge *Gide) {
+	dv.Gide = ge
+	dv.Lay = gi.LayoutVert
+	dv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "diffbar")
+	config.Add(gi.KiT_SplitView, "diffsplit")
+	mods, updt := dv.ConfigChildren(config, false)
+	dv.ConfigToolbar()
+	sv := dv.SplitView()
+	sv.Dim = gi.X
+	if !sv.HasChildren() {
+		for _, nm := range []string{"diff-a", "diff-b"} {
+			ly := sv.AddNewChild(gi.KiT_Layout, nm).(*gi.Layout)
+			tv := ge.ConfigOutputTextView(ly)
+			tv.SetProp("white-space", gi.WhiteSpacePre) // wrapping would misalign the sides
+			tv.SetProp("tab-size", ge.Prefs.Editor.TabSize)
+			tv.TextViewSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if sig != int64(giv.TextViewCursorMoved) {
+					return
+				}
+				dvv, _ := recv.Embed(KiT_DiffView).(*DiffView)
+				dvv.SyncViews(send.Embed(giv.KiT_TextView).(*giv.TextView))
+			})
+		}
+		sv.SetSplits(.5, .5)
+	}
+	if mods {
+		dv.UpdateEnd(updt)
+	}
+}
+
+// DiffBar returns the diff toolbar
+func (dv *DiffView) DiffBar() *gi.ToolBar {
+	tbi, ok := dv.ChildByName("diffbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// InfoLabel returns the label showing what is compared, in the toolbar
+func (dv *DiffView) InfoLabel() *gi.Label {
+	tb := dv.DiffBar()
+	if tb == nil {
+		return nil
+	}
+	lbi, ok := tb.ChildByName("info", 0)
+	if !ok {
+		return nil
+	}
+	return lbi.(*gi.Label)
+}
+
+// SplitView returns the splitter with the two sides
+func (dv *DiffView) SplitView() *gi.SplitView {
+	svi, ok := dv.ChildByName("diffsplit", 1)
+	if !ok {
+		return nil
+	}
+	return svi.(*gi.SplitView)
+}
+
+// TextViewA returns the text view for the first (left) version
+func (dv *DiffView) TextViewA() *giv.TextView {
+	return dv.SplitView().KnownChild(0).KnownChild(0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// TextViewB returns the text view for the second (right) version
+func (dv *DiffView) TextViewB() *giv.TextView {
+	return dv.SplitView().KnownChild(1).KnownChild(0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (dv *DiffView) ConfigToolbar() {
+	tb := dv.DiffBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	pv := tb.AddNewChild(gi.KiT_Action, "prev").(*gi.Action)
+	pv.SetText("Prev Change")
+	pv.Tooltip = "go to the previous block of changes"
+	pv.ActionSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dvv, _ := recv.Embed(KiT_DiffView).(*DiffView)
+		dvv.PrevChange()
+	})
+
+	nx := tb.AddNewChild(gi.KiT_Action, "next").(*gi.Action)
+	nx.SetText("Next Change")
+	nx.Tooltip = "go to the next block of changes"
+	nx.ActionSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dvv, _ := recv.Embed(KiT_DiffView).(*DiffView)
+		dvv.NextChange()
+	})
+
+	lbl := tb.AddNewChild(gi.KiT_Label, "info").(*gi.Label)
+	lbl.SetStretchMaxWidth()
+}
+
+var DiffViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
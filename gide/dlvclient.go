@@ -0,0 +1,336 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// This is a client for the JSON-RPC API (version 2) of the Delve debugger,
+// github.com/go-delve/delve -- only the parts of the API used here are
+// defined, using the same JSON field names as Delve's service/api types.
+
+// DlvFunction is a function in the program being debugged
+type DlvFunction struct {
+	Name string `json:"name"`
+}
+
+// DlvBreakpoint is a breakpoint set in the program being debugged
+type DlvBreakpoint struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Addr          uint64 `json:"addr"`
+	File          string `json:"file"`
+	Line          int    `json:"line"`
+	FunctionName  string `json:"functionName,omitempty"`
+	Cond          string `json:"Cond"`
+	TotalHitCount uint64 `json:"totalHitCount"`
+}
+
+// DlvThread is a thread of the program being debugged
+type DlvThread struct {
+	ID          int            `json:"id"`
+	PC          uint64         `json:"pc"`
+	File        string         `json:"file"`
+	Line        int            `json:"line"`
+	Function    *DlvFunction   `json:"function,omitempty"`
+	GoroutineID int64          `json:"goroutineID"`
+	Breakpoint  *DlvBreakpoint `json:"breakPoint,omitempty"`
+}
+
+// DlvState is the state of the debugger, as returned from each command
+type DlvState struct {
+	Running       bool       `json:"Running"`
+	CurrentThread *DlvThread `json:"currentThread,omitempty"`
+	Exited        bool       `json:"exited"`
+	ExitStatus    int        `json:"exitStatus"`
+}
+
+// DlvVariable is the value of a variable or expression
+type DlvVariable struct {
+	Name       string        `json:"name"`
+	Addr       uint64        `json:"addr"`
+	Type       string        `json:"type"`
+	Kind       int           `json:"kind"`
+	Value      string        `json:"value"`
+	Len        int64         `json:"len"`
+	Children   []DlvVariable `json:"children"`
+	Unreadable string        `json:"unreadable"`
+}
+
+// dlvKindString is the reflect.Kind of strings, for quoting string values
+const dlvKindString = 24
+
+// ValueString returns the value of the variable as a string, showing up to
+// given depth of nested values
+func (dv *DlvVariable) ValueString(depth int) string {
+	if dv.Unreadable != "" {
+		return "(unreadable " + dv.Unreadable + ")"
+	}
+	if dv.Kind == dlvKindString {
+		return strconv.Quote(dv.Value)
+	}
+	if dv.Value != "" || len(dv.Children) == 0 {
+		return dv.Value
+	}
+	if depth <= 0 {
+		return "{...}"
+	}
+	var vals []string
+	for i := range dv.Children {
+		ch := &dv.Children[i]
+		v := ch.ValueString(depth - 1)
+		if ch.Name != "" {
+			v = ch.Name + ": " + v
+		}
+		vals = append(vals, v)
+	}
+	if dv.Len > int64(len(dv.Children)) {
+		vals = append(vals, fmt.Sprintf("...+%d more", dv.Len-int64(len(dv.Children))))
+	}
+	return "{" + strings.Join(vals, ", ") + "}"
+}
+
+// String returns the type and value of the variable
+func (dv *DlvVariable) String() string {
+	return dv.Type + " " + dv.ValueString(2)
+}
+
+// DlvClient is a client for one running Delve debug server, for the program
+// being debugged
+type DlvClient struct {
+	Addr     string        `desc:"address the server is listening on"`
+	Exec     *exec.Cmd     `desc:"the dlv server process"`
+	Done     chan struct{} `desc:"closed once the dlv server process has exited"`
+	Client   *rpc.Client   `desc:"JSON-RPC connection to the server"`
+	Recorded bool          `desc:"true if the program is being recorded, e.g., by the rr backend, so it can be run in reverse"`
+}
+
+// dlvListenPrefix starts the line output by a headless dlv server with the
+// address it is listening on
+const dlvListenPrefix = "API server listening at:"
+
+// StartDlv starts a headless Delve server to debug the main package in given
+// directory with given program args, connecting to it -- all output of the
+// server and the program, after startup, is written to out.  The backend is
+// passed to dlv if non-empty, e.g., rr to record for reverse execution.
+func StartDlv(dir string, args []string, backend string, out io.Writer) (*DlvClient, error) {
+	dargs := []string{"debug", "--headless", "--api-version=2", "--listen=127.0.0.1:0"}
+	if backend != "" {
+		dargs = append(dargs, "--backend="+backend)
+	}
+	if len(args) > 0 {
+		dargs = append(dargs, "--")
+		dargs = append(dargs, args...)
+	}
+	cmd := exec.Command("dlv", dargs...)
+	cmd.Dir = dir
+	CmdBuildEnv.SetCmdEnv(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stderr = cmd.Stdout
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	outscan := bufio.NewScanner(stdout)
+	addr := ""
+	for outscan.Scan() {
+		ln := outscan.Text()
+		if strings.HasPrefix(ln, dlvListenPrefix) {
+			addr = strings.TrimSpace(strings.TrimPrefix(ln, dlvListenPrefix))
+			break
+		}
+		fmt.Fprintln(out, ln) // build errors etc
+	}
+	if addr == "" {
+		cmd.Wait()
+		return nil, fmt.Errorf("gide.StartDlv: dlv exited without starting the debug server -- see the output for errors")
+	}
+	done := make(chan struct{})
+	go func() {
+		for outscan.Scan() {
+			fmt.Fprintln(out, outscan.Text())
+		}
+		cmd.Wait()
+		close(done)
+	}()
+	cl, err := jsonrpc.Dial("tcp", addr)
+	if err != nil {
+		cmd.Process.Kill()
+		return nil, err
+	}
+	dc := &DlvClient{Addr: addr, Exec: cmd, Done: done, Client: cl}
+	dc.Recorded, _ = dc.IsRecorded()
+	return dc, nil
+}
+
+// Command runs given debugger command, e.g., continue, next, step, stepOut,
+// halt, waiting until the program stops again (except for halt) -- when the
+// program is recorded, the reverse commands rewind, reverseNext, reverseStep
+// and reverseStepOut are also available
+func (dc *DlvClient) Command(name string) (*DlvState, error) {
+	args := struct {
+		Name string `json:"name"`
+	}{name}
+	out := struct {
+		State DlvState
+	}{}
+	err := dc.Client.Call("RPCServer.Command", args, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out.State, nil
+}
+
+// IsRecorded returns true if the program is being recorded, so that it can
+// be run in reverse
+func (dc *DlvClient) IsRecorded() (bool, error) {
+	args := struct{}{}
+	out := struct {
+		Recorded       bool
+		TraceDirectory string
+	}{}
+	if err := dc.Client.Call("RPCServer.Recorded", args, &out); err != nil {
+		return false, err
+	}
+	return out.Recorded, nil
+}
+
+// State returns the current state of the debugger, without waiting if the
+// program is running
+func (dc *DlvClient) State() (*DlvState, error) {
+	args := struct {
+		NonBlocking bool
+	}{true}
+	out := struct {
+		State DlvState
+	}{}
+	if err := dc.Client.Call("RPCServer.State", args, &out); err != nil {
+		return nil, err
+	}
+	return &out.State, nil
+}
+
+// CreateBreakpoint sets a breakpoint at given file and 1-based line, with
+// given condition if non-empty
+func (dc *DlvClient) CreateBreakpoint(file string, line int, cond string) (*DlvBreakpoint, error) {
+	args := struct {
+		Breakpoint DlvBreakpoint
+	}{DlvBreakpoint{File: file, Line: line, Cond: cond}}
+	out := struct {
+		Breakpoint DlvBreakpoint
+	}{}
+	if err := dc.Client.Call("RPCServer.CreateBreakpoint", args, &out); err != nil {
+		return nil, err
+	}
+	return &out.Breakpoint, nil
+}
+
+// ClearBreakpoint removes the breakpoint with given id
+func (dc *DlvClient) ClearBreakpoint(id int) error {
+	args := struct {
+		Id int
+	}{id}
+	out := struct {
+		Breakpoint *DlvBreakpoint
+	}{}
+	return dc.Client.Call("RPCServer.ClearBreakpoint", args, &out)
+}
+
+// Eval evaluates given expression in the current goroutine and frame of the
+// stopped program
+func (dc *DlvClient) Eval(expr string) (*DlvVariable, error) {
+	type scope struct {
+		GoroutineID  int64
+		Frame        int
+		DeferredCall int
+	}
+	type loadConfig struct {
+		FollowPointers     bool
+		MaxVariableRecurse int
+		MaxStringLen       int
+		MaxArrayValues     int
+		MaxStructFields    int
+	}
+	args := struct {
+		Scope scope
+		Expr  string
+		Cfg   *loadConfig
+	}{scope{GoroutineID: -1}, expr, &loadConfig{true, 1, 256, 32, -1}}
+	out := struct {
+		Variable *DlvVariable
+	}{}
+	if err := dc.Client.Call("RPCServer.Eval", args, &out); err != nil {
+		return nil, err
+	}
+	return out.Variable, nil
+}
+
+// ExamineMemory returns given number of bytes of the memory of the program
+// starting at given address, and whether the program is little-endian
+func (dc *DlvClient) ExamineMemory(addr uint64, length int) ([]byte, bool, error) {
+	args := struct {
+		Address uint64
+		Length  int
+	}{addr, length}
+	out := struct {
+		Mem            []byte
+		IsLittleEndian bool
+	}{}
+	if err := dc.Client.Call("RPCServer.ExamineMemory", args, &out); err != nil {
+		return nil, false, err
+	}
+	return out.Mem, out.IsLittleEndian, nil
+}
+
+// DlvRegister is a CPU register of the program being debugged
+type DlvRegister struct {
+	Name  string
+	Value string
+}
+
+// ListRegisters returns the CPU registers of the current thread, as of
+// given stack frame (0 is the innermost), including floating point
+// registers if fp
+func (dc *DlvClient) ListRegisters(frame int, fp bool) ([]DlvRegister, error) {
+	type scope struct {
+		GoroutineID  int64
+		Frame        int
+		DeferredCall int
+	}
+	args := struct {
+		ThreadID  int
+		IncludeFp bool
+		Scope     *scope
+	}{0, fp, &scope{GoroutineID: -1, Frame: frame}}
+	out := struct {
+		Registers string
+		Regs      []DlvRegister
+	}{}
+	if err := dc.Client.Call("RPCServer.ListRegisters", args, &out); err != nil {
+		return nil, err
+	}
+	return out.Regs, nil
+}
+
+// Detach ends the debug session, killing the program
+func (dc *DlvClient) Detach() error {
+	args := struct {
+		Kill bool
+	}{true}
+	out := struct{}{}
+	err := dc.Client.Call("RPCServer.Detach", args, &out)
+	dc.Client.Close()
+	return err
+}
@@ -0,0 +1,776 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"html"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// DocIssue is one spelling or grammar problem found in the documentation
+// of a file -- positions are 0-based, with St, Ed in runes within line Ln
+type DocIssue struct {
+	Ln       int      `desc:"line number, 0-based"`
+	St       int      `desc:"starting char position within the line, 0-based"`
+	Ed       int      `desc:"ending char position within the line, 0-based"`
+	Kind     string   `desc:"spelling or grammar"`
+	Code     string   `desc:"rule that found it, e.g., misspelling, repeated-word, a-an"`
+	Word     string   `desc:"text of the region with the problem"`
+	Message  string   `desc:"the message"`
+	Suggests []string `desc:"suggested replacements for the text of the region"`
+}
+
+// DocFileResults are the documentation problems found in one file
+type DocFileResults struct {
+	Node   *giv.FileNode
+	Issues []DocIssue
+}
+
+// DocMaskRune replaces the runes of a line that are not documentation text,
+// e.g., code, in the lines returned by DocMaskFile
+const DocMaskRune = '\x00'
+
+// DocCheckExts are the extensions of the files that are checked: Markdown
+// files, and doc comments in Go files
+var DocCheckExts = []string{".md", ".markdown", ".go"}
+
+// DocMaskFile returns the lines of given file contents with everything that
+// is not documentation text replaced by DocMaskRune, so that positions are
+// kept -- nil if the file is not checked
+func DocMaskFile(fname string, src []byte) [][]rune {
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".go":
+		return DocMaskGo(src)
+	case ".md", ".markdown":
+		return DocMaskMarkdown(src)
+	}
+	return nil
+}
+
+// docMaskLines returns the lines of src with the bytes not in keep masked
+func docMaskLines(src []byte, keep []bool) [][]rune {
+	var lines [][]rune
+	st := 0
+	for i := 0; i <= len(src); i++ {
+		if i < len(src) && src[i] != '\n' {
+			continue
+		}
+		var ln []rune
+		for j := st; j < i; {
+			r, sz := utf8.DecodeRune(src[j:i])
+			if !keep[j] || r == '\r' {
+				r = DocMaskRune
+			}
+			ln = append(ln, r)
+			j += sz
+		}
+		lines = append(lines, ln)
+		st = i + 1
+	}
+	return lines
+}
+
+// DocMaskGo returns the lines of given Go source with everything but the
+// text of doc comments (on the package and declarations, including fields)
+// masked -- code blocks within the comments and directives are masked too
+func DocMaskGo(src []byte) [][]rune {
+	keep := make([]bool, len(src))
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if f == nil {
+		return docMaskLines(src, keep)
+	}
+	_ = err // a partial parse still has the doc comments before the error
+	keepCmt := func(c *ast.Comment) {
+		off := fset.Position(c.Pos()).Offset
+		txt := c.Text
+		if strings.HasPrefix(txt, "//") {
+			rest := txt[2:]
+			if strings.HasPrefix(rest, "\t") || strings.HasPrefix(rest, "  ") || strings.HasPrefix(rest, "go:") || strings.HasPrefix(rest, "line ") || strings.HasPrefix(rest, "export ") {
+				return
+			}
+			for i := off + 2; i < off+len(txt); i++ {
+				keep[i] = true
+			}
+			return
+		}
+		for i := off + 2; i < off+len(txt)-2; i++ { // /* */
+			keep[i] = true
+		}
+	}
+	keepDoc := func(cg *ast.CommentGroup) {
+		if cg == nil {
+			return
+		}
+		for _, c := range cg.List {
+			keepCmt(c)
+		}
+	}
+	keepDoc(f.Doc)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch d := n.(type) {
+		case *ast.FuncDecl:
+			keepDoc(d.Doc)
+		case *ast.GenDecl:
+			keepDoc(d.Doc)
+		case *ast.TypeSpec:
+			keepDoc(d.Doc)
+		case *ast.ValueSpec:
+			keepDoc(d.Doc)
+		case *ast.Field:
+			keepDoc(d.Doc)
+		}
+		return true
+	})
+	return docMaskLines(src, keep)
+}
+
+// DocMaskMarkdown returns the lines of given Markdown text with code blocks,
+// inline code, link targets, html tags and autolinks masked
+func DocMaskMarkdown(src []byte) [][]rune {
+	keep := make([]bool, len(src))
+	fence := ""
+	st := 0
+	for st <= len(src) {
+		ed := bytes.IndexByte(src[st:], '\n')
+		if ed < 0 {
+			ed = len(src)
+		} else {
+			ed += st
+		}
+		ln := src[st:ed]
+		tl := strings.TrimLeft(string(ln), " ")
+		ind := len(ln) - len(tl)
+		switch {
+		case fence != "":
+			if ind < 4 && strings.HasPrefix(tl, fence) {
+				fence = ""
+			}
+		case ind < 4 && (strings.HasPrefix(tl, "```") || strings.HasPrefix(tl, "~~~")):
+			fence = tl[:3]
+		case ind >= 4 || strings.HasPrefix(tl, "\t"):
+			// indented code block
+		default:
+			docKeepMarkdownLine(ln, keep[st:ed])
+		}
+		st = ed + 1
+	}
+	return docMaskLines(src, keep)
+}
+
+// docKeepMarkdownLine marks the text of given line of Markdown outside of
+// inline code, link targets, html tags and autolinks as kept
+func docKeepMarkdownLine(ln []byte, keep []bool) {
+	for i := 0; i < len(ln); i++ {
+		c := ln[i]
+		end := -1
+		switch {
+		case c == '`':
+			if e := bytes.IndexByte(ln[i+1:], '`'); e >= 0 {
+				end = i + 1 + e
+			}
+		case c == ']' && i+1 < len(ln) && ln[i+1] == '(':
+			if e := bytes.IndexByte(ln[i+1:], ')'); e >= 0 {
+				keep[i] = true
+				i++
+				end = i + e
+			}
+		case c == '<':
+			if e := bytes.IndexByte(ln[i+1:], '>'); e >= 0 {
+				end = i + 1 + e
+			}
+		}
+		if end >= 0 {
+			i = end // masked through end
+			continue
+		}
+		keep[i] = true
+	}
+}
+
+// docIsCodeWord returns true if given word, with any punctuation around it
+// trimmed, looks like code rather than prose, e.g., an identifier, path or
+// url, which are not spell checked
+func docIsCodeWord(w string) bool {
+	w = strings.Trim(w, ".,;:!?()[]\"'*")
+	for i, r := range w {
+		switch {
+		case unicode.IsDigit(r), strings.ContainsRune("_/\\=@.<>{}*$#&|~^+%", r):
+			return true
+		case i > 0 && unicode.IsUpper(r):
+			return true
+		}
+	}
+	return false
+}
+
+// DocSpellText returns the text of given masked lines to spell check, with
+// the masked runes and words that look like code replaced by spaces, so
+// that positions are kept
+func DocSpellText(lines [][]rune) []byte {
+	var b bytes.Buffer
+	for li, ln := range lines {
+		if li > 0 {
+			b.WriteByte('\n')
+		}
+		out := make([]rune, len(ln))
+		for i, r := range ln {
+			if r == DocMaskRune {
+				r = ' '
+			}
+			out[i] = r
+		}
+		docFields(out, func(st, ed int) {
+			if docIsCodeWord(string(out[st:ed])) {
+				for i := st; i < ed; i++ {
+					out[i] = ' '
+				}
+			}
+		})
+		b.WriteString(string(out))
+	}
+	return b.Bytes()
+}
+
+// docFields calls fun with the start and end of each space-separated field
+// of given line, including masked runes
+func docFields(ln []rune, fun func(st, ed int)) {
+	st := -1
+	for i := 0; i <= len(ln); i++ {
+		if i < len(ln) && !unicode.IsSpace(ln[i]) {
+			if st < 0 {
+				st = i
+			}
+			continue
+		}
+		if st >= 0 {
+			fun(st, i)
+			st = -1
+		}
+	}
+}
+
+// docIsLetters returns true if given word is all letters
+func docIsLetters(w string) bool {
+	if w == "" {
+		return false
+	}
+	for _, r := range w {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// DocAnWord returns true if given word is said starting with a vowel sound,
+// so it takes "an" rather than "a" -- by its spelling, with the common
+// exceptions, e.g., an hour, a user
+func DocAnWord(w string) bool {
+	lw := strings.ToLower(w)
+	for _, p := range []string{"hour", "honest", "honor", "honour", "heir"} {
+		if strings.HasPrefix(lw, p) {
+			return true
+		}
+	}
+	for _, p := range []string{"uni", "use", "usa", "usi", "usu", "uti", "ubiq", "eu", "one", "once"} {
+		if strings.HasPrefix(lw, p) {
+			return false
+		}
+	}
+	return lw != "" && strings.ContainsRune("aeiou", rune(lw[0]))
+}
+
+// docRepeatOk are words that can be correctly repeated, e.g., "that that"
+var docRepeatOk = map[string]bool{"that": true, "had": true}
+
+// DocGrammar returns the grammar problems in given masked lines: repeated
+// words, and "a" vs. "an" -- only words separated by spaces alone, within a
+// line, are checked against each other
+func DocGrammar(lines [][]rune) []DocIssue {
+	var iss []DocIssue
+	for li, ln := range lines {
+		type fld struct{ st, ed int }
+		var flds []fld
+		docFields(ln, func(st, ed int) { flds = append(flds, fld{st, ed}) })
+		for i := 1; i < len(flds); i++ {
+			p, n := flds[i-1], flds[i]
+			pw := string(ln[p.st:p.ed])
+			nw := strings.TrimRight(string(ln[n.st:n.ed]), ".,;:!?)\"'")
+			if !docIsLetters(pw) || !docIsLetters(nw) {
+				continue
+			}
+			lp := strings.ToLower(pw)
+			switch {
+			case lp == strings.ToLower(nw) && !docRepeatOk[lp]:
+				ned := n.st + len([]rune(nw))
+				iss = append(iss, DocIssue{Ln: li, St: p.st, Ed: ned, Kind: "grammar", Code: "repeated-word", Word: string(ln[p.st:ned]), Message: fmt.Sprintf("repeated word %q", pw), Suggests: []string{pw}})
+			case lp == "a" || lp == "an":
+				if len(nw) < 2 || strings.ToUpper(nw) == nw { // letters or acronyms vary
+					continue
+				}
+				an := DocAnWord(nw)
+				if an == (lp == "an") {
+					continue
+				}
+				sug := "a"
+				if an {
+					sug = "an"
+				}
+				if unicode.IsUpper([]rune(pw)[0]) {
+					sug = strings.ToUpper(sug[:1]) + sug[1:]
+				}
+				iss = append(iss, DocIssue{Ln: li, St: p.st, Ed: p.ed, Kind: "grammar", Code: "a-an", Word: pw, Message: fmt.Sprintf("use %q before %q", strings.ToLower(sug), nw), Suggests: []string{sug}})
+			}
+		}
+	}
+	return iss
+}
+
+// DocMaxSuggests is the maximum number of spelling suggestions offered as
+// fixes for each misspelled word
+var DocMaxSuggests = 3
+
+// DocSpelling returns the misspelled words in given masked lines, using the
+// spell checker -- which has global state, so this must not be called while
+// a check in the Spell panel is in progress
+func DocSpelling(lines [][]rune) []DocIssue {
+	var iss []DocIssue
+	gi.InitNewSpellCheck(DocSpellText(lines))
+	for {
+		tw, sugs, err := gi.NextUnknownWord()
+		if err != nil || tw.Word == "" {
+			break
+		}
+		if len(sugs) > DocMaxSuggests {
+			sugs = sugs[:DocMaxSuggests]
+		}
+		iss = append(iss, DocIssue{Ln: tw.Line, St: tw.StartPos, Ed: tw.EndPos, Kind: "spelling", Code: "misspelling", Word: tw.Word, Message: fmt.Sprintf("unknown word %q", tw.Word), Suggests: sugs})
+	}
+	return iss
+}
+
+// DocCheckFileOk returns true if given file node should be checked
+func DocCheckFileOk(fn *giv.FileNode) bool {
+	if fn.IsDir() || fn.IsAutoSave() {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(fn.Nm))
+	for _, e := range DocCheckExts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// DocSource is a file to be checked for documentation problems: a copy of
+// the text of its buffer, if it is open, and otherwise nil, to read it from
+// disk -- so that it can be checked in the background
+type DocSource struct {
+	Node *giv.FileNode
+	Src  []byte
+}
+
+// NewDocSource returns the source of given file node for checking -- it
+// copies the text of its buffer, so it must be called on the GUI goroutine,
+// where the buffer is edited
+func NewDocSource(fn *giv.FileNode) DocSource {
+	ds := DocSource{Node: fn}
+	if fn.IsOpen() && fn.Buf != nil {
+		ds.Src = fn.Buf.LinesToBytesCopy()
+	}
+	return ds
+}
+
+// Check checks the documentation of the source for spelling and grammar,
+// reading the file from disk if it has no text -- sorted by position
+func (ds *DocSource) Check() []DocIssue {
+	src := ds.Src
+	if src == nil {
+		var err error
+		src, err = ioutil.ReadFile(string(ds.Node.FPath))
+		if err != nil {
+			log.Printf("gide.DocSource.Check: read error: %v\n", err)
+			return nil
+		}
+	}
+	lines := DocMaskFile(ds.Node.Nm, src)
+	if lines == nil {
+		return nil
+	}
+	iss := append(DocSpelling(lines), DocGrammar(lines)...)
+	sort.SliceStable(iss, func(i, j int) bool {
+		if iss[i].Ln != iss[j].Ln {
+			return iss[i].Ln < iss[j].Ln
+		}
+		return iss[i].St < iss[j].St
+	})
+	return iss
+}
+
+// DocCheckFile checks the documentation in given file node for spelling and
+// grammar, using its open buffer if it has one, and otherwise reading the
+// file from disk -- sorted by position -- on the GUI goroutine
+func DocCheckFile(fn *giv.FileNode) []DocIssue {
+	ds := NewDocSource(fn)
+	return ds.Check()
+}
+
+// DocTreeSources returns the sources of all the files to check starting at
+// given node (only within open directories, as in FileTreeSearch) -- on the
+// GUI goroutine, see NewDocSource
+func DocTreeSources(start *giv.FileNode) []DocSource {
+	var srcs []DocSource
+	start.FuncDownMeFirst(0, start, func(k ki.Ki, level int, d interface{}) bool {
+		sfn := k.Embed(giv.KiT_FileNode).(*giv.FileNode)
+		if sfn.IsDir() && !sfn.IsOpen() {
+			return false // don't go down into closed directories!
+		}
+		if DocCheckFileOk(sfn) {
+			srcs = append(srcs, NewDocSource(sfn))
+		}
+		return true
+	})
+	return srcs
+}
+
+// DocCheckSources returns the documentation problems in given sources,
+// sorted by file path -- it only uses the sources, so it can run in the
+// background
+func DocCheckSources(srcs []DocSource) []DocFileResults {
+	res := make([]DocFileResults, 0)
+	for i := range srcs {
+		if iss := srcs[i].Check(); len(iss) > 0 {
+			res = append(res, DocFileResults{srcs[i].Node, iss})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Node.FPath < res[j].Node.FPath
+	})
+	return res
+}
+
+// DocProblems returns given results as problems, e.g., for export
+func DocProblems(res []DocFileResults, root string) []Problem {
+	var pbs []Problem
+	for _, fr := range res {
+		fp := string(fr.Node.FPath)
+		rp := refRelPath(root, fp)
+		for _, is := range fr.Issues {
+			msg := is.Message
+			if len(is.Suggests) > 0 {
+				msg += " -- did you mean: " + strings.Join(is.Suggests, ", ")
+			}
+			pbs = append(pbs, Problem{Path: fp, RelPath: rp, Line: is.Ln + 1, Col: is.St + 1, EndLine: is.Ln + 1, EndCol: is.Ed + 1, Severity: "info", Source: is.Kind, Code: is.Code, Message: msg})
+		}
+	}
+	return pbs
+}
+
+// DocCheckView is a widget that displays the spelling and grammar problems
+// found in the Markdown files and Go doc comments of the project, grouped by
+// file, with links to each one and to apply each suggested fix
+type DocCheckView struct {
+	gi.Layout
+	Gide    *Gide            `json:"-" xml:"-" desc:"parent gide project"`
+	Results []DocFileResults `json:"-" xml:"-" desc:"current results, by file"`
+	ResMu   sync.Mutex       `json:"-" xml:"-" view:"-" desc:"mutex protecting results -- checking is done in the background"`
+}
+
+var KiT_DocCheckView = kit.Types.AddType(&DocCheckView{}, DocCheckViewProps)
+
+// Rescan checks the entire project in the background, and displays the
+// results when done -- the files to check, and the text of open buffers,
+// are collected here, before checking them
+func (dv *DocCheckView) Rescan() {
+	ge := dv.Gide
+	root := ge.Files.Embed(giv.KiT_FileNode).(*giv.FileNode)
+	gi.InitSpell()
+	srcs := DocTreeSources(root)
+	ge.SetStatus("Checking project documentation...")
+	go func() {
+		res := DocCheckSources(srcs)
+		ge.RunOnGui(func() {
+			dv.ResMu.Lock()
+			dv.Results = res
+			dv.ResMu.Unlock()
+			dv.ShowResults()
+			ge.SetStatus(fmt.Sprintf("Found %d documentation problems in %d files", dv.NIssues(), len(res)))
+		})
+	}()
+}
+
+// RescanFile rechecks just the given file, updating the results for it --
+// called after a fix is applied
+func (dv *DocCheckView) RescanFile(fn *giv.FileNode) {
+	var iss []DocIssue
+	if DocCheckFileOk(fn) {
+		iss = DocCheckFile(fn)
+	}
+	dv.ResMu.Lock()
+	fi := -1
+	for i, fr := range dv.Results {
+		if fr.Node == fn || fr.Node.FPath == fn.FPath {
+			fi = i
+			break
+		}
+	}
+	switch {
+	case fi >= 0 && len(iss) > 0:
+		dv.Results[fi].Issues = iss
+	case fi >= 0:
+		dv.Results = append(dv.Results[:fi], dv.Results[fi+1:]...)
+	case len(iss) > 0:
+		dv.Results = append(dv.Results, DocFileResults{fn, iss})
+		sort.Slice(dv.Results, func(i, j int) bool {
+			return dv.Results[i].Node.FPath < dv.Results[j].Node.FPath
+		})
+	}
+	dv.ResMu.Unlock()
+	dv.ShowResults()
+}
+
+// NIssues returns the total number of problems in the current results
+func (dv *DocCheckView) NIssues() int {
+	dv.ResMu.Lock()
+	defer dv.ResMu.Unlock()
+	n := 0
+	for _, fr := range dv.Results {
+		n += len(fr.Issues)
+	}
+	return n
+}
+
+// ShowResults renders the current results into the results buffer -- each
+// suggestion is a docfix:/// link that applies it
+func (dv *DocCheckView) ShowResults() {
+	tbuf, _ := dv.Gide.FindOrMakeCmdBuf("Docs", true)
+	tbuf.New(0)
+
+	dv.ResMu.Lock()
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	for _, fr := range dv.Results {
+		fp := fr.Node.Info.Path
+		fn := fr.Node.MyRelPath()
+		lstr := fmt.Sprintf(`%v: %v`, fn, len(fr.Issues))
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte(fmt.Sprintf(`<b>%v</b>`, lstr)))
+		for _, is := range fr.Issues {
+			ln := is.Ln + 1
+			ch := is.St + 1
+			ech := is.Ed + 1
+			fnstr := fmt.Sprintf("%v:%d:%d", fn, ln, ch)
+			msg := html.EscapeString(is.Message)
+			lstr = fmt.Sprintf(`	%v: %v: %v`, fnstr, is.Kind, is.Message)
+			mstr := fmt.Sprintf(`	<a href="file:///%v#L%vC%v">%v</a>: <b>%v</b>: %v`, fp, ln, ch, fnstr, is.Kind, msg)
+			if len(is.Suggests) > 0 {
+				lstr += " -- fix: " + strings.Join(is.Suggests, " | ")
+				mus := make([]string, len(is.Suggests))
+				for i, sg := range is.Suggests {
+					q := url.Values{"fix": {sg}, "word": {is.Word}}
+					mus[i] = fmt.Sprintf(`<a href="docfix:///%v?%v#L%vC%v-L%vC%v">%v</a>`, fp, html.EscapeString(q.Encode()), ln, ch, ln, ech, html.EscapeString(sg))
+				}
+				mstr += " -- fix: " + strings.Join(mus, " | ")
+			}
+			outlns = append(outlns, []byte(lstr))
+			outmus = append(outmus, []byte(mstr))
+		}
+		outlns = append(outlns, []byte(""))
+		outmus = append(outmus, []byte(""))
+	}
+	dv.ResMu.Unlock()
+
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	tbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+}
+
+// ApplyFix applies the fix in given docfix:/// url: replaces the region in
+// the url with the fix, if it still has the text that was checked, and
+// rechecks the file
+func (dv *DocCheckView) ApplyFix(ur string) bool {
+	ge := dv.Gide
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("DocCheckView ApplyFix parse err: %v\n", err)
+		return false
+	}
+	fpath := up.Path[1:] // has double //
+	tv, _, ok := ge.LinkViewFile(gi.FileName(fpath))
+	if !ok {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Open File at Link", Prompt: fmt.Sprintf("Could not find or open file path in project: %v", fpath)}, true, false, nil, nil)
+		return false
+	}
+	reg := giv.TextRegion{}
+	if !reg.FromString(up.Fragment) {
+		return false
+	}
+	q := up.Query()
+	tb := tv.Buf
+	st, ed := reg.Start, reg.End
+	if st.Ln >= len(tb.Lines) || ed.Ch > len(tb.Lines[st.Ln]) || string(tb.Lines[st.Ln][st.Ch:ed.Ch]) != q.Get("word") {
+		ge.SetStatus("Text has changed since it was checked -- rescan to update")
+		tv.SetCursorShow(st)
+		return false
+	}
+	tb.DeleteText(st, ed, true, true)
+	fix := q.Get("fix")
+	tb.InsertText(st, []byte(fix), true, true)
+	tv.SetCursorShow(giv.TextPos{Ln: st.Ln, Ch: st.Ch + len([]rune(fix))})
+	if fnk, ok := ge.Files.FindFile(fpath); ok {
+		dv.RescanFile(fnk.Embed(giv.KiT_FileNode).(*giv.FileNode))
+	}
+	return true
+}
+
+// NextIssue shows next problem
+func (dv *DocCheckView) NextIssue() {
+	dtv := dv.TextView()
+	ok := dtv.CursorNextLink(true) // wrap
+	if ok {
+		dtv.OpenLinkAt(dtv.CursorPos)
+	}
+}
+
+// PrevIssue shows previous problem
+func (dv *DocCheckView) PrevIssue() {
+	dtv := dv.TextView()
+	ok := dtv.CursorPrevLink(true) // wrap
+	if ok {
+		dtv.OpenLinkAt(dtv.CursorPos)
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (dv *DocCheckView) UpdateView(ge *Gide) {
+	dv.Gide = ge
+	mods, updt := dv.StdDocCheckConfig()
+	dv.ConfigToolbar()
+	tvly := dv.TextViewLay()
+	dv.Gide.ConfigOutputTextView(tvly)
+	if mods {
+		dv.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (dv *DocCheckView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "docbar")
+	config.Add(gi.KiT_Layout, "doctext")
+	return config
+}
+
+// StdDocCheckConfig configures a standard setup of the overall layout --
+// returns mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (dv *DocCheckView) StdDocCheckConfig() (mods, updt bool) {
+	dv.Lay = gi.LayoutVert
+	dv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := dv.StdConfig()
+	mods, updt = dv.ConfigChildren(config, false)
+	return
+}
+
+// DocBar returns the doc check toolbar
+func (dv *DocCheckView) DocBar() *gi.ToolBar {
+	tbi, ok := dv.ChildByName("docbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// TextViewLay returns the doc check results TextView layout
+func (dv *DocCheckView) TextViewLay() *gi.Layout {
+	tvi, ok := dv.ChildByName("doctext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the doc check results TextView
+func (dv *DocCheckView) TextView() *giv.TextView {
+	tvly := dv.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (dv *DocCheckView) ConfigToolbar() {
+	tb := dv.DocBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	rescan := tb.AddNewChild(gi.KiT_Action, "rescan").(*gi.Action)
+	rescan.SetText("Rescan")
+	rescan.Tooltip = "recheck all Markdown files and Go doc comments in the project. Only open folders in file browser will be checked -- adjust those to scope the check"
+	rescan.ActionSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dvv, _ := recv.Embed(KiT_DocCheckView).(*DocCheckView)
+		dvv.Rescan()
+	})
+
+	next := tb.AddNewChild(gi.KiT_Action, "next").(*gi.Action)
+	next.SetIcon("widget-wedge-down")
+	next.Tooltip = "go to next problem"
+	next.ActionSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dvv, _ := recv.Embed(KiT_DocCheckView).(*DocCheckView)
+		dvv.NextIssue()
+	})
+
+	prev := tb.AddNewChild(gi.KiT_Action, "prev").(*gi.Action)
+	prev.SetIcon("widget-wedge-up")
+	prev.Tooltip = "go to previous problem"
+	prev.ActionSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dvv, _ := recv.Embed(KiT_DocCheckView).(*DocCheckView)
+		dvv.PrevIssue()
+	})
+
+	ej := tb.AddNewChild(gi.KiT_Action, "export-json").(*gi.Action)
+	ej.SetText("Export JSON")
+	ej.Tooltip = "export the problems to a JSON file, in the same format as the Problems panel"
+	ej.ActionSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dvv, _ := recv.Embed(KiT_DocCheckView).(*DocCheckView)
+		dvv.ResMu.Lock()
+		pbs := DocProblems(dvv.Results, string(dvv.Gide.ProjRoot))
+		dvv.ResMu.Unlock()
+		dvv.Gide.ExportProblemsDialog(pbs, ".json")
+	})
+}
+
+var DocCheckViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
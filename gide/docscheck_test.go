@@ -0,0 +1,110 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"testing"
+)
+
+// docUnmask returns masked lines as text, with masked runes as '~'
+func docUnmask(lines [][]rune) string {
+	sl := make([]string, len(lines))
+	for i, ln := range lines {
+		sl[i] = strings.Replace(string(ln), string(DocMaskRune), "~", -1)
+	}
+	return strings.Join(sl, "\n")
+}
+
+func TestDocMaskGo(t *testing.T) {
+	src := `// Package p does things.
+package p
+
+// Foo returns ünï, e.g.:
+//	x := Foo()
+//go:noinline
+func Foo() int {
+	// not a doc comment
+	return 1
+}
+
+type T struct {
+	// A is a field
+	A int // trailing
+}
+`
+	got := docUnmask(DocMaskGo([]byte(src)))
+	want := `~~ Package p does things.
+~~~~~~~~~
+
+~~ Foo returns ünï, e.g.:
+~~~~~~~~~~~~~
+~~~~~~~~~~~~~
+~~~~~~~~~~~~~~~~
+~~~~~~~~~~~~~~~~~~~~~
+~~~~~~~~~
+~
+
+~~~~~~~~~~~~~~~
+~~~ A is a field
+~~~~~~~~~~~~~~~~~~
+~
+`
+	if got != want {
+		t.Errorf("DocMaskGo:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestDocMaskMarkdown(t *testing.T) {
+	src := "# Title\n\nSee `code` and [the docs](http://x.org/a) or <b>here</b>.\n\n```go\nfunc teh() {}\n```\n\n    indented code\nEnd"
+	got := docUnmask(DocMaskMarkdown([]byte(src)))
+	want := "# Title\n\nSee ~~~~~~ and [the docs]~~~~~~~~~~~~~~~~ or ~~~here~~~~.\n\n~~~~~\n~~~~~~~~~~~~~\n~~~\n\n~~~~~~~~~~~~~~~~~\nEnd"
+	if got != want {
+		t.Errorf("DocMaskMarkdown:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestDocSpellText(t *testing.T) {
+	lines := DocMaskMarkdown([]byte("Call `f` with myVar, a_b, gi.TextView or http://x.org (and 2nd) [link](u)."))
+	got := string(DocSpellText(lines))
+	want := "Call     with                         or              (and      [link]   ."
+	if got != want {
+		t.Errorf("DocSpellText:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestDocAnWord(t *testing.T) {
+	for w, want := range map[string]bool{"apple": true, "hour": true, "user": false, "unused": true, "Unix": false, "banana": false, "honest": true, "one": false, "error": true} {
+		if got := DocAnWord(w); got != want {
+			t.Errorf("DocAnWord(%q) = %v, want %v", w, got, want)
+		}
+	}
+}
+
+func TestDocGrammar(t *testing.T) {
+	lines := DocMaskMarkdown([]byte("This is the the end, a error.\nAn banana and an HTML page, that that is ok.\nUse a `int` value, is is."))
+	iss := DocGrammar(lines)
+	type res struct {
+		ln, st, ed int
+		code, word string
+		sug        string
+	}
+	want := []res{
+		{0, 8, 15, "repeated-word", "the the", "the"},
+		{0, 21, 22, "a-an", "a", "an"},
+		{1, 0, 2, "a-an", "An", "A"},
+		{2, 19, 24, "repeated-word", "is is", "is"},
+	}
+	if len(iss) != len(want) {
+		t.Fatalf("DocGrammar: got %d issues, want %d: %+v", len(iss), len(want), iss)
+	}
+	for i, w := range want {
+		is := iss[i]
+		g := res{is.Ln, is.St, is.Ed, is.Code, is.Word, is.Suggests[0]}
+		if g != w {
+			t.Errorf("DocGrammar %d: got %+v, want %+v", i, g, w)
+		}
+	}
+}
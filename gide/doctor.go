@@ -0,0 +1,708 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// levels of the results of the Doctor checks
+const (
+	DoctorOk      = "ok"
+	DoctorWarning = "warning"
+	DoctorError   = "error"
+)
+
+// DoctorGoMin is the oldest Go version that gide works with, as major,
+// minor -- modules need go1.11
+var DoctorGoMin = [2]int{1, 11}
+
+// DoctorGoURL is where Go is downloaded from, to install or update it
+var DoctorGoURL = "https://go.dev/dl/"
+
+// DoctorMinWatches is the inotify max_user_watches limit under which file
+// watching can fail on big projects, on Linux
+var DoctorMinWatches = 65536
+
+// DoctorWatchesPath is the file with the inotify max_user_watches limit
+var DoctorWatchesPath = "/proc/sys/fs/inotify/max_user_watches"
+
+// DoctorTool is an external tool that gide uses, checked by the Doctor
+type DoctorTool struct {
+	Name     string `desc:"name of the program, as found on the PATH"`
+	Use      string `desc:"what gide uses it for"`
+	Required bool   `desc:"gide can not work without it -- its absence is an error, not a warning"`
+	Install  string `desc:"package to go install it from, e.g., golang.org/x/tools/gopls@latest -- empty if it can not be installed that way"`
+}
+
+// DoctorTools are the external tools that the Doctor checks for -- the
+// language servers in Preferences are checked too
+var DoctorTools = []DoctorTool{
+	{Name: "go", Use: "building, running and testing Go code", Required: true},
+	{Name: "gofmt", Use: "formatting Go files", Required: true},
+	{Name: "goimports", Use: "formatting Go files and fixing their imports on save", Install: "golang.org/x/tools/cmd/goimports@latest"},
+	{Name: "gopls", Use: "the Go language server: completion, diagnostics, definitions", Install: "golang.org/x/tools/gopls@latest"},
+	{Name: "dlv", Use: "debugging", Install: "github.com/go-delve/delve/cmd/dlv@latest"},
+	{Name: "gorename", Use: "renaming without a language server", Install: "golang.org/x/tools/cmd/gorename@latest"},
+	{Name: "git", Use: "version control"},
+}
+
+// DoctorCheck is the result of one check of the setup by the Doctor
+type DoctorCheck struct {
+	Name    string            `desc:"what was checked, e.g., Go toolchain"`
+	Level   string            `desc:"ok, warning or error"`
+	Message string            `desc:"what was found, and what to do about it"`
+	FixName string            `desc:"label of the fix, e.g., Install -- empty if there is none"`
+	Fix     func(*DoctorView) `json:"-" desc:"applies the fix"`
+}
+
+// goVersionRe matches the version in the output of go version
+var goVersionRe = regexp.MustCompile(`\bgo(\d+)\.(\d+)`)
+
+// ParseGoVersion returns the major and minor version in given output of go
+// version, e.g., go version go1.12.5 linux/amd64
+func ParseGoVersion(out string) (major, minor int, ok bool) {
+	m := goVersionRe.FindStringSubmatch(out)
+	if m == nil {
+		return 0, 0, false
+	}
+	major, _ = strconv.Atoi(m[1])
+	minor, _ = strconv.Atoi(m[2])
+	return major, minor, true
+}
+
+// GoVersionLess returns true if version major.minor is older than min
+func GoVersionLess(major, minor int, min [2]int) bool {
+	return major < min[0] || major == min[0] && minor < min[1]
+}
+
+// KeyMapConflicts returns the conflicts in given key map: the single keys
+// bound to a function that also start a two key sequence, so they are
+// always taken as the start of the sequence and their function can not be
+// used
+func KeyMapConflicts(km KeySeqMap) []string {
+	var cfs []string
+	for ks, kf := range km {
+		if ks.Key2 != "" {
+			continue
+		}
+		var seqs []string
+		for ks2, kf2 := range km {
+			if ks2.Key1 == ks.Key1 && ks2.Key2 != "" {
+				seqs = append(seqs, fmt.Sprintf("%v %v (%v)", ks2.Key1, ks2.Key2, kf2))
+			}
+		}
+		if len(seqs) == 0 {
+			continue
+		}
+		sort.Strings(seqs)
+		cfs = append(cfs, fmt.Sprintf("%v is bound to %v, but also starts %v, so %v can not be used", ks.Key1, kf, strings.Join(seqs, ", "), kf))
+	}
+	sort.Strings(cfs)
+	return cfs
+}
+
+// ParseMaxUserWatches parses the contents of the inotify max_user_watches
+// file
+func ParseMaxUserWatches(b []byte) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	return n, err == nil
+}
+
+// PathProblems returns the problems with the directories in given PATH:
+// empty entries (which mean the current directory), entries that are not
+// existing directories, and duplicates, with the cleaned PATH without them
+func PathProblems(path string, isDir func(dir string) bool) ([]string, string) {
+	var pbs []string
+	var keep []string
+	seen := make(map[string]bool)
+	for _, d := range filepath.SplitList(path) {
+		switch {
+		case d == "":
+			pbs = append(pbs, "an empty entry, which runs programs from the current directory")
+		case seen[filepath.Clean(d)]:
+			pbs = append(pbs, "a duplicate of "+d)
+		case !isDir(d):
+			pbs = append(pbs, d+", which is not an existing directory")
+			seen[filepath.Clean(d)] = true
+		default:
+			seen[filepath.Clean(d)] = true
+			keep = append(keep, d)
+		}
+	}
+	return pbs, strings.Join(keep, string(filepath.ListSeparator))
+}
+
+// PathHas returns true if given PATH has given directory
+func PathHas(path, dir string) bool {
+	dir = filepath.Clean(dir)
+	for _, d := range filepath.SplitList(path) {
+		if d != "" && filepath.Clean(d) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// GoBinDir returns the directory that go install puts programs in, given
+// the output of go env GOBIN GOPATH: GOBIN, or the bin of the first GOPATH
+func GoBinDir(envOut string) string {
+	lns := strings.Split(strings.TrimRight(envOut, "\r\n"), "\n")
+	if len(lns) != 2 {
+		return ""
+	}
+	if bin := strings.TrimSpace(lns[0]); bin != "" {
+		return bin
+	}
+	gp := strings.TrimSpace(lns[1])
+	if gp == "" {
+		return ""
+	}
+	return filepath.Join(filepath.SplitList(gp)[0], "bin")
+}
+
+// isDir returns true if given path is an existing directory
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// RunDoctor checks the setup of gide: the Go toolchain and its version,
+// the external tools, the prefs files, the key map, the file watcher limit
+// and the PATH
+func RunDoctor() []DoctorCheck {
+	var cks []DoctorCheck
+	cks = append(cks, DoctorGo())
+	cks = append(cks, DoctorToolChecks()...)
+	cks = append(cks, DoctorPrefsChecks()...)
+	cks = append(cks, DoctorKeyMap())
+	if ck, ok := DoctorWatches(); ok {
+		cks = append(cks, ck)
+	}
+	cks = append(cks, DoctorPath()...)
+	return cks
+}
+
+// DoctorGo checks that the Go toolchain is present and recent enough
+func DoctorGo() DoctorCheck {
+	ck := DoctorCheck{Name: "Go toolchain", FixName: "Download Go", Fix: func(dv *DoctorView) {
+		dv.Gide.OpenURL(DoctorGoURL)
+	}}
+	out, err := exec.Command("go", "version").Output()
+	if err != nil {
+		ck.Level = DoctorError
+		ck.Message = "go version failed: " + err.Error() + " -- install Go, and make sure it is on the PATH"
+		return ck
+	}
+	major, minor, ok := ParseGoVersion(string(out))
+	switch {
+	case !ok:
+		ck.Level = DoctorWarning
+		ck.Message = "could not read the version of Go from: " + strings.TrimSpace(string(out))
+	case GoVersionLess(major, minor, DoctorGoMin):
+		ck.Level = DoctorError
+		ck.Message = fmt.Sprintf("go%d.%d is too old -- gide needs go%d.%d or later", major, minor, DoctorGoMin[0], DoctorGoMin[1])
+	default:
+		ck.Level = DoctorOk
+		ck.Message = strings.TrimSpace(string(out))
+		ck.FixName, ck.Fix = "", nil
+	}
+	return ck
+}
+
+// DoctorToolChecks checks that the external tools in DoctorTools and the
+// language servers in Preferences are on the PATH -- the ones that are not
+// can be installed with go install where that is known
+func DoctorToolChecks() []DoctorCheck {
+	tools := append([]DoctorTool{}, DoctorTools...)
+	for _, ls := range Prefs.LangServers {
+		fs := strings.Fields(ls.Cmd)
+		if len(fs) == 0 {
+			continue
+		}
+		has := false
+		for _, t := range tools {
+			if t.Name == fs[0] {
+				has = true
+				break
+			}
+		}
+		if !has {
+			tools = append(tools, DoctorTool{Name: fs[0], Use: fmt.Sprintf("the %v language server", ls.Lang)})
+		}
+	}
+	var cks []DoctorCheck
+	for _, t := range tools {
+		ck := DoctorCheck{Name: t.Name}
+		if p, err := exec.LookPath(t.Name); err == nil {
+			ck.Level = DoctorOk
+			ck.Message = p
+			cks = append(cks, ck)
+			continue
+		}
+		ck.Level = DoctorWarning
+		if t.Required {
+			ck.Level = DoctorError
+		}
+		ck.Message = fmt.Sprintf("not found on the PATH -- it is used for %v", t.Use)
+		if t.Install != "" {
+			pkg := t.Install
+			ck.FixName = "Install"
+			ck.Fix = func(dv *DoctorView) {
+				dv.Install(pkg)
+			}
+		}
+		cks = append(cks, ck)
+	}
+	return cks
+}
+
+// doctorPrefsFile is a prefs file checked by the Doctor: Load loads it into
+// a fresh value to find its errors, and Save saves the valid parts loaded
+// at startup in place of it
+type doctorPrefsFile struct {
+	Name string
+	Load func(b []byte) JSONErrors
+	Save func() error
+}
+
+// doctorPrefsFiles returns the prefs files that are checked by the Doctor
+func doctorPrefsFiles() []doctorPrefsFile {
+	return []doctorPrefsFile{
+		{PrefsFileName, func(b []byte) JSONErrors {
+			var pf Preferences
+			return JSONLoad(b, &pf, nil)
+		}, Prefs.Save},
+		{PrefsKeyMapsFileName, func(b []byte) JSONErrors {
+			var km KeyMaps
+			return JSONLoad(b, &km, CheckKeyMapsItem)
+		}, AvailKeyMaps.SavePrefs},
+		{PrefsCmdsFileName, func(b []byte) JSONErrors {
+			var cm Commands
+			return JSONLoad(b, &cm, CheckCommand)
+		}, CustomCmds.SavePrefs},
+		{PrefsLangsFileName, func(b []byte) JSONErrors {
+			var lt Langs
+			return JSONLoad(b, &lt, nil)
+		}, AvailLangs.SavePrefs},
+		{PrefsLayoutsFileName, func(b []byte) JSONErrors {
+			_, err := ParseLayouts(b)
+			je, _ := err.(JSONErrors)
+			return je
+		}, AvailLayouts.SavePrefs},
+	}
+}
+
+// DoctorPrefsChecks checks that the prefs files that exist are valid JSON
+// with the fields gide knows -- a broken one can be rewritten with just its
+// valid parts, which are what was loaded, after backing it up
+func DoctorPrefsChecks() []DoctorCheck {
+	pdir := oswin.TheApp.AppPrefsDir()
+	var cks []DoctorCheck
+	for _, pf := range doctorPrefsFiles() {
+		pnm := filepath.Join(pdir, pf.Name)
+		b, err := ioutil.ReadFile(pnm)
+		if os.IsNotExist(err) {
+			continue
+		}
+		ck := DoctorCheck{Name: pf.Name}
+		if err != nil {
+			ck.Level = DoctorError
+			ck.Message = err.Error()
+			cks = append(cks, ck)
+			continue
+		}
+		errs := pf.Load(b)
+		if len(errs) == 0 {
+			ck.Level = DoctorOk
+			ck.Message = pnm
+			cks = append(cks, ck)
+			continue
+		}
+		ck.Level = DoctorError
+		ck.Message = fmt.Sprintf("%d entries could not be loaded, and are skipped: %v", len(errs), errs[0].Error())
+		if len(errs) > 1 {
+			ck.Message += fmt.Sprintf(", and %d more", len(errs)-1)
+		}
+		save := pf.Save
+		ck.FixName = "Keep Valid Parts"
+		ck.Fix = func(dv *DoctorView) {
+			if err := os.Rename(pnm, pnm+".bak"); err != nil {
+				dv.Gide.SetStatus("Could not back up " + pnm + ": " + err.Error())
+				return
+			}
+			if err := save(); err != nil {
+				dv.Gide.SetStatus("Could not save " + pnm + ": " + err.Error())
+				return
+			}
+			dv.Gide.SetStatus("Saved the valid parts of " + pnm + " -- the broken file is in " + pnm + ".bak")
+		}
+		cks = append(cks, ck)
+	}
+	return cks
+}
+
+// DoctorKeyMap checks the active key map for conflicts
+func DoctorKeyMap() DoctorCheck {
+	ck := DoctorCheck{Name: "Key map " + string(ActiveKeyMapName)}
+	if ActiveKeyMap == nil {
+		ck.Level = DoctorError
+		ck.Message = "no key map is active"
+		return ck
+	}
+	cfs := KeyMapConflicts(*ActiveKeyMap)
+	if len(cfs) == 0 {
+		ck.Level = DoctorOk
+		ck.Message = "no conflicts"
+		return ck
+	}
+	ck.Level = DoctorWarning
+	ck.Message = strings.Join(cfs, "; ")
+	ck.FixName = "Edit Key Maps"
+	ck.Fix = func(dv *DoctorView) {
+		Prefs.EditKeyMaps()
+	}
+	return ck
+}
+
+// DoctorWatches checks that the inotify max_user_watches limit is high
+// enough to watch the files of big projects -- false if there is no such
+// limit, e.g., if it is not Linux
+func DoctorWatches() (DoctorCheck, bool) {
+	if runtime.GOOS != "linux" {
+		return DoctorCheck{}, false
+	}
+	b, err := ioutil.ReadFile(DoctorWatchesPath)
+	if err != nil {
+		return DoctorCheck{}, false
+	}
+	n, ok := ParseMaxUserWatches(b)
+	if !ok {
+		return DoctorCheck{}, false
+	}
+	ck := DoctorCheck{Name: "File watcher limit", Level: DoctorOk, Message: fmt.Sprintf("inotify max_user_watches is %d", n)}
+	if n >= DoctorMinWatches {
+		return ck, true
+	}
+	cmd := fmt.Sprintf("sudo sysctl fs.inotify.max_user_watches=%d", 8*DoctorMinWatches)
+	ck.Level = DoctorWarning
+	ck.Message += fmt.Sprintf(", under %d, so changes to files of big projects can be missed -- raise it with: %v", DoctorMinWatches, cmd)
+	ck.FixName = "Copy Command"
+	ck.Fix = func(dv *DoctorView) {
+		oswin.TheApp.ClipBoard(dv.Gide.ParentWindow().OSWin).Write(mimedata.NewTextBytes([]byte(cmd)))
+		dv.Gide.SetStatus("Copied: " + cmd + " -- run it in a terminal")
+	}
+	return ck, true
+}
+
+// DoctorPath checks the PATH: that the directory go install puts programs
+// in is on it, and that it has no empty, missing or duplicate entries --
+// both can be fixed for this session
+func DoctorPath() []DoctorCheck {
+	path := os.Getenv("PATH")
+	var cks []DoctorCheck
+	if out, err := exec.Command("go", "env", "GOBIN", "GOPATH").Output(); err == nil {
+		if bin := GoBinDir(string(out)); bin != "" {
+			ck := DoctorCheck{Name: "Go bin on PATH", Level: DoctorOk, Message: bin}
+			if !PathHas(path, bin) {
+				ck.Level = DoctorWarning
+				ck.Message = bin + " is not on the PATH, so the tools installed with go install can not be found -- add it to the PATH in your shell profile"
+				ck.FixName = "Add For Session"
+				ck.Fix = func(dv *DoctorView) {
+					os.Setenv("PATH", os.Getenv("PATH")+string(filepath.ListSeparator)+bin)
+					dv.Gide.SetStatus("Added " + bin + " to the PATH of this session")
+				}
+			}
+			cks = append(cks, ck)
+		}
+	}
+	ck := DoctorCheck{Name: "PATH", Level: DoctorOk, Message: "no problems"}
+	if pbs, clean := PathProblems(path, isDir); len(pbs) > 0 {
+		ck.Level = DoctorWarning
+		ck.Message = "the PATH has " + strings.Join(pbs, "; ")
+		ck.FixName = "Clean For Session"
+		ck.Fix = func(dv *DoctorView) {
+			os.Setenv("PATH", clean)
+			dv.Gide.SetStatus("Removed the problem entries from the PATH of this session")
+		}
+	}
+	cks = append(cks, ck)
+	return cks
+}
+
+// DoctorCounts returns the numbers of errors and warnings in given checks
+func DoctorCounts(cks []DoctorCheck) (errs, warns int) {
+	for _, ck := range cks {
+		switch ck.Level {
+		case DoctorError:
+			errs++
+		case DoctorWarning:
+			warns++
+		}
+	}
+	return
+}
+
+// Doctor checks the setup of gide -- the Go toolchain and its version, the
+// external tools, the prefs files, the key map, the file watcher limit and
+// the PATH -- and shows a report in the Doctor panel, with links to fix the
+// problems that can be fixed from gide
+func (ge *Gide) Doctor() {
+	dbuf, _ := ge.FindOrMakeCmdBuf("Doctor", true)
+	dvi, _ := ge.FindOrMakeMainTab("Doctor", KiT_DoctorView, true) // sel
+	dv := dvi.Embed(KiT_DoctorView).(*DoctorView)
+	dv.UpdateView(ge)
+	dtv := dv.TextView()
+	dtv.SetInactive()
+	dtv.SetBuf(dbuf)
+	dv.Rerun()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// DoctorStartup checks the setup of gide in the background when a project
+// is opened, and points to the Doctor in the status bar if there are errors
+func (ge *Gide) DoctorStartup() {
+	go func() {
+		cks := RunDoctor()
+		errs, _ := DoctorCounts(cks)
+		if errs == 0 {
+			return
+		}
+		for _, ck := range cks {
+			if ck.Level == DoctorError {
+				ge.SetStatus(fmt.Sprintf("Setup problem: %v: %v -- see Help / Doctor (%d errors)", ck.Name, ck.Message, errs))
+				return
+			}
+		}
+	}()
+}
+
+// OpenDoctorURL applies the fix in given doctor:/// url from Doctor --
+// delegates to DoctorView
+func (ge *Gide) OpenDoctorURL(ur string, dtv *giv.TextView) bool {
+	dvk, ok := dtv.ParentByType(KiT_DoctorView, true)
+	if !ok {
+		return false
+	}
+	dv := dvk.(*DoctorView)
+	return dv.ApplyFix(ur)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    DoctorView
+
+// DoctorView is a widget that shows the report of the Doctor on the setup
+// of gide, with links to fix the problems that can be fixed from gide
+type DoctorView struct {
+	gi.Layout
+	Gide     *Gide         `json:"-" xml:"-" desc:"parent gide project"`
+	Checks   []DoctorCheck `json:"-" xml:"-" desc:"results of the checks as of the last run"`
+	ChecksMu sync.Mutex    `json:"-" xml:"-" view:"-" desc:"mutex protecting the checks -- they are run in the background"`
+}
+
+var KiT_DoctorView = kit.Types.AddType(&DoctorView{}, DoctorViewProps)
+
+// Rerun checks the setup again in the background, and shows the report
+// when done
+func (dv *DoctorView) Rerun() {
+	ge := dv.Gide
+	ge.SetStatus("Checking the setup...")
+	go func() {
+		cks := RunDoctor()
+		dv.ChecksMu.Lock()
+		dv.Checks = cks
+		dv.ChecksMu.Unlock()
+		dv.ShowResults()
+		errs, warns := DoctorCounts(cks)
+		ge.SetStatus(fmt.Sprintf("Doctor: %d errors, %d warnings", errs, warns))
+	}()
+}
+
+// ShowResults renders the results of the checks into the results buffer,
+// errors first -- each fix is a doctor:/// link that applies it
+func (dv *DoctorView) ShowResults() {
+	tbuf, _ := dv.Gide.FindOrMakeCmdBuf("Doctor", true)
+	tbuf.New(0)
+
+	dv.ChecksMu.Lock()
+	idx := make([]int, len(dv.Checks))
+	for i := range idx {
+		idx[i] = i
+	}
+	rank := map[string]int{DoctorError: 0, DoctorWarning: 1, DoctorOk: 2}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return rank[dv.Checks[idx[i]].Level] < rank[dv.Checks[idx[j]].Level]
+	})
+	errs, warns := DoctorCounts(dv.Checks)
+	hdr := fmt.Sprintf("%d errors, %d warnings in %d checks", errs, warns, len(dv.Checks))
+	outlns := [][]byte{[]byte(hdr), []byte("")}
+	outmus := [][]byte{[]byte("<b>" + html.EscapeString(hdr) + "</b>"), []byte("")}
+	for _, i := range idx {
+		ck := &dv.Checks[i]
+		lstr := fmt.Sprintf("%v\t%v: %v", ck.Level, ck.Name, ck.Message)
+		mstr := fmt.Sprintf("<b>%v</b>\t%v: %v", ck.Level, html.EscapeString(ck.Name), html.EscapeString(ck.Message))
+		if ck.Fix != nil {
+			lstr += "  [" + ck.FixName + "]"
+			mstr += fmt.Sprintf(`  [<a href="doctor:///%d">%v</a>]`, i, html.EscapeString(ck.FixName))
+		}
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte(mstr))
+	}
+	dv.ChecksMu.Unlock()
+
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	tbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+}
+
+// ApplyFix applies the fix of the check in given doctor:/// url, by its
+// index in the checks, and runs the checks again
+func (dv *DoctorView) ApplyFix(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("DoctorView ApplyFix parse err: %v\n", err)
+		return false
+	}
+	i, err := strconv.Atoi(strings.TrimPrefix(up.Path, "/"))
+	dv.ChecksMu.Lock()
+	var fix func(*DoctorView)
+	if err == nil && i >= 0 && i < len(dv.Checks) {
+		fix = dv.Checks[i].Fix
+	}
+	dv.ChecksMu.Unlock()
+	if fix == nil {
+		return false
+	}
+	fix(dv)
+	dv.Rerun()
+	return true
+}
+
+// Install installs given package with go install in the background, and
+// runs the checks again when done
+func (dv *DoctorView) Install(pkg string) {
+	ge := dv.Gide
+	if err := OfflineCheck("go install " + pkg); err != nil {
+		ge.SetStatus(err.Error())
+		return
+	}
+	ge.SetStatus("Installing " + pkg + "...")
+	go func() {
+		cmd := exec.Command("go", "install", pkg)
+		cmd.Env = append(os.Environ(), OfflineEnv()...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			ge.SetStatus(fmt.Sprintf("go install %v failed: %v %s", pkg, err, bytes.TrimSpace(out)))
+			return
+		}
+		ge.SetStatus("Installed " + pkg)
+		dv.Rerun()
+	}()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (dv *DoctorView) UpdateView(ge *Gide) {
+	dv.Gide = ge
+	mods, updt := dv.StdDoctorConfig()
+	dv.ConfigToolbar()
+	tvly := dv.TextViewLay()
+	dv.Gide.ConfigOutputTextView(tvly)
+	if mods {
+		dv.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (dv *DoctorView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "doctorbar")
+	config.Add(gi.KiT_Layout, "doctortext")
+	return config
+}
+
+// StdDoctorConfig configures a standard setup of the overall layout --
+// returns mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (dv *DoctorView) StdDoctorConfig() (mods, updt bool) {
+	dv.Lay = gi.LayoutVert
+	dv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := dv.StdConfig()
+	mods, updt = dv.ConfigChildren(config, false)
+	return
+}
+
+// DoctorBar returns the doctor toolbar
+func (dv *DoctorView) DoctorBar() *gi.ToolBar {
+	tbi, ok := dv.ChildByName("doctorbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// TextViewLay returns the doctor report TextView layout
+func (dv *DoctorView) TextViewLay() *gi.Layout {
+	tvi, ok := dv.ChildByName("doctortext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the doctor report TextView
+func (dv *DoctorView) TextView() *giv.TextView {
+	tvly := dv.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (dv *DoctorView) ConfigToolbar() {
+	tb := dv.DoctorBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	rerun := tb.AddNewChild(gi.KiT_Action, "rerun").(*gi.Action)
+	rerun.SetText("Rerun")
+	rerun.Tooltip = "check the setup again, e.g., after fixing a problem outside of gide"
+	rerun.ActionSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dvv, _ := recv.Embed(KiT_DoctorView).(*DoctorView)
+		dvv.Rerun()
+	})
+}
+
+var DoctorViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
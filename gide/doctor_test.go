@@ -0,0 +1,90 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGoVersion(t *testing.T) {
+	tests := []struct {
+		out          string
+		major, minor int
+		ok           bool
+	}{
+		{"go version go1.12.5 linux/amd64\n", 1, 12, true},
+		{"go version go1.10 darwin/amd64", 1, 10, true},
+		{"go version devel +abc123 Mon Oct 1 2018", 0, 0, false},
+	}
+	for _, tt := range tests {
+		major, minor, ok := ParseGoVersion(tt.out)
+		if major != tt.major || minor != tt.minor || ok != tt.ok {
+			t.Errorf("ParseGoVersion(%q) = %v, %v, %v, want %v, %v, %v", tt.out, major, minor, ok, tt.major, tt.minor, tt.ok)
+		}
+	}
+	if !GoVersionLess(1, 10, [2]int{1, 11}) || GoVersionLess(1, 11, [2]int{1, 11}) || GoVersionLess(2, 0, [2]int{1, 11}) {
+		t.Errorf("GoVersionLess: wrong order")
+	}
+}
+
+func TestKeyMapConflicts(t *testing.T) {
+	km := KeySeqMap{
+		KeySeq{"Control+X", ""}:          KeyFunNextPanel,
+		KeySeq{"Control+X", "Control+S"}: KeyFunBufSave,
+		KeySeq{"Control+O", ""}:          KeyFunPrevPanel,
+	}
+	cfs := KeyMapConflicts(km)
+	if len(cfs) != 1 || !strings.HasPrefix(cfs[0], "Control+X is bound to") {
+		t.Errorf("KeyMapConflicts: %v, want one for Control+X", cfs)
+	}
+	delete(km, KeySeq{"Control+X", ""})
+	if cfs := KeyMapConflicts(km); len(cfs) != 0 {
+		t.Errorf("KeyMapConflicts: %v, want none", cfs)
+	}
+}
+
+func TestParseMaxUserWatches(t *testing.T) {
+	if n, ok := ParseMaxUserWatches([]byte("8192\n")); !ok || n != 8192 {
+		t.Errorf("ParseMaxUserWatches: %v, %v, want 8192, true", n, ok)
+	}
+	if _, ok := ParseMaxUserWatches([]byte("")); ok {
+		t.Errorf("ParseMaxUserWatches: empty is ok")
+	}
+}
+
+func TestPathProblems(t *testing.T) {
+	sep := string(filepath.ListSeparator)
+	dirs := map[string]bool{"/usr/bin": true, "/bin": true}
+	isDir := func(d string) bool { return dirs[d] }
+	path := strings.Join([]string{"/usr/bin", "", "/nope", "/bin", "/usr/bin/"}, sep)
+	pbs, clean := PathProblems(path, isDir)
+	if len(pbs) != 3 {
+		t.Errorf("PathProblems: %v, want 3", pbs)
+	}
+	if want := "/usr/bin" + sep + "/bin"; clean != want {
+		t.Errorf("PathProblems clean: %q, want %q", clean, want)
+	}
+	if !PathHas(path, "/bin/") || PathHas(path, "/sbin") {
+		t.Errorf("PathHas: wrong")
+	}
+}
+
+func TestGoBinDir(t *testing.T) {
+	tests := []struct {
+		out, want string
+	}{
+		{"/opt/gobin\n/home/me/go\n", "/opt/gobin"},
+		{"\n/home/me/go\n", filepath.Join("/home/me/go", "bin")},
+		{"\n/home/me/go" + string(filepath.ListSeparator) + "/other\n", filepath.Join("/home/me/go", "bin")},
+		{"\n\n", ""},
+	}
+	for _, tt := range tests {
+		if got := GoBinDir(tt.out); got != tt.want {
+			t.Errorf("GoBinDir(%q) = %q, want %q", tt.out, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,467 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+)
+
+// EditorConfigName is the name of the EditorConfig files, which set the
+// indentation, line endings and charset of the files in their directory and
+// below -- see https://editorconfig.org
+var EditorConfigName = ".editorconfig"
+
+// ECSection is a section of an EditorConfig file: the properties of the
+// files matching its glob
+type ECSection struct {
+	Glob  string
+	Props map[string]string
+}
+
+// ECFile is a parsed EditorConfig file
+type ECFile struct {
+	Root     bool        `desc:"root = true was set in the preamble: files in the directories above are not looked at"`
+	Sections []ECSection `desc:"sections, in order -- later ones override earlier ones"`
+}
+
+// ParseEditorConfig parses the text of an EditorConfig file -- property
+// names, and the values of the standard ones, are lower case
+func ParseEditorConfig(b []byte) *ECFile {
+	ef := &ECFile{}
+	var cur *ECSection
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		l := strings.TrimSpace(sc.Text())
+		if l == "" || l[0] == '#' || l[0] == ';' {
+			continue
+		}
+		if l[0] == '[' {
+			if ed := strings.LastIndex(l, "]"); ed > 0 {
+				ef.Sections = append(ef.Sections, ECSection{Glob: l[1:ed], Props: map[string]string{}})
+				cur = &ef.Sections[len(ef.Sections)-1]
+			}
+			continue
+		}
+		eq := strings.IndexAny(l, "=:")
+		if eq < 0 {
+			continue
+		}
+		k := strings.ToLower(strings.TrimSpace(l[:eq]))
+		v := strings.TrimSpace(l[eq+1:])
+		if ecLowerVal(k) {
+			v = strings.ToLower(v)
+		}
+		if cur == nil {
+			if k == "root" {
+				ef.Root = v == "true"
+			}
+			continue
+		}
+		cur.Props[k] = v
+	}
+	return ef
+}
+
+// ecLowerVal returns true if the values of given property are case
+// insensitive
+func ecLowerVal(k string) bool {
+	switch k {
+	case "root", "indent_style", "indent_size", "tab_width", "end_of_line", "charset", "trim_trailing_whitespace", "insert_final_newline":
+		return true
+	}
+	return false
+}
+
+var ecRangeRe = regexp.MustCompile(`^([+-]?\d+)\.\.([+-]?\d+)$`)
+
+// ECGlobRegexp returns the regexp for given EditorConfig glob, matching the
+// slash-separated path of a file relative to the directory of the
+// EditorConfig file: * matches within a directory, ** across directories,
+// ? one character, [abc] and [!abc] a character in or not in a set,
+// {a,b} any of the alternatives and {1..3} a number in a range -- a glob
+// without a / matches the file name in any directory
+func ECGlobRegexp(glob string) (*regexp.Regexp, error) {
+	pfx := "^"
+	if strings.Contains(glob, "/") {
+		glob = strings.TrimPrefix(glob, "/")
+	} else {
+		pfx = "^(?:.*/)?"
+	}
+	re, err := ecGlobRe([]rune(glob))
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile(pfx + re + "$")
+}
+
+// ecGlobRe returns the regexp for given glob, without anchors
+func ecGlobRe(g []rune) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(g); i++ {
+		c := g[i]
+		switch c {
+		case '\\':
+			if i+1 < len(g) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(g[i])))
+			} else {
+				b.WriteString(`\\`)
+			}
+		case '*':
+			if i+1 < len(g) && g[i+1] == '*' {
+				i++
+				b.WriteString(".*")
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			ed := i + 1
+			for ed < len(g) && g[ed] != ']' {
+				ed++
+			}
+			if ed >= len(g) || ed == i+1 {
+				b.WriteString(`\[`)
+				continue
+			}
+			set := g[i+1 : ed]
+			b.WriteByte('[')
+			if set[0] == '!' || set[0] == '^' {
+				b.WriteByte('^')
+				set = set[1:]
+			}
+			for _, r := range set {
+				if r == '\\' || r == '[' || r == ']' || r == '^' {
+					b.WriteByte('\\')
+				}
+				b.WriteRune(r)
+			}
+			b.WriteByte(']')
+			i = ed
+		case '{':
+			ed, depth := i+1, 1
+			for ; ed < len(g); ed++ {
+				if g[ed] == '{' {
+					depth++
+				} else if g[ed] == '}' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			if ed >= len(g) {
+				b.WriteString(`\{`)
+				continue
+			}
+			alt, err := ecBraceRe(g[i+1 : ed])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(alt)
+			i = ed
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String(), nil
+}
+
+// ecBraceRe returns the regexp for the inside of a {} group of a glob:
+// alternatives or a numeric range
+func ecBraceRe(in []rune) (string, error) {
+	if m := ecRangeRe.FindStringSubmatch(string(in)); m != nil {
+		lo, _ := strconv.Atoi(m[1])
+		hi, _ := strconv.Atoi(m[2])
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if hi-lo > 10000 {
+			return "", fmt.Errorf("range too large: {%v}", string(in))
+		}
+		alts := make([]string, 0, hi-lo+1)
+		for n := lo; n <= hi; n++ {
+			alts = append(alts, strconv.Itoa(n))
+		}
+		return "(?:" + strings.Join(alts, "|") + ")", nil
+	}
+	var alts []string
+	depth, st := 0, 0
+	for i, r := range in {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alts = append(alts, string(in[st:i]))
+				st = i + 1
+			}
+		}
+	}
+	alts = append(alts, string(in[st:]))
+	if len(alts) == 1 {
+		re, err := ecGlobRe(in)
+		return `\{` + re + `\}`, err
+	}
+	res := make([]string, len(alts))
+	for i, a := range alts {
+		re, err := ecGlobRe([]rune(a))
+		if err != nil {
+			return "", err
+		}
+		res[i] = re
+	}
+	return "(?:" + strings.Join(res, "|") + ")", nil
+}
+
+// Match sets the properties of the sections of the file that match given
+// slash-separated path, relative to its directory, in given map
+func (ef *ECFile) Match(rel string, props map[string]string) {
+	for _, s := range ef.Sections {
+		re, err := ECGlobRegexp(s.Glob)
+		if err != nil || !re.MatchString(rel) {
+			continue
+		}
+		for k, v := range s.Props {
+			props[k] = v
+		}
+	}
+}
+
+// EditorConfigProps returns the EditorConfig properties of the file at given
+// path, from the EditorConfig files in its directory and up the tree, up to
+// one with root = true, the nearest ones overriding the others -- those set
+// to unset are left out
+func EditorConfigProps(fpath string) map[string]string {
+	fpath, _ = filepath.Abs(fpath)
+	type ecDir struct {
+		dir string
+		ef  *ECFile
+	}
+	var efs []ecDir
+	for dir := filepath.Dir(fpath); ; {
+		if b, err := ioutil.ReadFile(filepath.Join(dir, EditorConfigName)); err == nil {
+			ef := ParseEditorConfig(b)
+			efs = append(efs, ecDir{dir, ef})
+			if ef.Root {
+				break
+			}
+		}
+		up := filepath.Dir(dir)
+		if up == dir {
+			break
+		}
+		dir = up
+	}
+	props := map[string]string{}
+	for i := len(efs) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(efs[i].dir, fpath)
+		if err != nil {
+			continue
+		}
+		efs[i].ef.Match(filepath.ToSlash(rel), props)
+	}
+	for k, v := range props {
+		if strings.ToLower(v) == "unset" {
+			delete(props, k)
+		}
+	}
+	return props
+}
+
+// EditorConfig is the EditorConfig settings of a file that gide applies,
+// which override the preferences -- empty strings and zeros are not set
+type EditorConfig struct {
+	IndentStyle string `desc:"tab or space"`
+	IndentSize  int    `desc:"number of columns of an indent level"`
+	TabWidth    int    `desc:"number of columns of a tab"`
+	EndOfLine   string `desc:"lf, crlf or cr"`
+	Charset     string `desc:"latin1, utf-8, utf-8-bom, utf-16be or utf-16le"`
+	TrimTrail   string `desc:"true to remove trailing whitespace on save"`
+	FinalNL     string `desc:"true to end the file with a newline on save"`
+}
+
+// NewEditorConfig returns the settings in given EditorConfig properties
+func NewEditorConfig(props map[string]string) *EditorConfig {
+	ec := &EditorConfig{
+		IndentStyle: props["indent_style"],
+		EndOfLine:   props["end_of_line"],
+		Charset:     props["charset"],
+		TrimTrail:   props["trim_trailing_whitespace"],
+		FinalNL:     props["insert_final_newline"],
+	}
+	ec.TabWidth, _ = strconv.Atoi(props["tab_width"])
+	if is := props["indent_size"]; is == "tab" {
+		ec.IndentSize = ec.TabWidth
+	} else {
+		ec.IndentSize, _ = strconv.Atoi(is)
+	}
+	if ec.TabWidth == 0 && props["indent_size"] != "tab" {
+		ec.TabWidth = ec.IndentSize
+	}
+	return ec
+}
+
+// IsEmpty returns true if nothing is set
+func (ec *EditorConfig) IsEmpty() bool {
+	return *ec == EditorConfig{}
+}
+
+// LineEnds returns the line endings set by end_of_line, or false if they
+// are not set
+func (ec *EditorConfig) LineEnds() (LineEnds, bool) {
+	switch ec.EndOfLine {
+	case "lf":
+		return LineEndsLF, true
+	case "crlf":
+		return LineEndsCRLF, true
+	case "cr":
+		return LineEndsCR, true
+	}
+	return LineEndsLF, false
+}
+
+// ecCharsets are the names of the Encodings for the EditorConfig charsets
+var ecCharsets = map[string]string{
+	"utf-8":     EncodingUTF8,
+	"utf-8-bom": "UTF-8 BOM",
+	"latin1":    "Latin-1",
+	"utf-16be":  "UTF-16BE BOM",
+	"utf-16le":  "UTF-16LE BOM",
+}
+
+// Encoding returns the name of the encoding set by charset, or false if it
+// is not set
+func (ec *EditorConfig) Encoding() (string, bool) {
+	enc, ok := ecCharsets[ec.Charset]
+	return enc, ok
+}
+
+// TabSize returns the tab size of the buffer: the indent size for space
+// indentation, else the tab width -- 0 if neither is set
+func (ec *EditorConfig) TabSize(spaces bool) int {
+	if spaces && ec.IndentSize > 0 {
+		return ec.IndentSize
+	}
+	if ec.TabWidth > 0 {
+		return ec.TabWidth
+	}
+	return ec.IndentSize
+}
+
+// EditorConfigFor returns the EditorConfig settings of given buffer, read
+// when it was opened, or nil if there are none
+func (ge *Gide) EditorConfigFor(tb *giv.TextBuf) *EditorConfig {
+	return ge.edConfigs[tb]
+}
+
+// EditorConfigOpen reads the EditorConfig settings of given buffer, just
+// opened from its file, and applies them: reloads it in the encoding set by
+// charset, if it was detected as another one, and sets its indentation --
+// the line endings are applied by EditorConfigLineEnds, after the detected
+// ones
+func (ge *Gide) EditorConfigOpen(tb *giv.TextBuf) {
+	if ge.edConfigs == nil {
+		ge.edConfigs = make(map[*giv.TextBuf]*EditorConfig)
+	}
+	delete(ge.edConfigs, tb)
+	if tb.Filename == "" {
+		return
+	}
+	ec := NewEditorConfig(EditorConfigProps(string(tb.Filename)))
+	if ec.IsEmpty() {
+		return
+	}
+	ge.edConfigs[tb] = ec
+	if enc, ok := ec.Encoding(); ok && enc != ge.EncodingFor(tb) {
+		raw, err := ioutil.ReadFile(string(tb.Filename))
+		if err == nil {
+			if enc == EncodingUTF8 {
+				tb.SetText(raw)
+				ge.setEncoding(tb, enc)
+			} else if err := ge.encodingLoad(tb, raw, enc); err != nil {
+				ge.SetStatus(fmt.Sprintf("Could not read the file in %v, the charset set in %v: %v", enc, EditorConfigName, err))
+			}
+		}
+	}
+	ge.EditorConfigIndent(tb)
+}
+
+// EditorConfigLineEnds sets the line endings that given buffer is saved
+// with to those set by end_of_line in its EditorConfig settings, if any
+func (ge *Gide) EditorConfigLineEnds(tb *giv.TextBuf) {
+	ec := ge.EditorConfigFor(tb)
+	if ec == nil {
+		return
+	}
+	le, ok := ec.LineEnds()
+	if !ok {
+		return
+	}
+	if ge.lineEnds == nil {
+		ge.lineEnds = make(map[*giv.TextBuf]LineEnds)
+	}
+	if le == LineEndsLF {
+		delete(ge.lineEnds, tb)
+	} else {
+		ge.lineEnds[tb] = le
+	}
+}
+
+// EditorConfigIndent sets the indentation of given buffer from its
+// EditorConfig settings, if any, overriding the preferences -- called when
+// the buffer is configured
+func (ge *Gide) EditorConfigIndent(tb *giv.TextBuf) {
+	ec := ge.EditorConfigFor(tb)
+	if ec == nil {
+		return
+	}
+	switch ec.IndentStyle {
+	case "space":
+		tb.Opts.SpaceIndent = true
+	case "tab":
+		tb.Opts.SpaceIndent = false
+	}
+	if ts := ec.TabSize(tb.Opts.SpaceIndent); ts > 0 {
+		tb.Opts.TabSize = ts
+	}
+}
+
+// EditorConfigSave removes the trailing whitespace of the lines of given
+// buffer, and ends it with a newline, if trim_trailing_whitespace and
+// insert_final_newline are set in its EditorConfig settings -- called
+// before it is saved
+func (ge *Gide) EditorConfigSave(tb *giv.TextBuf) {
+	ec := ge.EditorConfigFor(tb)
+	if ec == nil {
+		return
+	}
+	if ec.TrimTrail == "true" {
+		for ln, l := range tb.Lines {
+			n := len(strings.TrimRightFunc(string(l), unicode.IsSpace))
+			n = len([]rune(string(l)[:n]))
+			if n < len(l) {
+				tb.DeleteText(giv.TextPos{Ln: ln, Ch: n}, giv.TextPos{Ln: ln, Ch: len(l)}, true, true)
+			}
+		}
+	}
+	if ec.FinalNL == "true" {
+		if nl := len(tb.Lines); nl > 0 && len(tb.Lines[nl-1]) > 0 {
+			tb.InsertText(giv.TextPos{Ln: nl - 1, Ch: len(tb.Lines[nl-1])}, []byte("\n"), true, true)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestECGlobRegexp(t *testing.T) {
+	cases := []struct {
+		glob, rel string
+		want      bool
+	}{
+		{"*", "a.go", true},
+		{"*", "sub/a.go", true},
+		{"*.py", "lib/x/y.py", true},
+		{"*.py", "y.pyc", false},
+		{"lib/*.js", "lib/a.js", true},
+		{"lib/*.js", "lib/sub/a.js", false},
+		{"/lib/*.js", "lib/a.js", true},
+		{"lib/**.js", "lib/sub/a.js", true},
+		{"*.{js,ts}", "a.ts", true},
+		{"*.{js,ts}", "a.go", false},
+		{"{package.json,.travis.yml}", "package.json", true},
+		{"file{1..3}.txt", "file2.txt", true},
+		{"file{1..3}.txt", "file4.txt", false},
+		{"*.[ch]", "a.h", true},
+		{"*.[!ch]", "a.h", false},
+		{"*.[!ch]", "a.o", true},
+		{"a?c", "abc", true},
+		{"a?c", "a/c", false},
+		{"Makefile", "sub/Makefile", true},
+		{"{a", "{a", true},
+	}
+	for _, c := range cases {
+		re, err := ECGlobRegexp(c.glob)
+		if err != nil {
+			t.Errorf("%q: %v", c.glob, err)
+			continue
+		}
+		if got := re.MatchString(c.rel); got != c.want {
+			t.Errorf("%q %q: got %v, want %v (%v)", c.glob, c.rel, got, c.want, re)
+		}
+	}
+}
+
+func TestParseEditorConfig(t *testing.T) {
+	ef := ParseEditorConfig([]byte(`# top
+root = TRUE
+
+[*]
+indent_style = Space
+indent_size = 4
+; comment
+
+[*.{c,h}]
+indent_style = tab
+my_prop = KeepCase
+`))
+	if !ef.Root || len(ef.Sections) != 2 {
+		t.Fatalf("got %+v", ef)
+	}
+	if ef.Sections[0].Props["indent_style"] != "space" || ef.Sections[1].Glob != "*.{c,h}" || ef.Sections[1].Props["my_prop"] != "KeepCase" {
+		t.Errorf("got %+v", ef.Sections)
+	}
+	props := map[string]string{}
+	ef.Match("src/a.c", props)
+	if props["indent_style"] != "tab" || props["indent_size"] != "4" {
+		t.Errorf("match: got %v", props)
+	}
+}
+
+func TestEditorConfigProps(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-ec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sub := filepath.Join(dir, "proj", "sub")
+	os.MkdirAll(sub, 0755)
+	ioutil.WriteFile(filepath.Join(dir, ".editorconfig"), []byte("[*]\ncharset = latin1\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "proj", ".editorconfig"), []byte("root = true\n[*]\nindent_style = space\nindent_size = 2\nend_of_line = crlf\n[sub/*.go]\nindent_style = tab\ntab_width = 8\n"), 0644)
+	ioutil.WriteFile(filepath.Join(sub, ".editorconfig"), []byte("[*.go]\nend_of_line = unset\ntrim_trailing_whitespace = true\n"), 0644)
+
+	props := EditorConfigProps(filepath.Join(sub, "a.go"))
+	want := map[string]string{"indent_style": "tab", "indent_size": "2", "tab_width": "8", "trim_trailing_whitespace": "true"}
+	if len(props) != len(want) {
+		t.Errorf("got %v, want %v", props, want)
+	}
+	for k, v := range want {
+		if props[k] != v {
+			t.Errorf("%v: got %q, want %q", k, props[k], v)
+		}
+	}
+	props = EditorConfigProps(filepath.Join(dir, "proj", "b.txt"))
+	if props["end_of_line"] != "crlf" || props["charset"] != "" {
+		t.Errorf("root: got %v", props)
+	}
+}
+
+func TestNewEditorConfig(t *testing.T) {
+	ec := NewEditorConfig(map[string]string{"indent_style": "space", "indent_size": "2", "end_of_line": "crlf", "charset": "utf-8-bom"})
+	if ec.TabSize(true) != 2 || ec.TabWidth != 2 {
+		t.Errorf("sizes: got %+v", ec)
+	}
+	if le, ok := ec.LineEnds(); !ok || le != LineEndsCRLF {
+		t.Errorf("line ends: got %v %v", le, ok)
+	}
+	if enc, ok := ec.Encoding(); !ok || enc != "UTF-8 BOM" {
+		t.Errorf("encoding: got %v %v", enc, ok)
+	}
+	ec = NewEditorConfig(map[string]string{"indent_style": "tab", "indent_size": "tab", "tab_width": "8"})
+	if ec.IndentSize != 8 || ec.TabSize(false) != 8 {
+		t.Errorf("tab: got %+v", ec)
+	}
+	if !NewEditorConfig(map[string]string{"other": "x"}).IsEmpty() {
+		t.Errorf("not empty for unknown properties")
+	}
+	if _, ok := NewEditorConfig(map[string]string{"end_of_line": "nl"}).LineEnds(); ok {
+		t.Errorf("bad end_of_line is set")
+	}
+}
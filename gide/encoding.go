@@ -0,0 +1,319 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// EncodingUTF8 is the name of the encoding that buffers are edited in, and
+// that most files are in
+const EncodingUTF8 = "UTF-8"
+
+// Encodings are the character encodings that files can be read and written
+// in, by name -- files in encodings other than UTF-8 are transcoded to UTF-8
+// when opened, and back when saved
+var Encodings = []struct {
+	Name string
+	Enc  encoding.Encoding
+}{
+	{EncodingUTF8, unicode.UTF8},
+	{"UTF-8 BOM", unicode.UTF8BOM},
+	{"UTF-16LE", unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+	{"UTF-16BE", unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+	{"UTF-16LE BOM", unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)},
+	{"UTF-16BE BOM", unicode.UTF16(unicode.BigEndian, unicode.UseBOM)},
+	{"Latin-1", charmap.ISO8859_1},
+	{"Windows-1252", charmap.Windows1252},
+	{"Shift-JIS", japanese.ShiftJIS},
+}
+
+// EncodingNames returns the names of the Encodings
+func EncodingNames() []string {
+	nms := make([]string, len(Encodings))
+	for i, en := range Encodings {
+		nms[i] = en.Name
+	}
+	return nms
+}
+
+// EncodingByName returns the encoding with given name, or nil if there is
+// none
+func EncodingByName(name string) encoding.Encoding {
+	for _, en := range Encodings {
+		if en.Name == name {
+			return en.Enc
+		}
+	}
+	return nil
+}
+
+// DetectEncoding returns the name of the encoding of given file contents:
+// from its byte order mark, if it has one, or else UTF-16 if every other
+// byte is mostly zero, UTF-8 if it is valid, Shift-JIS if it is valid and
+// has double-byte characters, and otherwise Windows-1252 if it has bytes in
+// the range that Latin-1 uses for control characters, or else Latin-1
+func DetectEncoding(b []byte) string {
+	switch {
+	case bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}):
+		return "UTF-8 BOM"
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+		return "UTF-16LE BOM"
+	case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+		return "UTF-16BE BOM"
+	}
+	if len(b) >= 4 && len(b)%2 == 0 {
+		var z [2]int
+		for i, c := range b {
+			if c == 0 {
+				z[i%2]++
+			}
+		}
+		half := len(b) / 2
+		switch {
+		case z[1] > half*3/4 && z[0] <= half/8:
+			return "UTF-16LE"
+		case z[0] > half*3/4 && z[1] <= half/8:
+			return "UTF-16BE"
+		}
+	}
+	if utf8.Valid(b) {
+		return EncodingUTF8
+	}
+	if isShiftJIS(b) {
+		return "Shift-JIS"
+	}
+	for _, c := range b {
+		if c >= 0x80 && c <= 0x9F {
+			return "Windows-1252"
+		}
+	}
+	return "Latin-1"
+}
+
+// isShiftJIS returns true if given text is valid Shift-JIS, with at least
+// one double-byte character
+func isShiftJIS(b []byte) bool {
+	ndbl := 0
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c < 0x80 || (c >= 0xA1 && c <= 0xDF): // ascii, half-width katakana
+		case (c >= 0x81 && c <= 0x9F) || (c >= 0xE0 && c <= 0xFC):
+			if i+1 >= len(b) {
+				return false
+			}
+			t := b[i+1]
+			if t < 0x40 || t == 0x7F || t > 0xFC {
+				return false
+			}
+			ndbl++
+			i++
+		default:
+			return false
+		}
+	}
+	return ndbl > 0
+}
+
+// DecodeText returns given text in the encoding with given name transcoded
+// to UTF-8
+func DecodeText(b []byte, name string) ([]byte, error) {
+	enc := EncodingByName(name)
+	if enc == nil {
+		return nil, fmt.Errorf("gide.DecodeText: unknown encoding: %v", name)
+	}
+	return enc.NewDecoder().Bytes(b)
+}
+
+// EncodeText returns given UTF-8 text transcoded to the encoding with given
+// name -- it is an error if some of the characters can not be represented
+// in it
+func EncodeText(b []byte, name string) ([]byte, error) {
+	enc := EncodingByName(name)
+	if enc == nil {
+		return nil, fmt.Errorf("gide.EncodeText: unknown encoding: %v", name)
+	}
+	return enc.NewEncoder().Bytes(b)
+}
+
+// EncodingFor returns the name of the encoding that given buffer is saved in
+func (ge *Gide) EncodingFor(tb *giv.TextBuf) string {
+	if enc, ok := ge.encodings[tb]; ok {
+		return enc
+	}
+	return EncodingUTF8
+}
+
+// setEncoding records the encoding that given buffer is saved in
+func (ge *Gide) setEncoding(tb *giv.TextBuf, enc string) {
+	if ge.encodings == nil {
+		ge.encodings = make(map[*giv.TextBuf]string)
+	}
+	if enc == EncodingUTF8 {
+		delete(ge.encodings, tb)
+	} else {
+		ge.encodings[tb] = enc
+	}
+}
+
+// EncodingOpen detects the encoding of the file of given buffer, just opened
+// from it, and if it is not UTF-8, reloads the buffer with the file
+// transcoded to UTF-8, keeping the encoding to save it in
+func (ge *Gide) EncodingOpen(tb *giv.TextBuf) {
+	ge.setEncoding(tb, EncodingUTF8)
+	if tb.Filename == "" {
+		return
+	}
+	raw, err := ioutil.ReadFile(string(tb.Filename))
+	if err != nil {
+		return
+	}
+	ge.encodingLoad(tb, raw, DetectEncoding(raw))
+}
+
+// encodingLoad sets the text of given buffer to raw file contents in given
+// encoding, transcoded to UTF-8
+func (ge *Gide) encodingLoad(tb *giv.TextBuf, raw []byte, enc string) error {
+	if enc == EncodingUTF8 {
+		ge.setEncoding(tb, enc)
+		return nil
+	}
+	txt, err := DecodeText(raw, enc)
+	if err != nil {
+		return err
+	}
+	tb.SetText(txt)
+	ge.setEncoding(tb, enc)
+	return nil
+}
+
+// EncodingSaved rewrites the file of given buffer, just saved in UTF-8, in
+// its encoding, if it is another one
+func (ge *Gide) EncodingSaved(tb *giv.TextBuf) error {
+	enc := ge.EncodingFor(tb)
+	if enc == EncodingUTF8 || tb.Filename == "" {
+		return nil
+	}
+	fpath := string(tb.Filename)
+	st, err := os.Stat(fpath)
+	if err != nil {
+		return err
+	}
+	txt, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return err
+	}
+	out, err := EncodeText(txt, enc)
+	if err != nil {
+		return fmt.Errorf("file left in UTF-8, as it can not be saved in %v: %v", enc, err)
+	}
+	return ioutil.WriteFile(fpath, out, st.Mode())
+}
+
+// BufOpened sets up given buffer, just opened or reverted from its file:
+// transcodes it to UTF-8 and converts its line endings to LF, to save it
+// back with the ones it has, or those of its EditorConfig settings, sets its
+// indentation by them, records the text for merging changes made on disk,
+// loads its undo history, and notes its size for the audit log -- called
+// after every open of a file buffer
+func (ge *Gide) BufOpened(tb *giv.TextBuf) {
+	ge.EncodingOpen(tb)
+	ge.EditorConfigOpen(tb)
+	ge.LineEndsOpen(tb)
+	ge.EditorConfigLineEnds(tb)
+	ge.WatchOpened(tb)
+	ge.UndoHistOpen(tb)
+	ge.AuditOpen(tb)
+}
+
+// BufSaved converts the file of given buffer, just saved, to its line
+// endings and encoding, if they are not LF and UTF-8, stores its undo
+// history, records the write in the audit log and the text for merging
+// changes made on disk, removes its recovery file, and updates the Todo
+// panel, the symbol and project indexes and the language server for it --
+// called after every save of a file buffer
+func (ge *Gide) BufSaved(tb *giv.TextBuf) error {
+	return ge.bufSaved(tb, true)
+}
+
+// bufSaved is BufSaved, only converting the file if convert is true, i.e.,
+// if it was saved in UTF-8 with LF line endings
+func (ge *Gide) bufSaved(tb *giv.TextBuf, convert bool) error {
+	ge.UndoHistSaved(tb)
+	var err error
+	if convert {
+		err = ge.LineEndsSaved(tb)
+		if err == nil {
+			err = ge.EncodingSaved(tb)
+		}
+	}
+	ge.AuditSaved(tb)
+	ge.WatchSaved(tb)
+	ge.RecoveryRemove(tb)
+	ge.TodoRescanBuf(tb)
+	ge.SymIndexFile(string(tb.Filename))
+	ge.ProjIndexFile(string(tb.Filename))
+	ge.LspDidSave(tb)
+	ge.BufTabsUpdate()
+	return err
+}
+
+// GideEncodings gets the names of the encodings for submenu-func
+func GideEncodings(it interface{}, vp *gi.Viewport2D) []string {
+	return EncodingNames()
+}
+
+// ReopenWithEncoding reloads the file in the active view from disk, reading
+// it in given encoding, e.g., when it was not detected correctly -- any
+// unsaved changes are lost
+func (ge *Gide) ReopenWithEncoding(enc string) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf.Filename == "" {
+		return
+	}
+	tb := tv.Buf
+	raw, err := ioutil.ReadFile(string(tb.Filename))
+	if err != nil {
+		ge.SetStatus(err.Error())
+		return
+	}
+	if enc == EncodingUTF8 {
+		tb.SetText(raw)
+	}
+	if err := ge.encodingLoad(tb, raw, enc); err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not read file as %v: %v", enc, err))
+		return
+	}
+	ge.LineEndsOpen(tb)
+	ge.UndoHistOpen(tb)
+	ge.SetStatus(fmt.Sprintf("Reopened as %v", enc))
+}
+
+// SaveWithEncoding sets the encoding that the file in the active view is
+// saved in, and saves it
+func (ge *Gide) SaveWithEncoding(enc string) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || EncodingByName(enc) == nil {
+		return
+	}
+	if _, err := EncodeText(tv.Buf.LinesToBytesCopy(), enc); err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Can Not Save With Encoding", Prompt: fmt.Sprintf("The file has characters that can not be represented in %v: %v", enc, err)}, true, false, nil, nil)
+		return
+	}
+	ge.setEncoding(tv.Buf, enc)
+	ge.SaveActiveView()
+}
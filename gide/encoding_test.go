@@ -0,0 +1,57 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		want string
+	}{
+		{[]byte("plain ascii\n"), "UTF-8"},
+		{[]byte("café\n"), "UTF-8"},
+		{[]byte("\xef\xbb\xbfbom\n"), "UTF-8 BOM"},
+		{[]byte("\xff\xfeh\x00i\x00"), "UTF-16LE BOM"},
+		{[]byte("\xfe\xff\x00h\x00i"), "UTF-16BE BOM"},
+		{[]byte("h\x00e\x00l\x00l\x00o\x00"), "UTF-16LE"},
+		{[]byte("\x00h\x00e\x00l\x00l\x00o"), "UTF-16BE"},
+		{[]byte("\x93\xfa\x96\x7b\x8c\xea\n"), "Shift-JIS"}, // nihongo
+		{[]byte("caf\xe9 au lait\n"), "Latin-1"},
+		{[]byte("\x93quoted\x94 caf\xe9\n"), "Windows-1252"},
+	}
+	for _, tt := range tests {
+		if got := DetectEncoding(tt.b); got != tt.want {
+			t.Errorf("DetectEncoding(%q) = %v, want %v", tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeDecodeText(t *testing.T) {
+	tests := []struct {
+		enc      string
+		utf, raw string
+	}{
+		{"Latin-1", "café", "caf\xe9"},
+		{"Shift-JIS", "日本", "\x93\xfa\x96\x7b"},
+		{"UTF-16LE BOM", "hi", "\xff\xfeh\x00i\x00"},
+	}
+	for _, tt := range tests {
+		raw, err := EncodeText([]byte(tt.utf), tt.enc)
+		if err != nil || !bytes.Equal(raw, []byte(tt.raw)) {
+			t.Errorf("EncodeText(%q, %v) = %q, %v, want %q", tt.utf, tt.enc, raw, err, tt.raw)
+		}
+		utf, err := DecodeText([]byte(tt.raw), tt.enc)
+		if err != nil || string(utf) != tt.utf {
+			t.Errorf("DecodeText(%q, %v) = %q, %v, want %q", tt.raw, tt.enc, utf, err, tt.utf)
+		}
+	}
+	if _, err := EncodeText([]byte("日"), "Latin-1"); err == nil {
+		t.Errorf("EncodeText of a character not in Latin-1 did not fail")
+	}
+}
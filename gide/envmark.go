@@ -0,0 +1,157 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"html"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+)
+
+// EnvMark is a rule that marks the files matching a path pattern, e.g., the
+// config files of a production environment, by tinting the background of
+// the editor and showing a banner above it -- a guard-rail against editing
+// the wrong one
+type EnvMark struct {
+	Pattern string `desc:"slash-separated path pattern of the files that are marked, where * matches within a directory and ** across directories, e.g., */prod/*.yaml or **/secrets/** -- patterns that do not start with / match the end of the path"`
+	Color   string `desc:"background color of the editor for the files -- standard color name or #RRGGBB hex value, e.g., #3a1c1c for a dark red tint -- empty for no tint"`
+	Banner  string `desc:"text of the banner shown above the editor for the files, e.g., PRODUCTION -- empty for no banner"`
+}
+
+// EnvMarks are the rules for marking files -- the first one that matches a
+// file applies
+type EnvMarks []EnvMark
+
+// EnvPathMatch returns true if given slash-separated path matches given
+// pattern, as in EnvMark.Pattern
+func EnvPathMatch(pat, fpath string) bool {
+	pat = strings.TrimSpace(pat)
+	if pat == "" {
+		return false
+	}
+	ps := strings.Split(strings.Trim(pat, "/"), "/")
+	fs := strings.Split(strings.Trim(fpath, "/"), "/")
+	if !strings.HasPrefix(pat, "/") {
+		ps = append([]string{"**"}, ps...)
+	}
+	return envMatchSegs(ps, fs)
+}
+
+// envMatchSegs matches path segments against pattern segments, ** matching
+// any number of segments
+func envMatchSegs(ps, fs []string) bool {
+	for len(ps) > 0 {
+		if ps[0] == "**" {
+			for i := 0; i <= len(fs); i++ {
+				if envMatchSegs(ps[1:], fs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(fs) == 0 {
+			return false
+		}
+		if ok, err := path.Match(ps[0], fs[0]); err != nil || !ok {
+			return false
+		}
+		ps, fs = ps[1:], fs[1:]
+	}
+	return len(fs) == 0
+}
+
+// Match returns the first rule that matches given file path, or false if
+// none does
+func (em EnvMarks) Match(fpath string) (EnvMark, bool) {
+	sp := filepath.ToSlash(fpath)
+	for _, m := range em {
+		if EnvPathMatch(m.Pattern, sp) {
+			return m, true
+		}
+	}
+	return EnvMark{}, false
+}
+
+// EnvMarkFor returns the rule of the EnvMarks in the Preferences that
+// applies to given buffer, or false if none does
+func EnvMarkFor(tb *giv.TextBuf) (EnvMark, bool) {
+	if tb == nil || tb.Filename == "" {
+		return EnvMark{}, false
+	}
+	return Prefs.EnvMarks.Match(string(tb.Filename))
+}
+
+// EnvMarkView tints the background of given view if the file it shows is
+// marked by the EnvMarks in the Preferences, and removes the tint otherwise
+// -- called when the view shows another buffer, and when the prefs change
+func (ge *Gide) EnvMarkView(tv *giv.TextView) {
+	if tv == nil {
+		return
+	}
+	em, _ := EnvMarkFor(tv.Buf)
+	cur, _ := tv.Prop("background-color").(string)
+	if cur == em.Color {
+		return
+	}
+	updt := tv.UpdateStart()
+	tv.SetFullReRender()
+	if em.Color != "" {
+		tv.SetProp("background-color", em.Color)
+	} else {
+		tv.DeleteProp("background-color")
+	}
+	tv.UpdateEnd(updt)
+}
+
+// EnvBanner returns the banner above the text views, or nil if it is not
+// shown
+func (ge *Gide) EnvBanner() *gi.Label {
+	lbi, ok := ge.ChildByName("envbanner", 1)
+	if !ok {
+		return nil
+	}
+	return lbi.(*gi.Label)
+}
+
+// EnvMarkActive shows the banner of the rule of the EnvMarks that applies
+// to the file in the active view, if any, above the text views, in its
+// color, and removes it otherwise, and tints all of the views by their files
+// -- called when the active view changes
+func (ge *Gide) EnvMarkActive() {
+	for _, tv := range ge.PaneViews {
+		ge.EnvMarkView(tv)
+	}
+	var em EnvMark
+	if tv := ge.ActiveTextView(); tv != nil {
+		em, _ = EnvMarkFor(tv.Buf)
+	}
+	if (em.Banner != "") != (ge.envBanner != "") {
+		ge.envBanner = em.Banner
+		mods, updt := ge.StdConfig()
+		if mods {
+			ge.UpdateEnd(updt)
+		}
+	}
+	ge.envBanner = em.Banner
+	lb := ge.EnvBanner()
+	if lb == nil {
+		return
+	}
+	lb.SetStretchMaxWidth()
+	lb.SetProp("text-align", gi.AlignCenter)
+	lb.SetProp("font-weight", "bold")
+	lb.SetProp("padding", units.NewValue(.3, units.Em))
+	if em.Color != "" {
+		lb.SetProp("background-color", em.Color)
+	} else {
+		lb.SetProp("background-color", "highlight")
+	}
+	lb.SetText(html.EscapeString(em.Banner))
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import "testing"
+
+func TestEnvPathMatch(t *testing.T) {
+	cases := []struct {
+		pat, path string
+		want      bool
+	}{
+		{"*/prod/*.yaml", "/home/me/deploy/prod/app.yaml", true},
+		{"*/prod/*.yaml", "/home/me/deploy/staging/app.yaml", false},
+		{"*/prod/*.yaml", "/home/me/deploy/prod/sub/app.yaml", false},
+		{"prod/**", "/srv/cfg/prod/sub/app.yaml", true},
+		{"**/secrets/**", "/a/secrets/b/c.env", true},
+		{"/etc/*.conf", "/etc/nginx.conf", true},
+		{"/etc/*.conf", "/home/etc/nginx.conf", false},
+		{"*.prod.env", "/proj/.config/app.prod.env", true},
+		{"", "/any", false},
+	}
+	for _, c := range cases {
+		if got := EnvPathMatch(c.pat, c.path); got != c.want {
+			t.Errorf("%q %q: got %v, want %v", c.pat, c.path, got, c.want)
+		}
+	}
+}
+
+func TestEnvMarksMatch(t *testing.T) {
+	em := EnvMarks{
+		{Pattern: "*/prod/*.yaml", Color: "#3a1c1c", Banner: "PRODUCTION"},
+		{Pattern: "*/staging/*", Banner: "staging"},
+		{Pattern: "**/*.yaml", Color: "#202020"},
+	}
+	if m, ok := em.Match("/x/prod/a.yaml"); !ok || m.Banner != "PRODUCTION" {
+		t.Errorf("prod: got %v %v", m, ok)
+	}
+	if m, ok := em.Match("/x/staging/a.yaml"); !ok || m.Banner != "staging" {
+		t.Errorf("staging: got %v %v", m, ok)
+	}
+	if m, ok := em.Match("/x/dev/a.yaml"); !ok || m.Color != "#202020" {
+		t.Errorf("dev: got %v %v", m, ok)
+	}
+	if _, ok := em.Match("/x/dev/a.go"); ok {
+		t.Errorf("go file matched")
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// CmdError is one error parsed from command output, e.g., from the compiler
+// -- Line and Col are 1-based, and Col is 0 if not given
+type CmdError struct {
+	Path string `desc:"full path to the file"`
+	Line int    `desc:"line of the error"`
+	Col  int    `desc:"column of the error, 0 if not given"`
+	Msg  string `desc:"the error message"`
+}
+
+// cmdErrRe matches file:line[:col]: message lines, as output by go build,
+// go vet, go test and most compilers
+var cmdErrRe = regexp.MustCompile(`^\s*([^\s:]+):(\d+)(?::(\d+))?:\s*(.*)$`)
+
+// ParseCmdErrors returns the errors in given command output, with relative
+// file paths taken relative to given directory where the command was run
+func ParseCmdErrors(out []byte, dir string) []CmdError {
+	var errs []CmdError
+	for _, l := range bytes.Split(out, []byte("\n")) {
+		m := cmdErrRe.FindSubmatch(l)
+		if m == nil {
+			continue
+		}
+		fn := string(m[1])
+		if !strings.ContainsAny(fn, "./") { // extension or path
+			continue
+		}
+		if !filepath.IsAbs(fn) {
+			fn = filepath.Join(dir, fn)
+		}
+		ln, _ := strconv.Atoi(string(m[2]))
+		col, _ := strconv.Atoi(string(m[3]))
+		errs = append(errs, CmdError{Path: fn, Line: ln, Col: col, Msg: string(m[4])})
+	}
+	return errs
+}
+
+// SetCmdErrors sets the list of errors from the output of the given failed
+// command, for NextError / PrevError -- if the command is one of the build
+// commands and the BuildJumpErr project pref is on, jumps to the first error
+func (ge *Gide) SetCmdErrors(cm *Command, buf *giv.TextBuf) {
+	dir, _ := os.Getwd() // commands are run in their dir
+	out := buf.LinesToBytesCopy()
+	ge.CmdErrs = CgoFixErrors(ParseCmdErrors(out, dir), out, dir)
+	ge.CmdErrIdx = -1
+	if len(ge.CmdErrs) == 0 || !ge.Prefs.BuildJumpErr {
+		return
+	}
+	for _, bc := range ge.Prefs.BuildCmds {
+		if string(bc) == cm.Name {
+			ge.NextError()
+			return
+		}
+	}
+}
+
+// GotoError shows the error at given index in the list of errors from the
+// last failed command
+func (ge *Gide) GotoError(idx int) bool {
+	ce := ge.CmdErrs[idx]
+	ge.CmdErrIdx = idx
+	tv, _, ok := ge.LinkViewFile(gi.FileName(ce.Path))
+	if !ok {
+		ge.SetStatus(fmt.Sprintf("Error %d of %d: could not open %v", idx+1, len(ge.CmdErrs), ce.Path))
+		return false
+	}
+	pos := giv.TextPos{Ln: ce.Line - 1}
+	if ce.Col > 0 {
+		pos.Ch = ce.Col - 1
+	}
+	if pos.Ln < len(tv.Buf.Lines) {
+		tv.HighlightRegion(giv.TextRegion{Start: giv.TextPos{Ln: pos.Ln}, End: giv.TextPos{Ln: pos.Ln, Ch: len(tv.Buf.Lines[pos.Ln])}})
+	}
+	tv.SetCursorShow(pos)
+	ge.SetStatus(fmt.Sprintf("Error %d of %d: %v", idx+1, len(ge.CmdErrs), ce.Msg))
+	return true
+}
+
+// NextError goes to the next error in the output of the last failed command
+// (e.g., a build), in whatever file it is in
+func (ge *Gide) NextError() {
+	if ge.CmdErrIdx+1 >= len(ge.CmdErrs) {
+		ge.SetStatus("No more errors")
+		return
+	}
+	ge.GotoError(ge.CmdErrIdx + 1)
+}
+
+// PrevError goes to the previous error in the output of the last failed
+// command (e.g., a build), in whatever file it is in
+func (ge *Gide) PrevError() {
+	if ge.CmdErrIdx <= 0 {
+		ge.SetStatus("No previous errors")
+		return
+	}
+	ge.GotoError(ge.CmdErrIdx - 1)
+}
@@ -142,6 +142,16 @@ func (on *OpenNodes) ByStringName(name string) *giv.FileNode {
 	return nil
 }
 
+// ByBuf returns the open node with given buffer
+func (on *OpenNodes) ByBuf(tb *giv.TextBuf) *giv.FileNode {
+	for _, fn := range *on {
+		if fn.Buf == tb {
+			return fn
+		}
+	}
+	return nil
+}
+
 // NChanged returns number of changed open files
 func (on *OpenNodes) NChanged() int {
 	cnt := 0
@@ -263,6 +273,62 @@ func (ft *FileTreeView) ViewFiles() {
 	}
 }
 
+// DiffVCSFiles shows the changes since the last commit in the selected
+// files, side by side -- the last one selected is shown
+func (ft *FileTreeView) DiffVCSFiles() {
+	gek, ok := ft.ParentByType(KiT_Gide, true)
+	if !ok {
+		return
+	}
+	ge := gek.Embed(KiT_Gide).(*Gide)
+	sels := ft.SelectedViews()
+	for i := len(sels) - 1; i >= 0; i-- {
+		ftv := sels[i].Embed(KiT_FileTreeView).(*FileTreeView)
+		fn := ftv.FileNode()
+		if fn != nil && !fn.IsDir() {
+			ge.DiffFileVCS(fn.This().Embed(giv.KiT_FileNode).(*giv.FileNode))
+		}
+	}
+}
+
+// FindInDir opens the find dialog, to find in the files of this folder, and
+// those of the folders within it
+func (ft *FileTreeView) FindInDir() {
+	gek, ok := ft.ParentByType(KiT_Gide, true)
+	if !ok {
+		return
+	}
+	ge := gek.Embed(KiT_Gide).(*Gide)
+	fn := ft.FileNode()
+	if fn == nil || !fn.IsDir() {
+		return
+	}
+	ge.Prefs.Find.Dir = string(fn.FPath)
+	ge.Prefs.Find.Loc = FindLocSubdir
+	giv.CallMethod(ge, "Find", ge.Viewport)
+}
+
+// DiffSelFiles shows the differences between the two selected files side by
+// side
+func (ft *FileTreeView) DiffSelFiles() {
+	gek, ok := ft.ParentByType(KiT_Gide, true)
+	if !ok {
+		return
+	}
+	ge := gek.Embed(KiT_Gide).(*Gide)
+	sels := ft.SelectedViews()
+	if len(sels) != 2 {
+		ge.SetStatus("Select two files to compare")
+		return
+	}
+	fn1 := sels[0].Embed(KiT_FileTreeView).(*FileTreeView).FileNode()
+	fn2 := sels[1].Embed(KiT_FileTreeView).(*FileTreeView).FileNode()
+	if fn1 == nil || fn2 == nil || fn1.IsDir() || fn2.IsDir() {
+		return
+	}
+	ge.DiffFileNode(fn1.FPath, fn2.This().Embed(giv.KiT_FileNode).(*giv.FileNode))
+}
+
 // FileTreeViewExecCmds gets list of available commands for given file node, as a submenu-func
 func FileTreeViewExecCmds(it interface{}, vp *gi.Viewport2D) []string {
 	ft, ok := it.(ki.Ki).Embed(KiT_FileTreeView).(*FileTreeView)
@@ -381,6 +447,16 @@ var FileTreeViewProps = ki.Props{
 				{"Cmd Name", ki.Props{}},
 			},
 		}},
+		{"DiffVCSFiles", ki.Props{
+			"label":    "Diff With Last Commit",
+			"desc":     "show the changes since the last commit to version control, side by side",
+			"updtfunc": FileTreeInactiveDirFunc,
+		}},
+		{"DiffSelFiles", ki.Props{
+			"label":    "Diff Selected",
+			"desc":     "show the differences between the two selected files, side by side",
+			"updtfunc": FileTreeInactiveDirFunc,
+		}},
 		{"DuplicateFiles", ki.Props{
 			"label":    "Duplicate",
 			"updtfunc": FileTreeInactiveDirFunc,
@@ -421,5 +497,10 @@ var FileTreeViewProps = ki.Props{
 				}},
 			},
 		}},
+		{"FindInDir", ki.Props{
+			"label":    "Find In Folder...",
+			"desc":     "find in the files of this folder, and those of the folders within it",
+			"updtfunc": FileTreeActiveDirFunc,
+		}},
 	},
 }
@@ -0,0 +1,297 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+)
+
+// FindQueryHistMax is the most finds that are kept in the history of a
+// project, with their options
+var FindQueryHistMax = 50
+
+// FindQuery is a find / replace with all of its options, as kept in the
+// history of finds of a project, and its saved finds
+type FindQuery struct {
+	Name         string    `desc:"name of a saved find -- empty in the history"`
+	Find         string    `desc:"find string"`
+	Replace      string    `desc:"replace string"`
+	IgnoreCase   bool      `desc:"ignore case"`
+	Regexp       bool      `desc:"find string is a regular expression"`
+	WholeWord    bool      `desc:"only find whole words"`
+	MultiLine    bool      `desc:"regular expression matches can span lines"`
+	Structural   bool      `desc:"find string is a Go pattern, matched syntactically"`
+	PreserveCase bool      `desc:"replace keeping the case of what is replaced"`
+	Loc          FindLoc   `desc:"locations to search in"`
+	Dir          string    `desc:"directory searched, for Loc subdir"`
+	Globs        string    `desc:"globs of the files searched"`
+	Langs        LangNames `desc:"languages of the files searched"`
+}
+
+// Label returns the label of the query in menus: its name, if it has one,
+// then its find string and replace string, and its options
+func (fq *FindQuery) Label() string {
+	var sb strings.Builder
+	if fq.Name != "" {
+		sb.WriteString(fq.Name + ": ")
+	}
+	sb.WriteString(fmt.Sprintf("%q", fq.Find))
+	if fq.Replace != "" {
+		sb.WriteString(fmt.Sprintf(" -> %q", fq.Replace))
+	}
+	var opts []string
+	if fq.IgnoreCase {
+		opts = append(opts, "ignore case")
+	}
+	if fq.Regexp {
+		opts = append(opts, "regexp")
+	}
+	if fq.WholeWord {
+		opts = append(opts, "whole word")
+	}
+	if fq.MultiLine {
+		opts = append(opts, "multi-line")
+	}
+	if fq.Structural {
+		opts = append(opts, "structural")
+	}
+	if fq.PreserveCase {
+		opts = append(opts, "preserve case")
+	}
+	if fq.Loc != FindLocAll {
+		opts = append(opts, strings.ToLower(strings.TrimPrefix(fq.Loc.String(), "FindLoc")))
+	}
+	if fq.Globs != "" {
+		opts = append(opts, fq.Globs)
+	}
+	if len(fq.Langs) > 0 {
+		opts = append(opts, fmt.Sprintf("%v", fq.Langs))
+	}
+	if len(opts) > 0 {
+		sb.WriteString(" (" + strings.Join(opts, ", ") + ")")
+	}
+	return sb.String()
+}
+
+// Same returns true if the query is the same as the other, other than its
+// name
+func (fq *FindQuery) Same(oq *FindQuery) bool {
+	a, b := *fq, *oq
+	a.Name, b.Name = "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+// Query returns the current find and its options as a query
+func (fp *FindParams) Query() FindQuery {
+	return FindQuery{Find: fp.Find, Replace: fp.Replace, IgnoreCase: fp.IgnoreCase, Regexp: fp.Regexp, WholeWord: fp.WholeWord, MultiLine: fp.MultiLine, Structural: fp.Structural, PreserveCase: fp.PreserveCase, Loc: fp.Loc, Dir: fp.Dir, Globs: fp.Globs, Langs: append(LangNames(nil), fp.Langs...)}
+}
+
+// SetQuery sets the current find and its options from a query, and adds
+// its strings to their histories
+func (fp *FindParams) SetQuery(fq *FindQuery) {
+	fp.Find, fp.Replace = fq.Find, fq.Replace
+	fp.IgnoreCase, fp.Regexp, fp.PreserveCase = fq.IgnoreCase, fq.Regexp, fq.PreserveCase
+	fp.WholeWord, fp.MultiLine, fp.Structural = fq.WholeWord, fq.MultiLine, fq.Structural
+	fp.Loc, fp.Dir, fp.Globs = fq.Loc, fq.Dir, fq.Globs
+	fp.Langs = append(LangNames(nil), fq.Langs...)
+	gi.StringsInsertFirstUnique(&fp.FindHist, fp.Find, gi.Prefs.SavedPathsMax)
+	if fp.Replace != "" {
+		gi.StringsInsertFirstUnique(&fp.ReplHist, fp.Replace, gi.Prefs.SavedPathsMax)
+	}
+}
+
+// AddHist adds the current find and its options to the front of the
+// history, removing the same one from further back, and keeping only the
+// last FindQueryHistMax
+func (fp *FindParams) AddHist() {
+	fq := fp.Query()
+	hs := []FindQuery{fq}
+	for i := range fp.Hist {
+		if !fp.Hist[i].Same(&fq) && len(hs) < FindQueryHistMax {
+			hs = append(hs, fp.Hist[i])
+		}
+	}
+	fp.Hist = hs
+}
+
+// SaveQuery saves the current find and its options with given name,
+// replacing any saved with the same name
+func (fp *FindParams) SaveQuery(name string) {
+	fq := fp.Query()
+	fq.Name = name
+	for i := range fp.Saved {
+		if fp.Saved[i].Name == name {
+			fp.Saved[i] = fq
+			return
+		}
+	}
+	fp.Saved = append(fp.Saved, fq)
+}
+
+// DeleteSaved deletes the saved find of given name -- returns false if
+// there is none
+func (fp *FindParams) DeleteSaved(name string) bool {
+	for i := range fp.Saved {
+		if fp.Saved[i].Name == name {
+			fp.Saved = append(fp.Saved[:i], fp.Saved[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SavedNames returns the names of the saved finds
+func (fp *FindParams) SavedNames() []string {
+	nms := make([]string, len(fp.Saved))
+	for i := range fp.Saved {
+		nms[i] = fp.Saved[i].Name
+	}
+	return nms
+}
+
+// HistStep steps through the history of finds, as with the up and down
+// arrows in the find field: from position pos, where 0 is the find being
+// typed and n is Hist[n-1], to the one that is older by dir, which is 1
+// going back and -1 going forward -- returns the new position, which stays
+// within the history
+func (fp *FindParams) HistStep(pos, dir int) int {
+	pos += dir
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(fp.Hist) {
+		pos = len(fp.Hist)
+	}
+	return pos
+}
+
+// HistKey goes back (up) or forward (down) in the history of finds, from
+// the find field, setting the find and its options to those of the one it
+// goes to -- going forward past the newest goes back to the find that was
+// being typed
+func (fv *FindView) HistKey(up bool) {
+	fp := fv.Params()
+	if len(fp.Hist) == 0 {
+		return
+	}
+	if fv.HistPos == 0 {
+		fv.HistEdit = fp.Query()
+		if ft := fv.FindText(); ft != nil {
+			if tf, ok := ft.TextField(); ok {
+				fv.HistEdit.Find = tf.Text()
+			}
+		}
+	}
+	dir := 1
+	if !up {
+		dir = -1
+	}
+	pos := fp.HistStep(fv.HistPos, dir)
+	if pos == fv.HistPos {
+		return
+	}
+	fv.HistPos = pos
+	if pos == 0 {
+		fp.SetQuery(&fv.HistEdit)
+	} else {
+		fp.SetQuery(&fp.Hist[pos-1])
+	}
+	fv.UpdateView(fv.Gide)
+	fv.Gide.SetStatus(fmt.Sprintf("Find history %d of %d", pos, len(fp.Hist)))
+}
+
+// SavedMenu pops up the menu of the saved finds of the project, to run one
+// of them, save the current find, or delete one
+func (fv *FindView) SavedMenu() {
+	fp := fv.Params()
+	var m gi.Menu
+	for i := range fp.Saved {
+		fq := fp.Saved[i]
+		m.AddAction(gi.ActOpts{Label: fq.Label()}, fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			fvv.RunQuery(&fq)
+		})
+	}
+	if len(fp.Saved) > 0 {
+		m.AddSeparator("sep-saved")
+	}
+	m.AddAction(gi.ActOpts{Label: "Save Current Find..."}, fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+		fvv.SaveQueryDialog()
+	})
+	if len(fp.Saved) > 0 {
+		m.AddAction(gi.ActOpts{Label: "Delete Saved Find..."}, fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			fvv.DeleteSavedDialog()
+		})
+	}
+	var x, y int
+	if tb := fv.FindBar(); tb != nil {
+		if sa, ok := tb.ChildByName("saved", 10); ok {
+			bb := sa.(gi.Node2D).AsWidget().WinBBox
+			x, y = bb.Min.X, bb.Max.Y
+		}
+	}
+	gi.PopupMenu(m, x, y, fv.Viewport, "find-saved-menu")
+}
+
+// RunQuery sets the find and its options from given query, and finds it
+func (fv *FindView) RunQuery(fq *FindQuery) {
+	fv.Params().SetQuery(fq)
+	fv.HistPos = 0
+	fv.UpdateView(fv.Gide)
+	fv.FindAction()
+}
+
+// SaveQueryDialog prompts for a name to save the current find, and its
+// options, under in the project
+func (fv *FindView) SaveQueryDialog() {
+	fp := fv.Params()
+	if fp.Find == "" {
+		fv.Gide.SetStatus("Nothing to save -- enter something to find first")
+		return
+	}
+	gi.StringPromptDialog(fv.Gide.Viewport, fp.Find, "name",
+		gi.DlgOpts{Title: "Save Find", Prompt: "Name to save the current find under, with its options, in the project -- one with the same name is replaced"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dlg := send.(*gi.Dialog)
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			nm := strings.TrimSpace(gi.StringPromptDialogValue(dlg))
+			if nm == "" {
+				return
+			}
+			fvv.Params().SaveQuery(nm)
+			fvv.Gide.SetStatus(fmt.Sprintf("Saved find: %v", nm))
+		})
+}
+
+// DeleteSavedDialog prompts for the name of a saved find to delete
+func (fv *FindView) DeleteSavedDialog() {
+	nms := fv.Params().SavedNames()
+	if len(nms) == 0 {
+		return
+	}
+	gi.StringPromptDialog(fv.Gide.Viewport, nms[0], "name",
+		gi.DlgOpts{Title: "Delete Saved Find", Prompt: fmt.Sprintf("Name of the saved find to delete, one of: %v", strings.Join(nms, ", "))},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dlg := send.(*gi.Dialog)
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			nm := strings.TrimSpace(gi.StringPromptDialogValue(dlg))
+			if !fvv.Params().DeleteSaved(nm) {
+				fvv.Gide.SetStatus(fmt.Sprintf("No saved find named: %v", nm))
+			}
+		})
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindHist(t *testing.T) {
+	fp := &FindParams{Find: "foo", IgnoreCase: true}
+	fp.AddHist()
+	fp.Find = "bar"
+	fp.Globs = "*.go"
+	fp.AddHist()
+	fp.Find = "foo"
+	fp.Globs = ""
+	fp.AddHist()
+	if len(fp.Hist) != 2 || fp.Hist[0].Find != "foo" || fp.Hist[1].Find != "bar" {
+		t.Fatalf("AddHist = %v", fp.Hist)
+	}
+	if got, want := fp.Hist[1].Label(), `"bar" (ignore case, *.go)`; got != want {
+		t.Errorf("Label = %v, want %v", got, want)
+	}
+	fp.SetQuery(&fp.Hist[1])
+	if fp.Find != "bar" || fp.Globs != "*.go" || !fp.IgnoreCase || fp.FindHist[0] != "bar" {
+		t.Errorf("SetQuery = %+v", fp)
+	}
+	if got := []int{fp.HistStep(0, -1), fp.HistStep(0, 1), fp.HistStep(2, 1), fp.HistStep(2, -1)}; !reflect.DeepEqual(got, []int{0, 1, 2, 1}) {
+		t.Errorf("HistStep = %v", got)
+	}
+	saveMax := FindQueryHistMax
+	FindQueryHistMax = 2
+	fp.Find = "baz"
+	fp.AddHist()
+	FindQueryHistMax = saveMax
+	if len(fp.Hist) != 2 || fp.Hist[0].Find != "baz" {
+		t.Errorf("AddHist max = %v", fp.Hist)
+	}
+}
+
+func TestFindSaved(t *testing.T) {
+	fp := &FindParams{Find: "TODO", Loc: FindLocOpen}
+	fp.SaveQuery("todos")
+	fp.Find = "FIXME"
+	fp.SaveQuery("fixmes")
+	fp.Regexp = true
+	fp.SaveQuery("fixmes")
+	if got := fp.SavedNames(); !reflect.DeepEqual(got, []string{"todos", "fixmes"}) {
+		t.Errorf("SavedNames = %v", got)
+	}
+	if !fp.Saved[1].Regexp || fp.Saved[0].Loc != FindLocOpen {
+		t.Errorf("SaveQuery = %+v", fp.Saved)
+	}
+	if got, want := fp.Saved[0].Label(), `todos: "TODO" (open)`; got != want {
+		t.Errorf("Label = %v, want %v", got, want)
+	}
+	if !fp.DeleteSaved("todos") || fp.DeleteSaved("todos") || len(fp.Saved) != 1 {
+		t.Errorf("DeleteSaved: %v", fp.Saved)
+	}
+}
@@ -9,9 +9,9 @@ import (
 
 var _ = errors.New("dummy error")
 
-const _FindLoc_name = "FindLocAllFindLocFileFindLocDirFindLocNotTopFindLocN"
+const _FindLoc_name = "FindLocAllFindLocFileFindLocDirFindLocNotTopFindLocSubdirFindLocOpenFindLocN"
 
-var _FindLoc_index = [...]uint8{0, 10, 21, 31, 44, 52}
+var _FindLoc_index = [...]uint8{0, 10, 21, 31, 44, 57, 68, 76}
 
 func (i FindLoc) String() string {
 	if i < 0 || i >= FindLoc(len(_FindLoc_index)-1) {
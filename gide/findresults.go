@@ -0,0 +1,203 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/goki/gi/giv"
+)
+
+// FindContextLines is how many lines of the file are shown before and after
+// each match in the Find panel, when its context is shown
+var FindContextLines = 2
+
+// RefineResults returns the results that contain refine: all the matches
+// in the files whose path contains it, and the matches whose text contains
+// it in the others, ignoring case -- all of them if refine is empty
+func RefineResults(res []ProjSearchResults, refine string) []ProjSearchResults {
+	if refine == "" {
+		return res
+	}
+	refine = strings.ToLower(refine)
+	var rres []ProjSearchResults
+	for _, fs := range res {
+		if strings.Contains(strings.ToLower(fs.RelPath), refine) {
+			rres = append(rres, fs)
+			continue
+		}
+		var ms []giv.FileSearchMatch
+		for _, mt := range fs.Matches {
+			if strings.Contains(strings.ToLower(SearchMatchPlain(mt)), refine) {
+				ms = append(ms, mt)
+			}
+		}
+		if len(ms) > 0 {
+			rres = append(rres, ProjSearchResults{Path: fs.Path, RelPath: fs.RelPath, Matches: ms})
+		}
+	}
+	return rres
+}
+
+// FindResLine is a line listed for a file in the Find panel: a match, or a
+// line of context around the matches
+type FindResLine struct {
+	Ln    int `desc:"line in the file, 0-based"`
+	Match int `desc:"index of the match, or -1 for a line of context"`
+}
+
+// FindResLines returns the lines listed for matches on given lines, in
+// order, of a file of nlines, with n lines of context before and after
+// them -- each line of context is listed once, however many matches it is
+// near
+func FindResLines(mlns []int, nlines, n int) []FindResLine {
+	var rls []FindResLine
+	shown := -1 // last line listed
+	for i, ln := range mlns {
+		st := ln - n
+		if st <= shown {
+			st = shown + 1
+		}
+		for l := st; l < ln && l < nlines; l++ {
+			rls = append(rls, FindResLine{Ln: l, Match: -1})
+		}
+		rls = append(rls, FindResLine{Ln: ln, Match: i})
+		if ln > shown {
+			shown = ln
+		}
+		nxt := nlines
+		if i+1 < len(mlns) {
+			nxt = mlns[i+1]
+		}
+		for l := ln + 1; l <= ln+n && l < nxt && l < nlines; l++ {
+			rls = append(rls, FindResLine{Ln: l, Match: -1})
+			shown = l
+		}
+	}
+	return rls
+}
+
+// fileLines returns the lines of the file at given path -- from its buffer,
+// if it is open
+func (fv *FindView) fileLines(path string) []string {
+	if ond, ok := fv.Gide.OpenNodeByPath(path); ok && ond.Buf != nil {
+		return bufStrings(ond.Buf)
+	}
+	lines, _, _ := ReplaceFileLines(path)
+	return lines
+}
+
+// renderResults adds given results to the results buffer, refined by
+// Refine, and with lines of context around each match if Context -- Mu
+// must be locked
+func (fv *FindView) renderResults(res []ProjSearchResults) {
+	res = RefineResults(res, fv.Refine)
+	if len(res) == 0 {
+		return
+	}
+	fbuf, _ := fv.Gide.FindOrMakeCmdBuf("Find", false)
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	for _, fs := range res {
+		fbStLn := fv.NLines + len(outlns) // find buf start ln
+		lstr := fmt.Sprintf(`%v: %v`, fs.RelPath, len(fs.Matches))
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte(fmt.Sprintf(`<b>%v</b>`, html.EscapeString(lstr))))
+		mlns := make([]int, len(fs.Matches))
+		for i, mt := range fs.Matches {
+			mlns[i] = mt.Reg.Start.Ln
+		}
+		var flines []string
+		n := 0
+		if fv.Context {
+			flines = fv.fileLines(fs.Path)
+			n = FindContextLines
+		}
+		for _, rl := range FindResLines(mlns, len(flines), n) {
+			if rl.Match < 0 {
+				cstr := fmt.Sprintf("\t%v:%d  %v", fs.RelPath, rl.Ln+1, flines[rl.Ln])
+				outlns = append(outlns, []byte(cstr))
+				outmus = append(outmus, []byte(fmt.Sprintf(`<i>%v</i>`, html.EscapeString(cstr))))
+				continue
+			}
+			mt := fs.Matches[rl.Match]
+			ln := mt.Reg.Start.Ln + 1
+			ch := mt.Reg.Start.Ch + 1
+			eln := mt.Reg.End.Ln + 1 // after the start line for a multi-line match
+			ech := mt.Reg.End.Ch + 1
+			fnstr := fmt.Sprintf("%v:%d:%d", fs.RelPath, ln, ch)
+			outlns = append(outlns, []byte(fmt.Sprintf(`	%v: %s`, fnstr, SearchMatchPlain(mt))))
+			outmus = append(outmus, []byte(fmt.Sprintf(`	<a href="find:///%v#R%vN%vL%vC%v-L%vC%v">%v</a>: %s`, fs.Path, fbStLn, len(fs.Matches), ln, ch, eln, ech, html.EscapeString(fnstr), mt.Text)))
+		}
+		outlns = append(outlns, []byte(""))
+		outmus = append(outmus, []byte(""))
+	}
+	fv.NLines += len(outlns)
+	// the text ends with a newline, so the last line is left empty for the
+	// next results to start on
+	ltxt := append(bytes.Join(outlns, []byte("\n")), '\n')
+	mtxt := append(bytes.Join(outmus, []byte("\n")), '\n')
+	fbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+}
+
+// Rerender lists all the results so far again, as they are now refined,
+// and with context or not
+func (fv *FindView) Rerender() {
+	fv.Mu.Lock()
+	fv.Gide.FindOrMakeCmdBuf("Find", true) // clear
+	fv.NLines = 0
+	fv.renderResults(fv.Results)
+	nf, nm := 0, 0
+	for _, fs := range RefineResults(fv.Results, fv.Refine) {
+		nf++
+		nm += len(fs.Matches)
+	}
+	fv.Mu.Unlock()
+	if tv := fv.Gide.ActiveTextView(); tv != nil {
+		tv.Highlights = nil // of the results before
+		tv.SetNeedsRefresh()
+	}
+	if fv.Refine != "" {
+		fv.Gide.SetStatus(fmt.Sprintf("%d matches in %d files contain: %v", nm, nf, fv.Refine))
+	}
+}
+
+// SetRefine lists only the results that contain given string, as in
+// RefineResults -- all of them if it is empty
+func (fv *FindView) SetRefine(refine string) {
+	if refine == fv.Refine {
+		return
+	}
+	fv.Refine = refine
+	fv.Rerender()
+}
+
+// SetContext shows or hides the lines of context around each match
+func (fv *FindView) SetContext(on bool) {
+	if on == fv.Context {
+		return
+	}
+	fv.Context = on
+	fv.Rerender()
+}
+
+// FindResult goes to the next result listed in the Find panel, or the
+// previous one if prev, from anywhere -- wrapping around at the ends
+func (ge *Gide) FindResult(prev bool) {
+	fvi, _, ok := ge.MainTabByName("Find")
+	if !ok {
+		ge.SetStatus("No find results")
+		return
+	}
+	fv := fvi.Embed(KiT_FindView).(*FindView)
+	if prev {
+		fv.PrevFind()
+	} else {
+		fv.NextFind()
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRefineResults(t *testing.T) {
+	src := []byte("func Foo() {\n\treturn foo(bar)\n}\n// foo baz\n")
+	m, _ := NewSearchMatcher("foo", true, false)
+	res := []ProjSearchResults{
+		{Path: "/p/a.go", RelPath: "a.go", Matches: SearchText(src, m)},
+		{Path: "/p/bar/b.go", RelPath: "bar/b.go", Matches: SearchText(src, m)},
+	}
+	if got := RefineResults(res, ""); !reflect.DeepEqual(got, res) {
+		t.Errorf("RefineResults empty = %v", got)
+	}
+	got := RefineResults(res, "BAR")
+	if len(got) != 2 || len(got[0].Matches) != 1 || got[0].Matches[0].Reg.Start.Ln != 1 || len(got[1].Matches) != 3 {
+		t.Errorf("RefineResults bar = %v", got)
+	}
+	if got = RefineResults(res, "nothing"); len(got) != 0 {
+		t.Errorf("RefineResults nothing = %v", got)
+	}
+}
+
+func TestFindResLines(t *testing.T) {
+	ctx := func(ln int) FindResLine { return FindResLine{Ln: ln, Match: -1} }
+	mt := func(ln, i int) FindResLine { return FindResLine{Ln: ln, Match: i} }
+	tests := []struct {
+		mlns   []int
+		nlines int
+		n      int
+		want   []FindResLine
+	}{
+		{[]int{1, 5}, 10, 0, []FindResLine{mt(1, 0), mt(5, 1)}},
+		{[]int{1, 5}, 10, 1, []FindResLine{ctx(0), mt(1, 0), ctx(2), ctx(4), mt(5, 1), ctx(6)}},
+		{[]int{1, 2, 2}, 4, 2, []FindResLine{ctx(0), mt(1, 0), mt(2, 1), mt(2, 2), ctx(3)}},
+		{[]int{0, 6}, 7, 2, []FindResLine{mt(0, 0), ctx(1), ctx(2), ctx(4), ctx(5), mt(6, 1)}},
+	}
+	for _, tt := range tests {
+		if got := FindResLines(tt.mlns, tt.nlines, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindResLines(%v, %v, %v) = %v, want %v", tt.mlns, tt.nlines, tt.n, got, tt.want)
+		}
+	}
+}
@@ -6,13 +6,18 @@ package gide
 
 import (
 	"bytes"
+	"fmt"
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/units"
 	"github.com/goki/ki"
 	"github.com/goki/ki/kit"
 )
@@ -20,7 +25,7 @@ import (
 type FindLoc int
 
 const (
-	// FindLocAll finds in all open folders in the left file browser
+	// FindLocAll finds in all the files of the project
 	FindLocAll FindLoc = iota
 
 	// FindLocFile only finds in the current active file
@@ -29,9 +34,17 @@ const (
 	// FindLocDir only finds in the directory of the current active file
 	FindLocDir
 
-	// FindLocNotTop finds in all open folders *except* the top-level folder
+	// FindLocNotTop finds in all the files of the project *except* those at its top level
 	FindLocNotTop
 
+	// FindLocSubdir finds in the files of a chosen directory and those of
+	// the directories within it -- the directory of the current active file
+	// if none is chosen
+	FindLocSubdir
+
+	// FindLocOpen only finds in the files that are open
+	FindLocOpen
+
 	FindLocN
 )
 
@@ -44,22 +57,39 @@ func (ev *FindLoc) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(
 
 // FindParams are parameters for find / replace
 type FindParams struct {
-	Find       string    `desc:"find string"`
-	Replace    string    `desc:"replace string"`
-	IgnoreCase bool      `desc:"ignore case"`
-	Langs      LangNames `desc:"languages for files to search"`
-	Loc        FindLoc   `desc:"locations to search in"`
-	FindHist   []string  `desc:"history of finds"`
-	ReplHist   []string  `desc:"history of replaces"`
+	Find         string      `desc:"find string"`
+	Replace      string      `desc:"replace string"`
+	IgnoreCase   bool        `desc:"ignore case"`
+	Regexp       bool        `desc:"find string is a regular expression, in Go syntax"`
+	WholeWord    bool        `desc:"only find whole words: matches that are not right after or before a letter, digit or _"`
+	MultiLine    bool        `desc:"regular expression matches can span lines: . matches newlines too, and ^ and $ match at the start and end of each line"`
+	Structural   bool        `desc:"find string is a Go pattern, matched syntactically in Go files, as in StructMatcher: $X matches any expression or statement, and $*X any list of them -- in the replace string, $X is replaced with what it matched"`
+	PreserveCase bool        `desc:"replace keeping the case of what is replaced: foo, Foo and FOO are replaced with bar, Bar and BAR"`
+	Langs        LangNames   `desc:"languages for files to search"`
+	Loc          FindLoc     `desc:"locations to search in"`
+	Dir          string      `desc:"directory searched, with those within it, for Loc subdir -- that of the active file if empty"`
+	Globs        string      `desc:"only search files matching these globs, separated by spaces, e.g. *.go -- those starting with ! are excluded, e.g. !vendor/** -- matched as the patterns of a .gitignore file at the project root"`
+	Hist         []FindQuery `desc:"history of finds, with their options, newest first -- the up and down arrows in the find field go through it"`
+	Saved        []FindQuery `desc:"finds saved by name, with their options"`
+	FindHist     []string    `desc:"history of finds"`
+	ReplHist     []string    `desc:"history of replaces"`
 }
 
 // FindView is a find / replace widget that displays results in a TextView
 // and has a toolbar for controlling find / replace process.
 type FindView struct {
 	gi.Layout
-	Gide   *Gide         `json:"-" xml:"-" desc:"parent gide project"`
-	LangVV giv.ValueView `desc:"langs value view"`
-	Time   time.Time     `desc:"time of last find"`
+	Gide     *Gide               `json:"-" xml:"-" desc:"parent gide project"`
+	LangVV   giv.ValueView       `desc:"langs value view"`
+	Time     time.Time           `desc:"time of last find"`
+	SearchN  int                 `json:"-" xml:"-" view:"-" desc:"number of the current search -- a search is cancelled when it is stopped or another one is started"`
+	NLines   int                 `json:"-" xml:"-" view:"-" desc:"number of lines of results of the current search in the results buffer so far"`
+	Results  []ProjSearchResults `json:"-" xml:"-" view:"-" desc:"results of the current search so far, in the order they are listed"`
+	Mu       sync.Mutex          `json:"-" xml:"-" view:"-" desc:"mutex protecting the search -- results arrive in the background"`
+	HistPos  int                 `json:"-" xml:"-" view:"-" desc:"position in the history of finds that the up and down arrows in the find field have gone to: 0 for the find being typed, n for the n-th newest"`
+	HistEdit FindQuery           `json:"-" xml:"-" view:"-" desc:"the find being typed when the up arrow was first pressed, which the down arrow goes back to"`
+	Refine   string              `json:"-" xml:"-" view:"-" desc:"only the results containing this are listed -- see RefineResults"`
+	Context  bool                `json:"-" xml:"-" view:"-" desc:"list FindContextLines lines of context around each match"`
 }
 
 var KiT_FindView = kit.Types.AddType(&FindView{}, FindViewProps)
@@ -95,6 +125,99 @@ func (fv *FindView) FindAction() {
 	fv.Gide.Find(fv.Params().Find, fv.Params().Replace, fv.Params().IgnoreCase, fv.Params().Loc, fv.Params().Langs)
 }
 
+// NewSearch starts a new search, cancelling any one still running, and
+// returns its number
+func (fv *FindView) NewSearch() int {
+	fv.Mu.Lock()
+	defer fv.Mu.Unlock()
+	fv.SearchN++
+	fv.NLines = 0
+	fv.Results = nil
+	fv.Refine = ""
+	if rf := fv.RefineText(); rf != nil {
+		rf.SetText("")
+	}
+	return fv.SearchN
+}
+
+// IsSearch returns true if given search is still the current one
+func (fv *FindView) IsSearch(srch int) bool {
+	fv.Mu.Lock()
+	defer fv.Mu.Unlock()
+	return fv.SearchN == srch
+}
+
+// Stop cancels the search that is running, keeping the results so far
+func (fv *FindView) Stop() {
+	fv.Mu.Lock()
+	fv.SearchN++
+	fv.Mu.Unlock()
+	fv.Gide.SetStatus("Search stopped")
+}
+
+// AppendResults adds the matches in given files to the results, as they
+// arrive from given search -- they are dropped if it has been cancelled
+func (fv *FindView) AppendResults(srch int, res []ProjSearchResults) {
+	if len(res) == 0 {
+		return
+	}
+	fv.Mu.Lock()
+	defer fv.Mu.Unlock()
+	if fv.SearchN != srch {
+		return
+	}
+	fv.Results = append(fv.Results, res...)
+	fv.renderResults(res)
+}
+
+// Matcher returns the matcher of the find string, with its options
+func (fp *FindParams) Matcher() (SearchMatcher, error) {
+	if fp.Structural {
+		sm, err := NewStructMatcher(fp.Find)
+		if err != nil {
+			return nil, err
+		}
+		return sm, nil
+	}
+	return NewFindMatcher(fp.Find, fp.IgnoreCase, fp.Regexp, fp.WholeWord, fp.MultiLine)
+}
+
+// Replacer returns the matcher and replace function for the current params
+func (fv *FindView) Replacer() (SearchMatcher, ReplaceFunc, error) {
+	fp := fv.Params()
+	m, err := fp.Matcher()
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, NewReplacer(m, fp.Replace, fp.PreserveCase), nil
+}
+
+// RegionText returns the text of given region of lines, with newlines
+// between them -- the region must be within the lines
+func RegionText(lines [][]rune, reg giv.TextRegion) []byte {
+	if reg.Start.Ln == reg.End.Ln {
+		return []byte(string(lines[reg.Start.Ln][reg.Start.Ch:reg.End.Ch]))
+	}
+	var sb strings.Builder
+	sb.WriteString(string(lines[reg.Start.Ln][reg.Start.Ch:]))
+	for ln := reg.Start.Ln + 1; ln < reg.End.Ln; ln++ {
+		sb.WriteString("\n" + string(lines[ln]))
+	}
+	sb.WriteString("\n" + string(lines[reg.End.Ln][:reg.End.Ch]))
+	return []byte(sb.String())
+}
+
+// ReplaceAt returns the replacement for the match of m from byte st to ed
+// in given line -- false if that is no longer a match
+func ReplaceAt(line []byte, st, ed int, m SearchMatcher, repl ReplaceFunc) ([]byte, bool) {
+	for _, mi := range m.FindLine(line) {
+		if mi[0] == st && mi[1] == ed {
+			return repl(line, mi), true
+		}
+	}
+	return nil, false
+}
+
 // ReplaceAction performs the replace
 func (fv *FindView) ReplaceAction() bool {
 	winUpdt := fv.Gide.Viewport.Win.UpdateStart()
@@ -116,6 +239,11 @@ func (fv *FindView) ReplaceAction() bool {
 		}
 	}
 	ge := fv.Gide
+	m, rfn, err := fv.Replacer()
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Replace: %v", err))
+		return false
+	}
 	tv, reg, _, _, ok := ge.ParseOpenFindURL(tl.URL, ftv)
 	if !ok {
 		return false
@@ -133,10 +261,28 @@ func (fv *FindView) ReplaceAction() bool {
 	}
 	reg.Time.SetTime(fv.Time)
 	reg = tv.Buf.AdjustReg(reg)
+	var repl []byte
+	if !reg.IsNil() {
+		ok = false
+		if _, tf := m.(TextFinder); tf {
+			// matched in the whole text, so it may span lines
+			txt := RegionText(tv.Buf.Lines, reg)
+			repl, ok = ReplaceAt(txt, 0, len(txt), m, rfn)
+		} else if reg.Start.Ln == reg.End.Ln {
+			lr := tv.Buf.Lines[reg.Start.Ln]
+			st, ed := len(string(lr[:reg.Start.Ch])), len(string(lr[:reg.End.Ch]))
+			repl, ok = ReplaceAt([]byte(string(lr)), st, ed, m, rfn)
+		}
+		if !ok {
+			reg = giv.TextRegionNil // changed since the find
+		}
+	}
 	if !reg.IsNil() {
 		tv.RefreshIfNeeded()
+		ge.OpTouch(tv.Buf)
 		tbe := tv.Buf.DeleteText(reg.Start, reg.End, true, true)
-		tv.Buf.InsertText(tbe.Reg.Start, []byte(fv.Params().Replace), true, true)
+		tv.Buf.InsertText(tbe.Reg.Start, repl, true, true)
+		ge.AuditNote(tv.Buf, "replace")
 
 		// delete the link for the just done replace
 		ftvln := ftv.CursorPos.Ln
@@ -159,12 +305,14 @@ func (fv *FindView) ReplaceAction() bool {
 
 // ReplaceAllAction performs replace all
 func (fv *FindView) ReplaceAllAction() {
+	fv.Gide.OpStart(fmt.Sprintf("replace all %q with %q", fv.Params().Find, fv.Params().Replace))
 	for {
 		ok := fv.ReplaceAction()
 		if !ok {
 			break
 		}
 	}
+	fv.Gide.OpEnd(false)
 }
 
 // NextFind shows next find result
@@ -211,9 +359,10 @@ func (fv *FindView) HighlightFinds(tv, ftv *giv.TextView, fbStLn, fCount int, fi
 	fb := ftv.Buf
 
 	if len(tv.Highlights) != fCount { // highlight
-		hi := make([]giv.TextRegion, fCount)
-		for i := 0; i < fCount; i++ {
-			fln := fbStLn + 1 + i
+		hi := make([]giv.TextRegion, 0, fCount)
+		// the matches are listed after the file line, along with any lines
+		// of context, up to an empty line
+		for fln := fbStLn + 1; fln < len(fb.Markup) && len(fb.Markup[fln]) > 0; fln++ {
 			ltxt := fb.Markup[fln]
 			fpi := bytes.Index(ltxt, lnka)
 			if fpi < 0 {
@@ -230,7 +379,7 @@ func (fv *FindView) HighlightFinds(tv, ftv *giv.TextView, fbStLn, fCount int, fi
 			lidx := strings.Index(iup.Fragment, "L")
 			ireg.FromString(iup.Fragment[lidx:])
 			ireg.Time.SetTime(fv.Time)
-			hi[i] = ireg
+			hi = append(hi, ireg)
 		}
 		tv.Highlights = hi
 	}
@@ -252,8 +401,23 @@ func (fv *FindView) UpdateView(ge *Gide) {
 	rt.SetText(fv.Params().Replace)
 	ib := fv.IgnoreBox()
 	ib.SetChecked(fv.Params().IgnoreCase)
+	rb := fv.RegexpBox()
+	rb.SetChecked(fv.Params().Regexp)
+	wb := fv.WholeWordBox()
+	wb.SetChecked(fv.Params().WholeWord)
+	mb := fv.MultiLineBox()
+	mb.SetChecked(fv.Params().MultiLine)
+	sb := fv.StructuralBox()
+	sb.SetChecked(fv.Params().Structural)
+	pb := fv.PreserveCaseBox()
+	pb.SetChecked(fv.Params().PreserveCase)
 	cf := fv.LocCombo()
 	cf.SetCurIndex(int(fv.Params().Loc))
+	gf := fv.GlobsText()
+	gf.SetText(fv.Params().Globs)
+	if fv.LangVV != nil {
+		fv.LangVV.UpdateWidget()
+	}
 	tvly := fv.TextViewLay()
 	fv.Gide.ConfigOutputTextView(tvly)
 	if mods {
@@ -353,6 +517,32 @@ func (fv *FindView) LocCombo() *gi.ComboBox {
 	return tfi.(*gi.ComboBox)
 }
 
+// GlobsText returns the globs text field in toolbar
+func (fv *FindView) GlobsText() *gi.TextField {
+	tb := fv.ReplBar()
+	if tb == nil {
+		return nil
+	}
+	tfi, ok := tb.ChildByName("globs", 10)
+	if !ok {
+		return nil
+	}
+	return tfi.(*gi.TextField)
+}
+
+// RefineText returns the refine results text field in toolbar
+func (fv *FindView) RefineText() *gi.TextField {
+	tb := fv.FindBar()
+	if tb == nil {
+		return nil
+	}
+	tfi, ok := tb.ChildByName("refine", 9)
+	if !ok {
+		return nil
+	}
+	return tfi.(*gi.TextField)
+}
+
 // CurDirBox returns the cur file checkbox in toolbar
 func (fv *FindView) CurDirBox() *gi.CheckBox {
 	tb := fv.ReplBar()
@@ -366,13 +556,78 @@ func (fv *FindView) CurDirBox() *gi.CheckBox {
 	return tfi.(*gi.CheckBox)
 }
 
+// RegexpBox returns the regexp checkbox in toolbar
+func (fv *FindView) RegexpBox() *gi.CheckBox {
+	tb := fv.FindBar()
+	if tb == nil {
+		return nil
+	}
+	tfi, ok := tb.ChildByName("regexp", 3)
+	if !ok {
+		return nil
+	}
+	return tfi.(*gi.CheckBox)
+}
+
+// WholeWordBox returns the whole word checkbox in toolbar
+func (fv *FindView) WholeWordBox() *gi.CheckBox {
+	tb := fv.FindBar()
+	if tb == nil {
+		return nil
+	}
+	tfi, ok := tb.ChildByName("whole-word", 4)
+	if !ok {
+		return nil
+	}
+	return tfi.(*gi.CheckBox)
+}
+
+// MultiLineBox returns the multi-line checkbox in toolbar
+func (fv *FindView) MultiLineBox() *gi.CheckBox {
+	tb := fv.FindBar()
+	if tb == nil {
+		return nil
+	}
+	tfi, ok := tb.ChildByName("multi-line", 5)
+	if !ok {
+		return nil
+	}
+	return tfi.(*gi.CheckBox)
+}
+
+// StructuralBox returns the structural checkbox in toolbar
+func (fv *FindView) StructuralBox() *gi.CheckBox {
+	tb := fv.FindBar()
+	if tb == nil {
+		return nil
+	}
+	tfi, ok := tb.ChildByName("structural", 6)
+	if !ok {
+		return nil
+	}
+	return tfi.(*gi.CheckBox)
+}
+
+// PreserveCaseBox returns the preserve case checkbox in toolbar
+func (fv *FindView) PreserveCaseBox() *gi.CheckBox {
+	tb := fv.ReplBar()
+	if tb == nil {
+		return nil
+	}
+	tfi, ok := tb.ChildByName("preserve-case", 4)
+	if !ok {
+		return nil
+	}
+	return tfi.(*gi.CheckBox)
+}
+
 // FindNextAct returns the find next action in toolbar -- selected first
 func (fv *FindView) FindNextAct() *gi.Action {
 	tb := fv.FindBar()
 	if tb == nil {
 		return nil
 	}
-	tfi, ok := tb.ChildByName("next", 3)
+	tfi, ok := tb.ChildByName("next", 4)
 	if !ok {
 		return nil
 	}
@@ -411,7 +666,7 @@ func (fv *FindView) ConfigToolbar() {
 
 	finda := fb.AddNewChild(gi.KiT_Action, "find-act").(*gi.Action)
 	finda.SetText("Find:")
-	finda.Tooltip = "Find given string in project files. Only open folders in file browser will be searched -- adjust those to scope the search"
+	finda.Tooltip = "Find given string in project files, in parallel in the background, listing the results as they are found -- files ignored by .gitignore files are not searched"
 	finda.ActionSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		fvv, _ := recv.Embed(KiT_FindView).(*FindView)
 		fvv.FindAction()
@@ -420,7 +675,7 @@ func (fv *FindView) ConfigToolbar() {
 	finds := fb.AddNewChild(gi.KiT_ComboBox, "find-str").(*gi.ComboBox)
 	finds.Editable = true
 	finds.SetStretchMaxWidth()
-	finds.Tooltip = "String to find -- hit enter or tab to update search -- click for history"
+	finds.Tooltip = "String to find -- hit enter or tab to update search -- click for history -- up and down arrows go through the history of finds, with their options"
 	finds.ConfigParts()
 	finds.ItemsFromStringList(fv.Params().FindHist, true, 0)
 	ftf, _ := finds.TextField()
@@ -430,6 +685,17 @@ func (fv *FindView) ConfigToolbar() {
 		fvv.Params().Find = cb.CurVal.(string)
 		fvv.FindAction()
 	})
+	ftf.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		kt := d.(*key.ChordEvent)
+		switch gi.KeyFun(kt.Chord()) {
+		case gi.KeyFunMoveUp:
+			kt.SetProcessed()
+			fv.HistKey(true)
+		case gi.KeyFunMoveDown:
+			kt.SetProcessed()
+			fv.HistKey(false)
+		}
+	})
 	ftf.TextFieldSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if sig == int64(gi.TextFieldDone) || sig == int64(gi.TextFieldDeFocused) {
 			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
@@ -458,6 +724,50 @@ func (fv *FindView) ConfigToolbar() {
 		}
 	})
 
+	re := fb.AddNewChild(gi.KiT_CheckBox, "regexp").(*gi.CheckBox)
+	re.SetText("Regexp")
+	re.Tooltip = "find string is a regular expression, in Go syntax, matched within each line, unless Multi-line"
+	re.ButtonSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			cb := send.(*gi.CheckBox)
+			fvv.Params().Regexp = cb.IsChecked()
+		}
+	})
+
+	ww := fb.AddNewChild(gi.KiT_CheckBox, "whole-word").(*gi.CheckBox)
+	ww.SetText("Whole Word")
+	ww.Tooltip = "only find whole words: matches that are not right after or before a letter, digit or _"
+	ww.ButtonSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			cb := send.(*gi.CheckBox)
+			fvv.Params().WholeWord = cb.IsChecked()
+		}
+	})
+
+	ml := fb.AddNewChild(gi.KiT_CheckBox, "multi-line").(*gi.CheckBox)
+	ml.SetText("Multi-line")
+	ml.Tooltip = "with Regexp, matches can span lines: . matches newlines too, and ^ and $ match at the start and end of each line"
+	ml.ButtonSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			cb := send.(*gi.CheckBox)
+			fvv.Params().MultiLine = cb.IsChecked()
+		}
+	})
+
+	sc := fb.AddNewChild(gi.KiT_CheckBox, "structural").(*gi.CheckBox)
+	sc.SetText("Structural")
+	sc.Tooltip = "find string is a Go pattern, e.g., if err != nil { return $X }, matched syntactically in Go files, whatever its formatting: $X matches any expression or statement, $*X any list of them, e.g., f($*A), and $_ anything -- in the replace string, $X is replaced with what it matched, and a replace is only applied to a file if it still parses"
+	sc.ButtonSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			cb := send.(*gi.CheckBox)
+			fvv.Params().Structural = cb.IsChecked()
+		}
+	})
+
 	next := fb.AddNewChild(gi.KiT_Action, "next").(*gi.Action)
 	next.SetIcon("widget-wedge-down")
 	next.Tooltip = "go to next result"
@@ -474,6 +784,45 @@ func (fv *FindView) ConfigToolbar() {
 		fvv.PrevFind()
 	})
 
+	stop := fb.AddNewChild(gi.KiT_Action, "stop").(*gi.Action)
+	stop.SetText("Stop")
+	stop.Tooltip = "stop the search that is running, keeping the results so far"
+	stop.ActionSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+		fvv.Stop()
+	})
+
+	ctxt := fb.AddNewChild(gi.KiT_CheckBox, "context").(*gi.CheckBox)
+	ctxt.SetText("Context")
+	ctxt.Tooltip = "list the lines around each match in the results"
+	ctxt.ButtonSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			cb := send.(*gi.CheckBox)
+			fvv.SetContext(cb.IsChecked())
+		}
+	})
+
+	refine := fb.AddNewChild(gi.KiT_TextField, "refine").(*gi.TextField)
+	refine.SetMinPrefWidth(units.NewValue(16, units.Ch))
+	refine.Placeholder = "refine results"
+	refine.Tooltip = "only list the results that contain this, ignoring case: all the matches in the files whose path contains it, and the matches whose line contains it in the others -- clear it to list all of them again"
+	refine.TextFieldSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) || sig == int64(gi.TextFieldCleared) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			tf := send.(*gi.TextField)
+			fvv.SetRefine(tf.Text())
+		}
+	})
+
+	saved := fb.AddNewChild(gi.KiT_Action, "saved").(*gi.Action)
+	saved.SetText("Saved")
+	saved.Tooltip = "finds saved by name in the project, with their options -- run one, save the current find, or delete one"
+	saved.ActionSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+		fvv.SavedMenu()
+	})
+
 	repla := rb.AddNewChild(gi.KiT_Action, "repl-act").(*gi.Action)
 	repla.SetText("Replace:")
 	repla.Tooltip = "Replace find string with replace string for currently-selected find result"
@@ -485,7 +834,7 @@ func (fv *FindView) ConfigToolbar() {
 	repls := rb.AddNewChild(gi.KiT_ComboBox, "repl-str").(*gi.ComboBox)
 	repls.Editable = true
 	repls.SetStretchMaxWidth()
-	repls.Tooltip = "String to replace find string -- click for history"
+	repls.Tooltip = "String to replace find string -- click for history -- with Regexp, $1 or ${1} is replaced with the text of the first group of the match, ${name} with that of the group named name"
 	repls.ConfigParts()
 	repls.ItemsFromStringList(fv.Params().ReplHist, true, 0)
 	rtf, _ := repls.TextField()
@@ -510,9 +859,28 @@ func (fv *FindView) ConfigToolbar() {
 		fvv.ReplaceAllAction()
 	})
 
+	preview := rb.AddNewChild(gi.KiT_Action, "repl-preview").(*gi.Action)
+	preview.SetText("Preview")
+	preview.Tooltip = "show the changes that replacing all of the results makes, in the Replace panel, where each one can be unchecked before they are applied"
+	preview.ActionSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+		fvv.Gide.ReplacePreview()
+	})
+
+	pc := rb.AddNewChild(gi.KiT_CheckBox, "preserve-case").(*gi.CheckBox)
+	pc.SetText("Preserve Case")
+	pc.Tooltip = "replace keeping the case of what is replaced: foo, Foo and FOO are replaced with bar, Bar and BAR"
+	pc.ButtonSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			cb := send.(*gi.CheckBox)
+			fvv.Params().PreserveCase = cb.IsChecked()
+		}
+	})
+
 	locl := rb.AddNewChild(gi.KiT_Label, "loc-lbl").(*gi.Label)
 	locl.SetText("Loc:")
-	locl.Tooltip = "location to find in: all = all open folders in browser; file = current active file; dir = directory of current active file; nottop = all except the top-level in browser"
+	locl.Tooltip = "location to find in: all = all files in the project; file = current active file; dir = directory of current active file; nottop = all except those at the top level of the project; subdir = the folder chosen with Find In Folder in the file tree, or that of the current active file, and all the folders within it; open = the open files"
 	// locl.SetProp("vertical-align", gi.AlignMiddle)
 
 	cf := rb.AddNewChild(gi.KiT_ComboBox, "loc").(*gi.ComboBox)
@@ -537,6 +905,22 @@ func (fv *FindView) ConfigToolbar() {
 	langw := rb.AddNewChild(vtyp, "langs").(gi.Node2D)
 	fv.LangVV.ConfigWidget(langw)
 	langw.AsWidget().Tooltip = langl.Tooltip
+
+	globl := rb.AddNewChild(gi.KiT_Label, "globs-lbl").(*gi.Label)
+	globl.SetText("Files:")
+	globl.Tooltip = "only search files matching these globs, separated by spaces, e.g. *.go -- those starting with ! are excluded, e.g. !vendor/** -- matched as the patterns of a .gitignore file at the project root"
+
+	globs := rb.AddNewChild(gi.KiT_TextField, "globs").(*gi.TextField)
+	globs.SetMinPrefWidth(units.NewValue(20, units.Ch))
+	globs.Tooltip = globl.Tooltip
+	globs.TextFieldSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			tf := send.(*gi.TextField)
+			fvv.Params().Globs = tf.Text()
+		}
+	})
+
 	//	vvb := vv.AsValueViewBase()
 	//	vvb.ViewSig.ConnectOnly(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 	//		fvv, _ := recv.Embed(KiT_FindView).(*FindView)
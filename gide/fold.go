@@ -0,0 +1,307 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/giv"
+)
+
+// FoldRegion is a region of lines that can be folded: the Start line stays
+// visible and the lines after it, through End, are folded away
+type FoldRegion struct {
+	Start int    `desc:"line where the region starts -- stays visible when folded"`
+	End   int    `desc:"last line of the region"`
+	Kind  string `desc:"kind of region: block, comment or region (for //region markers)"`
+	Name  string `desc:"name given after a //region marker"`
+}
+
+// Contains returns true if given line is within the region
+func (fr *FoldRegion) Contains(ln int) bool {
+	return fr.Start <= ln && ln <= fr.End
+}
+
+// Hides returns true if given line is hidden when the region is folded
+func (fr *FoldRegion) Hides(ln int) bool {
+	return fr.Start < ln && ln <= fr.End
+}
+
+// foldMarker returns the name of a //region marker on given (trimmed) line,
+// and whether it is a start (1) or end (-1) marker, or 0 if not a marker
+func foldMarker(s string) (string, int) {
+	for _, pfx := range []string{"//", "#"} {
+		if !strings.HasPrefix(s, pfx) {
+			continue
+		}
+		s = strings.TrimSpace(s[len(pfx):])
+		switch {
+		case strings.HasPrefix(s, "endregion"):
+			return "", -1
+		case strings.HasPrefix(s, "region"):
+			return strings.TrimSpace(s[len("region"):]), 1
+		}
+	}
+	return "", 0
+}
+
+// FoldRegions returns the foldable regions in given lines of text: blocks
+// between matching braces, runs of // comment lines, and regions between
+// //region and //endregion markers -- sorted by start line, outermost first
+func FoldRegions(lines [][]rune) []FoldRegion {
+	var frs []FoldRegion
+	var braces, marks []int
+	var names []string
+	cmtSt := -1
+	inBlkCmt := false
+	var quote rune
+	endCmt := func(ln int) {
+		if cmtSt >= 0 && ln-1 > cmtSt {
+			frs = append(frs, FoldRegion{Start: cmtSt, End: ln - 1, Kind: "comment"})
+		}
+		cmtSt = -1
+	}
+	for ln, l := range lines {
+		ts := strings.TrimSpace(string(l))
+		if nm, mk := foldMarker(ts); mk != 0 {
+			endCmt(ln)
+			if mk > 0 {
+				marks = append(marks, ln)
+				names = append(names, nm)
+			} else if n := len(marks); n > 0 {
+				frs = append(frs, FoldRegion{Start: marks[n-1], End: ln, Kind: "region", Name: names[n-1]})
+				marks, names = marks[:n-1], names[:n-1]
+			}
+			continue
+		}
+		if !inBlkCmt && strings.HasPrefix(ts, "//") {
+			if cmtSt < 0 {
+				cmtSt = ln
+			}
+			continue
+		}
+		endCmt(ln)
+		for i := 0; i < len(l); i++ {
+			r := l[i]
+			switch {
+			case inBlkCmt:
+				if r == '*' && i+1 < len(l) && l[i+1] == '/' {
+					inBlkCmt = false
+					i++
+				}
+			case quote != 0:
+				if r == '\\' && quote != '`' {
+					i++
+				} else if r == quote {
+					quote = 0
+				}
+			case r == '/' && i+1 < len(l) && l[i+1] == '/':
+				i = len(l)
+			case r == '/' && i+1 < len(l) && l[i+1] == '*':
+				inBlkCmt = true
+				i++
+			case r == '"' || r == '\'' || r == '`':
+				quote = r
+			case r == '{':
+				braces = append(braces, ln)
+			case r == '}':
+				if n := len(braces); n > 0 {
+					if st := braces[n-1]; ln > st {
+						frs = append(frs, FoldRegion{Start: st, End: ln, Kind: "block"})
+					}
+					braces = braces[:n-1]
+				}
+			}
+		}
+		if quote != '`' {
+			quote = 0 // only raw strings span lines
+		}
+	}
+	endCmt(len(lines))
+	sort.SliceStable(frs, func(i, j int) bool {
+		if frs[i].Start == frs[j].Start {
+			return frs[i].End > frs[j].End
+		}
+		return frs[i].Start < frs[j].Start
+	})
+	return frs
+}
+
+// FoldRegionAt returns the innermost region in given regions that contains
+// given line, or nil if none
+func FoldRegionAt(frs []FoldRegion, ln int) *FoldRegion {
+	var in *FoldRegion
+	for i := range frs {
+		fr := &frs[i]
+		if fr.Start > ln {
+			break
+		}
+		if fr.Contains(ln) && (in == nil || fr.End <= in.End) {
+			in = fr
+		}
+	}
+	return in
+}
+
+// FoldPath returns the key used for the fold state of given file in the
+// project prefs: its path relative to the project root
+func (ge *Gide) FoldPath(tb *giv.TextBuf) string {
+	return refRelPath(string(ge.ProjRoot), string(tb.Filename))
+}
+
+// FoldedRegions returns the regions of given buffer that are currently
+// folded
+func (ge *Gide) FoldedRegions(tb *giv.TextBuf) []FoldRegion {
+	fls := ge.Prefs.Folds[ge.FoldPath(tb)]
+	if len(fls) == 0 {
+		return nil
+	}
+	var fd []FoldRegion
+	for _, fr := range FoldRegions(tb.Lines) {
+		for _, fl := range fls {
+			if fr.Start == fl {
+				fd = append(fd, fr)
+				break
+			}
+		}
+	}
+	return fd
+}
+
+// SetFolded sets the fold state of the regions starting at given lines in
+// given buffer, saved with the project
+func (ge *Gide) SetFolded(tb *giv.TextBuf, fold bool, lns ...int) {
+	if ge.Prefs.Folds == nil {
+		ge.Prefs.Folds = make(map[string][]int)
+	}
+	fp := ge.FoldPath(tb)
+	fls := ge.Prefs.Folds[fp]
+	for _, ln := range lns {
+		idx := -1
+		for i, fl := range fls {
+			if fl == ln {
+				idx = i
+				break
+			}
+		}
+		switch {
+		case fold && idx < 0:
+			fls = append(fls, ln)
+		case !fold && idx >= 0:
+			fls = append(fls[:idx], fls[idx+1:]...)
+		}
+	}
+	sort.Ints(fls)
+	if len(fls) == 0 {
+		delete(ge.Prefs.Folds, fp)
+	} else {
+		ge.Prefs.Folds[fp] = fls
+	}
+	ge.Prefs.Changed = true
+}
+
+// FoldToggle folds or unfolds the innermost foldable region containing the
+// cursor in the active view
+func (ge *Gide) FoldToggle() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	fr := FoldRegionAt(FoldRegions(tv.Buf.Lines), tv.CursorPos.Ln)
+	if fr == nil {
+		ge.SetStatus("No foldable region at cursor")
+		return
+	}
+	fold := true
+	for _, fd := range ge.FoldedRegions(tv.Buf) {
+		if fd.Start == fr.Start {
+			fold = false
+			break
+		}
+	}
+	ge.SetFolded(tv.Buf, fold, fr.Start)
+	if fold {
+		tv.SetCursorShow(giv.TextPos{Ln: fr.Start})
+		ge.SetStatus(fmt.Sprintf("Folded %v: lines %d-%d", fr.Kind, fr.Start+2, fr.End+1))
+	} else {
+		ge.SetStatus(fmt.Sprintf("Unfolded %v: lines %d-%d", fr.Kind, fr.Start+2, fr.End+1))
+	}
+	ge.FoldShow(tv)
+}
+
+// FoldAll folds all the top-level foldable regions in the active view
+func (ge *Gide) FoldAll() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	var lns []int
+	end := -1
+	for _, fr := range FoldRegions(tv.Buf.Lines) {
+		if fr.Start > end {
+			lns = append(lns, fr.Start)
+			end = fr.End
+		}
+	}
+	ge.SetFolded(tv.Buf, true, lns...)
+	if fr := FoldRegionAt(ge.FoldedRegions(tv.Buf), tv.CursorPos.Ln); fr != nil {
+		tv.SetCursorShow(giv.TextPos{Ln: fr.Start})
+	}
+	ge.SetStatus(fmt.Sprintf("Folded %d regions", len(lns)))
+	ge.FoldShow(tv)
+}
+
+// UnfoldAll unfolds all the folded regions in the active view
+func (ge *Gide) UnfoldAll() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	fls := append([]int{}, ge.Prefs.Folds[ge.FoldPath(tv.Buf)]...)
+	ge.SetFolded(tv.Buf, false, fls...)
+	ge.SetStatus("Unfolded all regions")
+	ge.FoldShow(tv)
+}
+
+// FoldShow marks the folded regions in given view by highlighting the
+// folded lines
+func (ge *Gide) FoldShow(tv *giv.TextView) {
+	if tv.Buf == nil {
+		return
+	}
+	updt := tv.UpdateStart()
+	tv.Highlights = tv.Highlights[:0]
+	for _, fr := range ge.FoldedRegions(tv.Buf) {
+		st := giv.TextPos{Ln: fr.Start + 1}
+		en := giv.TextPos{Ln: fr.End, Ch: len(tv.Buf.Lines[fr.End])}
+		tv.Highlights = append(tv.Highlights, giv.TextRegion{Start: st, End: en})
+	}
+	tv.UpdateEnd(updt)
+}
+
+// FoldSkip moves the cursor in given view past any folded region it has
+// moved into -- down past the end if moving down, otherwise up to the
+// start -- called when the cursor moves
+func (ge *Gide) FoldSkip(tv *giv.TextView) {
+	vi := ge.TextViewIndex(tv)
+	if vi < 0 || tv.Buf == nil {
+		return
+	}
+	ln := tv.CursorPos.Ln
+	prv := ge.foldLns[vi]
+	ge.foldLns[vi] = ln
+	fr := FoldRegionAt(ge.FoldedRegions(tv.Buf), ln)
+	if fr == nil || !fr.Hides(ln) {
+		return
+	}
+	to := fr.Start
+	if ln > prv && fr.End+1 < len(tv.Buf.Lines) {
+		to = fr.End + 1
+	}
+	ge.foldLns[vi] = to
+	tv.SetCursorShow(giv.TextPos{Ln: to})
+}
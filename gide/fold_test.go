@@ -0,0 +1,51 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFoldRegions(t *testing.T) {
+	src := `// Foo does things
+// in two lines
+func Foo() {
+	s := "{"
+	if true {
+		x := ` + "`" + `
+}` + "`" + `
+	}
+}
+
+//region helpers
+var a = 1 /* { */
+//endregion`
+	var lines [][]rune
+	for _, l := range strings.Split(src, "\n") {
+		lines = append(lines, []rune(l))
+	}
+	frs := FoldRegions(lines)
+	want := []FoldRegion{
+		{Start: 0, End: 1, Kind: "comment"},
+		{Start: 2, End: 8, Kind: "block"},
+		{Start: 4, End: 7, Kind: "block"},
+		{Start: 10, End: 12, Kind: "region", Name: "helpers"},
+	}
+	if len(frs) != len(want) {
+		t.Fatalf("got %d regions: %+v", len(frs), frs)
+	}
+	for i := range want {
+		if frs[i] != want[i] {
+			t.Errorf("region %d: got %+v want %+v", i, frs[i], want[i])
+		}
+	}
+	if fr := FoldRegionAt(frs, 5); fr == nil || fr.Start != 4 {
+		t.Errorf("innermost region at 5: got %+v", fr)
+	}
+	if fr := FoldRegionAt(frs, 9); fr != nil {
+		t.Errorf("no region at 9: got %+v", fr)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/giv"
+)
+
+// LineEdit replaces lines [St, Ed) of a text with Lines
+type LineEdit struct {
+	St, Ed int
+	Lines  []string
+}
+
+// LineEdits returns the edits that turn lines a into lines b, in order,
+// from a diff of them -- only the lines that differ are replaced, so that
+// positions in the others are kept
+func LineEdits(a, b []string) []LineEdit {
+	var eds []LineEdit
+	ai, bi := 0, 0
+	add := func(ae, be int) {
+		if ae > ai || be > bi {
+			eds = append(eds, LineEdit{St: ai, Ed: ae, Lines: b[bi:be]})
+		}
+		ai, bi = ae+1, be+1
+	}
+	for _, mt := range diffMatches(a, b) {
+		add(mt[0], mt[1])
+	}
+	add(len(a), len(b))
+	return eds
+}
+
+// Region returns the region of text of lines a to delete for the edit, and
+// the text to insert in its place
+func (le *LineEdit) Region(a []string) (st, ed giv.TextPos, txt string) {
+	join := strings.Join(le.Lines, "\n")
+	ins := len(le.Lines) > 0
+	lnEnd := func(ln int) giv.TextPos {
+		return giv.TextPos{Ln: ln, Ch: len([]rune(a[ln]))}
+	}
+	switch {
+	case le.Ed < len(a): // whole lines, up to the start of the next one
+		st, ed = giv.TextPos{Ln: le.St}, giv.TextPos{Ln: le.Ed}
+		if ins {
+			txt = join + "\n"
+		}
+	case le.St > 0: // at the end, from the end of the line before
+		st = lnEnd(le.St - 1)
+		ed = st
+		if le.Ed > le.St {
+			ed = lnEnd(le.Ed - 1)
+		}
+		if ins {
+			txt = "\n" + join
+		}
+	default: // all of it
+		if le.Ed > 0 {
+			ed = lnEnd(le.Ed - 1)
+		}
+		txt = join
+	}
+	return
+}
+
+// FormatText runs the given formatter command and args on src, which it
+// reads on stdin, in given dir, and returns the formatted text it writes to
+// stdout
+func FormatText(args []string, dir string, src []byte) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no formatter command")
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		if args[0] != "goimports" {
+			return nil, fmt.Errorf("formatter %v not found", args[0])
+		}
+		args = []string{"gofmt"} // goimports is not installed everywhere
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	CmdBuildEnv.SetCmdEnv(cmd)
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(src)
+	var out, errb bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errb
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(errb.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("%v: %v", args[0], msg)
+	}
+	return out.Bytes(), nil
+}
+
+// FormatterFor returns the formatter command and args for the language of
+// given file, preferring the main language of the project if the file has
+// several, with any variables in them bound for the file -- nil if none
+func (ge *Gide) FormatterFor(fpath string) []string {
+	var fmtr string
+	for _, lr := range LangsForFilename(fpath) {
+		if lr.Formatter == "" {
+			continue
+		}
+		if fmtr == "" || lr.Name == string(ge.Prefs.MainLang) {
+			fmtr = lr.Formatter
+		}
+	}
+	if fmtr == "" {
+		return nil
+	}
+	SetArgVarVals(&ArgVarVals, fpath, &ge.Prefs, nil)
+	flds := strings.Fields(fmtr)
+	for i := range flds {
+		flds[i] = BindArgVars(flds[i])
+	}
+	return flds
+}
+
+// FormatBuf formats given buffer with the formatter for its language, by
+// replacing only the lines it changes, so that the cursor position, undo
+// and other positions in the rest of the buffer are kept -- returns false
+// if there is no formatter for it, and an error if the formatter failed
+func (ge *Gide) FormatBuf(tb *giv.TextBuf) (bool, error) {
+	fpath := string(tb.Filename)
+	fcmd := ge.FormatterFor(fpath)
+	if len(fcmd) == 0 {
+		return false, nil
+	}
+	src := tb.LinesToBytesCopy()
+	out, err := FormatText(fcmd, filepath.Dir(fpath), src)
+	if err != nil {
+		return true, err
+	}
+	if bytes.Equal(out, src) {
+		return true, nil
+	}
+	a := make([]string, len(tb.Lines))
+	for i, l := range tb.Lines {
+		a[i] = string(l)
+	}
+	eds := LineEdits(a, strings.Split(string(out), "\n"))
+	var views []*giv.TextView
+	var curs []giv.TextPos
+	for _, tv := range ge.PaneViews {
+		if tv.Buf == tb {
+			views = append(views, tv)
+			curs = append(curs, tv.CursorPos)
+		}
+	}
+	for i := len(eds) - 1; i >= 0; i-- { // from the end, so earlier positions stay valid
+		st, ed, txt := eds[i].Region(a)
+		if ed != st {
+			tb.DeleteText(st, ed, true, true)
+			del := cursorEdit{Delete: true, St: st, Ed: ed}
+			for j := range curs {
+				curs[j] = del.Adjust(curs[j])
+			}
+		}
+		if txt != "" {
+			tb.InsertText(st, []byte(txt), true, true)
+			ins := cursorEdit{St: st, Ed: TextInsertEnd(st, []byte(txt))}
+			for j := range curs {
+				curs[j] = ins.Adjust(curs[j])
+			}
+		}
+	}
+	for i, tv := range views {
+		tv.SetCursorShow(curs[i])
+	}
+	ge.AuditNote(tb, "formatted")
+	return true, nil
+}
+
+// FormatActiveView formats the buffer of the active view with the formatter
+// for its language (see Edit Langs in Preferences)
+func (ge *Gide) FormatActiveView() {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil || tv.IsInactive() {
+		return
+	}
+	ok, err := ge.FormatBuf(tv.Buf)
+	switch {
+	case err != nil:
+		ge.SetStatus("Format failed: " + err.Error())
+	case !ok:
+		ge.SetStatus("No formatter for this language -- set one in Preferences Edit Langs")
+	default:
+		ge.SetStatus("Formatted")
+	}
+}
+
+// FormatOnSave formats given buffer before it is saved, if FormatOnSave is
+// set in the editor preferences, or in the save hooks of its language --
+// returns the error if the formatter failed, in which case the buffer is
+// saved as it is
+func (ge *Gide) FormatOnSave(tb *giv.TextBuf) error {
+	if !ge.SaveHooksFor(tb).Format {
+		return nil
+	}
+	_, err := ge.FormatBuf(tb)
+	return err
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/giv"
+)
+
+// formatTestApply applies the edits to lines a, as FormatBuf does to a buffer
+func formatTestApply(a []string, eds []LineEdit) string {
+	txt := []rune(strings.Join(a, "\n"))
+	off := func(p giv.TextPos) int {
+		o := 0
+		for ln := 0; ln < p.Ln; ln++ {
+			o += len([]rune(a[ln])) + 1
+		}
+		return o + p.Ch
+	}
+	for i := len(eds) - 1; i >= 0; i-- {
+		st, ed, ins := eds[i].Region(a)
+		txt = append(append(append([]rune{}, txt[:off(st)]...), []rune(ins)...), txt[off(ed):]...)
+	}
+	return string(txt)
+}
+
+func TestLineEdits(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"a\nb\nc\n", "a\nB\nc\n"},
+		{"a\nb\nc", "a\nb\nc\nd"},
+		{"a\nb\nc", "a"},
+		{"a\nb", "x\ny\nz"},
+		{"x\na\nb\n", "a\nb\n"},
+		{"a\n\n\nb\n", "a\n\nb\n"},
+		{"a", ""},
+	}
+	for _, ts := range tests {
+		a, b := strings.Split(ts.a, "\n"), strings.Split(ts.b, "\n")
+		if got := formatTestApply(a, LineEdits(a, b)); got != ts.b {
+			t.Errorf("LineEdits %q -> %q gave %q", ts.a, ts.b, got)
+		}
+	}
+	a := strings.Split("a\nb\nc\nd\n", "\n")
+	eds := LineEdits(a, strings.Split("a\nB\nc\nd\n", "\n"))
+	if len(eds) != 1 || eds[0].St != 1 || eds[0].Ed != 2 {
+		t.Errorf("LineEdits replaced more than the changed line: %v", eds)
+	}
+}
+
+func TestFormatText(t *testing.T) {
+	if _, err := exec.LookPath("gofmt"); err != nil {
+		t.Skip("gofmt not found")
+	}
+	out, err := FormatText([]string{"gofmt"}, ".", []byte("package x\nfunc  f( ) {\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "package x\n\nfunc f() {\n}\n"; string(out) != want {
+		t.Errorf("FormatText: %q, want %q", out, want)
+	}
+	if _, err := FormatText([]string{"gofmt"}, ".", []byte("package x\nfunc {")); err == nil {
+		t.Errorf("FormatText: no error for bad code")
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/giv"
+)
+
+// Generator is a code generator that is run whenever one of its source
+// files is saved, e.g., templ, stringer or mockgen, to refresh the files it
+// generates from it
+type Generator struct {
+	Name    string `desc:"name of the generator, e.g., templ -- shown in the status, and as the source of the errors it reports"`
+	Sources string `desc:"space-separated globs of the source files it generates from, e.g., *.templ -- globs with a / are relative to the project root, others match the file name in any directory, as in .editorconfig"`
+	Cmd     string `width:"60" desc:"command to run, with its args, in the directory of the saved file -- can use the file variables of commands, e.g., {FileName}, {FilePath} and {FileNameNoExt}"`
+	Outputs string `desc:"space-separated files it generates, relative to the directory of the saved file, with the same variables, e.g., {FileNameNoExt}_templ.go -- open ones are reloaded after it runs"`
+	Off     bool   `desc:"do not run the generator when its source files are saved"`
+}
+
+// Generators are the code generators of a project
+type Generators []Generator
+
+// Matches returns true if the file at given slash-separated path, relative
+// to the project root, is one of the sources of the generator
+func (gn *Generator) Matches(rel string) bool {
+	for _, g := range strings.Fields(gn.Sources) {
+		re, err := ECGlobRegexp(g)
+		if err == nil && re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// For returns the generators that are run when the file at given
+// slash-separated path, relative to the project root, is saved
+func (gs Generators) For(rel string) []Generator {
+	var out []Generator
+	for _, gn := range gs {
+		if !gn.Off && gn.Cmd != "" && gn.Matches(rel) {
+			out = append(out, gn)
+		}
+	}
+	return out
+}
+
+// GenBind returns given string with the variables in it replaced by their
+// values in given map
+func GenBind(s string, vals map[string]string) string {
+	var rp []string
+	for k, v := range vals {
+		rp = append(rp, k, v)
+	}
+	return strings.NewReplacer(rp...).Replace(s)
+}
+
+// GenArgs returns the command and args of given generator command, with the
+// variables in them replaced by their values in given map -- each arg is
+// bound after splitting, so that file names with spaces stay one arg
+func GenArgs(cmd string, vals map[string]string) []string {
+	fs := strings.Fields(cmd)
+	for i, f := range fs {
+		fs[i] = GenBind(f, vals)
+	}
+	return fs
+}
+
+// GenFailErrors returns the errors of a generator that failed with given
+// output for given source file: those in the output, as for other
+// commands, or else an error at the start of the source file with the
+// first line of the output, so the failure is always shown
+func GenFailErrors(out []byte, dir, src, name string, err error) []CmdError {
+	errs := ParseCmdErrors(out, dir)
+	if len(errs) > 0 {
+		return errs
+	}
+	msg := err.Error()
+	for _, l := range bytes.Split(out, []byte("\n")) {
+		if l := strings.TrimSpace(string(l)); l != "" {
+			msg = l
+			break
+		}
+	}
+	return []CmdError{{Path: src, Line: 1, Msg: name + " failed: " + msg}}
+}
+
+// RunGenerators runs the generators of the project for given buffer, just
+// saved, in the background -- when one fails, its errors are shown as
+// diagnostics until it runs without error, and when it succeeds, its
+// outputs that are open are reloaded
+func (ge *Gide) RunGenerators(tb *giv.TextBuf) {
+	fpath := string(tb.Filename)
+	if fpath == "" || len(ge.Prefs.Generators) == 0 {
+		return
+	}
+	rel, err := filepath.Rel(string(ge.ProjRoot), fpath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return
+	}
+	gens := ge.Prefs.Generators.For(filepath.ToSlash(rel))
+	if len(gens) == 0 {
+		return
+	}
+	ge.genMu.Lock()
+	if ge.genRunning[fpath] {
+		ge.genMu.Unlock()
+		return // its outputs will be refreshed by the run going on now
+	}
+	if ge.genRunning == nil {
+		ge.genRunning = make(map[string]bool)
+	}
+	ge.genRunning[fpath] = true
+	ge.genMu.Unlock()
+	var vals map[string]string
+	SetArgVarVals(&vals, fpath, &ge.Prefs, nil)
+	go func() {
+		for _, gn := range gens {
+			ge.runGenerator(&gn, fpath, vals)
+		}
+		ge.genMu.Lock()
+		delete(ge.genRunning, fpath)
+		ge.genMu.Unlock()
+		ge.RunOnGui(ge.WatchCheck) // reload the outputs
+	}()
+}
+
+// runGenerator runs given generator for given source file, with given
+// values of the variables in its command and outputs
+func (ge *Gide) runGenerator(gn *Generator, src string, vals map[string]string) {
+	args := GenArgs(gn.Cmd, vals)
+	if len(args) == 0 {
+		return
+	}
+	dir := filepath.Dir(src)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	ge.Prefs.BuildEnv.SetCmdEnv(cmd)
+	ge.SetStatus(fmt.Sprintf("Generating with %v: %v", gn.Name, strings.Join(args, " ")))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		ge.genDiags(gn.Name, GenFailErrors(out, dir, src, gn.Name, err))
+		ge.SetStatus(fmt.Sprintf("%v failed on %v: see the Problems panel", gn.Name, filepath.Base(src)))
+		return
+	}
+	ge.genDiags(gn.Name, nil)
+	ge.Files.UpdateNewFile(src) // update everything in dir, for new outputs
+	for _, o := range GenArgs(gn.Outputs, vals) {
+		if !filepath.IsAbs(o) {
+			o = filepath.Join(dir, o)
+		}
+		if filepath.Dir(o) != dir {
+			ge.Files.UpdateNewFile(o)
+		}
+	}
+	ge.SetStatus(fmt.Sprintf("Generated with %v from %v", gn.Name, filepath.Base(src)))
+}
+
+// genDiags records given errors of the generator with given name, for
+// showing them as diagnostics, as DiagCmdDone does for commands, or clears
+// them if there are none -- then updates the display of the open files
+func (ge *Gide) genDiags(name string, errs []CmdError) {
+	src := "generate " + name
+	if ge.cmdDiags == nil {
+		ge.cmdDiags = make(map[string][]CmdError)
+	}
+	_, had := ge.cmdDiags[src]
+	if len(errs) == 0 {
+		if !had {
+			return
+		}
+		delete(ge.cmdDiags, src)
+	} else {
+		ge.cmdDiags[src] = errs
+	}
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil {
+			ge.HiMarkupBuf(ond.Buf)
+			ond.Buf.RefreshViews()
+		}
+	}
+	ge.problemsRefresh()
+}
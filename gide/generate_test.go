@@ -0,0 +1,66 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGeneratorsFor(t *testing.T) {
+	gs := Generators{
+		{Name: "templ", Sources: "*.templ", Cmd: "templ generate -f {FileName}"},
+		{Name: "stringer", Sources: "gide/keyfun.go gide/argvars.go", Cmd: "go generate {FileName}"},
+		{Name: "mockgen", Sources: "*.go", Cmd: "mockgen", Off: true},
+		{Name: "empty", Sources: "*.templ"},
+	}
+	cases := []struct {
+		rel  string
+		want []string
+	}{
+		{"views/home.templ", []string{"templ"}},
+		{"home.templ", []string{"templ"}},
+		{"gide/keyfun.go", []string{"stringer"}},
+		{"other/gide/keyfun.go", nil},
+		{"gide/gide.go", nil},
+	}
+	for _, c := range cases {
+		var got []string
+		for _, gn := range gs.For(c.rel) {
+			got = append(got, gn.Name)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%v: got %v, want %v", c.rel, got, c.want)
+		}
+	}
+}
+
+func TestGenArgs(t *testing.T) {
+	vals := map[string]string{"{FileName}": "my file.templ", "{FileNameNoExt}": "my file", "{FileDir}": "views", "{FileDirPath}": "/p/views"}
+	got := GenArgs("templ generate -f {FileName} -o {FileDirPath}/{FileNameNoExt}_templ.go", vals)
+	want := []string{"templ", "generate", "-f", "my file.templ", "-o", "/p/views/my file_templ.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := GenArgs("  ", vals); len(got) != 0 {
+		t.Errorf("empty: got %q", got)
+	}
+}
+
+func TestGenFailErrors(t *testing.T) {
+	errs := GenFailErrors([]byte("views/home.templ:3:5: unexpected token\n"), "/p", "/p/views/home.templ", "templ", errors.New("exit status 1"))
+	if len(errs) != 1 || errs[0] != (CmdError{Path: "/p/views/home.templ", Line: 3, Col: 5, Msg: "unexpected token"}) {
+		t.Errorf("parsed: got %+v", errs)
+	}
+	errs = GenFailErrors([]byte("\n  stringer: no type Foo\nmore\n"), "/p", "/p/a.go", "stringer", errors.New("exit status 1"))
+	if len(errs) != 1 || errs[0] != (CmdError{Path: "/p/a.go", Line: 1, Msg: "stringer failed: stringer: no type Foo"}) {
+		t.Errorf("unparsed: got %+v", errs)
+	}
+	errs = GenFailErrors(nil, "/p", "/p/a.go", "mockgen", errors.New("exec: \"mockgen\": executable file not found in $PATH"))
+	if len(errs) != 1 || errs[0].Msg != "mockgen failed: exec: \"mockgen\": executable file not found in $PATH" {
+		t.Errorf("no output: got %+v", errs)
+	}
+}
@@ -11,13 +11,11 @@ Derived classes can extend the functionality for specific domains.
 package gide
 
 import (
-	"bytes"
 	"fmt"
 	"html"
 	"log"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -65,9 +63,73 @@ type Gide struct {
 	CmdBufs           map[string]*giv.TextBuf `json:"-" desc:"the command buffers for commands run in this project"`
 	CmdHistory        CmdNames                `json:"-" desc:"history of commands executed in this session"`
 	RunningCmds       CmdRuns                 `json:"-" xml:"-" desc:"currently running commands in this project"`
-	Prefs             ProjPrefs               `desc:"preferences for this project -- this is what is saved in a .gide project file"`
-	KeySeq1           key.Chord               `desc:"first key in sequence if needs2 key pressed"`
-	UpdtMu            sync.Mutex              `desc:"mutex for protecting overall updates to Gide"`
+	Lsp               LspClients              `json:"-" xml:"-" view:"-" desc:"language server clients for this project"`
+	NavHist           NavHistory              `json:"-" xml:"-" view:"-" desc:"back / forward navigation history for jumps such as go to definition"`
+	Ops               OpHistory               `json:"-" xml:"-" view:"-" desc:"history of the operations that changed several files, e.g., renames and replace alls, for undoing and redoing them as a unit"`
+	CmdErrs           []CmdError              `json:"-" xml:"-" view:"-" desc:"errors parsed from the output of the last failed command, for NextError / PrevError"`
+	CmdErrIdx         int                     `json:"-" xml:"-" view:"-" desc:"index of the current error in CmdErrs"`
+	AsmErrs           []CmdError              `json:"-" xml:"-" view:"-" desc:"findings of the last go vet check of assembly files, highlighted in their views"`
+	Cursors           MultiCursors            `json:"-" xml:"-" view:"-" desc:"additional cursors in the active view, where edits are also made"`
+	Snippet           SnippetSession          `json:"-" xml:"-" view:"-" desc:"the expanded snippet whose tab stops are being visited"`
+	RectBuf           []string                `json:"-" xml:"-" view:"-" desc:"last rectangle of text copied or killed, one string per line, for RectYank"`
+	PaneViews         []*giv.TextView         `json:"-" xml:"-" view:"-" desc:"all the text views: the NTextViews main ones, then any panes split off from them"`
+	Dbg               *DlvClient              `json:"-" xml:"-" view:"-" desc:"the running debugger, if debugging"`
+	DbgRunning        bool                    `json:"-" xml:"-" view:"-" desc:"true while the program being debugged is running, false when stopped"`
+	Follow            bool                    `json:"-" xml:"-" desc:"if true, and both text views are viewing the same buffer, the other view follows the cursor in the active one, staying FollowLines ahead (or behind) of it"`
+	FollowLines       int                     `json:"-" xml:"-" desc:"number of lines that the other view is offset from the active one in Follow mode"`
+	ScrollLock        bool                    `json:"-" xml:"-" desc:"if true, the two text views scroll together: moving in one moves the other by the same number of lines -- for comparing similar files, or code beside its generated output"`
+	inFollow          bool
+	lockLns           []int
+	foldLns           []int
+	brackHls          map[*giv.TextView][]giv.TextRegion
+	asmHls            map[*giv.TextView][]giv.TextRegion
+	hiStates          map[*giv.TextBuf]*HiState
+	semStates         map[*giv.TextBuf]*SemState
+	spellStates       map[*giv.TextBuf]*SpellState
+	undoHists         map[*giv.TextBuf]*UndoHistState
+	audits            map[*giv.TextBuf]*AuditState
+	recovered         map[string]string
+	recoverMu         sync.Mutex
+	recoverGen        int
+	recoverStop       chan struct{}
+	killLast          *killState
+	curOp             *opRecord
+	wsShow            WsShow
+	tour              *tourRun
+	symIdx            *SymIndex
+	projIdx           *ProjIndex
+	crumbs            *crumbState
+	panelAlt          AltPair
+	autoHidden        map[int]float32
+	diagStates        map[*giv.TextBuf]*DiagState
+	cmdDiags          map[string][]CmdError
+	envBanner         string
+	diffSaving        map[*giv.TextBuf][]func(err error)
+	saveWaits         []func()
+	saveMu            sync.Mutex
+	yankLast          *yankState
+	lineEnds          map[*giv.TextBuf]LineEnds
+	encodings         map[*giv.TextBuf]string
+	edConfigs         map[*giv.TextBuf]*EditorConfig
+	diskStates        map[*giv.TextBuf]*diskState
+	watchMu           sync.Mutex
+	watchStop         chan struct{}
+	genRunning        map[string]bool
+	genMu             sync.Mutex
+	synSel            *synSelState
+	bufTabs           *BufTabs
+	bufTabsKey        string
+	bufTabDrag        string
+	regPending        string
+	isearch           *isearchState
+	isearchHi         []giv.TextRegion
+	power             PowerState
+	powerStop         chan struct{}
+	hiMu              sync.Mutex
+	Prefs             ProjPrefs  `desc:"preferences for this project -- this is what is saved in a .gide project file"`
+	SingleFile        bool       `json:"-" desc:"if true, this is a lightweight window for editing a single file -- no file tree is shown, and nothing is indexed or saved as a project -- use PromoteToProj to turn it into a full project"`
+	KeySeq1           key.Chord  `desc:"first key in sequence if needs2 key pressed"`
+	UpdtMu            sync.Mutex `desc:"mutex for protecting overall updates to Gide"`
 }
 
 var KiT_Gide = kit.Types.AddType(&Gide{}, nil)
@@ -81,6 +143,21 @@ func (ge *Gide) UpdateFiles() {
 	ge.Files.OpenPath(string(ge.ProjRoot))
 }
 
+// SingleFileNode sets the files of the project to just the file of given
+// name in its root, for single-file mode, without reading the rest of the
+// directory -- returns its node
+func (ge *Gide) SingleFileNode(fnm string) *giv.FileNode {
+	ft := &ge.Files
+	ft.FRoot = ft
+	ft.FPath = ge.ProjRoot
+	ft.SetName(filepath.Base(string(ge.ProjRoot)))
+	ft.DeleteChildren(true)
+	fn := ft.AddNewChild(ft.NodeType, fnm).Embed(giv.KiT_FileNode).(*giv.FileNode)
+	fn.FRoot = ft
+	fn.SetNodePath(filepath.Join(string(ge.ProjRoot), fnm))
+	return fn
+}
+
 func (ge *Gide) IsEmpty() bool {
 	return ge.ProjRoot == ""
 }
@@ -107,6 +184,9 @@ func (ge *Gide) OpenPath(path gi.FileName) (*gi.Window, *Gide) {
 	}
 	ge.Defaults()
 	root, pnm, fnm, ok := ProjPathParse(string(path))
+	if ok && fnm == "" && ProjPull(filepath.Join(root, pnm+".gide")) { // from the prefs storage
+		return ge.OpenProj(gi.FileName(filepath.Join(root, pnm+".gide")))
+	}
 	if ok {
 		os.Chdir(root)
 		SavedPaths.AddPath(root, gi.Prefs.SavedPathsMax)
@@ -133,6 +213,51 @@ func (ge *Gide) OpenPath(path gi.FileName) (*gi.Window, *Gide) {
 	return ge.ParentWindow(), ge
 }
 
+// OpenFile opens given file in a lightweight single-file mode, without the
+// file tree or any of the project-level analysis, for quick edits -- use
+// PromoteToProj to turn it into a full project.  If path is a directory,
+// it is opened as a regular project via OpenPath.
+func (ge *Gide) OpenFile(path gi.FileName) (*gi.Window, *Gide) {
+	if !ge.IsEmpty() {
+		return NewGideFile(string(path))
+	}
+	root, _, fnm, ok := ProjPathParse(string(path))
+	if !ok {
+		return ge.ParentWindow(), ge
+	}
+	if fnm == "" {
+		return ge.OpenPath(path)
+	}
+	ge.Defaults()
+	ge.SingleFile = true
+	ge.Prefs.Splits = []float32{0, 1, 0, 0, 0}
+	ge.ProjRoot = gi.FileName(root)
+	ge.Prefs.ProjRoot = ge.ProjRoot
+	ge.SetName(fnm)
+	ge.UpdateProj()
+	win := ge.ParentWindow()
+	if win != nil {
+		winm := "gide-file-" + fnm
+		win.SetName(winm)
+		win.SetTitle(winm)
+	}
+	ge.NextViewFileNode(ge.SingleFileNode(fnm))
+	return ge.ParentWindow(), ge
+}
+
+// PromoteToProj turns a single-file window (see OpenFile) into a full
+// project for the directory containing the file, keeping the file open
+func (ge *Gide) PromoteToProj() {
+	if !ge.SingleFile {
+		return
+	}
+	root := ge.ProjRoot
+	ge.SingleFile = false
+	ge.ProjRoot = "" // now empty, so opens in place
+	ge.OpenPath(root)
+	ge.SetStatus("Promoted to project: " + string(root))
+}
+
 // OpenProj opens .gide project file and its settings from given filename, in a standard
 // JSON-formatted file
 func (ge *Gide) OpenProj(filename gi.FileName) (*gi.Window, *Gide) {
@@ -150,6 +275,7 @@ func (ge *Gide) OpenProj(filename gi.FileName) (*gi.Window, *Gide) {
 		ge.SetName(pnm)
 		ge.ApplyPrefs()
 		ge.UpdateProj()
+		ge.RestorePanes()
 		win := ge.ParentWindow()
 		if win != nil {
 			winm := "gide-" + pnm
@@ -205,7 +331,7 @@ func (ge *Gide) SaveProj() {
 // standard JSON-formatted file, only if it already exists -- returns true if saved
 // saveAllFiles indicates if user should be prompted for saving all files
 func (ge *Gide) SaveProjIfExists(saveAllFiles bool) bool {
-	if ge.Prefs.ProjFilename == "" {
+	if ge.SingleFile || ge.Prefs.ProjFilename == "" {
 		return false
 	}
 	if _, err := os.Stat(string(ge.Prefs.ProjFilename)); os.IsNotExist(err) {
@@ -227,6 +353,7 @@ func (ge *Gide) SaveProjAs(filename gi.FileName, saveAllFiles bool) bool {
 	ge.ProjFilename = ge.Prefs.ProjFilename
 	ge.GrabPrefs()
 	ge.Prefs.SaveJSON(filename)
+	ProjPush(string(filename))
 	gi.SaveSpellModel()
 	ge.Changed = false
 	if saveAllFiles {
@@ -236,6 +363,7 @@ func (ge *Gide) SaveProjAs(filename gi.FileName, saveAllFiles bool) bool {
 }
 
 // SaveAllCheck -- check if any files have not been saved, and prompt to save them
+// in one dialog listing them all (see UnsavedDialog)
 // returns true if there were unsaved files, false otherwise.
 // cancelOpt presents an option to cancel current command, in which case function is not called.
 // if function is passed, then it is called in all cases except if the user selects cancel.
@@ -247,34 +375,27 @@ func (ge *Gide) SaveAllCheck(cancelOpt bool, fun func(ge *Gide)) bool {
 		}
 		return false
 	}
-	opts := []string{"Save All", "Don't Save"}
-	if cancelOpt {
-		opts = []string{"Save All", "Don't Save", "Cancel Command"}
-	}
-	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "There are Unsaved Files",
-		Prompt: fmt.Sprintf("In Project: %v There are <b>%v</b> opened files with <b>unsaved changes</b> -- do you want to save all?", ge.Nm, nch)}, opts,
-		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-			if sig != 2 {
-				if sig == 0 {
-					ge.SaveAllOpenNodes()
-				}
-				if fun != nil {
-					fun(ge)
-				}
-			}
-		})
-	return true
+	return ge.UnsavedDialog("There are Unsaved Files", fmt.Sprintf("In Project: %v", ge.Nm), cancelOpt, func(ch UnsavedChoice) {
+		if ch != UnsavedCancel && fun != nil {
+			fun(ge)
+		}
+	})
 }
 
-// UpdateProj does full update to current proj
+// UpdateProj does full update to current proj -- in single-file mode, the
+// files of the project are not read, as there is no file tree
 func (ge *Gide) UpdateProj() {
 	mods, updt := ge.StdConfig()
 	if !mods {
 		updt = ge.UpdateStart()
 	}
-	ge.UpdateFiles()
+	if !ge.SingleFile {
+		ge.UpdateFiles()
+	}
 	ge.ConfigSplitView()
 	ge.ConfigToolbar()
+	ge.ConfigBufTabBar()
+	ge.ConfigBreadcrumbs()
 	ge.ConfigStatusBar()
 	ge.SetStatus("just updated")
 	if mods {
@@ -394,25 +515,22 @@ func (ge *Gide) ConfigTextBuf(tb *giv.TextBuf) {
 	langs := LangsForExt(ext)
 	if len(langs) > 0 {
 		ln := langs[0].Name
+		if langs[0].TabSize > 0 {
+			tb.Opts.TabSize = langs[0].TabSize
+			tb.Opts.SpaceIndent = langs[0].SpaceIndent
+		}
 		// todo: completer funcs should be stored in language struct
 		switch ln {
-		case "Go":
-			if ge.Prefs.Editor.Completion {
-				tb.SetCompleter(tb, giv.CompleteGo, giv.CompleteGoEdit)
-			}
-		default:
-			//if ge.Prefs.Editor.Completion {
-		//	tb.SetCompleter(tb, giv.CompleteText, giv.CompleteTextEdit)
-		//}
 		case "Markdown":
 			if ge.Prefs.Editor.SpellCorrect {
 				tb.SetSpellCorrect(tb, giv.SpellCorrectEdit)
 			}
-			//tb.SetCompleter(tb, giv.CompleteText, giv.CompleteTextEdit)
 		}
-	} else {
-		//tb.SetCompleter(tb, giv.CompleteText, giv.CompleteTextEdit)
 	}
+	if ge.Prefs.Editor.Completion {
+		tb.SetCompleter(&CompleteCtx{Gide: ge, Buf: tb}, CompleteGide, CompleteGideEdit)
+	}
+	ge.EditorConfigIndent(tb)
 }
 
 // ActiveTextView returns the currently-active TextView
@@ -422,11 +540,10 @@ func (ge *Gide) ActiveTextView() *giv.TextView {
 	return ge.TextViewByIndex(ge.ActiveTextViewIdx)
 }
 
-// TextViewIndex finds index of given textview (0 or 1)
+// TextViewIndex finds index of given textview (0 or 1, or higher for panes
+// split off from them)
 func (ge *Gide) TextViewIndex(av *giv.TextView) int {
-	split := ge.SplitView()
-	for i := 0; i < NTextViews; i++ {
-		tv := split.KnownChild(TextView1Idx + i).KnownChild(0).Embed(giv.KiT_TextView).(*giv.TextView)
+	for i, tv := range ge.PaneViews {
 		if tv.This() == av.This() {
 			return i
 		}
@@ -441,10 +558,8 @@ func (ge *Gide) TextViewForFileNode(fn *giv.FileNode) (*giv.TextView, int, bool)
 		return nil, -1, false
 	}
 	ge.ConfigTextBuf(fn.Buf)
-	split := ge.SplitView()
-	for i := 0; i < NTextViews; i++ {
-		tv := split.KnownChild(TextView1Idx + i).KnownChild(0).Embed(giv.KiT_TextView).(*giv.TextView)
-		if tv != nil && tv.Buf != nil && tv.Buf.This() == fn.Buf.This() && ge.PanelIsOpen(i+TextView1Idx) {
+	for i, tv := range ge.PaneViews {
+		if tv != nil && tv.Buf != nil && tv.Buf.This() == fn.Buf.This() && ge.PaneIsOpen(i) {
 			return tv, i, true
 		}
 	}
@@ -479,6 +594,11 @@ func (ge *Gide) TextViewForFile(fnm gi.FileName) (*giv.TextView, int, bool) {
 func (ge *Gide) SetActiveFilename(fname gi.FileName) {
 	ge.ActiveFilename = fname
 	ge.ActiveLangs = LangNamesForFilename(string(fname))
+	ge.OutlineActive()
+	ge.MarkdownActive()
+	ge.EnvMarkActive()
+	ge.CrumbsUpdate()
+	ge.BufTabsUpdate()
 }
 
 // SetActiveTextView sets the given textview as the active one, and returns its index
@@ -494,6 +614,7 @@ func (ge *Gide) SetActiveTextView(av *giv.TextView) int {
 	if av.Buf != nil {
 		ge.SetActiveFilename(av.Buf.Filename)
 	}
+	ge.OpenNodeTouch(av)
 	ge.SetStatus("")
 	return idx
 }
@@ -501,7 +622,7 @@ func (ge *Gide) SetActiveTextView(av *giv.TextView) int {
 // SetActiveTextViewIdx sets the given view index as the currently-active
 // TextView -- returns that textview
 func (ge *Gide) SetActiveTextViewIdx(idx int) *giv.TextView {
-	if idx < 0 || idx >= NTextViews {
+	if idx < 0 || idx >= len(ge.PaneViews) {
 		log.Printf("Gide SetActiveTextViewIdx: text view index out of range: %v\n", idx)
 		return nil
 	}
@@ -510,6 +631,7 @@ func (ge *Gide) SetActiveTextViewIdx(idx int) *giv.TextView {
 	if av.Buf != nil {
 		ge.SetActiveFilename(av.Buf.Filename)
 	}
+	ge.OpenNodeTouch(av)
 	ge.SetStatus("")
 	av.GrabFocus()
 	return av
@@ -523,8 +645,8 @@ func (ge *Gide) NextTextView() (*giv.TextView, int) {
 	if av.Buf == nil {
 		return av, ge.ActiveTextViewIdx
 	}
-	nxt := (ge.ActiveTextViewIdx + 1) % NTextViews
-	if !ge.PanelIsOpen(nxt + TextView1Idx) {
+	nxt := (ge.ActiveTextViewIdx + 1) % len(ge.PaneViews)
+	if !ge.PaneIsOpen(nxt) {
 		return av, ge.ActiveTextViewIdx
 	}
 	return ge.TextViewByIndex(nxt), nxt
@@ -535,11 +657,24 @@ func (ge *Gide) SaveActiveView() {
 	tv := ge.ActiveTextView()
 	if tv.Buf != nil {
 		if tv.Buf.Filename != "" {
-			tv.Buf.Save()
-			ge.SetStatus("File Saved")
-			fpath, _ := filepath.Split(string(tv.Buf.Filename))
-			ge.Files.UpdateNewFile(fpath) // update everything in dir -- will have removed autosave
-			ge.RunPostCmdsActiveView()
+			tb := tv.Buf
+			ge.EditorConfigSave(tb)
+			ge.SaveCleanup(tb)
+			fmterr := ge.FormatOnSave(tb)
+			ge.SaveBuf(tb, func(cverr error) {
+				switch {
+				case cverr != nil:
+					ge.SetStatus("File Saved, without converting: " + cverr.Error())
+				case fmterr != nil:
+					ge.SetStatus("File Saved, without formatting: " + fmterr.Error())
+				default:
+					ge.SetStatus("File Saved")
+				}
+				fpath, _ := filepath.Split(string(tb.Filename))
+				ge.Files.UpdateNewFile(fpath) // update everything in dir -- will have removed autosave
+				ge.RunPostCmdsActiveView()
+				ge.RunGenerators(tb)
+			})
 		} else {
 			giv.CallMethod(ge, "SaveActiveViewAs", ge.Viewport) // uses fileview
 		}
@@ -558,6 +693,7 @@ func (ge *Gide) SaveActiveViewAs(filename gi.FileName) {
 				ge.SetStatus(fmt.Sprintf("File %v NOT Saved As: %v", ofn, filename))
 				return
 			}
+			ge.BufSaved(tv.Buf)
 			ge.SetStatus(fmt.Sprintf("File %v Saved As: %v", ofn, filename))
 			// ge.RunPostCmdsActiveView() // doesn't make sense..
 			ge.Files.UpdateNewFile(string(filename)) // update everything in dir -- will have removed autosave
@@ -566,6 +702,7 @@ func (ge *Gide) SaveActiveViewAs(filename gi.FileName) {
 				fn := fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode)
 				if fn.Buf != nil {
 					fn.Buf.Revert()
+					ge.BufOpened(fn.Buf)
 				}
 				ge.ViewFileNode(tv, ge.ActiveTextViewIdx, fn)
 			}
@@ -580,6 +717,7 @@ func (ge *Gide) RevertActiveView() {
 	if tv.Buf != nil {
 		ge.ConfigTextBuf(tv.Buf)
 		tv.Buf.Revert()
+		ge.BufOpened(tv.Buf)
 		fpath, _ := filepath.Split(string(tv.Buf.Filename))
 		ge.Files.UpdateNewFile(fpath) // update everything in dir -- will have removed autosave
 	}
@@ -588,20 +726,33 @@ func (ge *Gide) RevertActiveView() {
 // CloseActiveView closes the buffer associated with active view
 func (ge *Gide) CloseActiveView() {
 	tv := ge.ActiveTextView()
-	ond, idx, got := ge.OpenNodeForTextView(tv)
+	ond, _, got := ge.OpenNodeForTextView(tv)
 	if got {
-		ond.Buf.Close(func(canceled bool) {
-			if canceled {
-				ge.SetStatus(fmt.Sprintf("File %v NOT closed", ond.FPath))
-				return
-			}
-			ge.OpenNodes.DeleteIdx(idx)
-			ond.SetClosed()
-			ge.SetStatus(fmt.Sprintf("File %v closed", ond.FPath))
-		})
+		ge.CloseOpenNode(ond)
 	}
 }
 
+// CloseOpenNode closes the buffer of given open node, asking to save it if
+// it has unsaved changes
+func (ge *Gide) CloseOpenNode(ond *giv.FileNode) {
+	ond.Buf.Close(func(canceled bool) {
+		if canceled {
+			ge.SetStatus(fmt.Sprintf("File %v NOT closed", ond.FPath))
+			return
+		}
+		ge.OpenNodes.Delete(ond)
+		delete(ge.undoHists, ond.Buf)
+		delete(ge.audits, ond.Buf)
+		ge.RecoveryRemove(ond.Buf)
+		if lc := ge.LspClientForBuf(ond.Buf); lc != nil {
+			lc.DidClose(string(ond.FPath))
+		}
+		ond.SetClosed()
+		ge.BufTabsUpdate()
+		ge.SetStatus(fmt.Sprintf("File %v closed", ond.FPath))
+	})
+}
+
 // RunPostCmdsActiveView runs any registered post commands on the active view
 // -- returns true if commands were run and file was reverted after that --
 // uses MainLang to disambiguate if multiple languages associated with extension.
@@ -688,6 +839,15 @@ func (ge *Gide) OpenFileNode(fn *giv.FileNode) (bool, error) {
 		ge.ConfigTextBuf(fn.Buf)
 		ge.OpenNodes.Add(fn)
 		fn.SetOpen()
+		ge.BufTabsUpdate()
+		if nw {
+			ge.BufOpened(fn.Buf)
+			ge.LspOpenBuf(fn.Buf)
+			fn.Buf.TextBufSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				gee, _ := recv.Embed(KiT_Gide).(*Gide)
+				gee.TextBufSig(send.Embed(giv.KiT_TextBuf).(*giv.TextBuf), giv.TextBufSignals(sig), data)
+			})
+		}
 	}
 	return nw, err
 }
@@ -698,6 +858,16 @@ func (ge *Gide) ViewFileNode(tv *giv.TextView, vidx int, fn *giv.FileNode) {
 	if fn.IsDir() {
 		return
 	}
+	if fn.Buf == nil { // binary files are shown instead of opened
+		switch {
+		case IsImageFile(fn) && !IsSVGFile(string(fn.FPath)):
+			ge.ViewImage(gi.FileName(fn.FPath))
+			return
+		case FileIsBinary(string(fn.FPath)):
+			ge.ViewHex(gi.FileName(fn.FPath))
+			return
+		}
+	}
 	if tv.IsChanged() {
 		ge.SetStatus(fmt.Sprintf("Note: Changes not yet saved in file: %v", tv.Buf.Filename))
 	}
@@ -707,6 +877,9 @@ func (ge *Gide) ViewFileNode(tv *giv.TextView, vidx int, fn *giv.FileNode) {
 		if nw {
 			ge.AutoSaveCheck(tv, vidx, fn)
 		}
+		if len(ge.Prefs.Folds[ge.FoldPath(fn.Buf)]) > 0 {
+			ge.FoldShow(tv)
+		}
 		ge.SetActiveTextViewIdx(vidx)
 	}
 }
@@ -764,6 +937,28 @@ func (ge *Gide) ViewFile(fnm gi.FileName) (*giv.TextView, int, bool) {
 	return tv, idx, true
 }
 
+// ViewLargeFile views given file in large file mode, for files too large to
+// open for editing, e.g., multi-hundred-MB logs: read-only, in a panel that
+// loads a window of its lines at a time, with no highlighting or undo, and
+// searches it by streaming through it rather than loading it
+func (ge *Gide) ViewLargeFile(fnm gi.FileName) {
+	path := string(fnm)
+	nm := LargeFileTabName(path)
+	tbuf, _ := ge.FindOrMakeCmdBuf(nm, true)
+	fbuf, _ := ge.FindOrMakeCmdBuf(nm+" Find", true)
+	lvi, _ := ge.FindOrMakeMainTab(nm, KiT_LargeFileView, true) // sel
+	lv := lvi.Embed(KiT_LargeFileView).(*LargeFileView)
+	lv.UpdateView(ge)
+	ltv := lv.TextView()
+	ltv.SetInactive()
+	ltv.SetBuf(tbuf)
+	ftv := lv.FindView()
+	ftv.SetInactive()
+	ftv.SetBuf(fbuf)
+	lv.Open(path)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
 // LinkViewFileNode opens the file node in the 2nd textview, which is next to
 // the tabs where links are clicked, if it is not collapsed -- else 1st
 func (ge *Gide) LinkViewFileNode(fn *giv.FileNode) (*giv.TextView, int) {
@@ -849,19 +1044,166 @@ func (ge *Gide) CloneActiveView() (*giv.TextView, int) {
 	return nil, -1
 }
 
+// SameBufTextView returns the other text view if it is open and viewing the
+// same buffer as given one -- i.e., a split view of one buffer
+func (ge *Gide) SameBufTextView(tv *giv.TextView) (*giv.TextView, int, bool) {
+	if tv == nil || tv.Buf == nil {
+		return nil, -1, false
+	}
+	idx := ge.TextViewIndex(tv)
+	for i, ov := range ge.PaneViews {
+		if i == idx || !ge.PaneIsOpen(i) {
+			continue
+		}
+		if ov != nil && ov.Buf != nil && ov.Buf.This() == tv.Buf.This() {
+			return ov, i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// ToggleFollow toggles Follow mode, where the other text view shows the
+// same buffer as the active one and follows its cursor, offset by the lines
+// that are visible in the active view, so that the two views show one
+// continuous stretch of the file -- the other view is cloned from the active
+// one if it is not already viewing the same buffer.  When off, the two views
+// of one buffer are fully independent (each has its own cursor and scroll
+// position, with edits shown in both).
+func (ge *Gide) ToggleFollow() {
+	ge.Follow = !ge.Follow
+	if !ge.Follow {
+		ge.SetStatus("Follow mode off")
+		return
+	}
+	tv := ge.ActiveTextView()
+	ov, _, ok := ge.SameBufTextView(tv)
+	if !ok {
+		ov, _ = ge.CloneActiveView()
+		if ov == nil {
+			ge.Follow = false
+			return
+		}
+		ge.SetActiveTextView(tv)
+		ge.FollowLines = tv.VisSize.Y
+	} else {
+		ge.FollowLines = ov.CursorPos.Ln - tv.CursorPos.Ln
+	}
+	if ge.FollowLines == 0 {
+		ge.FollowLines = tv.VisSize.Y
+	}
+	ge.FollowSync(tv)
+	ge.SetStatus(fmt.Sprintf("Follow mode on: other view is %v lines away", ge.FollowLines))
+}
+
+// FollowSync moves the cursor in the other view of the same buffer as given
+// text view to stay FollowLines away from the cursor in it, in Follow mode
+func (ge *Gide) FollowSync(tv *giv.TextView) {
+	if !ge.Follow || ge.inFollow {
+		return
+	}
+	ov, oidx, ok := ge.SameBufTextView(tv)
+	if !ok {
+		return
+	}
+	off := ge.FollowLines
+	if oidx < ge.TextViewIndex(tv) {
+		off = -off // moving from the second view
+	}
+	ln := tv.CursorPos.Ln + off
+	if ln < 0 {
+		ln = 0
+	}
+	if nl := len(tv.Buf.Lines); ln >= nl {
+		ln = nl - 1
+	}
+	ge.inFollow = true
+	ov.SetCursorShow(giv.TextPos{Ln: ln})
+	ge.inFollow = false
+	ge.SetActiveTextView(tv)
+}
+
+// ToggleScrollLock toggles ScrollLock mode, where the two text views scroll
+// together, keeping their current relative positions -- unlike Follow mode,
+// the views can show different files
+func (ge *Gide) ToggleScrollLock() {
+	ge.ScrollLock = !ge.ScrollLock
+	if !ge.ScrollLock {
+		ge.SetStatus("Scroll lock off")
+		return
+	}
+	if !ge.PanelIsOpen(TextView1Idx) || !ge.PanelIsOpen(TextView2Idx) {
+		ge.ScrollLock = false
+		ge.SetStatus("Scroll lock needs both text views to be open")
+		return
+	}
+	for i := 0; i < NTextViews; i++ {
+		ge.lockLns[i] = ge.TextViewByIndex(i).CursorPos.Ln
+	}
+	ge.SetStatus("Scroll lock on")
+}
+
+// ScrollLockSync moves the other text view by the same number of lines that
+// given text view has moved since the last sync, in ScrollLock mode
+func (ge *Gide) ScrollLockSync(tv *giv.TextView) {
+	if !ge.ScrollLock || ge.inFollow {
+		return
+	}
+	idx := ge.TextViewIndex(tv)
+	if idx < 0 || idx >= NTextViews {
+		return // only the main views scroll together
+	}
+	del := tv.CursorPos.Ln - ge.lockLns[idx]
+	ge.lockLns[idx] = tv.CursorPos.Ln
+	if del == 0 {
+		return
+	}
+	oidx := (idx + 1) % NTextViews
+	if !ge.PanelIsOpen(oidx + TextView1Idx) {
+		return
+	}
+	ov := ge.TextViewByIndex(oidx)
+	if ov == nil || ov.Buf == nil {
+		return
+	}
+	ln := ov.CursorPos.Ln + del
+	if ln < 0 {
+		ln = 0
+	}
+	if nl := len(ov.Buf.Lines); ln >= nl {
+		ln = nl - 1
+	}
+	ge.inFollow = true
+	ov.SetCursorShow(giv.TextPos{Ln: ln, Ch: ov.CursorPos.Ch})
+	ge.inFollow = false
+	ge.lockLns[oidx] = ln
+	ge.SetActiveTextView(tv)
+}
+
 // SaveAllOpenNodes saves all of the open filenodes to their current file names
 func (ge *Gide) SaveAllOpenNodes() {
 	for _, ond := range ge.OpenNodes {
 		if ond.Buf.IsChanged() {
-			ond.Buf.Save()
-			ge.RunPostCmdsFileNode(ond)
+			ge.SaveOpenNode(ond)
 		}
 	}
 }
 
 // TextViewSig handles all signals from the textviews
 func (ge *Gide) TextViewSig(tv *giv.TextView, sig giv.TextViewSignals) {
+	if ge.inFollow {
+		return // signal from the view being moved by FollowSync
+	}
 	ge.SetActiveTextView(tv) // if we're sending signals, we're the active one!
+	if sig == giv.TextViewCursorMoved {
+		ge.FollowSync(tv)
+		ge.ScrollLockSync(tv)
+		ge.FoldSkip(tv)
+		ge.CursorsMoved(tv)
+		ge.BracketShow(tv)
+		ge.CrumbsUpdate()
+		ge.MarkdownScroll(tv)
+		ge.PanelTouch(ge.CurPanel())
+	}
 	switch sig {
 	case giv.TextViewISearch:
 		fallthrough
@@ -870,10 +1212,37 @@ func (ge *Gide) TextViewSig(tv *giv.TextView, sig giv.TextViewSignals) {
 	case giv.TextViewCursorMoved:
 		ge.SetStatus("")
 	}
+	if sig == giv.TextViewCursorMoved {
+		ge.AsmErrAtCursor(tv)
+	}
+}
+
+// ReplaceHighlights replaces the old highlighted regions in given view, as
+// added by some feature, with the new ones, leaving any others in place
+func ReplaceHighlights(tv *giv.TextView, old, nw []giv.TextRegion) {
+	if len(old) == 0 && len(nw) == 0 {
+		return
+	}
+	updt := tv.UpdateStart()
+	hls := tv.Highlights[:0]
+	for _, hl := range tv.Highlights {
+		mine := false
+		for _, o := range old {
+			if hl.Start == o.Start && hl.End == o.End {
+				mine = true
+				break
+			}
+		}
+		if !mine {
+			hls = append(hls, hl)
+		}
+	}
+	tv.Highlights = append(hls, nw...)
+	tv.UpdateEnd(updt)
 }
 
-// DiffFiles shows the differences between two given files (currently outputs a context diff
-// but will show a side-by-side view soon..
+// DiffFiles shows the differences between two given files side by side, in
+// the Diff tab
 func (ge *Gide) DiffFiles(fnm1, fnm2 gi.FileName) {
 	fnk2, ok := ge.Files.FindFile(string(fnm2))
 	if !ok {
@@ -886,8 +1255,8 @@ func (ge *Gide) DiffFiles(fnm1, fnm2 gi.FileName) {
 	ge.DiffFileNode(fnm1, fn2)
 }
 
-// DiffFileNode shows the differences between two given files (currently outputs a context diff
-// but will show a side-by-side view soon..
+// DiffFileNode shows the differences between two given files side by side,
+// in the Diff tab
 func (ge *Gide) DiffFileNode(fnm gi.FileName, fn *giv.FileNode) {
 	fnk1, ok := ge.Files.FindFile(string(fnm))
 	if !ok {
@@ -909,10 +1278,7 @@ func (ge *Gide) DiffFileNode(fnm gi.FileName, fn *giv.FileNode) {
 	if fn.Buf == nil {
 		return
 	}
-	dif := fn1.Buf.DiffBufsUnified(fn.Buf, 3)
-	cbuf, _, _, _ := ge.FindOrMakeCmdTab("Diffs", true, true)
-	cbuf.SetText(dif)
-	cbuf.AutoScrollViews()
+	ge.DiffVersions(fn1.Nm, fn1.Buf.LinesToBytesCopy(), fn.Nm, fn.Buf.LinesToBytesCopy())
 }
 
 //////////////////////////////////////////////////////////////////////////////////////
@@ -932,12 +1298,30 @@ func TextLinkHandler(tl gi.TextLink) bool {
 			ge.OpenFindURL(ur, ftv)
 		case strings.HasPrefix(ur, "spell:///"):
 			ge.OpenSpellURL(ur, ftv)
+		case strings.HasPrefix(ur, "todo:///"):
+			ge.OpenTodoURL(ur, ftv)
+		case strings.HasPrefix(ur, "large:///"):
+			ge.OpenLargeURL(ur, ftv)
+		case strings.HasPrefix(ur, "proc:///"):
+			ge.OpenProcURL(ur, ftv)
+		case strings.HasPrefix(ur, "buflist:///"):
+			ge.OpenBufListURL(ur, ftv)
+		case strings.HasPrefix(ur, "replace:///"):
+			ge.OpenReplaceURL(ur, ftv)
+		case strings.HasPrefix(ur, "docfix:///"):
+			ge.OpenDocFixURL(ur, ftv)
+		case strings.HasPrefix(ur, "doctor:///"):
+			ge.OpenDoctorURL(ur, ftv)
+		case strings.HasPrefix(ur, "ref:///"):
+			ge.OpenRefURL(ur, ftv)
+		case strings.HasPrefix(ur, "outline:///"):
+			ge.OpenOutlineURL(ur, ftv)
 		case strings.HasPrefix(ur, "file:///"):
 			ge.OpenFileURL(ur)
 		default:
-			oswin.TheApp.OpenURL(ur)
+			ge.OpenURL(ur)
 		}
-	} else {
+	} else if !NetworkURL(tl.URL) || OfflineCheck("Opening "+tl.URL) == nil {
 		oswin.TheApp.OpenURL(tl.URL)
 	}
 	return true
@@ -984,6 +1368,156 @@ func init() {
 	gi.TextLinkHandler = TextLinkHandler
 }
 
+//////////////////////////////////////////////////////////////////////////////////////
+//   Language Servers
+
+// LspClientForBuf returns the language server client for given buffer,
+// starting the server if needed -- nil if none (or in single-file mode), or
+// if it is still starting: LspStarted opens the buffers in it when it is
+// ready
+func (ge *Gide) LspClientForBuf(tb *giv.TextBuf) *LspClient {
+	if tb == nil || tb.Filename == "" || ge.SingleFile || ge.IsEmpty() {
+		return nil
+	}
+	ge.Lsp.Mu.Lock()
+	if ge.Lsp.DiagFunc == nil {
+		ge.Lsp.DiagFunc = func(uri string, diags []LspDiagnostic) {
+			ge.RunOnGui(func() { ge.DiagsPublished(uri, diags) })
+		}
+		ge.Lsp.LinesFunc = ge.LspLines
+		ge.Lsp.StartFunc = func(lang LangName) {
+			ge.RunOnGui(func() { ge.LspStarted(lang) })
+		}
+	}
+	ge.Lsp.Mu.Unlock()
+	return ge.Lsp.ClientForFile(string(tb.Filename), string(ge.ProjRoot))
+}
+
+// LspStarted opens the open buffers of given language in its language
+// server, which has just started, and updates their display
+func (ge *Gide) LspStarted(lang LangName) {
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf == nil {
+			continue
+		}
+		if ls := LangsForFilename(string(ond.Buf.Filename)); len(ls) > 0 && LangName(ls[0].Name) == lang {
+			ge.LspOpenBuf(ond.Buf)
+			ge.HiMarkupBuf(ond.Buf)
+			ond.Buf.RefreshViews()
+		}
+	}
+}
+
+// LspLines returns the lines of given file for converting language server
+// positions: those of its buffer if it is open, else those of the file
+func (ge *Gide) LspLines(fpath string) [][]rune {
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil && string(ond.Buf.Filename) == fpath {
+			return ond.Buf.Lines
+		}
+	}
+	return LspFileLines(fpath)
+}
+
+// LspOpenBuf opens given newly-opened buffer in its language server, if any
+// and not already open -- TextBufSig keeps the server in sync with any
+// edits made to it
+func (ge *Gide) LspOpenBuf(tb *giv.TextBuf) {
+	lc := ge.LspClientForBuf(tb)
+	if lc == nil || lc.IsOpen(string(tb.Filename)) {
+		return
+	}
+	lc.DidOpen(string(tb.Filename), string(tb.LinesToBytesCopy()))
+}
+
+// TextBufSig handles all signals from the buffers of open files
+func (ge *Gide) TextBufSig(tb *giv.TextBuf, sig giv.TextBufSignals, data interface{}) {
+	switch sig {
+	case giv.TextBufNew:
+		ge.LspSyncBuf(tb, nil)
+	case giv.TextBufInsert, giv.TextBufDelete:
+		tbe, _ := data.(*giv.TextBufEdit)
+		ge.SnippetEdit(tb, tbe) // before the edits made at other cursors
+		ge.MultiCursorEdit(tb, tbe)
+		ge.LspSyncBuf(tb, tbe)
+		ge.SigHelpEdit(tb, tbe)
+		ge.UndoHistEdit(tb, tbe)
+		ge.OutlineEdit(tb)
+		ge.MarkdownEdit(tb)
+		ge.CrumbsEdit(tb)
+		ge.BufTabsUpdate()
+	case giv.TextBufMarkUpdt:
+		ge.HiMarkupBuf(tb)
+		ge.CgoMarkup(tb)
+		ge.GoAsmMarkupBuf(tb)
+	}
+}
+
+// LspSyncBuf sends given edit of given buffer to its language server, if it
+// is open in it -- a nil edit is new text, which is sent in full -- servers
+// that do not take incremental edits get the full text, at most every
+// LspSyncDelay, the rest being sent by LspFlushBuf
+func (ge *Gide) LspSyncBuf(tb *giv.TextBuf, tbe *giv.TextBufEdit) {
+	lc := ge.LspClientForBuf(tb)
+	if lc == nil {
+		return
+	}
+	fpath := string(tb.Filename)
+	if !lc.IsOpen(fpath) {
+		return
+	}
+	switch {
+	case lc.SyncKind() == 0:
+	case tbe != nil && lc.SyncKind() == 2:
+		st := tbe.Reg.Start
+		var line []rune
+		if st.Ln < len(tb.Lines) {
+			line = tb.Lines[st.Ln]
+		}
+		lc.DidChangeEdits(fpath, LspEditChange(line, st.Ln, st.Ch, tbe.Delete, tbe.ToBytes(), lc.UTF16))
+	case tbe != nil && lc.HoldChange(fpath):
+	default:
+		lc.DidChange(fpath, string(tb.LinesToBytesCopy()))
+	}
+}
+
+// LspFlushBuf sends the full text of given buffer to given language server if
+// it has changes that were held back by LspSyncBuf -- before requests and
+// saves, which need the server to have the current text
+func (ge *Gide) LspFlushBuf(lc *LspClient, tb *giv.TextBuf) {
+	if fpath := string(tb.Filename); lc.IsPending(fpath) {
+		lc.DidChange(fpath, string(tb.LinesToBytesCopy()))
+	}
+}
+
+// LspDidSave tells the language server of given buffer that it was saved
+func (ge *Gide) LspDidSave(tb *giv.TextBuf) {
+	if lc := ge.LspClientForBuf(tb); lc != nil {
+		ge.LspFlushBuf(lc, tb)
+		lc.DidSave(string(tb.Filename))
+	}
+}
+
+// LspClientForActive returns the language server client for the active
+// text view, making sure the server has its current contents, along with the
+// active view and its filename -- nil if none
+func (ge *Gide) LspClientForActive() (*LspClient, *giv.TextView, string) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return nil, nil, ""
+	}
+	lc := ge.LspClientForBuf(tv.Buf)
+	if lc == nil {
+		return nil, tv, ""
+	}
+	fpath := string(tv.Buf.Filename)
+	if !lc.IsOpen(fpath) {
+		lc.DidOpen(fpath, string(tv.Buf.LinesToBytesCopy()))
+	}
+	ge.LspFlushBuf(lc, tv.Buf)
+	return lc, tv, fpath
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //   Close / Quit Req
 
@@ -992,30 +1526,103 @@ func (ge *Gide) NChangedFiles() int {
 	return ge.OpenNodes.NChanged()
 }
 
+// RunningProcs returns descriptions of the processes that are still
+// running for this project: commands and the debugger
+func (ge *Gide) RunningProcs() []string {
+	var procs []string
+	for _, cm := range ge.RunningCmds.Running() {
+		procs = append(procs, fmt.Sprintf("%v: %v (pid %d)", cm.Name, cm.CmdStr, cm.Exec.Process.Pid))
+	}
+	if dc := ge.Dbg; dc != nil && dc.Exec != nil && dc.Exec.Process != nil {
+		procs = append(procs, fmt.Sprintf("Debug session: dlv (pid %d)", dc.Exec.Process.Pid))
+	}
+	return procs
+}
+
+// TerminateProcs terminates all of the processes that are still running for
+// this project, in parallel, each with SIGTERM and then SIGKILL after
+// ProcTermTimeout (see TerminateProc) -- the debug session is ended first,
+// which kills the program being debugged.  The processes are waited for in
+// the background, and then fun is called on the GUI thread, if non-nil.
+func (ge *Gide) TerminateProcs(fun func()) {
+	var wg sync.WaitGroup
+	for _, cm := range ge.RunningCmds.Running() {
+		wg.Add(1)
+		go func(cm *CmdRun) {
+			TerminateProc(cm.Exec, cm.Done, ProcTermTimeout)
+			wg.Done()
+		}(cm)
+	}
+	if dc := ge.Dbg; dc != nil {
+		ge.Dbg = nil
+		ge.DbgRunning = false
+		wg.Add(1)
+		go func() {
+			done := make(chan struct{})
+			go func() {
+				dc.Detach()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(ProcTermTimeout):
+			}
+			TerminateProc(dc.Exec, dc.Done, ProcTermTimeout)
+			wg.Done()
+		}()
+	}
+	go func() {
+		wg.Wait()
+		if fun != nil {
+			ge.RunOnGui(fun)
+		}
+	}()
+}
+
+// RunningProcsCheck checks for processes still running for this project,
+// and if there are any, lists them in a dialog that offers to terminate them
+// (see TerminateProcs) in the background, calling fun on the GUI thread after
+// that, or to cancel -- fun is called right away if there are none
+func (ge *Gide) RunningProcsCheck(title string, fun func()) {
+	procs := ge.RunningProcs()
+	if len(procs) == 0 {
+		fun()
+		return
+	}
+	lst := make([]string, len(procs))
+	for i, pr := range procs {
+		lst[i] = html.EscapeString(pr)
+	}
+	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: title,
+		Prompt: fmt.Sprintf("In Project: %v These processes are <b>still running</b>:<br><br>%v<br><br>Terminate them (they are killed if they do not exit within %v) or cancel?", ge.Nm, strings.Join(lst, "<br>"), ProcTermTimeout)},
+		[]string{"Terminate", "Cancel"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == 0 {
+				ge.SetStatus("Terminating running processes...")
+				ge.TerminateProcs(fun)
+			}
+		})
+}
+
 // CurPanel returns the splitter panel that currently has keyboard focus
 // CloseWindowReq is called when user tries to close window -- we
 // automatically save the project if it already exists (no harm), and prompt
-// to save open files -- if this returns true, then it is OK to close --
-// otherwise not
+// to save open files, and to terminate any processes still running -- if
+// this returns true, then it is OK to close -- otherwise not
 func (ge *Gide) CloseWindowReq() bool {
 	ge.SaveProjIfExists(false) // don't prompt here, as we will do it now..
 	nch := ge.NChangedFiles()
-	if nch == 0 {
+	if nch == 0 && len(ge.RunningProcs()) == 0 {
 		return true
 	}
-	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "Close Project: There are Unsaved Files",
-		Prompt: fmt.Sprintf("In Project: %v There are <b>%v</b> opened files with <b>unsaved changes</b> -- do you want to save all or cancel closing this project and review  / save those files first?", ge.Nm, nch)},
-		[]string{"Cancel", "Save All", "Close Without Saving"},
-		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-			switch sig {
-			case 0:
-				// do nothing, will have returned false already
-			case 1:
-				ge.SaveAllOpenNodes()
-			case 2:
-				ge.ParentWindow().OSWin.Close() // will not be prompted again!
-			}
+	ge.UnsavedDialog("Close Project: There are Unsaved Files", fmt.Sprintf("In Project: %v -- save them before closing, or cancel closing this project to review them", ge.Nm), true, func(ch UnsavedChoice) {
+		if ch == UnsavedCancel {
+			return
+		}
+		ge.RunningProcsCheck("Close Project: There are Running Processes", func() {
+			ge.ParentWindow().OSWin.Close() // will not be prompted again!
 		})
+	})
 	return false // not yet
 }
 
@@ -1083,6 +1690,7 @@ func (ge *Gide) FocusOnPanel(panel int) bool {
 	if sv == nil {
 		return false
 	}
+	ge.PanelTouch(ge.CurPanel())
 	win := ge.ParentWindow()
 	switch panel {
 	case TextView1Idx:
@@ -1109,6 +1717,7 @@ func (ge *Gide) FocusOnPanel(panel int) bool {
 		ski := sv.Kids[panel]
 		win.FocusNext(ski)
 	}
+	ge.PanelTouch(panel)
 	return true
 }
 
@@ -1380,6 +1989,7 @@ func (ge *Gide) SetArgVarVals() {
 	} else {
 		SetArgVarVals(&ArgVarVals, string(tv.Buf.Filename), &ge.Prefs, tv)
 	}
+	CmdBuildEnv = ge.Prefs.BuildEnv
 }
 
 // ExecCmds executes a sequence of commands, sel = select tab, clearBuf = clear buffer
@@ -1416,6 +2026,51 @@ func (ge *Gide) Run() {
 	ge.ExecCmds(ge.Prefs.RunCmds, true, true)
 }
 
+// BuildTarget sets the GOOS/GOARCH target for builds, tests, etc run for
+// this project -- "host" resets to the host system.  Other build env settings
+// (tags, GOFLAGS) are set in the project prefs.
+func (ge *Gide) BuildTarget(target string) {
+	ge.Prefs.BuildEnv.SetTarget(target)
+	ge.Changed = true
+	ge.SetStatus("Build target: " + ge.Prefs.BuildEnv.Label())
+}
+
+// GideBuildTargets gets list of standard build targets for submenu-func,
+// with current target first if not a standard one
+func GideBuildTargets(it interface{}, vp *gi.Viewport2D) []string {
+	ge, ok := it.(ki.Ki).Embed(KiT_Gide).(*Gide)
+	if !ok {
+		return nil
+	}
+	cur := ge.Prefs.BuildEnv.Target()
+	for _, bt := range BuildTargets {
+		if bt == cur {
+			return BuildTargets
+		}
+	}
+	return append([]string{cur}, BuildTargets...)
+}
+
+// CloneRepo clones the git repository at given url into given parent folder,
+// optionally with given depth (> 0 = shallow) and branch, showing progress
+// in the Clone tab, and opens it as a new project when done
+func (ge *Gide) CloneRepo(repoURL string, parentFolder gi.FileName, depth int, branch string) {
+	if repoURL == "" {
+		return
+	}
+	cp := &CloneParams{URL: repoURL, Parent: string(parentFolder), Depth: depth, Branch: branch}
+	cbuf, _, _, _ := ge.FindOrMakeCmdTab("Clone", true, true)
+	ge.SetStatus(fmt.Sprintf("Cloning %v into %v...", repoURL, cp.Path()))
+	cp.CloneGui(ge.Viewport, ge.This(), ge.RunOnGui, func(line string) {
+		lb := []byte(html.EscapeString(line))
+		cbuf.AppendTextLineMarkup(lb, lb, false, true)
+		cbuf.AutoScrollViews()
+	}, func(path string) {
+		ge.SetStatus("Cloned: " + path)
+		NewGideProjPath(path)
+	})
+}
+
 // Commit commits the current changes using relevant VCS tool, and updates the changelog.
 // Checks for VCS setting and
 func (ge *Gide) Commit() {
@@ -1508,7 +2163,9 @@ func (ge *Gide) CursorToHistNext() bool {
 //    Find / Replace
 
 // Find does Find / Replace in files, using given options and filters -- opens up a
-// main tab with the results and further controls.
+// main tab with the results and further controls.  Files are searched in
+// parallel in the background, and the results are listed as they are found
+// -- any search still running is cancelled.
 func (ge *Gide) Find(find, repl string, ignoreCase bool, loc FindLoc, langs LangNames) {
 	if find == "" {
 		return
@@ -1528,8 +2185,15 @@ func (ge *Gide) Find(find, repl string, ignoreCase bool, loc FindLoc, langs Lang
 
 	fv.SaveFindString(find)
 	fv.SaveReplString(repl)
+	ge.Prefs.Find.AddHist()
+	fv.HistPos = 0
 
-	root := ge.Files.Embed(giv.KiT_FileNode).(*giv.FileNode)
+	srch := fv.NewSearch()
+	m, err := ge.Prefs.Find.Matcher()
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Find: %v", err))
+		return
+	}
 
 	atv := ge.ActiveTextView()
 	ond, _, got := ge.OpenNodeForTextView(atv)
@@ -1538,51 +2202,55 @@ func (ge *Gide) Find(find, repl string, ignoreCase bool, loc FindLoc, langs Lang
 		adir, _ = filepath.Split(string(ond.FPath))
 	}
 
-	var res []FileSearchResults
 	if loc == FindLocFile {
 		if got {
-			cnt, matches := atv.Buf.Search([]byte(find), ignoreCase)
-			res = append(res, FileSearchResults{ond, cnt, matches})
+			ms := SearchText(atv.Buf.LinesToBytesCopy(), m)
+			fv.AppendResults(srch, []ProjSearchResults{{Path: string(ond.FPath), RelPath: ond.MyRelPath(), Matches: ms}})
+			ge.SetStatus(fmt.Sprintf("Found %d matches in %v", len(ms), ond.Nm))
 		}
-	} else {
-		res = FileTreeSearch(root, find, ignoreCase, loc, adir, langs)
-	}
-
-	outlns := make([][]byte, 0, 100)
-	outmus := make([][]byte, 0, 100) // markups
-	for _, fs := range res {
-		fp := fs.Node.Info.Path
-		fn := fs.Node.MyRelPath()
-		fbStLn := len(outlns) // find buf start ln
-		lstr := fmt.Sprintf(`%v: %v`, fn, fs.Count)
-		outlns = append(outlns, []byte(lstr))
-		mstr := fmt.Sprintf(`<b>%v</b>`, lstr)
-		outmus = append(outmus, []byte(mstr))
-		for _, mt := range fs.Matches {
-			ln := mt.Reg.Start.Ln + 1
-			ch := mt.Reg.Start.Ch + 1
-			ech := mt.Reg.End.Ch + 1
-			fnstr := fmt.Sprintf("%v:%d:%d", fn, ln, ch)
-			nomu := bytes.Replace(mt.Text, []byte("<mark>"), nil, -1)
-			nomu = bytes.Replace(nomu, []byte("</mark>"), nil, -1)
-			nomus := html.EscapeString(string(nomu))
-			lstr = fmt.Sprintf(`%v: %s`, fnstr, nomus) // note: has tab embedded at start of lstr
-
-			outlns = append(outlns, []byte(lstr))
-			mstr = fmt.Sprintf(`	<a href="find:///%v#R%vN%vL%vC%v-L%vC%v">%v</a>: %s`, fp, fbStLn, fs.Count, ln, ch, ln, ech, fnstr, mt.Text)
-			outmus = append(outmus, []byte(mstr))
-		}
-		outlns = append(outlns, []byte(""))
-		outmus = append(outmus, []byte(""))
-	}
-	ltxt := bytes.Join(outlns, []byte("\n"))
-	mtxt := bytes.Join(outmus, []byte("\n"))
-	fbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
-	ftv.CursorStartDoc()
-	ok := ftv.CursorNextLink(false) // no wrap
-	if ok {
-		ftv.OpenLinkAt(ftv.CursorPos)
+		ftv.CursorStartDoc()
+		ok := ftv.CursorNextLink(false) // no wrap
+		if ok {
+			ftv.OpenLinkAt(ftv.CursorPos)
+		}
+		ge.FocusOnPanel(MainTabsIdx)
+		return
 	}
+
+	opts := &ProjSearchOpts{Root: string(ge.ProjRoot), Loc: loc, ActiveDir: adir, Dir: ge.Prefs.Find.Dir, Langs: langs, Globs: ParseSearchGlobs(ge.Prefs.Find.Globs), Bufs: ge.SearchBufs(loc == FindLocOpen), Cands: ge.ProjIndexCands(m, find, ignoreCase, ge.Prefs.Find.Regexp), MaxMatches: SearchMaxMatches}
+	if opts.Dir == "" {
+		opts.Dir = adir
+	}
+	if ge.Prefs.Find.Structural && len(opts.Langs) == 0 {
+		opts.Langs = LangNames{"Go"}
+	}
+	ge.SetStatus(fmt.Sprintf("Searching for: %v...", find))
+	go func() {
+		stt := time.Now()
+		cancel := func() bool {
+			return !fv.IsSearch(srch)
+		}
+		var pend []ProjSearchResults
+		last := stt
+		nf, nm, trunc := ProjSearch(opts, m, cancel, func(res ProjSearchResults) {
+			pend = append(pend, res)
+			if time.Since(last) >= SearchFlushInterval {
+				fv.AppendResults(srch, pend)
+				pend = nil
+				last = time.Now()
+			}
+		})
+		if cancel() {
+			return
+		}
+		fv.AppendResults(srch, pend)
+		dur := time.Since(stt).Round(time.Millisecond)
+		if trunc {
+			ge.SetStatus(fmt.Sprintf("Found the first %d matches for: %v -- searched %d files in %v", nm, find, nf, dur))
+		} else {
+			ge.SetStatus(fmt.Sprintf("Found %d matches for: %v -- searched %d files in %v", nm, find, nf, dur))
+		}
+	}()
 	ge.FocusOnPanel(MainTabsIdx)
 }
 
@@ -1613,24 +2281,163 @@ func (ge *Gide) Spell() {
 	ge.FocusOnPanel(MainTabsIdx)
 }
 
-// ParseOpenFindURL parses and opens given find:/// url from Find, return text
-// region encoded in url, and starting line of results in find buffer, and
-// number of results returned -- for parsing all the find results
-func (ge *Gide) ParseOpenFindURL(ur string, ftv *giv.TextView) (tv *giv.TextView, reg giv.TextRegion, findBufStLn, findCount int, ok bool) {
-	up, err := url.Parse(ur)
-	if err != nil {
-		log.Printf("FindView OpenFindURL parse err: %v\n", err)
-		return
-	}
-	fpath := up.Path[1:] // has double //
-	pos := up.Fragment
-	tv, _, ok = ge.LinkViewFile(gi.FileName(fpath))
-	if !ok {
-		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Open File at Link", Prompt: fmt.Sprintf("Could not find or open file path in project: %v", fpath)}, true, false, nil, nil)
-		return
-	}
-	if pos == "" {
-		return
+// Todos scans the project for TODO / FIXME etc comments (tags set in the Todo
+// panel) in the background, and lists them in the Todo panel
+func (ge *Gide) Todos() {
+	if len(ge.Prefs.Todo.Tags) == 0 {
+		ge.Prefs.Todo.Defaults()
+	}
+	tbuf, _ := ge.FindOrMakeCmdBuf("Todo", true)
+	tvi, _ := ge.FindOrMakeMainTab("Todo", KiT_TodoView, true) // sel
+	tv := tvi.Embed(KiT_TodoView).(*TodoView)
+	tv.UpdateView(ge)
+	ttv := tv.TextView()
+	ttv.SetInactive()
+	ttv.SetBuf(tbuf)
+	tv.Rescan()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// Procs lists the child processes that gide has spawned for the project --
+// commands, language servers and the debugger -- in the Procs panel, with
+// their pids, cpu and memory usage and command lines, and links to kill or
+// restart each one
+func (ge *Gide) Procs() {
+	tbuf, _ := ge.FindOrMakeCmdBuf("Procs", true)
+	pvi, _ := ge.FindOrMakeMainTab("Procs", KiT_ProcView, true) // sel
+	pv := pvi.Embed(KiT_ProcView).(*ProcView)
+	pv.UpdateView(ge)
+	ttv := pv.TextView()
+	ttv.SetInactive()
+	ttv.SetBuf(tbuf)
+	pv.Refresh()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// DocCheck checks the spelling and grammar of all the Markdown files and Go
+// doc comments in the project in the background, and lists the problems in
+// the Docs panel, with links to apply the suggested fixes -- for cleaning up
+// documentation before a release
+func (ge *Gide) DocCheck() {
+	dbuf, _ := ge.FindOrMakeCmdBuf("Docs", true)
+	dvi, _ := ge.FindOrMakeMainTab("Docs", KiT_DocCheckView, true) // sel
+	dv := dvi.Embed(KiT_DocCheckView).(*DocCheckView)
+	dv.UpdateView(ge)
+	dtv := dv.TextView()
+	dtv.SetInactive()
+	dtv.SetBuf(dbuf)
+	dv.Rescan()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// Problems lists the errors and warnings reported by the language servers,
+// and by failed builds and linters, for the project in the Problems panel -- if a baseline has been saved,
+// only the problems that are new relative to it are shown
+func (ge *Gide) Problems() {
+	tbuf, _ := ge.FindOrMakeCmdBuf("Problems", true)
+	pvi, _ := ge.FindOrMakeMainTab("Problems", KiT_ProblemsView, true) // sel
+	pv := pvi.Embed(KiT_ProblemsView).(*ProblemsView)
+	pv.UpdateView(ge)
+	ptv := pv.TextView()
+	ptv.SetInactive()
+	ptv.SetBuf(tbuf)
+	if pv.Baseline == nil {
+		pv.OpenBaseline()
+	}
+	pv.NewOnlyBox().SetChecked(pv.NewOnly)
+	pv.Refresh()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// FindRefs shows all the references to the symbol at the cursor in the
+// active view in the Refs panel, grouped by file -- uses the language server
+// if available, otherwise a whole-word search of the project files
+func (ge *Gide) FindRefs() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.CursorPos.Ln >= len(tv.Buf.Lines) {
+		return
+	}
+	word, _, _ := WordAtPos(tv.Buf.Lines[tv.CursorPos.Ln], tv.CursorPos.Ch)
+	if word == "" {
+		ge.SetStatus("No symbol at cursor to find references for")
+		return
+	}
+	root := string(ge.ProjRoot)
+	var res []RefFileResults
+	got := false
+	if lc, _, fpath := ge.LspClientForActive(); lc != nil {
+		locs, err := lc.References(fpath, tv.CursorPos.Ln, tv.CursorPos.Ch)
+		if err == nil {
+			res = RefsFromLocations(locs, root)
+			got = true
+		}
+	}
+	if !got {
+		res, got = ge.RefsFromIndex(word)
+	}
+	if !got {
+		froot := ge.Files.Embed(giv.KiT_FileNode).(*giv.FileNode)
+		sres := FileTreeSearch(froot, word, false, FindLocAll, "", nil)
+		res = RefsFromSearch(sres, word, root)
+	}
+
+	tbuf, _ := ge.FindOrMakeCmdBuf("Refs", true)
+	rvi, _ := ge.FindOrMakeMainTab("Refs", KiT_RefsView, true) // sel
+	rv := rvi.Embed(KiT_RefsView).(*RefsView)
+	rv.UpdateView(ge)
+	rtv := rv.TextView()
+	rtv.SetInactive()
+	rtv.SetBuf(tbuf)
+	rv.Symbol = word
+	rv.Results = res
+	rv.ShowResults()
+	ge.SetStatus(fmt.Sprintf("Found %d references to %v in %d files", rv.NItems(), word, len(res)))
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// OpenRefURL opens given ref:/// url from FindRefs -- delegates to RefsView
+func (ge *Gide) OpenRefURL(ur string, rtv *giv.TextView) bool {
+	rvk, ok := rtv.ParentByType(KiT_RefsView, true)
+	if !ok {
+		return false
+	}
+	rv := rvk.(*RefsView)
+	return rv.OpenRefURL(ur, rtv)
+}
+
+// TodoRescanBuf updates the Todo panel for the file of given buffer, if the
+// Todo panel is open -- called after saving
+func (ge *Gide) TodoRescanBuf(tb *giv.TextBuf) {
+	tvi, _, ok := ge.MainTabByName("Todo")
+	if !ok {
+		return
+	}
+	ond := ge.OpenNodes.ByBuf(tb)
+	if ond == nil {
+		return
+	}
+	tv := tvi.Embed(KiT_TodoView).(*TodoView)
+	tv.RescanFile(ond)
+}
+
+// ParseOpenFindURL parses and opens given find:/// url from Find, return text
+// region encoded in url, and starting line of results in find buffer, and
+// number of results returned -- for parsing all the find results
+func (ge *Gide) ParseOpenFindURL(ur string, ftv *giv.TextView) (tv *giv.TextView, reg giv.TextRegion, findBufStLn, findCount int, ok bool) {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("FindView OpenFindURL parse err: %v\n", err)
+		return
+	}
+	fpath := up.Path[1:] // has double //
+	pos := up.Fragment
+	tv, _, ok = ge.LinkViewFile(gi.FileName(fpath))
+	if !ok {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Open File at Link", Prompt: fmt.Sprintf("Could not find or open file path in project: %v", fpath)}, true, false, nil, nil)
+		return
+	}
+	if pos == "" {
+		return
 	}
 
 	lidx := strings.Index(pos, "L")
@@ -1662,6 +2469,49 @@ func (ge *Gide) OpenSpellURL(ur string, stv *giv.TextView) bool {
 	return fv.OpenSpellURL(ur, stv)
 }
 
+// OpenTodoURL opens given todo:/// url from Todos -- delegates to TodoView
+func (ge *Gide) OpenTodoURL(ur string, ttv *giv.TextView) bool {
+	tvk, ok := ttv.ParentByType(KiT_TodoView, true)
+	if !ok {
+		return false
+	}
+	tv := tvk.(*TodoView)
+	return tv.OpenTodoURL(ur, ttv)
+}
+
+// OpenLargeURL shows the line in given large:/// url from the find results
+// of large file mode -- delegates to LargeFileView
+func (ge *Gide) OpenLargeURL(ur string, ttv *giv.TextView) bool {
+	lvk, ok := ttv.ParentByType(KiT_LargeFileView, true)
+	if !ok {
+		return false
+	}
+	lv := lvk.(*LargeFileView)
+	return lv.OpenLargeURL(ur, ttv)
+}
+
+// OpenProcURL kills or restarts the process in given proc:/// url from
+// Procs -- delegates to ProcView
+func (ge *Gide) OpenProcURL(ur string, ttv *giv.TextView) bool {
+	pvk, ok := ttv.ParentByType(KiT_ProcView, true)
+	if !ok {
+		return false
+	}
+	pv := pvk.(*ProcView)
+	return pv.OpenProcURL(ur, ttv)
+}
+
+// OpenDocFixURL applies the fix in given docfix:/// url from DocCheck --
+// delegates to DocCheckView
+func (ge *Gide) OpenDocFixURL(ur string, dtv *giv.TextView) bool {
+	dvk, ok := dtv.ParentByType(KiT_DocCheckView, true)
+	if !ok {
+		return false
+	}
+	dv := dvk.(*DocCheckView)
+	return dv.ApplyFix(ur)
+}
+
 // ReplaceInActive does query-replace in active file only
 func (ge *Gide) ReplaceInActive() {
 	tv := ge.ActiveTextView()
@@ -1734,6 +2584,9 @@ func (ge *Gide) CommentOut() bool {
 	cmt := []byte("// ")
 	ls := LangsForFilename(string(tv.Buf.Filename))
 	if len(ls) == 1 {
+		if ls[0].Comment == "" { // block comments only
+			return ge.CommentToggle()
+		}
 		cmt = []byte(ls[0].Comment)
 	}
 	tv.Buf.CommentRegion(stl, etl, cmt)
@@ -1779,6 +2632,7 @@ func (ge *Gide) SetStatus(msg string) {
 	fnm := ""
 	ln := 0
 	ch := 0
+	les := ""
 	tv := ge.ActiveTextView()
 	if tv != nil {
 		ln = tv.CursorPos.Ln + 1
@@ -1788,6 +2642,13 @@ func (ge *Gide) SetStatus(msg string) {
 			if tv.Buf.IsChanged() {
 				fnm += "*"
 			}
+			les = ge.LineEndsFor(tv.Buf).String()
+			if enc := ge.EncodingFor(tv.Buf); enc != EncodingUTF8 {
+				les += " " + enc
+			}
+		}
+		if is := ge.ISearchStatus(); is != "" {
+			msg = fmt.Sprintf("\t%v\t%v", is, msg)
 		}
 		if tv.ISearch.On {
 			msg = fmt.Sprintf("\tISearch: %v (n=%v)\t%v", tv.ISearch.Find, len(tv.ISearch.Matches), msg)
@@ -1797,7 +2658,16 @@ func (ge *Gide) SetStatus(msg string) {
 		}
 	}
 
-	str := fmt.Sprintf("%v\t<b>%v:</b>\t(%v,%v)\t%v", ge.Nm, fnm, ln, ch, msg)
+	str := fmt.Sprintf("%v\t<b>%v:</b>\t(%v,%v)\t%v\t%v", ge.Nm, fnm, ln, ch, les, msg)
+	if !ge.Prefs.BuildEnv.IsEmpty() {
+		str = fmt.Sprintf("%v\t<b>[%v]</b>", str, ge.Prefs.BuildEnv.Label())
+	}
+	if Prefs.Offline {
+		str += "\t<b>[offline]</b>"
+	}
+	if pi := ge.PowerIndicator(); pi != "" {
+		str += "\t<b>[" + pi + "]</b>"
+	}
 	lbl.SetText(str)
 	sb.UpdateEnd(updt)
 }
@@ -1810,6 +2680,7 @@ func (ge *Gide) Defaults() {
 	ge.Prefs.Files = Prefs.Files
 	ge.Prefs.Editor = Prefs.Editor
 	ge.Prefs.Splits = []float32{.1, .325, .325, .25, 0}
+	ge.Prefs.Todo.Defaults()
 	ge.Files.DirsOnTop = ge.Prefs.Files.DirsOnTop
 	ge.Files.NodeType = KiT_FileNode
 }
@@ -1819,7 +2690,8 @@ func (ge *Gide) Defaults() {
 func (ge *Gide) GrabPrefs() {
 	sv := ge.SplitView()
 	if sv != nil {
-		ge.Prefs.Splits = sv.Splits
+		ge.Prefs.Splits = ge.AutoHideGrab(sv.Splits)
+		ge.Prefs.Panes = ge.PanesLayout()
 	}
 	ge.Prefs.OpenDirs = ge.Files.OpenDirs
 }
@@ -1834,9 +2706,7 @@ func (ge *Gide) ApplyPrefs() {
 	histyle.StyleDefault = Prefs.HiStyle
 	sv := ge.SplitView()
 	if sv != nil {
-		for i := 0; i < NTextViews; i++ {
-			txly := sv.KnownChild(1 + i).(*gi.Layout)
-			txed := txly.KnownChild(0).(*giv.TextView)
+		for _, txed := range ge.PaneViews {
 			if txed.Buf != nil {
 				ge.ConfigTextBuf(txed.Buf)
 			}
@@ -1874,8 +2744,8 @@ func (ge *Gide) SplitsSetView(split SplitName) {
 		if ok {
 			sv.SetSplitsAction(sp.Splits...)
 			ge.Prefs.SplitName = split
-			if !ge.PanelIsOpen(ge.ActiveTextViewIdx + TextView1Idx) {
-				ge.SetActiveTextViewIdx((ge.ActiveTextViewIdx + 1) % 2)
+			if !ge.PaneIsOpen(ge.ActiveTextViewIdx) {
+				ge.SetActiveTextViewIdx((ge.PaneSlot(ge.ActiveTextViewIdx) + 1) % NTextViews)
 			}
 		}
 	}
@@ -1911,7 +2781,7 @@ func (ge *Gide) SplitsEdit() {
 
 // HelpWiki opens wiki page for gide on github
 func (ge *Gide) HelpWiki() {
-	oswin.TheApp.OpenURL("https://github.com/goki/gide/wiki")
+	ge.OpenURL("https://github.com/goki/gide/wiki")
 }
 
 //////////////////////////////////////////////////////////////////////////////////////
@@ -1922,6 +2792,15 @@ func (ge *Gide) HelpWiki() {
 func (ge *Gide) StdFrameConfig() kit.TypeAndNameList {
 	config := kit.TypeAndNameList{}
 	config.Add(gi.KiT_ToolBar, "toolbar")
+	if ge.envBanner != "" {
+		config.Add(gi.KiT_Label, "envbanner")
+	}
+	if ge.Prefs.Editor.TabBar {
+		config.Add(gi.KiT_ToolBar, "buftabs")
+	}
+	if ge.Prefs.Editor.Breadcrumbs {
+		config.Add(gi.KiT_ToolBar, "breadcrumbs")
+	}
 	config.Add(gi.KiT_SplitView, "splitview")
 	config.Add(gi.KiT_Frame, "statusbar")
 	return config
@@ -1946,28 +2825,28 @@ func (ge *Gide) SplitView() *gi.SplitView {
 	return svi.(*gi.SplitView)
 }
 
-// FileTree returns the main FileTree
+// FileTree returns the main FileTree -- nil in single-file mode
 func (ge *Gide) FileTree() *giv.TreeView {
 	split := ge.SplitView()
 	if split != nil {
-		tv := split.KnownChild(FileTreeIdx).KnownChild(0).(*giv.TreeView)
+		ftfr := split.KnownChild(FileTreeIdx)
+		if !ftfr.HasChildren() {
+			return nil
+		}
+		tv := ftfr.KnownChild(0).(*giv.TreeView)
 		return tv
 	}
 	return nil
 }
 
-// TextViewByIndex returns the TextView by index (0 or 1), nil if not found
+// TextViewByIndex returns the TextView by index (0 or 1, or higher for panes
+// split off from them), nil if not found
 func (ge *Gide) TextViewByIndex(idx int) *giv.TextView {
-	if idx < 0 || idx >= NTextViews {
+	if idx < 0 || idx >= len(ge.PaneViews) {
 		log.Printf("Gide: text view index out of range: %v\n", idx)
 		return nil
 	}
-	split := ge.SplitView()
-	if split != nil {
-		svk := split.KnownChild(TextView1Idx + idx).KnownChild(0)
-		return svk.Embed(giv.KiT_TextView).(*giv.TextView)
-	}
-	return nil
+	return ge.PaneViews[idx]
 }
 
 // MainTabs returns the main TabView
@@ -2076,29 +2955,6 @@ func (ge *Gide) ConfigSplitView() {
 	config := ge.SplitViewConfig()
 	mods, updt := split.ConfigChildren(config, true)
 	if mods {
-		ftfr := split.KnownChild(FileTreeIdx).(*gi.Frame)
-		if !ftfr.HasChildren() {
-			ft := ftfr.AddNewChild(KiT_FileTreeView, "filetree").(*FileTreeView)
-			ft.SetRootNode(&ge.Files)
-			ft.TreeViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-				if data == nil {
-					return
-				}
-				tvn, _ := data.(ki.Ki).Embed(KiT_FileTreeView).(*FileTreeView)
-				gee, _ := recv.Embed(KiT_Gide).(*Gide)
-				if tvn.SrcNode.Ptr != nil {
-					fn := tvn.SrcNode.Ptr.Embed(giv.KiT_FileNode).(*giv.FileNode)
-					switch sig {
-					case int64(giv.TreeViewSelected):
-						gee.FileNodeSelected(fn, tvn)
-					case int64(giv.TreeViewOpened):
-						gee.FileNodeOpened(fn, tvn)
-					case int64(giv.TreeViewClosed):
-						gee.FileNodeClosed(fn, tvn)
-					}
-				}
-			})
-		}
 		for i := 0; i < NTextViews; i++ {
 			txly := split.KnownChild(TextView1Idx + i).(*gi.Layout)
 			txly.SetStretchMaxWidth()
@@ -2107,11 +2963,8 @@ func (ge *Gide) ConfigSplitView() {
 			txly.SetMinPrefHeight(units.NewValue(10, units.Ch))
 			if !txly.HasChildren() {
 				ted := txly.AddNewChild(giv.KiT_TextView, fmt.Sprintf("textview-%v", i)).(*giv.TextView)
-				ted.TextViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-					gee, _ := recv.Embed(KiT_Gide).(*Gide)
-					tee := send.Embed(giv.KiT_TextView).(*giv.TextView)
-					gee.TextViewSig(tee, giv.TextViewSignals(sig))
-				})
+				ge.ConfigPaneView(ted)
+				ge.addPane(ted)
 			}
 		}
 
@@ -2131,22 +2984,49 @@ func (ge *Gide) ConfigSplitView() {
 		split.SetSplits(ge.Prefs.Splits...)
 		split.UpdateEnd(updt)
 	}
-	for i := 0; i < NTextViews; i++ {
-		txly := split.KnownChild(1 + i).(*gi.Layout)
-		txed := txly.KnownChild(0).(*giv.TextView)
-		if ge.Prefs.Editor.WordWrap {
-			txed.SetProp("white-space", gi.WhiteSpacePreWrap)
-		} else {
-			txed.SetProp("white-space", gi.WhiteSpacePre)
-		}
-		txed.SetProp("tab-size", ge.Prefs.Editor.TabSize)
-		txed.SetProp("font-family", Prefs.FontFamily)
+	ge.ConfigFileTree()
+	for _, txed := range ge.PaneViews {
+		ge.ConfigPaneStyle(txed)
 	}
 
 	// set some properties always, even if no mods
 	split.SetSplits(ge.Prefs.Splits...)
 }
 
+// ConfigFileTree makes the tree view of the files of the project, if it has
+// not been made yet -- there is none in single-file mode, until the window
+// is promoted to a project
+func (ge *Gide) ConfigFileTree() {
+	split := ge.SplitView()
+	if split == nil || ge.SingleFile {
+		return
+	}
+	ftfr := split.KnownChild(FileTreeIdx).(*gi.Frame)
+	if ftfr.HasChildren() {
+		return
+	}
+	ft := ftfr.AddNewChild(KiT_FileTreeView, "filetree").(*FileTreeView)
+	ft.SetRootNode(&ge.Files)
+	ft.TreeViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if data == nil {
+			return
+		}
+		tvn, _ := data.(ki.Ki).Embed(KiT_FileTreeView).(*FileTreeView)
+		gee, _ := recv.Embed(KiT_Gide).(*Gide)
+		if tvn.SrcNode.Ptr != nil {
+			fn := tvn.SrcNode.Ptr.Embed(giv.KiT_FileNode).(*giv.FileNode)
+			switch sig {
+			case int64(giv.TreeViewSelected):
+				gee.FileNodeSelected(fn, tvn)
+			case int64(giv.TreeViewOpened):
+				gee.FileNodeOpened(fn, tvn)
+			case int64(giv.TreeViewClosed):
+				gee.FileNodeClosed(fn, tvn)
+			}
+		}
+	})
+}
+
 // FileNodeSelected is called whenever tree browser has file node selected
 func (ge *Gide) FileNodeSelected(fn *giv.FileNode, tvn *FileTreeView) {
 	// if fn.IsDir() {
@@ -2169,20 +3049,22 @@ func (ge *Gide) FileNodeOpened(fn *giv.FileNode, tvn *FileTreeView) {
 		ArgVarVals["{PromptString1}"] = string(fn.FPath)
 		CmdNoUserPrompt = true                            // don't re-prompt!
 		ge.ExecCmdName(CmdName("Run Prompt"), true, true) // sel, clear
-	case strings.HasPrefix(fn.Info.Mime, "image"):
-		ge.ExecCmdNameFileNode(fn, CmdName("Open File"), true, true) // sel, clear
+	case IsImageFile(fn):
+		ge.ViewImage(gi.FileName(fn.FPath))
 	case fn.Info.Mime == "application/pdf":
 		ge.ExecCmdNameFileNode(fn, CmdName("Open File"), true, true) // sel, clear
 	default:
 		if int(fn.Info.Size) > GideBigFileSize {
 			gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "File is relatively large",
-				Prompt: fmt.Sprintf("The file: %v is relatively large at: %v -- really open for editing?", fn.Nm, fn.Info.Size)},
-				[]string{"Open", "Cancel"},
+				Prompt: fmt.Sprintf("The file: %v is relatively large at: %v -- view it read-only in large file mode, which loads a window of lines at a time, or really open it for editing?", fn.Nm, fn.Info.Size)},
+				[]string{"View Read-Only", "Open for Editing", "Cancel"},
 				ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 					switch sig {
 					case 0:
-						ge.NextViewFileNode(fn)
+						ge.ViewLargeFile(gi.FileName(fn.FPath))
 					case 1:
+						ge.NextViewFileNode(fn)
+					case 2:
 						// do nothing
 					}
 				})
@@ -2208,6 +3090,9 @@ func (ge *Gide) GideKeys(kt *key.ChordEvent) {
 		fmt.Printf("Gide KeyInput: %v\n", ge.PathUnique())
 	}
 	gkf := gi.KeyFun(kc)
+	if ge.RegisterKey(kt) {
+		return
+	}
 	if ge.KeySeq1 != "" {
 		kf = KeyFun(ge.KeySeq1, kc)
 		seqstr := string(ge.KeySeq1) + " " + string(kc)
@@ -2241,7 +3126,36 @@ func (ge *Gide) GideKeys(kt *key.ChordEvent) {
 		}
 	}
 
+	if ge.ISearchKey(kt, kf, gkf) {
+		return
+	}
+
+	if Kiosk != nil && KioskKeyFuns[kf] {
+		kt.SetProcessed()
+		ge.SetStatus("Not available in kiosk mode")
+		return
+	}
+
+	ge.KillRingKey(kf, gkf)
+
 	switch gkf {
+	case gi.KeyFunAbort:
+		ge.SnippetEnd()
+		if ge.Cursors.Has(ge.ActiveTextView()) {
+			ge.ClearCursors() // and let the view have it too
+		}
+	case gi.KeyFunEnter:
+		if ge.AutoIndentEnter() {
+			kt.SetProcessed()
+		}
+	case gi.KeyFunFocusNext:
+		if ge.SnippetTab() {
+			kt.SetProcessed()
+		}
+	case gi.KeyFunFocusPrev:
+		if ge.SnippetBackTab() {
+			kt.SetProcessed()
+		}
 	case gi.KeyFunFind:
 		kt.SetProcessed()
 		tv := ge.ActiveTextView()
@@ -2253,6 +3167,10 @@ func (ge *Gide) GideKeys(kt *key.ChordEvent) {
 	if kt.IsProcessed() {
 		return
 	}
+	if kf == KeyFunNil && gkf == gi.KeyFunNil && ge.AutoClose(kt) {
+		kt.SetProcessed()
+		return
+	}
 	switch kf {
 	case KeyFunNextPanel:
 		kt.SetProcessed()
@@ -2260,12 +3178,45 @@ func (ge *Gide) GideKeys(kt *key.ChordEvent) {
 	case KeyFunPrevPanel:
 		kt.SetProcessed()
 		ge.FocusPrevPanel()
+	case KeyFunPanelAltToggle:
+		kt.SetProcessed()
+		ge.PanelAltToggle()
+	case KeyFunBufAltToggle:
+		kt.SetProcessed()
+		ge.BufAltToggle()
+	case KeyFunPanelsReveal:
+		kt.SetProcessed()
+		ge.PanelsReveal()
+	case KeyFunMarkdownPreview:
+		kt.SetProcessed()
+		ge.MarkdownPreview()
+	case KeyFunDuplicateLines:
+		kt.SetProcessed()
+		ge.DuplicateLines()
+	case KeyFunNextFunc:
+		kt.SetProcessed()
+		ge.NextFunc()
+	case KeyFunPrevFunc:
+		kt.SetProcessed()
+		ge.PrevFunc()
+	case KeyFunBeginDefun:
+		kt.SetProcessed()
+		ge.BeginDefun()
+	case KeyFunEndDefun:
+		kt.SetProcessed()
+		ge.EndDefun()
+	case KeyFunExpandSel:
+		kt.SetProcessed()
+		ge.ExpandSel()
+	case KeyFunShrinkSel:
+		kt.SetProcessed()
+		ge.ShrinkSel()
 	case KeyFunFileOpen:
 		kt.SetProcessed()
 		giv.CallMethod(ge, "ViewFile", ge.Viewport)
 	case KeyFunBufSelect:
 		kt.SetProcessed()
-		ge.SelectOpenNode()
+		ge.BufList()
 	case KeyFunBufClone:
 		kt.SetProcessed()
 		ge.CloneActiveView()
@@ -2283,13 +3234,58 @@ func (ge *Gide) GideKeys(kt *key.ChordEvent) {
 		giv.CallMethod(ge, "ExecCmd", ge.Viewport)
 	case KeyFunRegCopy:
 		kt.SetProcessed()
-		giv.CallMethod(ge, "RegisterCopy", ge.Viewport)
+		ge.RegisterPrompt("copy")
 	case KeyFunRegPaste:
 		kt.SetProcessed()
-		giv.CallMethod(ge, "RegisterPaste", ge.Viewport)
+		ge.RegisterPrompt("paste")
+	case KeyFunClipHistory:
+		kt.SetProcessed()
+		ge.ClipboardHistory()
+	case KeyFunNextTab:
+		kt.SetProcessed()
+		ge.NextBufTab()
+	case KeyFunPrevTab:
+		kt.SetProcessed()
+		ge.PrevBufTab()
+	case KeyFunCloseOtherTabs:
+		kt.SetProcessed()
+		ge.CloseOtherTabs()
 	case KeyFunCommentOut:
 		kt.SetProcessed()
 		ge.CommentOut()
+	case KeyFunCommentToggle:
+		kt.SetProcessed()
+		ge.CommentToggle()
+	case KeyFunWhitespaceToggle:
+		kt.SetProcessed()
+		ge.ToggleWhitespace()
+	case KeyFunSortLines:
+		kt.SetProcessed()
+		ge.SortLinesAsc()
+	case KeyFunSortLinesDesc:
+		kt.SetProcessed()
+		ge.SortLinesDesc()
+	case KeyFunSortLinesNum:
+		kt.SetProcessed()
+		ge.SortLinesNumeric()
+	case KeyFunUniqueLines:
+		kt.SetProcessed()
+		ge.UniqueLinesActive()
+	case KeyFunReverseLines:
+		kt.SetProcessed()
+		ge.ReverseLinesActive()
+	case KeyFunJoinLines:
+		kt.SetProcessed()
+		ge.JoinLinesActive()
+	case KeyFunSymbolJump:
+		kt.SetProcessed()
+		ge.SymbolJump()
+	case KeyFunSymbolSearch:
+		kt.SetProcessed()
+		ge.SymbolSearch()
+	case KeyFunISearch:
+		kt.SetProcessed()
+		ge.ISearch()
 	case KeyFunIndent:
 		kt.SetProcessed()
 		ge.Indent()
@@ -2301,12 +3297,166 @@ func (ge *Gide) GideKeys(kt *key.ChordEvent) {
 	case KeyFunSetSplit:
 		kt.SetProcessed()
 		giv.CallMethod(ge, "SplitsSetView", ge.Viewport)
+	case KeyFunSetLayout:
+		kt.SetProcessed()
+		ge.LayoutChoose()
+	case KeyFunNextLayout:
+		kt.SetProcessed()
+		ge.LayoutNext()
 	case KeyFunBuildProj:
 		kt.SetProcessed()
 		ge.Build()
 	case KeyFunRunProj:
 		kt.SetProcessed()
 		ge.Run()
+	case KeyFunGotoDef:
+		kt.SetProcessed()
+		ge.GotoDef()
+	case KeyFunNavBack:
+		kt.SetProcessed()
+		ge.NavBack()
+	case KeyFunNavForward:
+		kt.SetProcessed()
+		ge.NavForward()
+	case KeyFunFindRefs:
+		kt.SetProcessed()
+		ge.FindRefs()
+	case KeyFunShowDoc:
+		kt.SetProcessed()
+		ge.ShowDoc()
+	case KeyFunPeekDef:
+		kt.SetProcessed()
+		ge.PeekDef()
+	case KeyFunRename:
+		kt.SetProcessed()
+		ge.Rename()
+	case KeyFunFoldToggle:
+		kt.SetProcessed()
+		ge.FoldToggle()
+	case KeyFunFoldAll:
+		kt.SetProcessed()
+		ge.FoldAll()
+	case KeyFunUnfoldAll:
+		kt.SetProcessed()
+		ge.UnfoldAll()
+	case KeyFunNextFind:
+		kt.SetProcessed()
+		ge.FindResult(false)
+	case KeyFunPrevFind:
+		kt.SetProcessed()
+		ge.FindResult(true)
+	case KeyFunNextError:
+		kt.SetProcessed()
+		ge.NextError()
+	case KeyFunPrevError:
+		kt.SetProcessed()
+		ge.PrevError()
+	case KeyFunAddCursorAbove:
+		kt.SetProcessed()
+		ge.AddCursorAbove()
+	case KeyFunAddCursorBelow:
+		kt.SetProcessed()
+		ge.AddCursorBelow()
+	case KeyFunAddCursorNextMatch:
+		kt.SetProcessed()
+		ge.AddCursorNextMatch()
+	case KeyFunAddCursorsToLines:
+		kt.SetProcessed()
+		ge.AddCursorsToLines()
+	case KeyFunRectSelect:
+		kt.SetProcessed()
+		ge.RectSelect()
+	case KeyFunRectCopy:
+		kt.SetProcessed()
+		ge.RectCopy()
+	case KeyFunRectKill:
+		kt.SetProcessed()
+		ge.RectKill()
+	case KeyFunRectYank:
+		kt.SetProcessed()
+		ge.RectYank()
+	case KeyFunYank:
+		if ge.Yank() {
+			kt.SetProcessed()
+		}
+	case KeyFunYankPop:
+		kt.SetProcessed()
+		ge.YankPop()
+	case KeyFunDebugContinue:
+		kt.SetProcessed()
+		ge.DebugContinue()
+	case KeyFunDebugStop:
+		kt.SetProcessed()
+		ge.DebugStop()
+	case KeyFunDebugToggleBreak:
+		kt.SetProcessed()
+		ge.DebugToggleBreak()
+	case KeyFunDebugNext:
+		kt.SetProcessed()
+		ge.DebugNext()
+	case KeyFunDebugStep:
+		kt.SetProcessed()
+		ge.DebugStep()
+	case KeyFunDebugStepOut:
+		kt.SetProcessed()
+		ge.DebugStepOut()
+	case KeyFunDebugRunToCursor:
+		kt.SetProcessed()
+		ge.DebugRunToCursor()
+	case KeyFunDebugEvalAtCursor:
+		kt.SetProcessed()
+		ge.DebugEvalAtCursor()
+	case KeyFunDebugEvalSelection:
+		kt.SetProcessed()
+		ge.DebugEvalSelection()
+	case KeyFunDebugReverseContinue:
+		kt.SetProcessed()
+		ge.DebugReverseContinue()
+	case KeyFunDebugReverseNext:
+		kt.SetProcessed()
+		ge.DebugReverseNext()
+	case KeyFunDebugReverseStep:
+		kt.SetProcessed()
+		ge.DebugReverseStep()
+	case KeyFunPaneSplitH:
+		kt.SetProcessed()
+		ge.SplitPaneH()
+	case KeyFunPaneSplitV:
+		kt.SetProcessed()
+		ge.SplitPaneV()
+	case KeyFunPaneClose:
+		kt.SetProcessed()
+		ge.ClosePane()
+	case KeyFunPaneUnsplit:
+		kt.SetProcessed()
+		ge.UnsplitPanes()
+	case KeyFunPaneSwap:
+		kt.SetProcessed()
+		ge.SwapPanes()
+	case KeyFunPaneFocusLeft:
+		kt.SetProcessed()
+		ge.FocusPaneLeft()
+	case KeyFunPaneFocusRight:
+		kt.SetProcessed()
+		ge.FocusPaneRight()
+	case KeyFunPaneFocusUp:
+		kt.SetProcessed()
+		ge.FocusPaneUp()
+	case KeyFunPaneFocusDown:
+		kt.SetProcessed()
+		ge.FocusPaneDown()
+	case KeyFunJumpToMatch:
+		kt.SetProcessed()
+		ge.JumpToMatch()
+	case KeyFunFormatBuffer:
+		kt.SetProcessed()
+		ge.FormatActiveView()
+	case KeyFunReflowComment:
+		kt.SetProcessed()
+		ge.ReflowCommentAtCursor()
+	case KeyFunSpellMenu:
+		kt.SetProcessed()
+		ge.SpellMenuAtCursor()
 	}
 }
 
@@ -2338,6 +3488,49 @@ func (ge *Gide) ConnectEvents2D() {
 		ge.LayoutScrollEvents()
 	}
 	ge.KeyChordEvent()
+	ge.WindowFocusEvent()
+	ge.AutoHideEvents()
+	ge.DiagHoverEvents()
+	ge.RunOnGuiEvent()
+}
+
+// guiFunc is a function sent by RunOnGui to the window, as a custom event
+type guiFunc func()
+
+// runOnWinGui runs given function on the goroutine of the events of given
+// window, which must have a node connected to runGuiFunc -- it is run right
+// away if there is no window
+func runOnWinGui(win *gi.Window, fun func()) {
+	if win == nil || win.OSWin == nil {
+		fun()
+		return
+	}
+	oswin.SendCustomEvent(win.OSWin, guiFunc(fun))
+}
+
+// runGuiFunc is the custom event handler that runs the functions sent by
+// runOnWinGui
+func runGuiFunc(recv, send ki.Ki, sig int64, d interface{}) {
+	ce, ok := d.(*oswin.CustomEvent)
+	if !ok {
+		return
+	}
+	if fun, ok := ce.Data.(guiFunc); ok {
+		ce.SetProcessed()
+		fun()
+	}
+}
+
+// RunOnGui runs given function on the goroutine of the events of the window,
+// where the gui can be safely updated -- for goroutines that need to show
+// their results -- it is run right away if there is no window
+func (ge *Gide) RunOnGui(fun func()) {
+	runOnWinGui(ge.ParentWindow(), fun)
+}
+
+// RunOnGuiEvent runs the functions sent by RunOnGui
+func (ge *Gide) RunOnGuiEvent() {
+	ge.ConnectEvent(oswin.CustomEventType, gi.RegPri, runGuiFunc)
 }
 
 // GideInactiveEmptyFunc is an ActionUpdateFunc that inactivates action if project is empty
@@ -2346,6 +3539,13 @@ var GideInactiveEmptyFunc = giv.ActionUpdateFunc(func(gei interface{}, act *gi.A
 	act.SetInactiveState(ge.IsEmpty())
 })
 
+// GideActiveSingleFileFunc is an ActionUpdateFunc that activates action only
+// if in single-file mode
+var GideActiveSingleFileFunc = giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+	ge := gei.(ki.Ki).Embed(KiT_Gide).(*Gide)
+	act.SetActiveState(ge.SingleFile)
+})
+
 var GideProps = ki.Props{
 	"background-color": &gi.Prefs.Colors.Background,
 	"color":            &gi.Prefs.Colors.Font,
@@ -2358,6 +3558,12 @@ var GideProps = ki.Props{
 	},
 	"MethViewNoUpdateAfter": true, // no update after is default for everything
 	"ToolBar": ki.PropSlice{
+		{"PromoteToProj", ki.Props{
+			"label":    "Promote",
+			"icon":     "folder-open",
+			"desc":     "turn this single-file window into a full project for the directory containing the file",
+			"updtfunc": GideActiveSingleFileFunc,
+		}},
 		{"UpdateFiles", ki.Props{
 			"shortcut": "Command+U",
 			"desc":     "update file browser list of files",
@@ -2402,9 +3608,6 @@ var GideProps = ki.Props{
 			"label":        "Edit",
 			"desc":         "select an open file to view in active text view",
 			"submenu-func": giv.SubMenuFunc(GideOpenNodes),
-			"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
-				return key.Chord(ChordForFun(KeyFunBufSelect).String())
-			}),
 			"Args": ki.PropSlice{
 				{"Node Name", ki.Props{}},
 			},
@@ -2425,7 +3628,7 @@ var GideProps = ki.Props{
 		{"Find", ki.Props{
 			"label":    "Find...",
 			"icon":     "search",
-			"desc":     "Find / replace in all open folders in file browser",
+			"desc":     "Find / replace in all the files of the project, skipping those ignored by .gitignore files",
 			"shortcut": gi.KeyFunFind,
 			"Args": ki.PropSlice{
 				{"Search For", ki.Props{
@@ -2456,6 +3659,11 @@ var GideProps = ki.Props{
 			"label": "Spelling...",
 			"icon":  "spelling",
 		}},
+		{"Todos", ki.Props{
+			"label": "Todos",
+			"icon":  "search",
+			"desc":  "list all the TODO / FIXME etc comments in all open folders in file browser",
+		}},
 		{"sep-file", ki.BlankProp{}},
 		{"Build", ki.Props{
 			"icon":    "terminal",
@@ -2471,6 +3679,15 @@ var GideProps = ki.Props{
 				return key.Chord(ChordForFun(KeyFunRunProj).String())
 			}),
 		}},
+		{"BuildTarget", ki.Props{
+			"icon":         "terminal",
+			"label":        "Target",
+			"desc":         "select the GOOS/GOARCH target for builds, tests, etc -- build tags and GOFLAGS are set in Proj Prefs, under BuildEnv",
+			"submenu-func": giv.SubMenuFunc(GideBuildTargets),
+			"Args": ki.PropSlice{
+				{"Target", ki.Props{}},
+			},
+		}},
 		{"Commit", ki.Props{
 			"icon": "star",
 		}},
@@ -2527,11 +3744,36 @@ var GideProps = ki.Props{
 		{"AppMenu", ki.BlankProp{}},
 		{"File", ki.PropSlice{
 			{"OpenRecent", ki.Props{
-				"submenu": &SavedPaths,
+				"submenu-func": giv.SubMenuFunc(GideRecentPaths),
 				"Args": ki.PropSlice{
 					{"File Name", ki.Props{}},
 				},
 			}},
+			{"ShowProjWindow", ki.Props{
+				"label":        "Project Windows",
+				"desc":         "raise an open project window -- only projects in the current group filter are listed",
+				"submenu-func": giv.SubMenuFunc(GideProjWindows),
+				"Args": ki.PropSlice{
+					{"Window Name", ki.Props{}},
+				},
+			}},
+			{"SetProjGroup", ki.Props{
+				"label":        "Project Group",
+				"desc":         "tag this project with a group (e.g., work, OSS, experiments) -- groups and their colors are set in Preferences",
+				"updtfunc":     GideInactiveEmptyFunc,
+				"submenu-func": giv.SubMenuFunc(GideProjGroupTags),
+				"Args": ki.PropSlice{
+					{"Group", ki.Props{}},
+				},
+			}},
+			{"FilterProjGroup", ki.Props{
+				"label":        "Filter Group",
+				"desc":         "only show projects in the given group in the recent and project window lists",
+				"submenu-func": giv.SubMenuFunc(GideProjGroupFilters),
+				"Args": ki.PropSlice{
+					{"Group", ki.Props{}},
+				},
+			}},
 			{"OpenProj", ki.Props{
 				"shortcut": "Command+O",
 				"label":    "Open Project...",
@@ -2550,9 +3792,40 @@ var GideProps = ki.Props{
 					{"Path", ki.Props{}},
 				},
 			}},
-			{"New", ki.PropSlice{
-				{"NewProj", ki.Props{
-					"shortcut": "Command+N",
+			{"OpenFile", ki.Props{
+				"label": "Open Single File...",
+				"desc":  "open a file by itself in a lightweight window, without a file browser or any project-level processing -- use Promote To Project to make it a full project",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{}},
+				},
+			}},
+			{"PromoteToProj", ki.Props{
+				"label":    "Promote To Project",
+				"desc":     "turn this single-file window into a full project for the directory containing the file",
+				"updtfunc": GideActiveSingleFileFunc,
+			}},
+			{"CloneRepo", ki.Props{
+				"label": "Clone...",
+				"desc":  "clone a git repository from given URL into given parent folder, and open it as a new project",
+				"Args": ki.PropSlice{
+					{"Repository URL", ki.Props{
+						"width": 60,
+					}},
+					{"Parent Folder", ki.Props{
+						"dirs-only":     true, // todo: support
+						"default-field": "ProjRoot",
+					}},
+					{"Depth", ki.Props{
+						"desc": "if > 0, make a shallow clone with history truncated to this many commits",
+					}},
+					{"Branch", ki.Props{
+						"desc": "branch (or tag) to check out -- leave empty for the default branch",
+					}},
+				},
+			}},
+			{"New", ki.PropSlice{
+				{"NewProj", ki.Props{
+					"shortcut": "Command+N",
 					"label":    "New Project...",
 					"desc":     "Create a new project -- select a path for the parent folder, and a folder name for the new project -- all Gide projects are basically folders with files.  You can also specify the main language and {version control system for the project.  For other options, do <code>Proj Prefs</code> in the File menu of the new project.",
 					"Args": ki.PropSlice{
@@ -2576,6 +3849,19 @@ var GideProps = ki.Props{
 						}},
 					},
 				}},
+				{"NewScratch", ki.Props{
+					"label":    "New Scratch Buffer...",
+					"desc":     "open a new scratch buffer in given language, for notes, pasted logs and quick experiments: it is not a file of the project, but is kept in the prefs directory across sessions until it is deleted, and can be saved as a file with Save As",
+					"updtfunc": GideInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Language", ki.Props{}},
+					},
+				}},
+				{"OpenScratch", ki.Props{
+					"label":    "Open Scratch Buffer...",
+					"desc":     "show the scratch buffers kept from this and earlier sessions, to open one",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
 			}},
 			{"SaveProj", ki.Props{
 				// "shortcut": "Command+S",
@@ -2608,6 +3894,27 @@ var GideProps = ki.Props{
 					{"File Name", ki.Props{}},
 				},
 			}},
+			{"ViewLargeFile", ki.Props{
+				"label":    "View Large File...",
+				"desc":     "view a file that is too large to open for editing, e.g., a big log, read-only in large file mode, which loads a window of its lines at a time, and streams through it to search it",
+				"updtfunc": GideInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{}},
+				},
+			}},
+			{"ViewHex", ki.Props{
+				"label":    "View As Hex...",
+				"desc":     "view a read-only hex dump of a file, a chunk at a time -- binary files are shown this way when opened",
+				"updtfunc": GideInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{}},
+				},
+			}},
+			{"ReopenAsHex", ki.Props{
+				"label":    "Reopen As Hex",
+				"desc":     "reopen the file of the active view in hex editor mode, to edit its bytes in the hex or ASCII column, insert and delete them with undo, and search for hex patterns or strings",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
 			{"SaveActiveView", ki.Props{
 				"label": "Save File",
 				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
@@ -2628,6 +3935,41 @@ var GideProps = ki.Props{
 					}},
 				},
 			}},
+			{"Line Endings", ki.PropSlice{
+				{"LineEndsToLF", ki.Props{
+					"label":    "LF (Unix)",
+					"desc":     "save the active file with LF line endings, as on Unix, Linux and macOS -- the current ones are shown in the status bar",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"LineEndsToCRLF", ki.Props{
+					"label":    "CRLF (Windows)",
+					"desc":     "save the active file with CRLF line endings, as on Windows -- the current ones are shown in the status bar",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"LineEndsToCR", ki.Props{
+					"label":    "CR (Classic Mac)",
+					"desc":     "save the active file with CR line endings, as on classic Mac OS -- the current ones are shown in the status bar",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+			}},
+			{"ReopenWithEncoding", ki.Props{
+				"label":        "Reopen with Encoding",
+				"desc":         "reload the active file from disk, reading it in the given character encoding, e.g., when it was not detected correctly -- unsaved changes are lost -- encodings other than UTF-8 are shown in the status bar",
+				"updtfunc":     GideInactiveEmptyFunc,
+				"submenu-func": giv.SubMenuFunc(GideEncodings),
+				"Args": ki.PropSlice{
+					{"Encoding", ki.Props{}},
+				},
+			}},
+			{"SaveWithEncoding", ki.Props{
+				"label":        "Save with Encoding",
+				"desc":         "save the active file in the given character encoding, which it is saved in from then on",
+				"updtfunc":     GideInactiveEmptyFunc,
+				"submenu-func": giv.SubMenuFunc(GideEncodings),
+				"Args": ki.PropSlice{
+					{"Encoding", ki.Props{}},
+				},
+			}},
 			{"RevertActiveView", ki.Props{
 				"desc":     "Revert active file to last saved version: this will lose all active changes -- are you sure?",
 				"confirm":  true,
@@ -2641,11 +3983,39 @@ var GideProps = ki.Props{
 					return key.Chord(ChordForFun(KeyFunBufClose).String())
 				}),
 			}},
+			{"CloseOtherTabs", ki.Props{
+				"label":    "Close Other Files",
+				"desc":     "close the files other than the one in the active view, except for those with pinned tabs, asking to save those with unsaved changes",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunCloseOtherTabs).String())
+				}),
+			}},
+			{"BufList", ki.Props{
+				"label":    "Open Buffers...",
+				"desc":     "list all of the open files, with their paths, unsaved changes, sizes and languages, sortable and filterable, to view them or to close, save or revert several at once",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunBufSelect).String())
+				}),
+			}},
 			{"sep-prefs", ki.BlankProp{}},
 			{"ProjPrefs", ki.Props{
 				"label":    "Project Prefs...",
 				"updtfunc": GideInactiveEmptyFunc,
 			}},
+			{"ToggleOffline", ki.Props{
+				"label":    "Work Offline",
+				"desc":     "turn working offline on or off: when it is on, all of the features that use the network (opening web links, cloning, version control and go get commands, module queries by the go tool) are disabled and fail fast, e.g., for flights and air-gapped environments -- shown in the status bar",
+				"updtfunc": GideOfflineFunc,
+			}},
+			{"SetPowerMode", ki.Props{
+				"label": "Power Mode...",
+				"desc":  "set how background work -- symbol indexing, semantic highlighting, and checking files for changes on disk -- adapts to the power of the machine: reduced automatically when on battery or under thermal pressure (Auto), always reduced (Save), or never (Full) -- shown in the status bar when it is reduced",
+				"Args": ki.PropSlice{
+					{"Mode", ki.Props{}},
+				},
+			}},
 			{"sep-close", ki.BlankProp{}},
 			{"Close Window", ki.BlankProp{}},
 		}},
@@ -2662,6 +4032,52 @@ var GideProps = ki.Props{
 			{"Paste History...", ki.Props{
 				"keyfun": gi.KeyFunPasteHist,
 			}},
+			{"CopyReference", ki.Props{
+				"label":    "Copy Reference",
+				"desc":     "copy a reference to the selected lines, or the cursor line, of the active file to the clipboard, with its path relative to the project root, e.g., gide/gide.go:123 -- for pasting into issues and chat",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"CopyPermalink", ki.Props{
+				"label":    "Copy Permalink",
+				"desc":     "copy the url of the selected lines, or the cursor line, of the active file on the GitHub or GitLab site of the git remote of the project, at the current commit, to the clipboard",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"CopyHighlighted", ki.Props{
+				"label":    "Copy Highlighted",
+				"desc":     "copy the selected text, or the cursor line, with its syntax highlighting in the current highlighting style, as both HTML and RTF -- for pasting colored code into slides and documents",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"CopyHighlightedStyle", ki.Props{
+				"label":    "Copy Highlighted With...",
+				"desc":     "copy the selected text, or the cursor line, with its syntax highlighting in a chosen highlighting style, as both HTML and RTF, optionally with line numbers",
+				"updtfunc": GideInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"Style", ki.Props{}},
+					{"Line Numbers", ki.Props{}},
+				},
+			}},
+			{"CopyWithLineNumbers", ki.Props{
+				"label":    "Copy With Line Numbers",
+				"desc":     "copy the selected text, or the cursor line, as plain text with the line numbers in front of each line",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"ExportSnippet", ki.Props{
+				"label":    "Export Snippet Image...",
+				"desc":     "save the selected text, or the cursor line, as a PNG or SVG image with its syntax highlighting in a chosen highlighting style, optionally with line numbers and window chrome -- for sharing code on social media or in docs",
+				"updtfunc": GideInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".png,.svg",
+					}},
+					{"Style", ki.Props{}},
+					{"Line Numbers", ki.Props{
+						"value": true,
+					}},
+					{"Window Chrome", ki.Props{
+						"value": true,
+					}},
+				},
+			}},
 			{"Registers", ki.PropSlice{
 				{"RegisterCopy", ki.Props{
 					"label": "Copy...",
@@ -2687,6 +4103,14 @@ var GideProps = ki.Props{
 						}},
 					},
 				}},
+				{"ClipboardHistory", ki.Props{
+					"label": "Clipboard History...",
+					"desc":  "show the letter registers, a to z, and the last copies, newest first, with a preview of each, to paste one at the cursor and put it on the clipboard -- the register copy and paste keys take the letter of a register as the next key",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunClipHistory).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
 			}},
 			{"sep-undo", ki.BlankProp{}},
 			{"Undo", ki.Props{
@@ -2695,11 +4119,52 @@ var GideProps = ki.Props{
 			{"Redo", ki.Props{
 				"keyfun": gi.KeyFunRedo,
 			}},
+			{"UndoPrevSession", ki.Props{
+				"label":    "Undo Previous Session",
+				"desc":     "undo the last edit to the active file made in a previous session, before it was last closed -- the edits since it was opened must be undone first, with Undo -- the number of edits kept is set by UndoHistory in the Editor prefs",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"UndoOperation", ki.Props{
+				"label":    "Undo Operation",
+				"desc":     "undo the last operation that changed several files -- a rename or a replace all -- in all of them as a unit, even if they have been closed since",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"RedoOperation", ki.Props{
+				"label":    "Redo Operation",
+				"desc":     "redo the last operation undone with Undo Operation, in all of its files",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"Operations", ki.Props{
+				"label":    "Operations",
+				"desc":     "show the history of the operations that changed several files, newest first, with the files they changed",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"sep-todo", ki.BlankProp{}},
+			{"InsertTodo", ki.Props{
+				"label":    "Insert TODO...",
+				"desc":     "insert a TODO comment with given text above the cursor line",
+				"updtfunc": GideInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"Text", ki.Props{
+						"width": 60,
+					}},
+				},
+			}},
+			{"InsertTodoIssue", ki.Props{
+				"label":    "Insert TODO With Issue...",
+				"desc":     "insert a TODO comment with given text above the cursor line, and file an issue for it in the GitHub or GitLab tracker of the project, putting the issue number in the comment, e.g., TODO(#12): -- the tracker is set in the project prefs, or is that of the git remote, and the access token is taken from GIDE_TRACKER_TOKEN, or GITHUB_TOKEN or GITLAB_TOKEN",
+				"updtfunc": GideInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"Text", ki.Props{
+						"width": 60,
+					}},
+				},
+			}},
 			{"sep-find", ki.BlankProp{}},
 			{"Find", ki.Props{
 				"label":    "Find...",
 				"shortcut": gi.KeyFunFind,
-				"desc":     "Find / replace in all open folders in file browser",
+				"desc":     "Find / replace in all the files of the project, skipping those ignored by .gitignore files",
 				"updtfunc": GideInactiveEmptyFunc,
 				"Args": ki.PropSlice{
 					{"Search For", ki.Props{
@@ -2726,6 +4191,14 @@ var GideProps = ki.Props{
 					}},
 				},
 			}},
+			{"ISearch", ki.Props{
+				"label":    "Incremental Search",
+				"desc":     "search the active text view as you type, as in emacs: repeat to go to the next match, Backspace to go back, Enter to stay at the match and Escape to go back to where it started",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunISearch).String())
+				}),
+			}},
 			{"ReplaceInActive", ki.Props{
 				"label":    "Replace In Active...",
 				"shortcut": gi.KeyFunReplace,
@@ -2736,6 +4209,31 @@ var GideProps = ki.Props{
 				"label":    "Spelling...",
 				"updtfunc": GideInactiveEmptyFunc,
 			}},
+			{"Todos", ki.Props{
+				"label":    "Todos",
+				"desc":     "list all the TODO / FIXME etc comments in all open folders in file browser",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"DocCheck", ki.Props{
+				"label":    "Check Docs",
+				"desc":     "check the spelling and grammar of all Markdown files and Go doc comments in all open folders in file browser, with links to apply the suggested fixes",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"Problems", ki.Props{
+				"label":    "Problems",
+				"desc":     "list the errors and warnings reported by the language servers -- can be exported to SARIF or JSON, and a baseline can be set so only new problems are shown",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"Procs", ki.Props{
+				"label":    "Processes",
+				"desc":     "list the child processes that gide has spawned for the project -- commands, language servers and the debugger -- with their pids, cpu and memory usage and command lines, and links to kill or restart each one",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"AuditLog", ki.Props{
+				"label":    "Audit Log",
+				"desc":     "show every write to a file that gide made in this project -- saves, save as and renames, with the formatting and replaces in them -- with its time and change in size, newest first",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
 			{"ShowCompletions", ki.Props{
 				"keyfun":   gi.KeyFunComplete,
 				"updtfunc": GideInactiveEmptyFunc,
@@ -2747,12 +4245,233 @@ var GideProps = ki.Props{
 				}),
 				"updtfunc": GideInactiveEmptyFunc,
 			}},
+			{"CommentToggle", ki.Props{
+				"label": "Toggle Comment",
+				"desc":  "comments out the current line or the selected lines, or uncomments them if they are all commented out, with the line comment of the language, or its block comment if it has none (see Preferences Edit Langs) -- markers are aligned and indentation kept",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunCommentToggle).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
 			{"Indent", ki.Props{
 				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
 					return key.Chord(ChordForFun(KeyFunIndent).String())
 				}),
 				"updtfunc": GideInactiveEmptyFunc,
 			}},
+			{"FormatActiveView", ki.Props{
+				"label": "Format Buffer",
+				"desc":  "format the active file with the formatter for its language, set in Preferences Edit Langs, e.g., goimports for Go",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunFormatBuffer).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"ReflowCommentAtCursor", ki.Props{
+				"label": "Reflow Comment",
+				"desc":  "rewrap the comment at the cursor to fit within the fill column set in the editor preferences, keeping its comment markers and indentation",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunReflowComment).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"DocStubAtCursor", ki.Props{
+				"label":    "Insert Doc Comment",
+				"desc":     "insert a doc comment skeleton named for the declaration at the cursor, e.g., // Name for a Go function, or a docstring for a Python one",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"SpellMenuAtCursor", ki.Props{
+				"label": "Spelling Suggestions",
+				"desc":  "show the suggested corrections for the word at the cursor that is underlined as misspelled, with options to add it to the dictionary of the project or of all projects",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunSpellMenu).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"sep-lines", ki.BlankProp{}},
+			{"Lines", ki.PropSlice{
+				{"SortLinesAsc", ki.Props{
+					"label": "Sort Ascending",
+					"desc":  "sort the selected lines, or all lines if nothing is selected, in ascending order",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunSortLines).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"SortLinesDesc", ki.Props{
+					"label": "Sort Descending",
+					"desc":  "sort the selected lines, or all lines if nothing is selected, in descending order",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunSortLinesDesc).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"SortLinesNumeric", ki.Props{
+					"label": "Sort Numeric",
+					"desc":  "sort the selected lines, or all lines if nothing is selected, by the number at their start, e.g., 2 before 10 -- lines without one go last",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunSortLinesNum).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"UniqueLinesActive", ki.Props{
+					"label": "Remove Duplicates",
+					"desc":  "remove the repeats of the selected lines, or of all lines if nothing is selected, keeping the first of each",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunUniqueLines).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"ReverseLinesActive", ki.Props{
+					"label": "Reverse",
+					"desc":  "reverse the order of the selected lines, or of all lines if nothing is selected",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunReverseLines).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"JoinLinesActive", ki.Props{
+					"label": "Join",
+					"desc":  "join the selected lines into one, or the current line with the next one, with a space between them",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunJoinLines).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"DuplicateLines", ki.Props{
+					"label": "Duplicate",
+					"desc":  "duplicate the selected lines, or the cursor line, below them",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunDuplicateLines).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+			}},
+			{"Case", ki.PropSlice{
+				{"CaseUpper", ki.Props{
+					"label":    "UPPER CASE",
+					"desc":     "convert the selection, or the word at the cursor, to upper case",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"CaseLower", ki.Props{
+					"label":    "lower case",
+					"desc":     "convert the selection, or the word at the cursor, to lower case",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"CaseTitle", ki.Props{
+					"label":    "Title Case",
+					"desc":     "convert the selection, or the word at the cursor, to title case: the first letter of each word upper case",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"CaseCamel", ki.Props{
+					"label":    "camelCase",
+					"desc":     "convert the identifiers in the selection, or the one at the cursor, to camelCase -- from any style, e.g., snake_case",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"CasePascal", ki.Props{
+					"label":    "PascalCase",
+					"desc":     "convert the identifiers in the selection, or the one at the cursor, to PascalCase -- from any style, e.g., snake_case",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"CaseSnake", ki.Props{
+					"label":    "snake_case",
+					"desc":     "convert the identifiers in the selection, or the one at the cursor, to snake_case -- from any style, e.g., camelCase",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"CaseKebab", ki.Props{
+					"label":    "kebab-case",
+					"desc":     "convert the identifiers in the selection, or the one at the cursor, to kebab-case -- from any style, e.g., camelCase",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+			}},
+			{"sep-rect", ki.BlankProp{}},
+			{"RectSelect", ki.Props{
+				"label": "Rectangle Select",
+				"desc":  "select the rectangle with corners at the start and end of the selection -- typing then replaces it on every line, and delete deletes it",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunRectSelect).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"RectCopy", ki.Props{
+				"label": "Rectangle Copy",
+				"desc":  "copy the rectangle with corners at the start and end of the selection, for Rectangle Yank",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunRectCopy).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"RectKill", ki.Props{
+				"label": "Rectangle Kill",
+				"desc":  "delete the rectangle with corners at the start and end of the selection, saving it for Rectangle Yank",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunRectKill).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"RectYank", ki.Props{
+				"label": "Rectangle Yank",
+				"desc":  "insert the last copied or killed rectangle with its top left corner at the cursor",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunRectYank).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"sep-kill", ki.BlankProp{}},
+			{"Yank", ki.Props{
+				"label": "Yank",
+				"desc":  "insert the last killed, cut or copied text from the kill ring at the cursor -- Yank Pop right after it replaces it with the kill before that",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunYank).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"KillRingMenu", ki.Props{
+				"label": "Kill Ring...",
+				"desc":  "show the kills in the kill ring, newest first, to insert one at the cursor",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunYankPop).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"sep-cursors", ki.BlankProp{}},
+			{"AddCursorAbove", ki.Props{
+				"label": "Add Cursor Above",
+				"desc":  "add another cursor on the line above -- edits are made at all cursors, and Escape clears them",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunAddCursorAbove).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"AddCursorBelow", ki.Props{
+				"label": "Add Cursor Below",
+				"desc":  "add another cursor on the line below -- edits are made at all cursors, and Escape clears them",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunAddCursorBelow).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"AddCursorNextMatch", ki.Props{
+				"label": "Add Cursor At Next Match",
+				"desc":  "add another cursor at the next occurrence of the selected text, selecting it -- typing then replaces all the selected occurrences",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunAddCursorNextMatch).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"AddCursorsToLines", ki.Props{
+				"label": "Add Cursors To Lines",
+				"desc":  "add a cursor on each line of the selection, at the column of the cursor -- for editing a column of lines",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunAddCursorsToLines).String())
+				}),
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"ClearCursors", ki.Props{
+				"label":    "Clear Cursors",
+				"desc":     "remove all the additional cursors (also Escape)",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
 		}},
 		{"View", ki.PropSlice{
 			{"Panels", ki.PropSlice{
@@ -2770,6 +4489,51 @@ var GideProps = ki.Props{
 					}),
 					"updtfunc": GideInactiveEmptyFunc,
 				}},
+				{"PanelAltToggle", ki.Props{
+					"label": "Focus Last",
+					"desc":  "move the focus to the panel that had it before the current one -- again to move it back",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPanelAltToggle).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"BufAltToggle", ki.Props{
+					"label": "Last File",
+					"desc":  "switch the active view to the file used before the one it is viewing -- again to switch back",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunBufAltToggle).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"NextBufTab", ki.Props{
+					"label": "Next Tab",
+					"desc":  "view the file of the next tab of the buffer tab bar in the active view -- the tabs are in the order they were opened or dragged to, with the pinned ones first",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunNextTab).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"PrevBufTab", ki.Props{
+					"label": "Previous Tab",
+					"desc":  "view the file of the previous tab of the buffer tab bar in the active view",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPrevTab).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"PinActiveTab", ki.Props{
+					"label":    "Pin Tab",
+					"desc":     "pin the tab of the active file, or unpin it: pinned tabs are kept first in the buffer tab bar, are not closed by Close Other Tabs, and are pinned again when their files are next opened in the project",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"PanelsReveal", ki.Props{
+					"label": "Reveal Hidden",
+					"desc":  "reveal and focus the file tree or tabs, if they auto-hide (see AutoHideTree and AutoHideTabs in the project prefs) -- again to go back, hiding them",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPanelsReveal).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
 				{"CloneActiveView", ki.Props{
 					"label": "Clone Active",
 					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
@@ -2777,6 +4541,276 @@ var GideProps = ki.Props{
 					}),
 					"updtfunc": GideInactiveEmptyFunc,
 				}},
+				{"FoldToggle", ki.Props{
+					"label": "Fold / Unfold",
+					"desc":  "fold or unfold the innermost block, comment run or //region at the cursor -- folded lines are highlighted and skipped over by the cursor, and fold state is saved with the project",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunFoldToggle).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"FoldAll", ki.Props{
+					"label": "Fold All",
+					"desc":  "fold all the top-level regions in the active view",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunFoldAll).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"UnfoldAll", ki.Props{
+					"label": "Unfold All",
+					"desc":  "unfold all the folded regions in the active view",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunUnfoldAll).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"ToggleScrollLock", ki.Props{
+					"label":    "Toggle Scroll Lock",
+					"desc":     "toggle locked scrolling of the two text views, so that moving in one moves the other by the same amount -- useful for comparing similar files, or code beside its generated output",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"ToggleFollow", ki.Props{
+					"label":    "Toggle Follow",
+					"desc":     "toggle follow mode, where the other view shows the same file and follows the cursor in the active view, continuing on from it",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"ToggleWhitespace", ki.Props{
+					"label": "Toggle Whitespace",
+					"desc":  "hide or show the indent guides and whitespace (spaces as dots, tabs underlined, trailing whitespace highlighted) in all open files, for this session -- what is shown is set by IndentGuides and ShowWhitespace in the editor preferences, and per language in Edit Langs",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunWhitespaceToggle).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+			}},
+			{"Panes", ki.PropSlice{
+				{"SplitPaneH", ki.Props{
+					"label": "Split Side By Side",
+					"desc":  "split the active pane into two, side by side, both showing the same file",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPaneSplitH).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"SplitPaneV", ki.Props{
+					"label": "Split Above / Below",
+					"desc":  "split the active pane into two, one above the other, both showing the same file",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPaneSplitV).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"ClosePane", ki.Props{
+					"label": "Close Pane",
+					"desc":  "close the active pane, if it was split off from one of the main text views",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPaneClose).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"UnsplitPanes", ki.Props{
+					"label": "Unsplit All",
+					"desc":  "close all the split panes, going back to the two main text views",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPaneUnsplit).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"SwapPanes", ki.Props{
+					"label": "Swap With Next",
+					"desc":  "swap the files shown in the active pane and the next one",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPaneSwap).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"FocusPaneLeft", ki.Props{
+					"label": "Focus Left",
+					"desc":  "move the focus to the pane to the left",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPaneFocusLeft).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"FocusPaneRight", ki.Props{
+					"label": "Focus Right",
+					"desc":  "move the focus to the pane to the right",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPaneFocusRight).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"FocusPaneUp", ki.Props{
+					"label": "Focus Up",
+					"desc":  "move the focus to the pane above",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPaneFocusUp).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"FocusPaneDown", ki.Props{
+					"label": "Focus Down",
+					"desc":  "move the focus to the pane below",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPaneFocusDown).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+			}},
+			{"Navigate", ki.PropSlice{
+				{"GotoDef", ki.Props{
+					"label": "Go To Definition",
+					"desc":  "jump to the definition of the symbol at the cursor, using the language server or go/packages",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunGotoDef).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"SymbolJump", ki.Props{
+					"label": "Jump To Symbol...",
+					"desc":  "jump to a function, type, method or section in the active file by (part of) its name, matched fuzzily, e.g., tvsb for TextView.SetBuf",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunSymbolJump).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"SymbolSearch", ki.Props{
+					"label": "Search Project Symbols...",
+					"desc":  "jump to a type, function or method anywhere in the project by (part of) its name, matched fuzzily, using the language server or an index of the Go files of the project",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunSymbolSearch).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"NextFunc", ki.Props{
+					"label": "Next Function",
+					"desc":  "move the cursor to the start of the next function, or the next top-level block by indentation in languages other than Go",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunNextFunc).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"PrevFunc", ki.Props{
+					"label": "Previous Function",
+					"desc":  "move the cursor to the start of the previous function, or the previous top-level block by indentation in languages other than Go",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPrevFunc).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"BeginDefun", ki.Props{
+					"label": "Beginning of Function",
+					"desc":  "move the cursor to the start of the function it is in, or of the previous one if it is already there",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunBeginDefun).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"EndDefun", ki.Props{
+					"label": "End of Function",
+					"desc":  "move the cursor to the end of the function it is in, or of the next one if it is already there",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunEndDefun).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"ExpandSel", ki.Props{
+					"label": "Expand Selection",
+					"desc":  "expand the selection to the smallest enclosing syntax node, for Go, or word, brackets, quotes, line or indented block in other languages -- repeat to keep expanding",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunExpandSel).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"ShrinkSel", ki.Props{
+					"label": "Shrink Selection",
+					"desc":  "shrink the selection back to what it was before the last Expand Selection",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunShrinkSel).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"Outline", ki.Props{
+					"label":    "Outline",
+					"desc":     "show the functions, types and methods, or Markdown sections, of the active file in the Outline panel, with links to each -- it follows the active view, and is kept up to date with edits",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"MarkdownPreview", ki.Props{
+					"label": "Markdown Preview",
+					"desc":  "show the Markdown file in the active view rendered in the Preview panel, with its images -- it scrolls along with the cursor in the file, and is kept up to date with edits",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunMarkdownPreview).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"Rename", ki.Props{
+					"label": "Rename Symbol...",
+					"desc":  "rename the symbol at the cursor everywhere in the project, using the language server or gorename -- shows a preview of the changes first",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunRename).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"ShowDiag", ki.Props{
+					"label":    "Show Errors At Line",
+					"desc":     "expand the errors and warnings from the language server for the cursor line, with related locations and a preview of any available quick fixes, which can be applied",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"PeekDef", ki.Props{
+					"label": "Peek Definition",
+					"desc":  "show the definition of the symbol at the cursor in a small editor popup, instead of jumping to it -- Escape closes it",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPeekDef).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"ShowDoc", ki.Props{
+					"label": "Show Doc",
+					"desc":  "show the documentation and type of the symbol at the cursor, from the language server",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunShowDoc).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"NextError", ki.Props{
+					"label": "Next Error",
+					"desc":  "go to the next error in the output of the last failed command, e.g., a build",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunNextError).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"PrevError", ki.Props{
+					"label": "Previous Error",
+					"desc":  "go to the previous error in the output of the last failed command, e.g., a build",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunPrevError).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"FindRefs", ki.Props{
+					"label": "Find References",
+					"desc":  "show all references to the symbol at the cursor, using the language server or a whole-word search of the project",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunFindRefs).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"NavBack", ki.Props{
+					"label": "Back",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunNavBack).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"NavForward", ki.Props{
+					"label": "Forward",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunNavForward).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
 			}},
 			{"Splits", ki.PropSlice{
 				{"SplitsSetView", ki.Props{
@@ -2813,6 +4847,82 @@ var GideProps = ki.Props{
 					"label":    "Edit...",
 				}},
 			}},
+			{"Layouts", ki.PropSlice{
+				{"LayoutSetView", ki.Props{
+					"label":    "Set Layout",
+					"desc":     "change the window to a named layout: the proportions of its panels, its split panes and the tool panels open in its main tabs",
+					"submenu":  &AvailLayoutNames,
+					"updtfunc": GideInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Layout Name", ki.Props{}},
+					},
+				}},
+				{"LayoutNext", ki.Props{
+					"label": "Next Layout",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunNextLayout).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"LayoutSaveAs", ki.Props{
+					"label":    "Save As...",
+					"desc":     "save the current layout of the window under a name, replacing any layout of that name",
+					"updtfunc": GideInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Name", ki.Props{
+							"width": 60,
+						}},
+						{"Desc", ki.Props{
+							"width": 60,
+						}},
+					},
+				}},
+				{"sep-share", ki.BlankProp{}},
+				{"LayoutCopy", ki.Props{
+					"label":    "Copy As JSON...",
+					"desc":     "copy the current layout of the window to the clipboard, as JSON, to share it with teammates, who can import it with Paste Layout",
+					"updtfunc": GideInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Name", ki.Props{
+							"width": 60,
+						}},
+						{"Desc", ki.Props{
+							"width": 60,
+						}},
+					},
+				}},
+				{"LayoutPaste", ki.Props{
+					"label":    "Paste Layout",
+					"desc":     "import the layout(s) in the JSON in the clipboard, saving them, and change the window to the first one",
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
+				{"LayoutExport", ki.Props{
+					"label":    "Export...",
+					"desc":     "save the current layout of the window to a JSON file, to share it",
+					"updtfunc": GideInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"File Name", ki.Props{
+							"ext": ".json",
+						}},
+						{"Name", ki.Props{
+							"width": 60,
+						}},
+						{"Desc", ki.Props{
+							"width": 60,
+						}},
+					},
+				}},
+				{"LayoutImport", ki.Props{
+					"label":    "Import...",
+					"desc":     "import the layout(s) in a JSON file, saving them, and change the window to the first one",
+					"updtfunc": GideInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"File Name", ki.Props{
+							"ext": ".json",
+						}},
+					},
+				}},
+			}},
 			{"OpenConsoleTab", ki.Props{
 				"updtfunc": GideInactiveEmptyFunc,
 			}},
@@ -2828,6 +4938,14 @@ var GideProps = ki.Props{
 				{"Jump To Line", ki.Props{
 					"keyfun": gi.KeyFunJump,
 				}},
+				{"JumpToMatch", ki.Props{
+					"label": "Jump To Matching Bracket",
+					"desc":  "move the cursor to the bracket matching the one at or just before the cursor -- brackets in strings and comments are skipped",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(ChordForFun(KeyFunJumpToMatch).String())
+					}),
+					"updtfunc": GideInactiveEmptyFunc,
+				}},
 			}},
 		}},
 		{"Command", ki.PropSlice{
@@ -2861,9 +4979,154 @@ var GideProps = ki.Props{
 					{"File Name 2", ki.Props{}},
 				},
 			}},
+			{"DiffActiveDisk", ki.Props{
+				"label":    "Diff Unsaved Changes",
+				"desc":     "show the unsaved changes in the active view, side by side with the file on disk",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"DiffActiveVCS", ki.Props{
+				"label":    "Diff With Last Commit",
+				"desc":     "show the changes in the active view since the last commit to version control (git HEAD or svn BASE), side by side",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+		}},
+		{"Debug", ki.PropSlice{
+			{"DebugContinue", ki.Props{
+				"label":    "Start / Continue",
+				"desc":     "start debugging the main package in the build dir, or continue to the next breakpoint",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugContinue).String())
+				}),
+			}},
+			{"DebugPause", ki.Props{
+				"label":    "Pause",
+				"desc":     "pause the running program",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"DebugStop", ki.Props{
+				"label":    "Stop",
+				"desc":     "end the debug session",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugStop).String())
+				}),
+			}},
+			{"sep-step", ki.BlankProp{}},
+			{"DebugNext", ki.Props{
+				"label":    "Step Over",
+				"desc":     "run to the next line in the current function",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugNext).String())
+				}),
+			}},
+			{"DebugStep", ki.Props{
+				"label":    "Step Into",
+				"desc":     "step into the function called on the current line",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugStep).String())
+				}),
+			}},
+			{"DebugStepOut", ki.Props{
+				"label":    "Step Out",
+				"desc":     "run until the current function returns",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugStepOut).String())
+				}),
+			}},
+			{"DebugRunToCursor", ki.Props{
+				"label":    "Run To Cursor",
+				"desc":     "run until the line of the cursor is reached",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugRunToCursor).String())
+				}),
+			}},
+			{"sep-reverse", ki.BlankProp{}},
+			{"DebugReverseContinue", ki.Props{
+				"label":    "Reverse Continue",
+				"desc":     "run backwards to the previous breakpoint -- needs the rr debug backend, set in the project prefs",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugReverseContinue).String())
+				}),
+			}},
+			{"DebugReverseNext", ki.Props{
+				"label":    "Reverse Step Over",
+				"desc":     "run backwards to the previous line in the current function -- needs the rr debug backend",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugReverseNext).String())
+				}),
+			}},
+			{"DebugReverseStep", ki.Props{
+				"label":    "Reverse Step Into",
+				"desc":     "run backwards into the function called on the previous line -- needs the rr debug backend",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugReverseStep).String())
+				}),
+			}},
+			{"DebugReverseStepOut", ki.Props{
+				"label":    "Reverse Step Out",
+				"desc":     "run backwards to where the current function was called -- needs the rr debug backend",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"sep-eval", ki.BlankProp{}},
+			{"DebugToggleBreak", ki.Props{
+				"label":    "Toggle Breakpoint",
+				"desc":     "set or clear a breakpoint on the line of the cursor",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugToggleBreak).String())
+				}),
+			}},
+			{"DebugEvalAtCursor", ki.Props{
+				"label":    "Show Value",
+				"desc":     "show the value of the variable at the cursor, or the selected expression",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugEvalAtCursor).String())
+				}),
+			}},
+			{"DebugEvalSelection", ki.Props{
+				"label":    "Evaluate Selection",
+				"desc":     "print the value of the selected expression in the Debug panel",
+				"updtfunc": GideInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(ChordForFun(KeyFunDebugEvalSelection).String())
+				}),
+			}},
+			{"DebugBreaksView", ki.Props{
+				"label":    "Breakpoints",
+				"desc":     "list all the breakpoints, to edit their groups and conditions, enable or disable them, and import or export them",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"sep-lowlevel", ki.BlankProp{}},
+			{"DebugMemory", ki.Props{
+				"label":    "Memory",
+				"desc":     "show a hex dump of the memory at the address of the selected expression (or the word at the cursor), in which pointers can be followed",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
+			{"DebugRegisters", ki.Props{
+				"label":    "Registers",
+				"desc":     "show the CPU registers of the stopped program, for a given stack frame",
+				"updtfunc": GideInactiveEmptyFunc,
+			}},
 		}},
 		{"Window", "Windows"},
 		{"Help", ki.PropSlice{
+			{"TakeTour", ki.Props{
+				"label": "Tour",
+				"desc":  "takes an interactive tour of the main parts of gide -- tours of plugins and local setups can be added in the tours folder of the prefs directory",
+			}},
+			{"Doctor", ki.Props{
+				"label": "Doctor",
+				"desc":  "check the setup of gide -- the Go toolchain and its version, the external tools such as gopls and dlv, the prefs files, the key map, the file watcher limit and the PATH -- and show a report, with links to fix the problems that can be fixed from gide",
+			}},
 			{"HelpWiki", ki.Props{}},
 		}},
 	},
@@ -2889,6 +5152,9 @@ var GideProps = ki.Props{
 
 func init() {
 	gi.CustomAppMenuFunc = func(m *gi.Menu, win *gi.Window) {
+		if Kiosk != nil { // preferences are locked
+			return
+		}
 		m.InsertActionAfter("GoGi Preferences...", gi.ActOpts{Label: "Gide Preferences..."},
 			win, func(recv, send ki.Ki, sig int64, data interface{}) {
 				PrefsView(&Prefs)
@@ -2903,6 +5169,15 @@ func NewGideProjPath(path string) (*gi.Window, *Gide) {
 	return NewGideWindow(path, projnm, true)
 }
 
+// NewGideFile creates a new lightweight single-file Gide window (see
+// OpenFile) for editing given file
+func NewGideFile(path string) (*gi.Window, *Gide) {
+	_, fnm := filepath.Split(path)
+	return newGideWindow("gide-file-"+fnm, func(ge *Gide) {
+		ge.OpenFile(gi.FileName(path))
+	})
+}
+
 // OpenGideProj creates a new Gide window opened to given Gide project,
 // returning the window and the path
 func OpenGideProj(projfile string) (*gi.Window, *Gide) {
@@ -2918,8 +5193,18 @@ func OpenGideProj(projfile string) (*gi.Window, *Gide) {
 
 // NewGideWindow is common code for Open GideWindow from Proj or Path
 func NewGideWindow(path, projnm string, doPath bool) (*gi.Window, *Gide) {
-	winm := "gide-" + projnm
+	return newGideWindow("gide-"+projnm, func(ge *Gide) {
+		if doPath {
+			ge.OpenPath(gi.FileName(path))
+		} else {
+			ge.OpenProj(gi.FileName(path))
+		}
+	})
+}
 
+// newGideWindow makes a new Gide window of given name, calling open to
+// open the relevant content within it -- returns existing one if found
+func newGideWindow(winm string, open func(ge *Gide)) (*gi.Window, *Gide) {
 	if win, found := gi.AllWindows.FindName(winm); found {
 		mfr := win.SetMainFrame()
 		ge := mfr.KnownChild(0).Embed(KiT_Gide).(*Gide)
@@ -2939,11 +5224,7 @@ func NewGideWindow(path, projnm string, doPath bool) (*gi.Window, *Gide) {
 	ge := mfr.AddNewChild(KiT_Gide, "gide").(*Gide)
 	ge.Viewport = vp
 
-	if doPath {
-		ge.OpenPath(gi.FileName(path))
-	} else {
-		ge.OpenProj(gi.FileName(path))
-	}
+	open(ge)
 
 	mmen := win.MainMenu
 	giv.MainMenuView(ge, win, mmen)
@@ -2965,6 +5246,10 @@ func NewGideWindow(path, projnm string, doPath bool) (*gi.Window, *Gide) {
 	// })
 
 	win.OSWin.SetCloseCleanFunc(func(w oswin.Window) {
+		ge.StopRecovery()
+		ge.StopWatch()
+		ge.StopPower()
+		ge.Lsp.ShutdownAll()
 		if gi.MainWindows.Len() <= 1 {
 			go oswin.TheApp.Quit() // once main window is closed, quit
 		}
@@ -2976,8 +5261,13 @@ func NewGideWindow(path, projnm string, doPath bool) (*gi.Window, *Gide) {
 
 	win.GoStartEventLoop()
 
-	cmd := exec.Command("gocode", "close")
-	defer cmd.Run()
+	ge.StartPower()
+	ge.StartRecovery()
+	ge.StartWatch()
+	ge.SymIndexStart()
+	ge.ProjIndexStart()
+	ge.OfferTour()
+	ge.DoctorStartup()
 
 	return win, ge
 }
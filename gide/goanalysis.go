@@ -0,0 +1,320 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/complete"
+	"golang.org/x/tools/go/packages"
+)
+
+// GoPkgInfo is the syntax and type information for a Go package, which
+// handles type parameters (generics) -- used for Go files when there is no
+// language server (gopls), and for the inferred type arguments of generic
+// functions and types
+type GoPkgInfo struct {
+	Fset  *token.FileSet
+	Files []*ast.File
+	Pkg   *types.Package
+	Info  *types.Info
+}
+
+// GoLoadFile loads the package of given Go file using go/packages, with src
+// as the current contents of the file, which need not be saved
+func GoLoadFile(fpath string, src []byte) (*GoPkgInfo, error) {
+	cfg := &packages.Config{
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir:     filepath.Dir(fpath),
+		Env:     append(append(os.Environ(), CmdBuildEnv.Env()...), OfflineEnv()...),
+		Tests:   strings.HasSuffix(fpath, "_test.go"),
+		Overlay: map[string][]byte{fpath: src},
+	}
+	pkgs, err := packages.Load(cfg, "file="+fpath)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pkgs {
+		if p.Types == nil || p.TypesInfo == nil {
+			continue
+		}
+		gp := &GoPkgInfo{Fset: p.Fset, Files: p.Syntax, Pkg: p.Types, Info: p.TypesInfo}
+		if f, _ := gp.File(fpath); f != nil {
+			return gp, nil
+		}
+	}
+	if len(pkgs) > 0 && len(pkgs[0].Errors) > 0 {
+		return nil, pkgs[0].Errors[0]
+	}
+	return nil, fmt.Errorf("no package found for %v", fpath)
+}
+
+// File returns the syntax of given file in the package, if it is in it
+func (gp *GoPkgInfo) File(fpath string) (*ast.File, *token.File) {
+	for _, f := range gp.Files {
+		tf := gp.Fset.File(f.Pos())
+		if tf != nil && tf.Name() == fpath {
+			return f, tf
+		}
+	}
+	return nil, nil
+}
+
+// filePos returns the position of given byte offset in given file
+func (gp *GoPkgInfo) filePos(fpath string, off int) (*ast.File, token.Pos) {
+	f, tf := gp.File(fpath)
+	if f == nil || off < 0 || off > tf.Size() {
+		return nil, token.NoPos
+	}
+	return f, tf.Pos(off)
+}
+
+// qual qualifies types by package name, except for those of this package
+func (gp *GoPkgInfo) qual() types.Qualifier {
+	return func(p *types.Package) string {
+		if p == gp.Pkg {
+			return ""
+		}
+		return p.Name()
+	}
+}
+
+// IdentAt returns the identifier at given byte offset of given file, or
+// just before it, if any
+func (gp *GoPkgInfo) IdentAt(fpath string, off int) *ast.Ident {
+	f, pos := gp.filePos(fpath, off)
+	if f == nil {
+		return nil
+	}
+	var id *ast.Ident
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil || id != nil || pos < n.Pos() || pos > n.End() {
+			return false
+		}
+		if i, ok := n.(*ast.Ident); ok {
+			id = i
+			return false
+		}
+		return true
+	})
+	return id
+}
+
+// ObjectOf returns the object defined or referred to by given identifier --
+// for a function, method or field of an instantiated generic type or
+// function, it is the one declared in the generic code
+func (gp *GoPkgInfo) ObjectOf(id *ast.Ident) types.Object {
+	obj := gp.Info.ObjectOf(id)
+	switch o := obj.(type) {
+	case *types.Func:
+		return o.Origin()
+	case *types.Var:
+		return o.Origin()
+	}
+	return obj
+}
+
+// DefAt returns the file and 1-based line and byte column of the definition
+// of the identifier at given byte offset of given file
+func (gp *GoPkgInfo) DefAt(fpath string, off int) (string, int, int, error) {
+	id := gp.IdentAt(fpath, off)
+	if id == nil {
+		return "", 0, 0, fmt.Errorf("no identifier at cursor")
+	}
+	obj := gp.ObjectOf(id)
+	if obj == nil || !obj.Pos().IsValid() {
+		return "", 0, 0, fmt.Errorf("no definition found for %v", id.Name)
+	}
+	p := gp.Fset.Position(obj.Pos())
+	return p.Filename, p.Line, p.Column, nil
+}
+
+// InstanceAt returns the instantiation of the generic function or type named
+// by the identifier at given byte offset of given file, with its type
+// arguments, which may have been inferred, e.g., Map[int, string] func(s
+// []int, f func(int) string) []string -- false if it is not one
+func (gp *GoPkgInfo) InstanceAt(fpath string, off int) (string, bool) {
+	id := gp.IdentAt(fpath, off)
+	if id == nil {
+		return "", false
+	}
+	inst, ok := gp.Info.Instances[id]
+	if !ok || inst.TypeArgs == nil {
+		return "", false
+	}
+	args := make([]string, inst.TypeArgs.Len())
+	for i := range args {
+		args[i] = types.TypeString(inst.TypeArgs.At(i), gp.qual())
+	}
+	typ := inst.Type
+	if nt, ok := typ.(*types.Named); ok {
+		typ = nt.Underlying()
+	}
+	return fmt.Sprintf("%v[%v] %v", id.Name, strings.Join(args, ", "), types.TypeString(typ, gp.qual())), true
+}
+
+// DocAt returns a description of the object at given byte offset of given
+// file, as declared, and its instantiation if it is generic
+func (gp *GoPkgInfo) DocAt(fpath string, off int) string {
+	id := gp.IdentAt(fpath, off)
+	if id == nil {
+		return ""
+	}
+	obj := gp.ObjectOf(id)
+	if obj == nil {
+		return ""
+	}
+	doc := types.ObjectString(obj, gp.qual())
+	if inst, ok := gp.InstanceAt(fpath, off); ok {
+		doc += "\n\ninstantiated as: " + inst
+	}
+	return doc
+}
+
+// goObjKind returns the LSP completion item kind for given object
+func goObjKind(obj types.Object) int {
+	switch o := obj.(type) {
+	case *types.Func:
+		if sig, ok := o.Type().(*types.Signature); ok && sig.Recv() != nil {
+			return 2
+		}
+		return 3
+	case *types.Var:
+		if o.IsField() {
+			return 5
+		}
+	case *types.Const:
+		return 21
+	case *types.TypeName:
+		switch o.Type().Underlying().(type) {
+		case *types.Interface:
+			return 8
+		case *types.Struct:
+			return 22
+		}
+		return 7
+	case *types.PkgName:
+		return 9
+	}
+	return 6
+}
+
+// goMembers returns the fields and methods of a value of given type,
+// including those of an instantiated generic type, and the methods of the
+// constraint of a type parameter
+func goMembers(t types.Type) []types.Object {
+	var objs []types.Object
+	mt := t
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+	default:
+		if _, isTP := t.(*types.TypeParam); !isTP {
+			mt = types.NewPointer(t) // addressable, so pointer methods too
+		}
+	}
+	ms := types.NewMethodSet(mt)
+	for i := 0; i < ms.Len(); i++ {
+		objs = append(objs, ms.At(i).Obj())
+	}
+	if pt, ok := t.Underlying().(*types.Pointer); ok {
+		t = pt.Elem()
+	}
+	seen := make(map[types.Type]bool)
+	var fields func(t types.Type)
+	fields = func(t types.Type) {
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok || seen[t] {
+			return
+		}
+		seen[t] = true
+		for i := 0; i < st.NumFields(); i++ {
+			fv := st.Field(i)
+			objs = append(objs, fv)
+			if fv.Embedded() {
+				ft := fv.Type()
+				if pt, ok := ft.(*types.Pointer); ok {
+					ft = pt.Elem()
+				}
+				fields(ft)
+			}
+		}
+	}
+	fields(t)
+	return objs
+}
+
+// CompleteAt returns the completions for the identifier being typed at
+// given byte offset of given file, starting with seed: the members of the
+// value, type or package before a dot, or else the names in scope there,
+// including type parameters in generic code
+func (gp *GoPkgInfo) CompleteAt(fpath string, off int, seed string) []complete.Completion {
+	f, pos := gp.filePos(fpath, off-len(seed))
+	if f == nil {
+		return nil
+	}
+	var sel *ast.SelectorExpr
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil || sel != nil || pos < n.Pos() || pos > n.End()+1 {
+			return false
+		}
+		if se, ok := n.(*ast.SelectorExpr); ok && se.X.End()+1 == pos {
+			sel = se
+			return false
+		}
+		return true
+	})
+	var objs []types.Object
+	if sel != nil {
+		if id, ok := sel.X.(*ast.Ident); ok {
+			if pn, ok := gp.Info.Uses[id].(*types.PkgName); ok {
+				sc := pn.Imported().Scope()
+				for _, nm := range sc.Names() {
+					if obj := sc.Lookup(nm); obj.Exported() {
+						objs = append(objs, obj)
+					}
+				}
+			}
+		}
+		if objs == nil {
+			if t := gp.Info.TypeOf(sel.X); t != nil {
+				objs = goMembers(t)
+			}
+		}
+	} else {
+		psc := gp.Pkg.Scope()
+		sc := psc.Innermost(pos)
+		if sc == nil {
+			sc = psc
+		}
+		for s := sc; s != nil; s = s.Parent() {
+			for _, nm := range s.Names() {
+				obj := s.Lookup(nm)
+				if s != psc && s != types.Universe && obj.Pos() > pos {
+					continue // declared later
+				}
+				objs = append(objs, obj)
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	var cs []complete.Completion
+	for _, obj := range objs {
+		nm := obj.Name()
+		if seen[nm] || nm == "_" || !strings.HasPrefix(nm, seed) {
+			continue
+		}
+		seen[nm] = true
+		cs = append(cs, complete.Completion{Text: nm, Icon: LspCompletionIcons[goObjKind(obj)], Desc: types.ObjectString(obj, gp.qual())})
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Text < cs[j].Text })
+	return cs
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// goTestSrc is generic code for testing GoPkgInfo
+var goTestSrc = `package gen
+
+type Stringer interface {
+	String() string
+}
+
+type List[T any] struct {
+	items []T
+	Len   int
+}
+
+func (l *List[T]) Push(v T) {
+	l.items = append(l.items, v)
+}
+
+func Map[T, U any](s []T, f func(T) U) []U {
+	var r []U
+	for _, v := range s {
+		r = append(r, f(v))
+	}
+	return r
+}
+
+func Join[S Stringer](ss []S) string {
+	out := ""
+	for _, s := range ss {
+		out += s.
+	}
+	return out
+}
+
+func use() {
+	var l List[int]
+	l.Push(1)
+	strs := Map([]int{1}, func(i int) string { return "" })
+	_ = strs
+}
+`
+
+// goTestPkg type checks the test source, which has an error where it is
+// being completed
+func goTestPkg(t *testing.T) (*GoPkgInfo, string) {
+	fpath := "/tmp/gen/gen.go"
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, fpath, goTestSrc, parser.AllErrors)
+	info := &types.Info{
+		Types:     make(map[ast.Expr]types.TypeAndValue),
+		Defs:      make(map[*ast.Ident]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+		Instances: make(map[*ast.Ident]types.Instance),
+		Scopes:    make(map[ast.Node]*types.Scope),
+	}
+	conf := types.Config{Error: func(err error) {}}
+	pkg, _ := conf.Check("gen", fset, []*ast.File{f}, info)
+	return &GoPkgInfo{Fset: fset, Files: []*ast.File{f}, Pkg: pkg, Info: info}, fpath
+}
+
+// goTestOff returns the offset of the n'th occurrence of s in the test
+// source, plus delta
+func goTestOff(s string, n, delta int) int {
+	off := -1
+	for i := 0; i <= n; i++ {
+		off += 1 + strings.Index(goTestSrc[off+1:], s)
+	}
+	return off + delta
+}
+
+func TestGoPkgInfoDef(t *testing.T) {
+	gp, fpath := goTestPkg(t)
+	_, ln, _, err := gp.DefAt(fpath, goTestOff("l.Push(1)", 0, 3))
+	if err != nil || ln != 12 {
+		t.Errorf("DefAt method of instantiated type: line %d, %v", ln, err)
+	}
+	_, ln, _, err = gp.DefAt(fpath, goTestOff("Map(", 0, 1))
+	if err != nil || ln != 16 {
+		t.Errorf("DefAt generic function: line %d, %v", ln, err)
+	}
+	if _, _, _, err = gp.DefAt(fpath, goTestOff("package", 0, 0)); err == nil {
+		t.Errorf("DefAt keyword: no error")
+	}
+}
+
+func TestGoPkgInfoInstance(t *testing.T) {
+	gp, fpath := goTestPkg(t)
+	inst, ok := gp.InstanceAt(fpath, goTestOff("Map(", 0, 1))
+	if want := "Map[int, string] func(s []int, f func(int) string) []string"; !ok || inst != want {
+		t.Errorf("InstanceAt: %q, want %q", inst, want)
+	}
+	if _, ok := gp.InstanceAt(fpath, goTestOff("use()", 0, 1)); ok {
+		t.Errorf("InstanceAt non-generic function")
+	}
+	if doc := gp.DocAt(fpath, goTestOff("Map(", 0, 1)); !strings.Contains(doc, "func Map[T, U any]") || !strings.Contains(doc, "Map[int, string]") {
+		t.Errorf("DocAt: %q", doc)
+	}
+}
+
+func TestGoPkgInfoComplete(t *testing.T) {
+	gp, fpath := goTestPkg(t)
+	texts := func(off int, seed string) string {
+		var s []string
+		for _, c := range gp.CompleteAt(fpath, off, seed) {
+			s = append(s, c.Text)
+		}
+		return strings.Join(s, " ")
+	}
+	if got := texts(goTestOff("s.\n", 0, 2), ""); got != "String" {
+		t.Errorf("CompleteAt type parameter methods: %q", got)
+	}
+	if got := texts(goTestOff("l.Push(1)", 0, 3), "P"); got != "Push" {
+		t.Errorf("CompleteAt instantiated type methods: %q", got)
+	}
+	if got := texts(goTestOff("l.Push(1)", 0, 2), ""); got != "Len Push items" {
+		t.Errorf("CompleteAt instantiated type members: %q", got)
+	}
+	if got := texts(goTestOff("r = append", 0, 1), "r"); got != "r real recover rune" {
+		t.Errorf("CompleteAt scope: %q", got)
+	}
+	if got := texts(goTestOff("var r []U", 0, 7), "U"); got != "U" {
+		t.Errorf("CompleteAt type parameter in scope: %q", got)
+	}
+}
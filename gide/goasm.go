@@ -0,0 +1,312 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// GoAsmLang is the name of the language for Go assembly files
+const GoAsmLang = "Go Asm"
+
+// AsmVetCmdName is the name of the command that checks Go assembly against
+// the Go declarations of its functions (go vet -asmdecl)
+const AsmVetCmdName = "Vet Go Asm"
+
+// goAsmDirectives are the pseudo-instructions of the Go assembler that
+// declare things, highlighted as declarations
+var goAsmDirectives = map[string]bool{
+	"TEXT": true, "DATA": true, "GLOBL": true, "FUNCDATA": true, "PCDATA": true,
+}
+
+// goAsmRegRe matches register names in Go assembly, including the pseudo
+// registers SB, FP, SP and PC
+var goAsmRegRe = regexp.MustCompile(`^(SB|FP|SP|PC|[A-D]X|[SD]I|BP|R\d+|[A-Z]?[XYZ]\d+|F\d+|V\d+|K\d+|[A-Z]\d+[LHWB]?)$`)
+
+// goAsmTokRe splits an operand of Go assembly into tokens: a comment,
+// a string, a number (possibly immediate), a symbol, or any other rune
+var goAsmTokRe = regexp.MustCompile(`//.*|"(?:[^"\\]|\\.)*"|\$?-?(?:0x[0-9a-fA-F]+|\d+(?:\.\d+)?)|[\pL_·][\pL\pN_·./<>]*|.`)
+
+// goAsmSpan returns s in a span of given class
+func goAsmSpan(cls, s string) string {
+	return `<span class="` + cls + `">` + html.EscapeString(s) + `</span>`
+}
+
+// GoAsmMarkup returns the highlighting markup for a line of Go assembly,
+// using the same css classes as for the highlighting of other files
+func GoAsmMarkup(line string) []byte {
+	var b bytes.Buffer
+	rest := line
+	trim := strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(trim, "#"):
+		return []byte(goAsmSpan("cp", line))
+	case strings.HasPrefix(trim, "//"):
+		return []byte(goAsmSpan("c1", line))
+	}
+	ws := leadingSpace(rest)
+	b.WriteString(ws)
+	rest = rest[len(ws):]
+	if ci := strings.Index(rest, ":"); ci > 0 && !strings.ContainsAny(rest[:ci], " \t(") {
+		b.WriteString(goAsmSpan("nl", rest[:ci+1])) // label
+		rest = rest[ci+1:]
+		ws = leadingSpace(rest)
+		b.WriteString(ws)
+		rest = rest[len(ws):]
+	}
+	op := rest
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		op = rest[:i]
+	}
+	if op != "" && !strings.HasPrefix(op, "//") {
+		cls := "k"
+		if goAsmDirectives[op] {
+			cls = "kd"
+		}
+		b.WriteString(goAsmSpan(cls, op))
+		rest = rest[len(op):]
+	}
+	for _, tok := range goAsmTokRe.FindAllString(rest, -1) {
+		r := tok[0]
+		switch {
+		case strings.HasPrefix(tok, "//"):
+			b.WriteString(goAsmSpan("c1", tok))
+		case r == '"':
+			b.WriteString(goAsmSpan("s", tok))
+		case r == '$' || r == '-' || (r >= '0' && r <= '9'):
+			b.WriteString(goAsmSpan("m", tok))
+		case goAsmRegRe.MatchString(tok):
+			b.WriteString(goAsmSpan("nb", tok))
+		case len(tok) > 1 || r == '_' || strings.HasPrefix(tok, "·") || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			if strings.Contains(tok, "·") {
+				b.WriteString(goAsmSpan("nf", tok))
+			} else {
+				b.WriteString(goAsmSpan("n", tok))
+			}
+		default:
+			b.WriteString(html.EscapeString(tok))
+		}
+	}
+	return b.Bytes()
+}
+
+// GoAsmMarkupBuf re-highlights given buffer, if it is Go assembly, with
+// GoAsmMarkup -- called when the markup of the buffer is updated
+func (ge *Gide) GoAsmMarkupBuf(tb *giv.TextBuf) {
+	ls := LangsForFilename(string(tb.Filename))
+	if len(ls) == 0 || ls[0].Name != GoAsmLang {
+		return
+	}
+	for ln := range tb.Lines {
+		if ln >= len(tb.Markup) {
+			break
+		}
+		tb.Markup[ln] = GoAsmMarkup(string(tb.Lines[ln]))
+	}
+}
+
+// goAsmTextRe matches the TEXT directive starting an assembly function,
+// with the package (empty for the current one) and name of the function
+var goAsmTextRe = regexp.MustCompile(`^\s*TEXT\s+([\w./]*)·(\w+)\(SB\)`)
+
+// goBodylessFuncRe matches a Go function declaration without a body, which
+// is implemented in assembly
+var goBodylessFuncRe = regexp.MustCompile(`^func\s+(\w+)\s*\([^{]*$`)
+
+// AsmTextName returns the name of the function started by given line of Go
+// assembly, if it is a TEXT line for a function of the current package
+func AsmTextName(line string) (string, bool) {
+	m := goAsmTextRe.FindStringSubmatch(line)
+	if m == nil || m[1] != "" {
+		return "", false
+	}
+	return m[2], true
+}
+
+// GoBodylessFunc returns the name of the function declared by given line of
+// Go code, if it is a declaration without a body
+func GoBodylessFunc(line string) (string, bool) {
+	l := strings.TrimSpace(line)
+	if ci := strings.Index(l, "//"); ci >= 0 {
+		l = strings.TrimSpace(l[:ci])
+	}
+	m := goBodylessFuncRe.FindStringSubmatch(l)
+	if m == nil || strings.HasSuffix(l, ",") || strings.HasSuffix(l, "(") { // signature continues
+		return "", false
+	}
+	return m[1], true
+}
+
+// AsmSymbolAt returns the name of the function of the current package
+// referred to at given position of a line of Go assembly, e.g., by CALL
+// ·foo(SB), or the function started on the line if it is a TEXT line
+func AsmSymbolAt(line []rune, ch int) (string, bool) {
+	if nm, ok := AsmTextName(string(line)); ok {
+		return nm, true
+	}
+	wd, st, _ := WordAtPos(line, ch)
+	if wd == "" || st == 0 || line[st-1] != '·' {
+		return "", false
+	}
+	if st > 1 && completeIsWordRune(line[st-2]) {
+		return "", false // in another package
+	}
+	return wd, true
+}
+
+// asmFileScore ranks assembly files for the current architecture first
+func asmFileScore(fpath string) int {
+	if strings.HasSuffix(fpath, "_"+runtime.GOARCH+".s") {
+		return 0
+	}
+	return 1
+}
+
+// FindAsmFunc returns the file and 0-based line of the TEXT directive
+// implementing the Go function of given name in the assembly files in given
+// directory, preferring those for the current architecture
+func FindAsmFunc(dir, name string) (string, int, bool) {
+	fls, _ := filepath.Glob(filepath.Join(dir, "*.s"))
+	sort.SliceStable(fls, func(i, j int) bool {
+		return asmFileScore(fls[i]) < asmFileScore(fls[j])
+	})
+	for _, fp := range fls {
+		b, err := ioutil.ReadFile(fp)
+		if err != nil {
+			continue
+		}
+		for ln, l := range strings.Split(string(b), "\n") {
+			if nm, ok := AsmTextName(l); ok && nm == name {
+				return fp, ln, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// FindGoFuncDecl returns the file and 0-based line of the declaration of
+// the Go function of given name in the Go files in given directory
+func FindGoFuncDecl(dir, name string) (string, int, bool) {
+	fls, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, fp := range fls {
+		b, err := ioutil.ReadFile(fp)
+		if err != nil {
+			continue
+		}
+		for ln, l := range strings.Split(string(b), "\n") {
+			if strings.HasPrefix(l, "func "+name+"(") || strings.HasPrefix(l, "func "+name+" (") {
+				return fp, ln, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// AsmDefAt returns the other side of a Go function implemented in assembly,
+// for the position in given buffer: the TEXT directive in an assembly file
+// for a Go declaration without a body, and the Go declaration (or the TEXT
+// of another assembly function) for a function in assembly
+func (ge *Gide) AsmDefAt(tb *giv.TextBuf, pos giv.TextPos) (NavPos, bool) {
+	if pos.Ln >= len(tb.Lines) {
+		return NavPos{}, false
+	}
+	fpath := string(tb.Filename)
+	dir := filepath.Dir(fpath)
+	line := tb.Lines[pos.Ln]
+	switch filepath.Ext(fpath) {
+	case ".go":
+		nm, ok := GoBodylessFunc(string(line))
+		if !ok {
+			return NavPos{}, false
+		}
+		if fp, ln, ok := FindAsmFunc(dir, nm); ok {
+			return NavPos{Filename: gi.FileName(fp), Pos: giv.TextPos{Ln: ln}}, true
+		}
+	case ".s":
+		nm, ok := AsmSymbolAt(line, pos.Ch)
+		if !ok {
+			return NavPos{}, false
+		}
+		if _, txt := AsmTextName(string(line)); !txt {
+			if fp, ln, ok := FindAsmFunc(dir, nm); ok {
+				return NavPos{Filename: gi.FileName(fp), Pos: giv.TextPos{Ln: ln}}, true
+			}
+		}
+		if fp, ln, ok := FindGoFuncDecl(dir, nm); ok {
+			return NavPos{Filename: gi.FileName(fp), Pos: giv.TextPos{Ln: ln, Ch: len("func ")}}, true
+		}
+	}
+	return NavPos{}, false
+}
+
+// AsmVetResult records the findings of the go vet check of assembly from
+// the output of given command, if it is that check, and highlights them in
+// the open assembly files -- called when a command finishes
+func (ge *Gide) AsmVetResult(cm *Command, buf *giv.TextBuf) {
+	if cm.Name != AsmVetCmdName {
+		return
+	}
+	dir, _ := os.Getwd() // commands are run in their dir
+	ge.AsmErrs = nil
+	for _, ce := range ParseCmdErrors(buf.LinesToBytesCopy(), dir) {
+		if filepath.Ext(ce.Path) == ".s" {
+			ge.AsmErrs = append(ge.AsmErrs, ce)
+		}
+	}
+	for _, tv := range ge.PaneViews {
+		ge.AsmErrsShow(tv)
+	}
+	if len(ge.AsmErrs) > 0 {
+		ge.SetStatus(fmt.Sprintf("go vet: %d problems in assembly -- they are highlighted, and shown here when the cursor is on their line", len(ge.AsmErrs)))
+	}
+}
+
+// AsmErrsShow highlights the lines of the assembly vet findings in given view
+func (ge *Gide) AsmErrsShow(tv *giv.TextView) {
+	if tv.Buf == nil {
+		return
+	}
+	var nw []giv.TextRegion
+	fpath := string(tv.Buf.Filename)
+	for _, ce := range ge.AsmErrs {
+		ln := ce.Line - 1
+		if ce.Path != fpath || ln < 0 || ln >= len(tv.Buf.Lines) {
+			continue
+		}
+		nw = append(nw, giv.TextRegion{Start: giv.TextPos{Ln: ln}, End: giv.TextPos{Ln: ln, Ch: len(tv.Buf.Lines[ln])}})
+	}
+	if ge.asmHls == nil {
+		ge.asmHls = make(map[*giv.TextView][]giv.TextRegion)
+	}
+	ReplaceHighlights(tv, ge.asmHls[tv], nw)
+	ge.asmHls[tv] = nw
+}
+
+// AsmErrAtCursor shows the assembly vet finding for the line of the cursor
+// in given view, if any, in the status bar -- called when the cursor moves
+func (ge *Gide) AsmErrAtCursor(tv *giv.TextView) {
+	if len(ge.AsmErrs) == 0 || tv.Buf == nil {
+		return
+	}
+	fpath := string(tv.Buf.Filename)
+	for _, ce := range ge.AsmErrs {
+		if ce.Path == fpath && ce.Line-1 == tv.CursorPos.Ln {
+			ge.SetStatus("go vet: " + ce.Msg)
+			return
+		}
+	}
+}
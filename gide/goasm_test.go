@@ -0,0 +1,85 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoAsmMarkup(t *testing.T) {
+	mu := string(GoAsmMarkup("TEXT ·Add(SB),NOSPLIT,$0-24 // add"))
+	for _, s := range []string{`<span class="kd">TEXT</span>`, `<span class="nf">·Add</span>`, `<span class="nb">SB</span>`, `<span class="m">$0</span>`, `<span class="c1">// add</span>`} {
+		if !strings.Contains(mu, s) {
+			t.Errorf("markup %q does not contain %q", mu, s)
+		}
+	}
+	mu = string(GoAsmMarkup("loop:\tMOVQ x+0(FP), AX"))
+	for _, s := range []string{`<span class="nl">loop:</span>`, `<span class="k">MOVQ</span>`, `<span class="nb">FP</span>`, `<span class="nb">AX</span>`} {
+		if !strings.Contains(mu, s) {
+			t.Errorf("markup %q does not contain %q", mu, s)
+		}
+	}
+	if mu := string(GoAsmMarkup(`#include "textflag.h"`)); mu != `<span class="cp">#include &#34;textflag.h&#34;</span>` {
+		t.Errorf("preprocessor markup: %q", mu)
+	}
+}
+
+func TestGoAsmNames(t *testing.T) {
+	if nm, ok := AsmTextName("TEXT ·Add(SB),NOSPLIT,$0-24"); !ok || nm != "Add" {
+		t.Errorf("AsmTextName: %q %v", nm, ok)
+	}
+	if _, ok := AsmTextName("TEXT runtime·memmove(SB),NOSPLIT,$0-24"); ok {
+		t.Errorf("AsmTextName matched function of another package")
+	}
+	tests := []struct {
+		line string
+		nm   string
+		ok   bool
+	}{
+		{"func Add(x, y int) int", "Add", true},
+		{"func Add(x, y int) int // in add_amd64.s", "Add", true},
+		{"func Add(x, y int) int {", "", false},
+		{"func Add(x,", "", false},
+		{"func (s *T) Add(x int)", "", false},
+	}
+	for _, ts := range tests {
+		nm, ok := GoBodylessFunc(ts.line)
+		if nm != ts.nm || ok != ts.ok {
+			t.Errorf("GoBodylessFunc(%q) = %q %v, want %q %v", ts.line, nm, ok, ts.nm, ts.ok)
+		}
+	}
+	line := []rune("\tCALL ·helper(SB)")
+	if nm, ok := AsmSymbolAt(line, 9); !ok || nm != "helper" {
+		t.Errorf("AsmSymbolAt: %q %v", nm, ok)
+	}
+	if _, ok := AsmSymbolAt([]rune("\tCALL runtime·morestack(SB)"), 16); ok {
+		t.Errorf("AsmSymbolAt matched function of another package")
+	}
+}
+
+func TestFindAsmFunc(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-goasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "add.go"), []byte("package add\n\n// Add adds\nfunc Add(x, y int) int\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "add_other.s"), []byte("#include \"textflag.h\"\n\nTEXT ·Add(SB),NOSPLIT,$0-24\n\tRET\n"), 0644)
+	fp, ln, ok := FindAsmFunc(dir, "Add")
+	if !ok || filepath.Base(fp) != "add_other.s" || ln != 2 {
+		t.Errorf("FindAsmFunc: %q %d %v", fp, ln, ok)
+	}
+	if _, _, ok := FindAsmFunc(dir, "Sub"); ok {
+		t.Errorf("FindAsmFunc found missing function")
+	}
+	fp, ln, ok = FindGoFuncDecl(dir, "Add")
+	if !ok || filepath.Base(fp) != "add.go" || ln != 3 {
+		t.Errorf("FindGoFuncDecl: %q %d %v", fp, ln, ok)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// HexEdit is an edit of the bytes of a HexDoc: the bytes at an offset that
+// were replaced, and the ones that replaced them -- an insertion has no Del,
+// and a deletion no Ins
+type HexEdit struct {
+	Off int
+	Del []byte
+	Ins []byte
+}
+
+// HexDoc is the bytes of a binary file being edited in hex editor mode, with
+// undo and redo of the edits
+type HexDoc struct {
+	Data  []byte    `desc:"the bytes of the file, as edited"`
+	Undos []HexEdit `desc:"edits that can be undone, last one last"`
+	Redos []HexEdit `desc:"edits that were undone and can be redone, last one last"`
+	Saved int       `desc:"number of Undos when last saved -- the doc is changed if it differs"`
+}
+
+// NewHexDoc returns a doc with given bytes, which it takes over
+func NewHexDoc(b []byte) *HexDoc {
+	return &HexDoc{Data: b}
+}
+
+// Changed returns true if the doc has been edited since it was opened or
+// last saved
+func (hd *HexDoc) Changed() bool {
+	return len(hd.Undos) != hd.Saved
+}
+
+// SetSaved records that the doc has been saved as it is now
+func (hd *HexDoc) SetSaved() {
+	hd.Saved = len(hd.Undos)
+}
+
+// apply makes given edit, which must be in range
+func (hd *HexDoc) apply(ed HexEdit) {
+	nd := make([]byte, 0, len(hd.Data)-len(ed.Del)+len(ed.Ins))
+	nd = append(nd, hd.Data[:ed.Off]...)
+	nd = append(nd, ed.Ins...)
+	nd = append(nd, hd.Data[ed.Off+len(ed.Del):]...)
+	hd.Data = nd
+}
+
+// edit makes given edit, recording it for undo
+func (hd *HexDoc) edit(ed HexEdit) {
+	hd.apply(ed)
+	if hd.Saved > len(hd.Undos) {
+		hd.Saved = -1 // undone past the save, which can no longer be redone
+	}
+	hd.Undos = append(hd.Undos, ed)
+	hd.Redos = nil
+}
+
+// Overwrite replaces the bytes at given offset with given ones, extending
+// the data if they go past its end
+func (hd *HexDoc) Overwrite(off int, b []byte) error {
+	if off < 0 || off > len(hd.Data) {
+		return fmt.Errorf("offset %d is out of range: the size is %d", off, len(hd.Data))
+	}
+	n := len(b)
+	if off+n > len(hd.Data) {
+		n = len(hd.Data) - off
+	}
+	del := make([]byte, n)
+	copy(del, hd.Data[off:off+n])
+	ins := make([]byte, len(b))
+	copy(ins, b)
+	hd.edit(HexEdit{Off: off, Del: del, Ins: ins})
+	return nil
+}
+
+// Insert inserts given bytes at given offset
+func (hd *HexDoc) Insert(off int, b []byte) error {
+	if off < 0 || off > len(hd.Data) {
+		return fmt.Errorf("offset %d is out of range: the size is %d", off, len(hd.Data))
+	}
+	ins := make([]byte, len(b))
+	copy(ins, b)
+	hd.edit(HexEdit{Off: off, Ins: ins})
+	return nil
+}
+
+// Delete deletes n bytes at given offset, or as many as there are
+func (hd *HexDoc) Delete(off, n int) error {
+	if off < 0 || off >= len(hd.Data) || n <= 0 {
+		return fmt.Errorf("nothing to delete at offset %d: the size is %d", off, len(hd.Data))
+	}
+	if off+n > len(hd.Data) {
+		n = len(hd.Data) - off
+	}
+	del := make([]byte, n)
+	copy(del, hd.Data[off:off+n])
+	hd.edit(HexEdit{Off: off, Del: del})
+	return nil
+}
+
+// Undo undoes the last edit, returning its offset, or false if there is
+// none
+func (hd *HexDoc) Undo() (int, bool) {
+	if len(hd.Undos) == 0 {
+		return 0, false
+	}
+	ed := hd.Undos[len(hd.Undos)-1]
+	hd.Undos = hd.Undos[:len(hd.Undos)-1]
+	hd.apply(HexEdit{Off: ed.Off, Del: ed.Ins, Ins: ed.Del})
+	hd.Redos = append(hd.Redos, ed)
+	return ed.Off, true
+}
+
+// Redo redoes the last edit undone, returning its offset, or false if there
+// is none
+func (hd *HexDoc) Redo() (int, bool) {
+	if len(hd.Redos) == 0 {
+		return 0, false
+	}
+	ed := hd.Redos[len(hd.Redos)-1]
+	hd.Redos = hd.Redos[:len(hd.Redos)-1]
+	hd.apply(ed)
+	hd.Undos = append(hd.Undos, ed)
+	return ed.Off, true
+}
+
+// Find returns the offset of the next occurrence of given bytes after
+// given offset, wrapping around to the start, or -1 if there is none
+func (hd *HexDoc) Find(pat []byte, from int) int {
+	if len(pat) == 0 {
+		return -1
+	}
+	if from < 0 || from > len(hd.Data) {
+		from = 0
+	}
+	if i := bytes.Index(hd.Data[from:], pat); i >= 0 {
+		return from + i
+	}
+	return bytes.Index(hd.Data, pat)
+}
+
+// ParseHexBytes returns the bytes in given hex string, e.g., "de ad be ef",
+// "deadbeef" or "0xde, 0xad"
+func ParseHexBytes(s string) ([]byte, error) {
+	s = strings.ToLower(s)
+	s = strings.Replace(s, "0x", "", -1)
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', ',', ':', '-':
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return nil, fmt.Errorf("no hex bytes")
+	}
+	if len(s)%2 != 0 {
+		return nil, fmt.Errorf("odd number of hex digits: %v", s)
+	}
+	return hex.DecodeString(s)
+}
+
+// HexDumpPos returns the offset of the byte at given line and column of a
+// HexDump starting at given offset, and whether it is in the ASCII column
+// rather than the hex one -- false if the column is not on a byte
+func HexDumpPos(ln, ch int, base int) (int, bool, bool) {
+	if ln < 0 || ch < 10 {
+		return 0, false, false
+	}
+	row := base + 16*ln
+	const ascSt = 10 + 16*3 + 1 + 2 // after the offset, the hex bytes and " |"
+	if ch >= ascSt {
+		j := ch - ascSt
+		if j >= 16 {
+			return 0, false, false
+		}
+		return row + j, true, true
+	}
+	c := ch - 10
+	if c >= 8*3 {
+		c-- // the extra space in the middle
+		if c < 8*3 {
+			return row + 7, false, true
+		}
+	}
+	j := c / 3
+	if j >= 16 {
+		return 0, false, false
+	}
+	return row + j, false, true
+}
+
+// HexDumpCol returns the line and column of the byte at given offset in a
+// HexDump starting at given offset, in the ASCII column if ascii, else the
+// hex one -- the inverse of HexDumpPos
+func HexDumpCol(off, base int, ascii bool) (int, int) {
+	r := off - base
+	ln, j := r/16, r%16
+	if ascii {
+		return ln, 10 + 16*3 + 1 + 2 + j
+	}
+	ch := 10 + 3*j
+	if j >= 8 {
+		ch++
+	}
+	return ln, ch
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import "testing"
+
+func TestHexDocEdits(t *testing.T) {
+	hd := NewHexDoc([]byte("hello world"))
+	if hd.Changed() {
+		t.Errorf("new doc is changed")
+	}
+	hd.Overwrite(0, []byte("J"))
+	hd.Insert(5, []byte(","))
+	hd.Delete(6, 6)
+	if got := string(hd.Data); got != "Jello," {
+		t.Errorf("edits: got %q", got)
+	}
+	if !hd.Changed() {
+		t.Errorf("edited doc is not changed")
+	}
+	hd.Overwrite(4, []byte("o!!"))
+	if got := string(hd.Data); got != "Jello!!" {
+		t.Errorf("overwrite past end: got %q", got)
+	}
+	for _, want := range []string{"Jello,", "Jello, world", "Jello world", "hello world"} {
+		if _, ok := hd.Undo(); !ok {
+			t.Fatalf("undo failed")
+		}
+		if got := string(hd.Data); got != want {
+			t.Errorf("undo: got %q, want %q", got, want)
+		}
+	}
+	if _, ok := hd.Undo(); ok {
+		t.Errorf("undo with nothing to undo")
+	}
+	if hd.Changed() {
+		t.Errorf("doc is changed after undoing all")
+	}
+	if off, ok := hd.Redo(); !ok || off != 0 || string(hd.Data) != "Jello world" {
+		t.Errorf("redo: got %v %v %q", off, ok, hd.Data)
+	}
+	hd.SetSaved()
+	hd.Undo()
+	hd.Insert(0, []byte(">"))
+	hd.Undo()
+	if !hd.Changed() {
+		t.Errorf("doc is not changed after an edit replaced the saved one")
+	}
+	if err := hd.Insert(100, []byte("x")); err == nil {
+		t.Errorf("no error inserting out of range")
+	}
+	if err := hd.Delete(len(hd.Data), 1); err == nil {
+		t.Errorf("no error deleting at the end")
+	}
+}
+
+func TestHexDocFind(t *testing.T) {
+	hd := NewHexDoc([]byte("abcabc"))
+	if got := hd.Find([]byte("bc"), 0); got != 1 {
+		t.Errorf("got %v, want 1", got)
+	}
+	if got := hd.Find([]byte("bc"), 2); got != 4 {
+		t.Errorf("got %v, want 4", got)
+	}
+	if got := hd.Find([]byte("bc"), 5); got != 1 {
+		t.Errorf("wrap: got %v, want 1", got)
+	}
+	if got := hd.Find([]byte("x"), 0); got != -1 {
+		t.Errorf("got %v, want -1", got)
+	}
+}
+
+func TestParseHexBytes(t *testing.T) {
+	for _, s := range []string{"de ad be ef", "DEADBEEF", "0xde, 0xad, 0xbe, 0xef", "de:ad-be:ef"} {
+		b, err := ParseHexBytes(s)
+		if err != nil || string(b) != "\xde\xad\xbe\xef" {
+			t.Errorf("%q: got %x %v", s, b, err)
+		}
+	}
+	for _, s := range []string{"", "abc", "zz"} {
+		if _, err := ParseHexBytes(s); err == nil {
+			t.Errorf("%q: no error", s)
+		}
+	}
+}
+
+func TestHexDumpPos(t *testing.T) {
+	cases := []struct {
+		ln, ch, base, off int
+		ascii, ok         bool
+	}{
+		{0, 10, 16, 16, false, true},
+		{0, 12, 0, 0, false, true},
+		{0, 34, 0, 7, false, true},
+		{0, 35, 0, 8, false, true},
+		{1, 56, 0, 31, false, true},
+		{0, 61, 0, 0, true, true},
+		{0, 76, 0, 15, true, true},
+		{0, 77, 0, 0, false, false},
+		{0, 5, 0, 0, false, false},
+	}
+	for _, c := range cases {
+		off, ascii, ok := HexDumpPos(c.ln, c.ch, c.base)
+		if off != c.off || ascii != c.ascii || ok != c.ok {
+			t.Errorf("%v:%v: got %v %v %v", c.ln, c.ch, off, ascii, ok)
+		}
+	}
+	lns := HexDumpLines(make([]byte, 40), 0)
+	for off := 0; off < 40; off++ {
+		for _, ascii := range []bool{false, true} {
+			ln, ch := HexDumpCol(off, 0, ascii)
+			if ch >= len(lns[ln]) {
+				t.Fatalf("%v: column %v past the line", off, ch)
+			}
+			if got, ga, ok := HexDumpPos(ln, ch, 0); !ok || got != off || ga != ascii {
+				t.Errorf("%v %v: round trip got %v %v %v", off, ascii, got, ga, ok)
+			}
+		}
+	}
+}
@@ -0,0 +1,652 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// BinarySniffSize is how much of the start of a file is looked at to tell
+// whether it is binary
+var BinarySniffSize = 8000
+
+// HexChunk is how many bytes of a binary file are shown at a time in the hex
+// dump view -- more are loaded on demand
+var HexChunk = 64 * 1024
+
+// IsBinary returns true if given start of a file looks like binary data
+// rather than text: it has a NUL byte, or more than a few percent of it is
+// control characters or invalid UTF-8 -- a rune cut off at the end does not
+// count
+func IsBinary(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	bad := 0
+	for i := 0; i < len(b); {
+		c := b[i]
+		if c == 0 {
+			return true
+		}
+		if c < utf8.RuneSelf {
+			if c < 0x20 && c != '\n' && c != '\r' && c != '\t' && c != '\f' && c != '\b' && c != 0x1b {
+				bad++
+			}
+			i++
+			continue
+		}
+		r, sz := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && sz <= 1 {
+			if !utf8.FullRune(b[i:]) {
+				break // cut off at the end
+			}
+			bad++
+		}
+		i += sz
+	}
+	return bad*100 > len(b)*3
+}
+
+// HexDumpLines returns the lines of a hex dump of given bytes, at given
+// offset in the file, 16 bytes per line, e.g.:
+//
+//	00000010  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 0a 00 00 00  |Hello, world....|
+func HexDumpLines(b []byte, off int64) []string {
+	var lns []string
+	for i := 0; i < len(b); i += 16 {
+		row := b[i:]
+		if len(row) > 16 {
+			row = row[:16]
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%08x  ", off+int64(i))
+		for j := 0; j < 16; j++ {
+			if j < len(row) {
+				fmt.Fprintf(&sb, "%02x ", row[j])
+			} else {
+				sb.WriteString("   ")
+			}
+			if j == 7 {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(" |")
+		for _, c := range row {
+			if c < 0x20 || c >= 0x7f {
+				c = '.'
+			}
+			sb.WriteByte(c)
+		}
+		sb.WriteByte('|')
+		lns = append(lns, sb.String())
+	}
+	return lns
+}
+
+// FileIsBinary returns true if the start of given file looks like binary
+// data, by IsBinary
+func FileIsBinary(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	b := make([]byte, BinarySniffSize)
+	n, _ := io.ReadFull(f, b)
+	return IsBinary(b[:n])
+}
+
+// HexTabName returns the name of the tab and buffer of the hex dump view of
+// given file
+func HexTabName(path string) string {
+	return "Hex: " + filepath.Base(path)
+}
+
+// ViewHex shows a read-only hex dump of given file, in a tab of its own --
+// binary files are shown this way instead of being opened in a text view,
+// where they would be garbled, and saving would corrupt them
+func (ge *Gide) ViewHex(fnm gi.FileName) {
+	path := string(fnm)
+	nm := HexTabName(path)
+	tbuf, _ := ge.FindOrMakeCmdBuf(nm, true)
+	hvi, _ := ge.FindOrMakeMainTab(nm, KiT_HexView, true) // sel
+	hv := hvi.Embed(KiT_HexView).(*HexView)
+	hv.UpdateView(ge)
+	htv := hv.TextView()
+	htv.SetInactive()
+	htv.SetBuf(tbuf)
+	hv.Open(path)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// HexView is a widget that shows a read-only hex dump of a binary file, a
+// chunk at a time, or edits its bytes in hex editor mode
+type HexView struct {
+	gi.Layout
+	Gide    *Gide   `json:"-" xml:"-" desc:"parent gide project"`
+	Path    string  `desc:"path to the file"`
+	Size    int64   `desc:"size of the file"`
+	Shown   int64   `desc:"number of bytes shown so far, from the start"`
+	Edit    bool    `desc:"true in hex editor mode, where the whole file is loaded into Doc, and a window of it is shown"`
+	Doc     *HexDoc `json:"-" xml:"-" desc:"the bytes being edited, in hex editor mode"`
+	Win     int     `desc:"offset of the start of the window of the bytes shown, in hex editor mode"`
+	Off     int     `desc:"offset of the byte at the cursor, in hex editor mode"`
+	AsText  bool    `desc:"true if the cursor is in the ASCII column, where bytes are entered as text rather than hex"`
+	barEdit bool    `desc:"which mode the toolbar is configured for"`
+}
+
+var KiT_HexView = kit.Types.AddType(&HexView{}, HexViewProps)
+
+// Open shows the first chunk of given file
+func (hv *HexView) Open(path string) {
+	hv.Path = path
+	hv.Size = 0
+	hv.Shown = 0
+	hv.Edit = false
+	hv.Doc = nil
+	hv.UpdateView(hv.Gide)
+	if fi, err := os.Stat(path); err == nil {
+		hv.Size = fi.Size()
+	}
+	tbuf, _ := hv.Gide.FindOrMakeCmdBuf(HexTabName(path), true)
+	tbuf.New(0)
+	hv.More()
+}
+
+// More shows the next chunk of the file, after the ones shown
+func (hv *HexView) More() {
+	if hv.Shown >= hv.Size && hv.Shown > 0 {
+		return
+	}
+	f, err := os.Open(hv.Path)
+	if err != nil {
+		hv.Gide.SetStatus(fmt.Sprintf("Could not open file: %v", err))
+		return
+	}
+	defer f.Close()
+	b := make([]byte, HexChunk)
+	n, err := f.ReadAt(b, hv.Shown)
+	if err != nil && err != io.EOF {
+		hv.Gide.SetStatus(fmt.Sprintf("Could not read file: %v", err))
+		return
+	}
+	lns := HexDumpLines(b[:n], hv.Shown)
+	hv.Shown += int64(n)
+	tbuf, _ := hv.Gide.FindOrMakeCmdBuf(HexTabName(hv.Path), true)
+	tbuf.AppendText([]byte(strings.Join(lns, "\n")+"\n"), false, true) // no save undo, yes signal
+	if lbl := hv.InfoLabel(); lbl != nil {
+		lbl.SetText(fmt.Sprintf("%v bytes of %v", hv.Shown, hv.Size))
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Hex editor mode
+
+// HexEditMax is the largest file that can be opened in hex editor mode,
+// which loads all of it
+var HexEditMax = 16 * 1024 * 1024
+
+// HexEdit opens given file in hex editor mode, in a tab of its own, to edit
+// its bytes in either the hex or the ASCII column, insert and delete them
+// with undo, and search for hex patterns or strings
+func (ge *Gide) HexEdit(fnm gi.FileName) {
+	path := string(fnm)
+	nm := HexTabName(path)
+	tbuf, _ := ge.FindOrMakeCmdBuf(nm, true)
+	hvi, _ := ge.FindOrMakeMainTab(nm, KiT_HexView, true) // sel
+	hv := hvi.Embed(KiT_HexView).(*HexView)
+	hv.UpdateView(ge)
+	htv := hv.TextView()
+	htv.SetInactive()
+	htv.SetBuf(tbuf)
+	hv.OpenEdit(path)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// ReopenAsHex reopens the file of the active view in hex editor mode -- any
+// unsaved changes to it in the view are not in the hex editor
+func (ge *Gide) ReopenAsHex() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf.Filename == "" {
+		ge.SetStatus("No file in the active view to reopen as hex")
+		return
+	}
+	if tv.Buf.IsChanged() {
+		ge.SetStatus("The file has unsaved changes, which are not in the hex editor")
+	}
+	ge.HexEdit(tv.Buf.Filename)
+}
+
+// OpenEdit opens given file in hex editor mode, loading all of it
+func (hv *HexView) OpenEdit(path string) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		hv.Gide.SetStatus(fmt.Sprintf("Could not open file: %v", err))
+		return
+	}
+	if fi.Size() > int64(HexEditMax) {
+		hv.Gide.SetStatus(fmt.Sprintf("File is too large to edit as hex: %v bytes, more than %v -- use View As Hex", fi.Size(), HexEditMax))
+		return
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		hv.Gide.SetStatus(fmt.Sprintf("Could not read file: %v", err))
+		return
+	}
+	hv.Path = path
+	hv.Size = int64(len(b))
+	hv.Doc = NewHexDoc(b)
+	hv.Edit = true
+	hv.Off = 0
+	hv.AsText = false
+	hv.UpdateView(hv.Gide)
+	hv.ShowWindow(0)
+}
+
+// ShowWindow shows the window of HexChunk bytes starting at given offset,
+// rounded down to a line
+func (hv *HexView) ShowWindow(win int) {
+	if hv.Doc == nil {
+		return
+	}
+	n := len(hv.Doc.Data)
+	if win > n-HexChunk {
+		win = n - HexChunk
+	}
+	if win < 0 {
+		win = 0
+	}
+	win -= win % 16
+	ed := win + HexChunk
+	if ed > n {
+		ed = n
+	}
+	hv.Win = win
+	hv.Shown = int64(ed)
+	tbuf, _ := hv.Gide.FindOrMakeCmdBuf(HexTabName(hv.Path), true)
+	tbuf.SetText([]byte(strings.Join(HexDumpLines(hv.Doc.Data[win:ed], int64(win)), "\n") + "\n"))
+	hv.UpdateInfo()
+}
+
+// UpdateInfo shows the window, the cursor offset and whether the file has
+// been changed in the toolbar
+func (hv *HexView) UpdateInfo() {
+	lbl := hv.InfoLabel()
+	if lbl == nil || hv.Doc == nil {
+		return
+	}
+	col := "hex"
+	if hv.AsText {
+		col = "text"
+	}
+	msg := fmt.Sprintf("bytes %d-%d of %d -- offset %d (0x%x), %v", hv.Win, hv.Shown, len(hv.Doc.Data), hv.Off, hv.Off, col)
+	if hv.Doc.Changed() {
+		msg += " -- changed"
+	}
+	lbl.SetText(msg)
+}
+
+// ShowOffset moves the cursor to the byte at given offset, showing the
+// window with it if it is not shown
+func (hv *HexView) ShowOffset(off int) {
+	if hv.Doc == nil {
+		return
+	}
+	if off > len(hv.Doc.Data) {
+		off = len(hv.Doc.Data)
+	}
+	if off < 0 {
+		off = 0
+	}
+	hv.Off = off
+	if off < hv.Win || off >= int(hv.Shown) {
+		hv.ShowWindow(off - HexChunk/4)
+	}
+	ln, ch := HexDumpCol(off, hv.Win, hv.AsText)
+	if tv := hv.TextView(); tv != nil && tv.Buf != nil && ln < len(tv.Buf.Lines) {
+		tv.SetCursorShow(giv.TextPos{Ln: ln, Ch: ch})
+	}
+	hv.UpdateInfo()
+}
+
+// CursorMoved sets the offset, and the column bytes are entered in, from
+// given cursor position in the dump, in hex editor mode
+func (hv *HexView) CursorMoved(pos giv.TextPos) {
+	if !hv.Edit || hv.Doc == nil {
+		return
+	}
+	off, ascii, ok := HexDumpPos(pos.Ln, pos.Ch, hv.Win)
+	if !ok || off >= len(hv.Doc.Data) {
+		return
+	}
+	hv.Off = off
+	hv.AsText = ascii
+	hv.UpdateInfo()
+}
+
+// ValueBytes returns the bytes of given value: hex bytes, e.g., "de ad",
+// or text if the cursor is in the ASCII column
+func (hv *HexView) ValueBytes(val string) ([]byte, error) {
+	if hv.AsText {
+		if val == "" {
+			return nil, fmt.Errorf("no text")
+		}
+		return []byte(val), nil
+	}
+	return ParseHexBytes(val)
+}
+
+// edited shows the bytes after an edit at given offset, or the error
+func (hv *HexView) edited(off int, err error) {
+	if err != nil {
+		hv.Gide.SetStatus(err.Error())
+		return
+	}
+	hv.ShowWindow(hv.Win)
+	hv.ShowOffset(off)
+}
+
+// Overwrite replaces the bytes at the cursor with those of given value, hex
+// bytes or text by the column of the cursor, moving it past them
+func (hv *HexView) Overwrite(val string) {
+	b, err := hv.ValueBytes(val)
+	if err != nil || hv.Doc == nil {
+		hv.edited(0, err)
+		return
+	}
+	hv.edited(hv.Off+len(b), hv.Doc.Overwrite(hv.Off, b))
+}
+
+// Insert inserts the bytes of given value at the cursor, hex bytes or text
+// by the column of the cursor, moving it past them
+func (hv *HexView) Insert(val string) {
+	b, err := hv.ValueBytes(val)
+	if err != nil || hv.Doc == nil {
+		hv.edited(0, err)
+		return
+	}
+	hv.edited(hv.Off+len(b), hv.Doc.Insert(hv.Off, b))
+}
+
+// Delete deletes the selected bytes, or the one at the cursor if none are
+// selected
+func (hv *HexView) Delete() {
+	if hv.Doc == nil {
+		return
+	}
+	off, n := hv.Off, 1
+	if tv := hv.TextView(); tv != nil && tv.HasSelection() {
+		st, _, sok := HexDumpPos(tv.SelectReg.Start.Ln, tv.SelectReg.Start.Ch, hv.Win)
+		ed, _, eok := HexDumpPos(tv.SelectReg.End.Ln, tv.SelectReg.End.Ch, hv.Win)
+		if sok && eok && ed >= st {
+			off, n = st, ed-st+1
+		}
+	}
+	hv.edited(off, hv.Doc.Delete(off, n))
+}
+
+// Undo undoes the last edit
+func (hv *HexView) Undo() {
+	if hv.Doc == nil {
+		return
+	}
+	off, ok := hv.Doc.Undo()
+	if !ok {
+		hv.Gide.SetStatus("Nothing to undo")
+		return
+	}
+	hv.edited(off, nil)
+}
+
+// Redo redoes the last edit undone
+func (hv *HexView) Redo() {
+	if hv.Doc == nil {
+		return
+	}
+	off, ok := hv.Doc.Redo()
+	if !ok {
+		hv.Gide.SetStatus("Nothing to redo")
+		return
+	}
+	hv.edited(off, nil)
+}
+
+// Find moves the cursor to the next occurrence of given hex bytes, or text
+// if not isHex, after the cursor, wrapping around to the start
+func (hv *HexView) Find(pat string, isHex bool) {
+	if hv.Doc == nil {
+		return
+	}
+	b := []byte(pat)
+	if isHex {
+		var err error
+		if b, err = ParseHexBytes(pat); err != nil {
+			hv.Gide.SetStatus(err.Error())
+			return
+		}
+	}
+	off := hv.Doc.Find(b, hv.Off+1)
+	if off < 0 {
+		hv.Gide.SetStatus(fmt.Sprintf("Not found: %v", pat))
+		return
+	}
+	hv.ShowOffset(off)
+	if tv := hv.TextView(); tv != nil {
+		sl, sc := HexDumpCol(off, hv.Win, hv.AsText)
+		el, ec := HexDumpCol(off+len(b)-1, hv.Win, hv.AsText)
+		if !hv.AsText {
+			ec += 2
+		} else {
+			ec++
+		}
+		tv.HighlightRegion(giv.TextRegion{Start: giv.TextPos{Ln: sl, Ch: sc}, End: giv.TextPos{Ln: el, Ch: ec}})
+	}
+}
+
+// Save saves the edited bytes to the file
+func (hv *HexView) Save() {
+	if hv.Doc == nil {
+		return
+	}
+	perm := os.FileMode(0644)
+	var before int64
+	if fi, err := os.Stat(hv.Path); err == nil {
+		perm = fi.Mode().Perm()
+		before = fi.Size()
+	}
+	if err := ioutil.WriteFile(hv.Path, hv.Doc.Data, perm); err != nil {
+		hv.Gide.SetStatus(fmt.Sprintf("Could not save file: %v", err))
+		return
+	}
+	hv.Gide.AuditWrite("hex", hv.Path, before, "")
+	hv.Doc.SetSaved()
+	hv.Size = int64(len(hv.Doc.Data))
+	hv.UpdateInfo()
+	msg := fmt.Sprintf("Saved %v", hv.Path)
+	for _, fn := range hv.Gide.OpenNodes {
+		if string(fn.FPath) == hv.Path && fn.Buf != nil {
+			msg += " -- it is also open as text, which does not have the changes: revert it there"
+			break
+		}
+	}
+	hv.Gide.SetStatus(msg)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (hv *HexView) UpdateView(ge *Gide) {
+	hv.Gide = ge
+	hv.Lay = gi.LayoutVert
+	hv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "hexbar")
+	config.Add(gi.KiT_Layout, "hextext")
+	mods, updt := hv.ConfigChildren(config, false)
+	hv.ConfigToolbar()
+	tv := ge.ConfigOutputTextView(hv.TextViewLay())
+	if mods {
+		tv.TextViewSig.Connect(hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(giv.TextViewCursorMoved) {
+				return
+			}
+			hvv, _ := recv.Embed(KiT_HexView).(*HexView)
+			hvv.CursorMoved(send.Embed(giv.KiT_TextView).(*giv.TextView).CursorPos)
+		})
+		hv.UpdateEnd(updt)
+	}
+}
+
+// HexBar returns the hex dump toolbar
+func (hv *HexView) HexBar() *gi.ToolBar {
+	tbi, ok := hv.ChildByName("hexbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// InfoLabel returns the label in the toolbar with how much is shown
+func (hv *HexView) InfoLabel() *gi.Label {
+	tb := hv.HexBar()
+	if tb == nil {
+		return nil
+	}
+	lbi, ok := tb.ChildByName("info", 1)
+	if !ok {
+		return nil
+	}
+	return lbi.(*gi.Label)
+}
+
+// TextViewLay returns the layout of the TextView with the hex dump
+func (hv *HexView) TextViewLay() *gi.Layout {
+	tvi, ok := hv.ChildByName("hextext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the TextView with the hex dump
+func (hv *HexView) TextView() *giv.TextView {
+	tvly := hv.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar, for the mode of the view
+func (hv *HexView) ConfigToolbar() {
+	tb := hv.HexBar()
+	if tb.HasChildren() && hv.barEdit == hv.Edit {
+		return
+	}
+	tb.DeleteChildren(true)
+	hv.barEdit = hv.Edit
+	tb.SetStretchMaxWidth()
+	if hv.Edit {
+		hv.ConfigEditToolbar(tb)
+		return
+	}
+
+	more := tb.AddNewChild(gi.KiT_Action, "more").(*gi.Action)
+	more.SetText("More")
+	more.Tooltip = fmt.Sprintf("show the next %v bytes of the file", HexChunk)
+	more.ActionSig.Connect(hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		hvv, _ := recv.Embed(KiT_HexView).(*HexView)
+		hvv.More()
+	})
+
+	edit := tb.AddNewChild(gi.KiT_Action, "edit").(*gi.Action)
+	edit.SetText("Edit")
+	edit.Tooltip = "reopen the file in hex editor mode, to edit its bytes"
+	edit.ActionSig.Connect(hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		hvv, _ := recv.Embed(KiT_HexView).(*HexView)
+		hvv.OpenEdit(hvv.Path)
+	})
+
+	tb.AddNewChild(gi.KiT_Label, "info")
+}
+
+// ConfigEditToolbar adds the actions of hex editor mode to given toolbar
+func (hv *HexView) ConfigEditToolbar(tb *gi.ToolBar) {
+	act := func(nm, txt, tip string, fun func(hvv *HexView)) {
+		ac := tb.AddNewChild(gi.KiT_Action, nm).(*gi.Action)
+		ac.SetText(txt)
+		ac.Tooltip = tip
+		ac.ActionSig.Connect(hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			hvv, _ := recv.Embed(KiT_HexView).(*HexView)
+			fun(hvv)
+		})
+	}
+	act("save", "Save", "save the edited bytes to the file", func(hvv *HexView) { hvv.Save() })
+	act("undo", "Undo", "undo the last edit", func(hvv *HexView) { hvv.Undo() })
+	act("redo", "Redo", "redo the last edit undone", func(hvv *HexView) { hvv.Redo() })
+	act("prev", "Prev", fmt.Sprintf("show the previous %v bytes", HexChunk), func(hvv *HexView) { hvv.ShowWindow(hvv.Win - HexChunk) })
+	act("next", "Next", fmt.Sprintf("show the next %v bytes", HexChunk), func(hvv *HexView) { hvv.ShowWindow(hvv.Win + HexChunk) })
+
+	tb.AddSeparator("sep-edit")
+	val := tb.AddNewChild(gi.KiT_TextField, "value").(*gi.TextField)
+	val.SetProp("width", "12em")
+	val.Tooltip = "bytes to enter at the cursor: hex, e.g., de ad be ef, with the cursor in the hex column, or text with it in the ASCII column -- hit enter to overwrite"
+	val.TextFieldSig.Connect(hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			hvv, _ := recv.Embed(KiT_HexView).(*HexView)
+			hvv.Overwrite(send.(*gi.TextField).Text())
+		}
+	})
+	act("overwrite", "Overwrite", "replace the bytes at the cursor with the value", func(hvv *HexView) { hvv.Overwrite(val.Text()) })
+	act("insert", "Insert", "insert the value at the cursor", func(hvv *HexView) { hvv.Insert(val.Text()) })
+	act("delete", "Delete", "delete the selected bytes, or the one at the cursor", func(hvv *HexView) { hvv.Delete() })
+
+	tb.AddSeparator("sep-find")
+	goff := tb.AddNewChild(gi.KiT_TextField, "goto").(*gi.TextField)
+	goff.SetProp("width", "8em")
+	goff.Tooltip = "offset to go to, decimal or 0x hex -- hit enter to show it"
+	goff.TextFieldSig.Connect(hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			hvv, _ := recv.Embed(KiT_HexView).(*HexView)
+			if off, err := strconv.ParseInt(strings.TrimSpace(send.(*gi.TextField).Text()), 0, 64); err == nil {
+				hvv.ShowOffset(int(off))
+			}
+		}
+	})
+	ih := tb.AddNewChild(gi.KiT_CheckBox, "find-hex").(*gi.CheckBox)
+	ih.SetText("Hex")
+	ih.Tooltip = "find hex bytes, e.g., de ad be ef, rather than text"
+	find := tb.AddNewChild(gi.KiT_TextField, "find").(*gi.TextField)
+	find.SetStretchMaxWidth()
+	find.Tooltip = "text, or hex bytes if Hex is checked, to find after the cursor -- hit enter to find the next one"
+	find.TextFieldSig.Connect(hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			hvv, _ := recv.Embed(KiT_HexView).(*HexView)
+			hvv.Find(send.(*gi.TextField).Text(), ih.IsChecked())
+		}
+	})
+
+	tb.AddNewChild(gi.KiT_Label, "info")
+}
+
+var HexViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
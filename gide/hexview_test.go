@@ -0,0 +1,42 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import "testing"
+
+func TestIsBinary(t *testing.T) {
+	cases := []struct {
+		b    string
+		want bool
+	}{
+		{"", false},
+		{"package gide\n\nfunc main() {}\n", false},
+		{"héllo wörld, ünïcode\r\n\ttabbed\n", false},
+		{"PNG\r\n\x1a\n\x00\x00\x00\rIHDR", true},
+		{"\x7fELF\x02\x01\x01" + string([]byte{0xff, 0xfe, 0x80, 0x81, 0x90, 0x91}), true},
+		{"text ending in a cut-off rune \xe2\x82", false},
+	}
+	for _, c := range cases {
+		if got := IsBinary([]byte(c.b)); got != c.want {
+			t.Errorf("%q: got %v, want %v", c.b, got, c.want)
+		}
+	}
+}
+
+func TestHexDumpLines(t *testing.T) {
+	lns := HexDumpLines([]byte("Hello, world\n\x00\x01\x02Z"), 16)
+	want := []string{
+		"00000010  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 0a 00 01 02  |Hello, world....|",
+		"00000020  5a                                                |Z|",
+	}
+	if len(lns) != len(want) {
+		t.Fatalf("got %d lines, want %d: %q", len(lns), len(want), lns)
+	}
+	for i := range want {
+		if lns[i] != want[i] {
+			t.Errorf("line %d:\ngot  %q\nwant %q", i, lns[i], want[i])
+		}
+	}
+}
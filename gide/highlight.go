@@ -0,0 +1,416 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/goki/gi/giv"
+)
+
+// HiTokenizer returns an iterator over the tokens of given text, each with
+// the css class for its type (empty for plain text) -- ok is false at the end
+type HiTokenizer func(src string) func() (cls, val string, ok bool)
+
+// ChromaTokenizer returns a tokenizer using given chroma lexer, with the
+// standard chroma css classes, as used by the highlighting styles
+func ChromaTokenizer(lx chroma.Lexer) HiTokenizer {
+	lx = chroma.Coalesce(lx)
+	return func(src string) func() (string, string, bool) {
+		it, err := lx.Tokenise(nil, src)
+		if err != nil {
+			return func() (string, string, bool) { return "", "", false }
+		}
+		return func() (string, string, bool) {
+			tok := it()
+			if tok == chroma.EOF {
+				return "", "", false
+			}
+			return chroma.StandardTypes[tok.Type], tok.Value, true
+		}
+	}
+}
+
+// HiShebangLexers are the chroma lexers for the interpreters named in the
+// #! line at the start of scripts, for files whose name does not say what
+// language they are in -- version numbers are ignored, e.g., python3.8
+var HiShebangLexers = map[string]string{
+	"sh":      "bash",
+	"bash":    "bash",
+	"dash":    "bash",
+	"ksh":     "bash",
+	"zsh":     "bash",
+	"python":  "python",
+	"pypy":    "python",
+	"node":    "javascript",
+	"nodejs":  "javascript",
+	"deno":    "typescript",
+	"ts-node": "typescript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+	"lua":     "lua",
+	"tclsh":   "tcl",
+	"awk":     "awk",
+	"gawk":    "awk",
+	"make":    "make",
+	"fish":    "fish",
+}
+
+// ShebangLexer returns the name of the chroma lexer for the interpreter in
+// given first line of a file, if it is a #! line, e.g., #!/usr/bin/env
+// python3 -- empty if none
+func ShebangLexer(line string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	flds := strings.Fields(line[2:])
+	for i, f := range flds {
+		cmd := filepath.Base(f)
+		if i == 0 && cmd == "env" || strings.HasPrefix(f, "-") || strings.Contains(f, "=") {
+			continue // env, its flags, and any variables it sets
+		}
+		if lx, ok := HiShebangLexers[cmd]; ok {
+			return lx
+		}
+		return HiShebangLexers[strings.TrimRight(cmd, "0123456789.")]
+	}
+	return ""
+}
+
+// HiLexerFor returns the chroma lexer for given file, from its name, or
+// else from the #! line that is its given first line -- nil if none
+func HiLexerFor(fname, first string) chroma.Lexer {
+	if lx := lexers.Match(filepath.Base(fname)); lx != nil {
+		return lx
+	}
+	if nm := ShebangLexer(first); nm != "" {
+		return lexers.Get(nm)
+	}
+	return nil
+}
+
+// HiState is the highlighting markup of a text, which is updated
+// incrementally as the text is edited: only the lines from just before the
+// first changed one are re-tokenized, and only until the tokens are back in
+// step with those of the unchanged lines after the last changed one
+type HiState struct {
+	Tokenize HiTokenizer `desc:"tokenizer for the language of the text -- nil if there is none"`
+	Key      string      `desc:"what the tokenizer was chosen for, e.g., the file name"`
+	Text     []string    `desc:"lines of text that the markup is for"`
+	Markup   [][]byte    `desc:"markup of each line"`
+	Spans    [][]HiSpan  `desc:"spans of text with a class in each line, from which the markup is made -- for layering other highlighting on top, e.g., semantic tokens"`
+	Fresh    []bool      `desc:"whether tokenizing can restart at each line -- it starts at the start of a token, in the first column"`
+	Deco     bool        `desc:"whether the markup was decorated with whitespace or rulers the last time -- see LineDeco"`
+}
+
+// HiSpan is a span of the text of a line, in runes [St, Ed), with a css class
+type HiSpan struct {
+	St, Ed int
+	Cls    string
+}
+
+// hiFreshLine returns true if tokenizing can restart at given line, if it
+// starts with a token: it has text in the first column, which is usually at
+// the top level, e.g., a declaration
+func hiFreshLine(line string) bool {
+	return line != "" && line[0] != ' ' && line[0] != '\t'
+}
+
+// hiWriteSpan writes the markup for given text of a token with given class
+func hiWriteSpan(b *bytes.Buffer, cls, s string) {
+	switch {
+	case s == "":
+	case cls == "":
+		b.WriteString(html.EscapeString(s))
+	default:
+		fmt.Fprintf(b, `<span class="%s">%s</span>`, cls, html.EscapeString(s))
+	}
+}
+
+// HiMarkupLine returns the markup for given line of text, with the classes
+// of the spans of over in place of those of the spans of base, and the text
+// of the spans of under underlined, e.g., misspelled words
+func HiMarkupLine(line string, base, over, under []HiSpan) []byte {
+	rs := []rune(line)
+	cls := make([]string, len(rs))
+	for _, sps := range [][]HiSpan{base, over} {
+		for _, sp := range sps {
+			for i := sp.St; i < sp.Ed && i < len(rs); i++ {
+				cls[i] = sp.Cls
+			}
+		}
+	}
+	ul := make([]bool, len(rs))
+	for _, sp := range under {
+		for i := sp.St; i < sp.Ed && i < len(rs); i++ {
+			ul[i] = true
+		}
+	}
+	var b bytes.Buffer
+	st := 0
+	for i := 1; i <= len(rs); i++ {
+		if i == len(rs) || cls[i] != cls[st] || ul[i] != ul[st] {
+			if ul[st] {
+				b.WriteString("<u>")
+			}
+			hiWriteSpan(&b, cls[st], string(rs[st:i]))
+			if ul[st] {
+				b.WriteString("</u>")
+			}
+			st = i
+		}
+	}
+	return b.Bytes()
+}
+
+// Update updates the markup for given new lines of the text, and returns the
+// number of lines that were re-tokenized
+func (hs *HiState) Update(lines []string) int {
+	ol := hs.Text
+	st := 0
+	for st < len(ol) && st < len(lines) && ol[st] == lines[st] {
+		st++
+	}
+	if st == len(ol) && st == len(lines) {
+		return 0
+	}
+	oe, ne := len(ol), len(lines)
+	for oe > st && ne > st && ol[oe-1] == lines[ne-1] {
+		oe--
+		ne--
+	}
+	shift := len(lines) - len(ol)
+	r := st
+	if r >= len(ol) {
+		r = len(ol) - 1
+	}
+	for r > 0 && !hs.Fresh[r] {
+		r--
+	}
+	if r < 0 {
+		r = 0
+	}
+	mu := append(make([][]byte, 0, len(lines)), hs.Markup[:r]...)
+	fr := append(make([]bool, 0, len(lines)), hs.Fresh[:r]...)
+	sp := append(make([][]HiSpan, 0, len(lines)), hs.Spans[:r]...)
+	hs.Text = append([]string(nil), lines...)
+
+	var b bytes.Buffer
+	var lsp []HiSpan
+	ln, fresh, col := r, true, 0
+	it := hs.Tokenize(strings.Join(lines[r:], "\n"))
+	for {
+		cls, val, ok := it()
+		if !ok {
+			break
+		}
+		pcs := strings.Split(val, "\n")
+		for k, s := range pcs {
+			if k > 0 {
+				mu = append(mu, append([]byte(nil), b.Bytes()...))
+				fr = append(fr, fresh)
+				sp = append(sp, lsp)
+				b.Reset()
+				lsp, col = nil, 0
+				ln++
+				fresh = k == len(pcs)-1 && s == "" && ln < len(lines) && hiFreshLine(lines[ln])
+				if fresh && ln >= ne && ln < len(lines) && hs.Fresh[ln-shift] { // back in step
+					n := ln - r
+					hs.Markup = append(mu, hs.Markup[ln-shift:]...)
+					hs.Fresh = append(fr, hs.Fresh[ln-shift:]...)
+					hs.Spans = append(sp, hs.Spans[ln-shift:]...)
+					return n
+				}
+			}
+			hiWriteSpan(&b, cls, s)
+			n := utf8.RuneCountInString(s)
+			if cls != "" && n > 0 {
+				lsp = append(lsp, HiSpan{col, col + n, cls})
+			}
+			col += n
+		}
+	}
+	if ln < len(lines) {
+		mu = append(mu, append([]byte(nil), b.Bytes()...))
+		fr = append(fr, fresh)
+		sp = append(sp, lsp)
+	}
+	for len(mu) < len(lines) {
+		mu = append(mu, []byte(html.EscapeString(lines[len(mu)])))
+		fr = append(fr, false)
+		sp = append(sp, nil)
+	}
+	hs.Markup, hs.Fresh, hs.Spans = mu[:len(lines)], fr[:len(lines)], sp[:len(lines)]
+	return len(lines) - r
+}
+
+// HiMarkupBuf updates the highlighting markup of given buffer with the
+// chroma lexer for its file name or #! line, incrementally from the last
+// time, so that only the edited lines and those after them that they change
+// are re-highlighted, with any semantic highlighting from the language
+// server, underlining of misspelled words, and indent guides, whitespace
+// and rulers (see LineDecoFor) on top -- called when the markup of the buffer is updated
+func (ge *Gide) HiMarkupBuf(tb *giv.TextBuf) {
+	if len(tb.Lines) == 0 {
+		return
+	}
+	ge.hiMu.Lock()
+	defer ge.hiMu.Unlock()
+	fname := string(tb.Filename)
+	first := string(tb.Lines[0])
+	key := fname
+	if filepath.Ext(fname) == "" && strings.HasPrefix(first, "#!") {
+		key += "\n" + first
+	}
+	hs := ge.hiStates[tb]
+	if hs == nil || hs.Key != key {
+		ge.hiPrune()
+		hs = &HiState{Key: key}
+		if lx := HiLexerFor(fname, first); lx != nil {
+			hs.Tokenize = ChromaTokenizer(lx)
+		}
+		ge.hiStates[tb] = hs
+	}
+	deco := ge.LineDecoFor(tb)
+	if hs.Tokenize == nil && !SpellFullText(fname) && !deco.Shows() && !hs.Deco && !ge.diagsMaybe(tb) { // no lexer: leave it as it is
+		return
+	}
+	lines := make([]string, len(tb.Lines))
+	for i, l := range tb.Lines {
+		lines[i] = string(l)
+	}
+	if hs.Tokenize != nil {
+		hs.Update(lines)
+		for ln := range hs.Markup {
+			if ln >= len(tb.Markup) {
+				break
+			}
+			tb.Markup[ln] = hs.Markup[ln]
+		}
+		ge.SemanticMarkupBuf(tb, hs, lines)
+	}
+	ge.SpellMarkupBuf(tb, hs, lines)
+	ge.DiagMarkupBuf(tb, lines)
+	ge.hiOverlay(tb, hs, lines, deco)
+	hs.Deco = deco.Shows() || ge.diagStates[tb] != nil
+}
+
+// LineDeco is how lines are decorated on top of their highlighting, with
+// indent guides and whitespace, and rulers
+type LineDeco struct {
+	Ws      WsShow `desc:"what is shown of the whitespace"`
+	Rulers  []int  `desc:"columns of the rulers"`
+	HiLong  bool   `desc:"highlight the text beyond the last ruler"`
+	TabSize int    `desc:"size of a tab, in columns"`
+}
+
+// Shows returns true if lines are decorated at all
+func (ld *LineDeco) Shows() bool {
+	return ld.Ws.Shows() || len(ld.Rulers) > 0
+}
+
+// Decorate returns given line with the glyphs for its whitespace, and the
+// spans for its decorations, with classes (over) and underlined (under) --
+// the line keeps its number of runes
+func (ld *LineDeco) Decorate(line string) (disp string, over, under []HiSpan) {
+	disp, over, under = WsDecorate(line, ld.Ws, ld.TabSize)
+	rover, runder := RulerSpans(line, ld.Rulers, ld.TabSize, ld.HiLong)
+	return disp, append(over, rover...), append(under, runder...)
+}
+
+// hiOverlay applies the semantic highlighting of given buffer, if it is for
+// its current lines, the underlining of misspelled words and of the ranges
+// of diagnostics, with their messages at the ends of the lines, and the
+// given decorations, on top of its lexical highlighting in hs -- must be
+// called with hiMu locked
+func (ge *Gide) hiOverlay(tb *giv.TextBuf, hs *HiState, lines []string, deco LineDeco) {
+	var sem map[int][]HiSpan
+	if ss := ge.semStates[tb]; ss != nil && ss.Spans != nil && ss.Text == strings.Join(lines, "\n") {
+		sem = ss.Spans
+	}
+	var bad [][]HiSpan
+	if sp := ge.spellStates[tb]; sp != nil && len(sp.Bad) == len(lines) {
+		bad = sp.Bad
+	}
+	dgs := ge.diagStates[tb]
+	if dgs != nil && dgs.Under != nil && len(dgs.Under) != len(lines) {
+		dgs = &DiagState{Lens: dgs.Lens} // ranges are for other lines
+	}
+	plain := hs.Tokenize == nil // markup is restored to the plain text
+	if sem == nil && bad == nil && dgs == nil && !deco.Shows() && !(plain && hs.Deco) {
+		return
+	}
+	for ln := range lines {
+		if ln >= len(tb.Markup) {
+			break
+		}
+		line, over := lines[ln], sem[ln]
+		var under []HiSpan
+		if bad != nil {
+			under = bad[ln]
+		}
+		var lens []byte
+		if dgs != nil {
+			if dgs.Under != nil && dgs.Under[ln] != nil {
+				under = append(append([]HiSpan(nil), under...), dgs.Under[ln]...)
+			}
+			lens = dgs.Lens[ln]
+		}
+		if deco.Shows() {
+			var wover, wunder []HiSpan
+			line, wover, wunder = deco.Decorate(line)
+			if wover != nil {
+				over = append(append([]HiSpan(nil), over...), wover...)
+			}
+			if wunder != nil {
+				under = append(append([]HiSpan(nil), under...), wunder...)
+			}
+		}
+		if over == nil && under == nil {
+			switch {
+			case plain:
+				tb.Markup[ln] = append([]byte(html.EscapeString(line)), lens...)
+			case lens != nil && ln < len(hs.Markup):
+				tb.Markup[ln] = append(append([]byte(nil), hs.Markup[ln]...), lens...)
+			}
+			continue
+		}
+		var base []HiSpan
+		if ln < len(hs.Spans) {
+			base = hs.Spans[ln]
+		}
+		tb.Markup[ln] = append(HiMarkupLine(line, base, over, under), lens...)
+	}
+}
+
+// hiPrune removes the highlighting state, lexical, semantic and spelling, of
+// buffers that are no longer open
+func (ge *Gide) hiPrune() {
+	if ge.hiStates == nil {
+		ge.hiStates = make(map[*giv.TextBuf]*HiState)
+		return
+	}
+	open := make(map[*giv.TextBuf]bool)
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil {
+			open[ond.Buf] = true
+		}
+	}
+	for tb := range ge.hiStates {
+		if !open[tb] {
+			delete(ge.hiStates, tb)
+			delete(ge.semStates, tb)
+			delete(ge.diagStates, tb)
+		}
+	}
+}
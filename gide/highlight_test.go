@@ -0,0 +1,122 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestShebangLexer(t *testing.T) {
+	for line, want := range map[string]string{
+		"#!/bin/sh":                      "bash",
+		"#!/usr/bin/env python3":         "python",
+		"#!/usr/bin/env -S python3.8 -u": "python",
+		"#!/usr/bin/env FOO=1 node":      "javascript",
+		"#! /usr/bin/perl -w":            "perl",
+		"#!/usr/local/bin/unknown":       "",
+		"# not a shebang":                "",
+	} {
+		if got := ShebangLexer(line); got != want {
+			t.Errorf("ShebangLexer(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+// hiTestTokenizer tokenizes /* */ comments, which can span lines, as class
+// "c", and everything else as plain text, one token per line
+func hiTestTokenizer(src string) func() (string, string, bool) {
+	return func() (string, string, bool) {
+		if src == "" {
+			return "", "", false
+		}
+		if strings.HasPrefix(src, "/*") {
+			e := strings.Index(src, "*/")
+			if e < 0 {
+				e = len(src)
+			} else {
+				e += 2
+			}
+			tok := src[:e]
+			src = src[e:]
+			return "c", tok, true
+		}
+		e := len(src)
+		if i := strings.Index(src, "/*"); i >= 0 {
+			e = i
+		}
+		if i := strings.Index(src, "\n"); i >= 0 && i+1 < e {
+			e = i + 1
+		}
+		tok := src[:e]
+		src = src[e:]
+		return "", tok, true
+	}
+}
+
+func TestHiStateUpdate(t *testing.T) {
+	text := []string{
+		"func a() {",
+		"	x /* in */ y",
+		"}",
+		"",
+		"func b() {",
+		"	z",
+		"}",
+		"/* a",
+		"  b */",
+		"func c() {}",
+	}
+	full := func(lines []string) [][]byte {
+		hs := &HiState{Tokenize: hiTestTokenizer}
+		hs.Update(lines)
+		return hs.Markup
+	}
+	check := func(hs *HiState, lines []string) {
+		t.Helper()
+		want := full(lines)
+		if len(hs.Markup) != len(want) {
+			t.Fatalf("got %d lines of markup, want %d", len(hs.Markup), len(want))
+		}
+		for i := range want {
+			if !bytes.Equal(hs.Markup[i], want[i]) {
+				t.Errorf("line %d: got %q, want %q", i, hs.Markup[i], want[i])
+			}
+		}
+	}
+
+	hs := &HiState{Tokenize: hiTestTokenizer}
+	if n := hs.Update(text); n != len(text) {
+		t.Errorf("first update tokenized %d lines, want %d", n, len(text))
+	}
+	if string(hs.Markup[1]) != `	x <span class="c">/* in */</span> y` {
+		t.Errorf("markup: got %q", hs.Markup[1])
+	}
+	if n := hs.Update(text); n != 0 {
+		t.Errorf("update without changes tokenized %d lines", n)
+	}
+
+	ed := append([]string(nil), text...)
+	ed[5] = "	z2"
+	if n := hs.Update(ed); n != 2 { // from func b, up to the } after it
+		t.Errorf("edit in func b tokenized %d lines, want 2", n)
+	}
+	check(hs, ed)
+
+	ed = append(ed[:2:2], append([]string{"/* open"}, ed[2:]...)...)
+	if n := hs.Update(ed); n != 8 { // from the } before it, up to func c after the comment
+		t.Errorf("opening comment tokenized %d lines, want 8", n)
+	}
+	check(hs, ed)
+
+	ed = append(ed[:2:2], ed[3:]...)
+	hs.Update(ed)
+	check(hs, ed)
+
+	ed = ed[:4]
+	hs.Update(ed)
+	check(hs, ed)
+}
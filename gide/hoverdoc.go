@@ -0,0 +1,152 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"html"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// DocPopupMaxLines is the maximum number of lines of documentation shown in
+// the hover documentation popup
+var DocPopupMaxLines = 20
+
+// DocPopupMarkup returns the given documentation text as markup for a
+// popup, limited to DocPopupMaxLines
+func DocPopupMarkup(doc string) string {
+	lns := strings.Split(strings.TrimSpace(doc), "\n")
+	if len(lns) > DocPopupMaxLines {
+		lns = append(lns[:DocPopupMaxLines], "...")
+	}
+	for i, l := range lns {
+		lns[i] = html.EscapeString(l)
+	}
+	return strings.Join(lns, "<br>")
+}
+
+// SigHelpMarkup returns the markup for the active signature in given
+// signature help, with the active parameter in bold
+func SigHelpMarkup(sh *LspSignatureHelp) string {
+	if sh == nil || len(sh.Signatures) == 0 {
+		return ""
+	}
+	si := sh.ActiveSignature
+	if si < 0 || si >= len(sh.Signatures) {
+		si = 0
+	}
+	sig := sh.Signatures[si]
+	lbl := sig.Label
+	st, ed := -1, -1
+	if pi := sh.ActiveParameter; pi >= 0 && pi < len(sig.Parameters) {
+		switch pl := sig.Parameters[pi].Label.(type) {
+		case string:
+			st = strings.Index(lbl, pl)
+			if st >= 0 {
+				ed = st + len(pl)
+			}
+		case []interface{}: // [start, end] offsets into the label
+			if len(pl) == 2 {
+				s, sok := pl[0].(float64)
+				e, eok := pl[1].(float64)
+				if sok && eok && int(s) <= int(e) && int(e) <= len(lbl) {
+					st, ed = int(s), int(e)
+				}
+			}
+		}
+	}
+	var mu string
+	if st >= 0 {
+		mu = html.EscapeString(lbl[:st]) + "<b>" + html.EscapeString(lbl[st:ed]) + "</b>" + html.EscapeString(lbl[ed:])
+	} else {
+		mu = html.EscapeString(lbl)
+	}
+	if doc := LspDocText(sig.Documentation); doc != "" {
+		mu += "<br>" + DocPopupMarkup(doc)
+	}
+	return mu
+}
+
+// PopupAtPos shows a popup with given markup just below given position in
+// given text view
+func (ge *Gide) PopupAtPos(tv *giv.TextView, pos giv.TextPos, markup, name string) {
+	if markup == "" {
+		return
+	}
+	cpos := tv.CharStartPos(pos).ToPoint()
+	cpos.Y += int(tv.LineHeight)
+	gi.PopupTooltip(markup, cpos.X, cpos.Y, tv.Viewport, name)
+}
+
+// ShowDoc shows the documentation and type of the symbol at the cursor in the
+// active view in a popup, as provided by the language server -- for Go, the
+// type arguments of an instantiated generic function or type are shown too,
+// and the declaration of the symbol if there is no language server
+func (ge *Gide) ShowDoc() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	var doc string
+	lc, _, lpath := ge.LspClientForActive()
+	if lc != nil {
+		if hv, err := lc.Hover(lpath, tv.CursorPos.Ln, tv.CursorPos.Ch); err == nil && hv != nil {
+			doc = LspDocText(hv.Contents)
+		}
+	}
+	if fpath := string(tv.Buf.Filename); strings.HasSuffix(fpath, ".go") {
+		if gp, err := GoLoadFile(fpath, tv.Buf.LinesToBytesCopy()); err == nil {
+			off := TextPosByteOffset(tv.Buf.Lines, tv.CursorPos)
+			if lc == nil {
+				doc = gp.DocAt(fpath, off)
+			} else if inst, ok := gp.InstanceAt(fpath, off); ok {
+				doc = "instantiated as: " + inst + "\n\n" + doc
+			}
+		}
+	}
+	if doc == "" {
+		ge.SetStatus("No documentation found")
+		return
+	}
+	ge.PopupAtPos(tv, tv.CursorPos, DocPopupMarkup(doc), "gide-doc")
+}
+
+// SigHelpBuf shows signature help for the call at given position in given
+// buffer, which is being edited in the active view -- called when an opening
+// paren or comma is typed, if the SigHelp editor pref is on -- the language
+// server must already have the current contents of the buffer
+func (ge *Gide) SigHelpBuf(tb *giv.TextBuf, pos giv.TextPos) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf != tb {
+		return
+	}
+	lc := ge.LspClientForBuf(tb)
+	if lc == nil {
+		return
+	}
+	sh, err := lc.SignatureHelp(string(tb.Filename), pos.Ln, pos.Ch)
+	if err != nil {
+		return
+	}
+	ge.PopupAtPos(tv, pos, SigHelpMarkup(sh), "gide-sighelp")
+}
+
+// SigHelpEdit checks if given edit to given buffer should trigger signature
+// help -- i.e., it is the insertion of an opening paren or a comma
+func (ge *Gide) SigHelpEdit(tb *giv.TextBuf, tbe *giv.TextBufEdit) {
+	if !ge.Prefs.Editor.SigHelp || tbe == nil || tbe.Delete {
+		return
+	}
+	end := tbe.Reg.End
+	if end.Ln >= len(tb.Lines) || end.Ch < 1 || end.Ch > len(tb.Lines[end.Ln]) {
+		return
+	}
+	switch tb.Lines[end.Ln][end.Ch-1] {
+	case '(', ',':
+		ge.SigHelpBuf(tb, end)
+	}
+}
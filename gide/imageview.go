@@ -0,0 +1,281 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// ImageZooms are the zoom levels the image viewer steps through
+var ImageZooms = []float32{0.1, 0.25, 0.5, 0.75, 1, 1.5, 2, 3, 4, 6, 8}
+
+// ImageZoomStep returns the next zoom level in ImageZooms in from given
+// zoom, or out if !in, staying at the ends
+func ImageZoomStep(zoom float32, in bool) float32 {
+	if in {
+		for _, z := range ImageZooms {
+			if z > zoom*1.001 {
+				return z
+			}
+		}
+		return ImageZooms[len(ImageZooms)-1]
+	}
+	for i := len(ImageZooms) - 1; i >= 0; i-- {
+		if z := ImageZooms[i]; z < zoom*0.999 {
+			return z
+		}
+	}
+	return ImageZooms[0]
+}
+
+// ImageFitZoom returns the zoom at which an image of given size fits within
+// given size, no more than 1 -- small images are not blown up
+func ImageFitZoom(w, h, fw, fh float32) float32 {
+	if w <= 0 || h <= 0 || fw <= 0 || fh <= 0 {
+		return 1
+	}
+	z := fw / w
+	if zh := fh / h; zh < z {
+		z = zh
+	}
+	if z > 1 {
+		z = 1
+	}
+	return z
+}
+
+// IsImageFile returns true if given file node is an image, by its mime type
+func IsImageFile(fn *giv.FileNode) bool {
+	return strings.HasPrefix(fn.Info.Mime, "image")
+}
+
+// IsSVGFile returns true if given file is an SVG image, which is text that
+// can be edited, unlike other images
+func IsSVGFile(path string) bool {
+	return strings.ToLower(filepath.Ext(path)) == ".svg"
+}
+
+// ImageTabName returns the name of the tab of the image viewer for given
+// file
+func ImageTabName(path string) string {
+	return "Image: " + filepath.Base(path)
+}
+
+// ViewImage shows given image file in the image viewer, in a tab of its
+// own, fit to the tab, with actions to zoom in and out
+func (ge *Gide) ViewImage(fnm gi.FileName) {
+	path := string(fnm)
+	ivi, _ := ge.FindOrMakeMainTab(ImageTabName(path), KiT_ImageView, true) // sel
+	iv := ivi.Embed(KiT_ImageView).(*ImageView)
+	iv.UpdateView(ge)
+	if err := iv.Open(path); err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not open image: %v", err))
+		return
+	}
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// ImageView is a widget that shows an image file, raster or SVG, zoomed in
+// or out
+type ImageView struct {
+	gi.Layout
+	Gide *Gide       `json:"-" xml:"-" desc:"parent gide project"`
+	Path string      `desc:"path to the image file"`
+	Zoom float32     `desc:"current zoom, 1 being the actual size"`
+	Img  image.Image `json:"-" xml:"-" view:"-" desc:"the decoded image, for raster images"`
+	Size image.Point `desc:"actual size of the image, in pixels -- for SVG that of its view box"`
+}
+
+var KiT_ImageView = kit.Types.AddType(&ImageView{}, ImageViewProps)
+
+// Open opens given image file, and shows it fit to the view
+func (iv *ImageView) Open(path string) error {
+	iv.Path = path
+	iv.Img = nil
+	fr := iv.Frame()
+	updt := fr.UpdateStart()
+	fr.SetFullReRender()
+	fr.DeleteChildren(true)
+	if IsSVGFile(path) {
+		sv := fr.AddNewChild(svg.KiT_SVG, "svg").(*svg.SVG)
+		if err := sv.OpenXML(gi.FileName(path)); err != nil {
+			fr.UpdateEnd(updt)
+			return err
+		}
+		sv.Norm = true
+		iv.Size = image.Point{int(sv.ViewBox.Size.X), int(sv.ViewBox.Size.Y)}
+	} else {
+		img, err := gi.OpenImage(path)
+		if err != nil {
+			fr.UpdateEnd(updt)
+			return err
+		}
+		iv.Img = img
+		iv.Size = img.Bounds().Size()
+		fr.AddNewChild(gi.KiT_Bitmap, "bitmap")
+	}
+	fr.UpdateEnd(updt)
+	fw, fh := float32(0), float32(0)
+	if sz := fr.LayData.AllocSize; sz.X > 0 {
+		fw, fh = sz.X, sz.Y
+	} else if sz := iv.LayData.AllocSize; sz.X > 0 {
+		fw, fh = sz.X, sz.Y*0.9 // less the toolbar
+	}
+	iv.SetZoom(ImageFitZoom(float32(iv.Size.X), float32(iv.Size.Y), fw, fh))
+	return nil
+}
+
+// SetZoom shows the image at given zoom, 1 being its actual size
+func (iv *ImageView) SetZoom(zoom float32) {
+	if zoom <= 0 {
+		zoom = 1
+	}
+	iv.Zoom = zoom
+	w := float32(iv.Size.X) * zoom
+	h := float32(iv.Size.Y) * zoom
+	fr := iv.Frame()
+	if bmk, ok := fr.ChildByName("bitmap", 0); ok && iv.Img != nil {
+		bmk.(*gi.Bitmap).SetImage(iv.Img, w, h)
+	} else if svk, ok := fr.ChildByName("svg", 0); ok {
+		sv := svk.(*svg.SVG)
+		sv.SetProp("width", units.NewValue(w, units.Px))
+		sv.SetProp("height", units.NewValue(h, units.Px))
+		sv.Resize(image.Point{int(w), int(h)})
+	}
+	fr.SetFullReRender()
+	fr.UpdateSig()
+	if lbl := iv.InfoLabel(); lbl != nil {
+		lbl.SetText(fmt.Sprintf("%d x %d at %v%%", iv.Size.X, iv.Size.Y, int(zoom*100+0.5)))
+	}
+}
+
+// OpenAsText opens an SVG file in a text view, to edit it, and shows other
+// images in a hex dump
+func (iv *ImageView) OpenAsText() {
+	ge := iv.Gide
+	if !IsSVGFile(iv.Path) {
+		ge.ViewHex(gi.FileName(iv.Path))
+		return
+	}
+	fnk, ok := ge.Files.FindFile(iv.Path)
+	if !ok {
+		ge.SetStatus("File is not in the project: " + iv.Path)
+		return
+	}
+	ge.NextViewFileNode(fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode))
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (iv *ImageView) UpdateView(ge *Gide) {
+	iv.Gide = ge
+	iv.Lay = gi.LayoutVert
+	iv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "imagebar")
+	config.Add(gi.KiT_Frame, "image")
+	mods, updt := iv.ConfigChildren(config, false)
+	iv.ConfigToolbar()
+	fr := iv.Frame()
+	fr.SetStretchMaxWidth()
+	fr.SetStretchMaxHeight()
+	fr.SetProp("overflow", "auto")
+	if mods {
+		iv.UpdateEnd(updt)
+	}
+}
+
+// ImageBar returns the image toolbar
+func (iv *ImageView) ImageBar() *gi.ToolBar {
+	tbi, ok := iv.ChildByName("imagebar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// InfoLabel returns the label in the toolbar with the size and zoom
+func (iv *ImageView) InfoLabel() *gi.Label {
+	tb := iv.ImageBar()
+	if tb == nil {
+		return nil
+	}
+	lbi, ok := tb.ChildByName("info", 4)
+	if !ok {
+		return nil
+	}
+	return lbi.(*gi.Label)
+}
+
+// Frame returns the frame the image is shown in
+func (iv *ImageView) Frame() *gi.Frame {
+	fri, ok := iv.ChildByName("image", 1)
+	if !ok {
+		return nil
+	}
+	return fri.(*gi.Frame)
+}
+
+// ConfigToolbar adds toolbar.
+func (iv *ImageView) ConfigToolbar() {
+	tb := iv.ImageBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	zin := tb.AddNewChild(gi.KiT_Action, "zoom-in").(*gi.Action)
+	zin.SetText("Zoom In")
+	zin.Tooltip = "show the image bigger"
+	zin.ActionSig.Connect(iv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ivv, _ := recv.Embed(KiT_ImageView).(*ImageView)
+		ivv.SetZoom(ImageZoomStep(ivv.Zoom, true))
+	})
+
+	zout := tb.AddNewChild(gi.KiT_Action, "zoom-out").(*gi.Action)
+	zout.SetText("Zoom Out")
+	zout.Tooltip = "show the image smaller"
+	zout.ActionSig.Connect(iv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ivv, _ := recv.Embed(KiT_ImageView).(*ImageView)
+		ivv.SetZoom(ImageZoomStep(ivv.Zoom, false))
+	})
+
+	act := tb.AddNewChild(gi.KiT_Action, "actual").(*gi.Action)
+	act.SetText("100%")
+	act.Tooltip = "show the image at its actual size"
+	act.ActionSig.Connect(iv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ivv, _ := recv.Embed(KiT_ImageView).(*ImageView)
+		ivv.SetZoom(1)
+	})
+
+	txt := tb.AddNewChild(gi.KiT_Action, "as-text").(*gi.Action)
+	txt.SetText("Open As Text")
+	txt.Tooltip = "open an SVG file in a text view, to edit it, or show other images in a hex dump"
+	txt.ActionSig.Connect(iv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ivv, _ := recv.Embed(KiT_ImageView).(*ImageView)
+		ivv.OpenAsText()
+	})
+
+	tb.AddNewChild(gi.KiT_Label, "info")
+}
+
+var ImageViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
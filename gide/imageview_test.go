@@ -0,0 +1,44 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import "testing"
+
+func TestImageZoomStep(t *testing.T) {
+	cases := []struct {
+		zoom float32
+		in   bool
+		want float32
+	}{
+		{1, true, 1.5},
+		{1, false, 0.75},
+		{0.6, true, 0.75},
+		{0.6, false, 0.5},
+		{8, true, 8},
+		{0.1, false, 0.1},
+		{0.05, true, 0.1},
+	}
+	for _, c := range cases {
+		if got := ImageZoomStep(c.zoom, c.in); got != c.want {
+			t.Errorf("%v in %v: got %v, want %v", c.zoom, c.in, got, c.want)
+		}
+	}
+}
+
+func TestImageFitZoom(t *testing.T) {
+	cases := []struct {
+		w, h, fw, fh, want float32
+	}{
+		{100, 50, 400, 400, 1},
+		{800, 400, 400, 400, 0.5},
+		{400, 800, 400, 400, 0.5},
+		{100, 100, 0, 0, 1},
+	}
+	for _, c := range cases {
+		if got := ImageFitZoom(c.w, c.h, c.fw, c.fh); got != c.want {
+			t.Errorf("%vx%v in %vx%v: got %v, want %v", c.w, c.h, c.fw, c.fh, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+
+	"github.com/goki/gi/giv"
+)
+
+// IndentRules are the rules for automatically indenting a new line, from
+// the language of the file
+type IndentRules struct {
+	Unit        string `desc:"string for one level of indentation, e.g., a tab or 4 spaces"`
+	Pairs       string `desc:"delimiter pairs, as in Lang AutoPairs -- brackets open blocks, and quotes delimit strings"`
+	Comment     string `desc:"line comment start"`
+	ColonBlocks bool   `desc:"a line ending in a colon starts a block, as in Python"`
+}
+
+// indentContOps are the operators that continue an expression onto the next
+// line when they end a line, in languages with bracket blocks
+var indentContOps = []string{"&&", "||", "+", "-", "*", "/", "=", ".", "\\"}
+
+// indentDedentWords are the words starting a line after which a block ends,
+// in languages with colon blocks
+var indentDedentWords = []string{"return", "pass", "break", "continue", "raise"}
+
+// leadingSpace returns the whitespace at the start of s
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+// dedent removes one level of indentation unit from the end of indent
+func dedent(ind, unit string) string {
+	switch {
+	case strings.HasSuffix(ind, unit):
+		return ind[:len(ind)-len(unit)]
+	case strings.HasSuffix(ind, "\t"):
+		return ind[:len(ind)-1]
+	}
+	n := len(ind) - len(strings.TrimRight(ind, " "))
+	if n > len(unit) {
+		n = len(unit)
+	}
+	return ind[:len(ind)-n]
+}
+
+// codeLine returns the code in given line, with each string replaced by a
+// 0, comments removed and trailing space trimmed, and its net bracket depth
+func (ir *IndentRules) codeLine(line []rune) (string, int) {
+	msk := codeMask(line, ir.Pairs, ir.Comment)
+	cmt := strings.TrimSpace(ir.Comment)
+	var code []rune
+	depth := 0
+	for i := 0; i < len(line); i++ {
+		r := line[i]
+		if !msk[i] {
+			if cmt != "" && strings.HasPrefix(string(line[i:]), cmt) {
+				break
+			}
+			code = append(code, '0')
+			for i+1 < len(line) && !msk[i+1] {
+				i++
+			}
+			continue
+		}
+		code = append(code, r)
+		if other, open, ok := BracketPair(ir.Pairs, r); ok && other != r {
+			if open {
+				depth++
+			} else {
+				depth--
+			}
+		}
+	}
+	return strings.TrimRight(string(code), " \t"), depth
+}
+
+// continues returns true if given code ends with an operator that continues
+// the expression onto the next line
+func (ir *IndentRules) continues(code string) bool {
+	if strings.HasSuffix(code, "\\") {
+		return true
+	}
+	if ir.ColonBlocks || strings.HasSuffix(code, "++") || strings.HasSuffix(code, "--") || strings.HasSuffix(code, "*/") {
+		return false
+	}
+	for _, op := range indentContOps {
+		if strings.HasSuffix(code, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextIndent returns the indentation for a new line started by breaking
+// line ln of lines at given char position, according to the rules: one
+// level deeper after a line that opens a bracket or (with ColonBlocks) ends
+// in a colon, and for the first continuation line of an expression, and
+// one level less after the end of a continued expression or (with
+// ColonBlocks) a return, etc -- opened is true if the line opens a bracket
+// that is closed by the text after the break
+func (ir *IndentRules) NextIndent(lines [][]rune, ln, ch int) (ind string, opened bool) {
+	line := lines[ln]
+	if ch > len(line) {
+		ch = len(line)
+	}
+	base := leadingSpace(string(line))
+	if ch < len([]rune(base)) {
+		return string(line[:ch]), false
+	}
+	code, depth := ir.codeLine(line[:ch])
+	if depth > 0 {
+		after := strings.TrimLeft(string(line[ch:]), " \t")
+		if after != "" {
+			if _, open, ok := BracketPair(ir.Pairs, []rune(after)[0]); ok && !open {
+				opened = true
+			}
+		}
+		return base + ir.Unit, opened
+	}
+	if ir.ColonBlocks {
+		if strings.HasSuffix(code, ":") {
+			return base + ir.Unit, false
+		}
+		fw := strings.Fields(code)
+		if len(fw) > 0 {
+			for _, w := range indentDedentWords {
+				if fw[0] == w {
+					return dedent(base, ir.Unit), false
+				}
+			}
+		}
+	}
+	prvCont := false
+	if ln > 0 {
+		pcode, _ := ir.codeLine(lines[ln-1])
+		prvCont = ir.continues(pcode)
+	}
+	cont := ir.continues(code)
+	switch {
+	case cont && !prvCont:
+		return base + ir.Unit, false
+	case !cont && prvCont && depth == 0:
+		return dedent(base, ir.Unit), false
+	}
+	return base, false
+}
+
+// IndentRulesFor returns the indentation rules for given buffer, from its
+// language and options
+func IndentRulesFor(tb *giv.TextBuf) *IndentRules {
+	ir := &IndentRules{Unit: "\t", Pairs: DefaultAutoPairs}
+	if tb.Opts.SpaceIndent {
+		ir.Unit = strings.Repeat(" ", tb.Opts.TabSize)
+	}
+	ls := LangsForFilename(string(tb.Filename))
+	if len(ls) > 0 {
+		ir.Comment = ls[0].Comment
+		ir.ColonBlocks = ls[0].ColonBlocks
+		if ls[0].AutoPairs != "" {
+			ir.Pairs = ls[0].AutoPairs
+		}
+	}
+	return ir
+}
+
+// AutoIndentEnter breaks the line at the cursor in the active view,
+// indenting the new line according to the language rules (see NextIndent)
+// -- when the break is between an opening bracket and its closing one, the
+// closing one goes on a further line, at the original indentation --
+// returns false if auto indent is off or the view is not being edited
+func (ge *Gide) AutoIndentEnter() bool {
+	if !ge.Prefs.Editor.AutoIndent {
+		return false
+	}
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil || tv.IsInactive() || !tv.HasFocus() || tv.HasSelection() || ge.Cursors.Has(tv) {
+		return false
+	}
+	pos := tv.CursorPos
+	if pos.Ln >= len(tv.Buf.Lines) {
+		return false
+	}
+	ir := IndentRulesFor(tv.Buf)
+	line := tv.Buf.Lines[pos.Ln]
+	base := leadingSpace(string(line))
+	ind, opened := ir.NextIndent(tv.Buf.Lines, pos.Ln, pos.Ch)
+	aft := pos.Ch // skip the space after the cursor, as it is replaced by the indent
+	for aft < len(line) && (line[aft] == ' ' || line[aft] == '\t') {
+		aft++
+	}
+	if aft > pos.Ch {
+		tv.Buf.DeleteText(pos, giv.TextPos{Ln: pos.Ln, Ch: aft}, true, true)
+	}
+	txt := "\n" + ind
+	if opened {
+		txt += "\n" + base
+	}
+	tv.Buf.InsertText(pos, []byte(txt), true, true)
+	tv.SetCursorShow(giv.TextPos{Ln: pos.Ln + 1, Ch: len([]rune(ind))})
+	return true
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import "testing"
+
+func TestNextIndent(t *testing.T) {
+	goir := &IndentRules{Unit: "\t", Pairs: "()[]{}\"\"''``", Comment: "// "}
+	pyir := &IndentRules{Unit: "    ", Pairs: "()[]{}\"\"''", Comment: "# ", ColonBlocks: true}
+	tests := []struct {
+		ir     *IndentRules
+		src    string
+		ch     int // -1 for end of last line
+		ind    string
+		opened bool
+	}{
+		{goir, "\tif x {", -1, "\t\t", false},
+		{goir, "\tif x {}", 7, "\t\t", true},
+		{goir, "\ts := \"{\" // {", -1, "\t", false},
+		{goir, "\tx := \"a &&\"", -1, "\t", false},
+		{goir, "\tx := a &&", -1, "\t\t", false},
+		{goir, "\tx := a &&\n\t\tb &&", -1, "\t\t", false},
+		{goir, "\tx := a &&\n\t\tb", -1, "\t", false},
+		{goir, "\t}", -1, "\t", false},
+		{goir, "\t\tfoo()", 1, "\t", false},
+		{pyir, "    def f(x):", -1, "        ", false},
+		{pyir, "        return x", -1, "    ", false},
+		{pyir, "    y = [1,", -1, "        ", false},
+		{pyir, "    s = 'a:'  # b:", -1, "    ", false},
+	}
+	for i, ts := range tests {
+		lines := cgoTestLines(ts.src)
+		ln := len(lines) - 1
+		ch := ts.ch
+		if ch < 0 {
+			ch = len(lines[ln])
+		}
+		ind, opened := ts.ir.NextIndent(lines, ln, ch)
+		if ind != ts.ind || opened != ts.opened {
+			t.Errorf("NextIndent %d %q: got %q %v, want %q %v", i, ts.src, ind, opened, ts.ind, ts.opened)
+		}
+	}
+	if d := dedent("      ", "    "); d != "  " {
+		t.Errorf("dedent: got %q", d)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+)
+
+// ISearchStep is one step of an incremental search: the find string after
+// a key, and the match of it that was shown then
+type ISearchStep struct {
+	Find    string         `desc:"find string"`
+	Reg     giv.TextRegion `desc:"the match -- nil if there was none"`
+	Wrapped bool           `desc:"the match was found by wrapping around to the start of the buffer"`
+}
+
+// isearchState is an incremental search in progress in a text view
+type isearchState struct {
+	tv    *giv.TextView
+	start giv.TextPos   // cursor when the search started, restored on abort
+	steps []ISearchStep // one per key, so Backspace can go back to the one before
+}
+
+// cur returns the current step, the last one
+func (is *isearchState) cur() ISearchStep {
+	if len(is.steps) == 0 {
+		return ISearchStep{}
+	}
+	return is.steps[len(is.steps)-1]
+}
+
+// ISearchFind returns the region of the first match of find in lines that
+// starts at or after from, wrapping around to the start of the lines if
+// there is none after it, which is returned as wrapped -- case is ignored
+// unless find has an upper case letter, as in emacs -- false if there is
+// no match at all
+func ISearchFind(lines [][]rune, find string, from giv.TextPos) (reg giv.TextRegion, wrapped, ok bool) {
+	fr := []rune(find)
+	nl := len(lines)
+	if len(fr) == 0 || nl == 0 {
+		return reg, false, false
+	}
+	fold := strings.ToLower(find) == find
+	for i := 0; i <= nl; i++ {
+		ln := (from.Ln + i) % nl
+		line := lines[ln]
+		st, ed := 0, len(line)
+		switch {
+		case i == 0:
+			st = from.Ch
+		case i == nl: // back at the start line, before from
+			ed = from.Ch + len(fr) - 1
+			if ed > len(line) {
+				ed = len(line)
+			}
+		}
+		if ch := isearchIndex(line, st, ed, fr, fold); ch >= 0 {
+			reg = giv.TextRegion{Start: giv.TextPos{Ln: ln, Ch: ch}, End: giv.TextPos{Ln: ln, Ch: ch + len(fr)}}
+			return reg, from.Ln+i >= nl, true
+		}
+	}
+	return reg, false, false
+}
+
+// isearchIndex returns the rune position of the first match of fr within
+// line from st to ed, or -1 if none -- lower-cases the line if fold
+func isearchIndex(line []rune, st, ed int, fr []rune, fold bool) int {
+	for ch := st; ch+len(fr) <= ed; ch++ {
+		i := 0
+		for ; i < len(fr); i++ {
+			r := line[ch+i]
+			if fold {
+				r = unicode.ToLower(r)
+			}
+			if r != fr[i] {
+				break
+			}
+		}
+		if i == len(fr) {
+			return ch
+		}
+	}
+	return -1
+}
+
+// ISearch starts an incremental search in the active view, as in emacs:
+// typing narrows the match as each key is typed, repeating this jumps to
+// the next match, Backspace goes back to the match before the last key,
+// Enter or any other command leaves the cursor at the match, with the
+// find string saved in the find history, and Escape goes back to where the
+// search started
+func (ge *Gide) ISearch() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if ge.isearch != nil && ge.isearch.tv == tv {
+		ge.ISearchNext()
+		return
+	}
+	ge.ISearchEnd(true)
+	ge.isearch = &isearchState{tv: tv, start: tv.CursorPos}
+	ge.ISearchShow()
+}
+
+// ISearchNext jumps to the next match of the incremental search -- with
+// nothing typed yet, it searches for the last find string in the history
+func (ge *Gide) ISearchNext() {
+	is := ge.isearch
+	cur := is.cur()
+	find := cur.Find
+	from := is.tv.CursorPos
+	if find == "" {
+		if len(ge.Prefs.Find.FindHist) == 0 {
+			return
+		}
+		find = ge.Prefs.Find.FindHist[0]
+	} else if !cur.Reg.IsNil() {
+		from = cur.Reg.Start
+		from.Ch++
+	}
+	ge.ISearchTo(find, from)
+}
+
+// ISearchTo adds a step to the incremental search, for the first match of
+// find at or after from
+func (ge *Gide) ISearchTo(find string, from giv.TextPos) {
+	is := ge.isearch
+	reg, wrapped, ok := ISearchFind(is.tv.Buf.Lines, find, from)
+	if !ok {
+		reg = giv.TextRegionNil
+	}
+	is.steps = append(is.steps, ISearchStep{Find: find, Reg: reg, Wrapped: wrapped || is.cur().Wrapped})
+	ge.ISearchShow()
+}
+
+// ISearchBack goes back to the step of the incremental search before the
+// last key
+func (ge *Gide) ISearchBack() {
+	is := ge.isearch
+	if len(is.steps) == 0 {
+		return
+	}
+	is.steps = is.steps[:len(is.steps)-1]
+	ge.ISearchShow()
+}
+
+// ISearchShow highlights the current match of the incremental search,
+// moves the cursor to it, and shows the find string in the status bar
+func (ge *Gide) ISearchShow() {
+	is := ge.isearch
+	cur := is.cur()
+	tv := is.tv
+	var nw []giv.TextRegion
+	pos := is.start
+	if !cur.Reg.IsNil() {
+		nw = []giv.TextRegion{cur.Reg}
+		pos = cur.Reg.Start
+	} else if len(is.steps) > 1 {
+		pos = tv.CursorPos // failing: stay at the last match
+	}
+	ReplaceHighlights(tv, ge.isearchHi, nw)
+	ge.isearchHi = nw
+	tv.SetCursorShow(pos)
+	ge.SetStatus("")
+}
+
+// ISearchStatus returns the status bar message for the incremental search
+// in progress, if any
+func (ge *Gide) ISearchStatus() string {
+	is := ge.isearch
+	if is == nil {
+		return ""
+	}
+	cur := is.cur()
+	lbl := "ISearch"
+	if cur.Wrapped {
+		lbl = "Wrapped ISearch"
+	}
+	if cur.Find != "" && cur.Reg.IsNil() {
+		lbl = "Failing " + lbl
+	}
+	return fmt.Sprintf("%v: %v", lbl, cur.Find)
+}
+
+// ISearchEnd ends the incremental search in progress, if any, leaving the
+// cursor at its match and saving its find string in the find history --
+// if abort, the cursor goes back to where the search started instead
+func (ge *Gide) ISearchEnd(abort bool) {
+	is := ge.isearch
+	if is == nil {
+		return
+	}
+	ge.isearch = nil
+	ReplaceHighlights(is.tv, ge.isearchHi, nil)
+	ge.isearchHi = nil
+	if abort {
+		is.tv.SetCursorShow(is.start)
+	} else if find := is.cur().Find; find != "" {
+		ge.Prefs.Find.Find = find
+		gi.StringsInsertFirstUnique(&ge.Prefs.Find.FindHist, find, gi.Prefs.SavedPathsMax)
+		giv.PrevISearchString = find
+	}
+	ge.SetStatus("")
+}
+
+// ISearchKey handles a key while an incremental search is in progress:
+// printable keys add to the find string, and Backspace, Enter and Escape
+// work as in ISearch -- any other key ends the search and is then handled
+// as usual -- returns true if the key was used here
+func (ge *Gide) ISearchKey(kt *key.ChordEvent, kf KeyFuns, gkf gi.KeyFuns) bool {
+	is := ge.isearch
+	if is == nil {
+		return false
+	}
+	if ge.ActiveTextView() != is.tv {
+		ge.ISearchEnd(false)
+		return false
+	}
+	switch {
+	case kf == KeyFunISearch:
+		return false // ISearch goes to the next match
+	case gkf == gi.KeyFunAbort:
+		ge.ISearchEnd(true)
+	case gkf == gi.KeyFunEnter:
+		ge.ISearchEnd(false)
+	case gkf == gi.KeyFunBackspace:
+		ge.ISearchBack()
+	default:
+		kr := []rune(strings.TrimPrefix(string(kt.Chord()), "Shift+"))
+		if kf != KeyFunNil || len(kr) != 1 || !unicode.IsPrint(kr[0]) {
+			ge.ISearchEnd(false)
+			return false
+		}
+		cur := is.cur()
+		from := is.tv.CursorPos
+		if !cur.Reg.IsNil() {
+			from = cur.Reg.Start // the longer string may still match here
+		}
+		ge.ISearchTo(cur.Find+string(kr), from)
+	}
+	kt.SetProcessed()
+	return true
+}
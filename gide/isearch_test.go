@@ -0,0 +1,35 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"testing"
+
+	"github.com/goki/gi/giv"
+)
+
+func TestISearchFind(t *testing.T) {
+	lines := [][]rune{[]rune("Foo bar"), []rune("föo foo"), []rune("bar")}
+	tests := []struct {
+		find    string
+		from    giv.TextPos
+		reg     giv.TextRegion
+		wrapped bool
+		ok      bool
+	}{
+		{"foo", giv.TextPos{}, giv.TextRegion{Start: giv.TextPos{Ln: 0, Ch: 0}, End: giv.TextPos{Ln: 0, Ch: 3}}, false, true},
+		{"foo", giv.TextPos{Ch: 1}, giv.TextRegion{Start: giv.TextPos{Ln: 1, Ch: 4}, End: giv.TextPos{Ln: 1, Ch: 7}}, false, true},
+		{"Foo", giv.TextPos{Ch: 1}, giv.TextRegion{Start: giv.TextPos{Ln: 0, Ch: 0}, End: giv.TextPos{Ln: 0, Ch: 3}}, true, true},
+		{"föo", giv.TextPos{Ln: 1, Ch: 1}, giv.TextRegion{Start: giv.TextPos{Ln: 1, Ch: 0}, End: giv.TextPos{Ln: 1, Ch: 3}}, true, true},
+		{"bar", giv.TextPos{Ln: 2, Ch: 1}, giv.TextRegion{Start: giv.TextPos{Ln: 0, Ch: 4}, End: giv.TextPos{Ln: 0, Ch: 7}}, true, true},
+		{"baz", giv.TextPos{}, giv.TextRegion{}, false, false},
+	}
+	for _, tt := range tests {
+		reg, wrapped, ok := ISearchFind(lines, tt.find, tt.from)
+		if ok != tt.ok || (ok && (reg.Start != tt.reg.Start || reg.End != tt.reg.End || wrapped != tt.wrapped)) {
+			t.Errorf("ISearchFind(%q, %v) = %v, %v, %v, want %v, %v, %v", tt.find, tt.from, reg, wrapped, ok, tt.reg, tt.wrapped, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
+
+// JSONError is an error in a JSON file, e.g., a syntax error, an unknown
+// field or a value of the wrong type, at a line and column of the file, in
+// a field given by its path, e.g., Editor.TabSize or [2].Name
+type JSONError struct {
+	File  string `desc:"file with the error"`
+	Line  int    `desc:"line of the error, starting at 1"`
+	Col   int    `desc:"column of the error, in bytes, starting at 1"`
+	Field string `desc:"path of the field with the error -- empty for syntax errors"`
+	Msg   string `desc:"what is wrong"`
+	off   int
+}
+
+func (je *JSONError) Error() string {
+	s := ""
+	if je.File != "" {
+		s = je.File + ":"
+	}
+	s += fmt.Sprintf("%d:%d: ", je.Line, je.Col)
+	if je.Field != "" {
+		s += je.Field + ": "
+	}
+	return s + je.Msg
+}
+
+// JSONErrors are the errors in a JSON file, reported together as one error
+type JSONErrors []*JSONError
+
+func (je JSONErrors) Error() string {
+	ss := make([]string, len(je))
+	for i, e := range je {
+		ss[i] = e.Error()
+	}
+	return strings.Join(ss, "\n")
+}
+
+// SetFile sets the file of the errors
+func (je JSONErrors) SetFile(fname string) {
+	for _, e := range je {
+		e.File = fname
+	}
+}
+
+// JSONReportMax is the maximum number of errors shown by JSONErrors.Report
+var JSONReportMax = 20
+
+// Report logs the errors, and shows them in a dialog with given title, as
+// the rest of the file was loaded without them
+func (je JSONErrors) Report(title string) {
+	if len(je) == 0 {
+		return
+	}
+	prompt := "These entries could not be loaded, and were skipped -- fix them to use them:"
+	for i, e := range je {
+		log.Println(e)
+		if i < JSONReportMax {
+			prompt += "<br>" + html.EscapeString(e.Error())
+		}
+	}
+	if len(je) > JSONReportMax {
+		prompt += fmt.Sprintf("<br>... and %d more, in the log", len(je)-JSONReportMax)
+	}
+	gi.PromptDialog(nil, gi.DlgOpts{Title: title, Prompt: prompt}, true, false, nil, nil)
+}
+
+// JSONLoad loads the JSON in b into the value that ptr points to, keeping
+// what is valid and skipping and reporting what is not, instead of failing
+// on the first error and leaving the rest unset: structs are loaded field
+// by field, maps entry by entry, and lists element by element, so a broken
+// entry only loses itself, with its line and field in the errors -- fields
+// that are not in the struct are errors too -- after a syntax error, what
+// came before it is kept -- if ptr is to a list and check is not nil, each
+// of its elements is checked with it, and skipped if it returns an error
+func JSONLoad(b []byte, ptr interface{}, check func(elem interface{}) error) JSONErrors {
+	var errs JSONErrors
+	v := reflect.ValueOf(ptr).Elem()
+	jsonLoad(b, 0, v, "", check, &errs)
+	if check != nil && v.Kind() != reflect.Slice {
+		if err := check(ptr); err != nil {
+			errs = append(errs, &JSONError{Msg: err.Error()})
+		}
+	}
+	for _, e := range errs {
+		e.Line, e.Col = jsonLineCol(b, e.off)
+	}
+	return errs
+}
+
+// jsonLineCol returns the line and column, starting at 1, of given offset
+func jsonLineCol(b []byte, off int) (int, int) {
+	if off > len(b) {
+		off = len(b)
+	}
+	ln := bytes.Count(b[:off], []byte("\n")) + 1
+	return ln, off - (bytes.LastIndexByte(b[:off], '\n') + 1) + 1
+}
+
+// jsonStrict decodes b into ptr, with unknown fields being errors
+func jsonStrict(b []byte, ptr interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(ptr)
+}
+
+// jsonErrMsg returns the message of given error from the json package
+func jsonErrMsg(err error) string {
+	return strings.TrimPrefix(err.Error(), "json: ")
+}
+
+// jsonEach calls fun for each member of the JSON object, or each element of
+// the JSON list, in b, with its key (or index), value, and offset in b --
+// it stops at a syntax error, returning its offset in b and it
+func jsonEach(b []byte, fun func(key string, val json.RawMessage, off int)) (int, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	syntax := func(err error) (int, error) {
+		var v interface{}
+		if se, ok := json.Unmarshal(b, &v).(*json.SyntaxError); ok {
+			off := int(se.Offset) - 1
+			if off < 0 {
+				off = 0
+			}
+			return off, se
+		}
+		return int(dec.InputOffset()), err
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return syntax(err)
+	}
+	obj := tok == json.Delim('{')
+	for i := 0; dec.More(); i++ {
+		key := strconv.Itoa(i)
+		if obj {
+			kt, err := dec.Token()
+			if err != nil {
+				return syntax(err)
+			}
+			key, _ = kt.(string)
+		}
+		off := int(dec.InputOffset())
+		for off < len(b) && strings.IndexByte(" \t\r\n:,", b[off]) >= 0 {
+			off++
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return syntax(err)
+		}
+		fun(key, val, off)
+	}
+	if _, err := dec.Token(); err != nil {
+		return syntax(err)
+	}
+	return 0, nil
+}
+
+// jsonFields returns the indexes of the fields of given struct type by
+// their lower case JSON names -- false if it has embedded fields, which
+// are loaded as a whole
+func jsonFields(t reflect.Type) (map[string]int, bool) {
+	fs := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			return nil, false
+		}
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		nm := f.Name
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			nm = tag
+		}
+		fs[strings.ToLower(nm)] = i
+	}
+	return fs, true
+}
+
+// jsonLoad loads b, at given offset in the file, into v, at given path,
+// adding its errors -- returns false if nothing could be loaded
+func jsonLoad(b []byte, off int, v reflect.Value, path string, check func(elem interface{}) error, errs *JSONErrors) bool {
+	addErr := func(o int, field, msg string) {
+		*errs = append(*errs, &JSONError{Field: field, Msg: msg, off: o})
+	}
+	whole := func() bool {
+		if err := jsonStrict(b, v.Addr().Interface()); err != nil {
+			addErr(off, path, jsonErrMsg(err))
+			return false
+		}
+		return true
+	}
+	pv := v.Addr().Interface()
+	_, um := pv.(json.Unmarshaler)
+	_, tm := pv.(encoding.TextUnmarshaler)
+	tb := bytes.TrimSpace(b)
+	if um || tm || len(tb) == 0 {
+		return whole()
+	}
+	each := func(fun func(key string, val json.RawMessage, o int)) {
+		if o, err := jsonEach(b, fun); err != nil {
+			addErr(off+o, "", jsonErrMsg(err))
+		}
+	}
+	t := v.Type()
+	switch {
+	case v.Kind() == reflect.Struct && tb[0] == '{':
+		fs, ok := jsonFields(t)
+		if !ok {
+			return whole()
+		}
+		each(func(key string, val json.RawMessage, o int) {
+			fi, has := fs[strings.ToLower(key)]
+			if !has {
+				addErr(off+o, strings.TrimPrefix(path+"."+key, "."), "unknown field")
+				return
+			}
+			jsonLoad(val, off+o, v.Field(fi), strings.TrimPrefix(path+"."+t.Field(fi).Name, "."), nil, errs)
+		})
+	case v.Kind() == reflect.Map && tb[0] == '{':
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		each(func(key string, val json.RawMessage, o int) {
+			ent := reflect.New(t)
+			kb, _ := json.Marshal(key)
+			eb := append(append(append(append([]byte("{"), kb...), ':'), val...), '}')
+			if err := jsonStrict(eb, ent.Interface()); err != nil {
+				addErr(off+o, path+"["+key+"]", jsonErrMsg(err))
+				return
+			}
+			for _, k := range ent.Elem().MapKeys() {
+				v.SetMapIndex(k, ent.Elem().MapIndex(k))
+			}
+		})
+	case v.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && tb[0] == '[':
+		sl := reflect.MakeSlice(t, 0, 0)
+		each(func(key string, val json.RawMessage, o int) {
+			ev := reflect.New(t.Elem())
+			epath := path + "[" + key + "]"
+			if !jsonLoad(val, off+o, ev.Elem(), epath, nil, errs) {
+				return
+			}
+			if check != nil {
+				if err := check(ev.Interface()); err != nil {
+					addErr(off+o, epath, err.Error())
+					return
+				}
+			}
+			sl = reflect.Append(sl, ev.Elem())
+		})
+		v.Set(sl)
+	default:
+		return whole()
+	}
+	return true
+}
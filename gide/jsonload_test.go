@@ -0,0 +1,143 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type jlSub struct {
+	Size int
+	Font string
+}
+
+type jlItem struct {
+	Name string
+	Tags map[string]int
+	Nums []float64
+	Sub  jlSub
+	Skip bool `json:"-"`
+}
+
+func jlCheck(elem interface{}) error {
+	if elem.(*jlItem).Name == "" {
+		return fmt.Errorf("no Name")
+	}
+	return nil
+}
+
+func TestJSONLoad(t *testing.T) {
+	b := []byte(`[
+  {"Name": "a", "Tags": {"x": 1, "y": "two", "z": 3}, "Nums": [1, "2", 3]},
+  {"Name": "b", "Sub": {"Size": "big", "Font": "mono"}, "Color": "red"},
+  {"Tags": {"x": 1}},
+  "c",
+  {"Name": "d"}
+]`)
+	var items []jlItem
+	errs := JSONLoad(b, &items, jlCheck)
+	want := []jlItem{
+		{Name: "a", Tags: map[string]int{"x": 1, "z": 3}, Nums: []float64{1, 3}},
+		{Name: "b", Sub: jlSub{Font: "mono"}},
+		{Name: "d"},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("got %+v\nwant %+v", items, want)
+	}
+	wantErrs := []struct {
+		line  int
+		field string
+	}{
+		{2, "[0].Tags[y]"},
+		{2, "[0].Nums[1]"},
+		{3, "[1].Sub.Size"},
+		{3, "[1].Color"},
+		{4, "[2]"},
+		{5, "[3]"},
+	}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(errs), len(wantErrs), errs)
+	}
+	for i, we := range wantErrs {
+		if errs[i].Line != we.line || errs[i].Field != we.field {
+			t.Errorf("error %d: got %v, want line %d field %v", i, errs[i], we.line, we.field)
+		}
+	}
+}
+
+func TestJSONLoadSyntax(t *testing.T) {
+	b := []byte("[\n  {\"Name\": \"a\"},\n  {\"Name\": \"b\",,}\n]")
+	var items []jlItem
+	errs := JSONLoad(b, &items, nil)
+	if len(items) != 1 || items[0].Name != "a" {
+		t.Errorf("valid part not kept: %+v", items)
+	}
+	if len(errs) != 1 || errs[0].Line != 3 || errs[0].Col != 16 {
+		t.Errorf("got errors %v", errs)
+	}
+	errs.SetFile("cmds.json")
+	if got := errs.Error(); !strings.HasPrefix(got, "cmds.json:3:16: ") {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestJSONLoadStruct(t *testing.T) {
+	it := jlItem{Name: "default", Sub: jlSub{Size: 12, Font: "mono"}}
+	errs := JSONLoad([]byte(`{"Sub": {"Size": 14, "Font": 3}, "Nums": [2]}`), &it, nil)
+	want := jlItem{Name: "default", Sub: jlSub{Size: 14, Font: "mono"}, Nums: []float64{2}}
+	if !reflect.DeepEqual(it, want) {
+		t.Errorf("got %+v, want %+v", it, want)
+	}
+	if len(errs) != 1 || errs[0].Field != "Sub.Font" {
+		t.Errorf("got errors %v", errs)
+	}
+}
+
+// FuzzJSONLoad checks that JSONLoad does not panic, and loads valid JSON
+// the same as the json package does
+func FuzzJSONLoad(f *testing.F) {
+	f.Add([]byte(`[{"Name": "a", "Tags": {"x": 1}, "Nums": [1.5], "Sub": {"Size": 2}}]`))
+	f.Add([]byte(`[{"Name": "a"}, {"Name": 1}, [], null, {"Tags": null}]`))
+	f.Add([]byte(`[{"Name": "a", "Tags": {"x": 1,}}`))
+	f.Add([]byte(`{}`))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var items []jlItem
+		errs := JSONLoad(b, &items, nil)
+		var std []jlItem
+		if jsonStrict(b, &std) == nil && len(errs) == 0 && !reflect.DeepEqual(items, std) {
+			t.Errorf("%q: got %+v, json package got %+v", b, items, std)
+		}
+		if len(errs) == 0 {
+			if _, err := json.Marshal(items); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+}
+
+func TestKeySeqUnmarshalText(t *testing.T) {
+	tests := []struct {
+		text string
+		want KeySeq
+		ok   bool
+	}{
+		{"Control+X;b", KeySeq{"Control+X", "b"}, true},
+		{"Control+S", KeySeq{"Control+S", ""}, true},
+		{"Control+S;", KeySeq{"Control+S", ""}, true},
+		{"", KeySeq{}, false},
+		{";b", KeySeq{}, false},
+	}
+	for _, tt := range tests {
+		var ks KeySeq
+		err := ks.UnmarshalText([]byte(tt.text))
+		if (err == nil) != tt.ok || (tt.ok && ks != tt.want) {
+			t.Errorf("%q: got %v, %v", tt.text, ks, err)
+		}
+	}
+}
@@ -32,25 +32,102 @@ import (
 type KeyFuns int32
 
 const (
-	KeyFunNil        KeyFuns = iota
-	KeyFunNeeds2             // special internal signal returned by KeyFun indicating need for second key
-	KeyFunNextPanel          // move to next panel to the right
-	KeyFunPrevPanel          // move to prev panel to the left
-	KeyFunFileOpen           // open a new file in active textview
-	KeyFunBufSelect          // select an open buffer to edit in active textview
-	KeyFunBufClone           // open active file in other view
-	KeyFunBufSave            // save active textview buffer to its file
-	KeyFunBufSaveAs          // save as active textview buffer to its file
-	KeyFunBufClose           // close active textview buffer
-	KeyFunExecCmd            // execute a command on active textview buffer
-	KeyFunRegCopy            // copy selection to named register
-	KeyFunRegPaste           // paste selection from named register
-	KeyFunCommentOut         // comment out region
-	KeyFunIndent             // indent region
-	KeyFunJump               // jump to line (same as gi.KeyFunJump)
-	KeyFunSetSplit           // set named splitter config
-	KeyFunBuildProj          // build overall project
-	KeyFunRunProj            // run overall project
+	KeyFunNil                  KeyFuns = iota
+	KeyFunNeeds2                       // special internal signal returned by KeyFun indicating need for second key
+	KeyFunNextPanel                    // move to next panel to the right
+	KeyFunPrevPanel                    // move to prev panel to the left
+	KeyFunFileOpen                     // open a new file in active textview
+	KeyFunBufSelect                    // list the open buffers, to view, close, save or revert them
+	KeyFunBufClone                     // open active file in other view
+	KeyFunBufSave                      // save active textview buffer to its file
+	KeyFunBufSaveAs                    // save as active textview buffer to its file
+	KeyFunBufClose                     // close active textview buffer
+	KeyFunExecCmd                      // execute a command on active textview buffer
+	KeyFunRegCopy                      // copy selection to named register
+	KeyFunRegPaste                     // paste selection from named register
+	KeyFunCommentOut                   // comment out region
+	KeyFunIndent                       // indent region
+	KeyFunJump                         // jump to line (same as gi.KeyFunJump)
+	KeyFunSetSplit                     // set named splitter config
+	KeyFunBuildProj                    // build overall project
+	KeyFunRunProj                      // run overall project
+	KeyFunGotoDef                      // go to definition of symbol at cursor
+	KeyFunNavBack                      // go back to position prior to last jump
+	KeyFunNavForward                   // go forward to position prior to last nav back
+	KeyFunFindRefs                     // find all references to symbol at cursor
+	KeyFunShowDoc                      // show documentation for symbol at cursor
+	KeyFunPeekDef                      // peek at definition of symbol at cursor in a popup
+	KeyFunRename                       // rename symbol at cursor across project
+	KeyFunFoldToggle                   // fold / unfold region at cursor
+	KeyFunFoldAll                      // fold all top-level regions
+	KeyFunUnfoldAll                    // unfold all regions
+	KeyFunNextError                    // go to next error from last failed command
+	KeyFunPrevError                    // go to previous error from last failed command
+	KeyFunAddCursorAbove               // add cursor on line above
+	KeyFunAddCursorBelow               // add cursor on line below
+	KeyFunAddCursorNextMatch           // add cursor at next occurrence of selection
+	KeyFunAddCursorsToLines            // add cursors to each line of selection
+	KeyFunRectSelect                   // rectangular selection from selection corners
+	KeyFunRectCopy                     // copy rectangle
+	KeyFunRectKill                     // kill rectangle
+	KeyFunRectYank                     // yank rectangle
+	KeyFunDebugContinue                // start debugging, or continue to next breakpoint
+	KeyFunDebugStop                    // stop debugging
+	KeyFunDebugToggleBreak             // set / clear breakpoint at cursor
+	KeyFunDebugNext                    // debugger step over
+	KeyFunDebugStep                    // debugger step into
+	KeyFunDebugStepOut                 // debugger step out
+	KeyFunDebugRunToCursor             // debugger run to cursor
+	KeyFunDebugEvalAtCursor            // show debugger value at cursor
+	KeyFunDebugEvalSelection           // print debugger value of selection
+	KeyFunPaneSplitH                   // split active pane side by side
+	KeyFunPaneSplitV                   // split active pane one above the other
+	KeyFunPaneClose                    // close active pane
+	KeyFunPaneUnsplit                  // close all split panes
+	KeyFunPaneSwap                     // swap buffers with next pane
+	KeyFunPaneFocusLeft                // focus pane to the left
+	KeyFunPaneFocusRight               // focus pane to the right
+	KeyFunPaneFocusUp                  // focus pane above
+	KeyFunPaneFocusDown                // focus pane below
+	KeyFunDebugReverseContinue         // debugger reverse continue
+	KeyFunDebugReverseNext             // debugger reverse step over
+	KeyFunDebugReverseStep             // debugger reverse step into
+	KeyFunJumpToMatch                  // jump to matching bracket
+	KeyFunFormatBuffer                 // format the buffer with the formatter for its language
+	KeyFunReflowComment                // rewraps the comment at the cursor at the fill column
+	KeyFunSpellMenu                    // shows the spelling suggestions for the misspelled word at the cursor
+	KeyFunYank                         // inserts the last kill from the kill ring
+	KeyFunYankPop                      // replaces the text just yanked with the previous kill, or shows the kill ring
+	KeyFunCommentToggle                // toggle comment with language's line or block comment
+	KeyFunWhitespaceToggle             // toggle indent guides and whitespace
+	KeyFunSortLines                    // sort selected lines ascending
+	KeyFunSortLinesDesc                // sort selected lines descending
+	KeyFunSortLinesNum                 // sort selected lines by leading number
+	KeyFunUniqueLines                  // remove duplicate selected lines
+	KeyFunReverseLines                 // reverse order of selected lines
+	KeyFunJoinLines                    // join selected lines, or line with next
+	KeyFunSymbolJump                   // jump to a symbol in the file by fuzzy name
+	KeyFunSetLayout                    // switch to a named window layout
+	KeyFunNextLayout                   // switch to the next named window layout
+	KeyFunSymbolSearch                 // search the symbols of the whole project by fuzzy name
+	KeyFunBufAltToggle                 // switch the active view to the buffer viewed before the current one -- again to switch back
+	KeyFunPanelAltToggle               // move the focus to the panel focused before the current one -- again to move back
+	KeyFunPanelsReveal                 // reveal and focus an auto-hidden panel, or go back from it, hiding it again
+	KeyFunMarkdownPreview              // show a live preview of the Markdown file
+	KeyFunDuplicateLines               // duplicate the selected lines or the cursor line
+	KeyFunNextFunc                     // move to the start of the next function
+	KeyFunPrevFunc                     // move to the start of the previous function
+	KeyFunBeginDefun                   // move to the start of the current function
+	KeyFunEndDefun                     // move to the end of the current function
+	KeyFunExpandSel                    // expand the selection to the enclosing syntax node
+	KeyFunShrinkSel                    // shrink the selection back to before the last expand
+	KeyFunClipHistory                  // shows the registers and the history of copies, with previews, to paste one
+	KeyFunNextTab                      // view the buffer of the next tab of the buffer tab bar
+	KeyFunPrevTab                      // view the buffer of the previous tab of the buffer tab bar
+	KeyFunCloseOtherTabs               // close the buffers other than the active one, except pinned ones
+	KeyFunISearch                      // incremental search in the active view, as in emacs
+	KeyFunNextFind                     // go to the next result in the Find panel
+	KeyFunPrevFind                     // go to the previous result in the Find panel
 	KeyFunsN
 )
 
@@ -87,9 +164,15 @@ func (kf KeySeq) MarshalText() ([]byte, error) {
 }
 
 func (kf *KeySeq) UnmarshalText(b []byte) error {
-	bs := bytes.Split(b, []byte(";"))
+	bs := bytes.SplitN(b, []byte(";"), 2)
+	if len(bytes.TrimSpace(bs[0])) == 0 {
+		return fmt.Errorf("empty key sequence: %q", b)
+	}
 	kf.Key1 = key.Chord(string(bs[0]))
-	kf.Key2 = key.Chord(string(bs[1]))
+	kf.Key2 = ""
+	if len(bs) > 1 {
+		kf.Key2 = key.Chord(string(bs[1]))
+	}
 	return nil
 }
 
@@ -319,7 +402,8 @@ func (km *KeyMaps) MapByName(name KeyMapName) (*KeySeqMap, int, bool) {
 // directory for saving / loading the default AvailKeyMaps key maps list
 var PrefsKeyMapsFileName = "key_maps_prefs.json"
 
-// OpenJSON opens keymaps from a JSON-formatted file.
+// OpenJSON opens keymaps from a JSON-formatted file -- broken key maps or
+// bindings are skipped, and reported in a dialog (see JSONLoad)
 func (km *KeyMaps) OpenJSON(filename gi.FileName) error {
 	b, err := ioutil.ReadFile(string(filename))
 	if err != nil {
@@ -328,7 +412,28 @@ func (km *KeyMaps) OpenJSON(filename gi.FileName) error {
 		return err
 	}
 	*km = make(KeyMaps, 0, 10) // reset
-	return json.Unmarshal(b, km)
+	errs := JSONLoad(b, km, CheckKeyMapsItem)
+	if len(errs) == 0 {
+		return nil
+	}
+	errs.SetFile(string(filename))
+	errs.Report("Errors in Key Maps")
+	return errs
+}
+
+// CheckKeyMapsItem checks a key map loaded from JSON: it must have a name,
+// and bindings to unknown key functions are removed
+func CheckKeyMapsItem(elem interface{}) error {
+	it := elem.(*KeyMapsItem)
+	if it.Name == "" {
+		return fmt.Errorf("key map has no Name")
+	}
+	for ks, kf := range it.Map {
+		if kf <= KeyFunNeeds2 || kf >= KeyFunsN {
+			delete(it.Map, ks)
+		}
+	}
+	return nil
 }
 
 // SaveJSON saves keymaps to a JSON-formatted file.
@@ -351,7 +456,11 @@ func (km *KeyMaps) OpenPrefs() error {
 	pdir := oswin.TheApp.AppPrefsDir()
 	pnm := filepath.Join(pdir, PrefsKeyMapsFileName)
 	AvailKeyMapsChanged = false
-	return km.OpenJSON(gi.FileName(pnm))
+	err := km.OpenJSON(gi.FileName(pnm))
+	if err != nil && len(*km) == 0 {
+		km.CopyFrom(StdKeyMaps) // nothing usable
+	}
+	return err
 }
 
 // SavePrefs saves KeyMaps to App standard prefs directory, using PrefsKeyMapsFileName
@@ -484,239 +593,683 @@ var KeyMapsProps = ki.Props{
 // the lastest key functions bound to standard key chords.
 var StdKeyMaps = KeyMaps{
 	{"MacStd", "Standard Mac KeyMap", KeySeqMap{
-		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
-		KeySeq{"Shift+Control+Tab", ""}:  KeyFunPrevPanel,
-		KeySeq{"Control+M", "o"}:         KeyFunNextPanel,
-		KeySeq{"Control+M", "Control+O"}: KeyFunNextPanel,
-		KeySeq{"Control+M", "p"}:         KeyFunPrevPanel,
-		KeySeq{"Control+M", "Control+P"}: KeyFunPrevPanel,
-		KeySeq{"Control+O", ""}:          KeyFunFileOpen,
-		KeySeq{"Control+M", "f"}:         KeyFunFileOpen,
-		KeySeq{"Control+M", "Control+F"}: KeyFunFileOpen,
-		KeySeq{"Control+M", "b"}:         KeyFunBufSelect,
-		KeySeq{"Control+M", "Control+B"}: KeyFunBufSelect,
-		KeySeq{"Control+S", ""}:          KeyFunBufSave,
-		KeySeq{"Shift+Control+S", ""}:    KeyFunBufSaveAs,
-		KeySeq{"Control+M", "s"}:         KeyFunBufSave,
-		KeySeq{"Control+M", "Control+S"}: KeyFunBufSave,
-		KeySeq{"Control+M", "w"}:         KeyFunBufSaveAs,
-		KeySeq{"Control+M", "Control+W"}: KeyFunBufSaveAs,
-		KeySeq{"Control+M", "k"}:         KeyFunBufClose,
-		KeySeq{"Control+M", "Control+K"}: KeyFunBufClose,
-		KeySeq{"Control+M", "c"}:         KeyFunExecCmd,
-		KeySeq{"Control+M", "Control+C"}: KeyFunExecCmd,
-		KeySeq{"Control+M", "n"}:         KeyFunBufClone,
-		KeySeq{"Control+M", "Control+N"}: KeyFunBufClone,
-		KeySeq{"Control+M", "x"}:         KeyFunRegCopy,
-		KeySeq{"Control+M", "g"}:         KeyFunRegPaste,
-		KeySeq{"Control+/", ""}:          KeyFunCommentOut,
-		KeySeq{"Control+M", "t"}:         KeyFunCommentOut,
-		KeySeq{"Control+M", "Control+T"}: KeyFunCommentOut,
-		KeySeq{"Control+M", "i"}:         KeyFunIndent,
-		KeySeq{"Control+M", "Control+I"}: KeyFunIndent,
-		KeySeq{"Control+M", "j"}:         KeyFunJump,
-		KeySeq{"Control+M", "Control+J"}: KeyFunJump,
-		KeySeq{"Control+M", "v"}:         KeyFunSetSplit,
-		KeySeq{"Control+M", "Control+V"}: KeyFunSetSplit,
-		KeySeq{"Control+M", "m"}:         KeyFunBuildProj,
-		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
-		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
-		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+Tab", ""}:         KeyFunNextPanel,
+		KeySeq{"Shift+Control+Tab", ""}:   KeyFunPrevPanel,
+		KeySeq{"Control+M", "o"}:          KeyFunNextPanel,
+		KeySeq{"Control+M", "Control+O"}:  KeyFunNextPanel,
+		KeySeq{"Control+M", "p"}:          KeyFunPrevPanel,
+		KeySeq{"Control+M", "Control+P"}:  KeyFunPrevPanel,
+		KeySeq{"Control+O", ""}:           KeyFunFileOpen,
+		KeySeq{"Control+M", "f"}:          KeyFunFileOpen,
+		KeySeq{"Control+M", "Control+F"}:  KeyFunFileOpen,
+		KeySeq{"Control+M", "b"}:          KeyFunBufSelect,
+		KeySeq{"Control+M", "Control+B"}:  KeyFunBufSelect,
+		KeySeq{"Control+S", ""}:           KeyFunBufSave,
+		KeySeq{"Shift+Control+S", ""}:     KeyFunBufSaveAs,
+		KeySeq{"Control+M", "s"}:          KeyFunBufSave,
+		KeySeq{"Control+M", "Control+S"}:  KeyFunBufSave,
+		KeySeq{"Control+M", "w"}:          KeyFunBufSaveAs,
+		KeySeq{"Control+M", "Control+W"}:  KeyFunBufSaveAs,
+		KeySeq{"Control+M", "k"}:          KeyFunBufClose,
+		KeySeq{"Control+M", "Control+K"}:  KeyFunBufClose,
+		KeySeq{"Control+M", "c"}:          KeyFunExecCmd,
+		KeySeq{"Control+M", "Control+C"}:  KeyFunExecCmd,
+		KeySeq{"Control+M", "n"}:          KeyFunBufClone,
+		KeySeq{"Control+M", "Control+N"}:  KeyFunBufClone,
+		KeySeq{"Control+M", "x"}:          KeyFunRegCopy,
+		KeySeq{"Control+M", "g"}:          KeyFunRegPaste,
+		KeySeq{"Control+/", ""}:           KeyFunCommentOut,
+		KeySeq{"Control+M", "t"}:          KeyFunCommentOut,
+		KeySeq{"Control+M", "Control+T"}:  KeyFunCommentOut,
+		KeySeq{"Control+M", "i"}:          KeyFunIndent,
+		KeySeq{"Control+M", "Control+I"}:  KeyFunIndent,
+		KeySeq{"Control+M", "j"}:          KeyFunJump,
+		KeySeq{"Control+M", "Control+J"}:  KeyFunJump,
+		KeySeq{"Control+M", "v"}:          KeyFunSetSplit,
+		KeySeq{"Control+M", "Control+V"}:  KeyFunSetSplit,
+		KeySeq{"Control+M", "m"}:          KeyFunBuildProj,
+		KeySeq{"Control+M", "Control+M"}:  KeyFunBuildProj,
+		KeySeq{"Control+M", "r"}:          KeyFunRunProj,
+		KeySeq{"Control+M", "Control+R"}:  KeyFunRunProj,
+		KeySeq{"Control+M", "d"}:          KeyFunGotoDef,
+		KeySeq{"Control+M", ","}:          KeyFunNavBack,
+		KeySeq{"Control+M", "."}:          KeyFunNavForward,
+		KeySeq{"Control+M", "u"}:          KeyFunFindRefs,
+		KeySeq{"Control+M", "h"}:          KeyFunShowDoc,
+		KeySeq{"Control+M", "e"}:          KeyFunPeekDef,
+		KeySeq{"Control+M", "Control+E"}:  KeyFunRename,
+		KeySeq{"Control+M", "-"}:          KeyFunFoldToggle,
+		KeySeq{"Control+M", "["}:          KeyFunFoldAll,
+		KeySeq{"Control+M", "]"}:          KeyFunUnfoldAll,
+		KeySeq{"F8", ""}:                  KeyFunNextError,
+		KeySeq{"Shift+F8", ""}:            KeyFunPrevError,
+		KeySeq{"Control+M", "a"}:          KeyFunAddCursorAbove,
+		KeySeq{"Control+M", "z"}:          KeyFunAddCursorBelow,
+		KeySeq{"Control+M", "Control+D"}:  KeyFunAddCursorNextMatch,
+		KeySeq{"Control+M", "l"}:          KeyFunAddCursorsToLines,
+		KeySeq{"Control+M", "Control+X"}:  KeyFunRectSelect,
+		KeySeq{"Control+M", "y"}:          KeyFunRectCopy,
+		KeySeq{"Control+M", "q"}:          KeyFunRectKill,
+		KeySeq{"Control+M", "Control+Y"}:  KeyFunRectYank,
+		KeySeq{"F5", ""}:                  KeyFunDebugContinue,
+		KeySeq{"Shift+F5", ""}:            KeyFunDebugStop,
+		KeySeq{"F9", ""}:                  KeyFunDebugToggleBreak,
+		KeySeq{"F10", ""}:                 KeyFunDebugNext,
+		KeySeq{"F11", ""}:                 KeyFunDebugStep,
+		KeySeq{"Shift+F11", ""}:           KeyFunDebugStepOut,
+		KeySeq{"Control+F10", ""}:         KeyFunDebugRunToCursor,
+		KeySeq{"Shift+F9", ""}:            KeyFunDebugEvalAtCursor,
+		KeySeq{"Control+F9", ""}:          KeyFunDebugEvalSelection,
+		KeySeq{"Control+M", "3"}:          KeyFunPaneSplitH,
+		KeySeq{"Control+M", "2"}:          KeyFunPaneSplitV,
+		KeySeq{"Control+M", "0"}:          KeyFunPaneClose,
+		KeySeq{"Control+M", "1"}:          KeyFunPaneUnsplit,
+		KeySeq{"Control+M", "4"}:          KeyFunPaneSwap,
+		KeySeq{"Control+M", "LeftArrow"}:  KeyFunPaneFocusLeft,
+		KeySeq{"Control+M", "RightArrow"}: KeyFunPaneFocusRight,
+		KeySeq{"Control+M", "UpArrow"}:    KeyFunPaneFocusUp,
+		KeySeq{"Control+M", "DownArrow"}:  KeyFunPaneFocusDown,
+		KeySeq{"Control+F5", ""}:          KeyFunDebugReverseContinue,
+		KeySeq{"Shift+F10", ""}:           KeyFunDebugReverseNext,
+		KeySeq{"Control+F11", ""}:         KeyFunDebugReverseStep,
+		KeySeq{"Control+M", "Control+G"}:  KeyFunJumpToMatch,
+		KeySeq{"Control+M", "Control+L"}:  KeyFunFormatBuffer,
+		KeySeq{"Control+M", "Control+Q"}:  KeyFunReflowComment,
+		KeySeq{"Control+M", "Control+U"}:  KeyFunSpellMenu,
+		KeySeq{"Shift+Control+Y", ""}:     KeyFunYank,
+		KeySeq{"Shift+Control+V", ""}:     KeyFunYankPop,
+		KeySeq{"Control+M", "/"}:          KeyFunCommentToggle,
+		KeySeq{"Control+M", "Control+H"}:  KeyFunWhitespaceToggle,
+		KeySeq{"Control+M", "^"}:          KeyFunSortLines,
+		KeySeq{"Control+M", "="}:          KeyFunUniqueLines,
+		KeySeq{"Control+M", "Control+A"}:  KeyFunJoinLines,
+		KeySeq{"Control+M", "@"}:          KeyFunSymbolJump,
+		KeySeq{"Control+M", "L"}:          KeyFunSetLayout,
+		KeySeq{"Control+M", "N"}:          KeyFunNextLayout,
+		KeySeq{"Control+M", "T"}:          KeyFunSymbolSearch,
+		KeySeq{"Control+M", "B"}:          KeyFunBufAltToggle,
+		KeySeq{"Control+M", "O"}:          KeyFunPanelAltToggle,
+		KeySeq{"Control+M", "H"}:          KeyFunPanelsReveal,
+		KeySeq{"Control+M", "P"}:          KeyFunMarkdownPreview,
+		KeySeq{"Control+M", "D"}:          KeyFunDuplicateLines,
+		KeySeq{"Control+M", "}"}:          KeyFunNextFunc,
+		KeySeq{"Control+M", "{"}:          KeyFunPrevFunc,
+		KeySeq{"Control+M", "A"}:          KeyFunBeginDefun,
+		KeySeq{"Control+M", "E"}:          KeyFunEndDefun,
+		KeySeq{"Control+M", "+"}:          KeyFunExpandSel,
+		KeySeq{"Control+M", "_"}:          KeyFunShrinkSel,
+		KeySeq{"Control+M", "V"}:          KeyFunClipHistory,
+		KeySeq{"Control+PageDown", ""}:    KeyFunNextTab,
+		KeySeq{"Control+PageUp", ""}:      KeyFunPrevTab,
+		KeySeq{"Control+M", "Q"}:          KeyFunCloseOtherTabs,
+		KeySeq{"Control+M", "I"}:          KeyFunISearch,
+		KeySeq{"F4", ""}:                  KeyFunNextFind,
+		KeySeq{"Shift+F4", ""}:            KeyFunPrevFind,
 	}},
 	{"MacEmacs", "Mac with emacs-style navigation -- emacs wins in conflicts", KeySeqMap{
-		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
-		KeySeq{"Shift+Control+Tab", ""}:  KeyFunPrevPanel,
-		KeySeq{"Control+X", "o"}:         KeyFunNextPanel,
-		KeySeq{"Control+X", "Control+O"}: KeyFunNextPanel,
-		KeySeq{"Control+X", "p"}:         KeyFunPrevPanel,
-		KeySeq{"Control+X", "Control+P"}: KeyFunPrevPanel,
-		KeySeq{"Control+X", "f"}:         KeyFunFileOpen,
-		KeySeq{"Control+X", "Control+F"}: KeyFunFileOpen,
-		KeySeq{"Control+X", "b"}:         KeyFunBufSelect,
-		KeySeq{"Control+X", "Control+B"}: KeyFunBufSelect,
-		KeySeq{"Control+X", "s"}:         KeyFunBufSave,
-		KeySeq{"Control+X", "Control+S"}: KeyFunBufSave,
-		KeySeq{"Control+X", "w"}:         KeyFunBufSaveAs,
-		KeySeq{"Control+X", "Control+W"}: KeyFunBufSaveAs,
-		KeySeq{"Control+X", "k"}:         KeyFunBufClose,
-		KeySeq{"Control+X", "Control+K"}: KeyFunBufClose,
-		KeySeq{"Control+X", "c"}:         KeyFunExecCmd,
-		KeySeq{"Control+X", "Control+C"}: KeyFunExecCmd,
-		KeySeq{"Control+C", "c"}:         KeyFunExecCmd,
-		KeySeq{"Control+C", "Control+C"}: KeyFunExecCmd,
-		KeySeq{"Control+C", "o"}:         KeyFunBufClone,
-		KeySeq{"Control+C", "Control+O"}: KeyFunBufClone,
-		KeySeq{"Control+X", "x"}:         KeyFunRegCopy,
-		KeySeq{"Control+X", "g"}:         KeyFunRegPaste,
-		KeySeq{"Control+C", "k"}:         KeyFunCommentOut,
-		KeySeq{"Control+C", "Control+K"}: KeyFunCommentOut,
-		KeySeq{"Control+X", "i"}:         KeyFunIndent,
-		KeySeq{"Control+X", "Control+I"}: KeyFunIndent,
-		KeySeq{"Control+X", "j"}:         KeyFunJump,
-		KeySeq{"Control+X", "Control+J"}: KeyFunJump,
-		KeySeq{"Control+X", "v"}:         KeyFunSetSplit,
-		KeySeq{"Control+X", "Control+V"}: KeyFunSetSplit,
-		KeySeq{"Control+X", "m"}:         KeyFunBuildProj,
-		KeySeq{"Control+X", "Control+M"}: KeyFunBuildProj,
-		KeySeq{"Control+X", "r"}:         KeyFunRunProj,
-		KeySeq{"Control+X", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+Tab", ""}:         KeyFunNextPanel,
+		KeySeq{"Shift+Control+Tab", ""}:   KeyFunPrevPanel,
+		KeySeq{"Control+X", "o"}:          KeyFunNextPanel,
+		KeySeq{"Control+X", "Control+O"}:  KeyFunNextPanel,
+		KeySeq{"Control+X", "p"}:          KeyFunPrevPanel,
+		KeySeq{"Control+X", "Control+P"}:  KeyFunPrevPanel,
+		KeySeq{"Control+X", "f"}:          KeyFunFileOpen,
+		KeySeq{"Control+X", "Control+F"}:  KeyFunFileOpen,
+		KeySeq{"Control+X", "b"}:          KeyFunBufSelect,
+		KeySeq{"Control+X", "Control+B"}:  KeyFunBufSelect,
+		KeySeq{"Control+X", "s"}:          KeyFunBufSave,
+		KeySeq{"Control+X", "Control+S"}:  KeyFunBufSave,
+		KeySeq{"Control+X", "w"}:          KeyFunBufSaveAs,
+		KeySeq{"Control+X", "Control+W"}:  KeyFunBufSaveAs,
+		KeySeq{"Control+X", "k"}:          KeyFunBufClose,
+		KeySeq{"Control+X", "Control+K"}:  KeyFunBufClose,
+		KeySeq{"Control+X", "c"}:          KeyFunExecCmd,
+		KeySeq{"Control+X", "Control+C"}:  KeyFunExecCmd,
+		KeySeq{"Control+C", "c"}:          KeyFunExecCmd,
+		KeySeq{"Control+C", "Control+C"}:  KeyFunExecCmd,
+		KeySeq{"Control+C", "o"}:          KeyFunBufClone,
+		KeySeq{"Control+C", "Control+O"}:  KeyFunBufClone,
+		KeySeq{"Control+X", "x"}:          KeyFunRegCopy,
+		KeySeq{"Control+X", "g"}:          KeyFunRegPaste,
+		KeySeq{"Control+C", "k"}:          KeyFunCommentOut,
+		KeySeq{"Control+C", "Control+K"}:  KeyFunCommentOut,
+		KeySeq{"Control+X", "i"}:          KeyFunIndent,
+		KeySeq{"Control+X", "Control+I"}:  KeyFunIndent,
+		KeySeq{"Control+X", "j"}:          KeyFunJump,
+		KeySeq{"Control+X", "Control+J"}:  KeyFunJump,
+		KeySeq{"Control+X", "v"}:          KeyFunSetSplit,
+		KeySeq{"Control+X", "Control+V"}:  KeyFunSetSplit,
+		KeySeq{"Control+X", "m"}:          KeyFunBuildProj,
+		KeySeq{"Control+X", "Control+M"}:  KeyFunBuildProj,
+		KeySeq{"Control+X", "r"}:          KeyFunRunProj,
+		KeySeq{"Control+X", "Control+R"}:  KeyFunRunProj,
+		KeySeq{"Control+C", "d"}:          KeyFunGotoDef,
+		KeySeq{"Control+C", ","}:          KeyFunNavBack,
+		KeySeq{"Control+C", "."}:          KeyFunNavForward,
+		KeySeq{"Control+C", "r"}:          KeyFunFindRefs,
+		KeySeq{"Control+C", "h"}:          KeyFunShowDoc,
+		KeySeq{"Control+C", "p"}:          KeyFunPeekDef,
+		KeySeq{"Control+C", "Control+R"}:  KeyFunRename,
+		KeySeq{"Control+C", "-"}:          KeyFunFoldToggle,
+		KeySeq{"Control+C", "["}:          KeyFunFoldAll,
+		KeySeq{"Control+C", "]"}:          KeyFunUnfoldAll,
+		KeySeq{"Control+C", "Control+N"}:  KeyFunNextError,
+		KeySeq{"Control+C", "Control+P"}:  KeyFunPrevError,
+		KeySeq{"Control+C", "a"}:          KeyFunAddCursorAbove,
+		KeySeq{"Control+C", "b"}:          KeyFunAddCursorBelow,
+		KeySeq{"Control+C", "m"}:          KeyFunAddCursorNextMatch,
+		KeySeq{"Control+C", "l"}:          KeyFunAddCursorsToLines,
+		KeySeq{"Control+C", "x"}:          KeyFunRectSelect,
+		KeySeq{"Control+C", "w"}:          KeyFunRectCopy,
+		KeySeq{"Control+C", "Control+W"}:  KeyFunRectKill,
+		KeySeq{"Control+C", "y"}:          KeyFunRectYank,
+		KeySeq{"F5", ""}:                  KeyFunDebugContinue,
+		KeySeq{"Shift+F5", ""}:            KeyFunDebugStop,
+		KeySeq{"F9", ""}:                  KeyFunDebugToggleBreak,
+		KeySeq{"F10", ""}:                 KeyFunDebugNext,
+		KeySeq{"F11", ""}:                 KeyFunDebugStep,
+		KeySeq{"Shift+F11", ""}:           KeyFunDebugStepOut,
+		KeySeq{"Control+F10", ""}:         KeyFunDebugRunToCursor,
+		KeySeq{"Shift+F9", ""}:            KeyFunDebugEvalAtCursor,
+		KeySeq{"Control+F9", ""}:          KeyFunDebugEvalSelection,
+		KeySeq{"Control+X", "3"}:          KeyFunPaneSplitH,
+		KeySeq{"Control+X", "2"}:          KeyFunPaneSplitV,
+		KeySeq{"Control+X", "0"}:          KeyFunPaneClose,
+		KeySeq{"Control+X", "1"}:          KeyFunPaneUnsplit,
+		KeySeq{"Control+X", "4"}:          KeyFunPaneSwap,
+		KeySeq{"Control+C", "LeftArrow"}:  KeyFunPaneFocusLeft,
+		KeySeq{"Control+C", "RightArrow"}: KeyFunPaneFocusRight,
+		KeySeq{"Control+C", "UpArrow"}:    KeyFunPaneFocusUp,
+		KeySeq{"Control+C", "DownArrow"}:  KeyFunPaneFocusDown,
+		KeySeq{"Control+F5", ""}:          KeyFunDebugReverseContinue,
+		KeySeq{"Shift+F10", ""}:           KeyFunDebugReverseNext,
+		KeySeq{"Control+F11", ""}:         KeyFunDebugReverseStep,
+		KeySeq{"Control+C", "j"}:          KeyFunJumpToMatch,
+		KeySeq{"Control+C", "f"}:          KeyFunFormatBuffer,
+		KeySeq{"Control+C", "q"}:          KeyFunReflowComment,
+		KeySeq{"Control+C", "s"}:          KeyFunSpellMenu,
+		KeySeq{"Control+Y", ""}:           KeyFunYank,
+		KeySeq{"Alt+Y", ""}:               KeyFunYankPop,
+		KeySeq{"Control+C", "/"}:          KeyFunCommentToggle,
+		KeySeq{"Control+C", "i"}:          KeyFunWhitespaceToggle,
+		KeySeq{"Control+C", "^"}:          KeyFunSortLines,
+		KeySeq{"Control+C", "u"}:          KeyFunUniqueLines,
+		KeySeq{"Control+C", "Control+J"}:  KeyFunJoinLines,
+		KeySeq{"Control+C", "@"}:          KeyFunSymbolJump,
+		KeySeq{"Control+C", "Control+L"}:  KeyFunSetLayout,
+		KeySeq{"Control+C", "L"}:          KeyFunNextLayout,
+		KeySeq{"Control+C", "Control+T"}:  KeyFunSymbolSearch,
+		KeySeq{"Control+C", "B"}:          KeyFunBufAltToggle,
+		KeySeq{"Control+C", "O"}:          KeyFunPanelAltToggle,
+		KeySeq{"Control+C", "H"}:          KeyFunPanelsReveal,
+		KeySeq{"Control+C", "P"}:          KeyFunMarkdownPreview,
+		KeySeq{"Control+C", "D"}:          KeyFunDuplicateLines,
+		KeySeq{"Control+Alt+N", ""}:       KeyFunNextFunc,
+		KeySeq{"Control+Alt+P", ""}:       KeyFunPrevFunc,
+		KeySeq{"Control+Alt+A", ""}:       KeyFunBeginDefun,
+		KeySeq{"Control+Alt+E", ""}:       KeyFunEndDefun,
+		KeySeq{"Control+C", "+"}:          KeyFunExpandSel,
+		KeySeq{"Control+C", "_"}:          KeyFunShrinkSel,
+		KeySeq{"Control+X", "V"}:          KeyFunClipHistory,
+		KeySeq{"Control+PageDown", ""}:    KeyFunNextTab,
+		KeySeq{"Control+PageUp", ""}:      KeyFunPrevTab,
+		KeySeq{"Control+C", "Q"}:          KeyFunCloseOtherTabs,
+		KeySeq{"Control+S", ""}:           KeyFunISearch,
+		KeySeq{"F4", ""}:                  KeyFunNextFind,
+		KeySeq{"Shift+F4", ""}:            KeyFunPrevFind,
 	}},
 	{"LinuxEmacs", "Linux with emacs-style navigation -- emacs wins in conflicts", KeySeqMap{
-		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
-		KeySeq{"Shift+Control+Tab", ""}:  KeyFunPrevPanel,
-		KeySeq{"Control+X", "o"}:         KeyFunNextPanel,
-		KeySeq{"Control+X", "Control+O"}: KeyFunNextPanel,
-		KeySeq{"Control+X", "p"}:         KeyFunPrevPanel,
-		KeySeq{"Control+X", "Control+P"}: KeyFunPrevPanel,
-		KeySeq{"Control+X", "f"}:         KeyFunFileOpen,
-		KeySeq{"Control+X", "Control+F"}: KeyFunFileOpen,
-		KeySeq{"Control+X", "b"}:         KeyFunBufSelect,
-		KeySeq{"Control+X", "Control+B"}: KeyFunBufSelect,
-		KeySeq{"Control+X", "s"}:         KeyFunBufSave,
-		KeySeq{"Control+X", "Control+S"}: KeyFunBufSave,
-		KeySeq{"Control+X", "w"}:         KeyFunBufSaveAs,
-		KeySeq{"Control+X", "Control+W"}: KeyFunBufSaveAs,
-		KeySeq{"Control+X", "k"}:         KeyFunBufClose,
-		KeySeq{"Control+X", "Control+K"}: KeyFunBufClose,
-		KeySeq{"Control+X", "c"}:         KeyFunExecCmd,
-		KeySeq{"Control+X", "Control+C"}: KeyFunExecCmd,
-		KeySeq{"Control+C", "c"}:         KeyFunExecCmd,
-		KeySeq{"Control+C", "Control+C"}: KeyFunExecCmd,
-		KeySeq{"Control+C", "o"}:         KeyFunBufClone,
-		KeySeq{"Control+C", "Control+O"}: KeyFunBufClone,
-		KeySeq{"Control+X", "x"}:         KeyFunRegCopy,
-		KeySeq{"Control+X", "g"}:         KeyFunRegPaste,
-		KeySeq{"Control+C", "k"}:         KeyFunCommentOut,
-		KeySeq{"Control+C", "Control+K"}: KeyFunCommentOut,
-		KeySeq{"Control+X", "i"}:         KeyFunIndent,
-		KeySeq{"Control+X", "Control+I"}: KeyFunIndent,
-		KeySeq{"Control+X", "j"}:         KeyFunJump,
-		KeySeq{"Control+X", "Control+J"}: KeyFunJump,
-		KeySeq{"Control+X", "v"}:         KeyFunSetSplit,
-		KeySeq{"Control+X", "Control+V"}: KeyFunSetSplit,
-		KeySeq{"Control+M", "m"}:         KeyFunBuildProj,
-		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
-		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
-		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+Tab", ""}:         KeyFunNextPanel,
+		KeySeq{"Shift+Control+Tab", ""}:   KeyFunPrevPanel,
+		KeySeq{"Control+X", "o"}:          KeyFunNextPanel,
+		KeySeq{"Control+X", "Control+O"}:  KeyFunNextPanel,
+		KeySeq{"Control+X", "p"}:          KeyFunPrevPanel,
+		KeySeq{"Control+X", "Control+P"}:  KeyFunPrevPanel,
+		KeySeq{"Control+X", "f"}:          KeyFunFileOpen,
+		KeySeq{"Control+X", "Control+F"}:  KeyFunFileOpen,
+		KeySeq{"Control+X", "b"}:          KeyFunBufSelect,
+		KeySeq{"Control+X", "Control+B"}:  KeyFunBufSelect,
+		KeySeq{"Control+X", "s"}:          KeyFunBufSave,
+		KeySeq{"Control+X", "Control+S"}:  KeyFunBufSave,
+		KeySeq{"Control+X", "w"}:          KeyFunBufSaveAs,
+		KeySeq{"Control+X", "Control+W"}:  KeyFunBufSaveAs,
+		KeySeq{"Control+X", "k"}:          KeyFunBufClose,
+		KeySeq{"Control+X", "Control+K"}:  KeyFunBufClose,
+		KeySeq{"Control+X", "c"}:          KeyFunExecCmd,
+		KeySeq{"Control+X", "Control+C"}:  KeyFunExecCmd,
+		KeySeq{"Control+C", "c"}:          KeyFunExecCmd,
+		KeySeq{"Control+C", "Control+C"}:  KeyFunExecCmd,
+		KeySeq{"Control+C", "o"}:          KeyFunBufClone,
+		KeySeq{"Control+C", "Control+O"}:  KeyFunBufClone,
+		KeySeq{"Control+X", "x"}:          KeyFunRegCopy,
+		KeySeq{"Control+X", "g"}:          KeyFunRegPaste,
+		KeySeq{"Control+C", "k"}:          KeyFunCommentOut,
+		KeySeq{"Control+C", "Control+K"}:  KeyFunCommentOut,
+		KeySeq{"Control+X", "i"}:          KeyFunIndent,
+		KeySeq{"Control+X", "Control+I"}:  KeyFunIndent,
+		KeySeq{"Control+X", "j"}:          KeyFunJump,
+		KeySeq{"Control+X", "Control+J"}:  KeyFunJump,
+		KeySeq{"Control+X", "v"}:          KeyFunSetSplit,
+		KeySeq{"Control+X", "Control+V"}:  KeyFunSetSplit,
+		KeySeq{"Control+M", "m"}:          KeyFunBuildProj,
+		KeySeq{"Control+M", "Control+M"}:  KeyFunBuildProj,
+		KeySeq{"Control+M", "r"}:          KeyFunRunProj,
+		KeySeq{"Control+M", "Control+R"}:  KeyFunRunProj,
+		KeySeq{"Control+C", "d"}:          KeyFunGotoDef,
+		KeySeq{"Control+C", ","}:          KeyFunNavBack,
+		KeySeq{"Control+C", "."}:          KeyFunNavForward,
+		KeySeq{"Control+C", "r"}:          KeyFunFindRefs,
+		KeySeq{"Control+C", "h"}:          KeyFunShowDoc,
+		KeySeq{"Control+C", "p"}:          KeyFunPeekDef,
+		KeySeq{"Control+C", "Control+R"}:  KeyFunRename,
+		KeySeq{"Control+C", "-"}:          KeyFunFoldToggle,
+		KeySeq{"Control+C", "["}:          KeyFunFoldAll,
+		KeySeq{"Control+C", "]"}:          KeyFunUnfoldAll,
+		KeySeq{"Control+C", "Control+N"}:  KeyFunNextError,
+		KeySeq{"Control+C", "Control+P"}:  KeyFunPrevError,
+		KeySeq{"Control+C", "a"}:          KeyFunAddCursorAbove,
+		KeySeq{"Control+C", "b"}:          KeyFunAddCursorBelow,
+		KeySeq{"Control+C", "m"}:          KeyFunAddCursorNextMatch,
+		KeySeq{"Control+C", "l"}:          KeyFunAddCursorsToLines,
+		KeySeq{"Control+C", "x"}:          KeyFunRectSelect,
+		KeySeq{"Control+C", "w"}:          KeyFunRectCopy,
+		KeySeq{"Control+C", "Control+W"}:  KeyFunRectKill,
+		KeySeq{"Control+C", "y"}:          KeyFunRectYank,
+		KeySeq{"F5", ""}:                  KeyFunDebugContinue,
+		KeySeq{"Shift+F5", ""}:            KeyFunDebugStop,
+		KeySeq{"F9", ""}:                  KeyFunDebugToggleBreak,
+		KeySeq{"F10", ""}:                 KeyFunDebugNext,
+		KeySeq{"F11", ""}:                 KeyFunDebugStep,
+		KeySeq{"Shift+F11", ""}:           KeyFunDebugStepOut,
+		KeySeq{"Control+F10", ""}:         KeyFunDebugRunToCursor,
+		KeySeq{"Shift+F9", ""}:            KeyFunDebugEvalAtCursor,
+		KeySeq{"Control+F9", ""}:          KeyFunDebugEvalSelection,
+		KeySeq{"Control+X", "3"}:          KeyFunPaneSplitH,
+		KeySeq{"Control+X", "2"}:          KeyFunPaneSplitV,
+		KeySeq{"Control+X", "0"}:          KeyFunPaneClose,
+		KeySeq{"Control+X", "1"}:          KeyFunPaneUnsplit,
+		KeySeq{"Control+X", "4"}:          KeyFunPaneSwap,
+		KeySeq{"Control+C", "LeftArrow"}:  KeyFunPaneFocusLeft,
+		KeySeq{"Control+C", "RightArrow"}: KeyFunPaneFocusRight,
+		KeySeq{"Control+C", "UpArrow"}:    KeyFunPaneFocusUp,
+		KeySeq{"Control+C", "DownArrow"}:  KeyFunPaneFocusDown,
+		KeySeq{"Control+F5", ""}:          KeyFunDebugReverseContinue,
+		KeySeq{"Shift+F10", ""}:           KeyFunDebugReverseNext,
+		KeySeq{"Control+F11", ""}:         KeyFunDebugReverseStep,
+		KeySeq{"Control+C", "j"}:          KeyFunJumpToMatch,
+		KeySeq{"Control+C", "f"}:          KeyFunFormatBuffer,
+		KeySeq{"Control+C", "q"}:          KeyFunReflowComment,
+		KeySeq{"Control+C", "s"}:          KeyFunSpellMenu,
+		KeySeq{"Control+Y", ""}:           KeyFunYank,
+		KeySeq{"Alt+Y", ""}:               KeyFunYankPop,
+		KeySeq{"Control+C", "/"}:          KeyFunCommentToggle,
+		KeySeq{"Control+C", "i"}:          KeyFunWhitespaceToggle,
+		KeySeq{"Control+C", "^"}:          KeyFunSortLines,
+		KeySeq{"Control+C", "u"}:          KeyFunUniqueLines,
+		KeySeq{"Control+C", "Control+J"}:  KeyFunJoinLines,
+		KeySeq{"Control+C", "@"}:          KeyFunSymbolJump,
+		KeySeq{"Control+C", "Control+L"}:  KeyFunSetLayout,
+		KeySeq{"Control+C", "L"}:          KeyFunNextLayout,
+		KeySeq{"Control+C", "Control+T"}:  KeyFunSymbolSearch,
+		KeySeq{"Control+C", "B"}:          KeyFunBufAltToggle,
+		KeySeq{"Control+C", "O"}:          KeyFunPanelAltToggle,
+		KeySeq{"Control+C", "H"}:          KeyFunPanelsReveal,
+		KeySeq{"Control+C", "P"}:          KeyFunMarkdownPreview,
+		KeySeq{"Control+C", "D"}:          KeyFunDuplicateLines,
+		KeySeq{"Control+Alt+N", ""}:       KeyFunNextFunc,
+		KeySeq{"Control+Alt+P", ""}:       KeyFunPrevFunc,
+		KeySeq{"Control+Alt+A", ""}:       KeyFunBeginDefun,
+		KeySeq{"Control+Alt+E", ""}:       KeyFunEndDefun,
+		KeySeq{"Control+C", "+"}:          KeyFunExpandSel,
+		KeySeq{"Control+C", "_"}:          KeyFunShrinkSel,
+		KeySeq{"Control+X", "V"}:          KeyFunClipHistory,
+		KeySeq{"Control+PageDown", ""}:    KeyFunNextTab,
+		KeySeq{"Control+PageUp", ""}:      KeyFunPrevTab,
+		KeySeq{"Control+C", "Q"}:          KeyFunCloseOtherTabs,
+		KeySeq{"Control+S", ""}:           KeyFunISearch,
+		KeySeq{"F4", ""}:                  KeyFunNextFind,
+		KeySeq{"Shift+F4", ""}:            KeyFunPrevFind,
 	}},
 	{"LinuxStd", "Standard Linux KeySeqMap", KeySeqMap{
-		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
-		KeySeq{"Shift+Control+Tab", ""}:  KeyFunPrevPanel,
-		KeySeq{"Control+M", "o"}:         KeyFunNextPanel,
-		KeySeq{"Control+M", "Control+O"}: KeyFunNextPanel,
-		KeySeq{"Control+M", "p"}:         KeyFunPrevPanel,
-		KeySeq{"Control+M", "Control+P"}: KeyFunPrevPanel,
-		KeySeq{"Control+O", ""}:          KeyFunFileOpen,
-		KeySeq{"Control+M", "f"}:         KeyFunFileOpen,
-		KeySeq{"Control+M", "Control+F"}: KeyFunFileOpen,
-		KeySeq{"Control+M", "b"}:         KeyFunBufSelect,
-		KeySeq{"Control+M", "Control+B"}: KeyFunBufSelect,
-		KeySeq{"Control+S", ""}:          KeyFunBufSave,
-		KeySeq{"Shift+Control+S", ""}:    KeyFunBufSaveAs,
-		KeySeq{"Control+M", "s"}:         KeyFunBufSave,
-		KeySeq{"Control+M", "Control+S"}: KeyFunBufSave,
-		KeySeq{"Control+M", "w"}:         KeyFunBufSaveAs,
-		KeySeq{"Control+M", "Control+W"}: KeyFunBufSaveAs,
-		KeySeq{"Control+M", "k"}:         KeyFunBufClose,
-		KeySeq{"Control+M", "Control+K"}: KeyFunBufClose,
-		KeySeq{"Control+M", "c"}:         KeyFunExecCmd,
-		KeySeq{"Control+M", "Control+C"}: KeyFunExecCmd,
-		KeySeq{"Control+M", "n"}:         KeyFunBufClone,
-		KeySeq{"Control+M", "Control+N"}: KeyFunBufClone,
-		KeySeq{"Control+M", "x"}:         KeyFunRegCopy,
-		KeySeq{"Control+M", "g"}:         KeyFunRegPaste,
-		KeySeq{"Control+/", ""}:          KeyFunCommentOut,
-		KeySeq{"Control+M", "t"}:         KeyFunCommentOut,
-		KeySeq{"Control+M", "Control+T"}: KeyFunCommentOut,
-		KeySeq{"Control+M", "i"}:         KeyFunIndent,
-		KeySeq{"Control+M", "Control+I"}: KeyFunIndent,
-		KeySeq{"Control+M", "j"}:         KeyFunJump,
-		KeySeq{"Control+M", "Control+J"}: KeyFunJump,
-		KeySeq{"Control+M", "v"}:         KeyFunSetSplit,
-		KeySeq{"Control+M", "Control+V"}: KeyFunSetSplit,
-		KeySeq{"Control+M", "m"}:         KeyFunBuildProj,
-		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
-		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
-		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+Tab", ""}:         KeyFunNextPanel,
+		KeySeq{"Shift+Control+Tab", ""}:   KeyFunPrevPanel,
+		KeySeq{"Control+M", "o"}:          KeyFunNextPanel,
+		KeySeq{"Control+M", "Control+O"}:  KeyFunNextPanel,
+		KeySeq{"Control+M", "p"}:          KeyFunPrevPanel,
+		KeySeq{"Control+M", "Control+P"}:  KeyFunPrevPanel,
+		KeySeq{"Control+O", ""}:           KeyFunFileOpen,
+		KeySeq{"Control+M", "f"}:          KeyFunFileOpen,
+		KeySeq{"Control+M", "Control+F"}:  KeyFunFileOpen,
+		KeySeq{"Control+M", "b"}:          KeyFunBufSelect,
+		KeySeq{"Control+M", "Control+B"}:  KeyFunBufSelect,
+		KeySeq{"Control+S", ""}:           KeyFunBufSave,
+		KeySeq{"Shift+Control+S", ""}:     KeyFunBufSaveAs,
+		KeySeq{"Control+M", "s"}:          KeyFunBufSave,
+		KeySeq{"Control+M", "Control+S"}:  KeyFunBufSave,
+		KeySeq{"Control+M", "w"}:          KeyFunBufSaveAs,
+		KeySeq{"Control+M", "Control+W"}:  KeyFunBufSaveAs,
+		KeySeq{"Control+M", "k"}:          KeyFunBufClose,
+		KeySeq{"Control+M", "Control+K"}:  KeyFunBufClose,
+		KeySeq{"Control+M", "c"}:          KeyFunExecCmd,
+		KeySeq{"Control+M", "Control+C"}:  KeyFunExecCmd,
+		KeySeq{"Control+M", "n"}:          KeyFunBufClone,
+		KeySeq{"Control+M", "Control+N"}:  KeyFunBufClone,
+		KeySeq{"Control+M", "x"}:          KeyFunRegCopy,
+		KeySeq{"Control+M", "g"}:          KeyFunRegPaste,
+		KeySeq{"Control+/", ""}:           KeyFunCommentOut,
+		KeySeq{"Control+M", "t"}:          KeyFunCommentOut,
+		KeySeq{"Control+M", "Control+T"}:  KeyFunCommentOut,
+		KeySeq{"Control+M", "i"}:          KeyFunIndent,
+		KeySeq{"Control+M", "Control+I"}:  KeyFunIndent,
+		KeySeq{"Control+M", "j"}:          KeyFunJump,
+		KeySeq{"Control+M", "Control+J"}:  KeyFunJump,
+		KeySeq{"Control+M", "v"}:          KeyFunSetSplit,
+		KeySeq{"Control+M", "Control+V"}:  KeyFunSetSplit,
+		KeySeq{"Control+M", "m"}:          KeyFunBuildProj,
+		KeySeq{"Control+M", "Control+M"}:  KeyFunBuildProj,
+		KeySeq{"Control+M", "r"}:          KeyFunRunProj,
+		KeySeq{"Control+M", "Control+R"}:  KeyFunRunProj,
+		KeySeq{"Control+M", "d"}:          KeyFunGotoDef,
+		KeySeq{"Control+M", ","}:          KeyFunNavBack,
+		KeySeq{"Control+M", "."}:          KeyFunNavForward,
+		KeySeq{"Control+M", "u"}:          KeyFunFindRefs,
+		KeySeq{"Control+M", "h"}:          KeyFunShowDoc,
+		KeySeq{"Control+M", "e"}:          KeyFunPeekDef,
+		KeySeq{"Control+M", "Control+E"}:  KeyFunRename,
+		KeySeq{"Control+M", "-"}:          KeyFunFoldToggle,
+		KeySeq{"Control+M", "["}:          KeyFunFoldAll,
+		KeySeq{"Control+M", "]"}:          KeyFunUnfoldAll,
+		KeySeq{"F8", ""}:                  KeyFunNextError,
+		KeySeq{"Shift+F8", ""}:            KeyFunPrevError,
+		KeySeq{"Control+M", "a"}:          KeyFunAddCursorAbove,
+		KeySeq{"Control+M", "z"}:          KeyFunAddCursorBelow,
+		KeySeq{"Control+M", "Control+D"}:  KeyFunAddCursorNextMatch,
+		KeySeq{"Control+M", "l"}:          KeyFunAddCursorsToLines,
+		KeySeq{"Control+M", "Control+X"}:  KeyFunRectSelect,
+		KeySeq{"Control+M", "y"}:          KeyFunRectCopy,
+		KeySeq{"Control+M", "q"}:          KeyFunRectKill,
+		KeySeq{"Control+M", "Control+Y"}:  KeyFunRectYank,
+		KeySeq{"F5", ""}:                  KeyFunDebugContinue,
+		KeySeq{"Shift+F5", ""}:            KeyFunDebugStop,
+		KeySeq{"F9", ""}:                  KeyFunDebugToggleBreak,
+		KeySeq{"F10", ""}:                 KeyFunDebugNext,
+		KeySeq{"F11", ""}:                 KeyFunDebugStep,
+		KeySeq{"Shift+F11", ""}:           KeyFunDebugStepOut,
+		KeySeq{"Control+F10", ""}:         KeyFunDebugRunToCursor,
+		KeySeq{"Shift+F9", ""}:            KeyFunDebugEvalAtCursor,
+		KeySeq{"Control+F9", ""}:          KeyFunDebugEvalSelection,
+		KeySeq{"Control+M", "3"}:          KeyFunPaneSplitH,
+		KeySeq{"Control+M", "2"}:          KeyFunPaneSplitV,
+		KeySeq{"Control+M", "0"}:          KeyFunPaneClose,
+		KeySeq{"Control+M", "1"}:          KeyFunPaneUnsplit,
+		KeySeq{"Control+M", "4"}:          KeyFunPaneSwap,
+		KeySeq{"Control+M", "LeftArrow"}:  KeyFunPaneFocusLeft,
+		KeySeq{"Control+M", "RightArrow"}: KeyFunPaneFocusRight,
+		KeySeq{"Control+M", "UpArrow"}:    KeyFunPaneFocusUp,
+		KeySeq{"Control+M", "DownArrow"}:  KeyFunPaneFocusDown,
+		KeySeq{"Control+F5", ""}:          KeyFunDebugReverseContinue,
+		KeySeq{"Shift+F10", ""}:           KeyFunDebugReverseNext,
+		KeySeq{"Control+F11", ""}:         KeyFunDebugReverseStep,
+		KeySeq{"Control+M", "Control+G"}:  KeyFunJumpToMatch,
+		KeySeq{"Control+M", "Control+L"}:  KeyFunFormatBuffer,
+		KeySeq{"Control+M", "Control+Q"}:  KeyFunReflowComment,
+		KeySeq{"Control+M", "Control+U"}:  KeyFunSpellMenu,
+		KeySeq{"Shift+Control+Y", ""}:     KeyFunYank,
+		KeySeq{"Shift+Control+V", ""}:     KeyFunYankPop,
+		KeySeq{"Control+M", "/"}:          KeyFunCommentToggle,
+		KeySeq{"Control+M", "Control+H"}:  KeyFunWhitespaceToggle,
+		KeySeq{"Control+M", "^"}:          KeyFunSortLines,
+		KeySeq{"Control+M", "="}:          KeyFunUniqueLines,
+		KeySeq{"Control+M", "Control+A"}:  KeyFunJoinLines,
+		KeySeq{"Control+M", "@"}:          KeyFunSymbolJump,
+		KeySeq{"Control+M", "L"}:          KeyFunSetLayout,
+		KeySeq{"Control+M", "N"}:          KeyFunNextLayout,
+		KeySeq{"Control+M", "T"}:          KeyFunSymbolSearch,
+		KeySeq{"Control+M", "B"}:          KeyFunBufAltToggle,
+		KeySeq{"Control+M", "O"}:          KeyFunPanelAltToggle,
+		KeySeq{"Control+M", "H"}:          KeyFunPanelsReveal,
+		KeySeq{"Control+M", "P"}:          KeyFunMarkdownPreview,
+		KeySeq{"Control+M", "D"}:          KeyFunDuplicateLines,
+		KeySeq{"Control+M", "}"}:          KeyFunNextFunc,
+		KeySeq{"Control+M", "{"}:          KeyFunPrevFunc,
+		KeySeq{"Control+M", "A"}:          KeyFunBeginDefun,
+		KeySeq{"Control+M", "E"}:          KeyFunEndDefun,
+		KeySeq{"Control+M", "+"}:          KeyFunExpandSel,
+		KeySeq{"Control+M", "_"}:          KeyFunShrinkSel,
+		KeySeq{"Control+M", "V"}:          KeyFunClipHistory,
+		KeySeq{"Control+PageDown", ""}:    KeyFunNextTab,
+		KeySeq{"Control+PageUp", ""}:      KeyFunPrevTab,
+		KeySeq{"Control+M", "Q"}:          KeyFunCloseOtherTabs,
+		KeySeq{"Control+M", "I"}:          KeyFunISearch,
+		KeySeq{"F4", ""}:                  KeyFunNextFind,
+		KeySeq{"Shift+F4", ""}:            KeyFunPrevFind,
 	}},
 	{"WindowsStd", "Standard Windows KeySeqMap", KeySeqMap{
-		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
-		KeySeq{"Shift+Control+Tab", ""}:  KeyFunPrevPanel,
-		KeySeq{"Control+M", "o"}:         KeyFunNextPanel,
-		KeySeq{"Control+M", "Control+O"}: KeyFunNextPanel,
-		KeySeq{"Control+M", "p"}:         KeyFunPrevPanel,
-		KeySeq{"Control+M", "Control+P"}: KeyFunPrevPanel,
-		KeySeq{"Control+O", ""}:          KeyFunFileOpen,
-		KeySeq{"Control+M", "f"}:         KeyFunFileOpen,
-		KeySeq{"Control+M", "Control+F"}: KeyFunFileOpen,
-		KeySeq{"Control+M", "b"}:         KeyFunBufSelect,
-		KeySeq{"Control+M", "Control+B"}: KeyFunBufSelect,
-		KeySeq{"Control+S", ""}:          KeyFunBufSave,
-		KeySeq{"Shift+Control+S", ""}:    KeyFunBufSaveAs,
-		KeySeq{"Control+M", "s"}:         KeyFunBufSave,
-		KeySeq{"Control+M", "Control+S"}: KeyFunBufSave,
-		KeySeq{"Control+M", "w"}:         KeyFunBufSaveAs,
-		KeySeq{"Control+M", "Control+W"}: KeyFunBufSaveAs,
-		KeySeq{"Control+M", "k"}:         KeyFunBufClose,
-		KeySeq{"Control+M", "Control+K"}: KeyFunBufClose,
-		KeySeq{"Control+M", "c"}:         KeyFunExecCmd,
-		KeySeq{"Control+M", "Control+C"}: KeyFunExecCmd,
-		KeySeq{"Control+M", "n"}:         KeyFunBufClone,
-		KeySeq{"Control+M", "Control+N"}: KeyFunBufClone,
-		KeySeq{"Control+M", "x"}:         KeyFunRegCopy,
-		KeySeq{"Control+M", "g"}:         KeyFunRegPaste,
-		KeySeq{"Control+/", ""}:          KeyFunCommentOut,
-		KeySeq{"Control+M", "t"}:         KeyFunCommentOut,
-		KeySeq{"Control+M", "Control+T"}: KeyFunCommentOut,
-		KeySeq{"Control+M", "i"}:         KeyFunIndent,
-		KeySeq{"Control+M", "Control+I"}: KeyFunIndent,
-		KeySeq{"Control+M", "j"}:         KeyFunJump,
-		KeySeq{"Control+M", "Control+J"}: KeyFunJump,
-		KeySeq{"Control+M", "v"}:         KeyFunSetSplit,
-		KeySeq{"Control+M", "Control+V"}: KeyFunSetSplit,
-		KeySeq{"Control+M", "m"}:         KeyFunBuildProj,
-		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
-		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
-		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+Tab", ""}:         KeyFunNextPanel,
+		KeySeq{"Shift+Control+Tab", ""}:   KeyFunPrevPanel,
+		KeySeq{"Control+M", "o"}:          KeyFunNextPanel,
+		KeySeq{"Control+M", "Control+O"}:  KeyFunNextPanel,
+		KeySeq{"Control+M", "p"}:          KeyFunPrevPanel,
+		KeySeq{"Control+M", "Control+P"}:  KeyFunPrevPanel,
+		KeySeq{"Control+O", ""}:           KeyFunFileOpen,
+		KeySeq{"Control+M", "f"}:          KeyFunFileOpen,
+		KeySeq{"Control+M", "Control+F"}:  KeyFunFileOpen,
+		KeySeq{"Control+M", "b"}:          KeyFunBufSelect,
+		KeySeq{"Control+M", "Control+B"}:  KeyFunBufSelect,
+		KeySeq{"Control+S", ""}:           KeyFunBufSave,
+		KeySeq{"Shift+Control+S", ""}:     KeyFunBufSaveAs,
+		KeySeq{"Control+M", "s"}:          KeyFunBufSave,
+		KeySeq{"Control+M", "Control+S"}:  KeyFunBufSave,
+		KeySeq{"Control+M", "w"}:          KeyFunBufSaveAs,
+		KeySeq{"Control+M", "Control+W"}:  KeyFunBufSaveAs,
+		KeySeq{"Control+M", "k"}:          KeyFunBufClose,
+		KeySeq{"Control+M", "Control+K"}:  KeyFunBufClose,
+		KeySeq{"Control+M", "c"}:          KeyFunExecCmd,
+		KeySeq{"Control+M", "Control+C"}:  KeyFunExecCmd,
+		KeySeq{"Control+M", "n"}:          KeyFunBufClone,
+		KeySeq{"Control+M", "Control+N"}:  KeyFunBufClone,
+		KeySeq{"Control+M", "x"}:          KeyFunRegCopy,
+		KeySeq{"Control+M", "g"}:          KeyFunRegPaste,
+		KeySeq{"Control+/", ""}:           KeyFunCommentOut,
+		KeySeq{"Control+M", "t"}:          KeyFunCommentOut,
+		KeySeq{"Control+M", "Control+T"}:  KeyFunCommentOut,
+		KeySeq{"Control+M", "i"}:          KeyFunIndent,
+		KeySeq{"Control+M", "Control+I"}:  KeyFunIndent,
+		KeySeq{"Control+M", "j"}:          KeyFunJump,
+		KeySeq{"Control+M", "Control+J"}:  KeyFunJump,
+		KeySeq{"Control+M", "v"}:          KeyFunSetSplit,
+		KeySeq{"Control+M", "Control+V"}:  KeyFunSetSplit,
+		KeySeq{"Control+M", "m"}:          KeyFunBuildProj,
+		KeySeq{"Control+M", "Control+M"}:  KeyFunBuildProj,
+		KeySeq{"Control+M", "r"}:          KeyFunRunProj,
+		KeySeq{"Control+M", "Control+R"}:  KeyFunRunProj,
+		KeySeq{"Control+M", "d"}:          KeyFunGotoDef,
+		KeySeq{"Control+M", ","}:          KeyFunNavBack,
+		KeySeq{"Control+M", "."}:          KeyFunNavForward,
+		KeySeq{"Control+M", "u"}:          KeyFunFindRefs,
+		KeySeq{"Control+M", "h"}:          KeyFunShowDoc,
+		KeySeq{"Control+M", "e"}:          KeyFunPeekDef,
+		KeySeq{"Control+M", "Control+E"}:  KeyFunRename,
+		KeySeq{"Control+M", "-"}:          KeyFunFoldToggle,
+		KeySeq{"Control+M", "["}:          KeyFunFoldAll,
+		KeySeq{"Control+M", "]"}:          KeyFunUnfoldAll,
+		KeySeq{"F8", ""}:                  KeyFunNextError,
+		KeySeq{"Shift+F8", ""}:            KeyFunPrevError,
+		KeySeq{"Control+M", "a"}:          KeyFunAddCursorAbove,
+		KeySeq{"Control+M", "z"}:          KeyFunAddCursorBelow,
+		KeySeq{"Control+M", "Control+D"}:  KeyFunAddCursorNextMatch,
+		KeySeq{"Control+M", "l"}:          KeyFunAddCursorsToLines,
+		KeySeq{"Control+M", "Control+X"}:  KeyFunRectSelect,
+		KeySeq{"Control+M", "y"}:          KeyFunRectCopy,
+		KeySeq{"Control+M", "q"}:          KeyFunRectKill,
+		KeySeq{"Control+M", "Control+Y"}:  KeyFunRectYank,
+		KeySeq{"F5", ""}:                  KeyFunDebugContinue,
+		KeySeq{"Shift+F5", ""}:            KeyFunDebugStop,
+		KeySeq{"F9", ""}:                  KeyFunDebugToggleBreak,
+		KeySeq{"F10", ""}:                 KeyFunDebugNext,
+		KeySeq{"F11", ""}:                 KeyFunDebugStep,
+		KeySeq{"Shift+F11", ""}:           KeyFunDebugStepOut,
+		KeySeq{"Control+F10", ""}:         KeyFunDebugRunToCursor,
+		KeySeq{"Shift+F9", ""}:            KeyFunDebugEvalAtCursor,
+		KeySeq{"Control+F9", ""}:          KeyFunDebugEvalSelection,
+		KeySeq{"Control+M", "3"}:          KeyFunPaneSplitH,
+		KeySeq{"Control+M", "2"}:          KeyFunPaneSplitV,
+		KeySeq{"Control+M", "0"}:          KeyFunPaneClose,
+		KeySeq{"Control+M", "1"}:          KeyFunPaneUnsplit,
+		KeySeq{"Control+M", "4"}:          KeyFunPaneSwap,
+		KeySeq{"Control+M", "LeftArrow"}:  KeyFunPaneFocusLeft,
+		KeySeq{"Control+M", "RightArrow"}: KeyFunPaneFocusRight,
+		KeySeq{"Control+M", "UpArrow"}:    KeyFunPaneFocusUp,
+		KeySeq{"Control+M", "DownArrow"}:  KeyFunPaneFocusDown,
+		KeySeq{"Control+F5", ""}:          KeyFunDebugReverseContinue,
+		KeySeq{"Shift+F10", ""}:           KeyFunDebugReverseNext,
+		KeySeq{"Control+F11", ""}:         KeyFunDebugReverseStep,
+		KeySeq{"Control+M", "Control+G"}:  KeyFunJumpToMatch,
+		KeySeq{"Control+M", "Control+L"}:  KeyFunFormatBuffer,
+		KeySeq{"Control+M", "Control+Q"}:  KeyFunReflowComment,
+		KeySeq{"Control+M", "Control+U"}:  KeyFunSpellMenu,
+		KeySeq{"Shift+Control+Y", ""}:     KeyFunYank,
+		KeySeq{"Shift+Control+V", ""}:     KeyFunYankPop,
+		KeySeq{"Control+M", "/"}:          KeyFunCommentToggle,
+		KeySeq{"Control+M", "Control+H"}:  KeyFunWhitespaceToggle,
+		KeySeq{"Control+M", "^"}:          KeyFunSortLines,
+		KeySeq{"Control+M", "="}:          KeyFunUniqueLines,
+		KeySeq{"Control+M", "Control+A"}:  KeyFunJoinLines,
+		KeySeq{"Control+M", "@"}:          KeyFunSymbolJump,
+		KeySeq{"Control+M", "L"}:          KeyFunSetLayout,
+		KeySeq{"Control+M", "N"}:          KeyFunNextLayout,
+		KeySeq{"Control+M", "T"}:          KeyFunSymbolSearch,
+		KeySeq{"Control+M", "B"}:          KeyFunBufAltToggle,
+		KeySeq{"Control+M", "O"}:          KeyFunPanelAltToggle,
+		KeySeq{"Control+M", "H"}:          KeyFunPanelsReveal,
+		KeySeq{"Control+M", "P"}:          KeyFunMarkdownPreview,
+		KeySeq{"Control+M", "D"}:          KeyFunDuplicateLines,
+		KeySeq{"Control+M", "}"}:          KeyFunNextFunc,
+		KeySeq{"Control+M", "{"}:          KeyFunPrevFunc,
+		KeySeq{"Control+M", "A"}:          KeyFunBeginDefun,
+		KeySeq{"Control+M", "E"}:          KeyFunEndDefun,
+		KeySeq{"Control+M", "+"}:          KeyFunExpandSel,
+		KeySeq{"Control+M", "_"}:          KeyFunShrinkSel,
+		KeySeq{"Control+M", "V"}:          KeyFunClipHistory,
+		KeySeq{"Control+PageDown", ""}:    KeyFunNextTab,
+		KeySeq{"Control+PageUp", ""}:      KeyFunPrevTab,
+		KeySeq{"Control+M", "Q"}:          KeyFunCloseOtherTabs,
+		KeySeq{"Control+M", "I"}:          KeyFunISearch,
+		KeySeq{"F4", ""}:                  KeyFunNextFind,
+		KeySeq{"Shift+F4", ""}:            KeyFunPrevFind,
 	}},
 	{"ChromeStd", "Standard chrome-browser and linux-under-chrome bindings", KeySeqMap{
-		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
-		KeySeq{"Shift+Control+Tab", ""}:  KeyFunPrevPanel,
-		KeySeq{"Control+M", "o"}:         KeyFunNextPanel,
-		KeySeq{"Control+M", "Control+O"}: KeyFunNextPanel,
-		KeySeq{"Control+M", "p"}:         KeyFunPrevPanel,
-		KeySeq{"Control+M", "Control+P"}: KeyFunPrevPanel,
-		KeySeq{"Control+O", ""}:          KeyFunFileOpen,
-		KeySeq{"Control+M", "f"}:         KeyFunFileOpen,
-		KeySeq{"Control+M", "Control+F"}: KeyFunFileOpen,
-		KeySeq{"Control+M", "b"}:         KeyFunBufSelect,
-		KeySeq{"Control+M", "Control+B"}: KeyFunBufSelect,
-		KeySeq{"Control+S", ""}:          KeyFunBufSave,
-		KeySeq{"Shift+Control+S", ""}:    KeyFunBufSaveAs,
-		KeySeq{"Control+M", "s"}:         KeyFunBufSave,
-		KeySeq{"Control+M", "Control+S"}: KeyFunBufSave,
-		KeySeq{"Control+M", "w"}:         KeyFunBufSaveAs,
-		KeySeq{"Control+M", "Control+W"}: KeyFunBufSaveAs,
-		KeySeq{"Control+M", "k"}:         KeyFunBufClose,
-		KeySeq{"Control+M", "Control+K"}: KeyFunBufClose,
-		KeySeq{"Control+M", "c"}:         KeyFunExecCmd,
-		KeySeq{"Control+M", "Control+C"}: KeyFunExecCmd,
-		KeySeq{"Control+M", "n"}:         KeyFunBufClone,
-		KeySeq{"Control+M", "Control+N"}: KeyFunBufClone,
-		KeySeq{"Control+M", "x"}:         KeyFunRegCopy,
-		KeySeq{"Control+M", "g"}:         KeyFunRegPaste,
-		KeySeq{"Control+/", ""}:          KeyFunCommentOut,
-		KeySeq{"Control+M", "t"}:         KeyFunCommentOut,
-		KeySeq{"Control+M", "Control+T"}: KeyFunCommentOut,
-		KeySeq{"Control+M", "i"}:         KeyFunIndent,
-		KeySeq{"Control+M", "Control+I"}: KeyFunIndent,
-		KeySeq{"Control+M", "j"}:         KeyFunJump,
-		KeySeq{"Control+M", "Control+J"}: KeyFunJump,
-		KeySeq{"Control+M", "v"}:         KeyFunSetSplit,
-		KeySeq{"Control+M", "Control+V"}: KeyFunSetSplit,
-		KeySeq{"Control+M", "m"}:         KeyFunBuildProj,
-		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
-		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
-		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+Tab", ""}:         KeyFunNextPanel,
+		KeySeq{"Shift+Control+Tab", ""}:   KeyFunPrevPanel,
+		KeySeq{"Control+M", "o"}:          KeyFunNextPanel,
+		KeySeq{"Control+M", "Control+O"}:  KeyFunNextPanel,
+		KeySeq{"Control+M", "p"}:          KeyFunPrevPanel,
+		KeySeq{"Control+M", "Control+P"}:  KeyFunPrevPanel,
+		KeySeq{"Control+O", ""}:           KeyFunFileOpen,
+		KeySeq{"Control+M", "f"}:          KeyFunFileOpen,
+		KeySeq{"Control+M", "Control+F"}:  KeyFunFileOpen,
+		KeySeq{"Control+M", "b"}:          KeyFunBufSelect,
+		KeySeq{"Control+M", "Control+B"}:  KeyFunBufSelect,
+		KeySeq{"Control+S", ""}:           KeyFunBufSave,
+		KeySeq{"Shift+Control+S", ""}:     KeyFunBufSaveAs,
+		KeySeq{"Control+M", "s"}:          KeyFunBufSave,
+		KeySeq{"Control+M", "Control+S"}:  KeyFunBufSave,
+		KeySeq{"Control+M", "w"}:          KeyFunBufSaveAs,
+		KeySeq{"Control+M", "Control+W"}:  KeyFunBufSaveAs,
+		KeySeq{"Control+M", "k"}:          KeyFunBufClose,
+		KeySeq{"Control+M", "Control+K"}:  KeyFunBufClose,
+		KeySeq{"Control+M", "c"}:          KeyFunExecCmd,
+		KeySeq{"Control+M", "Control+C"}:  KeyFunExecCmd,
+		KeySeq{"Control+M", "n"}:          KeyFunBufClone,
+		KeySeq{"Control+M", "Control+N"}:  KeyFunBufClone,
+		KeySeq{"Control+M", "x"}:          KeyFunRegCopy,
+		KeySeq{"Control+M", "g"}:          KeyFunRegPaste,
+		KeySeq{"Control+/", ""}:           KeyFunCommentOut,
+		KeySeq{"Control+M", "t"}:          KeyFunCommentOut,
+		KeySeq{"Control+M", "Control+T"}:  KeyFunCommentOut,
+		KeySeq{"Control+M", "i"}:          KeyFunIndent,
+		KeySeq{"Control+M", "Control+I"}:  KeyFunIndent,
+		KeySeq{"Control+M", "j"}:          KeyFunJump,
+		KeySeq{"Control+M", "Control+J"}:  KeyFunJump,
+		KeySeq{"Control+M", "v"}:          KeyFunSetSplit,
+		KeySeq{"Control+M", "Control+V"}:  KeyFunSetSplit,
+		KeySeq{"Control+M", "m"}:          KeyFunBuildProj,
+		KeySeq{"Control+M", "Control+M"}:  KeyFunBuildProj,
+		KeySeq{"Control+M", "r"}:          KeyFunRunProj,
+		KeySeq{"Control+M", "Control+R"}:  KeyFunRunProj,
+		KeySeq{"Control+M", "d"}:          KeyFunGotoDef,
+		KeySeq{"Control+M", ","}:          KeyFunNavBack,
+		KeySeq{"Control+M", "."}:          KeyFunNavForward,
+		KeySeq{"Control+M", "u"}:          KeyFunFindRefs,
+		KeySeq{"Control+M", "h"}:          KeyFunShowDoc,
+		KeySeq{"Control+M", "e"}:          KeyFunPeekDef,
+		KeySeq{"Control+M", "Control+E"}:  KeyFunRename,
+		KeySeq{"Control+M", "-"}:          KeyFunFoldToggle,
+		KeySeq{"Control+M", "["}:          KeyFunFoldAll,
+		KeySeq{"Control+M", "]"}:          KeyFunUnfoldAll,
+		KeySeq{"F8", ""}:                  KeyFunNextError,
+		KeySeq{"Shift+F8", ""}:            KeyFunPrevError,
+		KeySeq{"Control+M", "a"}:          KeyFunAddCursorAbove,
+		KeySeq{"Control+M", "z"}:          KeyFunAddCursorBelow,
+		KeySeq{"Control+M", "Control+D"}:  KeyFunAddCursorNextMatch,
+		KeySeq{"Control+M", "l"}:          KeyFunAddCursorsToLines,
+		KeySeq{"Control+M", "Control+X"}:  KeyFunRectSelect,
+		KeySeq{"Control+M", "y"}:          KeyFunRectCopy,
+		KeySeq{"Control+M", "q"}:          KeyFunRectKill,
+		KeySeq{"Control+M", "Control+Y"}:  KeyFunRectYank,
+		KeySeq{"F5", ""}:                  KeyFunDebugContinue,
+		KeySeq{"Shift+F5", ""}:            KeyFunDebugStop,
+		KeySeq{"F9", ""}:                  KeyFunDebugToggleBreak,
+		KeySeq{"F10", ""}:                 KeyFunDebugNext,
+		KeySeq{"F11", ""}:                 KeyFunDebugStep,
+		KeySeq{"Shift+F11", ""}:           KeyFunDebugStepOut,
+		KeySeq{"Control+F10", ""}:         KeyFunDebugRunToCursor,
+		KeySeq{"Shift+F9", ""}:            KeyFunDebugEvalAtCursor,
+		KeySeq{"Control+F9", ""}:          KeyFunDebugEvalSelection,
+		KeySeq{"Control+M", "3"}:          KeyFunPaneSplitH,
+		KeySeq{"Control+M", "2"}:          KeyFunPaneSplitV,
+		KeySeq{"Control+M", "0"}:          KeyFunPaneClose,
+		KeySeq{"Control+M", "1"}:          KeyFunPaneUnsplit,
+		KeySeq{"Control+M", "4"}:          KeyFunPaneSwap,
+		KeySeq{"Control+M", "LeftArrow"}:  KeyFunPaneFocusLeft,
+		KeySeq{"Control+M", "RightArrow"}: KeyFunPaneFocusRight,
+		KeySeq{"Control+M", "UpArrow"}:    KeyFunPaneFocusUp,
+		KeySeq{"Control+M", "DownArrow"}:  KeyFunPaneFocusDown,
+		KeySeq{"Control+F5", ""}:          KeyFunDebugReverseContinue,
+		KeySeq{"Shift+F10", ""}:           KeyFunDebugReverseNext,
+		KeySeq{"Control+F11", ""}:         KeyFunDebugReverseStep,
+		KeySeq{"Control+M", "Control+G"}:  KeyFunJumpToMatch,
+		KeySeq{"Control+M", "Control+L"}:  KeyFunFormatBuffer,
+		KeySeq{"Control+M", "Control+Q"}:  KeyFunReflowComment,
+		KeySeq{"Control+M", "Control+U"}:  KeyFunSpellMenu,
+		KeySeq{"Shift+Control+Y", ""}:     KeyFunYank,
+		KeySeq{"Shift+Control+V", ""}:     KeyFunYankPop,
+		KeySeq{"Control+M", "/"}:          KeyFunCommentToggle,
+		KeySeq{"Control+M", "Control+H"}:  KeyFunWhitespaceToggle,
+		KeySeq{"Control+M", "^"}:          KeyFunSortLines,
+		KeySeq{"Control+M", "="}:          KeyFunUniqueLines,
+		KeySeq{"Control+M", "Control+A"}:  KeyFunJoinLines,
+		KeySeq{"Control+M", "@"}:          KeyFunSymbolJump,
+		KeySeq{"Control+M", "L"}:          KeyFunSetLayout,
+		KeySeq{"Control+M", "N"}:          KeyFunNextLayout,
+		KeySeq{"Control+M", "T"}:          KeyFunSymbolSearch,
+		KeySeq{"Control+M", "B"}:          KeyFunBufAltToggle,
+		KeySeq{"Control+M", "O"}:          KeyFunPanelAltToggle,
+		KeySeq{"Control+M", "H"}:          KeyFunPanelsReveal,
+		KeySeq{"Control+M", "P"}:          KeyFunMarkdownPreview,
+		KeySeq{"Control+M", "D"}:          KeyFunDuplicateLines,
+		KeySeq{"Control+M", "}"}:          KeyFunNextFunc,
+		KeySeq{"Control+M", "{"}:          KeyFunPrevFunc,
+		KeySeq{"Control+M", "A"}:          KeyFunBeginDefun,
+		KeySeq{"Control+M", "E"}:          KeyFunEndDefun,
+		KeySeq{"Control+M", "+"}:          KeyFunExpandSel,
+		KeySeq{"Control+M", "_"}:          KeyFunShrinkSel,
+		KeySeq{"Control+M", "V"}:          KeyFunClipHistory,
+		KeySeq{"Control+PageDown", ""}:    KeyFunNextTab,
+		KeySeq{"Control+PageUp", ""}:      KeyFunPrevTab,
+		KeySeq{"Control+M", "Q"}:          KeyFunCloseOtherTabs,
+		KeySeq{"Control+M", "I"}:          KeyFunISearch,
+		KeySeq{"F4", ""}:                  KeyFunNextFind,
+		KeySeq{"Shift+F4", ""}:            KeyFunPrevFind,
 	}},
 }
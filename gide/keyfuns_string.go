@@ -9,9 +9,9 @@ import (
 
 var _ = errors.New("dummy error")
 
-const _KeyFuns_name = "KeyFunNilKeyFunNeeds2KeyFunNextPanelKeyFunPrevPanelKeyFunFileOpenKeyFunBufSelectKeyFunBufCloneKeyFunBufSaveKeyFunBufSaveAsKeyFunBufCloseKeyFunExecCmdKeyFunRegCopyKeyFunRegPasteKeyFunCommentOutKeyFunIndentKeyFunJumpKeyFunSetSplitKeyFunBuildProjKeyFunRunProjKeyFunsN"
+const _KeyFuns_name = "KeyFunNilKeyFunNeeds2KeyFunNextPanelKeyFunPrevPanelKeyFunFileOpenKeyFunBufSelectKeyFunBufCloneKeyFunBufSaveKeyFunBufSaveAsKeyFunBufCloseKeyFunExecCmdKeyFunRegCopyKeyFunRegPasteKeyFunCommentOutKeyFunIndentKeyFunJumpKeyFunSetSplitKeyFunBuildProjKeyFunRunProjKeyFunGotoDefKeyFunNavBackKeyFunNavForwardKeyFunFindRefsKeyFunShowDocKeyFunPeekDefKeyFunRenameKeyFunFoldToggleKeyFunFoldAllKeyFunUnfoldAllKeyFunNextErrorKeyFunPrevErrorKeyFunAddCursorAboveKeyFunAddCursorBelowKeyFunAddCursorNextMatchKeyFunAddCursorsToLinesKeyFunRectSelectKeyFunRectCopyKeyFunRectKillKeyFunRectYankKeyFunDebugContinueKeyFunDebugStopKeyFunDebugToggleBreakKeyFunDebugNextKeyFunDebugStepKeyFunDebugStepOutKeyFunDebugRunToCursorKeyFunDebugEvalAtCursorKeyFunDebugEvalSelectionKeyFunPaneSplitHKeyFunPaneSplitVKeyFunPaneCloseKeyFunPaneUnsplitKeyFunPaneSwapKeyFunPaneFocusLeftKeyFunPaneFocusRightKeyFunPaneFocusUpKeyFunPaneFocusDownKeyFunDebugReverseContinueKeyFunDebugReverseNextKeyFunDebugReverseStepKeyFunJumpToMatchKeyFunFormatBufferKeyFunReflowCommentKeyFunSpellMenuKeyFunYankKeyFunYankPopKeyFunCommentToggleKeyFunWhitespaceToggleKeyFunSortLinesKeyFunSortLinesDescKeyFunSortLinesNumKeyFunUniqueLinesKeyFunReverseLinesKeyFunJoinLinesKeyFunSymbolJumpKeyFunSetLayoutKeyFunNextLayoutKeyFunSymbolSearchKeyFunBufAltToggleKeyFunPanelAltToggleKeyFunPanelsRevealKeyFunMarkdownPreviewKeyFunDuplicateLinesKeyFunNextFuncKeyFunPrevFuncKeyFunBeginDefunKeyFunEndDefunKeyFunExpandSelKeyFunShrinkSelKeyFunClipHistoryKeyFunNextTabKeyFunPrevTabKeyFunCloseOtherTabsKeyFunISearchKeyFunNextFindKeyFunPrevFindKeyFunsN"
 
-var _KeyFuns_index = [...]uint16{0, 9, 21, 36, 51, 65, 80, 94, 107, 122, 136, 149, 162, 176, 192, 204, 214, 228, 243, 256, 264}
+var _KeyFuns_index = [...]uint16{0, 9, 21, 36, 51, 65, 80, 94, 107, 122, 136, 149, 162, 176, 192, 204, 214, 228, 243, 256, 269, 282, 298, 312, 325, 338, 350, 366, 379, 394, 409, 424, 444, 464, 488, 511, 527, 541, 555, 569, 588, 603, 625, 640, 655, 673, 695, 718, 742, 758, 774, 789, 806, 820, 839, 859, 876, 895, 921, 943, 965, 982, 1000, 1019, 1034, 1044, 1057, 1076, 1098, 1113, 1132, 1150, 1167, 1185, 1200, 1216, 1231, 1247, 1265, 1283, 1303, 1321, 1342, 1362, 1376, 1390, 1406, 1420, 1435, 1450, 1467, 1480, 1493, 1513, 1526, 1540, 1554, 1562}
 
 func (i KeyFuns) String() string {
 	if i < 0 || i >= KeyFuns(len(_KeyFuns_index)-1) {
@@ -0,0 +1,210 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+)
+
+// KillRing is the ring of text killed (cut, killed to the end of the line,
+// or copied) in emacs style, newest first, for yanking back with Yank and
+// cycling through earlier kills with YankPop
+type KillRing struct {
+	Kills []string `desc:"the kills, newest first"`
+	Max   int      `desc:"maximum number of kills kept"`
+}
+
+// TheKillRing is the kill ring shared by all gide windows
+var TheKillRing = KillRing{Max: 60}
+
+// Kill adds given killed text at the head of the ring -- if appnd is true
+// it is appended to the head instead, as successive kills are
+func (kr *KillRing) Kill(txt string, appnd bool) {
+	if txt == "" {
+		return
+	}
+	if appnd && len(kr.Kills) > 0 {
+		kr.Kills[0] += txt
+		return
+	}
+	kr.Kills = append([]string{txt}, kr.Kills...)
+	if kr.Max > 0 && len(kr.Kills) > kr.Max {
+		kr.Kills = kr.Kills[:kr.Max]
+	}
+}
+
+// Len returns the number of kills in the ring
+func (kr *KillRing) Len() int {
+	return len(kr.Kills)
+}
+
+// At returns the kill at given index in the ring, wrapping around
+func (kr *KillRing) At(idx int) string {
+	n := len(kr.Kills)
+	if n == 0 {
+		return ""
+	}
+	return kr.Kills[((idx%n)+n)%n]
+}
+
+// ToHead moves the kill at given index to the head of the ring
+func (kr *KillRing) ToHead(idx int) {
+	if idx <= 0 || idx >= len(kr.Kills) {
+		return
+	}
+	txt := kr.Kills[idx]
+	copy(kr.Kills[1:idx+1], kr.Kills[:idx])
+	kr.Kills[0] = txt
+}
+
+// KillLineText returns the text that killing to the end of the line at
+// given position deletes: the rest of the line, or the line break if it is
+// at the end of the line
+func KillLineText(lines [][]rune, pos giv.TextPos) string {
+	if pos.Ln >= len(lines) {
+		return ""
+	}
+	ln := lines[pos.Ln]
+	switch {
+	case pos.Ch < len(ln):
+		return string(ln[pos.Ch:])
+	case pos.Ln < len(lines)-1:
+		return "\n"
+	}
+	return ""
+}
+
+// KillSummary returns a one-line summary of given kill, for the kill ring
+// menu
+func KillSummary(txt string, max int) string {
+	nl := strings.Count(strings.TrimRight(txt, "\n"), "\n")
+	s := strings.TrimSpace(txt)
+	if i := strings.Index(s, "\n"); i >= 0 {
+		s = s[:i]
+	}
+	if r := []rune(s); len(r) > max {
+		s = string(r[:max]) + "..."
+	}
+	if nl > 0 {
+		s += fmt.Sprintf("  (%d lines)", nl+1)
+	}
+	return s
+}
+
+// killState is where the last kill was made, so a kill right after it is
+// appended to it
+type killState struct {
+	Buf *giv.TextBuf
+	Pos giv.TextPos
+}
+
+// yankState is the text just yanked, which YankPop replaces
+type yankState struct {
+	Buf    *giv.TextBuf
+	St, Ed giv.TextPos
+	Idx    int
+}
+
+// KillRingKey records the text that given key function of the active view
+// kills, if any, in the kill ring, before the view does it -- successive
+// kills are appended together -- and forgets the last kill and yank for
+// any other key, except Yank and YankPop
+func (ge *Gide) KillRingKey(kf KeyFuns, gkf gi.KeyFuns) {
+	if kf != KeyFunYank && kf != KeyFunYankPop {
+		ge.yankLast = nil
+	}
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || !tv.HasFocus() {
+		ge.killLast = nil
+		return
+	}
+	cp := tv.CursorPos
+	appnd := ge.killLast != nil && ge.killLast.Buf == tv.Buf && ge.killLast.Pos == cp
+	switch gkf {
+	case gi.KeyFunKill:
+		if tv.IsInactive() {
+			break
+		}
+		TheKillRing.Kill(KillLineText(tv.Buf.Lines, cp), appnd)
+		ge.killLast = &killState{Buf: tv.Buf, Pos: cp}
+		return
+	case gi.KeyFunCut, gi.KeyFunCopy:
+		if sel := tv.Selection(); sel != nil {
+			TheKillRing.Kill(string(sel.ToBytes()), appnd && gkf == gi.KeyFunCut)
+			ge.killLast = &killState{Buf: tv.Buf, Pos: sel.Reg.Start}
+			return
+		}
+	}
+	ge.killLast = nil
+}
+
+// Yank inserts the last kill at the cursor in the active view -- returns
+// false if the kill ring is empty
+func (ge *Gide) Yank() bool {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.IsInactive() || TheKillRing.Len() == 0 {
+		return false
+	}
+	ge.yankAt(tv, tv.CursorPos, 0)
+	return true
+}
+
+// yankAt inserts the kill at given index in the kill ring at given position
+// in given view, as the text just yanked
+func (ge *Gide) yankAt(tv *giv.TextView, st giv.TextPos, idx int) {
+	tbe := tv.Buf.InsertText(st, []byte(TheKillRing.At(idx)), true, true)
+	if tbe == nil {
+		return
+	}
+	ge.yankLast = &yankState{Buf: tv.Buf, St: st, Ed: tbe.Reg.End, Idx: idx}
+	tv.SetCursorShow(tbe.Reg.End)
+}
+
+// YankPop replaces the text just yanked in the active view with the kill
+// before it in the kill ring, cycling around -- if the last command was not
+// a yank, it shows the kill ring to pick one from instead
+func (ge *Gide) YankPop() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.IsInactive() {
+		return
+	}
+	yl := ge.yankLast
+	if yl == nil || yl.Buf != tv.Buf || tv.CursorPos != yl.Ed || TheKillRing.Len() < 2 {
+		ge.KillRingMenu()
+		return
+	}
+	tv.Buf.DeleteText(yl.St, yl.Ed, true, true)
+	ge.yankAt(tv, yl.St, yl.Idx+1)
+	ge.SetStatus(fmt.Sprintf("Yanked kill %d of %d", (yl.Idx+1)%TheKillRing.Len()+1, TheKillRing.Len()))
+}
+
+// KillRingMenu pops up a menu at the cursor in the active view with the
+// kills in the kill ring, newest first, to insert one at the cursor -- it
+// then moves to the head of the ring
+func (ge *Gide) KillRingMenu() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.IsInactive() {
+		return
+	}
+	var m gi.Menu
+	if TheKillRing.Len() == 0 {
+		m.AddAction(gi.ActOpts{Label: "(kill ring is empty)"}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {})
+	}
+	for i, txt := range TheKillRing.Kills {
+		i := i
+		m.AddAction(gi.ActOpts{Label: KillSummary(txt, 60), Tooltip: txt}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_Gide).(*Gide)
+			TheKillRing.ToHead(i)
+			gee.yankAt(tv, tv.CursorPos, 0)
+		})
+	}
+	cpos := tv.CharStartPos(tv.CursorPos).ToPoint()
+	gi.PopupMenu(m, cpos.X, cpos.Y+int(tv.LineHeight), tv.Viewport, "kill-ring-menu")
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goki/gi/giv"
+)
+
+func TestKillRing(t *testing.T) {
+	kr := KillRing{Max: 3}
+	kr.Kill("a", false)
+	kr.Kill("b", true) // appended to a
+	kr.Kill("c", false)
+	kr.Kill("", false)
+	want := []string{"c", "ab"}
+	if !reflect.DeepEqual(kr.Kills, want) {
+		t.Errorf("kills: %v, want %v", kr.Kills, want)
+	}
+	kr.Kill("d", false)
+	kr.Kill("e", false)
+	want = []string{"e", "d", "c"}
+	if !reflect.DeepEqual(kr.Kills, want) {
+		t.Errorf("max: %v, want %v", kr.Kills, want)
+	}
+	if kr.At(1) != "d" || kr.At(4) != "d" || kr.At(-1) != "c" {
+		t.Errorf("at: %q %q %q, want d d c", kr.At(1), kr.At(4), kr.At(-1))
+	}
+	kr.ToHead(2)
+	want = []string{"c", "e", "d"}
+	if !reflect.DeepEqual(kr.Kills, want) {
+		t.Errorf("to head: %v, want %v", kr.Kills, want)
+	}
+	var empty KillRing
+	if empty.At(3) != "" {
+		t.Errorf("empty at: %q", empty.At(3))
+	}
+}
+
+func TestKillLineText(t *testing.T) {
+	lines := [][]rune{[]rune("hello world"), []rune(""), []rune("end")}
+	tests := []struct {
+		pos  giv.TextPos
+		want string
+	}{
+		{giv.TextPos{Ln: 0, Ch: 6}, "world"},
+		{giv.TextPos{Ln: 0, Ch: 11}, "\n"},
+		{giv.TextPos{Ln: 1, Ch: 0}, "\n"},
+		{giv.TextPos{Ln: 2, Ch: 3}, ""},
+		{giv.TextPos{Ln: 5, Ch: 0}, ""},
+	}
+	for _, tt := range tests {
+		if got := KillLineText(lines, tt.pos); got != tt.want {
+			t.Errorf("%v: %q, want %q", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestKillSummary(t *testing.T) {
+	tests := []struct {
+		txt  string
+		max  int
+		want string
+	}{
+		{"short", 60, "short"},
+		{"  first line\nsecond\n", 60, "first line  (2 lines)"},
+		{"abcdefghij", 5, "abcde..."},
+	}
+	for _, tt := range tests {
+		if got := KillSummary(tt.txt, tt.max); got != tt.want {
+			t.Errorf("%q: %q, want %q", tt.txt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki"
+)
+
+// KioskConfig is the configuration of kiosk mode, for classrooms and exams:
+// the preferences are locked, the commands that destroy work or leave the
+// project are hidden, and each session starts from a fresh copy of a
+// project template, without any of the state of other sessions
+type KioskConfig struct {
+	Template  string   `desc:"folder with the project that each session starts from, e.g., the exercises of a class or an exam -- it is copied into a new folder in WorkDir for each session -- an empty project if not set"`
+	WorkDir   string   `desc:"folder in which the project folders of the sessions are made -- the temporary folder if empty"`
+	PrefsFile string   `desc:"preferences file, as saved by gide (gide_prefs.json), with the preferences to use instead of the user's -- the standard preferences if empty"`
+	Hide      []string `desc:"names of more commands to hide, in addition to KioskHidden, e.g., Build or Run"`
+	Keep      bool     `desc:"keep the project folders of the sessions after they end, e.g., to collect the answers of an exam -- otherwise they are removed when gide exits"`
+	session   string
+}
+
+// Kiosk is the kiosk mode configuration -- nil if not in kiosk mode
+var Kiosk *KioskConfig
+
+// KioskFileName is the name of the file in the prefs directory with the
+// kiosk mode configuration -- gide is in kiosk mode if it exists, or if
+// another file is given by the -kiosk flag
+var KioskFileName = "gide_kiosk.json"
+
+// KioskHidden are the names of the commands, in the menus and toolbar, that
+// are hidden in kiosk mode: those that leave the project, lose work, change
+// the preferences, or use the network or the shell
+var KioskHidden = []string{"OpenRecent", "ShowProjWindow", "SetProjGroup", "FilterProjGroup", "OpenProj", "OpenPath", "OpenFile", "PromoteToProj", "CloneRepo", "NewProj", "SaveProjAs", "SaveActiveViewAs", "RevertActiveView", "ReopenWithEncoding", "ProjPrefs", "ToggleOffline", "Commit", "ExecCmdNameActive", "DiffActiveVCS", "SplitsSaveAs", "SplitsSave", "SplitsEdit", "HelpWiki"}
+
+// KioskKeyFuns are the key functions that do nothing in kiosk mode, as
+// their commands are hidden
+var KioskKeyFuns = map[KeyFuns]bool{
+	KeyFunFileOpen:  true,
+	KeyFunBufSaveAs: true,
+	KeyFunExecCmd:   true,
+}
+
+// OpenKiosk opens the kiosk mode configuration in given JSON file
+func OpenKiosk(fname string) (*KioskConfig, error) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	kc := &KioskConfig{}
+	if err := json.Unmarshal(b, kc); err != nil {
+		return nil, fmt.Errorf("kiosk configuration %v: %v", fname, err)
+	}
+	return kc, nil
+}
+
+// InitKiosk turns on kiosk mode with the configuration in given file, or,
+// if it is empty, in the KioskFileName file in the prefs directory, if
+// there is one -- hiding the commands in KioskHidden and those in its Hide
+// -- must be called at startup, before InitPrefs
+func InitKiosk(fname string) error {
+	if fname == "" {
+		fname = filepath.Join(oswin.TheApp.AppPrefsDir(), KioskFileName)
+		if _, err := os.Stat(fname); err != nil {
+			return nil
+		}
+	}
+	kc, err := OpenKiosk(fname)
+	if err != nil {
+		return err
+	}
+	Kiosk = kc
+	hide := make(map[string]bool)
+	for _, nm := range append(KioskHidden, kc.Hide...) {
+		hide[nm] = true
+	}
+	for _, k := range []string{"ToolBar", "MainMenu"} {
+		if ps, ok := GideProps[k].(ki.PropSlice); ok {
+			GideProps[k] = KioskPrune(ps, hide)
+		}
+	}
+	return nil
+}
+
+// KioskPrune returns given menu or toolbar properties without the items
+// with given names, in sub-menus as well
+func KioskPrune(ps ki.PropSlice, hide map[string]bool) ki.PropSlice {
+	out := make(ki.PropSlice, 0, len(ps))
+	for _, p := range ps {
+		if hide[p.Name] {
+			continue
+		}
+		if sub, ok := p.Value.(ki.PropSlice); ok {
+			p.Value = KioskPrune(sub, hide)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// KioskPrefs sets the preferences for kiosk mode: the standard ones, or
+// those in the PrefsFile of the configuration -- they are not saved
+func KioskPrefs() error {
+	Prefs.Defaults()
+	var err error
+	if Kiosk.PrefsFile != "" {
+		var b []byte
+		if b, err = ioutil.ReadFile(Kiosk.PrefsFile); err == nil {
+			err = json.Unmarshal(b, &Prefs)
+		}
+	}
+	Prefs.Editor.UndoHistory = 0 // nothing kept across sessions
+	Prefs.Apply()
+	Prefs.Changed = false
+	return err
+}
+
+// KioskSession makes the project folder for a new kiosk mode session, in
+// the WorkDir of the configuration, with a copy of its Template, and
+// returns its path
+func KioskSession() (string, error) {
+	wd := Kiosk.WorkDir
+	if wd == "" {
+		wd = os.TempDir()
+	}
+	if err := os.MkdirAll(wd, 0755); err != nil {
+		return "", err
+	}
+	name := "gide-session"
+	if Kiosk.Template != "" {
+		name = filepath.Base(filepath.Clean(Kiosk.Template))
+	}
+	dir, err := ioutil.TempDir(wd, name+"-"+time.Now().Format("20060102-150405")+"-")
+	if err != nil {
+		return "", err
+	}
+	Kiosk.session = dir
+	if Kiosk.Template == "" {
+		return dir, nil
+	}
+	if err := copyTree(Kiosk.Template, dir); err != nil {
+		return dir, err
+	}
+	pfs, _ := filepath.Glob(filepath.Join(dir, "*.gide"))
+	for _, pf := range pfs { // point project files at the copy
+		pp := &ProjPrefs{}
+		if pp.OpenJSON(gi.FileName(pf)) == nil {
+			pp.ProjFilename = gi.FileName(pf)
+			pp.ProjRoot = gi.FileName(dir)
+			pp.SaveJSON(pp.ProjFilename)
+		}
+	}
+	return dir, nil
+}
+
+// KioskEnd ends the kiosk mode session, removing its project folder unless
+// the configuration says to keep it -- called when gide exits
+func KioskEnd() {
+	if Kiosk == nil || Kiosk.session == "" || Kiosk.Keep {
+		return
+	}
+	os.RemoveAll(Kiosk.session)
+}
+
+// copyTree copies the files and folders in folder src into folder dst,
+// keeping their modes
+func copyTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		to := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(to, info.Mode().Perm()|0700)
+		case !info.Mode().IsRegular():
+			return nil // no links, devices etc
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm()|0600)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
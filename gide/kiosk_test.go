@@ -0,0 +1,61 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/ki"
+)
+
+func TestKioskPrune(t *testing.T) {
+	ps := ki.PropSlice{
+		{"OpenRecent", ki.Props{}},
+		{"Save", ki.Props{}},
+		{"File", ki.PropSlice{
+			{"OpenPath", ki.Props{}},
+			{"SaveActiveView", ki.Props{}},
+			{"sep-file", ki.BlankProp{}},
+		}},
+	}
+	got := KioskPrune(ps, map[string]bool{"OpenRecent": true, "OpenPath": true})
+	if len(got) != 2 || got[0].Name != "Save" || got[1].Name != "File" {
+		t.Fatalf("got %v", got)
+	}
+	sub := got[1].Value.(ki.PropSlice)
+	if len(sub) != 2 || sub[0].Name != "SaveActiveView" {
+		t.Errorf("sub-menu got %v", sub)
+	}
+	if len(ps[2].Value.(ki.PropSlice)) != 3 {
+		t.Errorf("original changed")
+	}
+}
+
+func TestKioskSession(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-kiosk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tmpl := filepath.Join(dir, "exercises")
+	os.MkdirAll(filepath.Join(tmpl, "ex1"), 0755)
+	ioutil.WriteFile(filepath.Join(tmpl, "ex1", "main.go"), []byte("package main\n"), 0644)
+	Kiosk = &KioskConfig{Template: tmpl, WorkDir: filepath.Join(dir, "work")}
+	defer func() { Kiosk = nil }()
+	sess, err := KioskSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(sess, "ex1", "main.go")); err != nil || string(b) != "package main\n" {
+		t.Errorf("copy got %q, %v", b, err)
+	}
+	KioskEnd()
+	if _, err := os.Stat(sess); !os.IsNotExist(err) {
+		t.Errorf("session folder not removed: %v", err)
+	}
+}
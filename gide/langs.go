@@ -25,11 +25,21 @@ import (
 // Lang defines properties associated with a given language or file type more
 // generally (e.g., image files, data files, etc)
 type Lang struct {
-	Name         string   `desc:"name of this language / data / file type (must be unique)"`
-	Desc         string   `desc:"<i>brief</i> description of it"`
-	Exts         []string `desc:"associated lower-case file extensions -- if the filename itself is more diagnostic (e.g., Makefile), specify that -- if it doesn't start with a . then it will be treated as the start of the filename"`
-	PostSaveCmds CmdNames `desc:"command(s) to run after a file of this type is saved"`
-	Comment      string   `desc:"string used for commenting-out individual lines"`
+	Name         string    `desc:"name of this language / data / file type (must be unique)"`
+	Desc         string    `desc:"<i>brief</i> description of it"`
+	Exts         []string  `desc:"associated lower-case file extensions -- if the filename itself is more diagnostic (e.g., Makefile), specify that -- if it doesn't start with a . then it will be treated as the start of the filename"`
+	PostSaveCmds CmdNames  `desc:"command(s) to run after a file of this type is saved"`
+	Comment      string    `desc:"string used for commenting-out individual lines"`
+	CommentSt    string    `desc:"string that starts a block comment, e.g., /* -- used by Toggle Comment for languages without line comments"`
+	CommentEd    string    `desc:"string that ends a block comment, e.g., */"`
+	AutoPairs    string    `desc:"pairs of opening and closing delimiters that are automatically closed when typed, e.g., ()[]{}\"\" -- brackets among these are also highlighted and matched"`
+	TabSize      int       `desc:"size of an indent level for this language, in spaces -- 0 to use the editor preferences for this and SpaceIndent"`
+	SpaceIndent  bool      `desc:"use spaces for indentation, otherwise tabs -- only used if TabSize is set"`
+	ColonBlocks  bool      `desc:"blocks are started by a line ending in a colon, as in Python, rather than by brackets -- for automatic indentation"`
+	Rulers       []int     `desc:"columns at which vertical rulers are drawn for this language, e.g., 79 for Python -- empty to use those of the editor preferences"`
+	Whitespace   WsShow    `desc:"whether indent guides and whitespace are shown in files of this language -- Default to use the IndentGuides and ShowWhitespace editor preferences"`
+	Formatter    string    `desc:"command that formats a file of this language, reading it on stdin and writing the formatted text to stdout, e.g., clang-format -- use {FilePath} etc for the file -- run by Format Buffer, and before saving if FormatOnSave is set in the editor preferences"`
+	OnSave       SaveHooks `desc:"cleanups done to files of this language before they are saved, if Set -- otherwise those of the editor preferences (FormatOnSave, TrimOnSave and FinalNLOnSave) are done"`
 }
 
 // Label satisfies the Labeler interface
@@ -345,12 +355,14 @@ var LangsProps = ki.Props{
 
 // StdLangs is the original compiled-in set of standard languages.
 var StdLangs = Langs{
-	{"C", "C code", []string{".c", ".h"}, nil, "// "},
-	{"C++", "C++ code", []string{".cpp", ".cxx", ".cc", ".h", ".hh", ".hpp"}, nil, "// "},
-	{"Go", "Go code", []string{".go"}, CmdNames{"Imports Go File"}, "// "},
-	{"HTML", "HTML document", []string{".html", ".htm"}, nil, "<-- "},
-	{"LaTeX", "LaTeX document", []string{".tex"}, CmdNames{"LaTeX PDF"}, "% "},
-	{"Markdown", "Markdown document", []string{".md"}, nil, "<--- "},
-	{"PDF", "PDF document", []string{".pdf"}, CmdNames{"Open File"}, ""},
-	{"Python", "Python code", []string{".py"}, nil, "# "},
+	{"C", "C code", []string{".c", ".h"}, nil, "// ", "/* ", " */", "()[]{}\"\"''", 0, false, false, nil, WsShowDefault, "clang-format --assume-filename={FilePath}", SaveHooks{}},
+	{"C++", "C++ code", []string{".cpp", ".cxx", ".cc", ".h", ".hh", ".hpp"}, nil, "// ", "/* ", " */", "()[]{}\"\"''", 0, false, false, nil, WsShowDefault, "clang-format --assume-filename={FilePath}", SaveHooks{}},
+	{"Go", "Go code", []string{".go"}, nil, "// ", "/* ", " */", "()[]{}\"\"''``", 0, false, false, nil, WsShowDefault, "goimports -srcdir {FileDirPath}", SaveHooks{}},
+	{"Go Asm", "Go assembly", []string{".s"}, CmdNames{"Vet Go Asm"}, "// ", "/* ", " */", "()", 0, false, false, nil, WsShowDefault, "", SaveHooks{}},
+	{"HTML", "HTML document", []string{".html", ".htm"}, nil, "", "<!-- ", " -->", "\"\"''", 0, false, false, nil, WsShowDefault, "prettier --stdin-filepath {FilePath}", SaveHooks{}},
+	{"LaTeX", "LaTeX document", []string{".tex"}, CmdNames{"LaTeX PDF"}, "% ", "", "", "()[]{}$$", 0, false, false, nil, WsShowDefault, "", SaveHooks{}},
+	{"Markdown", "Markdown document", []string{".md"}, nil, "", "<!-- ", " -->", "()[]``", 0, false, false, nil, WsShowDefault, "prettier --stdin-filepath {FilePath}", SaveHooks{}},
+	{"PDF", "PDF document", []string{".pdf"}, CmdNames{"Open File"}, "", "", "", "", 0, false, false, nil, WsShowDefault, "", SaveHooks{}},
+	{"Python", "Python code", []string{".py"}, nil, "# ", "", "", "()[]{}\"\"''", 4, true, true, []int{79}, WsShowGuides, "black -q -", SaveHooks{}},
+	{"YAML", "YAML data", []string{".yaml", ".yml"}, nil, "# ", "", "", "()[]{}\"\"''", 2, true, false, nil, WsShowGuides, "", SaveHooks{}},
 }
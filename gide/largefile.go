@@ -0,0 +1,544 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// LargeFileWindow is the number of lines of a large file that are loaded
+// and shown at a time in large file mode
+var LargeFileWindow = 5000
+
+// LargeFileMaxLine is the maximum length of a line of a large file that is
+// loaded or searched -- longer lines are cut off
+var LargeFileMaxLine = 64 * 1024
+
+// LargeFileMaxFinds is the maximum number of matches listed by a search in
+// large file mode
+var LargeFileMaxFinds = 1000
+
+// LargeFileIndexStep is the number of lines between the offsets recorded in
+// the index of a LargeFile
+const LargeFileIndexStep = 1000
+
+// LargeFile is a file that is too large to load all of it into a buffer
+// -- it is read a window of lines at a time, using an index of the offsets
+// of its lines, and searched by streaming through it
+type LargeFile struct {
+	Path  string  `desc:"path to the file"`
+	Size  int64   `desc:"size of the file, in bytes, when it was indexed"`
+	Lines int     `desc:"number of lines in the file"`
+	Offs  []int64 `desc:"offset of every LargeFileIndexStep'th line"`
+}
+
+// OpenLargeFile indexes the lines of given file, reading through it in
+// chunks, for a LargeFile to read windows of them from
+func OpenLargeFile(path string) (*LargeFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	lf := &LargeFile{Path: path}
+	if err := lf.index(f); err != nil {
+		return nil, err
+	}
+	return lf, nil
+}
+
+// index records the line offsets of given reader
+func (lf *LargeFile) index(r io.Reader) error {
+	lf.Offs = []int64{0}
+	lf.Lines = 0
+	var off int64
+	buf := make([]byte, 1024*1024)
+	atStart := true
+	for {
+		n, err := r.Read(buf)
+		for _, c := range buf[:n] {
+			off++
+			if atStart {
+				lf.Lines++
+				atStart = false
+			}
+			if c == '\n' {
+				atStart = true
+				if lf.Lines%LargeFileIndexStep == 0 {
+					lf.Offs = append(lf.Offs, off)
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	lf.Size = off
+	return nil
+}
+
+// lineReader returns a reader of given file positioned at the start of
+// given line, 0-based
+func (lf *LargeFile) lineReader(f io.ReadSeeker, ln int) (*bufio.Reader, error) {
+	oi := ln / LargeFileIndexStep
+	if oi >= len(lf.Offs) {
+		oi = len(lf.Offs) - 1
+	}
+	if _, err := f.Seek(lf.Offs[oi], io.SeekStart); err != nil {
+		return nil, err
+	}
+	br := bufio.NewReaderSize(f, 1024*1024)
+	for i := oi * LargeFileIndexStep; i < ln; i++ {
+		if _, err := readLine(br); err != nil {
+			return br, err
+		}
+	}
+	return br, nil
+}
+
+// readLine reads the next line from given reader, without its line ending,
+// cut off at LargeFileMaxLine -- returns io.EOF if there are no more lines
+func readLine(br *bufio.Reader) ([]byte, error) {
+	var ln []byte
+	for {
+		sl, err := br.ReadSlice('\n')
+		if len(ln) < LargeFileMaxLine {
+			ln = append(ln, sl...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil && (err != io.EOF || len(ln) == 0) {
+			return nil, err
+		}
+		break
+	}
+	ln = bytes.TrimSuffix(ln, []byte("\n"))
+	ln = bytes.TrimSuffix(ln, []byte("\r"))
+	if len(ln) > LargeFileMaxLine {
+		ln = ln[:LargeFileMaxLine]
+	}
+	return ln, nil
+}
+
+// ReadLines returns up to n lines of the file starting at line st, 0-based,
+// joined with LF
+func (lf *LargeFile) ReadLines(st, n int) ([]byte, error) {
+	f, err := os.Open(lf.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	br, err := lf.lineReader(f, st)
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	lns := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		ln, err := readLine(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		lns = append(lns, ln)
+	}
+	return bytes.Join(lns, []byte("\n")), nil
+}
+
+// LargeFileMatch is a match of a search of a LargeFile
+type LargeFileMatch struct {
+	Ln   int    `desc:"line number, 0-based"`
+	Ch   int    `desc:"rune position of the match within the line, 0-based"`
+	Text string `desc:"text of the line"`
+}
+
+// Search streams through the file for the lines with given text in them,
+// returning the first match in each of up to max lines -- it stops if it is
+// cancelled by cancel returning true, which is checked regularly
+func (lf *LargeFile) Search(find string, ignoreCase bool, max int, cancel func() bool) ([]LargeFileMatch, error) {
+	f, err := os.Open(lf.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fb := []byte(find)
+	if ignoreCase {
+		fb = bytes.ToLower(fb)
+	}
+	var ms []LargeFileMatch
+	br := bufio.NewReaderSize(f, 1024*1024)
+	for ln := 0; len(ms) < max; ln++ {
+		if cancel != nil && ln%LargeFileIndexStep == 0 && cancel() {
+			break
+		}
+		lb, err := readLine(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ms, err
+		}
+		sl := lb
+		if ignoreCase {
+			sl = bytes.ToLower(lb)
+		}
+		if i := bytes.Index(sl, fb); i >= 0 {
+			ms = append(ms, LargeFileMatch{Ln: ln, Ch: utf8.RuneCount(lb[:i]), Text: string(lb)})
+		}
+	}
+	return ms, nil
+}
+
+// LargeFileView is a widget that shows a large file in large file mode: a
+// window of its lines at a time, read-only, with no highlighting or undo,
+// with a toolbar to move through the file and search it, listing the
+// matches below with links to each one
+type LargeFileView struct {
+	gi.Layout
+	Gide  *Gide      `json:"-" xml:"-" desc:"parent gide project"`
+	File  *LargeFile `json:"-" xml:"-" desc:"the file, once it is indexed"`
+	Start int        `json:"-" xml:"-" desc:"first line of the window of lines shown, 0-based"`
+	FindN int        `json:"-" xml:"-" view:"-" desc:"number of the current search -- a search is cancelled when another one is started"`
+	Mu    sync.Mutex `json:"-" xml:"-" view:"-" desc:"mutex protecting the file and search -- indexing and searching are done in the background"`
+}
+
+var KiT_LargeFileView = kit.Types.AddType(&LargeFileView{}, LargeFileViewProps)
+
+// LargeFileTabName returns the name of the tab and buffers for large file
+// mode for given file
+func LargeFileTabName(path string) string {
+	return "Large: " + filepath.Base(path)
+}
+
+// Open indexes given file in the background, and then shows its first
+// window of lines
+func (lv *LargeFileView) Open(path string) {
+	ge := lv.Gide
+	ge.SetStatus("Indexing large file: " + path + "...")
+	go func() {
+		lf, err := OpenLargeFile(path)
+		if err != nil {
+			ge.SetStatus(fmt.Sprintf("Could not open large file: %v", err))
+			return
+		}
+		lv.Mu.Lock()
+		lv.File = lf
+		lv.Mu.Unlock()
+		lv.ShowWindow(0)
+		ge.SetStatus(fmt.Sprintf("Large file mode: %v -- %v lines, %v bytes", path, lf.Lines, lf.Size))
+	}()
+}
+
+// ShowWindow loads and shows the window of lines starting at given line,
+// 0-based
+func (lv *LargeFileView) ShowWindow(st int) {
+	lv.Mu.Lock()
+	lf := lv.File
+	lv.Mu.Unlock()
+	if lf == nil {
+		return
+	}
+	if st > lf.Lines-LargeFileWindow {
+		st = lf.Lines - LargeFileWindow
+	}
+	if st < 0 {
+		st = 0
+	}
+	txt, err := lf.ReadLines(st, LargeFileWindow)
+	if err != nil {
+		lv.Gide.SetStatus(fmt.Sprintf("Could not read large file: %v", err))
+		return
+	}
+	lv.Start = st
+	tbuf, _ := lv.Gide.FindOrMakeCmdBuf(LargeFileTabName(lf.Path), true)
+	tbuf.SetText(txt)
+	ed := st + LargeFileWindow
+	if ed > lf.Lines {
+		ed = lf.Lines
+	}
+	if lbl := lv.WindowLabel(); lbl != nil {
+		lbl.SetText(fmt.Sprintf("lines %d-%d of %d", st+1, ed, lf.Lines))
+	}
+}
+
+// ShowLine shows the window of lines with given line, 0-based, near its
+// top, and highlights it
+func (lv *LargeFileView) ShowLine(ln int) {
+	if ln < lv.Start || ln >= lv.Start+LargeFileWindow {
+		lv.ShowWindow(ln - LargeFileWindow/10)
+	}
+	tv := lv.TextView()
+	rln := ln - lv.Start
+	if rln < 0 || tv.Buf == nil || rln >= len(tv.Buf.Lines) {
+		return
+	}
+	reg := giv.TextRegion{Start: giv.TextPos{Ln: rln}, End: giv.TextPos{Ln: rln, Ch: len(tv.Buf.Lines[rln])}}
+	tv.HighlightRegion(reg)
+	tv.SetCursorShow(reg.Start)
+}
+
+// NextWindow shows the next window of lines
+func (lv *LargeFileView) NextWindow() {
+	lv.ShowWindow(lv.Start + LargeFileWindow)
+}
+
+// PrevWindow shows the previous window of lines
+func (lv *LargeFileView) PrevWindow() {
+	lv.ShowWindow(lv.Start - LargeFileWindow)
+}
+
+// Find searches the file for given text in the background, streaming
+// through it, and lists the matches in the find results, with links to
+// each one -- any search still running is cancelled
+func (lv *LargeFileView) Find(find string, ignoreCase bool) {
+	lv.Mu.Lock()
+	lf := lv.File
+	lv.FindN++
+	srch := lv.FindN
+	lv.Mu.Unlock()
+	if lf == nil || find == "" {
+		return
+	}
+	ge := lv.Gide
+	ge.SetStatus(fmt.Sprintf("Searching large file for: %v...", find))
+	go func() {
+		cancel := func() bool {
+			lv.Mu.Lock()
+			defer lv.Mu.Unlock()
+			return lv.FindN != srch
+		}
+		ms, err := lf.Search(find, ignoreCase, LargeFileMaxFinds, cancel)
+		if cancel() {
+			return
+		}
+		lv.ShowFinds(lf, find, ms)
+		switch {
+		case err != nil:
+			ge.SetStatus(fmt.Sprintf("Search of large file stopped: %v", err))
+		case len(ms) >= LargeFileMaxFinds:
+			ge.SetStatus(fmt.Sprintf("Found the first %d lines with: %v", len(ms), find))
+		default:
+			ge.SetStatus(fmt.Sprintf("Found %d lines with: %v", len(ms), find))
+		}
+	}()
+}
+
+// ShowFinds renders given matches into the find results buffer
+func (lv *LargeFileView) ShowFinds(lf *LargeFile, find string, ms []LargeFileMatch) {
+	fbuf, _ := lv.Gide.FindOrMakeCmdBuf(LargeFileTabName(lf.Path)+" Find", true)
+	outlns := make([][]byte, 0, len(ms)+1)
+	outmus := make([][]byte, 0, len(ms)+1) // markups
+	lstr := fmt.Sprintf("%d lines with: %v", len(ms), find)
+	outlns = append(outlns, []byte(lstr))
+	outmus = append(outmus, []byte(fmt.Sprintf(`<b>%v</b>`, html.EscapeString(lstr))))
+	for _, m := range ms {
+		txt := m.Text
+		if len(txt) > 200 {
+			txt = txt[:200]
+		}
+		lstr = fmt.Sprintf(`	%d:%d: %s`, m.Ln+1, m.Ch+1, txt)
+		outlns = append(outlns, []byte(lstr))
+		mstr := fmt.Sprintf(`	<a href="large:///%v#L%v">%d:%d</a>: %s`, lf.Path, m.Ln+1, m.Ln+1, m.Ch+1, html.EscapeString(txt))
+		outmus = append(outmus, []byte(mstr))
+	}
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	fbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+}
+
+// OpenLargeURL shows the line in given large:/// url from the find results
+func (lv *LargeFileView) OpenLargeURL(ur string, ttv *giv.TextView) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("LargeFileView OpenLargeURL parse err: %v\n", err)
+		return false
+	}
+	ln, err := strconv.Atoi(strings.TrimPrefix(up.Fragment, "L"))
+	if err != nil || ln < 1 {
+		return false
+	}
+	lv.ShowLine(ln - 1)
+	return true
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (lv *LargeFileView) UpdateView(ge *Gide) {
+	lv.Gide = ge
+	mods, updt := lv.StdLargeConfig()
+	lv.ConfigToolbar()
+	ge.ConfigOutputTextView(lv.TextViewLay())
+	ge.ConfigOutputTextView(lv.FindViewLay())
+	if mods {
+		lv.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (lv *LargeFileView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "largebar")
+	config.Add(gi.KiT_Layout, "largetext")
+	config.Add(gi.KiT_Layout, "largefind")
+	return config
+}
+
+// StdLargeConfig configures a standard setup of the overall layout -- returns
+// mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (lv *LargeFileView) StdLargeConfig() (mods, updt bool) {
+	lv.Lay = gi.LayoutVert
+	lv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := lv.StdConfig()
+	mods, updt = lv.ConfigChildren(config, false)
+	return
+}
+
+// LargeBar returns the large file toolbar
+func (lv *LargeFileView) LargeBar() *gi.ToolBar {
+	tbi, ok := lv.ChildByName("largebar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// WindowLabel returns the label in the toolbar with the lines shown
+func (lv *LargeFileView) WindowLabel() *gi.Label {
+	tb := lv.LargeBar()
+	if tb == nil {
+		return nil
+	}
+	lbi, ok := tb.ChildByName("window", 2)
+	if !ok {
+		return nil
+	}
+	return lbi.(*gi.Label)
+}
+
+// TextViewLay returns the layout of the TextView with the window of lines
+func (lv *LargeFileView) TextViewLay() *gi.Layout {
+	tvi, ok := lv.ChildByName("largetext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the TextView with the window of lines
+func (lv *LargeFileView) TextView() *giv.TextView {
+	tvly := lv.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// FindViewLay returns the layout of the TextView with the find results
+func (lv *LargeFileView) FindViewLay() *gi.Layout {
+	tvi, ok := lv.ChildByName("largefind", 2)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// FindView returns the TextView with the find results
+func (lv *LargeFileView) FindView() *giv.TextView {
+	tvly := lv.FindViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (lv *LargeFileView) ConfigToolbar() {
+	tb := lv.LargeBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	prev := tb.AddNewChild(gi.KiT_Action, "prev").(*gi.Action)
+	prev.SetIcon("widget-wedge-up")
+	prev.Tooltip = "show the previous window of lines"
+	prev.ActionSig.Connect(lv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		lvv, _ := recv.Embed(KiT_LargeFileView).(*LargeFileView)
+		lvv.PrevWindow()
+	})
+
+	next := tb.AddNewChild(gi.KiT_Action, "next").(*gi.Action)
+	next.SetIcon("widget-wedge-down")
+	next.Tooltip = "show the next window of lines"
+	next.ActionSig.Connect(lv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		lvv, _ := recv.Embed(KiT_LargeFileView).(*LargeFileView)
+		lvv.NextWindow()
+	})
+
+	tb.AddNewChild(gi.KiT_Label, "window").(*gi.Label).SetText("indexing...")
+
+	gl := tb.AddNewChild(gi.KiT_TextField, "goto").(*gi.TextField)
+	gl.SetProp("width", "8em")
+	gl.Tooltip = "line number to go to -- hit enter to show it"
+	gl.TextFieldSig.Connect(lv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			lvv, _ := recv.Embed(KiT_LargeFileView).(*LargeFileView)
+			tf := send.(*gi.TextField)
+			if ln, err := strconv.Atoi(strings.TrimSpace(tf.Text())); err == nil && ln > 0 {
+				lvv.ShowLine(ln - 1)
+			}
+		}
+	})
+
+	ic := tb.AddNewChild(gi.KiT_CheckBox, "ignore-case").(*gi.CheckBox)
+	ic.SetText("Ignore Case")
+	ic.SetChecked(true)
+
+	find := tb.AddNewChild(gi.KiT_TextField, "find").(*gi.TextField)
+	find.SetStretchMaxWidth()
+	find.Tooltip = "text to find -- hit enter to search the whole file, which is streamed through rather than loaded"
+	find.TextFieldSig.Connect(lv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			lvv, _ := recv.Embed(KiT_LargeFileView).(*LargeFileView)
+			tf := send.(*gi.TextField)
+			lvv.Find(tf.Text(), ic.IsChecked())
+		}
+	})
+}
+
+var LargeFileViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeLargeTest(t *testing.T, txt string) string {
+	dir, err := ioutil.TempDir("", "gide-large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := filepath.Join(dir, "big.log")
+	if err := ioutil.WriteFile(fn, []byte(txt), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fn
+}
+
+func TestLargeFile(t *testing.T) {
+	var sb strings.Builder
+	n := 2*LargeFileIndexStep + 500
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "line %d\r\n", i)
+	}
+	sb.WriteString("last")
+	fn := writeLargeTest(t, sb.String())
+	defer os.RemoveAll(filepath.Dir(fn))
+
+	lf, err := OpenLargeFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lf.Lines != n+1 || lf.Size != int64(sb.Len()) || len(lf.Offs) != 3 {
+		t.Errorf("OpenLargeFile: lines %d, size %d, %d offsets, want %d, %d, 3", lf.Lines, lf.Size, len(lf.Offs), n+1, sb.Len())
+	}
+	tests := []struct {
+		st, n int
+		want  string
+	}{
+		{0, 2, "line 0\nline 1"},
+		{999, 3, "line 999\nline 1000\nline 1001"},
+		{2499, 5, "line 2499\nlast"},
+		{3000, 5, ""},
+	}
+	for _, tt := range tests {
+		txt, err := lf.ReadLines(tt.st, tt.n)
+		if err != nil || string(txt) != tt.want {
+			t.Errorf("ReadLines(%d, %d) = %q, %v, want %q", tt.st, tt.n, txt, err, tt.want)
+		}
+	}
+
+	ms, err := lf.Search("LINE 123", true, 5, nil)
+	if err != nil || len(ms) != 5 || ms[0].Ln != 123 || ms[1].Ln != 1230 || ms[0].Text != "line 123" {
+		t.Errorf("Search = %v, %v, want lines 123, 1230.. up to 5", ms, err)
+	}
+	ms, _ = lf.Search("LINE 123", false, 5, nil)
+	if len(ms) != 0 {
+		t.Errorf("Search case sensitive = %v, want none", ms)
+	}
+	ms, _ = lf.Search("last", false, 5, func() bool { return true })
+	if len(ms) != 0 {
+		t.Errorf("Search cancelled = %v, want none", ms)
+	}
+	ms, _ = lf.Search("st", false, 5, nil)
+	if len(ms) != 1 || ms[0].Ln != n || ms[0].Ch != 2 {
+		t.Errorf("Search = %v, want last line at 2", ms)
+	}
+}
+
+func TestLargeFileLongLine(t *testing.T) {
+	long := strings.Repeat("x", 3*LargeFileMaxLine)
+	fn := writeLargeTest(t, "a\n"+long+"\nb\n")
+	defer os.RemoveAll(filepath.Dir(fn))
+	lf, err := OpenLargeFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txt, err := lf.ReadLines(0, 10)
+	if err != nil || string(txt) != "a\n"+long[:LargeFileMaxLine]+"\nb" {
+		t.Errorf("ReadLines of long line: %d bytes, %v", len(txt), err)
+	}
+}
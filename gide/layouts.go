@@ -0,0 +1,382 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki"
+)
+
+// Layout is a named layout of a gide window, as a whole: the proportions of
+// its panels, the panes split off from its text views, and the tool panels
+// open in its main tabs -- layouts can be copied as JSON and shared, e.g.,
+// one for Debugging and one for Writing
+type Layout struct {
+	Name   string     `desc:"name of the layout"`
+	Desc   string     `desc:"brief description"`
+	Splits []float32  `desc:"proportions of the five panels: file tree, the two text views, main tabs and visualization tabs"`
+	Panes  []PaneNode `desc:"layout of the panes split off from the two text views, if any -- panes showing files that are not in the project are left empty"`
+	Tabs   []string   `desc:"tool panels to open in the main tabs, by name, e.g., Problems or Outline -- see LayoutTabs -- the last one is selected"`
+	Close  bool       `desc:"close the main tabs that are not in Tabs, e.g., the debugging ones when switching to a layout for writing"`
+}
+
+// Label satisfies the Labeler interface
+func (lt Layout) Label() string {
+	return lt.Name
+}
+
+// LayoutTabs are the gide methods that open the tool panels that can be in
+// layouts, by the names of their tabs -- other tabs, e.g., the output of
+// commands, are not part of layouts
+var LayoutTabs = map[string]string{
+	"Outline":     "Outline",
+	"Preview":     "MarkdownPreview",
+	"Problems":    "Problems",
+	"Todo":        "Todos",
+	"Procs":       "Procs",
+	"Audit":       "AuditLog",
+	"Breakpoints": "DebugBreaksView",
+	"Memory":      "DebugMemory",
+	"Registers":   "DebugRegisters",
+}
+
+// CheckLayout checks that the layout given as a *Layout has a name, and
+// splits for the five panels that are not all zero
+func CheckLayout(lti interface{}) error {
+	lt := lti.(*Layout)
+	if lt.Name == "" {
+		return fmt.Errorf("layout has no name")
+	}
+	if len(lt.Splits) != 5 {
+		return fmt.Errorf("layout %v: needs splits for the 5 panels, not %d", lt.Name, len(lt.Splits))
+	}
+	sum := float32(0)
+	for _, s := range lt.Splits {
+		if s < 0 {
+			return fmt.Errorf("layout %v: splits cannot be negative", lt.Name)
+		}
+		sum += s
+	}
+	if sum == 0 {
+		return fmt.Errorf("layout %v: splits are all zero", lt.Name)
+	}
+	return nil
+}
+
+// Layouts is a list of named layouts
+type Layouts []Layout
+
+// LayoutName has the name of one of the AvailLayouts
+type LayoutName string
+
+// AvailLayouts are the available named layouts -- StdLayouts, plus those
+// saved or imported, which are kept in the prefs directory
+var AvailLayouts Layouts
+
+// AvailLayoutNames are the names of the current AvailLayouts -- used for
+// some choosers
+var AvailLayoutNames []string
+
+func init() {
+	AvailLayouts = append(Layouts(nil), StdLayouts...)
+	AvailLayoutNames = AvailLayouts.Names()
+}
+
+// LayoutByName returns the layout of given name, and its index -- false if
+// not found
+func (lt *Layouts) LayoutByName(name LayoutName) (*Layout, int, bool) {
+	for i := range *lt {
+		if (*lt)[i].Name == string(name) {
+			return &(*lt)[i], i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// Names returns a slice of current names
+func (lt *Layouts) Names() []string {
+	nms := make([]string, len(*lt))
+	for i := range *lt {
+		nms[i] = (*lt)[i].Name
+	}
+	return nms
+}
+
+// Merge adds given layouts, replacing those with the same names, and
+// returns their names
+func (lt *Layouts) Merge(ls Layouts) []string {
+	nms := make([]string, len(ls))
+	for i, l := range ls {
+		nms[i] = l.Name
+		if _, idx, ok := lt.LayoutByName(LayoutName(l.Name)); ok {
+			(*lt)[idx] = l
+			continue
+		}
+		*lt = append(*lt, l)
+	}
+	return nms
+}
+
+// NextLayoutName returns the name of the layout after given one, wrapping
+// around -- the first one if it is not found
+func (lt *Layouts) NextLayoutName(name LayoutName) LayoutName {
+	if len(*lt) == 0 {
+		return ""
+	}
+	_, idx, _ := lt.LayoutByName(name)
+	return LayoutName((*lt)[(idx+1)%len(*lt)].Name)
+}
+
+// ParseLayouts parses given JSON, which can be one layout or a list of
+// them, e.g., as copied by a teammate -- layouts with errors are skipped,
+// and returned as errors with the rest
+func ParseLayouts(b []byte) (Layouts, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '{' {
+		b = append(append([]byte("["), b...), ']')
+	}
+	var ls Layouts
+	if errs := JSONLoad(b, &ls, CheckLayout); len(errs) > 0 {
+		return ls, errs
+	}
+	if len(ls) == 0 {
+		return nil, fmt.Errorf("no layouts in the JSON")
+	}
+	return ls, nil
+}
+
+// PrefsLayoutsFileName is the name of the preferences file in App prefs
+// directory for saving / loading the layouts that are not standard
+var PrefsLayoutsFileName = "layouts_prefs.json"
+
+// OpenPrefs adds the layouts saved in the App standard prefs directory to
+// StdLayouts
+func (lt *Layouts) OpenPrefs() error {
+	pnm := filepath.Join(oswin.TheApp.AppPrefsDir(), PrefsLayoutsFileName)
+	b, err := ioutil.ReadFile(pnm)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	ls, err := ParseLayouts(b)
+	if je, ok := err.(JSONErrors); ok {
+		je.SetFile(pnm)
+		je.Report("Errors in Layouts")
+	}
+	*lt = append(Layouts(nil), StdLayouts...)
+	lt.Merge(ls)
+	AvailLayoutNames = lt.Names()
+	return err
+}
+
+// SavePrefs saves the layouts to the App standard prefs directory
+func (lt *Layouts) SavePrefs() error {
+	pnm := filepath.Join(oswin.TheApp.AppPrefsDir(), PrefsLayoutsFileName)
+	AvailLayoutNames = lt.Names()
+	b, err := json.MarshalIndent(lt, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(pnm, b, 0644)
+	if err != nil {
+		gi.PromptDialog(nil, gi.DlgOpts{Title: "Could not Save Layouts", Prompt: err.Error()}, true, false, nil, nil)
+		log.Println(err)
+	}
+	return err
+}
+
+// StdLayouts is the original compiled-in set of standard layouts
+var StdLayouts = Layouts{
+	{Name: "Code", Desc: "file tree, 2 text views and main tabs", Splits: []float32{.1, .325, .325, .25, 0}},
+	{Name: "Writing", Desc: "1 wide text view, with its outline", Splits: []float32{.1, .6, 0, .3, 0}, Tabs: []string{"Outline"}, Close: true},
+	{Name: "Debugging", Desc: "1 text view, with the problems and breakpoints in wide main tabs", Splits: []float32{.1, .4, 0, .5, 0}, Tabs: []string{"Problems", "Breakpoints"}},
+}
+
+// CurLayout returns the current layout of the window, with given name and
+// description
+func (ge *Gide) CurLayout(name, desc string) *Layout {
+	lt := &Layout{Name: name, Desc: desc}
+	if sv := ge.SplitView(); sv != nil {
+		lt.Splits = append([]float32(nil), sv.Splits...)
+	}
+	pns := ge.PanesLayout()
+	for _, pn := range pns {
+		if len(pn.Kids) > 0 {
+			lt.Panes = pns
+			break
+		}
+	}
+	if mt := ge.MainTabs(); mt != nil {
+		for _, k := range mt.Frame().Kids {
+			if _, ok := LayoutTabs[k.Name()]; ok {
+				lt.Tabs = append(lt.Tabs, k.Name())
+			}
+		}
+	}
+	return lt
+}
+
+// ApplyLayout changes the window to given layout
+func (ge *Gide) ApplyLayout(lt *Layout) {
+	if sv := ge.SplitView(); sv != nil && len(lt.Splits) == len(sv.Splits) {
+		sv.SetSplitsAction(lt.Splits...)
+	}
+	if lt.Panes != nil {
+		ge.UnsplitPanes()
+		ge.Prefs.Panes = lt.Panes
+		ge.RestorePanes()
+	}
+	if mt := ge.MainTabs(); mt != nil && lt.Close {
+		keep := map[string]bool{}
+		for _, tn := range lt.Tabs {
+			keep[tn] = true
+		}
+		var cls []string
+		for _, k := range mt.Frame().Kids {
+			if !keep[k.Name()] {
+				cls = append(cls, k.Name())
+			}
+		}
+		for _, tn := range cls {
+			if _, idx, ok := ge.MainTabByName(tn); ok {
+				mt.DeleteTabIndex(idx, true)
+			}
+		}
+	}
+	for _, tn := range lt.Tabs {
+		meth, ok := LayoutTabs[tn]
+		if !ok {
+			continue
+		}
+		if _, _, has := ge.SelectMainTabByName(tn); has {
+			continue
+		}
+		if mv := reflect.ValueOf(ge).MethodByName(meth); mv.IsValid() {
+			mv.Call(nil)
+		}
+	}
+	ge.Prefs.Layout = LayoutName(lt.Name)
+	ge.Prefs.Changed = true
+	ge.SetStatus("Layout: " + lt.Name)
+}
+
+// LayoutSetView changes the window to the layout of given name
+func (ge *Gide) LayoutSetView(layout LayoutName) {
+	lt, _, ok := AvailLayouts.LayoutByName(layout)
+	if !ok {
+		ge.SetStatus(fmt.Sprintf("No layout named %v", layout))
+		return
+	}
+	ge.ApplyLayout(lt)
+}
+
+// LayoutChoose offers the names of the layouts in a menu, to change the
+// window to the one chosen
+func (ge *Gide) LayoutChoose() {
+	tv := ge.ActiveTextView()
+	gi.StringsChooserPopup(AvailLayoutNames, string(ge.Prefs.Layout), tv, func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		ge.LayoutSetView(LayoutName(AvailLayoutNames[ac.Data.(int)]))
+	})
+}
+
+// LayoutNext changes the window to the layout after the current one
+func (ge *Gide) LayoutNext() {
+	ge.LayoutSetView(AvailLayouts.NextLayoutName(ge.Prefs.Layout))
+}
+
+// LayoutSaveAs saves the current layout of the window under given name,
+// replacing any layout of that name
+func (ge *Gide) LayoutSaveAs(name, desc string) {
+	AvailLayouts.Merge(Layouts{*ge.CurLayout(name, desc)})
+	AvailLayouts.SavePrefs()
+	ge.Prefs.Layout = LayoutName(name)
+	ge.SetStatus("Saved layout: " + name)
+}
+
+// LayoutCopy copies the current layout of the window, as JSON, to the
+// clipboard, under given name, to share it, e.g., by pasting it in a chat --
+// it is imported with LayoutPaste
+func (ge *Gide) LayoutCopy(name, desc string) {
+	b, err := json.MarshalIndent(ge.CurLayout(name, desc), "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return
+	}
+	oswin.TheApp.ClipBoard(ge.ParentWindow().OSWin).Write(mimedata.NewTextBytes(b))
+	ge.SetStatus("Copied layout " + name + " to the clipboard, as JSON")
+}
+
+// LayoutPaste imports the layout(s) in the JSON in the clipboard, e.g., as
+// copied by a teammate with LayoutCopy, saving them, and changes the window
+// to the first one
+func (ge *Gide) LayoutPaste() {
+	md := oswin.TheApp.ClipBoard(ge.ParentWindow().OSWin).Read([]string{mimedata.TextPlain})
+	if md == nil {
+		ge.SetStatus("Nothing in the clipboard to paste")
+		return
+	}
+	ge.LayoutImportJSON(md.Text(mimedata.TextPlain), "the clipboard")
+}
+
+// LayoutExport saves the current layout of the window, as JSON, to given
+// file, under given name, to share it -- it is imported with LayoutImport
+func (ge *Gide) LayoutExport(filename gi.FileName, name, desc string) error {
+	b, err := json.MarshalIndent(ge.CurLayout(name, desc), "", "  ")
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Export Layout", Prompt: err.Error()}, true, false, nil, nil)
+	}
+	return err
+}
+
+// LayoutImport imports the layout(s) in given JSON file, saving them, and
+// changes the window to the first one
+func (ge *Gide) LayoutImport(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Import Layout", Prompt: err.Error()}, true, false, nil, nil)
+		return err
+	}
+	return ge.LayoutImportJSON(string(b), string(filename))
+}
+
+// LayoutImportJSON imports the layout(s) in given JSON, from given source,
+// saving them, and changes the window to the first one
+func (ge *Gide) LayoutImportJSON(js string, from string) error {
+	ls, err := ParseLayouts([]byte(js))
+	if je, ok := err.(JSONErrors); ok {
+		je.SetFile(from)
+		je.Report("Errors in Layouts")
+	} else if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Import Layout", Prompt: fmt.Sprintf("No layouts in %v: %v", from, err)}, true, false, nil, nil)
+		return err
+	}
+	if len(ls) == 0 {
+		return err
+	}
+	nms := AvailLayouts.Merge(ls)
+	AvailLayouts.SavePrefs()
+	ge.ApplyLayout(&ls[0])
+	ge.SetStatus(fmt.Sprintf("Imported layouts: %v", nms))
+	return err
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLayouts(t *testing.T) {
+	ls, err := ParseLayouts([]byte(`{"Name": "Review", "Splits": [0, 0.5, 0.5, 0, 0], "Tabs": ["Problems"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ls) != 1 || ls[0].Name != "Review" || !reflect.DeepEqual(ls[0].Tabs, []string{"Problems"}) {
+		t.Errorf("single: %+v", ls)
+	}
+
+	js := `[
+  {"Name": "A", "Splits": [0.1, 0.9, 0, 0, 0]},
+  {"Name": "", "Splits": [0.1, 0.9, 0, 0, 0]},
+  {"Name": "B", "Splits": [0.5, 0.5]},
+  {"Name": "C", "Splits": [0, 0, 0, 0, 0]},
+  {"Name": "D", "Splits": [-1, 1, 1, 0, 0]},
+  {"Name": "E", "Splits": [0.2, 0.8, 0, 0, 0], "Bogus": 1}
+]`
+	ls, err = ParseLayouts([]byte(js))
+	if len(ls) != 2 || ls[0].Name != "A" || ls[1].Name != "E" { // only the bad field of E is skipped
+		t.Errorf("list: %+v", ls)
+	}
+	je, ok := err.(JSONErrors)
+	if !ok || len(je) != 5 {
+		t.Fatalf("expected 5 errors, got %v", err)
+	}
+	if je[0].Line != 3 || !strings.Contains(je[0].Msg, "no name") {
+		t.Errorf("first error: %v", je[0])
+	}
+
+	if _, err := ParseLayouts([]byte(`[]`)); err == nil {
+		t.Error("expected error for no layouts")
+	}
+}
+
+func TestLayoutsMerge(t *testing.T) {
+	lt := append(Layouts(nil), StdLayouts...)
+	nms := lt.Merge(Layouts{{Name: "Writing", Desc: "mine", Splits: []float32{0, 1, 0, 0, 0}}, {Name: "Review", Splits: []float32{0, 1, 0, 0, 0}}})
+	if !reflect.DeepEqual(nms, []string{"Writing", "Review"}) {
+		t.Errorf("names: %v", nms)
+	}
+	if len(lt) != len(StdLayouts)+1 {
+		t.Errorf("expected %d layouts, got %d", len(StdLayouts)+1, len(lt))
+	}
+	if w, _, ok := lt.LayoutByName("Writing"); !ok || w.Desc != "mine" {
+		t.Errorf("Writing not replaced: %+v", w)
+	}
+	if StdLayouts[1].Desc == "mine" {
+		t.Error("StdLayouts changed")
+	}
+	if nx := lt.NextLayoutName("Review"); nx != LayoutName(lt[0].Name) {
+		t.Errorf("next after last: %v", nx)
+	}
+	if nx := lt.NextLayoutName("nope"); nx != LayoutName(lt[0].Name) {
+		t.Errorf("next after unknown: %v", nx)
+	}
+	if nx := lt.NextLayoutName(LayoutName(lt[0].Name)); nx != LayoutName(lt[1].Name) {
+		t.Errorf("next: %v", nx)
+	}
+	for _, l := range StdLayouts {
+		if err := CheckLayout(&l); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ProcLimits are limits on the resources that a spawned tool can use, so
+// that background linters, indexers and big builds can not starve the editor
+// or the machine -- they are set using the sh ulimit builtin and nice, so
+// they are only applied where those are available (not on Windows)
+type ProcLimits struct {
+	Nice    int `min:"0" max:"19" desc:"niceness to run the tool with, from 0 (normal) to 19 -- the higher it is, the lower its cpu priority, so it only gets the cpu time that others do not need"`
+	CPUSecs int `min:"0" desc:"maximum cpu time the tool can use, in seconds, after which it is killed -- 0 for no limit"`
+	MemMB   int `min:"0" desc:"maximum virtual memory the tool can use, in megabytes, beyond which its allocations fail -- 0 for no limit"`
+}
+
+// IsZero returns true if there are no limits set
+func (pl *ProcLimits) IsZero() bool {
+	return pl.Nice <= 0 && pl.CPUSecs <= 0 && pl.MemMB <= 0
+}
+
+// LimitArgs returns given command and args wrapped to run with the limits,
+// using sh -- any limits that can not be set on the system are left out
+func (pl *ProcLimits) LimitArgs(args []string) []string {
+	if pl.IsZero() || len(args) == 0 {
+		return args
+	}
+	var scr []string
+	if pl.CPUSecs > 0 {
+		scr = append(scr, fmt.Sprintf("ulimit -t %d 2>/dev/null", pl.CPUSecs))
+	}
+	if pl.MemMB > 0 {
+		scr = append(scr, fmt.Sprintf("ulimit -v %d 2>/dev/null", pl.MemMB*1024))
+	}
+	if pl.Nice > 0 {
+		scr = append(scr, fmt.Sprintf(`exec nice -n %d "$0" "$@"`, pl.Nice))
+	} else {
+		scr = append(scr, `exec "$0" "$@"`)
+	}
+	return append([]string{"sh", "-c", strings.Join(scr, "; ")}, args...)
+}
+
+// Apply sets up given command, not yet started, to run with the limits, and
+// returns it -- it is wrapped in place, so all of its other settings are
+// kept -- it is left as is if there are none, or if sh is not available
+func (pl *ProcLimits) Apply(cmd *exec.Cmd) *exec.Cmd {
+	if pl.IsZero() || runtime.GOOS == "windows" {
+		return cmd
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		return cmd
+	}
+	cmd.Args = pl.LimitArgs(cmd.Args)
+	cmd.Path = sh
+	return cmd
+}
+
+// Limit returns given command, prepared to run for this command, set up to
+// run with the resource limits for it set in the preferences, if any
+func (cm *Command) Limit(cmd *exec.Cmd) *exec.Cmd {
+	pl, has := Prefs.CmdLimits[CmdName(cm.Name)]
+	if !has {
+		return cmd
+	}
+	return pl.Apply(cmd)
+}
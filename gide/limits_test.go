@@ -0,0 +1,53 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestLimitArgs(t *testing.T) {
+	args := []string{"golint", "./..."}
+	pl := ProcLimits{}
+	if got := pl.LimitArgs(args); !reflect.DeepEqual(got, args) {
+		t.Errorf("LimitArgs with no limits = %q, want %q", got, args)
+	}
+	pl = ProcLimits{Nice: 10, CPUSecs: 60, MemMB: 512}
+	want := []string{"sh", "-c", `ulimit -t 60 2>/dev/null; ulimit -v 524288 2>/dev/null; exec nice -n 10 "$0" "$@"`, "golint", "./..."}
+	if got := pl.LimitArgs(args); !reflect.DeepEqual(got, want) {
+		t.Errorf("LimitArgs = %q, want %q", got, want)
+	}
+	pl = ProcLimits{MemMB: 1}
+	want = []string{"sh", "-c", `ulimit -v 1024 2>/dev/null; exec "$0" "$@"`, "golint", "./..."}
+	if got := pl.LimitArgs(args); !reflect.DeepEqual(got, want) {
+		t.Errorf("LimitArgs = %q, want %q", got, want)
+	}
+}
+
+func TestProcLimitsApply(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("limits are checked with the linux sh and nice")
+	}
+	pl := ProcLimits{Nice: 5, CPUSecs: 30}
+	cmd := exec.Command("sh", "-c", `echo "$0 $1"; ulimit -t; nice; echo "$GIDE_LIMITS_TEST"`, "a b", "c")
+	cmd.Env = []string{"GIDE_LIMITS_TEST=env"}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if lc := pl.Apply(cmd); lc != cmd || lc.SysProcAttr == nil || !lc.SysProcAttr.Setpgid {
+		t.Errorf("Apply: command was not set up in place, with its SysProcAttr kept")
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Apply: run error: %v", err)
+	}
+	lns := strings.Fields(string(out))
+	if len(lns) != 6 || lns[0] != "a" || lns[1] != "b" || lns[2] != "c" || lns[3] != "30" || lns[4] != "5" || lns[5] != "env" {
+		t.Errorf("Apply: output = %q, want args a b c, cpu limit 30, niceness 5 and the command env", out)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/goki/gi/giv"
+)
+
+// LineEnds is the style of line endings of a file -- buffers are always
+// edited with LF line endings, and files with other ones are converted when
+// opened, and back when saved
+type LineEnds int
+
+const (
+	// LineEndsLF is \n, as on Unix, Linux and macOS
+	LineEndsLF LineEnds = iota
+
+	// LineEndsCRLF is \r\n, as on Windows
+	LineEndsCRLF
+
+	// LineEndsCR is \r, as on classic Mac OS
+	LineEndsCR
+)
+
+// lineEndsNames are the names and separators of the line ending styles
+var lineEndsNames = [...]struct{ nm, sep string }{
+	LineEndsLF:   {"LF", "\n"},
+	LineEndsCRLF: {"CRLF", "\r\n"},
+	LineEndsCR:   {"CR", "\r"},
+}
+
+func (le LineEnds) String() string {
+	if le < 0 || int(le) >= len(lineEndsNames) {
+		return fmt.Sprintf("LineEnds(%d)", int(le))
+	}
+	return lineEndsNames[le].nm
+}
+
+// Sep returns the separator for the line ending style
+func (le LineEnds) Sep() string {
+	if le < 0 || int(le) >= len(lineEndsNames) {
+		return "\n"
+	}
+	return lineEndsNames[le].sep
+}
+
+// DetectLineEnds returns the style of line endings used the most in given
+// text, LF if there are none, and whether other styles are used as well
+func DetectLineEnds(txt []byte) (le LineEnds, mixed bool) {
+	var n [len(lineEndsNames)]int
+	for i := 0; i < len(txt); i++ {
+		switch {
+		case txt[i] == '\n':
+			n[LineEndsLF]++
+		case txt[i] == '\r' && i+1 < len(txt) && txt[i+1] == '\n':
+			n[LineEndsCRLF]++
+			i++
+		case txt[i] == '\r':
+			n[LineEndsCR]++
+		}
+	}
+	nused := 0
+	for s, c := range n {
+		if c > n[le] {
+			le = LineEnds(s)
+		}
+		if c > 0 {
+			nused++
+		}
+	}
+	return le, nused > 1
+}
+
+// ConvertLineEnds returns given text with all of its line endings, of any
+// style, converted to le
+func ConvertLineEnds(txt []byte, le LineEnds) []byte {
+	sep := []byte(le.Sep())
+	var b bytes.Buffer
+	b.Grow(len(txt))
+	for i := 0; i < len(txt); i++ {
+		switch {
+		case txt[i] == '\r' && i+1 < len(txt) && txt[i+1] == '\n':
+			b.Write(sep)
+			i++
+		case txt[i] == '\r' || txt[i] == '\n':
+			b.Write(sep)
+		default:
+			b.WriteByte(txt[i])
+		}
+	}
+	return b.Bytes()
+}
+
+// LineEndsFor returns the line endings that given buffer is saved with
+func (ge *Gide) LineEndsFor(tb *giv.TextBuf) LineEnds {
+	return ge.lineEnds[tb]
+}
+
+// LineEndsOpen detects the line endings of given buffer, just opened from
+// its file, and converts any that are not LF, so it can be edited, keeping
+// the style used the most to save it with -- files with mixed line endings
+// are saved with that style throughout
+func (ge *Gide) LineEndsOpen(tb *giv.TextBuf) {
+	txt := tb.LinesToBytesCopy()
+	le, mixed := DetectLineEnds(txt)
+	if ge.lineEnds == nil {
+		ge.lineEnds = make(map[*giv.TextBuf]LineEnds)
+	}
+	if le == LineEndsLF {
+		delete(ge.lineEnds, tb)
+	} else {
+		ge.lineEnds[tb] = le
+	}
+	if le != LineEndsLF || mixed {
+		tb.SetText(ConvertLineEnds(txt, LineEndsLF))
+	}
+	if mixed {
+		ge.SetStatus(fmt.Sprintf("File has mixed line endings -- it will be saved with %v, the most used", le))
+	}
+}
+
+// LineEndsSaved rewrites the file of given buffer, just saved, with its line
+// endings, if they are not LF
+func (ge *Gide) LineEndsSaved(tb *giv.TextBuf) error {
+	le := ge.LineEndsFor(tb)
+	if le == LineEndsLF || tb.Filename == "" {
+		return nil
+	}
+	fpath := string(tb.Filename)
+	st, err := os.Stat(fpath)
+	if err != nil {
+		return err
+	}
+	txt, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fpath, ConvertLineEnds(txt, le), st.Mode())
+}
+
+// SetLineEndsActiveView sets the line endings that the file in the active
+// view is saved with -- it is converted when it is next saved
+func (ge *Gide) SetLineEndsActiveView(le LineEnds) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if ge.lineEnds == nil {
+		ge.lineEnds = make(map[*giv.TextBuf]LineEnds)
+	}
+	if le == LineEndsLF {
+		delete(ge.lineEnds, tv.Buf)
+	} else {
+		ge.lineEnds[tv.Buf] = le
+	}
+	ge.SetStatus(fmt.Sprintf("Line endings set to %v -- the file is converted when it is saved", le))
+}
+
+// LineEndsToLF converts the line endings of the file in the active view to
+// LF (Unix) when it is next saved
+func (ge *Gide) LineEndsToLF() {
+	ge.SetLineEndsActiveView(LineEndsLF)
+}
+
+// LineEndsToCRLF converts the line endings of the file in the active view to
+// CRLF (Windows) when it is next saved
+func (ge *Gide) LineEndsToCRLF() {
+	ge.SetLineEndsActiveView(LineEndsCRLF)
+}
+
+// LineEndsToCR converts the line endings of the file in the active view to
+// CR (classic Mac OS) when it is next saved
+func (ge *Gide) LineEndsToCR() {
+	ge.SetLineEndsActiveView(LineEndsCR)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import "testing"
+
+func TestDetectLineEnds(t *testing.T) {
+	tests := []struct {
+		txt   string
+		le    LineEnds
+		mixed bool
+	}{
+		{"", LineEndsLF, false},
+		{"one line", LineEndsLF, false},
+		{"a\nb\n", LineEndsLF, false},
+		{"a\r\nb\r\n", LineEndsCRLF, false},
+		{"a\rb\rc", LineEndsCR, false},
+		{"a\r\nb\r\nc\n", LineEndsCRLF, true},
+		{"a\nb\r\n", LineEndsLF, true},
+	}
+	for _, tt := range tests {
+		le, mixed := DetectLineEnds([]byte(tt.txt))
+		if le != tt.le || mixed != tt.mixed {
+			t.Errorf("DetectLineEnds(%q) = %v, %v, want %v, %v", tt.txt, le, mixed, tt.le, tt.mixed)
+		}
+	}
+}
+
+func TestConvertLineEnds(t *testing.T) {
+	in := "a\r\nb\nc\rd\r\n"
+	for le, want := range map[LineEnds]string{
+		LineEndsLF:   "a\nb\nc\nd\n",
+		LineEndsCRLF: "a\r\nb\r\nc\r\nd\r\n",
+		LineEndsCR:   "a\rb\rc\rd\r",
+	} {
+		if got := string(ConvertLineEnds([]byte(in), le)); got != want {
+			t.Errorf("ConvertLineEnds to %v: got %q, want %q", le, got, want)
+		}
+	}
+	if got := string(ConvertLineEnds([]byte("x\r\n"), LineEndsLF)); got != "x\n" {
+		t.Errorf("ConvertLineEnds of trailing CRLF: got %q", got)
+	}
+}
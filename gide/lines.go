@@ -0,0 +1,233 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+)
+
+// SortLines returns given lines sorted, ascending or descending, keeping
+// the order of equal lines
+func SortLines(lines []string, desc bool) []string {
+	out := append([]string(nil), lines...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if desc {
+			return out[i] > out[j]
+		}
+		return out[i] < out[j]
+	})
+	return out
+}
+
+var lineNumRe = regexp.MustCompile(`^\s*[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?`)
+
+// LineNumber returns the number at the start of given line, after any
+// spaces, e.g., 42, -3.5 or 1e6 -- false if it does not start with one
+func LineNumber(line string) (float64, bool) {
+	m := lineNumRe.FindString(line)
+	if m == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(m), 64)
+	return n, err == nil
+}
+
+// SortLinesNum returns given lines sorted by the number at their start (see
+// LineNumber), ascending -- lines without a number come after those with
+// one, in their order
+func SortLinesNum(lines []string) []string {
+	out := append([]string(nil), lines...)
+	sort.SliceStable(out, func(i, j int) bool {
+		ni, iok := LineNumber(out[i])
+		nj, jok := LineNumber(out[j])
+		if iok != jok {
+			return iok
+		}
+		return iok && ni < nj
+	})
+	return out
+}
+
+// UniqueLines returns given lines without the repeats of any line, adjacent
+// or not -- the first one of each is kept, where it is
+func UniqueLines(lines []string) []string {
+	has := make(map[string]bool, len(lines))
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if has[l] {
+			continue
+		}
+		has[l] = true
+		out = append(out, l)
+	}
+	return out
+}
+
+// ReverseLines returns given lines in reverse order
+func ReverseLines(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[len(lines)-1-i] = l
+	}
+	return out
+}
+
+// JoinLines returns given lines joined into one, with the indentation of the
+// first line that is not blank, and a single space between the text of each
+// -- blank lines are dropped
+func JoinLines(lines []string) []string {
+	if len(lines) == 0 {
+		return nil
+	}
+	jl := strings.TrimRightFunc(lines[0], unicode.IsSpace)
+	for _, l := range lines[1:] {
+		if strings.TrimSpace(jl) == "" {
+			jl = strings.TrimRightFunc(l, unicode.IsSpace)
+			continue
+		}
+		if l = strings.TrimSpace(l); l != "" {
+			jl += " " + l
+		}
+	}
+	return []string{jl}
+}
+
+// LinesApply replaces the selected lines in the active view, or all of its
+// lines if nothing is selected, with what given function makes of them, and
+// selects them -- join applies to the current line and the next one if
+// nothing is selected -- returns false if nothing changed
+func (ge *Gide) LinesApply(fun func(lines []string) []string, join bool) bool {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.IsInactive() {
+		return false
+	}
+	tb := tv.Buf
+	nl := len(tb.Lines)
+	stl, edl := 0, nl-1
+	if join {
+		stl, edl = tv.CursorPos.Ln, tv.CursorPos.Ln+1
+	}
+	if sel := tv.Selection(); sel != nil {
+		stl, edl = sel.Reg.Start.Ln, sel.Reg.End.Ln
+		if sel.Reg.End.Ch == 0 && edl > stl {
+			edl-- // selection of whole lines ends at the start of the next
+		}
+	} else if !join {
+		for edl > 0 && len(tb.Lines[edl]) == 0 {
+			edl-- // not the empty lines at the end
+		}
+	}
+	if edl >= nl {
+		edl = nl - 1
+	}
+	if stl < 0 || edl <= stl {
+		ge.SetStatus("Select the lines to change")
+		return false
+	}
+	lines := make([]string, edl-stl+1)
+	for i := range lines {
+		lines[i] = string(tb.Lines[stl+i])
+	}
+	out := fun(lines)
+	if strings.Join(out, "\n") == strings.Join(lines, "\n") {
+		ge.SetStatus("Lines are unchanged")
+		return false
+	}
+	tv.SelectReset()
+	ed := giv.TextPos{Ln: edl, Ch: len(tb.Lines[edl])}
+	tb.DeleteText(giv.TextPos{Ln: stl}, ed, true, true)
+	tb.InsertText(giv.TextPos{Ln: stl}, []byte(strings.Join(out, "\n")), true, true)
+	last := stl + len(out) - 1
+	reg := giv.TextRegion{Start: giv.TextPos{Ln: stl}, End: giv.TextPos{Ln: last, Ch: len([]rune(out[len(out)-1]))}}
+	updt := tv.UpdateStart()
+	tv.SetCursorShow(reg.End)
+	tv.SelectReg = reg
+	tv.UpdateEnd(updt)
+	if d := len(lines) - len(out); d > 0 {
+		ge.SetStatus(strconv.Itoa(d) + " lines removed")
+	}
+	return true
+}
+
+// SortLinesAsc sorts the selected lines in the active view, or all of them,
+// in ascending order
+func (ge *Gide) SortLinesAsc() {
+	ge.LinesApply(func(lines []string) []string { return SortLines(lines, false) }, false)
+}
+
+// SortLinesDesc sorts the selected lines in the active view, or all of
+// them, in descending order
+func (ge *Gide) SortLinesDesc() {
+	ge.LinesApply(func(lines []string) []string { return SortLines(lines, true) }, false)
+}
+
+// SortLinesNumeric sorts the selected lines in the active view, or all of
+// them, by the numbers at their start
+func (ge *Gide) SortLinesNumeric() {
+	ge.LinesApply(SortLinesNum, false)
+}
+
+// UniqueLinesActive removes the repeated lines of the selection in the
+// active view, or of all of its lines
+func (ge *Gide) UniqueLinesActive() {
+	ge.LinesApply(UniqueLines, false)
+}
+
+// ReverseLinesActive reverses the order of the selected lines in the
+// active view, or of all of them
+func (ge *Gide) ReverseLinesActive() {
+	ge.LinesApply(ReverseLines, false)
+}
+
+// JoinLinesActive joins the selected lines in the active view into one, or
+// the current line with the next one if nothing is selected
+func (ge *Gide) JoinLinesActive() {
+	ge.LinesApply(JoinLines, true)
+}
+
+// DuplicateLines duplicates the selected lines in the active view, or the
+// cursor line, inserting the copy below them, and moves the cursor or the
+// selection down onto it
+func (ge *Gide) DuplicateLines() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.IsInactive() {
+		return
+	}
+	tb := tv.Buf
+	stl, edl := tv.CursorPos.Ln, tv.CursorPos.Ln
+	sel := tv.Selection()
+	if sel != nil {
+		stl, edl = sel.Reg.Start.Ln, sel.Reg.End.Ln
+		if sel.Reg.End.Ch == 0 && edl > stl {
+			edl-- // selection of whole lines ends at the start of the next
+		}
+	}
+	if stl < 0 || edl >= len(tb.Lines) {
+		return
+	}
+	lines := make([]string, edl-stl+1)
+	for i := range lines {
+		lines[i] = string(tb.Lines[stl+i])
+	}
+	n, cur := len(lines), tv.CursorPos
+	tb.InsertText(giv.TextPos{Ln: edl, Ch: len(tb.Lines[edl])}, []byte("\n"+strings.Join(lines, "\n")), true, true)
+	updt := tv.UpdateStart()
+	if sel != nil {
+		reg := sel.Reg
+		reg.Start.Ln += n
+		reg.End.Ln += n
+		tv.SelectReg = reg
+		tv.SetCursorShow(reg.End)
+	} else {
+		tv.SetCursorShow(giv.TextPos{Ln: cur.Ln + n, Ch: cur.Ch})
+	}
+	tv.UpdateEnd(updt)
+}
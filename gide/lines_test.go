@@ -0,0 +1,55 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineOps(t *testing.T) {
+	tests := []struct {
+		name string
+		fun  func([]string) []string
+		in   []string
+		want []string
+	}{
+		{"sort", func(l []string) []string { return SortLines(l, false) }, []string{"b", "a", "c", "a"}, []string{"a", "a", "b", "c"}},
+		{"sort desc", func(l []string) []string { return SortLines(l, true) }, []string{"b", "a", "c"}, []string{"c", "b", "a"}},
+		{"numeric", SortLinesNum, []string{"10 ten", "x", "2 two", "  -1.5 neg", "", "1e2 hundred"}, []string{"  -1.5 neg", "2 two", "10 ten", "1e2 hundred", "x", ""}},
+		{"unique", UniqueLines, []string{"a", "b", "a", "c", "b"}, []string{"a", "b", "c"}},
+		{"reverse", ReverseLines, []string{"a", "b", "c"}, []string{"c", "b", "a"}},
+		{"join", JoinLines, []string{"\tfoo(a,  ", "\t\tb,", "", "   c)"}, []string{"\tfoo(a, b, c)"}},
+		{"join blank first", JoinLines, []string{"  ", "  x"}, []string{"  x"}},
+	}
+	for _, tt := range tests {
+		in := append([]string(nil), tt.in...)
+		if got := tt.fun(in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got %q, want %q", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(in, tt.in) {
+			t.Errorf("%v: input changed to %q", tt.name, in)
+		}
+	}
+}
+
+func TestLineNumber(t *testing.T) {
+	tests := []struct {
+		line string
+		n    float64
+		ok   bool
+	}{
+		{"42 x", 42, true},
+		{"  -3.5", -3.5, true},
+		{"+.5e1", 5, true},
+		{"v1", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if n, ok := LineNumber(tt.line); n != tt.n || ok != tt.ok {
+			t.Errorf("%q: got %v, %v", tt.line, n, ok)
+		}
+	}
+}
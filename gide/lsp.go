@@ -0,0 +1,650 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// This file has the Language Server Protocol (LSP) types and the JSON-RPC
+// transport used to talk to language servers -- see lspclient.go for the
+// client that manages servers and documents.  Only the parts of the protocol
+// used by Gide are defined here.
+
+//////////////////////////////////////////////////////////////////////////////////////
+//   Protocol types
+
+// LspPosition is a 0-based line, character position in a document -- the
+// character is in UTF-16 code units unless the server agreed to count runes
+// (see LspClient.UTF16) -- the client converts it to and from the rune
+// columns used everywhere else
+type LspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// LspRange is a range in a document, end exclusive
+type LspRange struct {
+	Start LspPosition `json:"start"`
+	End   LspPosition `json:"end"`
+}
+
+// LspLocation is a range within a given document
+type LspLocation struct {
+	URI   string   `json:"uri"`
+	Range LspRange `json:"range"`
+}
+
+// LspTextDocumentIdentifier identifies a document
+type LspTextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// LspVersionedTextDocumentIdentifier identifies a specific version of a document
+type LspVersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+// LspTextDocumentItem is a document being opened
+type LspTextDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+// LspTextDocumentPositionParams are params for requests at a position in a document
+type LspTextDocumentPositionParams struct {
+	TextDocument LspTextDocumentIdentifier `json:"textDocument"`
+	Position     LspPosition               `json:"position"`
+}
+
+// LspTextDocumentContentChangeEvent is a change to a document -- if Range is
+// nil the Text is the full new content
+type LspTextDocumentContentChangeEvent struct {
+	Range *LspRange `json:"range,omitempty"`
+	Text  string    `json:"text"`
+}
+
+// LspTextEdit is an edit to a document
+type LspTextEdit struct {
+	Range   LspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+// LspWorkspaceEdit is a set of edits across documents, by uri
+type LspWorkspaceEdit struct {
+	Changes map[string][]LspTextEdit `json:"changes"`
+}
+
+// LspDiagSeverity is the severity of a diagnostic
+type LspDiagSeverity int
+
+const (
+	LspSevError LspDiagSeverity = iota + 1
+	LspSevWarning
+	LspSevInfo
+	LspSevHint
+)
+
+// String returns the name of the severity
+func (sv LspDiagSeverity) String() string {
+	switch sv {
+	case LspSevError:
+		return "error"
+	case LspSevWarning:
+		return "warning"
+	case LspSevInfo:
+		return "info"
+	case LspSevHint:
+		return "hint"
+	}
+	return "error" // servers omit severity for errors
+}
+
+// LspDiagnosticRelatedInformation is a location related to a diagnostic,
+// e.g., the previous declaration for a redeclaration error
+type LspDiagnosticRelatedInformation struct {
+	Location LspLocation `json:"location"`
+	Message  string      `json:"message"`
+}
+
+// LspDiagnostic is an error, warning etc reported by the server
+type LspDiagnostic struct {
+	Range    LspRange                          `json:"range"`
+	Severity LspDiagSeverity                   `json:"severity,omitempty"`
+	Code     interface{}                       `json:"code,omitempty"`
+	Source   string                            `json:"source,omitempty"`
+	Message  string                            `json:"message"`
+	Related  []LspDiagnosticRelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+// LspCodeAction is an action offered by the server for a range, e.g., a quick
+// fix for a diagnostic -- only actions with an edit are used
+type LspCodeAction struct {
+	Title       string            `json:"title"`
+	Kind        string            `json:"kind,omitempty"`
+	Diagnostics []LspDiagnostic   `json:"diagnostics,omitempty"`
+	Edit        *LspWorkspaceEdit `json:"edit,omitempty"`
+}
+
+// LspPublishDiagnosticsParams are the params of the diagnostics notification
+type LspPublishDiagnosticsParams struct {
+	URI         string          `json:"uri"`
+	Diagnostics []LspDiagnostic `json:"diagnostics"`
+}
+
+// LspMarkupContent is documentation text, in plaintext or markdown
+type LspMarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// LspCompletionItem is one completion option
+type LspCompletionItem struct {
+	Label         string       `json:"label"`
+	Kind          int          `json:"kind,omitempty"`
+	Detail        string       `json:"detail,omitempty"`
+	Documentation interface{}  `json:"documentation,omitempty"`
+	InsertText    string       `json:"insertText,omitempty"`
+	TextEdit      *LspTextEdit `json:"textEdit,omitempty"`
+}
+
+// LspCompletionList is the result of a completion request
+type LspCompletionList struct {
+	IsIncomplete bool                `json:"isIncomplete"`
+	Items        []LspCompletionItem `json:"items"`
+}
+
+// LspHover is the result of a hover request -- Contents can be markup
+// content, a string, or a list of them: use LspDocText to get the text
+type LspHover struct {
+	Contents interface{} `json:"contents"`
+	Range    *LspRange   `json:"range,omitempty"`
+}
+
+// LspParameterInformation is one parameter of a signature
+type LspParameterInformation struct {
+	Label interface{} `json:"label"`
+}
+
+// LspSignatureInformation is one function signature
+type LspSignatureInformation struct {
+	Label         string                    `json:"label"`
+	Documentation interface{}               `json:"documentation,omitempty"`
+	Parameters    []LspParameterInformation `json:"parameters,omitempty"`
+}
+
+// LspSignatureHelp is the result of a signature help request
+type LspSignatureHelp struct {
+	Signatures      []LspSignatureInformation `json:"signatures"`
+	ActiveSignature int                       `json:"activeSignature"`
+	ActiveParameter int                       `json:"activeParameter"`
+}
+
+// LspDocumentSymbol is a symbol in a document, e.g., a function, type or
+// method, with the symbols within it as its children -- Range spans all of
+// it, and SelectionRange just its name
+type LspDocumentSymbol struct {
+	Name           string              `json:"name"`
+	Detail         string              `json:"detail,omitempty"`
+	Kind           int                 `json:"kind"`
+	Range          LspRange            `json:"range"`
+	SelectionRange LspRange            `json:"selectionRange"`
+	Children       []LspDocumentSymbol `json:"children,omitempty"`
+}
+
+// LspSymbolInformation is a symbol in the flat list of symbols that older
+// servers return instead of a tree of LspDocumentSymbol
+type LspSymbolInformation struct {
+	Name          string      `json:"name"`
+	Kind          int         `json:"kind"`
+	Location      LspLocation `json:"location"`
+	ContainerName string      `json:"containerName,omitempty"`
+}
+
+// LspSymbolKinds are the names of the symbol kinds, by their number, which
+// starts at 1
+var LspSymbolKinds = []string{"", "file", "module", "namespace", "package", "class", "method", "property", "field", "constructor", "enum", "interface", "function", "variable", "constant", "string", "number", "boolean", "array", "object", "key", "null", "enummember", "struct", "event", "operator", "typeparameter"}
+
+// LspSymbolKind returns the name of given symbol kind
+func LspSymbolKind(kind int) string {
+	if kind <= 0 || kind >= len(LspSymbolKinds) {
+		return "symbol"
+	}
+	return LspSymbolKinds[kind]
+}
+
+// LspSymbolTree converts a flat list of symbols into a tree, with each
+// symbol as a child of the top-level symbol named as its container, if any
+func LspSymbolTree(infos []LspSymbolInformation) []LspDocumentSymbol {
+	var syms []LspDocumentSymbol
+	top := map[string]int{}
+	var kids []LspSymbolInformation
+	for _, si := range infos {
+		if si.ContainerName != "" {
+			kids = append(kids, si)
+			continue
+		}
+		top[si.Name] = len(syms)
+		syms = append(syms, LspDocumentSymbol{Name: si.Name, Kind: si.Kind, Range: si.Location.Range, SelectionRange: si.Location.Range})
+	}
+	for _, si := range kids {
+		ds := LspDocumentSymbol{Name: si.Name, Kind: si.Kind, Range: si.Location.Range, SelectionRange: si.Location.Range}
+		if i, ok := top[si.ContainerName]; ok {
+			syms[i].Children = append(syms[i].Children, ds)
+		} else {
+			syms = append(syms, ds)
+		}
+	}
+	return syms
+}
+
+// LspSemanticTokensLegend gives the names of the token types and modifiers
+// that are encoded as numbers in semantic tokens, from the server capabilities
+type LspSemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+// LspSemanticTokens is the result of a semantic tokens request -- Data has 5
+// numbers for each token, relative to the one before: see LspDecodeSemanticTokens
+type LspSemanticTokens struct {
+	ResultID string `json:"resultId,omitempty"`
+	Data     []int  `json:"data"`
+}
+
+// LspSemanticToken is one decoded semantic token, at given 0-based line and
+// char, with its type and modifiers from the legend
+type LspSemanticToken struct {
+	Line int
+	Char int
+	Len  int
+	Type string
+	Mods []string
+}
+
+// LspDecodeSemanticTokens decodes the relative encoding of semantic tokens:
+// line delta, char delta (from the previous token, if on the same line),
+// length, type index, and modifier bit set, for each token
+func LspDecodeSemanticTokens(data []int, lg *LspSemanticTokensLegend) []LspSemanticToken {
+	toks := make([]LspSemanticToken, 0, len(data)/5)
+	ln, ch := 0, 0
+	for i := 0; i+4 < len(data); i += 5 {
+		if data[i] > 0 {
+			ln += data[i]
+			ch = 0
+		}
+		ch += data[i+1]
+		tk := LspSemanticToken{Line: ln, Char: ch, Len: data[i+2]}
+		if ti := data[i+3]; ti >= 0 && ti < len(lg.TokenTypes) {
+			tk.Type = lg.TokenTypes[ti]
+		}
+		for mi, mn := range lg.TokenModifiers {
+			if data[i+4]&(1<<uint(mi)) != 0 {
+				tk.Mods = append(tk.Mods, mn)
+			}
+		}
+		toks = append(toks, tk)
+	}
+	return toks
+}
+
+// LspDocText returns the text of documentation as returned by the server,
+// which can be a plain string, markup content (with kind and value), a
+// marked string (with language and value), or a list of any of these
+func LspDocText(doc interface{}) string {
+	switch dv := doc.(type) {
+	case string:
+		return dv
+	case map[string]interface{}:
+		if v, ok := dv["value"].(string); ok {
+			return v
+		}
+	case []interface{}:
+		strs := make([]string, 0, len(dv))
+		for _, d := range dv {
+			if ds := LspDocText(d); ds != "" {
+				strs = append(strs, ds)
+			}
+		}
+		return strings.Join(strs, "\n\n")
+	}
+	return ""
+}
+
+// LspCharLen returns the length of given runes in the characters of a
+// server: UTF-16 code units if utf16, else runes
+func LspCharLen(rs []rune, utf16 bool) int {
+	n := len(rs)
+	if utf16 {
+		for _, r := range rs {
+			if r >= 0x10000 { // a surrogate pair
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// LspCol returns the column in the characters of a server of given rune
+// column in given line -- columns past the end are kept past the end
+func LspCol(line []rune, ch int, utf16 bool) int {
+	if ch <= 0 {
+		return ch
+	}
+	if ch > len(line) {
+		return LspCharLen(line, utf16) + ch - len(line)
+	}
+	return LspCharLen(line[:ch], utf16)
+}
+
+// LspRuneCol returns the rune column in given line of given column in the
+// characters of a server -- the inverse of LspCol
+func LspRuneCol(line []rune, col int, utf16 bool) int {
+	if !utf16 || col <= 0 {
+		return col
+	}
+	n := 0
+	for i, r := range line {
+		if n >= col {
+			return i
+		}
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+	return len(line) + col - n
+}
+
+// LspEditChange returns the change event for an edit at given rune
+// position that inserted, or deleted if del, given text -- line is the line
+// of the position after the edit, which is the same as before it up to the
+// position
+func LspEditChange(line []rune, ln, ch int, del bool, text []byte, utf16 bool) LspTextDocumentContentChangeEvent {
+	st := LspPosition{Line: ln, Character: LspCol(line, ch, utf16)}
+	if !del {
+		return LspTextDocumentContentChangeEvent{Range: &LspRange{Start: st, End: st}, Text: string(text)}
+	}
+	ed := st
+	lns := strings.Split(string(text), "\n")
+	if len(lns) == 1 {
+		ed.Character += LspCharLen([]rune(lns[0]), utf16)
+	} else {
+		ed.Line += len(lns) - 1
+		ed.Character = LspCharLen([]rune(lns[len(lns)-1]), utf16)
+	}
+	return LspTextDocumentContentChangeEvent{Range: &LspRange{Start: st, End: ed}}
+}
+
+// LspApplyEdits returns the given text with the given edits applied --
+// edits must not overlap, and positions are in lines and runes
+func LspApplyEdits(text string, edits []LspTextEdit) string {
+	if len(edits) == 0 {
+		return text
+	}
+	lns := strings.SplitAfter(text, "\n")
+	offset := func(pos LspPosition) int {
+		off := 0
+		for ln := 0; ln < pos.Line && ln < len(lns); ln++ {
+			off += len(lns[ln])
+		}
+		if pos.Line < len(lns) {
+			rs := []rune(strings.TrimSuffix(lns[pos.Line], "\n"))
+			ch := pos.Character
+			if ch > len(rs) {
+				ch = len(rs)
+			}
+			off += len(string(rs[:ch]))
+		}
+		return off
+	}
+	se := make([]LspTextEdit, len(edits))
+	copy(se, edits)
+	sort.SliceStable(se, func(i, j int) bool {
+		a, b := se[i].Range.Start, se[j].Range.Start
+		if a.Line == b.Line {
+			return a.Character > b.Character
+		}
+		return a.Line > b.Line
+	})
+	for _, ed := range se {
+		st := offset(ed.Range.Start)
+		en := offset(ed.Range.End)
+		if en < st {
+			en = st
+		}
+		text = text[:st] + ed.NewText + text[en:]
+	}
+	return text
+}
+
+// LspURI returns the file:// uri for given file path
+func LspURI(fpath string) string {
+	ap, err := filepath.Abs(fpath)
+	if err == nil {
+		fpath = ap
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(fpath)}
+	return u.String()
+}
+
+// LspPath returns the file path for given file:// uri
+func LspPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return strings.TrimPrefix(uri, "file://")
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//   JSON-RPC transport
+
+// LspTimeout is how long to wait for a response from a language server
+var LspTimeout = 10 * time.Second
+
+// LspError is an error response from a language server
+type LspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (le *LspError) Error() string {
+	return fmt.Sprintf("lsp error %d: %v", le.Code, le.Message)
+}
+
+// lspMsg is a JSON-RPC message -- request, response, or notification
+type lspMsg struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *LspError       `json:"error,omitempty"`
+}
+
+// LspWriteMsg writes given message body with the LSP Content-Length header
+func LspWriteMsg(w io.Writer, body []byte) error {
+	_, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// LspReadMsg reads the next message body, as framed by the Content-Length header
+func LspReadMsg(r *bufio.Reader) ([]byte, error) {
+	clen := -1
+	for {
+		ln, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		ln = strings.TrimSpace(ln)
+		if ln == "" {
+			break // end of headers
+		}
+		ci := strings.Index(ln, ":")
+		if ci < 0 {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(ln[:ci]), "Content-Length") {
+			clen, err = strconv.Atoi(strings.TrimSpace(ln[ci+1:]))
+			if err != nil {
+				return nil, fmt.Errorf("gide.LspReadMsg: bad Content-Length: %v", ln)
+			}
+		}
+	}
+	if clen < 0 {
+		return nil, errors.New("gide.LspReadMsg: no Content-Length header")
+	}
+	body := make([]byte, clen)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+// LspConn is a JSON-RPC connection to a language server -- call Run in a
+// goroutine to process incoming messages
+type LspConn struct {
+	Notify  func(method string, params json.RawMessage) `desc:"function called for notifications from the server, e.g., diagnostics"`
+	r       *bufio.Reader
+	w       io.Writer
+	wmu     sync.Mutex
+	mu      sync.Mutex
+	seq     int64
+	pending map[int64]chan *lspMsg
+	closed  bool
+}
+
+// NewLspConn returns a new connection reading from r and writing to w
+func NewLspConn(r io.Reader, w io.Writer) *LspConn {
+	return &LspConn{r: bufio.NewReader(r), w: w, pending: make(map[int64]chan *lspMsg)}
+}
+
+func (lc *LspConn) send(msg *lspMsg) error {
+	msg.JSONRPC = "2.0"
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	lc.wmu.Lock()
+	defer lc.wmu.Unlock()
+	return LspWriteMsg(lc.w, b)
+}
+
+// Call sends a request and waits for the response, which is decoded into
+// result (if non-nil)
+func (lc *LspConn) Call(method string, params, result interface{}) error {
+	pb, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	lc.mu.Lock()
+	if lc.closed {
+		lc.mu.Unlock()
+		return errors.New("gide.LspConn: connection closed")
+	}
+	lc.seq++
+	id := lc.seq
+	rc := make(chan *lspMsg, 1)
+	lc.pending[id] = rc
+	lc.mu.Unlock()
+
+	err = lc.send(&lspMsg{ID: &id, Method: method, Params: pb})
+	if err != nil {
+		lc.mu.Lock()
+		delete(lc.pending, id)
+		lc.mu.Unlock()
+		return err
+	}
+	select {
+	case resp := <-rc:
+		if resp == nil {
+			return errors.New("gide.LspConn: connection closed")
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			return json.Unmarshal(resp.Result, result)
+		}
+		return nil
+	case <-time.After(LspTimeout):
+		lc.mu.Lock()
+		delete(lc.pending, id)
+		lc.mu.Unlock()
+		return fmt.Errorf("gide.LspConn: timeout waiting for response to %v", method)
+	}
+}
+
+// SendNotify sends a notification, which has no response
+func (lc *LspConn) SendNotify(method string, params interface{}) error {
+	pb, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return lc.send(&lspMsg{Method: method, Params: pb})
+}
+
+// Run reads and dispatches incoming messages until the connection is closed
+// or an error occurs, which is returned
+func (lc *LspConn) Run() error {
+	defer lc.Close()
+	for {
+		body, err := LspReadMsg(lc.r)
+		if err != nil {
+			return err
+		}
+		msg := &lspMsg{}
+		if err := json.Unmarshal(body, msg); err != nil {
+			continue
+		}
+		switch {
+		case msg.ID != nil && msg.Method == "": // response
+			lc.mu.Lock()
+			rc, has := lc.pending[*msg.ID]
+			delete(lc.pending, *msg.ID)
+			lc.mu.Unlock()
+			if has {
+				rc <- msg
+			}
+		case msg.ID != nil: // request from server -- we don't support any, but must reply
+			lc.send(&lspMsg{ID: msg.ID, Result: json.RawMessage("null")})
+		default:
+			if lc.Notify != nil {
+				lc.Notify(msg.Method, msg.Params)
+			}
+		}
+	}
+}
+
+// Close closes the connection, failing any pending calls
+func (lc *LspConn) Close() {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.closed {
+		return
+	}
+	lc.closed = true
+	for id, rc := range lc.pending {
+		rc <- nil
+		delete(lc.pending, id)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestLspFraming(t *testing.T) {
+	var buf bytes.Buffer
+	msgs := []string{`{"a":1}`, `{"b":"two\nlines"}`}
+	for _, m := range msgs {
+		if err := LspWriteMsg(&buf, []byte(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := bufio.NewReader(&buf)
+	for _, m := range msgs {
+		b, err := LspReadMsg(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != m {
+			t.Errorf("read %q != %q", b, m)
+		}
+	}
+	if _, err := LspReadMsg(r); err != io.EOF {
+		t.Errorf("expected EOF, got: %v", err)
+	}
+}
+
+// fakeServer answers requests on the given pipe ends by echoing the params
+// back as the result, after first sending a notification
+func fakeServer(t *testing.T, in io.Reader, out io.Writer) {
+	r := bufio.NewReader(in)
+	for {
+		b, err := LspReadMsg(r)
+		if err != nil {
+			return
+		}
+		msg := lspMsg{}
+		if err := json.Unmarshal(b, &msg); err != nil {
+			t.Error(err)
+			return
+		}
+		if msg.ID == nil {
+			continue
+		}
+		nb, _ := json.Marshal(lspMsg{JSONRPC: "2.0", Method: "note", Params: msg.Params})
+		LspWriteMsg(out, nb)
+		var rb []byte
+		if msg.Method == "fail" {
+			rb, _ = json.Marshal(lspMsg{JSONRPC: "2.0", ID: msg.ID, Error: &LspError{Code: -32601, Message: "nope"}})
+		} else {
+			rb, _ = json.Marshal(lspMsg{JSONRPC: "2.0", ID: msg.ID, Result: msg.Params})
+		}
+		LspWriteMsg(out, rb)
+	}
+}
+
+func TestLspConn(t *testing.T) {
+	cr, sw := io.Pipe()
+	sr, cw := io.Pipe()
+	go fakeServer(t, sr, sw)
+	lc := NewLspConn(cr, cw)
+	notes := make(chan string, 10)
+	lc.Notify = func(method string, params json.RawMessage) {
+		notes <- method
+	}
+	go lc.Run()
+
+	pos := LspPosition{Line: 3, Character: 7}
+	res := LspPosition{}
+	if err := lc.Call("echo", pos, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res != pos {
+		t.Errorf("echo result: %v != %v", res, pos)
+	}
+	if nm := <-notes; nm != "note" {
+		t.Errorf("notification: %v", nm)
+	}
+	err := lc.Call("fail", pos, nil)
+	if le, ok := err.(*LspError); !ok || le.Code != -32601 {
+		t.Errorf("expected LspError, got: %v", err)
+	}
+	cw.Close()
+	sw.Close()
+}
+
+func TestLspURI(t *testing.T) {
+	uri := LspURI("/home/me/my proj/main.go")
+	if uri != "file:///home/me/my%20proj/main.go" {
+		t.Errorf("LspURI: %v", uri)
+	}
+	if fp := LspPath(uri); fp != "/home/me/my proj/main.go" {
+		t.Errorf("LspPath: %v", fp)
+	}
+}
+
+func TestLspDocText(t *testing.T) {
+	docs := []string{
+		`"plain"`,
+		`{"kind": "markdown", "value": "func Foo()"}`,
+		`[{"language": "go", "value": "func Foo()"}, "does foo"]`,
+	}
+	want := []string{"plain", "func Foo()", "func Foo()\n\ndoes foo"}
+	for i, ds := range docs {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(ds), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if dt := LspDocText(doc); dt != want[i] {
+			t.Errorf("LspDocText(%v) = %q, want %q", ds, dt, want[i])
+		}
+	}
+}
+
+func TestLspApplyEdits(t *testing.T) {
+	text := "package main\n\nfunc föo() {\n\tbar()\n}\n"
+	edits := []LspTextEdit{
+		{Range: LspRange{Start: LspPosition{2, 5}, End: LspPosition{2, 8}}, NewText: "baz"},
+		{Range: LspRange{Start: LspPosition{3, 1}, End: LspPosition{3, 4}}, NewText: "qux"},
+		{Range: LspRange{Start: LspPosition{0, 0}, End: LspPosition{0, 0}}, NewText: "// hi\n"},
+	}
+	want := "// hi\npackage main\n\nfunc baz() {\n\tqux()\n}\n"
+	if res := LspApplyEdits(text, edits); res != want {
+		t.Errorf("LspApplyEdits: got %q, want %q", res, want)
+	}
+}
+
+func TestLspDecodeSemanticTokens(t *testing.T) {
+	lg := &LspSemanticTokensLegend{TokenTypes: []string{"type", "function", "variable"}, TokenModifiers: []string{"declaration", "readonly"}}
+	data := []int{
+		1, 5, 3, 0, 1, // line 1, char 5: type, declaration
+		0, 4, 2, 1, 0, // line 1, char 9: function
+		2, 1, 4, 2, 3, // line 3, char 1: variable, declaration readonly
+	}
+	toks := LspDecodeSemanticTokens(data, lg)
+	want := []LspSemanticToken{
+		{Line: 1, Char: 5, Len: 3, Type: "type", Mods: []string{"declaration"}},
+		{Line: 1, Char: 9, Len: 2, Type: "function"},
+		{Line: 3, Char: 1, Len: 4, Type: "variable", Mods: []string{"declaration", "readonly"}},
+	}
+	if !reflect.DeepEqual(toks, want) {
+		t.Errorf("LspDecodeSemanticTokens: got %+v, want %+v", toks, want)
+	}
+}
+
+func TestLspCol(t *testing.T) {
+	line := []rune("a😀b€c")
+	cols := []int{0, 1, 3, 4, 5, 6, 7} // past the end too
+	for ch, col := range cols {
+		if got := LspCol(line, ch, true); got != col {
+			t.Errorf("LspCol(%d) = %d, want %d", ch, got, col)
+		}
+		if got := LspRuneCol(line, col, true); got != ch {
+			t.Errorf("LspRuneCol(%d) = %d, want %d", col, got, ch)
+		}
+		if got := LspCol(line, ch, false); got != ch {
+			t.Errorf("LspCol(%d) in runes = %d", ch, got)
+		}
+	}
+	if got := LspRuneCol(line, 2, true); got != 2 { // inside the pair
+		t.Errorf("LspRuneCol in a surrogate pair = %d, want 2", got)
+	}
+}
+
+func TestLspEditChange(t *testing.T) {
+	line := []rune("x😀y")
+	ins := LspEditChange(line, 3, 2, false, []byte("ab"), true)
+	if want := (LspRange{Start: LspPosition{3, 3}, End: LspPosition{3, 3}}); *ins.Range != want || ins.Text != "ab" {
+		t.Errorf("insert change = %+v %q", *ins.Range, ins.Text)
+	}
+	del := LspEditChange(line, 3, 1, true, []byte("😀z"), true)
+	if want := (LspRange{Start: LspPosition{3, 1}, End: LspPosition{3, 4}}); *del.Range != want || del.Text != "" {
+		t.Errorf("delete change = %+v %q", *del.Range, del.Text)
+	}
+	del = LspEditChange(line, 3, 1, true, []byte("a\nbc\n😀d"), true)
+	if want := (LspRange{Start: LspPosition{3, 1}, End: LspPosition{5, 3}}); *del.Range != want {
+		t.Errorf("multi-line delete change = %+v", *del.Range)
+	}
+}
@@ -0,0 +1,849 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LangServer configures the language server (LSP) to use for a language
+type LangServer struct {
+	Lang   LangName   `desc:"language that this server handles"`
+	Cmd    string     `desc:"command to run the server, including any args -- it must talk the LSP protocol on stdin / stdout"`
+	ID     string     `desc:"LSP language identifier, e.g., go -- defaults to lower-case language name"`
+	Limits ProcLimits `view:"inline" desc:"resource limits for the server, e.g., a niceness or memory limit so indexing a big project can not starve the editor or the machine -- applied where the OS supports them"`
+}
+
+// LangServers is a list of language servers
+type LangServers []LangServer
+
+// StdLangServers are the standard language servers
+var StdLangServers = LangServers{
+	{Lang: "Go", Cmd: "gopls", ID: "go"},
+}
+
+// ServerForLang returns the language server config for given language, if any
+func (ls LangServers) ServerForLang(lang LangName) (*LangServer, bool) {
+	for i := range ls {
+		if ls[i].Lang == lang {
+			return &ls[i], true
+		}
+	}
+	return nil, false
+}
+
+// LanguageID returns the LSP language identifier
+func (ls *LangServer) LanguageID() string {
+	if ls.ID != "" {
+		return ls.ID
+	}
+	return strings.ToLower(string(ls.Lang))
+}
+
+// LspClient is a client for one running language server, for one project
+type LspClient struct {
+	Server    LangServer                              `desc:"server config"`
+	Root      string                                  `desc:"root directory of the project"`
+	Conn      *LspConn                                `desc:"connection to the server"`
+	Exec      *exec.Cmd                               `desc:"the server process"`
+	Caps      map[string]interface{}                  `desc:"server capabilities, as returned from initialize"`
+	Docs      map[string]int                          `desc:"open documents, by uri, with current version"`
+	Diags     map[string][]LspDiagnostic              `desc:"latest diagnostics, by uri, as sent by the server"`
+	DiagFunc  func(uri string, diags []LspDiagnostic) `desc:"function called, on the goroutine of the connection, when diagnostics are published for a document -- they are as sent by the server: use Diagnostics for them in runes"`
+	LinesFunc func(fpath string) [][]rune             `desc:"function returning the current lines of given file, for converting the characters of positions to and from UTF-16 -- nil reads the file"`
+	UTF16     bool                                    `desc:"true if the server counts the characters of positions in UTF-16 code units, as the spec has it by default, instead of in runes"`
+	Pending   map[string]bool                         `desc:"documents, by uri, with changes not yet sent to a server that only takes their full text"`
+	Sent      map[string]time.Time                    `desc:"when the full text of each document, by uri, was last sent"`
+	Mu        sync.Mutex                              `desc:"mutex protecting docs, diags and the funcs"`
+}
+
+// StartLspClient starts the given language server for project at given root
+// directory, and initializes it
+func StartLspClient(srv LangServer, root string) (*LspClient, error) {
+	args := strings.Fields(srv.Cmd)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("gide.StartLspClient: no command for language: %v", srv.Lang)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = root
+	if env := OfflineEnv(); len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd = srv.Limits.Apply(cmd)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	lc := &LspClient{Server: srv, Root: root, Exec: cmd}
+	lc.Docs = make(map[string]int)
+	lc.Diags = make(map[string][]LspDiagnostic)
+	lc.Pending = make(map[string]bool)
+	lc.Sent = make(map[string]time.Time)
+	lc.Conn = NewLspConn(stdout, stdin)
+	lc.Conn.Notify = lc.HandleNotify
+	go func() {
+		lc.Conn.Run()
+		cmd.Wait()
+	}()
+	if err = lc.Initialize(); err != nil {
+		lc.Kill()
+		return nil, err
+	}
+	return lc, nil
+}
+
+// Initialize does the initialize handshake with the server
+func (lc *LspClient) Initialize() error {
+	params := map[string]interface{}{
+		"processId": os.Getpid(),
+		"rootUri":   LspURI(lc.Root),
+		"capabilities": map[string]interface{}{
+			"general": map[string]interface{}{
+				"positionEncodings": []string{"utf-32", "utf-16"},
+			},
+			"workspace": map[string]interface{}{
+				"symbol": map[string]interface{}{},
+			},
+			"textDocument": map[string]interface{}{
+				"synchronization":    map[string]interface{}{"didSave": true},
+				"completion":         map[string]interface{}{"completionItem": map[string]interface{}{"snippetSupport": false}},
+				"hover":              map[string]interface{}{"contentFormat": []string{"plaintext", "markdown"}},
+				"signatureHelp":      map[string]interface{}{},
+				"definition":         map[string]interface{}{},
+				"references":         map[string]interface{}{},
+				"rename":             map[string]interface{}{},
+				"documentSymbol":     map[string]interface{}{"hierarchicalDocumentSymbolSupport": true},
+				"publishDiagnostics": map[string]interface{}{},
+				"semanticTokens": map[string]interface{}{
+					"requests":       map[string]interface{}{"full": true},
+					"tokenTypes":     LspSemanticTokenTypes,
+					"tokenModifiers": LspSemanticTokenModifiers,
+					"formats":        []string{"relative"},
+				},
+			},
+		},
+	}
+	res := struct {
+		Capabilities map[string]interface{} `json:"capabilities"`
+	}{}
+	if err := lc.Conn.Call("initialize", params, &res); err != nil {
+		return err
+	}
+	lc.Caps = res.Capabilities
+	lc.UTF16 = lc.Caps["positionEncoding"] != "utf-32"
+	return lc.Conn.SendNotify("initialized", struct{}{})
+}
+
+// Shutdown politely shuts down the server
+func (lc *LspClient) Shutdown() {
+	lc.Conn.Call("shutdown", nil, nil)
+	lc.Conn.SendNotify("exit", nil)
+	lc.Conn.Close()
+}
+
+// Kill kills the server process
+func (lc *LspClient) Kill() {
+	lc.Conn.Close()
+	if lc.Exec.Process != nil {
+		lc.Exec.Process.Kill()
+	}
+}
+
+// HandleNotify handles notifications from the server
+func (lc *LspClient) HandleNotify(method string, params json.RawMessage) {
+	switch method {
+	case "textDocument/publishDiagnostics":
+		pd := LspPublishDiagnosticsParams{}
+		if err := json.Unmarshal(params, &pd); err != nil {
+			log.Printf("gide.LspClient: bad diagnostics: %v\n", err)
+			return
+		}
+		lc.Mu.Lock()
+		lc.Diags[pd.URI] = pd.Diagnostics
+		df := lc.DiagFunc
+		lc.Mu.Unlock()
+		if df != nil {
+			df(pd.URI, pd.Diagnostics)
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//   Document sync
+
+// IsOpen returns true if given file is open in the server
+func (lc *LspClient) IsOpen(fpath string) bool {
+	lc.Mu.Lock()
+	defer lc.Mu.Unlock()
+	_, has := lc.Docs[LspURI(fpath)]
+	return has
+}
+
+// DidOpen tells the server that given file is open, with given text
+func (lc *LspClient) DidOpen(fpath string, text string) error {
+	uri := LspURI(fpath)
+	lc.Mu.Lock()
+	lc.Docs[uri] = 1
+	delete(lc.Pending, uri)
+	lc.Sent[uri] = time.Now()
+	lc.Mu.Unlock()
+	return lc.Conn.SendNotify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": LspTextDocumentItem{URI: uri, LanguageID: lc.Server.LanguageID(), Version: 1, Text: text},
+	})
+}
+
+// SyncKind returns how the server wants changes to documents: 0 not at
+// all, 1 as their full text, 2 as incremental edits
+func (lc *LspClient) SyncKind() int {
+	sync := lc.Caps["textDocumentSync"]
+	if opts, ok := sync.(map[string]interface{}); ok {
+		sync = opts["change"]
+	}
+	if k, ok := sync.(float64); ok {
+		return int(k)
+	}
+	return 0
+}
+
+// DidChange sends the full new text of given file to the server -- opens
+// it if not already open
+func (lc *LspClient) DidChange(fpath string, text string) error {
+	uri := LspURI(fpath)
+	lc.Mu.Lock()
+	ver, has := lc.Docs[uri]
+	if has {
+		ver++
+		lc.Docs[uri] = ver
+		delete(lc.Pending, uri)
+		lc.Sent[uri] = time.Now()
+	}
+	lc.Mu.Unlock()
+	if !has {
+		return lc.DidOpen(fpath, text)
+	}
+	return lc.Conn.SendNotify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   LspVersionedTextDocumentIdentifier{URI: uri, Version: ver},
+		"contentChanges": []LspTextDocumentContentChangeEvent{{Text: text}},
+	})
+}
+
+// DidChangeEdits sends given incremental changes of given open file to the
+// server, which must take them (SyncKind 2)
+func (lc *LspClient) DidChangeEdits(fpath string, changes ...LspTextDocumentContentChangeEvent) error {
+	uri := LspURI(fpath)
+	lc.Mu.Lock()
+	ver, has := lc.Docs[uri]
+	if has {
+		ver++
+		lc.Docs[uri] = ver
+	}
+	lc.Mu.Unlock()
+	if !has {
+		return fmt.Errorf("gide.LspClient.DidChangeEdits: %v is not open", fpath)
+	}
+	return lc.Conn.SendNotify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   LspVersionedTextDocumentIdentifier{URI: uri, Version: ver},
+		"contentChanges": changes,
+	})
+}
+
+// LspSyncDelay is the least time between sending the full text of a changed
+// document to a server that does not take incremental changes -- a change
+// within it is sent with the next one after it, or with the next request
+// or save
+var LspSyncDelay = 500 * time.Millisecond
+
+// HoldChange records that given file has changed, and returns true if its
+// full text should not be sent yet, because it was sent less than
+// LspSyncDelay ago -- it is then pending until DidChange
+func (lc *LspClient) HoldChange(fpath string) bool {
+	uri := LspURI(fpath)
+	lc.Mu.Lock()
+	defer lc.Mu.Unlock()
+	if time.Since(lc.Sent[uri]) < LspSyncDelay {
+		lc.Pending[uri] = true
+		return true
+	}
+	return false
+}
+
+// IsPending returns true if given file has changes that have not been sent
+// to the server yet
+func (lc *LspClient) IsPending(fpath string) bool {
+	lc.Mu.Lock()
+	defer lc.Mu.Unlock()
+	return lc.Pending[LspURI(fpath)]
+}
+
+// DidSave tells the server that given file was saved
+func (lc *LspClient) DidSave(fpath string) error {
+	return lc.Conn.SendNotify("textDocument/didSave", map[string]interface{}{
+		"textDocument": LspTextDocumentIdentifier{URI: LspURI(fpath)},
+	})
+}
+
+// DidClose tells the server that given file was closed
+func (lc *LspClient) DidClose(fpath string) error {
+	uri := LspURI(fpath)
+	lc.Mu.Lock()
+	delete(lc.Docs, uri)
+	delete(lc.Diags, uri)
+	delete(lc.Pending, uri)
+	delete(lc.Sent, uri)
+	lc.Mu.Unlock()
+	return lc.Conn.SendNotify("textDocument/didClose", map[string]interface{}{
+		"textDocument": LspTextDocumentIdentifier{URI: uri},
+	})
+}
+
+// Diagnostics returns the latest diagnostics for given file
+func (lc *LspClient) Diagnostics(fpath string) []LspDiagnostic {
+	uri := LspURI(fpath)
+	lc.Mu.Lock()
+	diags := lc.Diags[uri]
+	lc.Mu.Unlock()
+	return lc.Cols().Diags(uri, diags)
+}
+
+// AllDiagnostics returns the latest diagnostics for all files, by uri
+func (lc *LspClient) AllDiagnostics() map[string][]LspDiagnostic {
+	lc.Mu.Lock()
+	all := make(map[string][]LspDiagnostic, len(lc.Diags))
+	for uri, dgs := range lc.Diags {
+		all[uri] = dgs
+	}
+	lc.Mu.Unlock()
+	cv := lc.Cols()
+	for uri, dgs := range all {
+		all[uri] = cv.Diags(uri, dgs)
+	}
+	return all
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//   Positions
+
+// LspFileLines returns the lines of given file, as runes -- nil if it can
+// not be read
+func LspFileLines(fpath string) [][]rune {
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil
+	}
+	lns := strings.Split(string(b), "\n")
+	rs := make([][]rune, len(lns))
+	for i, l := range lns {
+		rs[i] = []rune(l)
+	}
+	return rs
+}
+
+// LspCols converts the characters of positions between the rune columns of
+// Gide and the characters of the server, with the lines of the files that
+// were needed so far -- it is for the positions of one request or response
+type LspCols struct {
+	LC    *LspClient
+	Lines map[string][][]rune
+}
+
+// Cols returns a new converter of the characters of positions for the server
+func (lc *LspClient) Cols() *LspCols {
+	return &LspCols{LC: lc}
+}
+
+// Line returns given line of the file with given uri -- nil if not known
+func (cv *LspCols) Line(uri string, ln int) []rune {
+	lns, has := cv.Lines[uri]
+	if !has {
+		fpath := LspPath(uri)
+		cv.LC.Mu.Lock()
+		lf := cv.LC.LinesFunc
+		cv.LC.Mu.Unlock()
+		if lf != nil {
+			lns = lf(fpath)
+		} else {
+			lns = LspFileLines(fpath)
+		}
+		if cv.Lines == nil {
+			cv.Lines = make(map[string][][]rune)
+		}
+		cv.Lines[uri] = lns
+	}
+	if ln < 0 || ln >= len(lns) {
+		return nil
+	}
+	return lns[ln]
+}
+
+// Pos returns the position for the server of given rune position in the file
+// with given uri
+func (cv *LspCols) Pos(uri string, ln, ch int) LspPosition {
+	if cv.LC.UTF16 {
+		ch = LspCol(cv.Line(uri, ln), ch, true)
+	}
+	return LspPosition{Line: ln, Character: ch}
+}
+
+// Range returns the range for the server of given range in runes
+func (cv *LspCols) Range(uri string, rng LspRange) LspRange {
+	return LspRange{Start: cv.Pos(uri, rng.Start.Line, rng.Start.Character), End: cv.Pos(uri, rng.End.Line, rng.End.Character)}
+}
+
+// RunePos returns the rune position of given position from the server
+func (cv *LspCols) RunePos(uri string, pos LspPosition) LspPosition {
+	if cv.LC.UTF16 {
+		pos.Character = LspRuneCol(cv.Line(uri, pos.Line), pos.Character, true)
+	}
+	return pos
+}
+
+// RuneRange returns the range in runes of given range from the server
+func (cv *LspCols) RuneRange(uri string, rng LspRange) LspRange {
+	return LspRange{Start: cv.RunePos(uri, rng.Start), End: cv.RunePos(uri, rng.End)}
+}
+
+// Locs converts given locations from the server to runes, in place
+func (cv *LspCols) Locs(locs []LspLocation) []LspLocation {
+	for i := range locs {
+		locs[i].Range = cv.RuneRange(locs[i].URI, locs[i].Range)
+	}
+	return locs
+}
+
+// Edits converts given edits of the file with given uri from the server to
+// runes, in place
+func (cv *LspCols) Edits(uri string, eds []LspTextEdit) []LspTextEdit {
+	for i := range eds {
+		eds[i].Range = cv.RuneRange(uri, eds[i].Range)
+	}
+	return eds
+}
+
+// WorkspaceEdit converts given edits from the server to runes, in place
+func (cv *LspCols) WorkspaceEdit(we *LspWorkspaceEdit) {
+	if we == nil {
+		return
+	}
+	for uri, eds := range we.Changes {
+		cv.Edits(uri, eds)
+	}
+}
+
+// Diags returns given diagnostics of the file with given uri from the
+// server converted to runes -- they are copied, as they are kept as sent
+func (cv *LspCols) Diags(uri string, diags []LspDiagnostic) []LspDiagnostic {
+	if !cv.LC.UTF16 || len(diags) == 0 {
+		return diags
+	}
+	cd := make([]LspDiagnostic, len(diags))
+	copy(cd, diags)
+	for i := range cd {
+		dg := &cd[i]
+		dg.Range = cv.RuneRange(uri, dg.Range)
+		if len(dg.Related) > 0 {
+			rel := make([]LspDiagnosticRelatedInformation, len(dg.Related))
+			copy(rel, dg.Related)
+			for j := range rel {
+				rel[j].Location.Range = cv.RuneRange(rel[j].Location.URI, rel[j].Location.Range)
+			}
+			dg.Related = rel
+		}
+	}
+	return cd
+}
+
+// Symbols converts given tree of symbols of the file with given uri from the
+// server to runes, in place
+func (cv *LspCols) Symbols(uri string, syms []LspDocumentSymbol) []LspDocumentSymbol {
+	for i := range syms {
+		syms[i].Range = cv.RuneRange(uri, syms[i].Range)
+		syms[i].SelectionRange = cv.RuneRange(uri, syms[i].SelectionRange)
+		cv.Symbols(uri, syms[i].Children)
+	}
+	return syms
+}
+
+// Tokens converts the chars and lengths of given semantic tokens of the file
+// with given uri from the server to runes, in place
+func (cv *LspCols) Tokens(uri string, toks []LspSemanticToken) []LspSemanticToken {
+	if !cv.LC.UTF16 {
+		return toks
+	}
+	for i := range toks {
+		tk := &toks[i]
+		line := cv.Line(uri, tk.Line)
+		st := LspRuneCol(line, tk.Char, true)
+		tk.Len = LspRuneCol(line, tk.Char+tk.Len, true) - st
+		tk.Char = st
+	}
+	return toks
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//   Features
+
+// posParams returns the params for a request at given rune position
+func (lc *LspClient) posParams(fpath string, ln, ch int) LspTextDocumentPositionParams {
+	uri := LspURI(fpath)
+	return LspTextDocumentPositionParams{TextDocument: LspTextDocumentIdentifier{URI: uri}, Position: lc.Cols().Pos(uri, ln, ch)}
+}
+
+// Completion returns completion items at given 0-based line, char position
+func (lc *LspClient) Completion(fpath string, ln, ch int) ([]LspCompletionItem, error) {
+	var raw json.RawMessage
+	if err := lc.Conn.Call("textDocument/completion", lc.posParams(fpath, ln, ch), &raw); err != nil {
+		return nil, err
+	}
+	// result can be either a list or just the items
+	var items []LspCompletionItem
+	cl := LspCompletionList{}
+	err := json.Unmarshal(raw, &cl)
+	if err == nil && cl.Items != nil {
+		items = cl.Items
+	} else {
+		err = json.Unmarshal(raw, &items)
+	}
+	uri := LspURI(fpath)
+	cv := lc.Cols()
+	for i := range items {
+		if te := items[i].TextEdit; te != nil {
+			te.Range = cv.RuneRange(uri, te.Range)
+		}
+	}
+	return items, err
+}
+
+// Hover returns the hover documentation at given position
+func (lc *LspClient) Hover(fpath string, ln, ch int) (*LspHover, error) {
+	hv := &LspHover{}
+	if err := lc.Conn.Call("textDocument/hover", lc.posParams(fpath, ln, ch), hv); err != nil {
+		return nil, err
+	}
+	if hv.Range != nil {
+		rng := lc.Cols().RuneRange(LspURI(fpath), *hv.Range)
+		hv.Range = &rng
+	}
+	return hv, nil
+}
+
+// SignatureHelp returns the signature help at given position
+func (lc *LspClient) SignatureHelp(fpath string, ln, ch int) (*LspSignatureHelp, error) {
+	sh := &LspSignatureHelp{}
+	if err := lc.Conn.Call("textDocument/signatureHelp", lc.posParams(fpath, ln, ch), sh); err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+// lspLocations decodes a location result, which can be a single location or
+// a list, with its ranges in runes
+func (lc *LspClient) lspLocations(raw json.RawMessage) ([]LspLocation, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var locs []LspLocation
+	if err := json.Unmarshal(raw, &locs); err == nil {
+		return lc.Cols().Locs(locs), nil
+	}
+	loc := LspLocation{}
+	err := json.Unmarshal(raw, &loc)
+	return lc.Cols().Locs([]LspLocation{loc}), err
+}
+
+// Definition returns the location(s) of the definition of the symbol at given position
+func (lc *LspClient) Definition(fpath string, ln, ch int) ([]LspLocation, error) {
+	var raw json.RawMessage
+	if err := lc.Conn.Call("textDocument/definition", lc.posParams(fpath, ln, ch), &raw); err != nil {
+		return nil, err
+	}
+	return lc.lspLocations(raw)
+}
+
+// References returns the locations of all references to the symbol at given
+// position, including its declaration
+func (lc *LspClient) References(fpath string, ln, ch int) ([]LspLocation, error) {
+	uri := LspURI(fpath)
+	params := map[string]interface{}{
+		"textDocument": LspTextDocumentIdentifier{URI: uri},
+		"position":     lc.Cols().Pos(uri, ln, ch),
+		"context":      map[string]bool{"includeDeclaration": true},
+	}
+	var raw json.RawMessage
+	if err := lc.Conn.Call("textDocument/references", params, &raw); err != nil {
+		return nil, err
+	}
+	return lc.lspLocations(raw)
+}
+
+// Rename returns the edits needed to rename the symbol at given position to newName
+func (lc *LspClient) Rename(fpath string, ln, ch int, newName string) (*LspWorkspaceEdit, error) {
+	uri := LspURI(fpath)
+	cv := lc.Cols()
+	params := map[string]interface{}{
+		"textDocument": LspTextDocumentIdentifier{URI: uri},
+		"position":     cv.Pos(uri, ln, ch),
+		"newName":      newName,
+	}
+	we := &LspWorkspaceEdit{}
+	if err := lc.Conn.Call("textDocument/rename", params, we); err != nil {
+		return nil, err
+	}
+	cv.WorkspaceEdit(we)
+	return we, nil
+}
+
+// CodeActions returns the actions with edits (e.g., quick fixes) available
+// for given range, for the given diagnostics within it
+func (lc *LspClient) CodeActions(fpath string, rng LspRange, diags []LspDiagnostic) ([]LspCodeAction, error) {
+	uri := LspURI(fpath)
+	cv := lc.Cols()
+	sdiags := make([]LspDiagnostic, len(diags))
+	copy(sdiags, diags)
+	for i := range sdiags {
+		sdiags[i].Range = cv.Range(uri, sdiags[i].Range)
+	}
+	params := map[string]interface{}{
+		"textDocument": LspTextDocumentIdentifier{URI: uri},
+		"range":        cv.Range(uri, rng),
+		"context":      map[string]interface{}{"diagnostics": sdiags},
+	}
+	var cas []LspCodeAction
+	if err := lc.Conn.Call("textDocument/codeAction", params, &cas); err != nil {
+		return nil, err
+	}
+	res := cas[:0]
+	for _, ca := range cas {
+		if ca.Edit != nil && len(ca.Edit.Changes) > 0 {
+			cv.WorkspaceEdit(ca.Edit)
+			res = append(res, ca)
+		}
+	}
+	return res, nil
+}
+
+// DocumentSymbols returns the tree of symbols in given file -- servers that
+// only return a flat list of symbols have it converted to a tree using the
+// name of the container of each symbol
+func (lc *LspClient) DocumentSymbols(fpath string) ([]LspDocumentSymbol, error) {
+	uri := LspURI(fpath)
+	params := map[string]interface{}{
+		"textDocument": LspTextDocumentIdentifier{URI: uri},
+	}
+	var raw []json.RawMessage
+	if err := lc.Conn.Call("textDocument/documentSymbol", params, &raw); err != nil {
+		return nil, err
+	}
+	cv := lc.Cols()
+	var syms []LspDocumentSymbol
+	var infos []LspSymbolInformation
+	for _, r := range raw {
+		var si LspSymbolInformation
+		if err := json.Unmarshal(r, &si); err == nil && si.Location.URI != "" {
+			si.Location.Range = cv.RuneRange(si.Location.URI, si.Location.Range)
+			infos = append(infos, si)
+			continue
+		}
+		var ds LspDocumentSymbol
+		if err := json.Unmarshal(r, &ds); err != nil {
+			return nil, err
+		}
+		syms = append(syms, cv.Symbols(uri, []LspDocumentSymbol{ds})...)
+	}
+	return append(syms, LspSymbolTree(infos)...), nil
+}
+
+// WorkspaceSymbols returns the symbols in the whole workspace that match
+// given query, as the server sees fit, e.g., fuzzily
+func (lc *LspClient) WorkspaceSymbols(query string) ([]LspSymbolInformation, error) {
+	var syms []LspSymbolInformation
+	if err := lc.Conn.Call("workspace/symbol", map[string]string{"query": query}, &syms); err != nil {
+		return nil, err
+	}
+	cv := lc.Cols()
+	for i := range syms {
+		syms[i].Location.Range = cv.RuneRange(syms[i].Location.URI, syms[i].Location.Range)
+	}
+	return syms, nil
+}
+
+// SemanticLegend returns the legend for the semantic tokens of the server,
+// from its capabilities -- false if it does not provide semantic tokens
+func (lc *LspClient) SemanticLegend() (*LspSemanticTokensLegend, bool) {
+	prov, ok := lc.Caps["semanticTokensProvider"].(map[string]interface{})
+	if !ok || prov["full"] == nil || prov["full"] == false {
+		return nil, false
+	}
+	b, err := json.Marshal(prov["legend"])
+	if err != nil {
+		return nil, false
+	}
+	lg := &LspSemanticTokensLegend{}
+	if err := json.Unmarshal(b, lg); err != nil || len(lg.TokenTypes) == 0 {
+		return nil, false
+	}
+	return lg, true
+}
+
+// SemanticTokens returns the semantic tokens for the whole of given file,
+// decoded using the legend of the server
+func (lc *LspClient) SemanticTokens(fpath string) ([]LspSemanticToken, error) {
+	lg, ok := lc.SemanticLegend()
+	if !ok {
+		return nil, fmt.Errorf("%v does not provide semantic tokens", lc.Server.Cmd)
+	}
+	uri := LspURI(fpath)
+	params := map[string]interface{}{
+		"textDocument": LspTextDocumentIdentifier{URI: uri},
+	}
+	st := &LspSemanticTokens{}
+	if err := lc.Conn.Call("textDocument/semanticTokens/full", params, st); err != nil {
+		return nil, err
+	}
+	return lc.Cols().Tokens(uri, LspDecodeSemanticTokens(st.Data, lg)), nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//   LspClients
+
+// LspClients are the running language server clients for a project, by language
+type LspClients struct {
+	Clients   map[LangName]*LspClient                 `desc:"running clients"`
+	Failed    map[LangName]bool                       `desc:"languages whose servers failed to start -- not retried"`
+	Starting  map[LangName]int                        `desc:"languages whose servers are being started, with the number of the start"`
+	DiagFunc  func(uri string, diags []LspDiagnostic) `desc:"DiagFunc of the clients as they are started"`
+	LinesFunc func(fpath string) [][]rune             `desc:"LinesFunc of the clients as they are started"`
+	StartFunc func(lang LangName)                     `desc:"function called, on the goroutine that started it, when the server for a language has started and can be used"`
+	NStarts   int                                     `desc:"number of starts so far, to tell them apart"`
+	Mu        sync.Mutex                              `desc:"mutex protecting the maps"`
+}
+
+// ClientForFile returns the client for the language of given file, starting
+// the server if needed -- returns nil if there is no server for it, or if it
+// is still starting: servers are started in the background, as they can
+// take a long time to start up (or not at all), and StartFunc is called
+// when one is ready
+func (lcs *LspClients) ClientForFile(fpath string, root string) *LspClient {
+	ls := LangsForFilename(fpath)
+	if len(ls) == 0 {
+		return nil
+	}
+	lang := LangName(ls[0].Name)
+	srv, has := Prefs.LangServers.ServerForLang(lang)
+	if !has || srv.Cmd == "" {
+		return nil
+	}
+	lcs.Mu.Lock()
+	defer lcs.Mu.Unlock()
+	if lcs.Clients == nil {
+		lcs.Clients = make(map[LangName]*LspClient)
+	}
+	if lcs.Failed == nil {
+		lcs.Failed = make(map[LangName]bool)
+	}
+	if lcs.Starting == nil {
+		lcs.Starting = make(map[LangName]int)
+	}
+	if lc, has := lcs.Clients[lang]; has {
+		return lc
+	}
+	if _, starting := lcs.Starting[lang]; starting || lcs.Failed[lang] {
+		return nil
+	}
+	lcs.NStarts++
+	lcs.Starting[lang] = lcs.NStarts
+	go lcs.Start(lang, *srv, root, lcs.NStarts)
+	return nil
+}
+
+// Start starts the server for given language, for given start number, and
+// records its client -- it does not hold the lock while the server starts,
+// and drops the client if the start was cancelled by Kill, Restart or
+// ShutdownAll in the meantime
+func (lcs *LspClients) Start(lang LangName, srv LangServer, root string, start int) {
+	lc, err := StartLspClient(srv, root)
+	lcs.Mu.Lock()
+	if lcs.Starting[lang] != start {
+		lcs.Mu.Unlock()
+		if lc != nil {
+			lc.Kill()
+		}
+		return
+	}
+	delete(lcs.Starting, lang)
+	if err != nil {
+		log.Printf("gide.LspClients: could not start language server %v for %v: %v\n", srv.Cmd, lang, err)
+		lcs.Failed[lang] = true
+		lcs.Mu.Unlock()
+		return
+	}
+	lc.Mu.Lock()
+	lc.DiagFunc = lcs.DiagFunc
+	lc.LinesFunc = lcs.LinesFunc
+	lc.Mu.Unlock()
+	lcs.Clients[lang] = lc
+	sf := lcs.StartFunc
+	lcs.Mu.Unlock()
+	if sf != nil {
+		sf(lang)
+	}
+}
+
+// Kill kills the running server for given language, if any, and does not
+// start it again until Restart is called
+func (lcs *LspClients) Kill(lang LangName) {
+	lcs.Mu.Lock()
+	defer lcs.Mu.Unlock()
+	if lc, has := lcs.Clients[lang]; has {
+		lc.Kill()
+		delete(lcs.Clients, lang)
+	}
+	delete(lcs.Starting, lang)
+	if lcs.Failed == nil {
+		lcs.Failed = make(map[LangName]bool)
+	}
+	lcs.Failed[lang] = true
+}
+
+// Restart kills the running server for given language, if any, so that it
+// is started again the next time it is needed, even if it failed before
+func (lcs *LspClients) Restart(lang LangName) {
+	lcs.Mu.Lock()
+	defer lcs.Mu.Unlock()
+	if lc, has := lcs.Clients[lang]; has {
+		lc.Kill()
+		delete(lcs.Clients, lang)
+	}
+	delete(lcs.Starting, lang)
+	delete(lcs.Failed, lang)
+}
+
+// ShutdownAll shuts down all the running servers, and drops any that are
+// still starting
+func (lcs *LspClients) ShutdownAll() {
+	lcs.Mu.Lock()
+	defer lcs.Mu.Unlock()
+	for lang, lc := range lcs.Clients {
+		lc.Shutdown()
+		delete(lcs.Clients, lang)
+	}
+	lcs.Starting = nil
+	lcs.Failed = nil
+}
@@ -0,0 +1,572 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// MarkdownDelay is how long after an edit the Markdown preview is rendered
+// again
+var MarkdownDelay = 300 * time.Millisecond
+
+// MarkdownImageMax is the widest that images are shown in the Markdown
+// preview, in pixels -- wider ones are scaled down
+var MarkdownImageMax = float32(640)
+
+// MdBlock is a block of a rendered Markdown file -- a heading, paragraph,
+// code block etc -- with the lines of the source it comes from, so that the
+// preview can be scrolled along with the source
+type MdBlock struct {
+	Kind   string   `desc:"heading, para, code, rule, table or html"`
+	Level  int      `desc:"level of a heading, 1 to 6"`
+	Indent int      `desc:"how deep the block is within lists and block quotes"`
+	Quote  bool     `desc:"block is within a block quote"`
+	Ln     int      `desc:"first line of the source, 0-based"`
+	EdLn   int      `desc:"last line of the source"`
+	Text   string   `desc:"rich text markup of the block, or the plain text of code, tables and html"`
+	Images []string `desc:"images in the block, as resolved by MdImagePath -- remote ones are left as links"`
+}
+
+// MdImagePath returns the file path of the image at given destination in a
+// Markdown file in given directory: relative paths are relative to the
+// directory, and absolute ones to the project root -- returns "" for remote
+// images, which are not fetched
+func MdImagePath(dest, dir, root string) string {
+	if dest == "" {
+		return ""
+	}
+	if u, err := url.Parse(dest); err == nil && u.Scheme != "" && len(u.Scheme) > 1 { // not a drive letter
+		if u.Scheme != "file" {
+			return ""
+		}
+		return filepath.FromSlash(u.Path)
+	}
+	if i := strings.IndexAny(dest, "?#"); i >= 0 {
+		dest = dest[:i]
+	}
+	if p, err := url.PathUnescape(dest); err == nil {
+		dest = p
+	}
+	dest = filepath.FromSlash(dest)
+	switch {
+	case strings.HasPrefix(dest, string(filepath.Separator)):
+		return filepath.Join(root, dest)
+	case filepath.IsAbs(dest):
+		return dest
+	}
+	return filepath.Join(dir, dest)
+}
+
+// MdBlockAt returns the index of the block of given blocks that shows given
+// line of the source -- the last one starting at or before it -- or -1 if
+// there are none
+func MdBlockAt(blocks []MdBlock, ln int) int {
+	bi := -1
+	for i, b := range blocks {
+		if b.Ln > ln {
+			break
+		}
+		bi = i
+	}
+	if bi < 0 && len(blocks) > 0 {
+		bi = 0
+	}
+	return bi
+}
+
+// MdLineOf returns the 0-based line of given byte offset in src
+func MdLineOf(src []byte, off int) int {
+	if off > len(src) {
+		off = len(src)
+	}
+	return bytes.Count(src[:off], []byte("\n"))
+}
+
+// mdLines returns the first and last source lines of given node, from the
+// lines of it or its first and last descendants that have any, or false if
+// none do
+func mdLines(n ast.Node, src []byte) (int, int, bool) {
+	if n.Type() == ast.TypeBlock {
+		if ls := n.Lines(); ls != nil && ls.Len() > 0 {
+			st := ls.At(0).Start
+			ed := ls.At(ls.Len() - 1).Stop
+			if ed > st {
+				ed-- // line end
+			}
+			return MdLineOf(src, st), MdLineOf(src, ed), true
+		}
+	}
+	st, ed, ok := -1, -1, false
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		cs, ce, cok := mdLines(c, src)
+		if !cok {
+			continue
+		}
+		if !ok {
+			st = cs
+		}
+		ed = ce
+		ok = true
+	}
+	return st, ed, ok
+}
+
+// mdRaw returns the source lines of given block, e.g., of code
+func mdRaw(n ast.Node, src []byte) string {
+	var b strings.Builder
+	ls := n.Lines()
+	for i := 0; i < ls.Len(); i++ {
+		sg := ls.At(i)
+		b.Write(sg.Value(src))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// mdInline returns the rich text markup of the inline kids of given node,
+// adding the images in it to imgs, resolved by MdImagePath
+func mdInline(n ast.Node, src []byte, dir, root string, imgs *[]string) string {
+	var b strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			b.WriteString(html.EscapeString(string(c.Segment.Value(src))))
+			switch {
+			case c.HardLineBreak():
+				b.WriteString("<br>")
+			case c.SoftLineBreak():
+				b.WriteString(" ")
+			}
+		case *ast.String:
+			b.WriteString(html.EscapeString(string(c.Value)))
+		case *ast.CodeSpan:
+			b.WriteString("<code>" + html.EscapeString(string(c.Text(src))) + "</code>")
+		case *ast.Emphasis:
+			tag := "i"
+			if c.Level > 1 {
+				tag = "b"
+			}
+			b.WriteString("<" + tag + ">" + mdInline(c, src, dir, root, imgs) + "</" + tag + ">")
+		case *east.Strikethrough:
+			b.WriteString("<s>" + mdInline(c, src, dir, root, imgs) + "</s>")
+		case *ast.Link:
+			b.WriteString(fmt.Sprintf(`<a href="%v">%v</a>`, html.EscapeString(string(c.Destination)), mdInline(c, src, dir, root, imgs)))
+		case *ast.AutoLink:
+			ur := string(c.URL(src))
+			if c.AutoLinkType == ast.AutoLinkEmail && !strings.HasPrefix(ur, "mailto:") {
+				ur = "mailto:" + ur
+			}
+			b.WriteString(fmt.Sprintf(`<a href="%v">%v</a>`, html.EscapeString(ur), html.EscapeString(string(c.Label(src)))))
+		case *ast.Image:
+			dest := string(c.Destination)
+			alt := string(c.Text(src))
+			if p := MdImagePath(dest, dir, root); p != "" {
+				*imgs = append(*imgs, p)
+				if alt != "" {
+					b.WriteString("<i>" + html.EscapeString(alt) + "</i>")
+				}
+			} else {
+				if alt == "" {
+					alt = dest
+				}
+				b.WriteString(fmt.Sprintf(`<a href="%v">[%v]</a>`, html.EscapeString(dest), html.EscapeString(alt)))
+			}
+		case *east.TaskCheckBox:
+			if c.IsChecked {
+				b.WriteString("☑ ")
+			} else {
+				b.WriteString("☐ ")
+			}
+		case *ast.RawHTML:
+			// dropped: only the text within inline html is shown
+		default:
+			b.WriteString(mdInline(c, src, dir, root, imgs))
+		}
+	}
+	return b.String()
+}
+
+// MdBlocks parses given Markdown source of a file in given directory, with
+// goldmark and the GitHub extensions, returning its blocks in order --
+// images are resolved relative to the directory, or the project root
+func MdBlocks(src []byte, dir, root string) []MdBlock {
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	doc := md.Parser().Parse(text.NewReader(src))
+	var blocks []MdBlock
+	prev := 0
+	add := func(n ast.Node, b MdBlock) {
+		st, ed, ok := mdLines(n, src)
+		if !ok {
+			st, ed = prev, prev
+		}
+		b.Ln, b.EdLn = st, ed
+		prev = ed
+		blocks = append(blocks, b)
+	}
+	var walk func(n ast.Node, indent int, quote bool, bullet string)
+	walk = func(n ast.Node, indent int, quote bool, bullet string) {
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			b := MdBlock{Indent: indent, Quote: quote}
+			switch c := c.(type) {
+			case *ast.Heading:
+				b.Kind = "heading"
+				b.Level = c.Level
+				b.Text = mdInline(c, src, dir, root, &b.Images)
+			case *ast.Paragraph, *ast.TextBlock:
+				b.Kind = "para"
+				b.Text = bullet + mdInline(c, src, dir, root, &b.Images)
+				bullet = ""
+			case *ast.FencedCodeBlock, *ast.CodeBlock:
+				b.Kind = "code"
+				b.Text = mdRaw(c, src)
+			case *ast.HTMLBlock:
+				b.Kind = "html"
+				b.Text = mdRaw(c, src)
+			case *ast.ThematicBreak:
+				b.Kind = "rule"
+			case *east.Table:
+				b.Kind = "table"
+				var rows []string
+				for r := c.FirstChild(); r != nil; r = r.NextSibling() {
+					var cells []string
+					for cl := r.FirstChild(); cl != nil; cl = cl.NextSibling() {
+						cells = append(cells, strings.TrimSpace(string(cl.Text(src))))
+					}
+					rows = append(rows, strings.Join(cells, " │ "))
+				}
+				b.Text = strings.Join(rows, "\n")
+			case *ast.Blockquote:
+				walk(c, indent+1, true, "")
+				continue
+			case *ast.List:
+				num := c.Start
+				for it := c.FirstChild(); it != nil; it = it.NextSibling() {
+					bl := "• "
+					if c.IsOrdered() {
+						bl = fmt.Sprintf("%d. ", num)
+						num++
+					}
+					walk(it, indent+1, quote, bl)
+				}
+				continue
+			default:
+				walk(c, indent, quote, bullet)
+				continue
+			}
+			add(c, b)
+		}
+	}
+	walk(doc, 0, false, "")
+	return blocks
+}
+
+// MarkdownPreview shows the Markdown file in the active view rendered in
+// the Preview panel -- it follows the active view while it shows Markdown,
+// scrolls along with the cursor in it, and is rendered again shortly after
+// edits
+func (ge *Gide) MarkdownPreview() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || !LangNamesMatchFilename(string(tv.Buf.Filename), LangNames{"Markdown"}) {
+		ge.SetStatus("Markdown Preview: the active view is not showing a Markdown file")
+		return
+	}
+	mvi, _ := ge.FindOrMakeMainTab("Preview", KiT_MarkdownView, true) // sel
+	mv := mvi.Embed(KiT_MarkdownView).(*MarkdownView)
+	mv.UpdateView(ge)
+	mv.Buf = tv.Buf
+	mv.Refresh()
+	mv.ScrollToLine(tv.CursorPos.Ln)
+}
+
+// markdownView returns the Preview panel, if it is open
+func (ge *Gide) markdownView() (*MarkdownView, bool) {
+	mvi, _, ok := ge.MainTabByName("Preview")
+	if !ok {
+		return nil, false
+	}
+	mv, ok := mvi.Embed(KiT_MarkdownView).(*MarkdownView)
+	return mv, ok
+}
+
+// MarkdownActive previews the file in the active view in the Preview panel,
+// if it is open and the file is another Markdown file -- called when the
+// active view changes
+func (ge *Gide) MarkdownActive() {
+	mv, ok := ge.markdownView()
+	if !ok {
+		return
+	}
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf == mv.Buf {
+		return
+	}
+	if !LangNamesMatchFilename(string(tv.Buf.Filename), LangNames{"Markdown"}) {
+		return // keeps showing the last one
+	}
+	mv.Buf = tv.Buf
+	mv.Refresh()
+	mv.ScrollToLine(tv.CursorPos.Ln)
+}
+
+// MarkdownEdit renders the Preview panel again shortly after given buffer is
+// edited, if it is showing it
+func (ge *Gide) MarkdownEdit(tb *giv.TextBuf) {
+	if mv, ok := ge.markdownView(); ok && mv.Buf == tb {
+		mv.RefreshLater()
+	}
+}
+
+// MarkdownScroll scrolls the Preview panel to the block at the cursor of
+// given view, if the panel is showing its buffer -- called when the cursor
+// moves
+func (ge *Gide) MarkdownScroll(tv *giv.TextView) {
+	if mv, ok := ge.markdownView(); ok && mv.Buf == tv.Buf {
+		mv.ScrollToLine(tv.CursorPos.Ln)
+	}
+}
+
+// MarkdownView is a widget that shows a Markdown file rendered, as labels,
+// code blocks and images, for previewing it as it is edited
+type MarkdownView struct {
+	gi.Layout
+	Gide   *Gide        `json:"-" xml:"-" desc:"parent gide project"`
+	Buf    *giv.TextBuf `json:"-" xml:"-" desc:"buffer that is previewed"`
+	Blocks []MdBlock    `json:"-" xml:"-" desc:"blocks of the current rendering"`
+	Mu     sync.Mutex   `json:"-" xml:"-" view:"-" desc:"mutex protecting the blocks and timer"`
+	curBlk int
+	timer  *time.Timer
+}
+
+var KiT_MarkdownView = kit.Types.AddType(&MarkdownView{}, MarkdownViewProps)
+
+// Refresh renders the buffer again
+func (mv *MarkdownView) Refresh() {
+	tb := mv.Buf
+	if tb == nil {
+		return
+	}
+	fp := string(tb.Filename)
+	blocks := MdBlocks(tb.LinesToBytesCopy(), filepath.Dir(fp), string(mv.Gide.ProjRoot))
+	mv.Mu.Lock()
+	mv.Blocks = blocks
+	mv.curBlk = -1
+	mv.Mu.Unlock()
+	if lb := mv.FileLabel(); lb != nil {
+		if rp, err := filepath.Rel(string(mv.Gide.ProjRoot), fp); err == nil {
+			fp = rp
+		}
+		lb.SetText(html.EscapeString(fp))
+	}
+	mv.ShowBlocks(blocks)
+}
+
+// RefreshLater renders the buffer again after MarkdownDelay, unless it is
+// asked to again before then
+func (mv *MarkdownView) RefreshLater() {
+	mv.Mu.Lock()
+	defer mv.Mu.Unlock()
+	if mv.timer != nil {
+		mv.timer.Stop()
+	}
+	mv.timer = time.AfterFunc(MarkdownDelay, func() {
+		mv.Refresh()
+		if tv := mv.Gide.ActiveTextView(); tv != nil && tv.Buf == mv.Buf {
+			mv.ScrollToLine(tv.CursorPos.Ln)
+		}
+	})
+}
+
+// mdHeadingSizes are the font sizes of the headings, by level
+var mdHeadingSizes = []string{"xx-large", "x-large", "large", "medium", "medium", "small"}
+
+// ShowBlocks replaces the contents of the preview with given blocks, block
+// i being the child named md-i, followed by its images, if any
+func (mv *MarkdownView) ShowBlocks(blocks []MdBlock) {
+	fr := mv.Preview()
+	if fr == nil {
+		return
+	}
+	updt := fr.UpdateStart()
+	fr.SetFullReRender()
+	fr.DeleteChildren(true)
+	for i, b := range blocks {
+		nm := fmt.Sprintf("md-%d", i)
+		var wi gi.Node2D
+		switch b.Kind {
+		case "rule":
+			sp := fr.AddNewChild(gi.KiT_Separator, nm).(*gi.Separator)
+			sp.Horiz = true
+			sp.SetStretchMaxWidth()
+			wi = sp
+		default:
+			lb := fr.AddNewChild(gi.KiT_Label, nm).(*gi.Label)
+			lb.SetStretchMaxWidth()
+			switch b.Kind {
+			case "heading":
+				lv := b.Level - 1
+				if lv < 0 || lv >= len(mdHeadingSizes) {
+					lv = len(mdHeadingSizes) - 1
+				}
+				lb.SetProp("font-size", mdHeadingSizes[lv])
+				lb.SetProp("font-weight", "bold")
+				lb.SetText(b.Text)
+			case "code", "table", "html":
+				lb.SetProp("white-space", gi.WhiteSpacePre)
+				lb.SetProp("font-family", "Go Mono")
+				lb.SetProp("background-color", "highlight")
+				lb.SetProp("padding", units.NewValue(.5, units.Ch))
+				lb.SetText(html.EscapeString(b.Text))
+			default:
+				lb.SetProp("white-space", gi.WhiteSpaceNormal)
+				lb.SetText(b.Text)
+			}
+			if b.Quote {
+				lb.SetProp("font-style", "italic")
+			}
+			wi = lb
+		}
+		if b.Indent > 0 {
+			wi.SetProp("margin-left", units.NewValue(float32(2*b.Indent), units.Ch))
+		}
+		for j, img := range b.Images {
+			bm := fr.AddNewChild(gi.KiT_Bitmap, fmt.Sprintf("md-%d-img-%d", i, j)).(*gi.Bitmap)
+			if err := bm.OpenImage(gi.FileName(img), 0, 0); err != nil {
+				fr.DeleteChild(bm.This(), true)
+				lb := fr.AddNewChild(gi.KiT_Label, fmt.Sprintf("md-%d-img-%d", i, j)).(*gi.Label)
+				lb.SetText(fmt.Sprintf("<i>image not found: %v</i>", html.EscapeString(img)))
+				continue
+			}
+			if sz := bm.Pixels.Bounds().Size(); float32(sz.X) > MarkdownImageMax {
+				h := MarkdownImageMax * float32(sz.Y) / float32(sz.X) // both, to keep the aspect
+				bm.OpenImage(gi.FileName(img), MarkdownImageMax, h)
+			}
+		}
+	}
+	fr.UpdateEnd(updt)
+}
+
+// ScrollToLine scrolls the preview to the block showing given line of the
+// source, if it is not already at it
+func (mv *MarkdownView) ScrollToLine(ln int) {
+	mv.Mu.Lock()
+	bi := MdBlockAt(mv.Blocks, ln)
+	if bi < 0 || bi == mv.curBlk {
+		mv.Mu.Unlock()
+		return
+	}
+	mv.curBlk = bi
+	mv.Mu.Unlock()
+	fr := mv.Preview()
+	if fr == nil {
+		return
+	}
+	wk, ok := fr.ChildByName(fmt.Sprintf("md-%d", bi), bi)
+	if !ok {
+		return
+	}
+	if _, wi := gi.KiToNode2D(wk); wi != nil {
+		wi.ScrollToMe()
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (mv *MarkdownView) UpdateView(ge *Gide) {
+	mv.Gide = ge
+	mv.Lay = gi.LayoutVert
+	mv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "mdbar")
+	config.Add(gi.KiT_Frame, "preview")
+	mods, updt := mv.ConfigChildren(config, false)
+	mv.ConfigToolbar()
+	fr := mv.Preview()
+	fr.Lay = gi.LayoutVert
+	fr.SetStretchMaxWidth()
+	fr.SetStretchMaxHeight()
+	fr.SetProp("overflow", "auto")
+	fr.SetProp("padding", units.NewValue(1, units.Ch))
+	fr.SetProp("spacing", units.NewValue(.5, units.Em))
+	if mods {
+		mv.UpdateEnd(updt)
+	}
+}
+
+// MdBar returns the preview toolbar
+func (mv *MarkdownView) MdBar() *gi.ToolBar {
+	tbi, ok := mv.ChildByName("mdbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// FileLabel returns the label in the toolbar with the file that is previewed
+func (mv *MarkdownView) FileLabel() *gi.Label {
+	tb := mv.MdBar()
+	if tb == nil {
+		return nil
+	}
+	lbi, ok := tb.ChildByName("file", 1)
+	if !ok {
+		return nil
+	}
+	return lbi.(*gi.Label)
+}
+
+// Preview returns the frame with the rendered blocks
+func (mv *MarkdownView) Preview() *gi.Frame {
+	fri, ok := mv.ChildByName("preview", 1)
+	if !ok {
+		return nil
+	}
+	return fri.(*gi.Frame)
+}
+
+// ConfigToolbar adds toolbar.
+func (mv *MarkdownView) ConfigToolbar() {
+	tb := mv.MdBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	refresh := tb.AddNewChild(gi.KiT_Action, "refresh").(*gi.Action)
+	refresh.SetText("Refresh")
+	refresh.Tooltip = "Render the file again, e.g., after its images have changed -- it is rendered again after each edit"
+	refresh.ActionSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		mvv, _ := recv.Embed(KiT_MarkdownView).(*MarkdownView)
+		mvv.Refresh()
+	})
+
+	tb.AddNewChild(gi.KiT_Label, "file")
+}
+
+var MarkdownViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMdImagePath(t *testing.T) {
+	dir := filepath.FromSlash("/proj/docs")
+	root := filepath.FromSlash("/proj")
+	cases := []struct {
+		dest, want string
+	}{
+		{"img/a.png", "/proj/docs/img/a.png"},
+		{"../logo/b.png", "/proj/logo/b.png"},
+		{"/logo/c.png", "/proj/logo/c.png"},
+		{"my%20pic.png?raw=true", "/proj/docs/my pic.png"},
+		{"d.png#frag", "/proj/docs/d.png"},
+		{"file:///tmp/e.png", "/tmp/e.png"},
+		{"https://example.com/f.png", ""},
+		{"data:image/png;base64,AAAA", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		want := filepath.FromSlash(c.want)
+		if got := MdImagePath(c.dest, dir, root); got != want {
+			t.Errorf("%q: got %q, want %q", c.dest, got, want)
+		}
+	}
+}
+
+func TestMdBlockAt(t *testing.T) {
+	blocks := []MdBlock{{Ln: 2, EdLn: 2}, {Ln: 4, EdLn: 6}, {Ln: 9, EdLn: 12}}
+	for ln, want := range map[int]int{0: 0, 2: 0, 3: 0, 4: 1, 8: 1, 9: 2, 100: 2} {
+		if got := MdBlockAt(blocks, ln); got != want {
+			t.Errorf("line %d: got %d, want %d", ln, got, want)
+		}
+	}
+	if got := MdBlockAt(nil, 3); got != -1 {
+		t.Errorf("no blocks: got %d, want -1", got)
+	}
+}
+
+func TestMdBlocks(t *testing.T) {
+	src := "# Title\n\nSome *em* and `code`.\n\n- one\n- two\n\n```go\nx := 1\n```\n\n![pic](img/p.png)\n"
+	blocks := MdBlocks([]byte(src), filepath.FromSlash("/proj/docs"), filepath.FromSlash("/proj"))
+	want := []struct {
+		kind string
+		ln   int
+		text string
+	}{
+		{"heading", 0, "Title"},
+		{"para", 2, "Some <i>em</i> and <code>code</code>."},
+		{"para", 4, "• one"},
+		{"para", 5, "• two"},
+		{"code", 8, "x := 1"},
+		{"para", 11, "<i>pic</i>"},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(blocks), len(want), blocks)
+	}
+	for i, w := range want {
+		b := blocks[i]
+		if b.Kind != w.kind || b.Ln != w.ln || b.Text != w.text {
+			t.Errorf("block %d: got %v %d %q, want %v %d %q", i, b.Kind, b.Ln, b.Text, w.kind, w.ln, w.text)
+		}
+	}
+	if im := blocks[5].Images; len(im) != 1 || im[0] != filepath.FromSlash("/proj/docs/img/p.png") {
+		t.Errorf("images: got %v", im)
+	}
+}
@@ -0,0 +1,431 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// MemDumpLen is the number of bytes of memory shown at a time in the
+// Memory panel
+var MemDumpLen = 512
+
+// memWordSize is the size of a pointer, for following pointers in memory
+// (all the platforms supported by Delve are 64 bit)
+const memWordSize = 8
+
+// memLinePrefix is the number of runes before the bytes on each line of a
+// hex dump: the address and two spaces
+const memLinePrefix = 20
+
+// ParseAddr parses a memory address, in hex with a 0x prefix, or decimal
+func ParseAddr(s string) (uint64, bool) {
+	a, err := strconv.ParseUint(strings.TrimSpace(s), 0, 64)
+	return a, err == nil
+}
+
+// HexDump returns a hex dump of given memory starting at given address, 16
+// bytes per line, with the address at the start of each line and the
+// printable ascii characters at the end
+func HexDump(addr uint64, mem []byte) string {
+	var b bytes.Buffer
+	for st := 0; st < len(mem); st += 16 {
+		if st > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "0x%016x  ", addr+uint64(st))
+		var asc []byte
+		for i := 0; i < 16; i++ {
+			if i == 8 {
+				b.WriteString(" ")
+			}
+			if st+i >= len(mem) {
+				b.WriteString("   ")
+				continue
+			}
+			c := mem[st+i]
+			fmt.Fprintf(&b, "%02x ", c)
+			if c >= 32 && c < 127 {
+				asc = append(asc, c)
+			} else {
+				asc = append(asc, '.')
+			}
+		}
+		b.WriteString(" |" + string(asc) + "|")
+	}
+	return b.String()
+}
+
+// HexDumpByte returns the index within its line (0-15) of the byte shown at
+// given column of a HexDump line, or -1 if the column is not on a byte
+func HexDumpByte(ch int) int {
+	ch -= memLinePrefix
+	if ch >= 8*3 {
+		ch-- // extra space in the middle
+		if ch < 8*3 {
+			return -1
+		}
+	}
+	if ch < 0 || ch >= 16*3 || ch%3 == 2 {
+		return -1
+	}
+	return ch / 3
+}
+
+// ReadWord returns the pointer-sized word in mem at given offset, with given
+// byte order, and false if there is not a whole word there
+func ReadWord(mem []byte, off int, little bool) (uint64, bool) {
+	if off < 0 || off+memWordSize > len(mem) {
+		return 0, false
+	}
+	if little {
+		return binary.LittleEndian.Uint64(mem[off:]), true
+	}
+	return binary.BigEndian.Uint64(mem[off:]), true
+}
+
+// MemView is a widget that shows a hex dump of the memory of the program
+// being debugged, with pointers in it that can be followed
+type MemView struct {
+	gi.Layout
+	Gide   *Gide    `json:"-" xml:"-" desc:"parent gide project"`
+	Addr   uint64   `desc:"address of the start of the memory shown"`
+	Mem    []byte   `json:"-" xml:"-" desc:"the memory shown"`
+	Little bool     `json:"-" xml:"-" desc:"true if the program is little-endian"`
+	Hist   []uint64 `json:"-" xml:"-" desc:"addresses shown before following pointers, for going back"`
+}
+
+var KiT_MemView = kit.Types.AddType(&MemView{}, MemViewProps)
+
+// ShowExpr shows the memory at given address, or if it is not a number, at
+// the address of given expression in the stopped program
+func (mv *MemView) ShowExpr(expr string) {
+	ge := mv.Gide
+	if !ge.DebugIsStopped() || strings.TrimSpace(expr) == "" {
+		return
+	}
+	addr, ok := ParseAddr(expr)
+	if !ok {
+		v, err := ge.Dbg.Eval(expr)
+		if err != nil {
+			ge.SetStatus(err.Error())
+			return
+		}
+		if v.Addr == 0 {
+			ge.SetStatus(expr + " has no address")
+			return
+		}
+		addr = v.Addr
+	}
+	mv.Hist = nil
+	mv.Load(addr)
+}
+
+// Load reads the memory at given address from the stopped program and
+// shows it
+func (mv *MemView) Load(addr uint64) bool {
+	ge := mv.Gide
+	if !ge.DebugIsStopped() {
+		return false
+	}
+	mem, little, err := ge.Dbg.ExamineMemory(addr, MemDumpLen)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Memory at 0x%x: %v", addr, err))
+		return false
+	}
+	mv.Addr, mv.Mem, mv.Little = addr, mem, little
+	mv.AddrField().SetText(fmt.Sprintf("0x%x", addr))
+	buf, _ := ge.FindOrMakeCmdBuf("Memory", true)
+	buf.SetText([]byte(HexDump(addr, mem)))
+	mv.TextView().SetBuf(buf)
+	return true
+}
+
+// Refresh reloads the memory shown, e.g., after the program has run
+func (mv *MemView) Refresh() {
+	if mv.Mem != nil {
+		mv.Load(mv.Addr)
+	}
+}
+
+// FollowPointer shows the memory at the address stored in the pointer at
+// the cursor in the dump
+func (mv *MemView) FollowPointer() {
+	tv := mv.TextView()
+	bi := HexDumpByte(tv.CursorPos.Ch)
+	if bi < 0 {
+		mv.Gide.SetStatus("Put the cursor on a byte of the pointer to follow")
+		return
+	}
+	off := (tv.CursorPos.Ln*16 + bi) &^ (memWordSize - 1)
+	p, ok := ReadWord(mv.Mem, off, mv.Little)
+	if !ok || p == 0 {
+		mv.Gide.SetStatus("Not a pointer")
+		return
+	}
+	prv := mv.Addr
+	if mv.Load(p) {
+		mv.Hist = append(mv.Hist, prv)
+	}
+}
+
+// Back goes back to the memory shown before following the last pointer
+func (mv *MemView) Back() {
+	n := len(mv.Hist)
+	if n == 0 {
+		return
+	}
+	addr := mv.Hist[n-1]
+	mv.Hist = mv.Hist[:n-1]
+	mv.Load(addr)
+}
+
+// PrevPage shows the memory just before that shown
+func (mv *MemView) PrevPage() {
+	if mv.Addr >= uint64(MemDumpLen) {
+		mv.Load(mv.Addr - uint64(MemDumpLen))
+	}
+}
+
+// NextPage shows the memory just after that shown
+func (mv *MemView) NextPage() {
+	mv.Load(mv.Addr + uint64(MemDumpLen))
+}
+
+// DebugMemory shows the Memory panel, with the memory at the address of
+// the selected expression (or the word at the cursor) if any
+func (ge *Gide) DebugMemory() {
+	mvi, _ := ge.FindOrMakeMainTab("Memory", KiT_MemView, true) // sel
+	mv := mvi.Embed(KiT_MemView).(*MemView)
+	mv.UpdateView(ge)
+	ge.FocusOnPanel(MainTabsIdx)
+	if ge.Dbg != nil && !ge.DbgRunning {
+		if _, expr := ge.debugExprAtCursor(); expr != "" {
+			mv.ShowExpr(expr)
+		}
+	}
+}
+
+// RegsView is a widget that shows the CPU registers of the program being
+// debugged, as of a given stack frame
+type RegsView struct {
+	gi.Layout
+	Gide  *Gide `json:"-" xml:"-" desc:"parent gide project"`
+	Frame int   `desc:"stack frame the registers are shown for, 0 for the innermost"`
+	Fp    bool  `desc:"show the floating point registers too"`
+}
+
+var KiT_RegsView = kit.Types.AddType(&RegsView{}, RegsViewProps)
+
+// Refresh shows the registers of the stopped program
+func (rv *RegsView) Refresh() {
+	ge := rv.Gide
+	if !ge.DebugIsStopped() {
+		return
+	}
+	regs, err := ge.Dbg.ListRegisters(rv.Frame, rv.Fp)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Registers of frame %d: %v", rv.Frame, err))
+		return
+	}
+	var b bytes.Buffer
+	for _, r := range regs {
+		fmt.Fprintf(&b, "%-8s %s\n", r.Name, r.Value)
+	}
+	buf, _ := ge.FindOrMakeCmdBuf("Registers", true)
+	buf.SetText(b.Bytes())
+	rv.TextView().SetBuf(buf)
+}
+
+// DebugRegisters shows the Registers panel
+func (ge *Gide) DebugRegisters() {
+	rvi, _ := ge.FindOrMakeMainTab("Registers", KiT_RegsView, true) // sel
+	rv := rvi.Embed(KiT_RegsView).(*RegsView)
+	rv.UpdateView(ge)
+	ge.FocusOnPanel(MainTabsIdx)
+	if ge.Dbg != nil && !ge.DbgRunning {
+		rv.Refresh()
+	}
+}
+
+// DebugRefreshViews updates the Memory and Registers panels, if open, when
+// the program stops
+func (ge *Gide) DebugRefreshViews() {
+	if mvi, _, ok := ge.MainTabByName("Memory"); ok {
+		mvi.Embed(KiT_MemView).(*MemView).Refresh()
+	}
+	if rvi, _, ok := ge.MainTabByName("Registers"); ok {
+		rvi.Embed(KiT_RegsView).(*RegsView).Refresh()
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (mv *MemView) UpdateView(ge *Gide) {
+	mv.Gide = ge
+	mv.Lay = gi.LayoutVert
+	mv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "membar")
+	config.Add(gi.KiT_Layout, "memtext")
+	mods, updt := mv.ConfigChildren(config, false)
+	mv.ConfigToolbar()
+	tv := ge.ConfigOutputTextView(mv.KnownChild(1).(*gi.Layout))
+	tv.SetProp("white-space", gi.WhiteSpacePre)
+	if mods {
+		mv.UpdateEnd(updt)
+	}
+}
+
+// MemBar returns the memory toolbar
+func (mv *MemView) MemBar() *gi.ToolBar {
+	return mv.KnownChild(0).(*gi.ToolBar)
+}
+
+// AddrField returns the address text field in the toolbar
+func (mv *MemView) AddrField() *gi.TextField {
+	return mv.MemBar().KnownChild(0).(*gi.TextField)
+}
+
+// TextView returns the text view showing the memory
+func (mv *MemView) TextView() *giv.TextView {
+	return mv.KnownChild(1).KnownChild(0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (mv *MemView) ConfigToolbar() {
+	tb := mv.MemBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	af := tb.AddNewChild(gi.KiT_TextField, "addr").(*gi.TextField)
+	af.SetStretchMaxWidth()
+	af.Tooltip = "address to show, e.g., 0xc000010000, or an expression whose address is shown, e.g., a variable name"
+	af.TextFieldSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			mvv, _ := recv.Embed(KiT_MemView).(*MemView)
+			mvv.ShowExpr(send.(*gi.TextField).Text())
+		}
+	})
+
+	fl := tb.AddNewChild(gi.KiT_Action, "follow").(*gi.Action)
+	fl.SetText("Follow Pointer")
+	fl.Tooltip = "show the memory pointed to by the pointer at the cursor"
+	fl.ActionSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		mvv, _ := recv.Embed(KiT_MemView).(*MemView)
+		mvv.FollowPointer()
+	})
+
+	bk := tb.AddNewChild(gi.KiT_Action, "back").(*gi.Action)
+	bk.SetText("Back")
+	bk.Tooltip = "go back to the memory shown before following the last pointer"
+	bk.ActionSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		mvv, _ := recv.Embed(KiT_MemView).(*MemView)
+		mvv.Back()
+	})
+
+	pv := tb.AddNewChild(gi.KiT_Action, "prev").(*gi.Action)
+	pv.SetText("Prev")
+	pv.Tooltip = "show the memory before this"
+	pv.ActionSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		mvv, _ := recv.Embed(KiT_MemView).(*MemView)
+		mvv.PrevPage()
+	})
+
+	nx := tb.AddNewChild(gi.KiT_Action, "next").(*gi.Action)
+	nx.SetText("Next")
+	nx.Tooltip = "show the memory after this"
+	nx.ActionSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		mvv, _ := recv.Embed(KiT_MemView).(*MemView)
+		mvv.NextPage()
+	})
+}
+
+var MemViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+// UpdateView updates view with current settings
+func (rv *RegsView) UpdateView(ge *Gide) {
+	rv.Gide = ge
+	rv.Lay = gi.LayoutVert
+	rv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "regsbar")
+	config.Add(gi.KiT_Layout, "regstext")
+	mods, updt := rv.ConfigChildren(config, false)
+	rv.ConfigToolbar()
+	tv := ge.ConfigOutputTextView(rv.KnownChild(1).(*gi.Layout))
+	tv.SetProp("white-space", gi.WhiteSpacePre)
+	if mods {
+		rv.UpdateEnd(updt)
+	}
+}
+
+// TextView returns the text view showing the registers
+func (rv *RegsView) TextView() *giv.TextView {
+	return rv.KnownChild(1).KnownChild(0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (rv *RegsView) ConfigToolbar() {
+	tb := rv.KnownChild(0).(*gi.ToolBar)
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	lbl := tb.AddNewChild(gi.KiT_Label, "frame-lbl").(*gi.Label)
+	lbl.SetText("Frame:")
+
+	fr := tb.AddNewChild(gi.KiT_SpinBox, "frame").(*gi.SpinBox)
+	fr.SetMin(0)
+	fr.Tooltip = "stack frame to show the registers of, 0 for the innermost"
+	fr.SpinBoxSig.Connect(rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		rvv, _ := recv.Embed(KiT_RegsView).(*RegsView)
+		rvv.Frame = int(send.(*gi.SpinBox).Value)
+		rvv.Refresh()
+	})
+
+	fp := tb.AddNewChild(gi.KiT_Action, "fp").(*gi.Action)
+	fp.SetText("Toggle FP")
+	fp.Tooltip = "show or hide the floating point registers"
+	fp.ActionSig.Connect(rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		rvv, _ := recv.Embed(KiT_RegsView).(*RegsView)
+		rvv.Fp = !rvv.Fp
+		rvv.Refresh()
+	})
+
+	rf := tb.AddNewChild(gi.KiT_Action, "refresh").(*gi.Action)
+	rf.SetText("Refresh")
+	rf.Tooltip = "read the registers again"
+	rf.ActionSig.Connect(rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		rvv, _ := recv.Embed(KiT_RegsView).(*RegsView)
+		rvv.Refresh()
+	})
+}
+
+var RegsViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHexDump(t *testing.T) {
+	mem := []byte("Hello, gide!\x00\x01\x02\x03\x10\x00\x00\x00\x00\x00\x00\x00")
+	lns := strings.Split(HexDump(0x1000, mem), "\n")
+	if len(lns) != 2 {
+		t.Fatalf("HexDump: got %d lines, want 2", len(lns))
+	}
+	want := "0x0000000000001000  48 65 6c 6c 6f 2c 20 67  69 64 65 21 00 01 02 03  |Hello, gide!....|"
+	if lns[0] != want {
+		t.Errorf("HexDump line 0:\ngot  %q\nwant %q", lns[0], want)
+	}
+	if !strings.HasPrefix(lns[1], "0x0000000000001010  10 00") || !strings.HasSuffix(lns[1], "|........|") {
+		t.Errorf("HexDump line 1: got %q", lns[1])
+	}
+	for ch, bi := range map[int]int{19: -1, 20: 0, 21: 0, 22: -1, 23: 1, 41: 7, 44: -1, 45: 8, 66: 15, 68: -1} {
+		if got := HexDumpByte(ch); got != bi {
+			t.Errorf("HexDumpByte(%d): got %d, want %d", ch, got, bi)
+		}
+	}
+	if p, ok := ReadWord(mem, 16, true); !ok || p != 0x10 {
+		t.Errorf("ReadWord: got %x %v", p, ok)
+	}
+	if _, ok := ReadWord(mem, 20, true); ok {
+		t.Errorf("ReadWord past end should fail")
+	}
+	if a, ok := ParseAddr(" 0xc000 "); !ok || a != 0xc000 {
+		t.Errorf("ParseAddr: got %x %v", a, ok)
+	}
+	if _, ok := ParseAddr("x"); ok {
+		t.Errorf("ParseAddr of a name should fail")
+	}
+}
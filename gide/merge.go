@@ -0,0 +1,433 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// MergeKind is how a chunk of a three-way merge was changed from the common
+// ancestor (base) in the two versions being merged (mine and theirs)
+type MergeKind int
+
+const (
+	// MergeSame is a chunk that is unchanged in both versions
+	MergeSame MergeKind = iota
+
+	// MergeMine is a chunk that is only changed in mine
+	MergeMine
+
+	// MergeTheirs is a chunk that is only changed in theirs
+	MergeTheirs
+
+	// MergeBoth is a chunk that is changed the same way in both versions
+	MergeBoth
+
+	// MergeConflict is a chunk that is changed differently in the two
+	// versions
+	MergeConflict
+)
+
+// MergeChunk is a chunk of the lines of a three-way merge, with the lines
+// of each version, and the line that they start at in each one
+type MergeChunk struct {
+	Kind                     MergeKind
+	Base, Mine, Theirs       []string
+	BaseLn, MineLn, TheirsLn int
+}
+
+// Merged returns the lines of the merge of the chunk -- nil for a conflict
+func (mc *MergeChunk) Merged() []string {
+	switch mc.Kind {
+	case MergeSame:
+		return mc.Base
+	case MergeTheirs:
+		return mc.Theirs
+	case MergeMine, MergeBoth:
+		return mc.Mine
+	}
+	return nil
+}
+
+// mergeEq returns true if given lines are the same
+func mergeEq(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge3 returns the chunks of the three-way merge of mine and theirs, two
+// versions of base, their common ancestor: the lines of base that are
+// unchanged in both versions separate the chunks where one or both of them
+// changed it
+func Merge3(base, mine, theirs []string) []MergeChunk {
+	ma := make([]int, len(base)+1)
+	mb := make([]int, len(base)+1)
+	for i := range ma {
+		ma[i], mb[i] = -1, -1
+	}
+	for _, m := range diffMatches(base, mine) {
+		ma[m[0]] = m[1]
+	}
+	for _, m := range diffMatches(base, theirs) {
+		mb[m[0]] = m[1]
+	}
+	ma[len(base)], mb[len(base)] = len(mine), len(theirs) // sentinel
+	var cks []MergeChunk
+	i, a, b := 0, 0, 0
+	for j := 0; j <= len(base); j++ {
+		if ma[j] < 0 || mb[j] < 0 {
+			continue
+		}
+		if j > i || ma[j] > a || mb[j] > b {
+			mc := MergeChunk{Base: base[i:j], Mine: mine[a:ma[j]], Theirs: theirs[b:mb[j]], BaseLn: i, MineLn: a, TheirsLn: b}
+			mineEq, theirsEq := mergeEq(mc.Mine, mc.Base), mergeEq(mc.Theirs, mc.Base)
+			switch {
+			case mineEq && theirsEq:
+				mc.Kind = MergeSame
+			case mineEq:
+				mc.Kind = MergeTheirs
+			case theirsEq:
+				mc.Kind = MergeMine
+			case mergeEq(mc.Mine, mc.Theirs):
+				mc.Kind = MergeBoth
+			default:
+				mc.Kind = MergeConflict
+			}
+			cks = append(cks, mc)
+		}
+		if j == len(base) {
+			break
+		}
+		n := len(cks)
+		if n > 0 && cks[n-1].Kind == MergeSame && cks[n-1].BaseLn+len(cks[n-1].Base) == j {
+			cks[n-1].Base = base[cks[n-1].BaseLn : j+1]
+			cks[n-1].Mine = cks[n-1].Base
+			cks[n-1].Theirs = cks[n-1].Base
+		} else {
+			cks = append(cks, MergeChunk{Kind: MergeSame, Base: base[j : j+1], Mine: base[j : j+1], Theirs: base[j : j+1], BaseLn: j, MineLn: ma[j], TheirsLn: mb[j]})
+		}
+		i, a, b = j+1, ma[j]+1, mb[j]+1
+	}
+	return cks
+}
+
+// MergeLines returns the lines of the merge of given chunks, with the
+// conflicts marked as git does, with given names of the versions, and the
+// number of conflicts
+func MergeLines(cks []MergeChunk, mineNm, theirsNm string) ([]string, int) {
+	var out []string
+	nc := 0
+	for i := range cks {
+		mc := &cks[i]
+		if mc.Kind != MergeConflict {
+			out = append(out, mc.Merged()...)
+			continue
+		}
+		nc++
+		out = append(out, "<<<<<<< "+mineNm)
+		out = append(out, mc.Mine...)
+		out = append(out, "||||||| base")
+		out = append(out, mc.Base...)
+		out = append(out, "=======")
+		out = append(out, mc.Theirs...)
+		out = append(out, ">>>>>>> "+theirsNm)
+	}
+	return out, nc
+}
+
+// MergeStat returns the number of chunks changed in either version, and of
+// those that conflict
+func MergeStat(cks []MergeChunk) (changes, conflicts int) {
+	for _, mc := range cks {
+		switch mc.Kind {
+		case MergeSame:
+		case MergeConflict:
+			conflicts++
+			changes++
+		default:
+			changes++
+		}
+	}
+	return
+}
+
+// MergeExternal shows the three-way merge of given buffer, with unsaved
+// changes, and the new version of its file on disk, from the version they
+// both started from, in the Merge tab, to merge the changes on disk into it
+func (ge *Gide) MergeExternal(tb *giv.TextBuf, disk []byte) {
+	base, ok := ge.diskBase(tb)
+	if !ok {
+		ge.SetStatus("The version the file was opened or saved as is not known: use Diff Disk to see the changes on disk")
+		return
+	}
+	mvi, _ := ge.FindOrMakeMainTab("Merge", KiT_MergeView, true) // sel
+	mv := mvi.Embed(KiT_MergeView).(*MergeView)
+	mv.UpdateView(ge)
+	mv.SetMerge(tb, base, disk)
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// MergeView is a widget that shows a three-way merge of a buffer with
+// unsaved changes and its file, changed on disk since it was opened or
+// saved, side by side with their common ancestor, with the changed chunks
+// highlighted, and applies the merge to the buffer
+type MergeView struct {
+	gi.Layout
+	Gide   *Gide        `json:"-" xml:"-" desc:"parent gide project"`
+	Buf    *giv.TextBuf `json:"-" xml:"-" desc:"the buffer being merged into"`
+	Disk   []byte       `json:"-" xml:"-" desc:"the text of the file on disk, as of the merge"`
+	Chunks []MergeChunk `json:"-" xml:"-" desc:"chunks of the merge"`
+	Cur    int          `json:"-" xml:"-" desc:"index of the current changed chunk, -1 if none"`
+}
+
+var KiT_MergeView = kit.Types.AddType(&MergeView{}, MergeViewProps)
+
+// mergeSides are the names of the buffers and views of the three versions
+var mergeSides = [3]string{"Merge Mine", "Merge Base", "Merge Disk"}
+
+// SetMerge computes and shows the merge of given buffer with given text on
+// disk, from given common ancestor
+func (mv *MergeView) SetMerge(tb *giv.TextBuf, base, disk []byte) {
+	mv.Buf = tb
+	mv.Disk = disk
+	split := func(b []byte) []string { return strings.Split(string(b), "\n") }
+	mine, bl, theirs := split(tb.LinesToBytesCopy()), split(base), split(disk)
+	mv.Chunks = Merge3(bl, mine, theirs)
+	mv.Cur = -1
+	texts := [3][]string{mine, bl, theirs}
+	for s := 0; s < 3; s++ {
+		buf, _ := mv.Gide.FindOrMakeCmdBuf(mergeSides[s], true)
+		buf.SetText([]byte(strings.Join(texts[s], "\n")))
+		tv := mv.TextView(s)
+		tv.SetBuf(buf)
+		var hls []giv.TextRegion
+		for _, mc := range mv.Chunks {
+			if mc.Kind == MergeSame {
+				continue
+			}
+			st, n := mergeSide(&mc, s)
+			if n == 0 {
+				continue
+			}
+			hls = append(hls, giv.TextRegion{Start: giv.TextPos{Ln: st}, End: giv.TextPos{Ln: st + n - 1, Ch: len([]rune(texts[s][st+n-1]))}})
+		}
+		updt := tv.UpdateStart()
+		tv.Highlights = hls
+		tv.UpdateEnd(updt)
+	}
+	mv.SetInfo()
+	mv.NextChunk()
+}
+
+// mergeSide returns the start line and number of lines of given chunk in
+// given side: 0 mine, 1 base, 2 theirs
+func mergeSide(mc *MergeChunk, side int) (int, int) {
+	switch side {
+	case 0:
+		return mc.MineLn, len(mc.Mine)
+	case 1:
+		return mc.BaseLn, len(mc.Base)
+	}
+	return mc.TheirsLn, len(mc.Theirs)
+}
+
+// SetInfo shows the number of changes and conflicts
+func (mv *MergeView) SetInfo() {
+	ch, cf := MergeStat(mv.Chunks)
+	msg := fmt.Sprintf("%v: mine | base | on disk -- %d changes, %d conflicts", filepath.Base(string(mv.Buf.Filename)), ch, cf)
+	if mv.Cur >= 0 {
+		msg += fmt.Sprintf(" -- at a %v", [...]string{"", "change of mine", "change on disk", "change in both", "conflict"}[mv.Chunks[mv.Cur].Kind])
+	}
+	mv.InfoLabel().SetText(msg)
+}
+
+// GotoChunk moves the cursors in the three views to the start of the chunk
+// with given index
+func (mv *MergeView) GotoChunk(i int) {
+	mv.Cur = i
+	mc := &mv.Chunks[i]
+	for s := 0; s < 3; s++ {
+		st, _ := mergeSide(mc, s)
+		mv.TextView(s).SetCursorShow(giv.TextPos{Ln: st})
+	}
+	mv.SetInfo()
+}
+
+// NextChunk goes to the next changed chunk
+func (mv *MergeView) NextChunk() {
+	for i := mv.Cur + 1; i < len(mv.Chunks); i++ {
+		if mv.Chunks[i].Kind != MergeSame {
+			mv.GotoChunk(i)
+			return
+		}
+	}
+	mv.Gide.SetStatus("No more changes")
+}
+
+// PrevChunk goes to the previous changed chunk
+func (mv *MergeView) PrevChunk() {
+	for i := mv.Cur - 1; i >= 0; i-- {
+		if mv.Chunks[i].Kind != MergeSame {
+			mv.GotoChunk(i)
+			return
+		}
+	}
+	mv.Gide.SetStatus("No previous changes")
+}
+
+// ApplyMerge replaces the text of the buffer with the merge, with the
+// conflicts marked as git does, to resolve in the editor -- it is an edit
+// that can be undone, and the buffer is then based on the version on disk,
+// so saving it saves over it
+func (mv *MergeView) ApplyMerge() {
+	tb := mv.Buf
+	if tb == nil {
+		return
+	}
+	ge := mv.Gide
+	out, nc := MergeLines(mv.Chunks, "mine", "on disk")
+	nl := len(tb.Lines)
+	tb.DeleteText(giv.TextPos{}, giv.TextPos{Ln: nl - 1, Ch: len(tb.Lines[nl-1])}, true, true)
+	tb.InsertText(giv.TextPos{}, []byte(strings.Join(out, "\n")), true, true)
+	ge.WatchSync(tb, mv.Disk)
+	if nc > 0 {
+		ge.SetStatus(fmt.Sprintf("Merged the changes on disk, with %d conflicts, marked with <<<<<<< and >>>>>>> -- resolve them and save", nc))
+	} else {
+		ge.SetStatus("Merged the changes on disk -- save to keep the merge")
+	}
+	ge.ViewFile(gi.FileName(tb.Filename))
+}
+
+// KeepMine keeps the buffer as it is, so saving it saves over the changes
+// on disk
+func (mv *MergeView) KeepMine() {
+	if mv.Buf == nil {
+		return
+	}
+	mv.Gide.WatchSync(mv.Buf, mv.Disk)
+	mv.Gide.SetStatus("Kept the unsaved changes -- saving saves over the changes on disk")
+}
+
+// TakeDisk reloads the buffer from its file on disk, losing its unsaved
+// changes
+func (mv *MergeView) TakeDisk() {
+	if mv.Buf == nil {
+		return
+	}
+	mv.Gide.WatchReload(mv.Buf)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (mv *MergeView) UpdateView(ge *Gide) {
+	mv.Gide = ge
+	mv.Lay = gi.LayoutVert
+	mv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "mergebar")
+	config.Add(gi.KiT_SplitView, "mergesplit")
+	mods, updt := mv.ConfigChildren(config, false)
+	mv.ConfigToolbar()
+	sv := mv.SplitView()
+	sv.Dim = gi.X
+	if !sv.HasChildren() {
+		for _, nm := range []string{"merge-mine", "merge-base", "merge-disk"} {
+			ly := sv.AddNewChild(gi.KiT_Layout, nm).(*gi.Layout)
+			tv := ge.ConfigOutputTextView(ly)
+			tv.SetProp("white-space", gi.WhiteSpacePre)
+			tv.SetProp("tab-size", ge.Prefs.Editor.TabSize)
+		}
+		sv.SetSplits(.34, .33, .33)
+	}
+	if mods {
+		mv.UpdateEnd(updt)
+	}
+}
+
+// MergeBar returns the merge toolbar
+func (mv *MergeView) MergeBar() *gi.ToolBar {
+	tbi, ok := mv.ChildByName("mergebar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// InfoLabel returns the label with the number of changes, in the toolbar
+func (mv *MergeView) InfoLabel() *gi.Label {
+	tb := mv.MergeBar()
+	if tb == nil {
+		return nil
+	}
+	lbi, ok := tb.ChildByName("info", 5)
+	if !ok {
+		return nil
+	}
+	return lbi.(*gi.Label)
+}
+
+// SplitView returns the splitter with the three versions
+func (mv *MergeView) SplitView() *gi.SplitView {
+	svi, ok := mv.ChildByName("mergesplit", 1)
+	if !ok {
+		return nil
+	}
+	return svi.(*gi.SplitView)
+}
+
+// TextView returns the text view for given side: 0 mine, 1 base, 2 on disk
+func (mv *MergeView) TextView(side int) *giv.TextView {
+	return mv.SplitView().KnownChild(side).KnownChild(0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (mv *MergeView) ConfigToolbar() {
+	tb := mv.MergeBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	act := func(nm, txt, tip string, fun func(mvv *MergeView)) {
+		ac := tb.AddNewChild(gi.KiT_Action, nm).(*gi.Action)
+		ac.SetText(txt)
+		ac.Tooltip = tip
+		ac.ActionSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			fun(mvv)
+		})
+	}
+	act("prev", "Prev Change", "go to the previous changed chunk", func(mvv *MergeView) { mvv.PrevChunk() })
+	act("next", "Next Change", "go to the next changed chunk", func(mvv *MergeView) { mvv.NextChunk() })
+	act("apply", "Apply Merge", "put the merge of both sets of changes in the file, with any conflicts marked with <<<<<<< and >>>>>>> to resolve in the editor -- can be undone", func(mvv *MergeView) { mvv.ApplyMerge() })
+	act("mine", "Keep Mine", "keep the file as it is, so saving it saves over the changes on disk", func(mvv *MergeView) { mvv.KeepMine() })
+	act("disk", "Take Disk", "reload the file from disk, losing the unsaved changes", func(mvv *MergeView) { mvv.TakeDisk() })
+
+	lbl := tb.AddNewChild(gi.KiT_Label, "info").(*gi.Label)
+	lbl.SetStretchMaxWidth()
+}
+
+var MergeViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
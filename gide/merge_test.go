@@ -0,0 +1,59 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := strings.Split("a\nb\nc\nd\ne", "\n")
+	mine := strings.Split("a\nB\nc\nd\ne\nf", "\n")
+	theirs := strings.Split("z\na\nb\nc\nD\ne", "\n")
+	cks := Merge3(base, mine, theirs)
+	out, nc := MergeLines(cks, "mine", "disk")
+	if nc != 0 {
+		t.Errorf("conflicts: got %v", nc)
+	}
+	if got := strings.Join(out, "\n"); got != "z\na\nB\nc\nD\ne\nf" {
+		t.Errorf("merged: got %q", got)
+	}
+	if ch, cf := MergeStat(cks); ch != 4 || cf != 0 {
+		t.Errorf("stat: got %v %v", ch, cf)
+	}
+	for _, mc := range cks {
+		if mc.Kind == MergeTheirs && mc.Theirs[0] == "D" && (mc.BaseLn != 3 || mc.MineLn != 3 || mc.TheirsLn != 4) {
+			t.Errorf("lines of the D chunk: %+v", mc)
+		}
+	}
+}
+
+func TestMerge3Conflict(t *testing.T) {
+	base := strings.Split("a\nb\nc", "\n")
+	mine := strings.Split("a\nmine\nc", "\n")
+	theirs := strings.Split("a\ntheirs\nc", "\n")
+	out, nc := MergeLines(Merge3(base, mine, theirs), "mine", "disk")
+	want := "a\n<<<<<<< mine\nmine\n||||||| base\nb\n=======\ntheirs\n>>>>>>> disk\nc"
+	if nc != 1 || strings.Join(out, "\n") != want {
+		t.Errorf("got %v %q", nc, strings.Join(out, "\n"))
+	}
+
+	same := strings.Split("a\nnew\nc", "\n")
+	cks := Merge3(base, same, same)
+	out, nc = MergeLines(cks, "mine", "disk")
+	if nc != 0 || strings.Join(out, "\n") != "a\nnew\nc" || cks[1].Kind != MergeBoth {
+		t.Errorf("same change: got %v %q %+v", nc, out, cks)
+	}
+
+	cks = Merge3(base, base, base)
+	if len(cks) != 1 || cks[0].Kind != MergeSame || len(cks[0].Base) != 3 {
+		t.Errorf("unchanged: got %+v", cks)
+	}
+	out, _ = MergeLines(Merge3(nil, []string{"x"}, nil), "mine", "disk")
+	if strings.Join(out, "\n") != "x" {
+		t.Errorf("empty base: got %q", out)
+	}
+}
@@ -0,0 +1,415 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/goki/gi/giv"
+)
+
+// textPosLess returns true if position a is before b
+func textPosLess(a, b giv.TextPos) bool {
+	if a.Ln == b.Ln {
+		return a.Ch < b.Ch
+	}
+	return a.Ln < b.Ln
+}
+
+// TextPosMove returns the position n runes after (or before, if n is
+// negative) given position in given lines, counting each line break as one
+// rune -- stops at the start or end of the text
+func TextPosMove(lines [][]rune, pos giv.TextPos, n int) giv.TextPos {
+	for n > 0 && pos.Ln < len(lines) {
+		rem := len(lines[pos.Ln]) - pos.Ch
+		if n <= rem {
+			pos.Ch += n
+			return pos
+		}
+		if pos.Ln+1 >= len(lines) {
+			pos.Ch = len(lines[pos.Ln])
+			return pos
+		}
+		n -= rem + 1
+		pos.Ln++
+		pos.Ch = 0
+	}
+	for n < 0 {
+		if -n <= pos.Ch {
+			pos.Ch += n
+			return pos
+		}
+		if pos.Ln == 0 {
+			pos.Ch = 0
+			return pos
+		}
+		n += pos.Ch + 1
+		pos.Ln--
+		pos.Ch = len(lines[pos.Ln])
+	}
+	return pos
+}
+
+// TextInsertEnd returns the position at the end of given text when inserted
+// at given position
+func TextInsertEnd(st giv.TextPos, txt []byte) giv.TextPos {
+	lns := bytes.Split(txt, []byte("\n"))
+	if len(lns) == 1 {
+		return giv.TextPos{Ln: st.Ln, Ch: st.Ch + len(bytes.Runes(txt))}
+	}
+	return giv.TextPos{Ln: st.Ln + len(lns) - 1, Ch: len(bytes.Runes(lns[len(lns)-1]))}
+}
+
+// cursorEdit is an insert or delete of the region from St to Ed
+type cursorEdit struct {
+	Delete bool
+	St, Ed giv.TextPos
+}
+
+// Adjust returns given position adjusted for the change in text from the
+// edit, which was made after the position was recorded
+func (ce *cursorEdit) Adjust(p giv.TextPos) giv.TextPos {
+	st, ed := ce.St, ce.Ed
+	if ce.Delete {
+		switch {
+		case textPosLess(p, st):
+			return p
+		case textPosLess(p, ed):
+			return st
+		case p.Ln == ed.Ln:
+			return giv.TextPos{Ln: st.Ln, Ch: st.Ch + p.Ch - ed.Ch}
+		default:
+			p.Ln -= ed.Ln - st.Ln
+			return p
+		}
+	}
+	switch {
+	case textPosLess(p, st):
+		return p
+	case p.Ln == st.Ln:
+		return giv.TextPos{Ln: ed.Ln, Ch: ed.Ch + p.Ch - st.Ch}
+	default:
+		p.Ln += ed.Ln - st.Ln
+		return p
+	}
+}
+
+// AdjustReg returns given region adjusted for the edit
+func (ce *cursorEdit) AdjustReg(r giv.TextRegion) giv.TextRegion {
+	return giv.TextRegion{Start: ce.Adjust(r.Start), End: ce.Adjust(r.End)}
+}
+
+// MultiCursors are additional cursors in one text view, where each edit made
+// at the main cursor is also made -- each can have a selection, which is
+// replaced by the first edit made, as when cursors are added at each
+// occurrence of the selected text
+type MultiCursors struct {
+	View   *giv.TextView    `desc:"the view the cursors are in"`
+	Regs   []giv.TextRegion `desc:"the additional cursors, as regions for those with a selection, otherwise Start == End"`
+	Last   giv.TextPos      `desc:"last position of the main cursor, before the current edit"`
+	fix    []cursorEdit
+	inEdit bool
+}
+
+// Has returns true if there are additional cursors in given view
+func (mc *MultiCursors) Has(tv *giv.TextView) bool {
+	return mc.View == tv && len(mc.Regs) > 0
+}
+
+// Add adds a cursor, unless there already is one there, and keeps them sorted
+func (mc *MultiCursors) Add(r giv.TextRegion) bool {
+	for _, er := range mc.Regs {
+		if er.Start == r.Start {
+			return false
+		}
+	}
+	mc.Regs = append(mc.Regs, r)
+	sort.Slice(mc.Regs, func(i, j int) bool {
+		return textPosLess(mc.Regs[i].Start, mc.Regs[j].Start)
+	})
+	return true
+}
+
+// Dedupe removes cursors at the same position as another one or the main
+// cursor, e.g., after deleting the text between them
+func (mc *MultiCursors) Dedupe(main giv.TextPos) {
+	var rs []giv.TextRegion
+	for i, r := range mc.Regs {
+		if i > 0 && r.Start == mc.Regs[i-1].Start {
+			continue
+		}
+		if r.Start == main && r.Start == r.End {
+			continue
+		}
+		rs = append(rs, r)
+	}
+	mc.Regs = rs
+}
+
+// CursorsFor returns the multiple cursors for given view, resetting them if
+// they were for another view
+func (ge *Gide) CursorsFor(tv *giv.TextView) *MultiCursors {
+	mc := &ge.Cursors
+	if mc.View != tv {
+		mc.View = tv
+		mc.Regs = nil
+		mc.fix = nil
+		mc.Last = tv.CursorPos
+	}
+	return mc
+}
+
+// AddCursorAbove adds a cursor on the line above the topmost cursor in the
+// active view, at the column of the main cursor
+func (ge *Gide) AddCursorAbove() {
+	ge.addCursorLine(-1)
+}
+
+// AddCursorBelow adds a cursor on the line below the bottommost cursor in the
+// active view, at the column of the main cursor
+func (ge *Gide) AddCursorBelow() {
+	ge.addCursorLine(1)
+}
+
+func (ge *Gide) addCursorLine(dir int) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	mc := ge.CursorsFor(tv)
+	ln := tv.CursorPos.Ln
+	for _, r := range mc.Regs {
+		if (dir < 0 && r.Start.Ln < ln) || (dir > 0 && r.Start.Ln > ln) {
+			ln = r.Start.Ln
+		}
+	}
+	ln += dir
+	if ln < 0 || ln >= len(tv.Buf.Lines) {
+		return
+	}
+	ch := tv.CursorPos.Ch
+	if ll := len(tv.Buf.Lines[ln]); ch > ll {
+		ch = ll
+	}
+	p := giv.TextPos{Ln: ln, Ch: ch}
+	mc.Add(giv.TextRegion{Start: p, End: p})
+	ge.ShowCursors(tv)
+}
+
+// AddCursorNextMatch adds a cursor, with a selection, at the next occurrence
+// of the text selected in the active view, after the last one added --
+// typing then replaces all of the occurrences
+func (ge *Gide) AddCursorNextMatch() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || !tv.HasSelection() {
+		ge.SetStatus("Select the text to add cursors at each occurrence of")
+		return
+	}
+	sel := tv.SelectReg
+	find := []rune(string(tv.Selection().ToBytes()))
+	if sel.Start.Ln != sel.End.Ln || len(find) == 0 {
+		ge.SetStatus("Can only add cursors at occurrences of text within one line")
+		return
+	}
+	mc := ge.CursorsFor(tv)
+	from := sel.End
+	for _, r := range mc.Regs {
+		if textPosLess(from, r.End) {
+			from = r.End
+		}
+	}
+	lines := tv.Buf.Lines
+	nl := len(lines)
+	for i := 0; i <= nl; i++ {
+		ln := (from.Ln + i) % nl
+		st := 0
+		if i == 0 {
+			st = from.Ch
+		}
+		l := lines[ln]
+		for ch := st; ch+len(find) <= len(l); ch++ {
+			if string(l[ch:ch+len(find)]) != string(find) {
+				continue
+			}
+			reg := giv.TextRegion{Start: giv.TextPos{Ln: ln, Ch: ch}, End: giv.TextPos{Ln: ln, Ch: ch + len(find)}}
+			if reg.Start == sel.Start {
+				ge.SetStatus("No more occurrences")
+				return
+			}
+			if mc.Add(reg) {
+				ge.ShowCursors(tv)
+				return
+			}
+		}
+	}
+}
+
+// AddCursorsToLines adds a cursor on each line of the selection in the active
+// view, at the column of the main cursor -- for editing a column of lines
+func (ge *Gide) AddCursorsToLines() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || !tv.HasSelection() {
+		ge.SetStatus("Select the lines to add cursors to")
+		return
+	}
+	sel := tv.SelectReg
+	mc := ge.CursorsFor(tv)
+	ch := tv.CursorPos.Ch
+	for ln := sel.Start.Ln; ln <= sel.End.Ln && ln < len(tv.Buf.Lines); ln++ {
+		if ln == tv.CursorPos.Ln {
+			continue
+		}
+		c := ch
+		if ll := len(tv.Buf.Lines[ln]); c > ll {
+			c = ll
+		}
+		p := giv.TextPos{Ln: ln, Ch: c}
+		mc.Add(giv.TextRegion{Start: p, End: p})
+	}
+	tv.SelectReset()
+	ge.ShowCursors(tv)
+}
+
+// ClearCursors removes all the additional cursors
+func (ge *Gide) ClearCursors() {
+	mc := &ge.Cursors
+	tv := mc.View
+	mc.Regs = nil
+	mc.fix = nil
+	if tv != nil {
+		ge.ShowCursors(tv)
+	}
+}
+
+// ShowCursors shows the additional cursors in given view by highlighting
+// them, or their selections
+func (ge *Gide) ShowCursors(tv *giv.TextView) {
+	mc := &ge.Cursors
+	updt := tv.UpdateStart()
+	tv.Highlights = tv.Highlights[:0]
+	if mc.View == tv {
+		for _, r := range mc.Regs {
+			if r.Start == r.End && r.Start.Ln < len(tv.Buf.Lines) {
+				if r.End.Ch < len(tv.Buf.Lines[r.Start.Ln]) {
+					r.End.Ch++
+				} else if r.Start.Ch > 0 {
+					r.Start.Ch--
+				}
+			}
+			tv.Highlights = append(tv.Highlights, r)
+		}
+	}
+	tv.UpdateEnd(updt)
+	if n := len(mc.Regs); n > 0 && mc.View == tv {
+		ge.SetStatus(fmt.Sprintf("%d cursors -- Escape to clear", n+1))
+	}
+}
+
+// MultiCursorEdit makes the given edit, just made at the main cursor in
+// given buffer, at each of the additional cursors -- called for all edits
+func (ge *Gide) MultiCursorEdit(tb *giv.TextBuf, tbe *giv.TextBufEdit) {
+	mc := &ge.Cursors
+	if mc.inEdit || tbe == nil || len(mc.Regs) == 0 || mc.View == nil || mc.View.Buf != tb {
+		return
+	}
+	txt := tbe.ToBytes()
+	n := len(bytes.Runes(txt))
+	back := false
+	if tbe.Delete {
+		switch mc.Last {
+		case tbe.Reg.End:
+			back = true
+		case tbe.Reg.Start:
+		default:
+			return // not at the cursor, e.g., undo
+		}
+	} else if tbe.Reg.Start != mc.Last {
+		return
+	}
+	mc.inEdit = true
+	defer func() { mc.inEdit = false }()
+	main := cursorEdit{Delete: tbe.Delete, St: tbe.Reg.Start, Ed: tbe.Reg.End}
+	for i := range mc.Regs {
+		mc.Regs[i] = main.AdjustReg(mc.Regs[i])
+	}
+	mc.fix = nil
+	apply := func(ce cursorEdit, skip int) {
+		if ce.Delete {
+			tb.DeleteText(ce.St, ce.Ed, true, true)
+		} else {
+			tb.InsertText(ce.St, txt, true, true)
+		}
+		for j := range mc.Regs {
+			if j != skip {
+				mc.Regs[j] = ce.AdjustReg(mc.Regs[j])
+			}
+		}
+		mc.fix = append(mc.fix, ce)
+	}
+	for i := range mc.Regs {
+		r := mc.Regs[i]
+		if r.Start != r.End { // replace selection
+			apply(cursorEdit{Delete: true, St: r.Start, Ed: r.End}, i)
+			mc.Regs[i] = giv.TextRegion{Start: r.Start, End: r.Start}
+			if tbe.Delete {
+				continue
+			}
+		}
+		p := mc.Regs[i].Start
+		switch {
+		case !tbe.Delete:
+			ed := TextInsertEnd(p, txt)
+			apply(cursorEdit{St: p, Ed: ed}, i)
+			mc.Regs[i] = giv.TextRegion{Start: ed, End: ed}
+		case back:
+			st := TextPosMove(tb.Lines, p, -n)
+			if st != p {
+				apply(cursorEdit{Delete: true, St: st, Ed: p}, i)
+				mc.Regs[i] = giv.TextRegion{Start: st, End: st}
+			}
+		default:
+			ed := TextPosMove(tb.Lines, p, n)
+			if ed != p {
+				apply(cursorEdit{Delete: true, St: p, Ed: ed}, i)
+			}
+		}
+	}
+	if tbe.Delete {
+		mc.Last = main.St
+	} else {
+		mc.Last = main.Ed
+	}
+	mp := mc.Last
+	for _, ce := range mc.fix {
+		mp = ce.Adjust(mp)
+	}
+	mc.Dedupe(mp)
+}
+
+// CursorsMoved is called when the cursor moves in given view: it moves the
+// main cursor to account for edits made at the additional cursors, and
+// records its position for the next edit
+func (ge *Gide) CursorsMoved(tv *giv.TextView) {
+	mc := &ge.Cursors
+	if mc.inEdit || mc.View != tv {
+		return
+	}
+	if len(mc.fix) > 0 {
+		p := tv.CursorPos
+		for _, ce := range mc.fix {
+			p = ce.Adjust(p)
+		}
+		mc.fix = nil
+		if p != tv.CursorPos {
+			mc.inEdit = true
+			tv.SetCursorShow(p)
+			mc.inEdit = false
+		}
+		ge.ShowCursors(tv)
+	}
+	mc.Last = tv.CursorPos
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+)
+
+// NavPos is a position in a file, recorded in the navigation history
+type NavPos struct {
+	Filename gi.FileName
+	Pos      giv.TextPos
+}
+
+// NavHistoryMax is the maximum number of positions kept in the back history
+var NavHistoryMax = 100
+
+// NavHistory is the back / forward navigation history for jumps such as go
+// to definition -- Back has the most recent position last, as does Fwd
+type NavHistory struct {
+	Back []NavPos
+	Fwd  []NavPos
+}
+
+// Push records given position as the place a jump was made from, clearing
+// the forward history
+func (nh *NavHistory) Push(np NavPos) {
+	if n := len(nh.Back); n > 0 && nh.Back[n-1] == np {
+		return
+	}
+	nh.Back = append(nh.Back, np)
+	if len(nh.Back) > NavHistoryMax {
+		nh.Back = nh.Back[len(nh.Back)-NavHistoryMax:]
+	}
+	nh.Fwd = nil
+}
+
+// GoBack returns the previous position, recording cur to go forward to
+func (nh *NavHistory) GoBack(cur NavPos) (NavPos, bool) {
+	n := len(nh.Back)
+	if n == 0 {
+		return NavPos{}, false
+	}
+	np := nh.Back[n-1]
+	nh.Back = nh.Back[:n-1]
+	nh.Fwd = append(nh.Fwd, cur)
+	return np, true
+}
+
+// GoForward returns the next position after having gone back, recording cur
+// to go back to
+func (nh *NavHistory) GoForward(cur NavPos) (NavPos, bool) {
+	n := len(nh.Fwd)
+	if n == 0 {
+		return NavPos{}, false
+	}
+	np := nh.Fwd[n-1]
+	nh.Fwd = nh.Fwd[:n-1]
+	nh.Back = append(nh.Back, cur)
+	return np, true
+}
+
+// TextPosByteOffset returns the byte offset of given position in the given
+// lines, as used by go/token
+func TextPosByteOffset(lines [][]rune, pos giv.TextPos) int {
+	off := 0
+	for ln := 0; ln < pos.Ln && ln < len(lines); ln++ {
+		off += len(string(lines[ln])) + 1
+	}
+	if pos.Ln < len(lines) {
+		lr := lines[pos.Ln]
+		ch := pos.Ch
+		if ch > len(lr) {
+			ch = len(lr)
+		}
+		off += len(string(lr[:ch]))
+	}
+	return off
+}
+
+// RuneColForByteCol converts a 0-based byte column in given line to a rune
+// column
+func RuneColForByteCol(line []rune, bcol int) int {
+	b := 0
+	for i, r := range line {
+		if b >= bcol {
+			return i
+		}
+		b += utf8.RuneLen(r)
+	}
+	return len(line)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//   Gide
+
+// NavCurPos returns the current position in the active text view
+func (ge *Gide) NavCurPos() (NavPos, bool) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf.Filename == "" {
+		return NavPos{}, false
+	}
+	return NavPos{Filename: tv.Buf.Filename, Pos: tv.CursorPos}, true
+}
+
+// NavJump views the file at given position in the active text view -- files
+// outside of the project are opened in a separate single-file window
+func (ge *Gide) NavJump(np NavPos) bool {
+	tv, _, ok := ge.ViewFile(np.Filename)
+	if !ok {
+		_, oge := NewGideFile(string(np.Filename))
+		if oge == nil {
+			return false
+		}
+		tv = oge.ActiveTextView()
+	}
+	tv.SetCursorShow(np.Pos)
+	tv.GrabFocus()
+	return true
+}
+
+// DefAtCursor finds the definition of the symbol at the cursor in the active
+// text view, using the language server if available, or go/packages for Go
+// files, which handles generic code --
+// C symbols referenced from Go through cgo are found in the C headers, with
+// the language server for C, and functions implemented in Go assembly are
+// found in the assembly files, and vice-versa -- returns the cursor position and the
+// definition position
+func (ge *Gide) DefAtCursor() (cur, def NavPos, ok bool) {
+	cur, ok = ge.NavCurPos()
+	if !ok {
+		return
+	}
+	ok = false
+	fpath := string(cur.Filename)
+	if def, ok = ge.AsmDefAt(ge.ActiveTextView().Buf, cur.Pos); ok {
+		return
+	}
+	if lc, _, lpath := ge.LspClientForActive(); lc != nil {
+		locs, err := lc.Definition(lpath, cur.Pos.Ln, cur.Pos.Ch)
+		if err == nil && len(locs) > 0 && !IsCgoGenerated(LspPath(locs[0].URI)) {
+			st := locs[0].Range.Start
+			def = NavPos{Filename: gi.FileName(LspPath(locs[0].URI)), Pos: giv.TextPos{Ln: st.Line, Ch: st.Character}}
+			ok = true
+			return
+		}
+	}
+	if def, ok = ge.CgoDefAt(ge.ActiveTextView().Buf, cur.Pos); ok {
+		return
+	}
+	if filepath.Ext(fpath) != ".go" {
+		ge.SetStatus("Definition not found")
+		return
+	}
+	tv := ge.ActiveTextView()
+	off := TextPosByteOffset(tv.Buf.Lines, cur.Pos)
+	dfile, ln, col := "", 0, 0
+	gp, err := GoLoadFile(fpath, tv.Buf.LinesToBytesCopy())
+	if err == nil {
+		dfile, ln, col, err = gp.DefAt(fpath, off)
+	}
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Definition not found: %v", err))
+		return
+	}
+	def = NavPos{Filename: gi.FileName(dfile), Pos: giv.TextPos{Ln: ln - 1, Ch: col - 1}}
+	if dfile == fpath && def.Pos.Ln < len(tv.Buf.Lines) {
+		def.Pos.Ch = RuneColForByteCol(tv.Buf.Lines[def.Pos.Ln], col-1)
+	}
+	ok = true
+	return
+}
+
+// GotoDef jumps to the definition of the symbol at the cursor in the active
+// text view -- the current position is recorded so NavBack can return to it
+func (ge *Gide) GotoDef() {
+	cur, def, ok := ge.DefAtCursor()
+	if !ok {
+		return
+	}
+	ge.NavHist.Push(cur)
+	if !ge.NavJump(def) {
+		ge.SetStatus(fmt.Sprintf("Could not open definition at: %v:%v", def.Filename, def.Pos.Ln+1))
+	}
+}
+
+// NavBack returns to the position prior to the last jump (e.g., go to
+// definition)
+func (ge *Gide) NavBack() {
+	cur, _ := ge.NavCurPos()
+	np, ok := ge.NavHist.GoBack(cur)
+	if !ok {
+		ge.SetStatus("No previous position to go back to")
+		return
+	}
+	ge.NavJump(np)
+}
+
+// NavForward goes forward again to the position prior to the last NavBack
+func (ge *Gide) NavForward() {
+	cur, _ := ge.NavCurPos()
+	np, ok := ge.NavHist.GoForward(cur)
+	if !ok {
+		ge.SetStatus("No next position to go forward to")
+		return
+	}
+	ge.NavJump(np)
+}
+
+// PeekLines is the height, in lines, of the peek definition popup
+var PeekLines = 15
+
+// BufForFile returns a text buffer for given file: the buffer of the project
+// file node if it is in the project (opening it if not already open), else a
+// new buffer with the contents of the file, with inProj false
+func (ge *Gide) BufForFile(fnm gi.FileName) (tb *giv.TextBuf, inProj bool, err error) {
+	if fnk, ok := ge.Files.FindFile(string(fnm)); ok {
+		fn := fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode)
+		if !fn.IsDir() {
+			_, err = ge.OpenFileNode(fn)
+			return fn.Buf, true, err
+		}
+	}
+	tb = &giv.TextBuf{}
+	tb.InitName(tb, "peek-buf")
+	err = tb.Open(fnm)
+	ge.ConfigTextBuf(tb)
+	return tb, false, err
+}
+
+// PeekDef shows the definition of the symbol at the cursor in the active text
+// view in a small editor popup, instead of jumping to it -- edits there are
+// made directly to the file buffer (only for files in the project), which is
+// saved as usual.  Escape closes the popup.
+func (ge *Gide) PeekDef() {
+	_, def, ok := ge.DefAtCursor()
+	if !ok {
+		return
+	}
+	tb, inProj, err := ge.BufForFile(def.Filename)
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Could not open definition file: %v", err))
+		return
+	}
+	tv := ge.ActiveTextView()
+	title := fmt.Sprintf("%v:%d", refRelPath(string(ge.ProjRoot), string(def.Filename)), def.Pos.Ln+1)
+	prompt := "Edits are made directly to the file -- press Escape to close"
+	if !inProj {
+		prompt = "File is outside of the project and is read-only here -- press Escape to close"
+	}
+	dlg := gi.NewStdDialog(gi.DlgOpts{Title: title, Prompt: prompt}, false, false)
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	ly := frame.InsertNewChild(gi.KiT_Layout, prIdx+1, "peek-lay").(*gi.Layout)
+	ly.Lay = gi.LayoutVert
+	ly.SetProp("width", units.NewValue(80, units.Ch))
+	ly.SetProp("height", units.NewValue(float32(PeekLines), units.Em))
+	ly.SetStretchMaxWidth()
+	ly.SetStretchMaxHeight()
+	ptv := ly.AddNewChild(giv.KiT_TextView, "peek-view").(*giv.TextView)
+	ptv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+	ptv.SetProp("font-family", Prefs.FontFamily)
+	ptv.SetProp("white-space", gi.WhiteSpacePre)
+	ptv.SetProp("tab-size", ge.Prefs.Editor.TabSize)
+	if !inProj {
+		ptv.SetInactive()
+	}
+	ptv.SetBuf(tb)
+	ptv.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		kt := d.(*key.ChordEvent)
+		if gi.KeyFun(kt.Chord()) == gi.KeyFunAbort {
+			kt.SetProcessed()
+			dlg.Cancel()
+		}
+	})
+	dlg.UpdateEndNoSig(true)
+	cpos := tv.CharStartPos(tv.CursorPos).ToPoint()
+	dlg.Open(cpos.X, cpos.Y+int(tv.LineHeight), tv.Viewport, nil)
+	ptv.SetCursorShow(def.Pos)
+	ptv.GrabFocus()
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+)
+
+// OfflineError is the error for something that needs the network, when
+// working offline (see Preferences.Offline)
+type OfflineError struct {
+	What string `desc:"what needed the network"`
+}
+
+func (oe *OfflineError) Error() string {
+	return fmt.Sprintf("%v needs the network, and gide is working offline -- turn off Work Offline in the File menu to use it", oe.What)
+}
+
+// OfflineCheck returns an OfflineError for given thing that needs the
+// network, if working offline, and otherwise nil
+func OfflineCheck(what string) error {
+	if !Prefs.Offline {
+		return nil
+	}
+	return &OfflineError{What: what}
+}
+
+// OfflineEnv returns the environment variables (as NAME=value) for tools
+// run when working offline, so they fail fast rather than trying to use the
+// network, e.g., the go tool does not query or download modules -- empty
+// when not offline
+func OfflineEnv() []string {
+	if !Prefs.Offline {
+		return nil
+	}
+	return []string{"GOPROXY=off"}
+}
+
+// NetworkCmds are the sub-commands of version control and build tools that
+// use the network, by tool
+var NetworkCmds = map[string][]string{
+	"git": {"clone", "fetch", "pull", "push", "ls-remote", "submodule"},
+	"svn": {"checkout", "co", "update", "up", "commit", "ci", "log", "info", "import", "export", "switch"},
+	"go":  {"get"},
+}
+
+// NetworkCmd returns true if given command and args use the network (see
+// NetworkCmds)
+func NetworkCmd(cmd string, args []string) bool {
+	scs, has := NetworkCmds[strings.TrimSuffix(strings.ToLower(cmd), ".exe")]
+	if !has || len(args) == 0 {
+		return false
+	}
+	for _, sc := range scs {
+		if args[0] == sc {
+			return true
+		}
+	}
+	return false
+}
+
+// NetworkURL returns true if given url is opened over the network, i.e.,
+// it is not a file or one of the gide urls, e.g., find:///
+func NetworkURL(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(up.Scheme) {
+	case "http", "https", "ftp", "ws", "wss":
+		return true
+	}
+	return false
+}
+
+// OpenURL opens given url in the browser, unless it is opened over the
+// network and gide is working offline, in which case that is reported in
+// the status bar
+func (ge *Gide) OpenURL(ur string) {
+	if NetworkURL(ur) {
+		if err := OfflineCheck("Opening " + ur); err != nil {
+			ge.SetStatus(err.Error())
+			return
+		}
+	}
+	oswin.TheApp.OpenURL(ur)
+}
+
+// ToggleOffline turns working offline on or off: when it is on, all of the
+// features that use the network (opening web links, cloning, version
+// control and go get commands, module queries by the go tool) are disabled
+// and fail fast, e.g., for flights and air-gapped environments
+func (ge *Gide) ToggleOffline() {
+	Prefs.Offline = !Prefs.Offline
+	Prefs.Save()
+	if Prefs.Offline {
+		ge.SetStatus("Working offline -- features that use the network are disabled, and language servers and commands started from now on do not use it")
+	} else {
+		ge.SetStatus("Working online")
+	}
+}
+
+// GideOfflineFunc is an ActionUpdateFunc that labels the offline toggle
+// with what it will do
+var GideOfflineFunc = giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+	if Prefs.Offline {
+		act.SetText("Work Online")
+	} else {
+		act.SetText("Work Offline")
+	}
+})
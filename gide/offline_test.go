@@ -0,0 +1,63 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import "testing"
+
+func TestNetworkCmd(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		args []string
+		net  bool
+	}{
+		{"git", []string{"pull"}, true},
+		{"git", []string{"status"}, false},
+		{"git.exe", []string{"push", "origin"}, true},
+		{"svn", []string{"update"}, true},
+		{"go", []string{"get", "{PromptString1}"}, true},
+		{"go", []string{"build"}, false},
+		{"make", []string{"get"}, false},
+		{"git", nil, false},
+	}
+	for _, tt := range tests {
+		if net := NetworkCmd(tt.cmd, tt.args); net != tt.net {
+			t.Errorf("NetworkCmd(%v, %v) = %v, want %v", tt.cmd, tt.args, net, tt.net)
+		}
+	}
+}
+
+func TestNetworkURL(t *testing.T) {
+	tests := []struct {
+		ur  string
+		net bool
+	}{
+		{"https://github.com/goki/gide/wiki", true},
+		{"HTTP://example.com", true},
+		{"file:///home/me/x.go", false},
+		{"find:///x.go#L1", false},
+		{"mailto:me@example.com", false},
+	}
+	for _, tt := range tests {
+		if net := NetworkURL(tt.ur); net != tt.net {
+			t.Errorf("NetworkURL(%v) = %v, want %v", tt.ur, net, tt.net)
+		}
+	}
+}
+
+func TestOfflineCheck(t *testing.T) {
+	defer func(off bool) { Prefs.Offline = off }(Prefs.Offline)
+	Prefs.Offline = false
+	if err := OfflineCheck("Cloning"); err != nil || len(OfflineEnv()) != 0 {
+		t.Errorf("online: OfflineCheck = %v, OfflineEnv = %v", err, OfflineEnv())
+	}
+	Prefs.Offline = true
+	err := OfflineCheck("Cloning")
+	if _, ok := err.(*OfflineError); !ok {
+		t.Errorf("offline: OfflineCheck = %v, want OfflineError", err)
+	}
+	if env := OfflineEnv(); len(env) != 1 || env[0] != "GOPROXY=off" {
+		t.Errorf("offline: OfflineEnv = %v", env)
+	}
+}
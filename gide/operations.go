@@ -0,0 +1,280 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// OpHistoryMax is the maximum number of operations kept in the operations
+// history of a project
+var OpHistoryMax = 50
+
+// OpPatch is the change that an operation made to one file, as the line
+// edits to undo and redo it, so it can be undone and redone as a unit
+// whether or not the file is still open
+type OpPatch struct {
+	Path       string     `desc:"full path of the file"`
+	Undo       []LineEdit `desc:"edits that turn the file after the operation back into the file before it"`
+	Redo       []LineEdit `desc:"edits that turn the file before the operation into the file after it"`
+	BeforeHash string     `desc:"hash of the lines of the file before the operation"`
+	AfterHash  string     `desc:"hash of the lines of the file after the operation"`
+}
+
+// OpLinesHash returns the hash of given lines of a file, for checking that
+// it has not changed since an operation
+func OpLinesHash(lines []string) string {
+	return UndoHash([]byte(strings.Join(lines, "\n")))
+}
+
+// NewOpPatch returns the patch for the file at given path with given lines
+// before and after an operation -- nil if they are the same
+func NewOpPatch(path string, before, after []string) *OpPatch {
+	bh, ah := OpLinesHash(before), OpLinesHash(after)
+	if bh == ah {
+		return nil
+	}
+	return &OpPatch{Path: path, Undo: LineEdits(after, before), Redo: LineEdits(before, after), BeforeHash: bh, AfterHash: ah}
+}
+
+// ApplyLineEdits returns given lines with given edits, in order, made to them
+func ApplyLineEdits(lines []string, eds []LineEdit) []string {
+	var out []string
+	ai := 0
+	for _, ed := range eds {
+		out = append(out, lines[ai:ed.St]...)
+		out = append(out, ed.Lines...)
+		ai = ed.Ed
+	}
+	return append(out, lines[ai:]...)
+}
+
+// Operation is a project-level operation that changes several files, e.g.,
+// a rename or a replace all, which is undone and redone as a unit
+type Operation struct {
+	Name    string     `desc:"description of the operation"`
+	Time    time.Time  `desc:"when the operation was done"`
+	Patches []*OpPatch `desc:"the changes it made to each file"`
+	Saved   bool       `desc:"true if the operation saved the files, so they are also saved when it is undone or redone"`
+}
+
+// OpHistory is the history of the operations in a project, for undoing and
+// redoing them
+type OpHistory struct {
+	Done   []*Operation `desc:"operations done, oldest first"`
+	Undone []*Operation `desc:"operations undone, most recently undone last, which can be redone"`
+}
+
+// Add adds given operation, just done -- the undone ones can then no longer
+// be redone -- keeping at most max operations
+func (oh *OpHistory) Add(op *Operation, max int) {
+	oh.Done = append(oh.Done, op)
+	oh.Undone = nil
+	if max > 0 && len(oh.Done) > max {
+		oh.Done = oh.Done[len(oh.Done)-max:]
+	}
+}
+
+// opRecord records the files that an operation being done touches, with
+// their lines before it
+type opRecord struct {
+	Name    string
+	Bufs    []*giv.TextBuf
+	Before  map[*giv.TextBuf][]string
+	Patches []*OpPatch
+}
+
+// bufStrings returns the lines of given buffer, as strings
+func bufStrings(tb *giv.TextBuf) []string {
+	a := make([]string, len(tb.Lines))
+	for i, l := range tb.Lines {
+		a[i] = string(l)
+	}
+	return a
+}
+
+// OpStart starts recording an operation with given name, that changes
+// several files -- OpTouch must be called for each buffer before it is
+// changed, and OpEnd when it is done
+func (ge *Gide) OpStart(name string) {
+	ge.curOp = &opRecord{Name: name, Before: make(map[*giv.TextBuf][]string)}
+}
+
+// OpTouch records the lines of given buffer before the operation being
+// recorded changes it, if one is
+func (ge *Gide) OpTouch(tb *giv.TextBuf) {
+	op := ge.curOp
+	if op == nil || tb == nil || tb.Filename == "" {
+		return
+	}
+	if _, has := op.Before[tb]; has {
+		return
+	}
+	op.Bufs = append(op.Bufs, tb)
+	op.Before[tb] = bufStrings(tb)
+}
+
+// OpFile records the change that the operation being recorded made to a
+// file that is not open, given its lines before and after it, if one is
+func (ge *Gide) OpFile(path string, before, after []string) {
+	op := ge.curOp
+	if op == nil {
+		return
+	}
+	if p := NewOpPatch(path, before, after); p != nil {
+		op.Patches = append(op.Patches, p)
+	}
+}
+
+// OpEnd ends recording the operation, and adds it to the operations history
+// with the changes it made to each buffer it touched -- saved is true if
+// it saved them
+func (ge *Gide) OpEnd(saved bool) {
+	rec := ge.curOp
+	ge.curOp = nil
+	if rec == nil {
+		return
+	}
+	op := &Operation{Name: rec.Name, Time: time.Now(), Saved: saved, Patches: rec.Patches}
+	for _, tb := range rec.Bufs {
+		if p := NewOpPatch(string(tb.Filename), rec.Before[tb], bufStrings(tb)); p != nil {
+			op.Patches = append(op.Patches, p)
+		}
+	}
+	if len(op.Patches) == 0 {
+		return
+	}
+	ge.Ops.Add(op, OpHistoryMax)
+}
+
+// opApply makes given line edits, for the lines the buffer has now, to
+// given buffer, from the end so the earlier positions stay valid
+func opApply(tb *giv.TextBuf, eds []LineEdit) {
+	a := bufStrings(tb)
+	for i := len(eds) - 1; i >= 0; i-- {
+		st, ed, txt := eds[i].Region(a)
+		if ed != st {
+			tb.DeleteText(st, ed, true, true)
+		}
+		if txt != "" {
+			tb.InsertText(st, []byte(txt), true, true)
+		}
+	}
+}
+
+// opReplay undoes (or redoes, if redo) given operation in all of its files,
+// opening those that are not open -- nothing is changed if any of them have
+// changed since, which is reported, and false returned
+func (ge *Gide) opReplay(op *Operation, redo bool) bool {
+	tbs := make([]*giv.TextBuf, len(op.Patches))
+	var bad []string
+	for i, p := range op.Patches {
+		want := p.AfterHash
+		if redo {
+			want = p.BeforeHash
+		}
+		tb, inProj, err := ge.BufForFile(gi.FileName(p.Path))
+		switch {
+		case err != nil || !inProj:
+			bad = append(bad, fmt.Sprintf("%v: could not be opened", p.Path))
+		case OpLinesHash(bufStrings(tb)) != want:
+			bad = append(bad, fmt.Sprintf("%v: changed since", p.Path))
+		}
+		tbs[i] = tb
+	}
+	what := "Undo"
+	if redo {
+		what = "Redo"
+	}
+	if len(bad) > 0 {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: what + " Operation Failed", Prompt: fmt.Sprintf("Could not %v <b>%v</b>, as some of its files can not be changed back -- nothing was changed:<br>%v", strings.ToLower(what), html.EscapeString(op.Name), html.EscapeString(strings.Join(bad, "\n")))}, true, false, nil, nil)
+		return false
+	}
+	for i, p := range op.Patches {
+		tb := tbs[i]
+		if redo {
+			opApply(tb, p.Redo)
+		} else {
+			opApply(tb, p.Undo)
+		}
+		if op.Saved {
+			tb.Save()
+			ge.BufSaved(tb)
+		}
+	}
+	return true
+}
+
+// UndoOperation undoes the last operation that changed several files, e.g.,
+// a rename or a replace all, in all of them, whether or not they are still
+// open
+func (ge *Gide) UndoOperation() {
+	n := len(ge.Ops.Done)
+	if n == 0 {
+		ge.SetStatus("No operations to undo")
+		return
+	}
+	op := ge.Ops.Done[n-1]
+	if !ge.opReplay(op, false) {
+		return
+	}
+	ge.Ops.Done = ge.Ops.Done[:n-1]
+	ge.Ops.Undone = append(ge.Ops.Undone, op)
+	ge.SetStatus(fmt.Sprintf("Undid %v, in %d files", op.Name, len(op.Patches)))
+}
+
+// RedoOperation redoes the last operation undone with UndoOperation
+func (ge *Gide) RedoOperation() {
+	n := len(ge.Ops.Undone)
+	if n == 0 {
+		ge.SetStatus("No operations to redo")
+		return
+	}
+	op := ge.Ops.Undone[n-1]
+	if !ge.opReplay(op, true) {
+		return
+	}
+	ge.Ops.Undone = ge.Ops.Undone[:n-1]
+	ge.Ops.Done = append(ge.Ops.Done, op)
+	ge.SetStatus(fmt.Sprintf("Redid %v, in %d files", op.Name, len(op.Patches)))
+}
+
+// Operations shows the operations history of the project in the Operations
+// tab: the operations that changed several files, newest first, with the
+// files they changed
+func (ge *Gide) Operations() {
+	tbuf, _, _, _ := ge.FindOrMakeCmdTab("Operations", true, true)
+	root := string(ge.ProjRoot)
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	add := func(op *Operation, state string) {
+		lstr := fmt.Sprintf("%v  %v  (%d files%v)", op.Time.Format("15:04:05"), op.Name, len(op.Patches), state)
+		outlns = append(outlns, []byte(""), []byte(lstr))
+		outmus = append(outmus, []byte(""), []byte("<b>"+html.EscapeString(lstr)+"</b>"))
+		for _, p := range op.Patches {
+			lstr = "	" + refRelPath(root, p.Path)
+			outlns = append(outlns, []byte(lstr))
+			outmus = append(outmus, []byte(fmt.Sprintf(`<a href="file:///%v">%v</a>`, p.Path, html.EscapeString(lstr))))
+		}
+	}
+	for i := range ge.Ops.Undone {
+		add(ge.Ops.Undone[i], ", undone -- can be redone")
+	}
+	for i := len(ge.Ops.Done) - 1; i >= 0; i-- {
+		add(ge.Ops.Done[i], "")
+	}
+	lstr := fmt.Sprintf("%d operations -- newest first", len(ge.Ops.Done)+len(ge.Ops.Undone))
+	outlns = append([][]byte{[]byte(lstr)}, outlns...)
+	outmus = append([][]byte{[]byte("<b>" + lstr + "</b>")}, outmus...)
+	tbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), false, true) // no save undo, yes signal
+	ge.FocusOnPanel(MainTabsIdx)
+}
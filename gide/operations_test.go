@@ -0,0 +1,62 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpPatch(t *testing.T) {
+	tests := []struct {
+		before, after string
+	}{
+		{"a\nb\nc\n", "a\nB\nc\n"},
+		{"a\nb\nc", "x\na\nc\ny"},
+		{"", "new\nfile\n"},
+		{"func f() {\n\tg()\n}\n", "func f() {\n\th()\n\th()\n}\n\nfunc h() {}\n"},
+	}
+	for _, tt := range tests {
+		before := strings.Split(tt.before, "\n")
+		after := strings.Split(tt.after, "\n")
+		p := NewOpPatch("/proj/f.go", before, after)
+		if p == nil {
+			t.Fatalf("%q -> %q: no patch", tt.before, tt.after)
+		}
+		if got := strings.Join(ApplyLineEdits(after, p.Undo), "\n"); got != tt.before {
+			t.Errorf("undo %q -> %q: got %q", tt.before, tt.after, got)
+		}
+		if got := strings.Join(ApplyLineEdits(before, p.Redo), "\n"); got != tt.after {
+			t.Errorf("redo %q -> %q: got %q", tt.before, tt.after, got)
+		}
+		if p.BeforeHash != OpLinesHash(before) || p.AfterHash != OpLinesHash(after) {
+			t.Errorf("%q -> %q: wrong hashes", tt.before, tt.after)
+		}
+	}
+	if p := NewOpPatch("/proj/f.go", []string{"same"}, []string{"same"}); p != nil {
+		t.Errorf("unchanged file has a patch: %v", p)
+	}
+}
+
+func TestOpHistoryAdd(t *testing.T) {
+	var oh OpHistory
+	ops := make([]*Operation, 4)
+	for i := range ops {
+		ops[i] = &Operation{Name: string(rune('a' + i))}
+	}
+	oh.Add(ops[0], 3)
+	oh.Add(ops[1], 3)
+	oh.Undone = []*Operation{ops[2]}
+	oh.Add(ops[3], 3)
+	if oh.Undone != nil {
+		t.Errorf("undone not cleared: %v", oh.Undone)
+	}
+	oh.Add(ops[2], 3)
+	want := []*Operation{ops[1], ops[3], ops[2]}
+	if !reflect.DeepEqual(oh.Done, want) {
+		t.Errorf("done: %v, want %v", oh.Done, want)
+	}
+}
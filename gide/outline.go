@@ -0,0 +1,723 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"html"
+	"log"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// OutlineSym is a symbol in the outline of a file, e.g., a function, type,
+// method or Markdown section, with the symbols within it
+type OutlineSym struct {
+	Name   string       `desc:"name of the symbol"`
+	Kind   string       `desc:"kind of symbol, e.g., func, type, method, section"`
+	Detail string       `desc:"more about the symbol, e.g., the signature of a function"`
+	Ln     int          `desc:"line of the name of the symbol, 0-based"`
+	Ch     int          `desc:"char position of the name within the line, 0-based"`
+	EdLn   int          `desc:"last line of the symbol, e.g., of the body of a function, 0-based"`
+	Kids   []OutlineSym `desc:"symbols within this one, e.g., the methods of a type"`
+}
+
+// OutlineFromLsp returns the outline for given language server symbols
+func OutlineFromLsp(syms []LspDocumentSymbol) []OutlineSym {
+	out := make([]OutlineSym, len(syms))
+	for i, ds := range syms {
+		pos := ds.SelectionRange.Start
+		out[i] = OutlineSym{Name: ds.Name, Kind: LspSymbolKind(ds.Kind), Detail: ds.Detail, Ln: pos.Line, Ch: pos.Character, EdLn: ds.Range.End.Line, Kids: OutlineFromLsp(ds.Children)}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Ln < out[j].Ln
+	})
+	return out
+}
+
+// GoOutline returns the outline of given Go source: its types, with their
+// fields and methods, funcs, consts and vars -- a file with syntax errors
+// gives what could be parsed, with the error
+func GoOutline(src []byte) ([]OutlineSym, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, 0)
+	if f == nil {
+		return nil, err
+	}
+	sym := func(id *ast.Ident, decl ast.Node, kind, detail string) OutlineSym {
+		p := fset.Position(id.Pos())
+		ls := p.Offset - (p.Column - 1)
+		return OutlineSym{Name: id.Name, Kind: kind, Detail: detail, Ln: p.Line - 1, Ch: utf8.RuneCount(src[ls:p.Offset]), EdLn: fset.Position(decl.End()).Line - 1}
+	}
+	text := func(n ast.Node) string {
+		st, ed := fset.Position(n.Pos()).Offset, fset.Position(n.End()).Offset
+		if st < 0 || ed > len(src) || st > ed {
+			return ""
+		}
+		return strings.Join(strings.Fields(string(src[st:ed])), " ")
+	}
+	var out []OutlineSym
+	types := map[string]int{}
+	var methods []*ast.FuncDecl
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				methods = append(methods, d)
+				continue
+			}
+			out = append(out, sym(d.Name, d, "func", text(d.Type.Params)+goOutlineResults(d.Type, text)))
+		case *ast.GenDecl:
+			for _, s := range d.Specs {
+				switch s := s.(type) {
+				case *ast.TypeSpec:
+					ts := sym(s.Name, s, "type", "")
+					switch st := s.Type.(type) {
+					case *ast.StructType:
+						ts.Kind = "struct"
+						for _, fl := range st.Fields.List {
+							for _, nm := range fl.Names {
+								ts.Kids = append(ts.Kids, sym(nm, fl, "field", text(fl.Type)))
+							}
+						}
+					case *ast.InterfaceType:
+						ts.Kind = "interface"
+						for _, fl := range st.Methods.List {
+							for _, nm := range fl.Names {
+								ts.Kids = append(ts.Kids, sym(nm, fl, "method", strings.TrimPrefix(text(fl.Type), "func")))
+							}
+						}
+					default:
+						ts.Detail = text(s.Type)
+					}
+					types[s.Name.Name] = len(out)
+					out = append(out, ts)
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, nm := range s.Names {
+						if nm.Name != "_" {
+							out = append(out, sym(nm, s, kind, ""))
+						}
+					}
+				}
+			}
+		}
+	}
+	for _, d := range methods {
+		recv := goRecvType(d.Recv.List[0].Type)
+		ms := sym(d.Name, d, "method", text(d.Type.Params)+goOutlineResults(d.Type, text))
+		if ti, ok := types[recv]; ok {
+			out[ti].Kids = append(out[ti].Kids, ms)
+			continue
+		}
+		ms.Name = recv + "." + ms.Name
+		out = append(out, ms)
+	}
+	for i := range out {
+		kids := out[i].Kids
+		sort.SliceStable(kids, func(i, j int) bool {
+			return kids[i].Ln < kids[j].Ln
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Ln < out[j].Ln
+	})
+	return out, err
+}
+
+// goOutlineResults returns the results of given func type, after a space
+func goOutlineResults(ft *ast.FuncType, text func(n ast.Node) string) string {
+	if ft.Results == nil {
+		return ""
+	}
+	return " " + text(ft.Results)
+}
+
+// goRecvType returns the name of the type of a method receiver, without any
+// pointer or type parameters
+func goRecvType(x ast.Expr) string {
+	switch x := x.(type) {
+	case *ast.StarExpr:
+		return goRecvType(x.X)
+	case *ast.ParenExpr:
+		return goRecvType(x.X)
+	case *ast.IndexExpr:
+		return goRecvType(x.X)
+	case *ast.Ident:
+		return x.Name
+	}
+	return ""
+}
+
+// mdHeading returns the level and title of given Markdown line if it is an
+// ATX heading, e.g., ## Title -- 0 if not
+func mdHeading(ln string) (int, string) {
+	t := strings.TrimLeft(ln, " ")
+	if len(ln)-len(t) > 3 {
+		return 0, "" // indented code
+	}
+	lev := 0
+	for lev < len(t) && t[lev] == '#' {
+		lev++
+	}
+	if lev == 0 || lev > 6 || (lev < len(t) && t[lev] != ' ' && t[lev] != '\t') {
+		return 0, ""
+	}
+	tl := strings.TrimSpace(t[lev:])
+	tl = strings.TrimSpace(strings.TrimRight(tl, "#")) // optional closing #s
+	return lev, tl
+}
+
+// MarkdownOutline returns the outline of given Markdown lines: its sections,
+// with the sections within each, from its # headings and its headings
+// underlined with === or --- -- code blocks are skipped
+func MarkdownOutline(lines [][]rune) []OutlineSym {
+	var flat []OutlineSym
+	var levs []int
+	fence := ""
+	for i, lr := range lines {
+		ln := string(lr)
+		t := strings.TrimSpace(ln)
+		if fence != "" {
+			if strings.HasPrefix(t, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+			fence = t[:3]
+			continue
+		}
+		if lev, tl := mdHeading(ln); lev > 0 {
+			if tl != "" {
+				flat = append(flat, OutlineSym{Name: tl, Kind: "section", Detail: fmt.Sprintf("h%d", lev), Ln: i, Ch: utf8.RuneCountInString(ln[:strings.Index(ln, tl)])})
+				levs = append(levs, lev)
+			}
+			continue
+		}
+		if i == 0 || t == "" || (strings.Trim(t, "=") != "" && strings.Trim(t, "-") != "") {
+			continue
+		}
+		prv := strings.TrimSpace(string(lines[i-1]))
+		if plev, _ := mdHeading(string(lines[i-1])); prv == "" || plev > 0 || strings.HasPrefix(prv, "-") || (len(flat) > 0 && flat[len(flat)-1].Ln == i-1) {
+			continue // not a heading, e.g., a rule after a list
+		}
+		lev := 1
+		if t[0] == '-' {
+			lev = 2
+		}
+		pl := string(lines[i-1])
+		flat = append(flat, OutlineSym{Name: prv, Kind: "section", Detail: fmt.Sprintf("h%d", lev), Ln: i - 1, Ch: utf8.RuneCountInString(pl[:strings.Index(pl, prv)])})
+		levs = append(levs, lev)
+	}
+	for i := range flat { // each section ends before the next one at its level or above
+		flat[i].EdLn = len(lines) - 1
+		for j := i + 1; j < len(flat); j++ {
+			if levs[j] <= levs[i] {
+				flat[i].EdLn = flat[j].Ln - 1
+				break
+			}
+		}
+	}
+	return outlineNest(flat, levs)
+}
+
+// outlineNest nests given symbols by their levels, each symbol having those
+// with higher levels after it as its kids
+func outlineNest(flat []OutlineSym, levs []int) []OutlineSym {
+	var out []OutlineSym
+	for i := 0; i < len(flat); {
+		j := i + 1
+		for j < len(flat) && levs[j] > levs[i] {
+			j++
+		}
+		s := flat[i]
+		s.Kids = outlineNest(flat[i+1:j], levs[i+1:j])
+		out = append(out, s)
+		i = j
+	}
+	return out
+}
+
+// OutlineFlat returns the symbols of given outline and all of their kids,
+// in order and without their kids, with the name of each kid prefixed by
+// the names of its parents, e.g., Type.Method
+func OutlineFlat(syms []OutlineSym) []OutlineSym {
+	var out []OutlineSym
+	var flat func(syms []OutlineSym, pfx string)
+	flat = func(syms []OutlineSym, pfx string) {
+		for _, s := range syms {
+			kids := s.Kids
+			s.Kids = nil
+			s.Name = pfx + s.Name
+			out = append(out, s)
+			flat(kids, s.Name+".")
+		}
+	}
+	flat(syms, "")
+	return out
+}
+
+// FuzzyScore returns how well given pattern matches given string, ignoring
+// case, where all the chars of the pattern must be in the string, in order
+// -- matches at the start of words, and of consecutive chars, score higher,
+// and exact matches highest -- false if it does not match
+func FuzzyScore(pat, s string) (int, bool) {
+	pr := []rune(strings.ToLower(pat))
+	sr := []rune(s)
+	if len(pr) == 0 {
+		return 0, true
+	}
+	score := 0
+	pi := 0
+	last := -2
+	for i, r := range sr {
+		if pi == len(pr) {
+			break
+		}
+		if unicode.ToLower(r) != pr[pi] {
+			continue
+		}
+		score++
+		if i == last+1 {
+			score += 5
+		}
+		if i == 0 || !unicode.IsLetter(sr[i-1]) && !unicode.IsDigit(sr[i-1]) || unicode.IsUpper(r) && unicode.IsLower(sr[i-1]) {
+			score += 8
+		}
+		if last < 0 {
+			score -= (i + 3) / 4 // the gap before the first match counts less
+		} else {
+			score -= i - last - 1
+		}
+		last = i
+		pi++
+	}
+	if pi < len(pr) {
+		return 0, false
+	}
+	if strings.EqualFold(pat, s) {
+		score += 100
+	} else if nm := s[strings.LastIndex(s, ".")+1:]; strings.EqualFold(pat, nm) {
+		score += 50
+	}
+	return score, true
+}
+
+// OutlineFuzzy returns the symbols of given outline, flattened as in
+// OutlineFlat, that match given pattern, best match first
+func OutlineFuzzy(pat string, syms []OutlineSym) []OutlineSym {
+	var out []OutlineSym
+	var scores []int
+	for _, s := range OutlineFlat(syms) {
+		if sc, ok := FuzzyScore(pat, s.Name); ok {
+			out = append(out, s)
+			scores = append(scores, sc)
+		}
+	}
+	idx := make([]int, len(out))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return scores[idx[i]] > scores[idx[j]]
+	})
+	res := make([]OutlineSym, len(out))
+	for i, ix := range idx {
+		res[i] = out[ix]
+	}
+	return res
+}
+
+// OutlineDelay is how long after an edit the Outline panel is refreshed,
+// so that it is not refreshed on each key
+var OutlineDelay = 500 * time.Millisecond
+
+// OutlineJumpMax is the maximum number of matches offered by SymbolJump
+var OutlineJumpMax = 30
+
+// BufOutline returns the outline of given buffer, from its language server
+// if it has one that provides symbols, and otherwise from go/parser for Go
+// files and from the headings of Markdown files, with where it came from
+func (ge *Gide) BufOutline(tb *giv.TextBuf) ([]OutlineSym, string, error) {
+	if lc := ge.LspClientForBuf(tb); lc != nil {
+		if prov := lc.Caps["documentSymbolProvider"]; prov != nil && prov != false {
+			syms, err := lc.DocumentSymbols(string(tb.Filename))
+			if err == nil {
+				return OutlineFromLsp(syms), lc.Server.Cmd, nil
+			}
+			log.Printf("gide.BufOutline: language server error: %v\n", err)
+		}
+	}
+	fn := string(tb.Filename)
+	switch {
+	case LangNamesMatchFilename(fn, LangNames{"Go"}):
+		syms, err := GoOutline(tb.LinesToBytesCopy())
+		return syms, "go/parser", err
+	case LangNamesMatchFilename(fn, LangNames{"Markdown"}):
+		return MarkdownOutline(tb.Lines), "headings", nil
+	}
+	return nil, "", fmt.Errorf("no outline for %v: no language server provides symbols for it", tb.Filename)
+}
+
+// Outline shows the outline of the file in the active view in the Outline
+// panel: its functions, types, methods or sections, with links to each one
+// -- it follows the active view, and is kept up to date with edits
+func (ge *Gide) Outline() {
+	tbuf, _ := ge.FindOrMakeCmdBuf("Outline", true)
+	ovi, _ := ge.FindOrMakeMainTab("Outline", KiT_OutlineView, true) // sel
+	ov := ovi.Embed(KiT_OutlineView).(*OutlineView)
+	ov.UpdateView(ge)
+	otv := ov.TextView()
+	otv.SetInactive()
+	otv.SetBuf(tbuf)
+	ov.Buf = nil
+	ge.OutlineActive()
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// outlineView returns the Outline panel, if it is open
+func (ge *Gide) outlineView() (*OutlineView, bool) {
+	ovi, _, ok := ge.MainTabByName("Outline")
+	if !ok {
+		return nil, false
+	}
+	ov, ok := ovi.Embed(KiT_OutlineView).(*OutlineView)
+	return ov, ok
+}
+
+// OutlineActive shows the outline of the file in the active view in the
+// Outline panel, if it is open and showing another file -- called when the
+// active view changes
+func (ge *Gide) OutlineActive() {
+	ov, ok := ge.outlineView()
+	if !ok {
+		return
+	}
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.Buf == ov.Buf {
+		return
+	}
+	ov.Buf = tv.Buf
+	ov.Refresh()
+}
+
+// OutlineEdit refreshes the Outline panel shortly after given buffer is
+// edited, if it is showing its outline
+func (ge *Gide) OutlineEdit(tb *giv.TextBuf) {
+	if ov, ok := ge.outlineView(); ok && ov.Buf == tb {
+		ov.RefreshLater()
+	}
+}
+
+// OpenOutlineURL opens given outline:/// url from the Outline panel --
+// delegates to OutlineView
+func (ge *Gide) OpenOutlineURL(ur string, otv *giv.TextView) bool {
+	ovk, ok := otv.ParentByType(KiT_OutlineView, true)
+	if !ok {
+		return false
+	}
+	return ovk.(*OutlineView).OpenOutlineURL(ur, otv)
+}
+
+// SymbolJump prompts for (part of) the name of a symbol in the file in the
+// active view, and jumps to it -- the name is matched fuzzily, e.g., tvsb
+// matches TextView.SetBuf, and if more than one symbol matches, they are
+// offered in a menu, best first
+func (ge *Gide) SymbolJump() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	syms, _, err := ge.BufOutline(tv.Buf)
+	if len(syms) == 0 {
+		if err != nil {
+			ge.SetStatus(err.Error())
+		} else {
+			ge.SetStatus("No symbols in " + filepath.Base(string(tv.Buf.Filename)))
+		}
+		return
+	}
+	gi.StringPromptDialog(ge.Viewport, "", "symbol name, e.g., tvsb for TextView.SetBuf",
+		gi.DlgOpts{Title: "Jump to Symbol", Prompt: "Jump to the symbol in this file that best matches the name -- its chars only need to be in order"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			gee, _ := recv.Embed(KiT_Gide).(*Gide)
+			pat := strings.TrimSpace(gi.StringPromptDialogValue(send.(*gi.Dialog)))
+			ms := OutlineFuzzy(pat, syms)
+			switch {
+			case len(ms) == 0:
+				gee.SetStatus("No symbol matches " + pat)
+			case len(ms) == 1 || strings.EqualFold(ms[0].Name, pat):
+				gee.outlineJump(tv, ms[0])
+			default:
+				if len(ms) > OutlineJumpMax {
+					ms = ms[:OutlineJumpMax]
+				}
+				nms := make([]string, len(ms))
+				for i, s := range ms {
+					nms[i] = s.Name + "  (" + s.Kind + ")"
+				}
+				gi.StringsChooserPopup(nms, nms[0], tv, func(recv, send ki.Ki, sig int64, data interface{}) {
+					ac := send.(*gi.Action)
+					gee.outlineJump(tv, ms[ac.Data.(int)])
+				})
+			}
+		})
+}
+
+// outlineJump moves the cursor of given view to given symbol, highlighting
+// its name
+func (ge *Gide) outlineJump(tv *giv.TextView, s OutlineSym) {
+	nm := s.Name[strings.LastIndex(s.Name, ".")+1:]
+	st := giv.TextPos{Ln: s.Ln, Ch: s.Ch}
+	tv.HighlightRegion(giv.TextRegion{Start: st, End: giv.TextPos{Ln: s.Ln, Ch: s.Ch + utf8.RuneCountInString(nm)}})
+	tv.SetCursorShow(st)
+	tv.GrabFocus()
+}
+
+// OutlineView is a widget that displays the outline of the file in the
+// active view -- its symbols, nested within each other -- in a TextView with
+// links to each one, filtered by a fuzzy match of their names
+type OutlineView struct {
+	gi.Layout
+	Gide   *Gide        `json:"-" xml:"-" desc:"parent gide project"`
+	Buf    *giv.TextBuf `json:"-" xml:"-" desc:"buffer whose outline is shown"`
+	Syms   []OutlineSym `json:"-" xml:"-" desc:"current outline"`
+	Source string       `json:"-" xml:"-" desc:"where the outline came from, e.g., the language server or go/parser"`
+	Filter string       `desc:"only the symbols whose names match this, fuzzily, are shown, best first"`
+	Mu     sync.Mutex   `json:"-" xml:"-" view:"-" desc:"mutex protecting the outline and timer"`
+	timer  *time.Timer
+}
+
+var KiT_OutlineView = kit.Types.AddType(&OutlineView{}, OutlineViewProps)
+
+// Refresh gets the outline of the buffer again, and shows it
+func (ov *OutlineView) Refresh() {
+	tb := ov.Buf
+	if tb == nil {
+		return
+	}
+	syms, src, err := ov.Gide.BufOutline(tb)
+	if err != nil && len(syms) == 0 {
+		src = err.Error()
+	}
+	ov.Mu.Lock()
+	ov.Syms = syms
+	ov.Source = src
+	ov.Mu.Unlock()
+	ov.ShowResults()
+}
+
+// RefreshLater refreshes the outline after OutlineDelay, unless it is asked
+// to again before then
+func (ov *OutlineView) RefreshLater() {
+	ov.Mu.Lock()
+	defer ov.Mu.Unlock()
+	if ov.timer != nil {
+		ov.timer.Stop()
+	}
+	ov.timer = time.AfterFunc(OutlineDelay, ov.Refresh)
+}
+
+// ShowResults renders the current outline into the results buffer
+func (ov *OutlineView) ShowResults() {
+	tbuf, _ := ov.Gide.FindOrMakeCmdBuf("Outline", true)
+	tb := ov.Buf
+	if tb == nil {
+		return
+	}
+	fp := string(tb.Filename)
+
+	ov.Mu.Lock()
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	lstr := fmt.Sprintf("%v: %v", filepath.Base(fp), ov.Source)
+	outlns = append(outlns, []byte(lstr))
+	outmus = append(outmus, []byte(fmt.Sprintf(`<b>%v</b>: %v`, html.EscapeString(filepath.Base(fp)), html.EscapeString(ov.Source))))
+	add := func(s OutlineSym, depth int) {
+		ind := strings.Repeat("\t", depth+1)
+		ln := s.Ln + 1
+		ch := s.Ch + 1
+		ech := ch + utf8.RuneCountInString(s.Name[strings.LastIndex(s.Name, ".")+1:])
+		nm := html.EscapeString(s.Name)
+		det := html.EscapeString(s.Detail)
+		outlns = append(outlns, []byte(fmt.Sprintf(`%v%v %v %v`, ind, s.Name, s.Kind, s.Detail)))
+		outmus = append(outmus, []byte(fmt.Sprintf(`%v<a href="outline:///%v#L%vC%v-L%vC%v">%v</a> <i>%v</i> %v`, ind, fp, ln, ch, ln, ech, nm, s.Kind, det)))
+	}
+	if ov.Filter != "" {
+		for _, s := range OutlineFuzzy(ov.Filter, ov.Syms) {
+			add(s, 0)
+		}
+	} else {
+		var tree func(syms []OutlineSym, depth int)
+		tree = func(syms []OutlineSym, depth int) {
+			for _, s := range syms {
+				add(s, depth)
+				tree(s.Kids, depth+1)
+			}
+		}
+		tree(ov.Syms, 0)
+	}
+	ov.Mu.Unlock()
+
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	tbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+}
+
+// OpenOutlineURL opens given outline:/// url from the outline
+func (ov *OutlineView) OpenOutlineURL(ur string, otv *giv.TextView) bool {
+	ge := ov.Gide
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("OutlineView OpenOutlineURL parse err: %v\n", err)
+		return false
+	}
+	fpath := up.Path[1:] // has double //
+	pos := up.Fragment
+	etv, _, ok := ge.LinkViewFile(gi.FileName(fpath))
+	if !ok {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Open File at Link", Prompt: fmt.Sprintf("Could not find or open file path in project: %v", fpath)}, true, false, nil, nil)
+		return false
+	}
+	reg := giv.TextRegion{}
+	if pos != "" && reg.FromString(pos) {
+		etv.HighlightRegion(reg)
+		etv.SetCursorShow(reg.Start)
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (ov *OutlineView) UpdateView(ge *Gide) {
+	ov.Gide = ge
+	mods, updt := ov.StdOutlineConfig()
+	ov.ConfigToolbar()
+	ov.FilterText().SetText(ov.Filter)
+	ovly := ov.TextViewLay()
+	ov.Gide.ConfigOutputTextView(ovly)
+	if mods {
+		ov.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (ov *OutlineView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "outlinebar")
+	config.Add(gi.KiT_Layout, "outlinetext")
+	return config
+}
+
+// StdOutlineConfig configures a standard setup of the overall layout --
+// returns mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (ov *OutlineView) StdOutlineConfig() (mods, updt bool) {
+	ov.Lay = gi.LayoutVert
+	ov.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := ov.StdConfig()
+	mods, updt = ov.ConfigChildren(config, false)
+	return
+}
+
+// OutlineBar returns the outline toolbar
+func (ov *OutlineView) OutlineBar() *gi.ToolBar {
+	tbi, ok := ov.ChildByName("outlinebar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// FilterText returns the filter textfield in toolbar
+func (ov *OutlineView) FilterText() *gi.TextField {
+	tb := ov.OutlineBar()
+	if tb == nil {
+		return nil
+	}
+	tfi, ok := tb.ChildByName("filter", 1)
+	if !ok {
+		return nil
+	}
+	return tfi.(*gi.TextField)
+}
+
+// TextViewLay returns the outline TextView layout
+func (ov *OutlineView) TextViewLay() *gi.Layout {
+	tvi, ok := ov.ChildByName("outlinetext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the outline TextView
+func (ov *OutlineView) TextView() *giv.TextView {
+	ovly := ov.TextViewLay()
+	if ovly == nil {
+		return nil
+	}
+	return ovly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (ov *OutlineView) ConfigToolbar() {
+	tb := ov.OutlineBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	refresh := tb.AddNewChild(gi.KiT_Action, "refresh").(*gi.Action)
+	refresh.SetText("Refresh")
+	refresh.Tooltip = "Get the outline of the file in the active view again -- it is refreshed after each edit, and when the active view changes"
+	refresh.ActionSig.Connect(ov.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ovv, _ := recv.Embed(KiT_OutlineView).(*OutlineView)
+		ovv.Buf = nil
+		ovv.Gide.OutlineActive()
+	})
+
+	filter := tb.AddNewChild(gi.KiT_TextField, "filter").(*gi.TextField)
+	filter.SetStretchMaxWidth()
+	filter.Tooltip = "Only show the symbols whose names match this, fuzzily, best first, e.g., tvsb for TextView.SetBuf -- hit enter to filter, clear to show the whole outline"
+	filter.TextFieldSig.Connect(ov.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			ovv, _ := recv.Embed(KiT_OutlineView).(*OutlineView)
+			ovv.Filter = strings.TrimSpace(send.(*gi.TextField).Text())
+			ovv.ShowResults()
+		}
+	})
+}
+
+var OutlineViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
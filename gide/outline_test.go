@@ -0,0 +1,149 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"testing"
+)
+
+func outlineNames(syms []OutlineSym) string {
+	var nms []string
+	for _, s := range OutlineFlat(syms) {
+		nms = append(nms, s.Name)
+	}
+	return strings.Join(nms, " ")
+}
+
+func TestGoOutline(t *testing.T) {
+	src := `package x
+
+const Max = 3
+
+// Buf is a buffer
+type Buf struct {
+	Lines []string
+	n, é  int
+}
+
+func (b *Buf) Len() int { return b.n }
+
+type Sizer interface {
+	Size() int
+}
+
+func New(n int) (*Buf, error) { return nil, nil }
+
+func (b Buf) Add(s string) {}
+
+func (o *Other) Gone() {}
+
+var _, pkgVar = 1, 2
+`
+	syms, err := GoOutline([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := outlineNames(syms), "Max Buf Buf.Lines Buf.n Buf.é Buf.Len Buf.Add Sizer Sizer.Size New Other.Gone pkgVar"; got != want {
+		t.Errorf("names:\n got %q\nwant %q", got, want)
+	}
+	flat := OutlineFlat(syms)
+	for _, s := range flat {
+		switch s.Name {
+		case "Buf":
+			if s.Kind != "struct" || s.Ln != 5 || s.Ch != 5 {
+				t.Errorf("Buf: %+v", s)
+			}
+		case "Buf.Len":
+			if s.Kind != "method" || s.Detail != "() int" || s.Ln != 10 || s.Ch != 14 {
+				t.Errorf("Buf.Len: %+v", s)
+			}
+		case "New":
+			if s.Kind != "func" || s.Detail != "(n int) (*Buf, error)" {
+				t.Errorf("New: %+v", s)
+			}
+		case "Sizer.Size":
+			if s.Detail != "() int" {
+				t.Errorf("Sizer.Size: %+v", s)
+			}
+		}
+	}
+	syms, err = GoOutline([]byte("package x\n\nfunc A() {}\n\nfunc B( {\n"))
+	if err == nil || len(syms) == 0 || syms[0].Name != "A" {
+		t.Errorf("syntax error: %v %+v", err, syms)
+	}
+}
+
+func TestMarkdownOutline(t *testing.T) {
+	md := `# Title
+
+Intro
+=====
+
+## One
+
+` + "```" + `
+# not a heading
+` + "```" + `
+
+### One.a ###
+
+- item
+---
+
+Two
+---
+
+#nospace
+`
+	var lines [][]rune
+	for _, l := range strings.Split(md, "\n") {
+		lines = append(lines, []rune(l))
+	}
+	syms := MarkdownOutline(lines)
+	if got, want := outlineNames(syms), "Title Intro Intro.One Intro.One.One.a Intro.Two"; got != want {
+		t.Errorf("names:\n got %q\nwant %q", got, want)
+	}
+	if len(syms) != 2 || syms[1].Ln != 2 || syms[1].Detail != "h1" {
+		t.Errorf("Intro: %+v", syms)
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := FuzzyScore("tvsb", "TextView.SetBuf"); !ok {
+		t.Error("tvsb should match TextView.SetBuf")
+	}
+	if _, ok := FuzzyScore("bst", "TextView.SetBuf"); ok {
+		t.Error("bst should not match TextView.SetBuf")
+	}
+	syms := []OutlineSym{
+		{Name: "SetBufSize"},
+		{Name: "TextView", Kids: []OutlineSym{{Name: "SetBuf"}, {Name: "Subset"}}},
+		{Name: "setbuf"},
+	}
+	ms := OutlineFuzzy("setbuf", syms)
+	if got, want := outlineNames(ms), "setbuf TextView.SetBuf SetBufSize"; got != want {
+		t.Errorf("setbuf:\n got %q\nwant %q", got, want)
+	}
+	ms = OutlineFuzzy("tvs", syms)
+	if len(ms) == 0 || ms[0].Name != "TextView.SetBuf" {
+		t.Errorf("tvs: %q", outlineNames(ms))
+	}
+}
+
+func TestLspSymbolTree(t *testing.T) {
+	infos := []LspSymbolInformation{
+		{Name: "M", Kind: 6, ContainerName: "T", Location: LspLocation{URI: "file:///a.go", Range: LspRange{Start: LspPosition{Line: 9}}}},
+		{Name: "T", Kind: 23, Location: LspLocation{URI: "file:///a.go", Range: LspRange{Start: LspPosition{Line: 2}}}},
+		{Name: "F", Kind: 12, ContainerName: "x", Location: LspLocation{URI: "file:///a.go", Range: LspRange{Start: LspPosition{Line: 5}}}},
+	}
+	syms := OutlineFromLsp(LspSymbolTree(infos))
+	if got, want := outlineNames(syms), "T T.M F"; got != want {
+		t.Errorf("names:\n got %q\nwant %q", got, want)
+	}
+	if syms[0].Kind != "struct" || syms[0].Kids[0].Kind != "method" {
+		t.Errorf("kinds: %+v", syms)
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+)
+
+// The two main text view panels in the splitter can each be split further,
+// horizontally and vertically, into any number of panes: a pane is split by
+// replacing its TextView with a SplitView holding it and the new TextView,
+// or, if it is already in a SplitView in the same dimension, by adding the
+// new TextView after it.  All the text views are in Gide.PaneViews, where the
+// first NTextViews are the main ones, which are never closed, so indexes of
+// text views work as before.
+
+// PaneNode is the layout of the panes in one of the main text view panels,
+// as saved with the project: either a single pane, showing a file, or a split
+// into sub-panes
+type PaneNode struct {
+	Dim    gi.Dims    `desc:"dimension along which the sub-panes are arranged, if split"`
+	Splits []float32  `desc:"proportion of the space for each sub-pane, if split"`
+	Kids   []PaneNode `desc:"the sub-panes, if split"`
+	File   string     `desc:"file shown in a single pane, relative to the project root"`
+}
+
+// NPanes returns the number of panes in the layout
+func (pn *PaneNode) NPanes() int {
+	if len(pn.Kids) == 0 {
+		return 1
+	}
+	n := 0
+	for i := range pn.Kids {
+		n += pn.Kids[i].NPanes()
+	}
+	return n
+}
+
+// PaneSlot returns which of the main text view panels (0 or 1) the pane with
+// given index is in, or -1 if not found
+func (ge *Gide) PaneSlot(idx int) int {
+	if idx < 0 || idx >= len(ge.PaneViews) {
+		return -1
+	}
+	split := ge.SplitView()
+	if split == nil {
+		return -1
+	}
+	for k := ge.PaneViews[idx].This(); k.Parent() != nil; k = k.Parent() {
+		if k.Parent() == split.This() {
+			si, _ := k.IndexInParent()
+			return si - TextView1Idx
+		}
+	}
+	return -1
+}
+
+// PaneIsOpen returns true if the main text view panel that the pane with
+// given index is in has not been collapsed
+func (ge *Gide) PaneIsOpen(idx int) bool {
+	slot := ge.PaneSlot(idx)
+	return slot >= 0 && ge.PanelIsOpen(slot+TextView1Idx)
+}
+
+// ConfigPaneView configures a new text view pane
+func (ge *Gide) ConfigPaneView(tv *giv.TextView) {
+	tv.SetStretchMaxWidth()
+	tv.SetStretchMaxHeight()
+	tv.TextViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gee, _ := recv.Embed(KiT_Gide).(*Gide)
+		tee := send.Embed(giv.KiT_TextView).(*giv.TextView)
+		gee.TextViewSig(tee, giv.TextViewSignals(sig))
+	})
+	ge.ConfigPaneStyle(tv)
+}
+
+// ConfigPaneStyle sets the style properties of a text view pane from the
+// project editor prefs
+func (ge *Gide) ConfigPaneStyle(tv *giv.TextView) {
+	if ge.Prefs.Editor.WordWrap {
+		tv.SetProp("white-space", gi.WhiteSpacePreWrap)
+	} else {
+		tv.SetProp("white-space", gi.WhiteSpacePre)
+	}
+	tv.SetProp("tab-size", ge.Prefs.Editor.TabSize)
+	tv.SetProp("font-family", Prefs.FontFamily)
+}
+
+// addPane adds given new text view to the list of panes, returning its index
+func (ge *Gide) addPane(tv *giv.TextView) int {
+	ge.PaneViews = append(ge.PaneViews, tv)
+	ge.lockLns = append(ge.lockLns, 0)
+	ge.foldLns = append(ge.foldLns, 0)
+	return len(ge.PaneViews) - 1
+}
+
+// removePane removes the pane with given index from the list of panes
+func (ge *Gide) removePane(idx int) {
+	ge.PaneViews = append(ge.PaneViews[:idx], ge.PaneViews[idx+1:]...)
+	ge.lockLns = append(ge.lockLns[:idx], ge.lockLns[idx+1:]...)
+	ge.foldLns = append(ge.foldLns[:idx], ge.foldLns[idx+1:]...)
+}
+
+// evenSplits sets the splits of given SplitView to be all the same
+func evenSplits(sv *gi.SplitView) {
+	n := len(sv.Kids)
+	sp := make([]float32, n)
+	for i := range sp {
+		sp[i] = 1 / float32(n)
+	}
+	sv.SetSplits(sp...)
+}
+
+// SplitPaneOf splits given text view pane along given dimension (X = side by
+// side, Y = one above the other), returning the new pane, which shows the
+// same buffer
+func (ge *Gide) SplitPaneOf(tv *giv.TextView, dim gi.Dims) *giv.TextView {
+	par := tv.Parent()
+	at, _ := tv.IndexInParent()
+	nm := fmt.Sprintf("textview-%v", len(ge.PaneViews))
+	updt := ge.UpdateStart()
+	ge.SetFullReRender()
+	var nv *giv.TextView
+	if psv, ok := par.(*gi.SplitView); ok && psv.Dim == dim {
+		nv = psv.InsertNewChild(giv.KiT_TextView, at+1, nm).(*giv.TextView)
+		evenSplits(psv)
+	} else {
+		nsv := par.InsertNewChild(gi.KiT_SplitView, at, "panes-"+nm).(*gi.SplitView)
+		nsv.Dim = dim
+		nsv.SetStretchMaxWidth()
+		nsv.SetStretchMaxHeight()
+		nsv.SetMinPrefWidth(units.NewValue(10, units.Ch))
+		nsv.SetMinPrefHeight(units.NewValue(5, units.Ch))
+		par.DeleteChild(tv, false)
+		nsv.AddChild(tv)
+		nv = nsv.AddNewChild(giv.KiT_TextView, nm).(*giv.TextView)
+		evenSplits(nsv)
+	}
+	ge.ConfigPaneView(nv)
+	ge.addPane(nv)
+	if tv.Buf != nil {
+		nv.SetBuf(tv.Buf)
+		nv.CursorPos = tv.CursorPos
+	}
+	ge.UpdateEnd(updt)
+	return nv
+}
+
+// SplitPane splits the active pane along given dimension (X = side by side,
+// Y = one above the other), with the new pane showing the same file
+func (ge *Gide) SplitPane(dim gi.Dims) {
+	av := ge.ActiveTextView()
+	if av == nil {
+		return
+	}
+	nv := ge.SplitPaneOf(av, dim)
+	ge.SetActiveTextViewIdx(ge.TextViewIndex(nv))
+	ge.SetStatus(fmt.Sprintf("Split into %d panes", len(ge.PaneViews)))
+}
+
+// SplitPaneH splits the active pane into two, side by side
+func (ge *Gide) SplitPaneH() {
+	ge.SplitPane(gi.X)
+}
+
+// SplitPaneV splits the active pane into two, one above the other
+func (ge *Gide) SplitPaneV() {
+	ge.SplitPane(gi.Y)
+}
+
+// closePaneView deletes given pane, which must not be one of the main text
+// views, collapsing its SplitView into its parent if only one pane remains
+func (ge *Gide) closePaneView(idx int) {
+	tv := ge.PaneViews[idx]
+	par := tv.Parent()
+	ge.removePane(idx)
+	par.DeleteChild(tv, true)
+	psv, ok := par.(*gi.SplitView)
+	if !ok {
+		return
+	}
+	if len(psv.Kids) > 1 {
+		evenSplits(psv)
+		return
+	}
+	gp := psv.Parent()
+	at, _ := psv.IndexInParent()
+	last := psv.Kids[0]
+	psv.DeleteChild(last, false)
+	gp.DeleteChild(psv, true)
+	gp.InsertChild(last, at)
+}
+
+// ClosePane closes the active pane, if it was split off from one of the two
+// main text views -- those can only be collapsed using the splitter
+func (ge *Gide) ClosePane() {
+	idx := ge.ActiveTextViewIdx
+	if idx < NTextViews {
+		ge.SetStatus("The main text views cannot be closed -- collapse them with the splitter instead")
+		return
+	}
+	slot := ge.PaneSlot(idx)
+	updt := ge.UpdateStart()
+	ge.SetFullReRender()
+	ge.closePaneView(idx)
+	ge.UpdateEnd(updt)
+	ge.SetActiveTextViewIdx(slot)
+}
+
+// UnsplitPanes closes all the panes that were split off from the two main
+// text views, going back to the standard layout
+func (ge *Gide) UnsplitPanes() {
+	if len(ge.PaneViews) <= NTextViews {
+		return
+	}
+	updt := ge.UpdateStart()
+	ge.SetFullReRender()
+	for len(ge.PaneViews) > NTextViews {
+		ge.closePaneView(len(ge.PaneViews) - 1)
+	}
+	ge.UpdateEnd(updt)
+	if ge.ActiveTextViewIdx >= NTextViews {
+		ge.SetActiveTextViewIdx(0)
+	}
+}
+
+// SwapPanes swaps the files shown in the active pane and the next open pane,
+// to move a file to another pane -- the active pane moves with its file
+func (ge *Gide) SwapPanes() {
+	idx := ge.ActiveTextViewIdx
+	np := len(ge.PaneViews)
+	oi := (idx + 1) % np
+	for oi != idx && !ge.PaneIsOpen(oi) {
+		oi = (oi + 1) % np
+	}
+	if oi == idx {
+		return
+	}
+	av, ov := ge.PaneViews[idx], ge.PaneViews[oi]
+	ab, ob := av.Buf, ov.Buf
+	ap, op := av.CursorPos, ov.CursorPos
+	if ob != nil {
+		av.SetBuf(ob)
+		av.SetCursorShow(op)
+	}
+	if ab != nil {
+		ov.SetBuf(ab)
+		ov.SetCursorShow(ap)
+	}
+	ge.SetActiveTextViewIdx(oi)
+}
+
+// FocusPaneDir moves the focus to the nearest pane in given direction from
+// the active one, with dx, dy = -1, 0, 1 in window coordinates
+func (ge *Gide) FocusPaneDir(dx, dy int) {
+	av := ge.ActiveTextView()
+	if av == nil {
+		return
+	}
+	center := func(r image.Rectangle) image.Point {
+		return r.Min.Add(r.Max).Div(2)
+	}
+	ac := center(av.WinBBox)
+	best := -1
+	bestd := 0
+	for i, tv := range ge.PaneViews {
+		if tv == av || !ge.PaneIsOpen(i) || tv.WinBBox.Empty() {
+			continue
+		}
+		c := center(tv.WinBBox)
+		along := (c.X-ac.X)*dx + (c.Y-ac.Y)*dy
+		if along <= 0 {
+			continue
+		}
+		across := (c.X-ac.X)*dy + (c.Y-ac.Y)*dx
+		if across < 0 {
+			across = -across
+		}
+		d := along + 2*across
+		if best < 0 || d < bestd {
+			best, bestd = i, d
+		}
+	}
+	if best >= 0 {
+		ge.SetActiveTextViewIdx(best)
+	}
+}
+
+// FocusPaneLeft moves the focus to the pane to the left of the active one
+func (ge *Gide) FocusPaneLeft() {
+	ge.FocusPaneDir(-1, 0)
+}
+
+// FocusPaneRight moves the focus to the pane to the right of the active one
+func (ge *Gide) FocusPaneRight() {
+	ge.FocusPaneDir(1, 0)
+}
+
+// FocusPaneUp moves the focus to the pane above the active one
+func (ge *Gide) FocusPaneUp() {
+	ge.FocusPaneDir(0, -1)
+}
+
+// FocusPaneDown moves the focus to the pane below the active one
+func (ge *Gide) FocusPaneDown() {
+	ge.FocusPaneDir(0, 1)
+}
+
+// paneNode returns the layout of the panes under given node
+func (ge *Gide) paneNode(k ki.Ki) PaneNode {
+	if sv, ok := k.(*gi.SplitView); ok {
+		pn := PaneNode{Dim: sv.Dim, Splits: append([]float32{}, sv.Splits...)}
+		for _, kid := range sv.Kids {
+			pn.Kids = append(pn.Kids, ge.paneNode(kid))
+		}
+		return pn
+	}
+	pn := PaneNode{}
+	tv := k.Embed(giv.KiT_TextView).(*giv.TextView)
+	if tv.Buf != nil && tv.Buf.Filename != "" {
+		fn := string(tv.Buf.Filename)
+		if rp, err := filepath.Rel(string(ge.ProjRoot), fn); err == nil {
+			fn = filepath.ToSlash(rp)
+		}
+		pn.File = fn
+	}
+	return pn
+}
+
+// PanesLayout returns the current layout of the panes in each of the main
+// text view panels, for saving with the project
+func (ge *Gide) PanesLayout() []PaneNode {
+	split := ge.SplitView()
+	if split == nil {
+		return nil
+	}
+	pns := make([]PaneNode, NTextViews)
+	for i := range pns {
+		txly := split.KnownChild(TextView1Idx + i).(*gi.Layout)
+		pns[i] = ge.paneNode(txly.KnownChild(0))
+	}
+	return pns
+}
+
+// restorePane recreates the layout of given node in given pane
+func (ge *Gide) restorePane(tv *giv.TextView, pn *PaneNode) {
+	if len(pn.Kids) == 0 {
+		if pn.File == "" {
+			return
+		}
+		fnm := filepath.Join(string(ge.ProjRoot), filepath.FromSlash(pn.File))
+		if fnk, ok := ge.Files.FindFile(fnm); ok {
+			fn := fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode)
+			ge.ViewFileNode(tv, ge.TextViewIndex(tv), fn)
+		}
+		return
+	}
+	tvs := []*giv.TextView{tv}
+	for i := 1; i < len(pn.Kids); i++ {
+		tvs = append(tvs, ge.SplitPaneOf(tvs[i-1], pn.Dim))
+	}
+	psv := tv.Parent().(*gi.SplitView)
+	for i, kv := range tvs {
+		ge.restorePane(kv, &pn.Kids[i])
+	}
+	if len(pn.Splits) == len(psv.Kids) {
+		psv.SetSplits(pn.Splits...)
+	}
+}
+
+// RestorePanes recreates the saved layout of panes, with the files they show
+func (ge *Gide) RestorePanes() {
+	for i := 0; i < NTextViews && i < len(ge.Prefs.Panes); i++ {
+		pn := &ge.Prefs.Panes[i]
+		if len(pn.Kids) == 0 {
+			continue // only restore split layouts -- files open as usual
+		}
+		ge.restorePane(ge.PaneViews[i], pn)
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/ki/kit"
+)
+
+// PowerMode is how gide adapts its background work -- symbol indexing,
+// semantic highlighting, and checking files for changes on disk -- to the
+// power of the machine
+type PowerMode int
+
+const (
+	// PowerAuto reduces background work when on battery power or under
+	// thermal pressure
+	PowerAuto PowerMode = iota
+
+	// PowerSave always reduces background work
+	PowerSave
+
+	// PowerFull never reduces background work
+	PowerFull
+
+	PowerModeN
+)
+
+//go:generate stringer -type=PowerMode
+
+var KiT_PowerMode = kit.Enums.AddEnumAltLower(PowerModeN, false, nil, "Power")
+
+func (ev PowerMode) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *PowerMode) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// PowerInterval is how often the power state of the machine is checked
+var PowerInterval = 30 * time.Second
+
+// PowerSlowdown is how many times less often the files of the open buffers
+// are checked for changes on disk when background work is reduced
+var PowerSlowdown = 4
+
+// PowerHotTemp is the temperature of a thermal zone, in degrees Celsius,
+// from which the machine is taken to be under thermal pressure, on Linux
+var PowerHotTemp = 85
+
+// PowerState is the power state of the machine
+type PowerState struct {
+	Battery   bool `desc:"the machine has a battery"`
+	OnBattery bool `desc:"the machine is running on its battery"`
+	Charge    int  `desc:"charge of the battery, in percent -- -1 if unknown"`
+	Hot       bool `desc:"the machine is under thermal pressure: it is hot, or its cpu is throttled"`
+}
+
+// Low returns true if background work should be reduced in this state: on
+// battery or under thermal pressure
+func (ps PowerState) Low() bool {
+	return ps.OnBattery || ps.Hot
+}
+
+// PowerLow returns true if background work should be reduced in given mode
+// and power state
+func PowerLow(mode PowerMode, ps PowerState) bool {
+	switch mode {
+	case PowerSave:
+		return true
+	case PowerFull:
+		return false
+	}
+	return ps.Low()
+}
+
+// PowerIndicator returns the indicator for the status bar for given mode
+// and power state -- empty if background work is not reduced
+func PowerIndicator(mode PowerMode, ps PowerState) string {
+	if !PowerLow(mode, ps) {
+		return ""
+	}
+	if mode == PowerSave {
+		return "power save"
+	}
+	var why []string
+	if ps.OnBattery {
+		if ps.Charge >= 0 {
+			why = append(why, fmt.Sprintf("battery %v%%", ps.Charge))
+		} else {
+			why = append(why, "battery")
+		}
+	}
+	if ps.Hot {
+		why = append(why, "hot")
+	}
+	return "power save: " + strings.Join(why, ", ")
+}
+
+// sysRead returns the trimmed contents of given sysfs file -- empty if it
+// can not be read
+func sysRead(fn string) string {
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// LinuxPowerState returns the power state from the power supplies and
+// thermal zones in the sysfs mounted at given root, normally /sys
+func LinuxPowerState(sys string) PowerState {
+	ps := PowerState{Charge: -1}
+	mains, online, discharging := false, false, false
+	sups, _ := filepath.Glob(filepath.Join(sys, "class", "power_supply", "*"))
+	for _, d := range sups {
+		switch sysRead(filepath.Join(d, "type")) {
+		case "Mains", "USB":
+			mains = true
+			if sysRead(filepath.Join(d, "online")) == "1" {
+				online = true
+			}
+		case "Battery":
+			if sysRead(filepath.Join(d, "scope")) == "Device" {
+				continue // e.g., of a mouse
+			}
+			ps.Battery = true
+			if sysRead(filepath.Join(d, "status")) == "Discharging" {
+				discharging = true
+			}
+			if c, err := strconv.Atoi(sysRead(filepath.Join(d, "capacity"))); err == nil {
+				ps.Charge = c
+			}
+		}
+	}
+	ps.OnBattery = ps.Battery && (discharging || (mains && !online))
+	zones, _ := filepath.Glob(filepath.Join(sys, "class", "thermal", "thermal_zone*", "temp"))
+	for _, z := range zones {
+		if t, err := strconv.Atoi(sysRead(z)); err == nil && t >= PowerHotTemp*1000 {
+			ps.Hot = true
+		}
+	}
+	return ps
+}
+
+var (
+	pmsetChargeRe = regexp.MustCompile(`(\d+)%`)
+	pmsetLimitRe  = regexp.MustCompile(`CPU_Speed_Limit\s*=\s*(\d+)`)
+)
+
+// PmsetPowerState returns the power state from the output of pmset -g batt
+// and pmset -g therm, on macOS
+func PmsetPowerState(batt, therm string) PowerState {
+	ps := PowerState{Charge: -1}
+	ps.Battery = strings.Contains(batt, "InternalBattery")
+	ps.OnBattery = strings.Contains(batt, "'Battery Power'")
+	if m := pmsetChargeRe.FindStringSubmatch(batt); m != nil {
+		ps.Charge, _ = strconv.Atoi(m[1])
+	}
+	if m := pmsetLimitRe.FindStringSubmatch(therm); m != nil {
+		if l, _ := strconv.Atoi(m[1]); l < 100 {
+			ps.Hot = true
+		}
+	}
+	return ps
+}
+
+// ReadPowerState returns the power state of the machine, where the OS
+// reports it: on Linux and macOS -- elsewhere it is always on mains power
+func ReadPowerState() PowerState {
+	switch runtime.GOOS {
+	case "linux":
+		return LinuxPowerState("/sys")
+	case "darwin":
+		batt, _ := exec.Command("pmset", "-g", "batt").Output()
+		therm, _ := exec.Command("pmset", "-g", "therm").Output()
+		return PmsetPowerState(string(batt), string(therm))
+	}
+	return PowerState{Charge: -1}
+}
+
+// powerMu guards the Power mode in Prefs, which all the projects share, and
+// the power state recorded by each of them
+var powerMu sync.Mutex
+
+// PowerLow returns true if background work is being reduced: on battery or
+// under thermal pressure, or always in the PowerSave mode (see Power in
+// Preferences)
+func (ge *Gide) PowerLow() bool {
+	powerMu.Lock()
+	defer powerMu.Unlock()
+	return PowerLow(Prefs.Power, ge.power)
+}
+
+// PowerIndicator returns the indicator of reduced background work for the
+// status bar -- empty if it is not reduced
+func (ge *Gide) PowerIndicator() string {
+	powerMu.Lock()
+	defer powerMu.Unlock()
+	return PowerIndicator(Prefs.Power, ge.power)
+}
+
+// PowerUpdate records given power state of the machine, and when that
+// changes whether background work is reduced, updates the status bar, and
+// resumes the work held back when it is no longer reduced, on the GUI
+// thread -- it can be called from any goroutine
+func (ge *Gide) PowerUpdate(ps PowerState) {
+	powerMu.Lock()
+	was := PowerLow(Prefs.Power, ge.power)
+	ind := PowerIndicator(Prefs.Power, ge.power)
+	ge.power = ps
+	low := PowerLow(Prefs.Power, ps)
+	changed := ind != PowerIndicator(Prefs.Power, ps)
+	powerMu.Unlock()
+	if !changed && !(was && !low) {
+		return
+	}
+	ge.RunOnGui(func() {
+		if changed {
+			ge.SetStatus("")
+		}
+		if was && !low {
+			ge.powerResume()
+		}
+	})
+}
+
+// powerResume starts the background work held back while it was reduced:
+// building the symbol and project indexes, and semantic highlighting
+func (ge *Gide) powerResume() {
+	if ge.symIdx != nil {
+		ge.symIdx.Start()
+	}
+	if ge.projIdx != nil {
+		ge.projIdx.Start()
+	}
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil {
+			ge.HiMarkupBuf(ond.Buf)
+			ond.Buf.RefreshViews()
+		}
+	}
+}
+
+// SetPowerMode sets how background work -- symbol indexing, semantic
+// highlighting, and checking files for changes on disk -- adapts to the
+// power of the machine: reduced automatically when on battery or under
+// thermal pressure, always reduced, or never -- shown in the status bar
+func (ge *Gide) SetPowerMode(mode PowerMode) {
+	powerMu.Lock()
+	was := PowerLow(Prefs.Power, ge.power)
+	Prefs.Power = mode
+	low := PowerLow(mode, ge.power)
+	powerMu.Unlock()
+	Prefs.Save()
+	if was && !low {
+		ge.powerResume()
+	}
+	if low {
+		ge.SetStatus("Reducing background work: symbol indexing, semantic highlighting and checking files for changes")
+	} else {
+		ge.SetStatus("Background work at full speed")
+	}
+}
+
+// StartPower starts checking the power state of the machine every
+// PowerInterval, to reduce background work when on battery or under
+// thermal pressure
+func (ge *Gide) StartPower() {
+	ge.PowerUpdate(ReadPowerState())
+	ge.powerStop = make(chan struct{})
+	go func(stop chan struct{}) {
+		tick := time.NewTicker(PowerInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				ge.PowerUpdate(ReadPowerState())
+			}
+		}
+	}(ge.powerStop)
+}
+
+// StopPower stops checking the power state of the machine
+func (ge *Gide) StopPower() {
+	if ge.powerStop != nil {
+		close(ge.powerStop)
+		ge.powerStop = nil
+	}
+}
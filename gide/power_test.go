@@ -0,0 +1,127 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPowerLow(t *testing.T) {
+	batt := PowerState{Battery: true, OnBattery: true, Charge: 42}
+	hot := PowerState{Charge: -1, Hot: true}
+	both := PowerState{Battery: true, OnBattery: true, Charge: -1, Hot: true}
+	mains := PowerState{Battery: true, Charge: 80}
+	tests := []struct {
+		mode PowerMode
+		ps   PowerState
+		low  bool
+		ind  string
+	}{
+		{PowerAuto, mains, false, ""},
+		{PowerAuto, batt, true, "power save: battery 42%"},
+		{PowerAuto, hot, true, "power save: hot"},
+		{PowerAuto, both, true, "power save: battery, hot"},
+		{PowerSave, mains, true, "power save"},
+		{PowerSave, batt, true, "power save"},
+		{PowerFull, both, false, ""},
+	}
+	for _, tt := range tests {
+		if got := PowerLow(tt.mode, tt.ps); got != tt.low {
+			t.Errorf("%v %+v: low %v, want %v", tt.mode, tt.ps, got, tt.low)
+		}
+		if got := PowerIndicator(tt.mode, tt.ps); got != tt.ind {
+			t.Errorf("%v %+v: indicator %q, want %q", tt.mode, tt.ps, got, tt.ind)
+		}
+	}
+}
+
+// powerTestSys writes given sysfs files, by path relative to its root, to a
+// new temporary directory, and returns it
+func powerTestSys(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "gide-power")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for nm, s := range files {
+		fn := filepath.Join(dir, filepath.FromSlash(nm))
+		os.MkdirAll(filepath.Dir(fn), 0755)
+		if err := ioutil.WriteFile(fn, []byte(s+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLinuxPowerState(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  PowerState
+	}{
+		{"desktop", map[string]string{
+			"class/thermal/thermal_zone0/temp": "45000",
+		}, PowerState{Charge: -1}},
+		{"on mains", map[string]string{
+			"class/power_supply/AC/type":       "Mains",
+			"class/power_supply/AC/online":     "1",
+			"class/power_supply/BAT0/type":     "Battery",
+			"class/power_supply/BAT0/status":   "Charging",
+			"class/power_supply/BAT0/capacity": "77",
+		}, PowerState{Battery: true, Charge: 77}},
+		{"discharging", map[string]string{
+			"class/power_supply/AC/type":       "Mains",
+			"class/power_supply/AC/online":     "0",
+			"class/power_supply/BAT0/type":     "Battery",
+			"class/power_supply/BAT0/status":   "Discharging",
+			"class/power_supply/BAT0/capacity": "31",
+		}, PowerState{Battery: true, OnBattery: true, Charge: 31}},
+		{"unplugged, full", map[string]string{
+			"class/power_supply/AC/type":       "Mains",
+			"class/power_supply/AC/online":     "0",
+			"class/power_supply/BAT0/type":     "Battery",
+			"class/power_supply/BAT0/status":   "Full",
+			"class/power_supply/BAT0/capacity": "100",
+		}, PowerState{Battery: true, OnBattery: true, Charge: 100}},
+		{"mouse battery", map[string]string{
+			"class/power_supply/hid-mouse/type":     "Battery",
+			"class/power_supply/hid-mouse/scope":    "Device",
+			"class/power_supply/hid-mouse/status":   "Discharging",
+			"class/power_supply/hid-mouse/capacity": "10",
+		}, PowerState{Charge: -1}},
+		{"hot", map[string]string{
+			"class/thermal/thermal_zone0/temp": "50000",
+			"class/thermal/thermal_zone1/temp": "91000",
+		}, PowerState{Charge: -1, Hot: true}},
+	}
+	for _, tt := range tests {
+		dir := powerTestSys(t, tt.files)
+		if got := LinuxPowerState(dir); got != tt.want {
+			t.Errorf("%v: got %+v, want %+v", tt.name, got, tt.want)
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPmsetPowerState(t *testing.T) {
+	tests := []struct {
+		batt, therm string
+		want        PowerState
+	}{
+		{"Now drawing from 'AC Power'\n -InternalBattery-0 (id=4653155)\t100%; charged; 0:00 remaining present: true\n",
+			"Note: No thermal warning level has been recorded\n", PowerState{Battery: true, Charge: 100}},
+		{"Now drawing from 'Battery Power'\n -InternalBattery-0 (id=4653155)\t64%; discharging; 5:12 remaining present: true\n",
+			"CPU_Scheduler_Limit \t= 100\nCPU_Available_CPUs \t= 8\nCPU_Speed_Limit \t= 100\n", PowerState{Battery: true, OnBattery: true, Charge: 64}},
+		{"Now drawing from 'AC Power'\n", "CPU_Speed_Limit \t= 70\n", PowerState{Charge: -1, Hot: true}},
+		{"", "", PowerState{Charge: -1}},
+	}
+	for _, tt := range tests {
+		if got := PmsetPowerState(tt.batt, tt.therm); got != tt.want {
+			t.Errorf("%q %q: got %+v, want %+v", tt.batt, tt.therm, got, tt.want)
+		}
+	}
+}
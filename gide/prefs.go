@@ -6,6 +6,7 @@ package gide
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"path/filepath"
@@ -26,27 +27,52 @@ type FilePrefs struct {
 
 // EditorPrefs contains editor preferences
 type EditorPrefs struct {
-	TabSize      int  `desc:"size of a tab, in chars -- also determines indent level for space indent"`
-	SpaceIndent  bool `desc:"use spaces for indentation, otherwise tabs"`
-	WordWrap     bool `desc:"wrap lines at word boundaries -- otherwise long lines scroll off the end"`
-	LineNos      bool `desc:"show line numbers"`
-	Completion   bool `desc:"use the completion system to suggest options while typing"`
-	SpellCorrect bool `desc:"suggest corrections for unknown words while typing"`
-	AutoIndent   bool `desc:"automatically indent lines when enter, tab, }, etc pressed"`
-	EmacsUndo    bool `desc:"use emacs-style undo, where after a non-undo command, all the current undo actions are added to the undo stack, such that a subsequent undo is actually a redo"`
+	TabSize         int   `desc:"size of a tab, in chars -- also determines indent level for space indent"`
+	SpaceIndent     bool  `desc:"use spaces for indentation, otherwise tabs"`
+	WordWrap        bool  `desc:"wrap lines at word boundaries -- otherwise long lines scroll off the end"`
+	LineNos         bool  `desc:"show line numbers"`
+	Completion      bool  `desc:"use the completion system to suggest options while typing"`
+	SpellCorrect    bool  `desc:"suggest corrections for unknown words while typing"`
+	SpellCheck      bool  `desc:"underline misspelled words as you type, in the comments and strings of code, and in all of the text of Markdown and plain text files -- see Spelling Suggestions in the Edit menu for corrections"`
+	SigHelp         bool  `desc:"show the signature of the function being called while typing its arguments (requires a language server)"`
+	SemanticHi      bool  `desc:"highlight types, functions, parameters, constants etc distinctly, using the semantic tokens from the language server, on top of the highlighting by syntax"`
+	AutoIndent      bool  `desc:"automatically indent lines when enter, tab, }, etc pressed"`
+	AutoClose       bool  `desc:"automatically insert the closing bracket or quote when an opening one is typed, and skip over it when typed next to it -- the pairs are set per language in AutoPairs"`
+	FillColumn      int   `desc:"column at which comments are wrapped by Reflow Comment"`
+	FormatOnSave    bool  `desc:"format files with the formatter for their language (see Edit Langs) before saving them, e.g., with goimports for Go -- can be set per language in Edit Langs, with OnSave"`
+	TrimOnSave      bool  `desc:"strip the trailing whitespace of the lines changed since a file was opened or last saved before saving it -- only those lines, to keep diffs clean -- can be set per language in Edit Langs, with OnSave"`
+	FinalNLOnSave   bool  `desc:"end files with exactly one newline, removing any blank lines at the end, before saving them -- can be set per language in Edit Langs, with OnSave"`
+	UndoHistory     int   `desc:"maximum number of edits kept in the undo history of each file across sessions, so they can still be undone with Undo Previous Session after it is closed and opened again -- set to 0 in the project prefs to turn this off for a project"`
+	RecoverySecs    int   `desc:"interval, in seconds, at which the unsaved contents of open files are written to recovery files, which are offered for recovery the next time the project is opened if gide does not exit cleanly -- 0 to turn this off"`
+	SaveOnFocusLoss bool  `desc:"save all open files with unsaved changes when the gide window loses the focus, e.g., on switching to a terminal or browser"`
+	IndentGuides    bool  `desc:"draw faint vertical indent guides at each indent level of the indentation of lines -- can be set per language in Edit Langs, and toggled with Toggle Whitespace"`
+	ShowWhitespace  bool  `desc:"show spaces as dots, underline tabs, and highlight trailing whitespace -- for spotting indentation bugs, e.g., in Python and YAML -- can be set per language in Edit Langs, and toggled with Toggle Whitespace"`
+	Rulers          []int `desc:"columns at which vertical rulers are drawn, e.g., 80 and 100, through the lines that reach them -- can be set per language in Edit Langs"`
+	HiLongLines     bool  `desc:"highlight the text of lines beyond the last ruler"`
+	EmacsUndo       bool  `desc:"use emacs-style undo, where after a non-undo command, all the current undo actions are added to the undo stack, such that a subsequent undo is actually a redo"`
+	DiagInline      bool  `desc:"show the errors and warnings from the language servers and from failed builds and linters in the text: their ranges underlined, their messages dimmed at the ends of their lines and in a popup on hovering over them, and the line numbers of their lines colored by severity"`
+	Breadcrumbs     bool  `desc:"show a bar above the text views with the package, type and function (or the sections) enclosing the cursor, each of which can be clicked to go to it -- takes effect when the project prefs are next applied"`
+	TabBar          bool  `desc:"show a tab strip above the text views with the open buffers, marking those with unsaved changes, which can be pinned, dragged to reorder them, and closed with their close buttons or a middle-click -- takes effect when the project prefs are next applied"`
 }
 
 // Preferences are the overall user preferences for Gide.
 type Preferences struct {
-	HiStyle     histyle.StyleName `desc:"highilighting style / theme"`
-	FontFamily  gi.FontName       `desc:"monospaced font family for editor"`
-	Files       FilePrefs         `desc:"file view preferences"`
-	Editor      EditorPrefs       `view:"inline" desc:"editor preferences"`
-	KeyMap      KeyMapName        `desc:"key map for gide-specific keyboard sequences"`
-	SaveKeyMaps bool              `desc:"if set, the current available set of key maps is saved to your preferences directory, and automatically loaded at startup -- this should be set if you are using custom key maps, but it may be safer to keep it <i>OFF</i> if you are <i>not</i> using custom key maps, so that you'll always have the latest compiled-in standard key maps with all the current key functions bound to standard key chords"`
-	SaveLangs   bool              `desc:"if set, the current customized set of language parameters (see Edit Langs) is saved / loaded along with other preferences -- if not set, then you always are using the default compiled-in standard set (which will be updated)"`
-	SaveCmds    bool              `desc:"if set, the current customized set of command parameters (see Edit Cmds) is saved / loaded along with other preferences -- if not set, then you always are using the default compiled-in standard set (which will be updated)"`
-	Changed     bool              `view:"-" changeflag:"+" json:"-" xml:"-" desc:"flag that is set by StructView by virtue of changeflag tag, whenever an edit is made.  Used to drive save menus etc."`
+	HiStyle     histyle.StyleName      `desc:"highilighting style / theme"`
+	FontFamily  gi.FontName            `desc:"monospaced font family for editor"`
+	Files       FilePrefs              `desc:"file view preferences"`
+	Editor      EditorPrefs            `view:"inline" desc:"editor preferences"`
+	KeyMap      KeyMapName             `desc:"key map for gide-specific keyboard sequences"`
+	SaveKeyMaps bool                   `desc:"if set, the current available set of key maps is saved to your preferences directory, and automatically loaded at startup -- this should be set if you are using custom key maps, but it may be safer to keep it <i>OFF</i> if you are <i>not</i> using custom key maps, so that you'll always have the latest compiled-in standard key maps with all the current key functions bound to standard key chords"`
+	SaveLangs   bool                   `desc:"if set, the current customized set of language parameters (see Edit Langs) is saved / loaded along with other preferences -- if not set, then you always are using the default compiled-in standard set (which will be updated)"`
+	SaveCmds    bool                   `desc:"if set, the current customized set of command parameters (see Edit Cmds) is saved / loaded along with other preferences -- if not set, then you always are using the default compiled-in standard set (which will be updated)"`
+	LangServers LangServers            `desc:"language servers (LSP) to use for code intelligence (completion, diagnostics, definitions, etc), by language -- clear the command to disable the server for a language"`
+	CmdLimits   map[CmdName]ProcLimits `desc:"resource limits for commands, by command name -- e.g., a niceness, cpu time or memory limit for a linter or big build, so it can not starve the editor or the machine -- applied where the OS supports them"`
+	Offline     bool                   `desc:"work offline: all of the features that use the network (opening web links, cloning, version control and go get commands, module queries by the go tool) are disabled and fail fast, e.g., for flights and air-gapped environments"`
+	Power       PowerMode              `desc:"how background work -- symbol indexing, semantic highlighting, and checking files for changes on disk -- adapts to the power of the machine: reduced automatically when on battery or under thermal pressure, always reduced, or never -- shown in the status bar when it is reduced"`
+	EnvMarks    EnvMarks               `desc:"rules that mark the files matching path patterns, e.g., */prod/*.yaml, by tinting the background of the editor and showing a banner above it -- a guard-rail against editing the config of the wrong environment"`
+	ProjGroups  ProjGroups             `desc:"groups with color labels (e.g., work, OSS, experiments) that recent projects can be tagged with, and the current group filter for the recent project lists"`
+	TourDone    bool                   `desc:"the tour (Help / Tour) has been taken, so it is no longer offered when a project is opened"`
+	Changed     bool                   `view:"-" changeflag:"+" json:"-" xml:"-" desc:"flag that is set by StructView by virtue of changeflag tag, whenever an edit is made.  Used to drive save menus etc."`
 }
 
 var KiT_Preferences = kit.Types.AddType(&Preferences{}, PreferencesProps)
@@ -70,9 +96,18 @@ func InitPrefs() {
 	DefaultKeyMap = "MacEmacs" // todo
 	SetActiveKeyMapName(DefaultKeyMap)
 	Prefs.Defaults()
-	Prefs.Open()
-	OpenPaths()
+	if Kiosk != nil {
+		if err := KioskPrefs(); err != nil {
+			log.Println(err)
+		}
+	} else {
+		InitPrefsStorage()
+		Prefs.Open()
+		OpenPaths()
+		OpenPinnedPaths()
+	}
 	OpenIcons()
+	OpenTours()
 	TheConsole.Init()
 	histyle.Init()
 }
@@ -87,7 +122,17 @@ func (pf *EditorPrefs) Defaults() {
 	pf.LineNos = true
 	pf.Completion = true
 	pf.SpellCorrect = true
+	pf.SpellCheck = true
+	pf.SigHelp = true
+	pf.SemanticHi = true
 	pf.AutoIndent = true
+	pf.AutoClose = true
+	pf.FillColumn = 80
+	pf.FormatOnSave = true
+	pf.UndoHistory = 1000
+	pf.RecoverySecs = 30
+	pf.Breadcrumbs = true
+	pf.DiagInline = true
 }
 
 func (pf *Preferences) Defaults() {
@@ -96,6 +141,9 @@ func (pf *Preferences) Defaults() {
 	pf.Files.Defaults()
 	pf.Editor.Defaults()
 	pf.KeyMap = DefaultKeyMap
+	pf.LangServers = make(LangServers, len(StdLangServers))
+	copy(pf.LangServers, StdLangServers)
+	pf.ProjGroups.Defaults()
 }
 
 // PrefsFileName is the name of the preferences file in GoGi prefs directory
@@ -119,7 +167,11 @@ func (pf *Preferences) Open() error {
 	if err != nil {
 		return err
 	}
-	err = json.Unmarshal(b, pf)
+	if errs := JSONLoad(b, pf, nil); len(errs) > 0 {
+		errs.SetFile(pnm)
+		errs.Report("Errors in Preferences")
+		err = errs
+	}
 	if pf.SaveKeyMaps {
 		AvailKeyMaps.OpenPrefs()
 	}
@@ -130,7 +182,9 @@ func (pf *Preferences) Open() error {
 		CustomCmds.OpenPrefs()
 	}
 	AvailSplits.OpenPrefs()
+	AvailLayouts.OpenPrefs()
 	AvailRegisters.OpenPrefs()
+	AvailSnippets.OpenPrefs()
 	pf.Apply()
 	pf.Changed = false
 	return err
@@ -138,6 +192,9 @@ func (pf *Preferences) Open() error {
 
 // Save Preferences to GoGi standard prefs directory
 func (pf *Preferences) Save() error {
+	if Kiosk != nil {
+		return fmt.Errorf("preferences are locked in kiosk mode")
+	}
 	pdir := oswin.TheApp.AppPrefsDir()
 	pnm := filepath.Join(pdir, PrefsFileName)
 	b, err := json.MarshalIndent(pf, "", "  ")
@@ -160,6 +217,8 @@ func (pf *Preferences) Save() error {
 	}
 	AvailSplits.SavePrefs()
 	AvailRegisters.SavePrefs()
+	AvailSnippets.SavePrefs()
+	PrefsPush(PrefsStorageNames()...)
 	pf.Changed = false
 	return err
 }
@@ -198,6 +257,12 @@ func (pf *Preferences) EditRegisters() {
 	RegistersView(&AvailRegisters)
 }
 
+// EditSnippets opens the SnippetsView editor to customize the snippets
+// expanded by typing their prefix and Tab
+func (pf *Preferences) EditSnippets() {
+	SnippetsView(&AvailSnippets)
+}
+
 // EditHiStyles opens the HiStyleView editor to customize highlighting styles
 func (pf *Preferences) EditHiStyles() {
 	giv.HiStylesView(&histyle.CustomStyles)
@@ -258,6 +323,10 @@ var PreferencesProps = ki.Props{
 			"icon": "file-binary",
 			"desc": "opens the RegistersView editor of saved named text registers.  Current values are saved and loaded with preferences automatically.",
 		}},
+		{"EditSnippets", ki.Props{
+			"icon": "file-text",
+			"desc": "opens the SnippetsView editor of snippets, for each language, that are expanded by typing their prefix and then Tab.  Current values are saved and loaded with preferences automatically.",
+		}},
 		{"EditHiStyles", ki.Props{
 			"icon": "file-binary",
 			"desc": "opens the HiStylesView editor of highlighting styles.",
@@ -270,25 +339,39 @@ var PreferencesProps = ki.Props{
 
 // ProjPrefs are the preferences for saving for a project -- this IS the project file
 type ProjPrefs struct {
-	Files        FilePrefs      `desc:"file view preferences"`
-	Editor       EditorPrefs    `view:"inline" desc:"editor preferences"`
-	SplitName    SplitName      `desc:"current named-split config in use for configuring the splitters"`
-	MainLang     LangName       `desc:"the language associated with the most frequently-encountered file extension in the file tree -- can be manually set here as well"`
-	VersCtrl     VersCtrlName   `desc:"the type of version control system used in this project (git, svn, etc) -- filters commands available"`
-	ChangeLog    ChangeLog      `desc:"log of version control commits made through Gide by current author -- use appropriate VCS log command to see full set of changes"`
-	ProjFilename gi.FileName    `ext:".gide" desc:"current project filename for saving / loading specific Gide configuration information in a .gide file (optional)"`
-	ProjRoot     gi.FileName    `desc:"root directory for the project -- all projects must be organized within a top-level root directory, with all the files therein constituting the scope of the project -- by default it is the path for ProjFilename"`
-	BuildCmds    CmdNames       `desc:"command(s) to run for main Build button"`
-	BuildDir     gi.FileName    `desc:"build directory for main Build button -- set this to the directory where you want to build the main target for this project -- avail as {BuildDir} in commands"`
-	BuildTarg    gi.FileName    `desc:"build target for main Build button, if relevant for your  BuildCmds"`
-	RunExec      gi.FileName    `desc:"executable to run for this project via main Run button -- called by standard Run Proj command"`
-	RunCmds      CmdNames       `desc:"command(s) to run for main Run button (typically Run Proj)"`
-	Find         FindParams     `view:"-" desc:"saved find params"`
-	Spell        SpellParams    `view:"-" desc:"saved spell params"`
-	OpenDirs     giv.OpenDirMap `view:"-" desc:"open directories"`
-	Register     RegisterName   `view:"-" desc:"last register used"`
-	Splits       []float32      `view:"-" desc:"current splitter splits"`
-	Changed      bool           `view:"-" changeflag:"+" json:"-" xml:"-" desc:"flag that is set by StructView by virtue of changeflag tag, whenever an edit is made.  Used to drive save menus etc."`
+	Files        FilePrefs        `desc:"file view preferences"`
+	Editor       EditorPrefs      `view:"inline" desc:"editor preferences"`
+	SplitName    SplitName        `desc:"current named-split config in use for configuring the splitters"`
+	Layout       LayoutName       `view:"-" desc:"current named layout of the window"`
+	MainLang     LangName         `desc:"the language associated with the most frequently-encountered file extension in the file tree -- can be manually set here as well"`
+	VersCtrl     VersCtrlName     `desc:"the type of version control system used in this project (git, svn, etc) -- filters commands available"`
+	ChangeLog    ChangeLog        `desc:"log of version control commits made through Gide by current author -- use appropriate VCS log command to see full set of changes"`
+	ProjFilename gi.FileName      `ext:".gide" desc:"current project filename for saving / loading specific Gide configuration information in a .gide file (optional)"`
+	ProjRoot     gi.FileName      `desc:"root directory for the project -- all projects must be organized within a top-level root directory, with all the files therein constituting the scope of the project -- by default it is the path for ProjFilename"`
+	BuildCmds    CmdNames         `desc:"command(s) to run for main Build button"`
+	BuildDir     gi.FileName      `desc:"build directory for main Build button -- set this to the directory where you want to build the main target for this project -- avail as {BuildDir} in commands"`
+	BuildTarg    gi.FileName      `desc:"build target for main Build button, if relevant for your  BuildCmds"`
+	BuildJumpErr bool             `desc:"after a failed build, jump to the first error in the editor -- use Next Error / Previous Error to walk through the rest"`
+	DebugBackend string           `desc:"backend for the Delve debugger: empty for the default, or rr to record the program so it can be run in reverse (Linux only, needs rr installed)"`
+	RunExec      gi.FileName      `desc:"executable to run for this project via main Run button -- called by standard Run Proj command"`
+	RunCmds      CmdNames         `desc:"command(s) to run for main Run button (typically Run Proj)"`
+	BuildEnv     BuildEnv         `desc:"build environment (GOOS, GOARCH, build tags, GOFLAGS) applied to all commands run for this project"`
+	Generators   Generators       `desc:"code generators, e.g., templ, stringer or mockgen, that are run whenever one of their source files is saved, reloading the files they generate -- their errors are shown as diagnostics"`
+	Tracker      TrackerPrefs     `desc:"issue tracker of the project, for filing issues for TODO comments -- empty for the GitHub or GitLab repository of the git remote"`
+	AutoHideTree bool             `desc:"collapse the file tree to a thin strip when it does not have the focus, revealing it on hovering over the strip, focusing it, or Reveal Hidden in the View / Panels menu -- for more editor space on small screens"`
+	AutoHideTabs bool             `desc:"collapse the tabs panel, with the console and other output, to a thin strip when it does not have the focus, revealing it as with AutoHideTree"`
+	Find         FindParams       `view:"-" desc:"saved find params"`
+	Spell        SpellParams      `view:"-" desc:"saved spell params"`
+	Todo         TodoParams       `view:"-" desc:"saved todo scanner params"`
+	Dict         []string         `desc:"words that are spelled correctly in this project, e.g., names, which are not underlined as misspelled -- added by Add to Project Dictionary in the Spelling Suggestions"`
+	OpenDirs     giv.OpenDirMap   `view:"-" desc:"open directories"`
+	Register     RegisterName     `view:"-" desc:"last register used"`
+	Splits       []float32        `view:"-" desc:"current splitter splits"`
+	Folds        map[string][]int `view:"-" desc:"folded regions, as the start lines of the regions for each file path relative to the project root"`
+	Panes        []PaneNode       `view:"-" desc:"layout of the panes split off from the main text views"`
+	Breaks       DebugBreaks      `view:"-" desc:"debugger breakpoints"`
+	PinnedTabs   []string         `view:"-" desc:"pinned tabs of the buffer tab bar, as the file paths relative to the project root"`
+	Changed      bool             `view:"-" changeflag:"+" json:"-" xml:"-" desc:"flag that is set by StructView by virtue of changeflag tag, whenever an edit is made.  Used to drive save menus etc."`
 }
 
 var KiT_ProjPrefs = kit.Types.AddType(&ProjPrefs{}, ProjPrefsProps)
@@ -343,9 +426,13 @@ var SavedPathsFileName = "gide_saved_paths.json"
 
 // SavePaths saves the active SavedPaths to prefs dir
 func SavePaths() {
+	if Kiosk != nil { // nothing kept across sessions
+		return
+	}
 	pdir := oswin.TheApp.AppPrefsDir()
 	pnm := filepath.Join(pdir, SavedPathsFileName)
 	SavedPaths.SaveJSON(pnm)
+	PrefsPush(SavedPathsFileName)
 }
 
 // OpenPaths loads the active SavedPaths from prefs dir
@@ -354,3 +441,28 @@ func OpenPaths() {
 	pnm := filepath.Join(pdir, SavedPathsFileName)
 	SavedPaths.OpenJSON(pnm)
 }
+
+// PinnedPaths are the saved paths that have been pinned to stay at the top of
+// the recent projects list in the Welcome window
+var PinnedPaths gi.FilePaths
+
+// PinnedPathsFileName is the name of the pinned file paths file in GoGi prefs directory
+var PinnedPathsFileName = "gide_pinned_paths.json"
+
+// SavePinnedPaths saves the active PinnedPaths to prefs dir
+func SavePinnedPaths() {
+	if Kiosk != nil {
+		return
+	}
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PinnedPathsFileName)
+	PinnedPaths.SaveJSON(pnm)
+	PrefsPush(PinnedPathsFileName)
+}
+
+// OpenPinnedPaths loads the active PinnedPaths from prefs dir
+func OpenPinnedPaths() {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PinnedPathsFileName)
+	PinnedPaths.OpenJSON(pnm)
+}
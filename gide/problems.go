@@ -0,0 +1,567 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// Problem is one error, warning or other diagnostic reported for a file in
+// the project -- lines and columns are 1-based
+type Problem struct {
+	Path     string `json:"-" desc:"full path to the file"`
+	RelPath  string `json:"path" desc:"path relative to the project root"`
+	Line     int    `json:"line" desc:"starting line"`
+	Col      int    `json:"col" desc:"starting column"`
+	EndLine  int    `json:"endLine" desc:"ending line"`
+	EndCol   int    `json:"endCol" desc:"ending column"`
+	Severity string `json:"severity" desc:"error, warning, info or hint"`
+	Source   string `json:"source,omitempty" desc:"tool that reported the problem, e.g., compiler or linter name"`
+	Code     string `json:"code,omitempty" desc:"code or rule identifier for the problem, if any"`
+	Message  string `json:"message" desc:"the message"`
+}
+
+// Key returns the key used to match this problem against a baseline: it
+// does not include the position, so that problems are still matched after
+// unrelated edits move them around in the file
+func (pb *Problem) Key() string {
+	msg := strings.Join(strings.Fields(pb.Message), " ")
+	return strings.Join([]string{pb.RelPath, pb.Severity, pb.Source, pb.Code, msg}, "|")
+}
+
+// ProblemsFromDiags returns the problems for given LSP diagnostics, by uri,
+// sorted by path and position
+func ProblemsFromDiags(diags map[string][]LspDiagnostic, root string) []Problem {
+	var pbs []Problem
+	for uri, dgs := range diags {
+		fp := LspPath(uri)
+		rp := refRelPath(root, fp)
+		for _, dg := range dgs {
+			code := ""
+			if dg.Code != nil {
+				code = fmt.Sprintf("%v", dg.Code)
+			}
+			pbs = append(pbs, Problem{Path: fp, RelPath: rp,
+				Line: dg.Range.Start.Line + 1, Col: dg.Range.Start.Character + 1,
+				EndLine: dg.Range.End.Line + 1, EndCol: dg.Range.End.Character + 1,
+				Severity: dg.Severity.String(), Source: dg.Source, Code: code, Message: dg.Message})
+		}
+	}
+	sort.SliceStable(pbs, func(i, j int) bool {
+		a, b := &pbs[i], &pbs[j]
+		switch {
+		case a.RelPath != b.RelPath:
+			return a.RelPath < b.RelPath
+		case a.Line != b.Line:
+			return a.Line < b.Line
+		default:
+			return a.Col < b.Col
+		}
+	})
+	return pbs
+}
+
+// Problems returns all the problems currently reported by all the language
+// servers running for the project
+func (lcs *LspClients) Problems(root string) []Problem {
+	return ProblemsFromDiags(lcs.AllDiags(), root)
+}
+
+// AllDiags returns all the diagnostics currently reported by all the
+// language servers running for the project, by uri
+func (lcs *LspClients) AllDiags() map[string][]LspDiagnostic {
+	lcs.Mu.Lock()
+	lcl := make([]*LspClient, 0, len(lcs.Clients))
+	for _, lc := range lcs.Clients {
+		lcl = append(lcl, lc)
+	}
+	lcs.Mu.Unlock()
+	diags := make(map[string][]LspDiagnostic)
+	for _, lc := range lcl {
+		for uri, dgs := range lc.AllDiagnostics() {
+			diags[uri] = append(diags[uri], dgs...)
+		}
+	}
+	return diags
+}
+
+// ProblemsBaselineFile is the name of the file in the project root where
+// the problems baseline is saved -- it can be committed so that everyone
+// working on the project shares the same baseline
+var ProblemsBaselineFile = ".gide-baseline.json"
+
+// ProblemsBaseline is a snapshot of the problems in a project at some point,
+// so that only problems that are new relative to it need to be shown --
+// e.g., when starting to use a linter on an existing code base
+type ProblemsBaseline struct {
+	Created time.Time      `desc:"when the baseline was made"`
+	Counts  map[string]int `desc:"number of problems for each problem key"`
+}
+
+// NewProblemsBaseline returns a baseline for given problems
+func NewProblemsBaseline(pbs []Problem) *ProblemsBaseline {
+	bl := &ProblemsBaseline{Created: time.Now(), Counts: make(map[string]int)}
+	for i := range pbs {
+		bl.Counts[pbs[i].Key()]++
+	}
+	return bl
+}
+
+// NewProblems returns the problems that are not in the baseline: for each
+// key, as many problems as were in the baseline are considered old
+func (bl *ProblemsBaseline) NewProblems(pbs []Problem) []Problem {
+	if bl == nil {
+		return pbs
+	}
+	seen := make(map[string]int)
+	var nw []Problem
+	for _, pb := range pbs {
+		k := pb.Key()
+		seen[k]++
+		if seen[k] > bl.Counts[k] {
+			nw = append(nw, pb)
+		}
+	}
+	return nw
+}
+
+// OpenJSON opens baseline from a JSON-formatted file.
+func (bl *ProblemsBaseline) OpenJSON(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, bl)
+}
+
+// SaveJSON saves baseline to a JSON-formatted file.
+func (bl *ProblemsBaseline) SaveJSON(filename gi.FileName) error {
+	b, err := json.MarshalIndent(bl, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(string(filename), b, 0644)
+}
+
+// ProblemSevRanks are the ranks of the severities of problems, most severe
+// first
+var ProblemSevRanks = map[string]int{"error": 1, "warning": 2, "info": 3, "hint": 4}
+
+// ProblemsAtLeast returns those of given problems that are at least as
+// severe as given severity -- all of them if it is empty or unknown
+func ProblemsAtLeast(pbs []Problem, sev string) []Problem {
+	min, ok := ProblemSevRanks[sev]
+	if !ok {
+		return pbs
+	}
+	var out []Problem
+	for _, pb := range pbs {
+		if rk, ok := ProblemSevRanks[pb.Severity]; !ok || rk <= min {
+			out = append(out, pb)
+		}
+	}
+	return out
+}
+
+// ProblemsJSON returns given problems as JSON
+func ProblemsJSON(pbs []Problem) ([]byte, error) {
+	if pbs == nil {
+		pbs = []Problem{}
+	}
+	return json.MarshalIndent(pbs, "", "  ")
+}
+
+// SarifLevel returns the SARIF result level for given problem severity
+func SarifLevel(sev string) string {
+	switch sev {
+	case "error":
+		return "error"
+	case "warning":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// ProblemsSARIF returns given problems in the SARIF 2.1.0 format used by
+// code scanning tools
+func ProblemsSARIF(pbs []Problem) ([]byte, error) {
+	type msg struct {
+		Text string `json:"text"`
+	}
+	type region struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine"`
+		EndColumn   int `json:"endColumn"`
+	}
+	type artifact struct {
+		URI string `json:"uri"`
+	}
+	type physLoc struct {
+		ArtifactLocation artifact `json:"artifactLocation"`
+		Region           region   `json:"region"`
+	}
+	type location struct {
+		PhysicalLocation physLoc `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId,omitempty"`
+		Level     string     `json:"level"`
+		Message   msg        `json:"message"`
+		Locations []location `json:"locations"`
+	}
+	res := make([]result, len(pbs))
+	for i, pb := range pbs {
+		rid := pb.Code
+		if rid == "" {
+			rid = pb.Source
+		}
+		res[i] = result{RuleID: rid, Level: SarifLevel(pb.Severity), Message: msg{pb.Message},
+			Locations: []location{{physLoc{artifact{filepath.ToSlash(pb.RelPath)},
+				region{pb.Line, pb.Col, pb.EndLine, pb.EndCol}}}}}
+	}
+	doc := map[string]interface{}{
+		"version": "2.1.0",
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool":    map[string]interface{}{"driver": map[string]interface{}{"name": "gide"}},
+				"results": res,
+			},
+		},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// ProblemsView is a widget that displays the problems reported by the
+// language servers for the project, in a TextView with links to each one --
+// optionally only those that are new relative to a saved baseline
+type ProblemsView struct {
+	gi.Layout
+	Gide     *Gide             `json:"-" xml:"-" desc:"parent gide project"`
+	All      []Problem         `json:"-" xml:"-" desc:"all the current problems"`
+	Baseline *ProblemsBaseline `json:"-" xml:"-" desc:"baseline, if one has been set"`
+	NewOnly  bool              `json:"-" xml:"-" desc:"only show problems that are not in the baseline"`
+	Severity string            `json:"-" xml:"-" desc:"only show problems at least this severe: error, warning or info -- all of them if empty"`
+}
+
+var KiT_ProblemsView = kit.Types.AddType(&ProblemsView{}, ProblemsViewProps)
+
+// BaselinePath returns the path of the baseline file for the project
+func (pv *ProblemsView) BaselinePath() gi.FileName {
+	return gi.FileName(filepath.Join(string(pv.Gide.ProjRoot), ProblemsBaselineFile))
+}
+
+// OpenBaseline opens the baseline for the project, if there is one
+func (pv *ProblemsView) OpenBaseline() {
+	bl := &ProblemsBaseline{}
+	if err := bl.OpenJSON(pv.BaselinePath()); err != nil {
+		pv.Baseline = nil
+		return
+	}
+	pv.Baseline = bl
+	pv.NewOnly = true
+}
+
+// Shown returns the problems that are shown, given the NewOnly setting
+func (pv *ProblemsView) Shown() []Problem {
+	pbs := pv.All
+	if pv.NewOnly {
+		pbs = pv.Baseline.NewProblems(pbs)
+	}
+	return ProblemsAtLeast(pbs, pv.Severity)
+}
+
+// Refresh gets the current problems from the language servers, and from
+// failed builds and linters, and shows them
+func (pv *ProblemsView) Refresh() {
+	pv.All = pv.Gide.ProjProblems()
+	pv.ShowResults()
+}
+
+// ShowResults renders the shown problems into the results buffer
+func (pv *ProblemsView) ShowResults() {
+	tbuf, _ := pv.Gide.FindOrMakeCmdBuf("Problems", true)
+	tbuf.New(0)
+	pbs := pv.Shown()
+	outlns := make([][]byte, 0, len(pbs)+1)
+	outmus := make([][]byte, 0, len(pbs)+1)
+	nerr := 0
+	for _, pb := range pbs {
+		if pb.Severity == "error" {
+			nerr++
+		}
+		fnstr := fmt.Sprintf("%v:%d:%d", pb.RelPath, pb.Line, pb.Col)
+		src := ""
+		if pb.Source != "" {
+			src = fmt.Sprintf(" (%v)", pb.Source)
+		}
+		outlns = append(outlns, []byte(fmt.Sprintf("%v: %v: %v%v", fnstr, pb.Severity, pb.Message, src)))
+		outmus = append(outmus, []byte(fmt.Sprintf(`<a href="file:///%v#L%vC%v">%v</a>: <b>%v</b>: %v%v`, pb.Path, pb.Line, pb.Col, fnstr, pb.Severity, html.EscapeString(pb.Message), html.EscapeString(src))))
+	}
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	tbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+	msg := fmt.Sprintf("%d problems (%d errors)", len(pbs), nerr)
+	if pv.Baseline != nil {
+		msg += fmt.Sprintf(" -- baseline of %v has %d", pv.Baseline.Created.Format("2006-01-02 15:04"), len(pv.All)-len(pv.Baseline.NewProblems(pv.All)))
+		if pv.NewOnly {
+			msg += " not shown"
+		}
+	}
+	pv.InfoLabel().SetText(msg)
+}
+
+// SetBaseline makes the current problems the baseline and saves it, so only
+// problems that arise after this are shown
+func (pv *ProblemsView) SetBaseline() {
+	bl := NewProblemsBaseline(pv.All)
+	if err := bl.SaveJSON(pv.BaselinePath()); err != nil {
+		gi.PromptDialog(pv.Gide.Viewport, gi.DlgOpts{Title: "Could not Save Baseline", Prompt: err.Error()}, true, false, nil, nil)
+		return
+	}
+	pv.Baseline = bl
+	pv.NewOnly = true
+	pv.Gide.SetStatus(fmt.Sprintf("Problems baseline saved to %v", ProblemsBaselineFile))
+	pv.ShowResults()
+}
+
+// ClearBaseline removes the baseline, so all problems are shown
+func (pv *ProblemsView) ClearBaseline() {
+	pv.Baseline = nil
+	pv.NewOnly = false
+	os.Remove(string(pv.BaselinePath()))
+	pv.ShowResults()
+}
+
+// Export saves the shown problems to given file, as SARIF if it has a .sarif
+// extension, and otherwise as JSON
+func (pv *ProblemsView) Export(filename gi.FileName) error {
+	return ExportProblems(pv.Shown(), filename)
+}
+
+// ExportDialog prompts for a file to export the shown problems to
+func (pv *ProblemsView) ExportDialog(ext string) {
+	pv.Gide.ExportProblemsDialog(pv.Shown(), ext)
+}
+
+// ExportProblems saves given problems to given file, as SARIF if it has a
+// .sarif extension, and otherwise as JSON
+func ExportProblems(pbs []Problem, filename gi.FileName) error {
+	var b []byte
+	var err error
+	if strings.ToLower(filepath.Ext(string(filename))) == ".sarif" {
+		b, err = ProblemsSARIF(pbs)
+	} else {
+		b, err = ProblemsJSON(pbs)
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(string(filename), b, 0644)
+}
+
+// ExportProblemsDialog prompts for a file with given extension to export
+// given problems to
+func (ge *Gide) ExportProblemsDialog(pbs []Problem, ext string) {
+	vp := ge.Viewport
+	giv.FileViewDialog(vp, string(ge.ProjRoot), ext, giv.DlgOpts{Title: "Export Problems to " + ext + " File"}, nil,
+		vp.Win, func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.DialogAccepted) {
+				dlg, _ := send.(*gi.Dialog)
+				fn := giv.FileViewDialogValue(dlg)
+				if filepath.Ext(fn) == "" {
+					fn += ext
+				}
+				if err := ExportProblems(pbs, gi.FileName(fn)); err != nil {
+					gi.PromptDialog(vp, gi.DlgOpts{Title: "Could not Export Problems", Prompt: err.Error()}, true, false, nil, nil)
+					return
+				}
+				ge.SetStatus("Problems exported to: " + fn)
+			}
+		})
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (pv *ProblemsView) UpdateView(ge *Gide) {
+	pv.Gide = ge
+	mods, updt := pv.StdProblemsConfig()
+	pv.ConfigToolbar()
+	tvly := pv.TextViewLay()
+	pv.Gide.ConfigOutputTextView(tvly)
+	if mods {
+		pv.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (pv *ProblemsView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "probsbar")
+	config.Add(gi.KiT_Layout, "probstext")
+	return config
+}
+
+// StdProblemsConfig configures a standard setup of the overall layout --
+// returns mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (pv *ProblemsView) StdProblemsConfig() (mods, updt bool) {
+	pv.Lay = gi.LayoutVert
+	pv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := pv.StdConfig()
+	mods, updt = pv.ConfigChildren(config, false)
+	return
+}
+
+// ProblemsBar returns the problems toolbar
+func (pv *ProblemsView) ProblemsBar() *gi.ToolBar {
+	tbi, ok := pv.ChildByName("probsbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// InfoLabel returns the label showing the problem counts in the toolbar
+func (pv *ProblemsView) InfoLabel() *gi.Label {
+	tb := pv.ProblemsBar()
+	if tb == nil {
+		return nil
+	}
+	lbi, ok := tb.ChildByName("info", 0)
+	if !ok {
+		return nil
+	}
+	return lbi.(*gi.Label)
+}
+
+// NewOnlyBox returns the new-only checkbox in the toolbar
+func (pv *ProblemsView) NewOnlyBox() *gi.CheckBox {
+	tb := pv.ProblemsBar()
+	if tb == nil {
+		return nil
+	}
+	cbi, ok := tb.ChildByName("new-only", 0)
+	if !ok {
+		return nil
+	}
+	return cbi.(*gi.CheckBox)
+}
+
+// TextViewLay returns the problems TextView layout
+func (pv *ProblemsView) TextViewLay() *gi.Layout {
+	tvi, ok := pv.ChildByName("probstext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the problems TextView
+func (pv *ProblemsView) TextView() *giv.TextView {
+	tvly := pv.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (pv *ProblemsView) ConfigToolbar() {
+	tb := pv.ProblemsBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	rf := tb.AddNewChild(gi.KiT_Action, "refresh").(*gi.Action)
+	rf.SetText("Refresh")
+	rf.Tooltip = "get the latest problems reported by the language servers"
+	rf.ActionSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+		pvv.Refresh()
+	})
+
+	nw := tb.AddNewChild(gi.KiT_CheckBox, "new-only").(*gi.CheckBox)
+	nw.SetText("New Only")
+	nw.Tooltip = "only show problems that are not in the baseline"
+	nw.ButtonSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+			cb := send.(*gi.CheckBox)
+			pvv.NewOnly = cb.IsChecked() && pvv.Baseline != nil
+			pvv.ShowResults()
+		}
+	})
+
+	sv := tb.AddNewChild(gi.KiT_ComboBox, "severity").(*gi.ComboBox)
+	sv.Tooltip = "only show problems at least this severe"
+	sv.ItemsFromStringList([]string{"all", "error", "warning", "info"}, false, 0)
+	sv.ComboSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+		pvv.Severity, _ = send.(*gi.ComboBox).CurVal.(string)
+		pvv.ShowResults()
+	})
+
+	sb := tb.AddNewChild(gi.KiT_Action, "set-baseline").(*gi.Action)
+	sb.SetText("Set Baseline")
+	sb.Tooltip = "save the current problems as the baseline in " + ProblemsBaselineFile + " in the project root, so only new problems are shown from now on"
+	sb.ActionSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+		pvv.SetBaseline()
+	})
+
+	cb := tb.AddNewChild(gi.KiT_Action, "clear-baseline").(*gi.Action)
+	cb.SetText("Clear Baseline")
+	cb.Tooltip = "delete the baseline, so all problems are shown"
+	cb.ActionSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+		pvv.ClearBaseline()
+	})
+
+	ej := tb.AddNewChild(gi.KiT_Action, "export-json").(*gi.Action)
+	ej.SetText("Export JSON")
+	ej.Tooltip = "export the shown problems to a JSON file"
+	ej.ActionSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+		pvv.ExportDialog(".json")
+	})
+
+	es := tb.AddNewChild(gi.KiT_Action, "export-sarif").(*gi.Action)
+	es.SetText("Export SARIF")
+	es.Tooltip = "export the shown problems to a SARIF file, as used by code scanning tools"
+	es.ActionSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+		pvv.ExportDialog(".sarif")
+	})
+
+	lbl := tb.AddNewChild(gi.KiT_Label, "info").(*gi.Label)
+	lbl.SetStretchMaxWidth()
+}
+
+var ProblemsViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProblemsBaseline(t *testing.T) {
+	root := "/proj"
+	rng := func(ln int) LspRange {
+		return LspRange{Start: LspPosition{Line: ln, Character: 2}, End: LspPosition{Line: ln, Character: 5}}
+	}
+	old := map[string][]LspDiagnostic{
+		LspURI("/proj/a.go"): {
+			{Range: rng(3), Severity: LspSevWarning, Source: "vet", Message: "unused  result"},
+			{Range: rng(9), Severity: LspSevWarning, Source: "vet", Message: "unused result"},
+		},
+	}
+	pbs := ProblemsFromDiags(old, root)
+	if len(pbs) != 2 || pbs[0].RelPath != "a.go" || pbs[0].Line != 4 || pbs[0].Col != 3 || pbs[0].Severity != "warning" {
+		t.Fatalf("bad problems: %+v", pbs)
+	}
+	bl := NewProblemsBaseline(pbs)
+
+	cur := map[string][]LspDiagnostic{
+		LspURI("/proj/a.go"): { // moved down, plus one more of the same
+			{Range: rng(5), Severity: LspSevWarning, Source: "vet", Message: "unused result"},
+			{Range: rng(11), Severity: LspSevWarning, Source: "vet", Message: "unused result"},
+			{Range: rng(20), Severity: LspSevWarning, Source: "vet", Message: "unused result"},
+		},
+		LspURI("/proj/b.go"): {
+			{Range: rng(0), Severity: LspSevError, Message: "undefined: x"},
+		},
+	}
+	nw := bl.NewProblems(ProblemsFromDiags(cur, root))
+	if len(nw) != 2 || nw[0].Line != 21 || nw[1].RelPath != "b.go" {
+		t.Errorf("bad new problems: %+v", nw)
+	}
+
+	b, err := ProblemsSARIF(nw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != "2.1.0" || len(doc.Runs) != 1 || len(doc.Runs[0].Results) != 2 {
+		t.Fatalf("bad sarif: %s", b)
+	}
+	r := doc.Runs[0].Results[1]
+	if r.Level != "error" || r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "b.go" || r.Locations[0].PhysicalLocation.Region.StartLine != 1 {
+		t.Errorf("bad sarif result: %+v", r)
+	}
+}
+
+func TestProblemsAtLeast(t *testing.T) {
+	pbs := []Problem{{Severity: "hint"}, {Severity: "error"}, {Severity: "info"}, {Severity: "warning"}, {Severity: "odd"}}
+	sevs := func(pbs []Problem) string {
+		var ss []string
+		for _, pb := range pbs {
+			ss = append(ss, pb.Severity)
+		}
+		return strings.Join(ss, " ")
+	}
+	for sev, want := range map[string]string{
+		"":        "hint error info warning odd",
+		"all":     "hint error info warning odd",
+		"error":   "error odd",
+		"warning": "error warning odd",
+		"info":    "error info warning odd",
+	} {
+		if got := sevs(ProblemsAtLeast(pbs, sev)); got != want {
+			t.Errorf("%q: got %q, want %q", sev, got, want)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// ChildProc is one child process that gide has spawned for a project, with
+// functions to kill and restart it
+type ChildProc struct {
+	Kind    string    `desc:"kind of process: command, language server or debugger"`
+	Name    string    `desc:"name of the command, language, or debug session"`
+	Exec    *exec.Cmd `desc:"the running process"`
+	Kill    func()    `desc:"kills the process -- called in the background, so it can wait for it to exit"`
+	Restart func()    `desc:"restarts the process, killing it first"`
+}
+
+// Pid returns the process id of the child process
+func (cp *ChildProc) Pid() int {
+	return cp.Exec.Process.Pid
+}
+
+// ChildProcs returns all the child processes that are running for this
+// project: commands, language servers and the debugger
+func (ge *Gide) ChildProcs() []ChildProc {
+	var cps []ChildProc
+	for _, cm := range ge.RunningCmds.Running() {
+		cm := cm
+		cps = append(cps, ChildProc{Kind: "command", Name: cm.Name, Exec: cm.Exec,
+			Kill: func() { TerminateProc(cm.Exec, cm.Done, ProcTermTimeout) },
+			Restart: func() {
+				ge.ExecCmdName(CmdName(cm.Name), true, true) // kills the running one
+			}})
+	}
+	ge.Lsp.Mu.Lock()
+	for lang, lc := range ge.Lsp.Clients {
+		lang := lang
+		if lc.Exec == nil || lc.Exec.Process == nil {
+			continue
+		}
+		cps = append(cps, ChildProc{Kind: "language server", Name: string(lang), Exec: lc.Exec,
+			Kill:    func() { ge.Lsp.Kill(lang) },
+			Restart: func() { ge.LspRestart(lang) }})
+	}
+	ge.Lsp.Mu.Unlock()
+	if dc := ge.Dbg; dc != nil && dc.Exec != nil && dc.Exec.Process != nil {
+		cps = append(cps, ChildProc{Kind: "debugger", Name: "Debug", Exec: dc.Exec,
+			Kill: func() {
+				ge.DebugStop()
+				TerminateProc(dc.Exec, dc.Done, ProcTermTimeout)
+			},
+			Restart: func() { ge.DebugStart() }})
+	}
+	return cps
+}
+
+// LspRestart restarts the language server for given language, and opens all
+// the open files in that language in the new one
+func (ge *Gide) LspRestart(lang LangName) {
+	ge.Lsp.Restart(lang)
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf == nil {
+			continue
+		}
+		if ls := LangsForFilename(string(ond.Buf.Filename)); len(ls) > 0 && LangName(ls[0].Name) == lang {
+			ge.LspOpenBuf(ond.Buf)
+		}
+	}
+}
+
+// ProcStats are the resource usage stats of a process
+type ProcStats struct {
+	CPU     time.Duration `desc:"total cpu time used, user and system"`
+	RSS     int64         `desc:"resident set size, in bytes"`
+	Cmdline string        `desc:"full command line"`
+}
+
+// ProcClockTicks is the number of clock ticks per second in which cpu times
+// are given in /proc/<pid>/stat (USER_HZ)
+const ProcClockTicks = 100
+
+// ParseProcStat parses the contents of a Linux /proc/<pid>/stat file, and
+// returns the cpu time used (user plus system), in clock ticks, and the
+// resident set size, in pages -- the fields are counted after the command
+// name, which is in parens and can have spaces and parens in it
+func ParseProcStat(stat string) (ticks, rssPages int64, err error) {
+	ci := strings.LastIndexByte(stat, ')')
+	if ci < 0 {
+		return 0, 0, fmt.Errorf("gide.ParseProcStat: no command name in: %q", stat)
+	}
+	fs := strings.Fields(stat[ci+1:])
+	// fs[0] is field 3, state -- utime is 14, stime 15, rss 24
+	if len(fs) < 22 {
+		return 0, 0, fmt.Errorf("gide.ParseProcStat: only %d fields after command name", len(fs))
+	}
+	ut, err := strconv.ParseInt(fs[11], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	st, err := strconv.ParseInt(fs[12], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	rssPages, err = strconv.ParseInt(fs[21], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ut + st, rssPages, nil
+}
+
+// ReadProcStats reads the resource usage stats of process with given pid
+// from /proc -- returns false if they are not available, e.g., if it is not
+// running, or not on Linux
+func ReadProcStats(pid int) (ProcStats, bool) {
+	var ps ProcStats
+	dir := fmt.Sprintf("/proc/%d/", pid)
+	stat, err := ioutil.ReadFile(dir + "stat")
+	if err != nil {
+		return ps, false
+	}
+	ticks, rss, err := ParseProcStat(string(stat))
+	if err != nil {
+		return ps, false
+	}
+	ps.CPU = time.Duration(ticks) * time.Second / ProcClockTicks
+	ps.RSS = rss * int64(os.Getpagesize())
+	if cl, err := ioutil.ReadFile(dir + "cmdline"); err == nil {
+		ps.Cmdline = strings.TrimSpace(string(bytes.Replace(cl, []byte{0}, []byte(" "), -1)))
+	}
+	return ps, true
+}
+
+// procSample is the cpu time of a process at a given time, for computing
+// the percentage of cpu it used since then
+type procSample struct {
+	CPU time.Duration
+	At  time.Time
+}
+
+// ProcView is a widget that lists the child processes that gide has spawned
+// for the project, with their pids, cpu and memory usage and command lines,
+// in a TextView with links to kill or restart each one
+type ProcView struct {
+	gi.Layout
+	Gide    *Gide              `json:"-" xml:"-" desc:"parent gide project"`
+	Procs   []ChildProc        `json:"-" xml:"-" desc:"processes as of the last refresh"`
+	Samples map[int]procSample `json:"-" xml:"-" desc:"cpu time of each process at the last refresh, by pid"`
+	ProcMu  sync.Mutex         `json:"-" xml:"-" view:"-" desc:"mutex protecting procs -- processes are killed in the background"`
+}
+
+var KiT_ProcView = kit.Types.AddType(&ProcView{}, ProcViewProps)
+
+// Refresh gathers the child processes and their stats, and shows them
+func (pv *ProcView) Refresh() {
+	cps := pv.Gide.ChildProcs()
+	sort.Slice(cps, func(i, j int) bool {
+		if cps[i].Kind != cps[j].Kind {
+			return cps[i].Kind < cps[j].Kind
+		}
+		return cps[i].Name < cps[j].Name
+	})
+	pv.ProcMu.Lock()
+	pv.Procs = cps
+	pv.ProcMu.Unlock()
+	pv.ShowResults()
+}
+
+// ShowResults renders the current processes into the results buffer
+func (pv *ProcView) ShowResults() {
+	tbuf, _ := pv.Gide.FindOrMakeCmdBuf("Procs", true)
+
+	pv.ProcMu.Lock()
+	now := time.Now()
+	samps := make(map[int]procSample, len(pv.Procs))
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	lstr := fmt.Sprintf("%d child processes, as of %v", len(pv.Procs), now.Format("15:04:05"))
+	outlns = append(outlns, []byte(lstr))
+	outmus = append(outmus, []byte(fmt.Sprintf(`<b>%v</b>`, lstr)))
+	for _, cp := range pv.Procs {
+		pid := cp.Pid()
+		hdr := fmt.Sprintf("%v: %v (pid %d)", cp.Kind, cp.Name, pid)
+		stats := "stats not available"
+		cmdline := strings.Join(cp.Exec.Args, " ")
+		if ps, ok := ReadProcStats(pid); ok {
+			cpu := "--"
+			if prv, has := pv.Samples[pid]; has && now.After(prv.At) {
+				cpu = fmt.Sprintf("%.1f%%", 100*float64(ps.CPU-prv.CPU)/float64(now.Sub(prv.At)))
+			}
+			samps[pid] = procSample{ps.CPU, now}
+			stats = fmt.Sprintf("cpu: %v  time: %v  rss: %.1f MB", cpu, ps.CPU, float64(ps.RSS)/(1024*1024))
+			if ps.Cmdline != "" {
+				cmdline = ps.Cmdline
+			}
+		}
+		outlns = append(outlns, []byte(""), []byte(hdr+"  kill  restart"))
+		outmus = append(outmus, []byte(""), []byte(fmt.Sprintf(`<b>%v</b>  <a href="proc:///kill?pid=%d">kill</a>  <a href="proc:///restart?pid=%d">restart</a>`, html.EscapeString(hdr), pid, pid)))
+		lstr = "	" + stats
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte(lstr))
+		lstr = "	" + cmdline
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte(html.EscapeString(lstr)))
+	}
+	pv.Samples = samps
+	pv.ProcMu.Unlock()
+
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	tbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+}
+
+// OpenProcURL kills or restarts the process in given proc:/// url from the
+// process list
+func (pv *ProcView) OpenProcURL(ur string, ttv *giv.TextView) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("ProcView OpenProcURL parse err: %v\n", err)
+		return false
+	}
+	pid, err := strconv.Atoi(up.Query().Get("pid"))
+	if err != nil {
+		log.Printf("ProcView OpenProcURL pid err: %v\n", err)
+		return false
+	}
+	var cp *ChildProc
+	pv.ProcMu.Lock()
+	for i := range pv.Procs {
+		if pv.Procs[i].Pid() == pid {
+			cp = &pv.Procs[i]
+			break
+		}
+	}
+	pv.ProcMu.Unlock()
+	if cp == nil {
+		return false
+	}
+	ge := pv.Gide
+	switch up.Path[1:] { // has double //
+	case "kill":
+		ge.SetStatus(fmt.Sprintf("Killing %v: %v (pid %d)...", cp.Kind, cp.Name, pid))
+		kill := cp.Kill
+		go func() {
+			kill()
+			pv.Refresh()
+			ge.SetStatus(fmt.Sprintf("Killed pid %d", pid))
+		}()
+	case "restart":
+		ge.SetStatus(fmt.Sprintf("Restarting %v: %v (pid %d)", cp.Kind, cp.Name, pid))
+		cp.Restart()
+		pv.Refresh()
+	default:
+		return false
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (pv *ProcView) UpdateView(ge *Gide) {
+	pv.Gide = ge
+	mods, updt := pv.StdProcConfig()
+	pv.ConfigToolbar()
+	tvly := pv.TextViewLay()
+	pv.Gide.ConfigOutputTextView(tvly)
+	if mods {
+		pv.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (pv *ProcView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "procbar")
+	config.Add(gi.KiT_Layout, "proctext")
+	return config
+}
+
+// StdProcConfig configures a standard setup of the overall layout -- returns
+// mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (pv *ProcView) StdProcConfig() (mods, updt bool) {
+	pv.Lay = gi.LayoutVert
+	pv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := pv.StdConfig()
+	mods, updt = pv.ConfigChildren(config, false)
+	return
+}
+
+// ProcBar returns the proc toolbar
+func (pv *ProcView) ProcBar() *gi.ToolBar {
+	tbi, ok := pv.ChildByName("procbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// TextViewLay returns the proc results TextView layout
+func (pv *ProcView) TextViewLay() *gi.Layout {
+	tvi, ok := pv.ChildByName("proctext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the proc results TextView
+func (pv *ProcView) TextView() *giv.TextView {
+	tvly := pv.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (pv *ProcView) ConfigToolbar() {
+	tb := pv.ProcBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	refresh := tb.AddNewChild(gi.KiT_Action, "refresh").(*gi.Action)
+	refresh.SetText("Refresh")
+	refresh.Tooltip = "list the running child processes again, with their cpu use since the last refresh"
+	refresh.ActionSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pvv, _ := recv.Embed(KiT_ProcView).(*ProcView)
+		pvv.Refresh()
+	})
+}
+
+var ProcViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestParseProcStat(t *testing.T) {
+	tests := []struct {
+		stat  string
+		ticks int64
+		rss   int64
+		err   bool
+	}{
+		{"1234 (gopls) S 1 1234 1234 0 -1 4194560 5000 0 0 0 250 50 0 0 20 0 12 0 100 800000000 4096 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 3 0 0 0 0 0", 300, 4096, false},
+		{"42 (my (odd) cmd) R 1 42 42 0 -1 0 0 0 0 0 7 3 0 0 20 0 1 0 5 1000 12 0", 10, 12, false},
+		{"42 no parens", 0, 0, true},
+		{"42 (short) S 1 2 3", 0, 0, true},
+		{"42 (bad) S 1 42 42 0 -1 0 0 0 0 0 x 3 0 0 20 0 1 0 5 1000 12 0", 0, 0, true},
+	}
+	for _, tt := range tests {
+		ticks, rss, err := ParseProcStat(tt.stat)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseProcStat(%q) err = %v, want err: %v", tt.stat, err, tt.err)
+			continue
+		}
+		if ticks != tt.ticks || rss != tt.rss {
+			t.Errorf("ParseProcStat(%q) = %d, %d, want %d, %d", tt.stat, ticks, rss, tt.ticks, tt.rss)
+		}
+	}
+}
+
+func TestReadProcStats(t *testing.T) {
+	ps, ok := ReadProcStats(os.Getpid())
+	if runtime.GOOS != "linux" {
+		if ok {
+			t.Errorf("ReadProcStats: stats available on %v", runtime.GOOS)
+		}
+		return
+	}
+	if !ok {
+		t.Fatalf("ReadProcStats: no stats for own process")
+	}
+	if ps.RSS <= 0 || ps.Cmdline == "" {
+		t.Errorf("ReadProcStats = %+v, want rss and command line", ps)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+)
+
+// ProjGroup is a named group of projects (e.g., work, OSS, experiments),
+// with a color label that is shown next to its projects in recent lists
+type ProjGroup struct {
+	Name  string `desc:"name of the group"`
+	Color string `desc:"color of the label for projects in the group -- standard color name or #RRGGBB hex value"`
+}
+
+// ProjGroups are the project groups, and the group that each recent project
+// path has been tagged with -- stored in the global Preferences
+type ProjGroups struct {
+	Groups []ProjGroup       `desc:"the available project groups"`
+	Paths  map[string]string `view:"-" desc:"group name for each tagged project path (.gide file or directory)"`
+	Filter string            `desc:"if set, only projects in this group are shown in the Welcome window, Open Recent menu, and Project Windows menu"`
+}
+
+// ProjGroupAll is the menu choice for showing projects in all groups
+const ProjGroupAll = "(all)"
+
+// ProjGroupNone is the menu choice for removing a project from its group
+const ProjGroupNone = "(none)"
+
+// Defaults sets some standard groups
+func (pg *ProjGroups) Defaults() {
+	pg.Groups = []ProjGroup{
+		{"work", "#4a90d9"},
+		{"OSS", "#5cb85c"},
+		{"experiments", "#f0ad4e"},
+	}
+}
+
+// GroupByName returns the group of given name, or nil if not found
+func (pg *ProjGroups) GroupByName(name string) *ProjGroup {
+	for i := range pg.Groups {
+		if pg.Groups[i].Name == name {
+			return &pg.Groups[i]
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the groups
+func (pg *ProjGroups) Names() []string {
+	nms := make([]string, len(pg.Groups))
+	for i, gp := range pg.Groups {
+		nms[i] = gp.Name
+	}
+	return nms
+}
+
+// PathGroup returns the group that given path is tagged with, or nil if none
+// (or the group no longer exists)
+func (pg *ProjGroups) PathGroup(path string) *ProjGroup {
+	if pg.Paths == nil {
+		return nil
+	}
+	gnm, ok := pg.Paths[path]
+	if !ok {
+		return nil
+	}
+	return pg.GroupByName(gnm)
+}
+
+// SetPathGroup tags given path with given group -- an empty group name (or
+// ProjGroupNone) removes the tag
+func (pg *ProjGroups) SetPathGroup(path, group string) {
+	if group == "" || group == ProjGroupNone {
+		delete(pg.Paths, path)
+		return
+	}
+	if pg.Paths == nil {
+		pg.Paths = make(map[string]string)
+	}
+	pg.Paths[path] = group
+}
+
+// InFilter returns true if given path passes the current group filter
+func (pg *ProjGroups) InFilter(path string) bool {
+	if pg.Filter == "" {
+		return true
+	}
+	gp := pg.PathGroup(path)
+	return gp != nil && gp.Name == pg.Filter
+}
+
+// FilterPaths returns the paths that pass the current group filter
+func (pg *ProjGroups) FilterPaths(paths []string) []string {
+	if pg.Filter == "" {
+		return paths
+	}
+	fp := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if pg.InFilter(p) {
+			fp = append(fp, p)
+		}
+	}
+	return fp
+}
+
+// SetFilter sets the group filter -- ProjGroupAll (or empty) shows all
+func (pg *ProjGroups) SetFilter(group string) {
+	if group == ProjGroupAll {
+		group = ""
+	}
+	pg.Filter = group
+}
+
+// FilterChoices returns the choices for the group filter menu
+func (pg *ProjGroups) FilterChoices() []string {
+	return append([]string{ProjGroupAll}, pg.Names()...)
+}
+
+// TagChoices returns the choices for tagging a project with a group
+func (pg *ProjGroups) TagChoices() []string {
+	return append([]string{ProjGroupNone}, pg.Names()...)
+}
+
+// Label returns the label for given path in a list, with the group name in
+// the group color if it is tagged with one
+func (pg *ProjGroups) Label(path string) string {
+	gp := pg.PathGroup(path)
+	if gp == nil {
+		return path
+	}
+	return `<span style="color:` + gp.Color + `">[` + gp.Name + `]</span> ` + path
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//   Gide
+
+// ProjGroupPath returns the path used for tagging this project with a group:
+// the project file if saved, else the root path
+func (ge *Gide) ProjGroupPath() string {
+	if ge.Prefs.ProjFilename != "" {
+		return string(ge.Prefs.ProjFilename)
+	}
+	return string(ge.ProjRoot)
+}
+
+// SetProjGroup tags the current project with given group (ProjGroupNone
+// removes the tag), saving the preferences
+func (ge *Gide) SetProjGroup(group string) {
+	if ge.IsEmpty() || ge.SingleFile {
+		return
+	}
+	Prefs.ProjGroups.SetPathGroup(string(ge.ProjRoot), group)
+	if ge.Prefs.ProjFilename != "" {
+		Prefs.ProjGroups.SetPathGroup(string(ge.Prefs.ProjFilename), group)
+	}
+	Prefs.Save()
+	ge.SetStatus("Project group: " + group)
+}
+
+// FilterProjGroup sets the group filter for the recent and project window
+// lists (ProjGroupAll shows all), saving the preferences
+func (ge *Gide) FilterProjGroup(group string) {
+	Prefs.ProjGroups.SetFilter(group)
+	Prefs.Save()
+	ge.SetStatus("Showing projects in group: " + group)
+}
+
+// OpenGideWindows returns the open Gide project windows, sorted by name
+func OpenGideWindows() []*Gide {
+	var gl []*Gide
+	for _, win := range gi.MainWindows {
+		if !strings.HasPrefix(win.Nm, "gide-") {
+			continue
+		}
+		mfr, ok := win.MainWidget()
+		if !ok {
+			continue
+		}
+		gek, ok := mfr.ChildByName("gide", 0)
+		if !ok {
+			continue
+		}
+		gl = append(gl, gek.Embed(KiT_Gide).(*Gide))
+	}
+	sort.Slice(gl, func(i, j int) bool {
+		return gl[i].Nm < gl[j].Nm
+	})
+	return gl
+}
+
+// ShowProjWindow raises the open Gide window with given window name
+func (ge *Gide) ShowProjWindow(winName string) {
+	if win, found := gi.AllWindows.FindName(winName); found {
+		win.OSWin.Raise()
+	}
+}
+
+// GideRecentPaths gets the recent paths filtered by project group, for
+// submenu-func
+func GideRecentPaths(it interface{}, vp *gi.Viewport2D) []string {
+	return Prefs.ProjGroups.FilterPaths(SavedPaths)
+}
+
+// GideProjWindows gets the names of the open Gide windows filtered by project
+// group, for submenu-func
+func GideProjWindows(it interface{}, vp *gi.Viewport2D) []string {
+	var wl []string
+	for _, ge := range OpenGideWindows() {
+		if ge.SingleFile {
+			continue
+		}
+		if !Prefs.ProjGroups.InFilter(ge.ProjGroupPath()) && !Prefs.ProjGroups.InFilter(string(ge.ProjRoot)) {
+			continue
+		}
+		if win := ge.ParentWindow(); win != nil {
+			wl = append(wl, win.Nm)
+		}
+	}
+	return wl
+}
+
+// GideProjGroupTags gets the choices for tagging a project, for submenu-func
+func GideProjGroupTags(it interface{}, vp *gi.Viewport2D) []string {
+	return Prefs.ProjGroups.TagChoices()
+}
+
+// GideProjGroupFilters gets the choices for the group filter, for submenu-func
+func GideProjGroupFilters(it interface{}, vp *gi.Viewport2D) []string {
+	return Prefs.ProjGroups.FilterChoices()
+}
+
+// ProjGroupChooser pops up a chooser for the group of given path, calling
+// done after it has been set and saved
+func ProjGroupChooser(path string, recv ki.Ki, done func()) {
+	cur := ProjGroupNone
+	if gp := Prefs.ProjGroups.PathGroup(path); gp != nil {
+		cur = gp.Name
+	}
+	choices := Prefs.ProjGroups.TagChoices()
+	gi.StringsChooserPopup(choices, cur, recv, func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		idx := ac.Data.(int)
+		Prefs.ProjGroups.SetPathGroup(path, choices[idx])
+		Prefs.Save()
+		if done != nil {
+			done()
+		}
+	})
+}
@@ -0,0 +1,747 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// ProjIndexDir is the directory, within the project root, that the project
+// index and the symbol index are cached in, so they are up to date soon
+// after the project is opened again -- it is not searched
+var ProjIndexDir = filepath.Join(".gide", "cache")
+
+// ProjIndexMaxSize is the largest file whose text is indexed, in bytes --
+// larger files are always searched
+var ProjIndexMaxSize int64 = 4 << 20
+
+// ProjIndexScanEvery is how many checks of the open buffers for changes on
+// disk (every WatchInterval) there are between scans of the project for
+// files changed outside of gide, to keep its index up to date
+var ProjIndexScanEvery = 15
+
+// projIndexVersion is the version of the cached index -- a cache of
+// another version is built again
+const projIndexVersion = 1
+
+// Trigram is three bytes of text, as indexed by ProjIndex, with ASCII
+// letters in lower case
+type Trigram uint32
+
+// IndexedFile is a file in the project index
+type IndexedFile struct {
+	Rel  string `desc:"path of the file relative to the project root, slash-separated"`
+	Mod  int64  `desc:"modification time of the file when it was indexed, in unix nanoseconds"`
+	Size int64  `desc:"size of the file when it was indexed"`
+	Text bool   `desc:"the text of the file is indexed -- otherwise it is too large, and it is always searched"`
+	Dead bool   `desc:"the file has been removed, or indexed again under another id"`
+}
+
+// ProjIndex is an index of the trigrams and identifiers in the text of the
+// files of a project, which narrows a project search down to the files
+// that can have matches, and finding references to the files that have the
+// identifier -- it is built in the background, cached in ProjIndexDir, and
+// kept up to date as files are saved, and by scans of the project for
+// files changed outside of gide
+type ProjIndex struct {
+	Root     string              `desc:"root directory of the index"`
+	Files    []IndexedFile       `desc:"the indexed files, by id -- a file that is indexed again gets a new id, so the ids of the files of each trigram stay in order"`
+	Grams    map[Trigram][]int32 `desc:"ids of the files with each trigram, in order"`
+	Idents   map[string][]int32  `desc:"ids of the files with each identifier, in order"`
+	Built    bool                `desc:"the whole root has been scanned"`
+	Mu       sync.RWMutex        `desc:"mutex protecting the index"`
+	ids      map[string]int32    // id of each live file, by Rel
+	ndead    int                 // number of dead files
+	scanning int32               // a scan is running
+	start    sync.Once
+}
+
+// projIndexCache is the cached form of a ProjIndex
+type projIndexCache struct {
+	Version int
+	Root    string
+	Files   []IndexedFile
+	Grams   map[Trigram][]int32
+	Idents  map[string][]int32
+}
+
+// NewProjIndex returns a new, empty index for given root
+func NewProjIndex(root string) *ProjIndex {
+	return &ProjIndex{Root: root, Grams: map[Trigram][]int32{}, Idents: map[string][]int32{}, ids: map[string]int32{}}
+}
+
+// asciiLower returns b in lower case, if it is an ASCII letter
+func asciiLower(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// TextTrigrams returns the distinct trigrams of given text, in order
+func TextTrigrams(src []byte) []Trigram {
+	set := make(map[Trigram]struct{})
+	var t Trigram
+	for i, b := range src {
+		t = (t<<8 | Trigram(asciiLower(b))) & 0xFFFFFF
+		if i >= 2 {
+			set[t] = struct{}{}
+		}
+	}
+	ts := make([]Trigram, 0, len(set))
+	for t := range set {
+		ts = append(ts, t)
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+	return ts
+}
+
+// IsIdent returns true if given word is one that is indexed as an
+// identifier: letters, digits and _, not starting with a digit, of 2 to 64
+// bytes
+func IsIdent(word string) bool {
+	if len(word) < 2 || len(word) > 64 {
+		return false
+	}
+	for i, r := range word {
+		if !isWordRune(r) || (i == 0 && '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// TextIdents returns the distinct identifiers in given text, as in IsIdent,
+// in order -- each is a whole run of letters, digits and _
+func TextIdents(src []byte) []string {
+	set := make(map[string]struct{})
+	st := -1 // start of the run of word runes
+	for i := 0; i <= len(src); {
+		r, sz := utf8.RuneError, 1
+		if i < len(src) {
+			r, sz = utf8.DecodeRune(src[i:])
+		}
+		if i < len(src) && isWordRune(r) {
+			if st < 0 {
+				st = i
+			}
+		} else if st >= 0 {
+			if w := string(src[st:i]); IsIdent(w) {
+				set[w] = struct{}{}
+			}
+			st = -1
+		}
+		i += sz
+	}
+	ids := make([]string, 0, len(set))
+	for w := range set {
+		ids = append(ids, w)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SearchLiterals returns strings that each match of given find string must
+// contain -- none if nothing is known of its matches, e.g., for a regexp of
+// alternatives
+func SearchLiterals(find string, useRegexp bool) []string {
+	if !useRegexp {
+		return []string{find}
+	}
+	re, err := syntax.Parse(find, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return regexpLiterals(re.Simplify())
+}
+
+// regexpLiterals returns the literal strings that each match of given
+// regexp must contain
+func regexpLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		lit := string(re.Rune)
+		if re.Flags&syntax.FoldCase != 0 && !isASCII(lit) {
+			return nil // not folded in the index
+		}
+		return []string{lit}
+	case syntax.OpCapture, syntax.OpPlus:
+		return regexpLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return regexpLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var lits []string
+		for _, sub := range re.Sub {
+			lits = append(lits, regexpLiterals(sub)...)
+		}
+		return lits
+	}
+	return nil
+}
+
+// LiteralTrigrams returns the trigrams that the text of a file must have
+// to contain all of given literals -- without those of non-ASCII bytes if
+// ignoreCase, as the case of those is not folded in the index
+func LiteralTrigrams(lits []string, ignoreCase bool) []Trigram {
+	set := make(map[Trigram]struct{})
+	for _, lit := range lits {
+		for _, t := range TextTrigrams([]byte(lit)) {
+			if ignoreCase && (t&0x808080) != 0 {
+				continue
+			}
+			set[t] = struct{}{}
+		}
+	}
+	ts := make([]Trigram, 0, len(set))
+	for t := range set {
+		ts = append(ts, t)
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+	return ts
+}
+
+// IndexText adds given file, with given contents, to the index, in place
+// of any version of it indexed before -- the text of files larger than
+// ProjIndexMaxSize is not indexed, so nil can be given for them, and no
+// trigrams are indexed for binary files, which are never searched
+func (pi *ProjIndex) IndexText(rel string, mod, size int64, src []byte) {
+	f := IndexedFile{Rel: rel, Mod: mod, Size: size, Text: size <= ProjIndexMaxSize}
+	var ts []Trigram
+	var ids []string
+	if f.Text && ServeIsText(src) {
+		ts = TextTrigrams(src)
+		ids = TextIdents(src)
+	}
+	pi.Mu.Lock()
+	defer pi.Mu.Unlock()
+	pi.kill(rel)
+	id := int32(len(pi.Files))
+	pi.Files = append(pi.Files, f)
+	pi.ids[rel] = id
+	for _, t := range ts {
+		pi.Grams[t] = append(pi.Grams[t], id)
+	}
+	for _, w := range ids {
+		pi.Idents[w] = append(pi.Idents[w], id)
+	}
+}
+
+// Remove removes given file from the index
+func (pi *ProjIndex) Remove(rel string) {
+	pi.Mu.Lock()
+	pi.kill(rel)
+	pi.Mu.Unlock()
+}
+
+// kill marks the file at given path as dead -- Mu must be locked
+func (pi *ProjIndex) kill(rel string) {
+	if id, has := pi.ids[rel]; has {
+		pi.Files[id].Dead = true
+		delete(pi.ids, rel)
+		pi.ndead++
+	}
+}
+
+// Compact drops the dead files from the index, renumbering the others
+func (pi *ProjIndex) Compact() {
+	pi.Mu.Lock()
+	defer pi.Mu.Unlock()
+	if pi.ndead == 0 {
+		return
+	}
+	nid := make([]int32, len(pi.Files))
+	fs := make([]IndexedFile, 0, len(pi.Files)-pi.ndead)
+	for i, f := range pi.Files {
+		nid[i] = -1
+		if !f.Dead {
+			nid[i] = int32(len(fs))
+			pi.ids[f.Rel] = nid[i]
+			fs = append(fs, f)
+		}
+	}
+	pi.Files = fs
+	pi.ndead = 0
+	for t, ids := range pi.Grams {
+		if ids = compactIds(ids, nid); len(ids) == 0 {
+			delete(pi.Grams, t)
+		} else {
+			pi.Grams[t] = ids
+		}
+	}
+	for w, ids := range pi.Idents {
+		if ids = compactIds(ids, nid); len(ids) == 0 {
+			delete(pi.Idents, w)
+		} else {
+			pi.Idents[w] = ids
+		}
+	}
+}
+
+// compactIds returns given ids as renumbered by nid, without those of dead
+// files, reusing ids
+func compactIds(ids []int32, nid []int32) []int32 {
+	n := 0
+	for _, id := range ids {
+		if nid[id] >= 0 {
+			ids[n] = nid[id]
+			n++
+		}
+	}
+	return ids[:n]
+}
+
+// intersectIds returns the ids in both a and b, which are in order
+func intersectIds(a, b []int32) []int32 {
+	var ids []int32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			ids = append(ids, a[i])
+			i++
+			j++
+		}
+	}
+	return ids
+}
+
+// IsBuilt returns true if the whole root has been scanned
+func (pi *ProjIndex) IsBuilt() bool {
+	pi.Mu.RLock()
+	defer pi.Mu.RUnlock()
+	return pi.Built
+}
+
+// Candidates returns the paths of the files, relative to the root, whose
+// text has all of given trigrams, along with those whose text is not
+// indexed -- false if the index can not narrow a search down, as it is not
+// built yet, or there are no trigrams
+func (pi *ProjIndex) Candidates(ts []Trigram) (map[string]bool, bool) {
+	pi.Mu.RLock()
+	defer pi.Mu.RUnlock()
+	if !pi.Built || len(ts) == 0 {
+		return nil, false
+	}
+	lists := make([][]int32, len(ts))
+	for i, t := range ts {
+		lists[i] = pi.Grams[t]
+	}
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+	ids := lists[0]
+	for _, l := range lists[1:] {
+		if len(ids) == 0 {
+			break
+		}
+		ids = intersectIds(ids, l)
+	}
+	cands := pi.liveFiles(ids)
+	for _, f := range pi.Files {
+		if !f.Text && !f.Dead {
+			cands[f.Rel] = true
+		}
+	}
+	return cands, true
+}
+
+// IdentFiles returns the paths of the files, relative to the root, that
+// have given identifier, along with those whose text is not indexed --
+// false if the index can not tell, as it is not built yet, or the word is
+// not indexed as an identifier (see IsIdent)
+func (pi *ProjIndex) IdentFiles(word string) (map[string]bool, bool) {
+	pi.Mu.RLock()
+	defer pi.Mu.RUnlock()
+	if !pi.Built || !IsIdent(word) {
+		return nil, false
+	}
+	cands := pi.liveFiles(pi.Idents[word])
+	for _, f := range pi.Files {
+		if !f.Text && !f.Dead {
+			cands[f.Rel] = true
+		}
+	}
+	return cands, true
+}
+
+// liveFiles returns the paths of the files of given ids that are not dead
+// -- Mu must be locked
+func (pi *ProjIndex) liveFiles(ids []int32) map[string]bool {
+	fs := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if f := &pi.Files[id]; !f.Dead {
+			fs[f.Rel] = true
+		}
+	}
+	return fs
+}
+
+// NFiles returns the number of files in the index
+func (pi *ProjIndex) NFiles() int {
+	pi.Mu.RLock()
+	defer pi.Mu.RUnlock()
+	return len(pi.ids)
+}
+
+// indexFile indexes the file at given path, relative to the root, if it
+// is new or has changed since it was indexed -- returns true if it was
+func (pi *ProjIndex) indexFile(rel string) bool {
+	fpath := filepath.Join(pi.Root, filepath.FromSlash(rel))
+	fi, err := os.Stat(fpath)
+	if err != nil || !fi.Mode().IsRegular() {
+		return false
+	}
+	mod, size := fi.ModTime().UnixNano(), fi.Size()
+	pi.Mu.RLock()
+	id, has := pi.ids[rel]
+	same := has && pi.Files[id].Mod == mod && pi.Files[id].Size == size
+	pi.Mu.RUnlock()
+	if same {
+		return false
+	}
+	var src []byte
+	if size <= ProjIndexMaxSize {
+		if src, err = ioutil.ReadFile(fpath); err != nil {
+			return false
+		}
+	}
+	pi.IndexText(rel, mod, size, src)
+	return true
+}
+
+// Scan brings the index up to date with the files within the root, as a
+// project search finds them: the files that are new, or have another
+// modification time or size than when they were indexed, are indexed, and
+// those that are gone are removed -- it stops early, without removing any,
+// if cancel returns true -- returns the number of files indexed and removed
+func (pi *ProjIndex) Scan(cancel func() bool) (nidx, nrem int) {
+	opts := &ProjSearchOpts{Root: pi.Root}
+	files := make(chan searchFile, 256)
+	stopped := func() bool { return cancel != nil && cancel() }
+	go func() {
+		defer close(files)
+		searchWalk(opts, pi.Root, "", nil, true, files, stopped)
+	}()
+	seen := make(map[string]bool)
+	for sf := range files {
+		seen[sf.rel] = true
+		if pi.indexFile(sf.rel) {
+			nidx++
+		}
+	}
+	if stopped() {
+		return
+	}
+	pi.Mu.Lock()
+	for rel := range pi.ids {
+		if !seen[rel] {
+			pi.kill(rel)
+			nrem++
+		}
+	}
+	pi.Built = true
+	compact := pi.ndead > len(pi.Files)/2
+	pi.Mu.Unlock()
+	if compact {
+		pi.Compact()
+	}
+	return
+}
+
+// Update indexes the file at given full path again, if it is within the
+// root and not ignored by a project search -- returns true if it was
+func (pi *ProjIndex) Update(fpath string) bool {
+	rel, err := filepath.Rel(pi.Root, fpath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if projIndexIgnored(pi.Root, rel) {
+		return false
+	}
+	if _, err := os.Stat(fpath); os.IsNotExist(err) {
+		pi.Remove(rel)
+		return true
+	}
+	return pi.indexFile(rel)
+}
+
+// projIndexIgnored returns true if the file at given path, relative to the
+// root, is not searched by a project search: it is within a directory that
+// is skipped, or it is ignored by a .gitignore file
+func projIndexIgnored(root, rel string) bool {
+	rules := searchDirRules(root, rel)
+	segs := strings.Split(rel, "/")
+	for i, seg := range segs[:len(segs)-1] {
+		if SearchSkipDirs[seg] || IgnoreMatch(rules, strings.Join(segs[:i+1], "/"), true) {
+			return true
+		}
+	}
+	if strings.HasPrefix(rel, filepath.ToSlash(ProjIndexDir)+"/") {
+		return true
+	}
+	return IgnoreMatch(rules, rel, false)
+}
+
+// CachePath returns the path of the file that the index is cached in
+func (pi *ProjIndex) CachePath() string {
+	return filepath.Join(pi.Root, ProjIndexDir, "index.gob")
+}
+
+// Save writes the index to its cache file, compacted, replacing it only
+// once it is all written
+func (pi *ProjIndex) Save() error {
+	pi.Compact()
+	pi.Mu.RLock()
+	defer pi.Mu.RUnlock()
+	return projCacheWrite(pi.CachePath(), &projIndexCache{Version: projIndexVersion, Root: pi.Root, Files: pi.Files, Grams: pi.Grams, Idents: pi.Idents})
+}
+
+// Load reads the index from its cache file, if it is of this version and
+// root -- it is not built until it is scanned, to bring it up to date
+func (pi *ProjIndex) Load() error {
+	var pc projIndexCache
+	if err := projCacheRead(pi.CachePath(), &pc); err != nil {
+		return err
+	}
+	if pc.Version != projIndexVersion || pc.Root != pi.Root {
+		return fmt.Errorf("project index cache is for another version or root: %v", pi.CachePath())
+	}
+	pi.Mu.Lock()
+	defer pi.Mu.Unlock()
+	pi.Files, pi.Grams, pi.Idents = pc.Files, pc.Grams, pc.Idents
+	if pi.Grams == nil {
+		pi.Grams = map[Trigram][]int32{}
+	}
+	if pi.Idents == nil {
+		pi.Idents = map[string][]int32{}
+	}
+	pi.ids = make(map[string]int32, len(pi.Files))
+	pi.ndead = 0
+	for i, f := range pi.Files {
+		if f.Dead {
+			pi.ndead++
+		} else {
+			pi.ids[f.Rel] = int32(i)
+		}
+	}
+	return nil
+}
+
+// projCacheWrite writes given value, gob-encoded, to the cache file at
+// given path, in ProjIndexDir, which is created with a .gitignore so it is
+// not committed
+func projCacheWrite(fpath string, v interface{}) error {
+	dir := filepath.Dir(fpath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	gign := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(gign); os.IsNotExist(err) {
+		ioutil.WriteFile(gign, []byte("*\n"), 0644)
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(fpath))
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	err = gob.NewEncoder(f).Encode(v)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, fpath)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// projCacheRead reads given value, gob-encoded, from the cache file at
+// given path
+func projCacheRead(fpath string, v interface{}) error {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return gob.NewDecoder(f).Decode(v)
+}
+
+// Rescan scans the project again, saving the index if anything changed --
+// unless a scan is already running
+func (pi *ProjIndex) Rescan() {
+	if !atomic.CompareAndSwapInt32(&pi.scanning, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&pi.scanning, 0)
+	if nidx, nrem := pi.Scan(nil); nidx+nrem > 0 {
+		pi.Save()
+	}
+}
+
+// Start starts building the index in the background, from its cache if
+// there is one, unless it has already been started
+func (pi *ProjIndex) Start() {
+	pi.start.Do(func() {
+		go func() {
+			pi.Load()
+			pi.Rescan()
+		}()
+	})
+}
+
+// InLoc returns true if the file at given path, relative to the root, is
+// in the locations searched by given options
+func (opts *ProjSearchOpts) InLoc(rel string) bool {
+	switch opts.Loc {
+	case FindLocNotTop:
+		return strings.Contains(rel, "/")
+	case FindLocDir:
+		return filepath.Dir(filepath.Join(opts.Root, filepath.FromSlash(rel))) == filepath.Clean(opts.ActiveDir)
+	case FindLocSubdir:
+		drel, err := filepath.Rel(opts.Root, opts.Dir)
+		if err != nil || strings.HasPrefix(drel, "..") {
+			return false
+		}
+		drel = filepath.ToSlash(drel)
+		return drel == "." || strings.HasPrefix(rel, drel+"/")
+	}
+	return true
+}
+
+// searchCands sends the candidate files from the project index that are in
+// the locations searched, in the order of their paths, along with the open
+// buffers with unsaved changes there, whose text can match where that of
+// their files does not
+func searchCands(opts *ProjSearchOpts, files chan<- searchFile, stopped func() bool) {
+	set := make(map[string]bool, len(opts.Cands)+len(opts.Bufs))
+	for rel := range opts.Cands {
+		set[rel] = true
+	}
+	for fpath := range opts.Bufs {
+		if r, err := filepath.Rel(opts.Root, fpath); err == nil && !strings.HasPrefix(r, "..") {
+			set[filepath.ToSlash(r)] = true
+		}
+	}
+	rels := make([]string, 0, len(set))
+	for rel := range set {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	for _, rel := range rels {
+		if !opts.InLoc(rel) || !LangNamesMatchFilename(path.Base(rel), opts.Langs) || !opts.Globs.Match(rel) {
+			continue
+		}
+		if stopped() {
+			return
+		}
+		files <- searchFile{filepath.Join(opts.Root, filepath.FromSlash(rel)), rel}
+	}
+}
+
+// ProjIndexStart starts building the index of the project in the
+// background, from its cache, so searches are narrowed down by it soon
+// after the project is opened -- not for single files, and not while
+// background work is reduced (see PowerLow), until it resumes
+func (ge *Gide) ProjIndexStart() {
+	if ge.SingleFile || ge.IsEmpty() || ge.ProjRoot == "" {
+		return
+	}
+	pi := NewProjIndex(string(ge.ProjRoot))
+	ge.projIdx = pi
+	if !ge.PowerLow() {
+		pi.Start()
+	}
+}
+
+// ProjIndexFile updates the index of the project for given file -- called
+// whenever a file is saved
+func (ge *Gide) ProjIndexFile(fname string) {
+	if pi := ge.projIdx; pi != nil {
+		pi.Update(fname)
+	}
+}
+
+// ProjIndexRescan scans the project for files changed outside of gide in
+// the background, to update its index, and saves the symbol index if it has
+// changed -- once the index is built
+func (ge *Gide) ProjIndexRescan() {
+	pi := ge.projIdx
+	if pi == nil || !pi.IsBuilt() {
+		return
+	}
+	go func() {
+		pi.Rescan()
+		if si := ge.symIdx; si != nil {
+			si.SaveCache()
+		}
+	}()
+}
+
+// ProjIndexCands returns the files that can have matches of m, for given
+// find string, from the index of the project, for narrowing a project
+// search down to them -- nil if all the files must be searched
+func (ge *Gide) ProjIndexCands(m SearchMatcher, find string, ignoreCase, useRegexp bool) map[string]bool {
+	pi := ge.projIdx
+	if pi == nil {
+		return nil
+	}
+	lits := SearchLiterals(find, useRegexp)
+	if sm, ok := m.(*StructMatcher); ok {
+		lits = sm.Literals()
+	}
+	cands, _ := pi.Candidates(LiteralTrigrams(lits, ignoreCase))
+	return cands
+}
+
+// RefsFromIndex returns the whole-word matches of given identifier in the
+// files of the project that have it, from its index -- false if the index
+// can not tell which files those are
+func (ge *Gide) RefsFromIndex(word string) ([]RefFileResults, bool) {
+	pi := ge.projIdx
+	if pi == nil {
+		return nil, false
+	}
+	cands, ok := pi.IdentFiles(word)
+	if !ok {
+		return nil, false
+	}
+	m, err := NewFindMatcher(word, false, false, true, false)
+	if err != nil {
+		return nil, false
+	}
+	opts := &ProjSearchOpts{Root: pi.Root, Cands: cands, Bufs: ge.SearchBufs(false)}
+	cache := make(map[string][][]rune)
+	byfile := make(map[string]*RefFileResults)
+	ProjSearch(opts, m, nil, func(res ProjSearchResults) {
+		fr := &RefFileResults{Path: res.Path, RelPath: res.RelPath}
+		lns := refFileLines(res.Path, cache)
+		for _, mt := range res.Matches {
+			txt := strings.TrimSpace(SearchMatchPlain(mt))
+			if ln := mt.Reg.Start.Ln; ln < len(lns) {
+				txt = strings.TrimSpace(string(lns[ln]))
+			}
+			fr.Items = append(fr.Items, RefItem{Reg: mt.Reg, Text: txt})
+		}
+		byfile[res.Path] = fr
+	})
+	return refsSorted(byfile), true
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTextTrigrams(t *testing.T) {
+	tri := func(s string) Trigram { return Trigram(s[0])<<16 | Trigram(s[1])<<8 | Trigram(s[2]) }
+	got := TextTrigrams([]byte("FooFoo"))
+	want := []Trigram{tri("foo"), tri("ofo"), tri("oof")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TextTrigrams = %x, want %x", got, want)
+	}
+	if ts := TextTrigrams([]byte("ab")); len(ts) != 0 {
+		t.Errorf("TextTrigrams short: %x", ts)
+	}
+	if got, want := TextIdents([]byte("x := fooBar(été_2, 2nd) + fooBar")), []string{"fooBar", "été_2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TextIdents = %q, want %q", got, want)
+	}
+}
+
+func TestSearchLiterals(t *testing.T) {
+	tests := []struct {
+		find   string
+		regexp bool
+		want   []string
+	}{
+		{"a|b", false, []string{"a|b"}},
+		{`func (\w+)Foo\(`, true, []string{"func ", "Foo("}},
+		{`(foo)+bar?`, true, []string{"foo", "ba"}},
+		{`foo|bar`, true, nil},
+		{`x*yz{0,2}`, true, []string{"y"}},
+		{`(?i)été`, true, nil},
+		{`(`, true, nil},
+	}
+	for _, tt := range tests {
+		if got := SearchLiterals(tt.find, tt.regexp); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SearchLiterals(%q) = %q, want %q", tt.find, got, tt.want)
+		}
+	}
+	if ts := LiteralTrigrams([]string{"été"}, true); len(ts) != 0 {
+		t.Errorf("LiteralTrigrams ignore case non-ASCII: %x", ts)
+	}
+	if ts := LiteralTrigrams([]string{"été"}, false); len(ts) == 0 {
+		t.Errorf("LiteralTrigrams non-ASCII: none")
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+func TestProjIndex(t *testing.T) {
+	root, err := ioutil.TempDir("", "gide-projidx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	write := func(fn, src string) {
+		fp := filepath.Join(root, filepath.FromSlash(fn))
+		os.MkdirAll(filepath.Dir(fp), 0755)
+		if err := ioutil.WriteFile(fp, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n\nfunc SaveAll() {}\n")
+	write("lib/lib.go", "package lib\n\nfunc LoadAll() { saveall() }\n")
+	write("lib/gen/gen.go", "package gen // SaveAll\n")
+	write("lib/.gitignore", "gen/\n")
+	write(".git/x.go", "SaveAll\n")
+	write("big.txt", "big")
+
+	pi := NewProjIndex(root)
+	if _, ok := pi.Candidates(LiteralTrigrams([]string{"SaveAll"}, false)); ok {
+		t.Errorf("Candidates before the scan")
+	}
+	if nidx, nrem := pi.Scan(nil); nidx != 4 || nrem != 0 {
+		t.Errorf("Scan = %d indexed, %d removed, want 4, 0", nidx, nrem)
+	}
+	cands := func(find string) []string {
+		cs, ok := pi.Candidates(LiteralTrigrams(SearchLiterals(find, false), true))
+		if !ok {
+			t.Fatalf("Candidates(%q): not ok", find)
+		}
+		return sortedKeys(cs)
+	}
+	if got, want := cands("SaveAll"), []string{"lib/lib.go", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Candidates SaveAll = %q, want %q", got, want)
+	}
+	if got := cands("nowhere"); len(got) != 0 {
+		t.Errorf("Candidates nowhere = %q", got)
+	}
+	if fs, ok := pi.IdentFiles("SaveAll"); !ok || !reflect.DeepEqual(sortedKeys(fs), []string{"main.go"}) {
+		t.Errorf("IdentFiles SaveAll = %v, %v", fs, ok)
+	}
+	if _, ok := pi.IdentFiles("x"); ok {
+		t.Errorf("IdentFiles of a word that is not indexed is ok")
+	}
+
+	// unchanged files are not indexed again, changed and new ones are
+	if nidx, nrem := pi.Scan(nil); nidx != 0 || nrem != 0 {
+		t.Errorf("Scan unchanged = %d, %d", nidx, nrem)
+	}
+	write("lib/lib.go", "package lib\n\nfunc LoadAll() {}\n")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(root, "lib", "lib.go"), later, later)
+	write("lib/more.go", "package lib\n\nvar SaveAll = 1\n")
+	os.Remove(filepath.Join(root, "main.go"))
+	if nidx, nrem := pi.Scan(nil); nidx != 2 || nrem != 1 {
+		t.Errorf("Scan changed = %d indexed, %d removed, want 2, 1", nidx, nrem)
+	}
+	if got, want := cands("saveall"), []string{"lib/more.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Candidates after changes = %q, want %q", got, want)
+	}
+
+	// files too large to index are always candidates
+	ProjIndexMaxSize = 2
+	defer func() { ProjIndexMaxSize = 4 << 20 }()
+	write("big.txt", "bigger")
+	if !pi.Update(filepath.Join(root, "big.txt")) {
+		t.Errorf("Update big.txt: not indexed")
+	}
+	if pi.Update(filepath.Join(root, "lib", "gen", "gen.go")) {
+		t.Errorf("Update of an ignored file")
+	}
+	if got, want := cands("saveall"), []string{"big.txt", "lib/more.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Candidates with big.txt = %q, want %q", got, want)
+	}
+
+	n := pi.NFiles()
+	pi.Compact()
+	if len(pi.Files) != n || pi.NFiles() != n {
+		t.Errorf("Compact: %d files, %d live, want %d", len(pi.Files), pi.NFiles(), n)
+	}
+	if got, want := cands("saveall"), []string{"big.txt", "lib/more.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Candidates after Compact = %q, want %q", got, want)
+	}
+
+	if err := pi.Save(); err != nil {
+		t.Fatal(err)
+	}
+	lp := NewProjIndex(root)
+	if err := lp.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if lp.NFiles() != n || !reflect.DeepEqual(lp.Grams, pi.Grams) || !reflect.DeepEqual(lp.Idents, pi.Idents) {
+		t.Errorf("Load: %d files, want %d", lp.NFiles(), n)
+	}
+	if nidx, nrem := lp.Scan(nil); nidx != 0 || nrem != 0 {
+		t.Errorf("Scan after Load = %d, %d -- the cache is searched, or the files are indexed again", nidx, nrem)
+	}
+	if NewProjIndex(filepath.Join(root, "lib")).Load() == nil {
+		t.Errorf("Load of another root")
+	}
+}
+
+func TestProjSearchCands(t *testing.T) {
+	root, err := ioutil.TempDir("", "gide-projcands")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	for fn, src := range map[string]string{"a.go": "foo\n", "b.go": "foo\n", "sub/c.go": "foo\n", "sub/d.txt": "foo\n"} {
+		fp := filepath.Join(root, filepath.FromSlash(fn))
+		os.MkdirAll(filepath.Dir(fp), 0755)
+		ioutil.WriteFile(fp, []byte(src), 0644)
+	}
+	m, _ := NewSearchMatcher("foo", false, false)
+	search := func(opts *ProjSearchOpts) []string {
+		var rels []string
+		ProjSearch(opts, m, nil, func(res ProjSearchResults) { rels = append(rels, res.RelPath) })
+		sort.Strings(rels)
+		return rels
+	}
+	cands := map[string]bool{"a.go": true, "sub/c.go": true, "sub/d.txt": true}
+	bufs := map[string][]byte{filepath.Join(root, "b.go"): []byte("foo\n")}
+	if got, want := search(&ProjSearchOpts{Root: root, Cands: cands, Bufs: bufs}), []string{"a.go", "b.go", "sub/c.go", "sub/d.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cands all = %q, want %q", got, want)
+	}
+	if got, want := search(&ProjSearchOpts{Root: root, Loc: FindLocSubdir, Dir: filepath.Join(root, "sub"), Cands: cands, Globs: ParseSearchGlobs("*.go")}), []string{"sub/c.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cands subdir = %q, want %q", got, want)
+	}
+	if got, want := search(&ProjSearchOpts{Root: root, Loc: FindLocDir, ActiveDir: root + string(filepath.Separator), Cands: cands}), []string{"a.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cands dir = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,841 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"html"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goki/gi/giv"
+)
+
+// SearchContext is how many runes of text on either side of a match are
+// shown with it in the search results
+var SearchContext = 30
+
+// SearchMaxMatches is the most matches that a project search lists, after
+// which it stops
+var SearchMaxMatches = 20000
+
+// SearchFlushInterval is how often the results of a project search are
+// added to the Find panel as they arrive
+var SearchFlushInterval = 100 * time.Millisecond
+
+// SearchSkipDirs are the directories that are never searched: those of the
+// version control systems
+var SearchSkipDirs = map[string]bool{".git": true, ".hg": true, ".svn": true, ".bzr": true}
+
+//////////////////////////////////////////////////////////////////////////
+//  Matchers
+
+// SearchMatcher finds the matches of a search in text
+type SearchMatcher interface {
+	// Match returns true if there is a match anywhere in the text -- a
+	// quick check done on whole files before they are split into lines
+	Match(src []byte) bool
+
+	// FindLine returns the start and end byte positions of each of the
+	// non-empty, non-overlapping matches within a line -- followed by those
+	// of each of its groups, for a regexp
+	FindLine(line []byte) [][]int
+}
+
+// TextFinder is a SearchMatcher whose matches can span lines, so it finds
+// them in the whole text, instead of line by line
+type TextFinder interface {
+	SearchMatcher
+
+	// FindText returns the start and end byte positions of each of the
+	// non-empty, non-overlapping matches in the text, followed by those of
+	// each of its groups
+	FindText(src []byte) [][]int
+}
+
+// NewFindMatcher returns the matcher for given find string, as for
+// NewSearchMatcher, that only finds whole words if wholeWord, and for a
+// regexp, finds matches that span lines if multiLine
+func NewFindMatcher(find string, ignoreCase, useRegexp, wholeWord, multiLine bool) (SearchMatcher, error) {
+	var m SearchMatcher
+	var err error
+	if useRegexp && multiLine {
+		m, err = NewMultiLineMatcher(find, ignoreCase)
+	} else {
+		m, err = NewSearchMatcher(find, ignoreCase, useRegexp)
+	}
+	if err != nil || !wholeWord {
+		return m, err
+	}
+	return NewWordMatcher(m), nil
+}
+
+// NewSearchMatcher returns the matcher for given find string: a regexp if
+// useRegexp, else a Boyer-Moore literal matcher -- ignoring case uses a
+// regexp when the find string is not all ASCII
+func NewSearchMatcher(find string, ignoreCase, useRegexp bool) (SearchMatcher, error) {
+	switch {
+	case useRegexp:
+		if ignoreCase {
+			find = "(?i)" + find
+		}
+		return NewRegexpMatcher(find)
+	case ignoreCase && !isASCII(find):
+		return NewRegexpMatcher("(?i)" + regexp.QuoteMeta(find))
+	}
+	return NewBMSearcher([]byte(find), ignoreCase), nil
+}
+
+// isASCII returns true if the string has only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// BMSearcher finds a literal pattern using the Boyer-Moore-Horspool
+// algorithm, optionally ignoring ASCII case
+type BMSearcher struct {
+	Pat  []byte `desc:"pattern, lower-cased if Fold"`
+	Fold bool   `desc:"ignore ASCII case"`
+	skip [256]int
+}
+
+// NewBMSearcher returns a searcher for given literal pattern
+func NewBMSearcher(pat []byte, fold bool) *BMSearcher {
+	bm := &BMSearcher{Fold: fold}
+	if fold {
+		pat = bytes.ToLower(pat)
+	}
+	bm.Pat = pat
+	n := len(pat)
+	for i := range bm.skip {
+		bm.skip[i] = n
+	}
+	for i := 0; i < n-1; i++ {
+		c := pat[i]
+		bm.skip[c] = n - 1 - i
+		if fold && 'a' <= c && c <= 'z' {
+			bm.skip[c-'a'+'A'] = n - 1 - i
+		}
+	}
+	return bm
+}
+
+// Index returns the byte position of the first match in s, or -1 if none
+func (bm *BMSearcher) Index(s []byte) int {
+	n := len(bm.Pat)
+	if n == 0 {
+		return -1
+	}
+	for i := n - 1; i < len(s); i += bm.skip[s[i]] {
+		j, k := n-1, i
+		for j >= 0 {
+			c := s[k]
+			if bm.Fold && 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			if c != bm.Pat[j] {
+				break
+			}
+			j--
+			k--
+		}
+		if j < 0 {
+			return k + 1
+		}
+	}
+	return -1
+}
+
+// Match returns true if the pattern is in src
+func (bm *BMSearcher) Match(src []byte) bool {
+	return bm.Index(src) >= 0
+}
+
+// FindLine returns the positions of the matches of the pattern in line
+func (bm *BMSearcher) FindLine(line []byte) [][]int {
+	var ms [][]int
+	n := len(bm.Pat)
+	for st := 0; st < len(line); {
+		i := bm.Index(line[st:])
+		if i < 0 {
+			break
+		}
+		ms = append(ms, []int{st + i, st + i + n})
+		st += i + n
+	}
+	return ms
+}
+
+// RegexpMatcher finds the matches of a regular expression, within each line
+type RegexpMatcher struct {
+	Re  *regexp.Regexp `desc:"the regexp, matched against each line"`
+	MRe *regexp.Regexp `desc:"the regexp in multi-line mode, for the quick check of whole files, where ^ and $ match at the lines"`
+}
+
+// NewRegexpMatcher compiles the matcher for given regexp, in Go syntax
+func NewRegexpMatcher(expr string) (*RegexpMatcher, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	mre, err := regexp.Compile("(?m)" + expr)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexpMatcher{Re: re, MRe: mre}, nil
+}
+
+// Match returns true if the regexp matches anywhere in src
+func (rm *RegexpMatcher) Match(src []byte) bool {
+	return rm.MRe.Match(src)
+}
+
+// FindLine returns the positions of the non-empty matches of the regexp in
+// line, and of their groups
+func (rm *RegexpMatcher) FindLine(line []byte) [][]int {
+	return nonEmptyMatches(rm.Re.FindAllSubmatchIndex(line, -1))
+}
+
+// nonEmptyMatches returns the matches that are not empty
+func nonEmptyMatches(ms [][]int) [][]int {
+	var nms [][]int
+	for _, m := range ms {
+		if m[1] > m[0] {
+			nms = append(nms, m)
+		}
+	}
+	return nms
+}
+
+// MultiLineMatcher finds the matches of a regular expression in the whole
+// text, where they can span lines: . matches newlines too, and ^ and $
+// match at the start and end of each line
+type MultiLineMatcher struct {
+	Re *regexp.Regexp `desc:"the regexp, in multi-line mode, with . matching newlines"`
+}
+
+// NewMultiLineMatcher compiles the matcher for given regexp, in Go syntax
+func NewMultiLineMatcher(expr string, ignoreCase bool) (*MultiLineMatcher, error) {
+	flags := "(?ms)"
+	if ignoreCase {
+		flags = "(?ims)"
+	}
+	re, err := regexp.Compile(flags + expr)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiLineMatcher{Re: re}, nil
+}
+
+// Match returns true if the regexp matches anywhere in src
+func (mm *MultiLineMatcher) Match(src []byte) bool {
+	return mm.Re.Match(src)
+}
+
+// FindLine returns the positions of the non-empty matches of the regexp
+// within line, and of their groups
+func (mm *MultiLineMatcher) FindLine(line []byte) [][]int {
+	return nonEmptyMatches(mm.Re.FindAllSubmatchIndex(line, -1))
+}
+
+// FindText returns the positions of the non-empty matches of the regexp in
+// src, and of their groups
+func (mm *MultiLineMatcher) FindText(src []byte) [][]int {
+	return nonEmptyMatches(mm.Re.FindAllSubmatchIndex(src, -1))
+}
+
+// WordMatcher only keeps the matches of another matcher that are whole
+// words: not right after or before a letter, digit or _
+type WordMatcher struct {
+	M SearchMatcher `desc:"the matcher whose matches are kept if they are whole words"`
+}
+
+// NewWordMatcher returns the matcher of the whole words that m matches --
+// a TextFinder if m is
+func NewWordMatcher(m SearchMatcher) SearchMatcher {
+	if _, ok := m.(TextFinder); ok {
+		return &TextWordMatcher{WordMatcher{M: m}}
+	}
+	return &WordMatcher{M: m}
+}
+
+// Match returns true if m matches anywhere in src -- it is the quick check
+// of whole files, so it can be true where there is no whole word
+func (wm *WordMatcher) Match(src []byte) bool {
+	return wm.M.Match(src)
+}
+
+// FindLine returns the positions of the matches of m within line that are
+// whole words
+func (wm *WordMatcher) FindLine(line []byte) [][]int {
+	return wordMatches(line, wm.M.FindLine(line))
+}
+
+// TextWordMatcher is a WordMatcher of a TextFinder
+type TextWordMatcher struct {
+	WordMatcher
+}
+
+// FindText returns the positions of the matches of m in src that are whole
+// words
+func (tw *TextWordMatcher) FindText(src []byte) [][]int {
+	return wordMatches(src, tw.M.(TextFinder).FindText(src))
+}
+
+// wordMatches returns the matches in src that are whole words
+func wordMatches(src []byte, ms [][]int) [][]int {
+	var wms [][]int
+	for _, mi := range ms {
+		if IsWholeWord(src, mi[0], mi[1]) {
+			wms = append(wms, mi)
+		}
+	}
+	return wms
+}
+
+// IsWholeWord returns true if the text from byte st to ed in src is not
+// right after or before a letter, digit or _
+func IsWholeWord(src []byte, st, ed int) bool {
+	if st > 0 {
+		if r, _ := utf8.DecodeLastRune(src[:st]); isWordRune(r) {
+			return false
+		}
+	}
+	if ed < len(src) {
+		if r, _ := utf8.DecodeRune(src[ed:]); isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordRune returns true if r is a letter, digit or _
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// SearchRegexp returns the regexp of a matcher, if it has one
+func SearchRegexp(m SearchMatcher) *regexp.Regexp {
+	switch mt := m.(type) {
+	case *RegexpMatcher:
+		return mt.Re
+	case *MultiLineMatcher:
+		return mt.Re
+	case *WordMatcher:
+		return SearchRegexp(mt.M)
+	case *TextWordMatcher:
+		return SearchRegexp(mt.M)
+	}
+	return nil
+}
+
+// SearchMatch returns the search match for the bytes from st to ed in given
+// line, with SearchContext runes of the line on either side, escaped, and
+// the match itself in <mark>
+func SearchMatch(line []byte, st, ed, ln int) giv.FileSearchMatch {
+	cst := st
+	for i := 0; i < SearchContext && cst > 0; i++ {
+		_, sz := utf8.DecodeLastRune(line[:cst])
+		cst -= sz
+	}
+	ced := ed
+	for i := 0; i < SearchContext && ced < len(line); i++ {
+		_, sz := utf8.DecodeRune(line[ced:])
+		ced += sz
+	}
+	txt := html.EscapeString(string(line[cst:st])) + "<mark>" + html.EscapeString(string(line[st:ed])) + "</mark>" + html.EscapeString(string(line[ed:ced]))
+	ch := utf8.RuneCount(line[:st])
+	ech := ch + utf8.RuneCount(line[st:ed])
+	reg := giv.TextRegion{Start: giv.TextPos{Ln: ln, Ch: ch}, End: giv.TextPos{Ln: ln, Ch: ech}}
+	return giv.FileSearchMatch{Reg: reg, Text: []byte(txt)}
+}
+
+// SearchMatchPlain returns the text of a search match without its markup
+func SearchMatchPlain(mt giv.FileSearchMatch) string {
+	txt := bytes.Replace(mt.Text, []byte("<mark>"), nil, -1)
+	txt = bytes.Replace(txt, []byte("</mark>"), nil, -1)
+	return html.UnescapeString(string(txt))
+}
+
+// SearchText returns the matches in given text, line by line, or in the
+// whole text for a TextFinder -- none if it looks like a binary file
+func SearchText(src []byte, m SearchMatcher) []giv.FileSearchMatch {
+	if !m.Match(src) || !ServeIsText(src) {
+		return nil
+	}
+	if tf, ok := m.(TextFinder); ok {
+		return searchTextAll(src, tf)
+	}
+	var ms []giv.FileSearchMatch
+	for ln := 0; len(src) > 0; ln++ {
+		line := src
+		if i := bytes.IndexByte(src, '\n'); i >= 0 {
+			line, src = src[:i], src[i+1:]
+		} else {
+			src = nil
+		}
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		for _, mi := range m.FindLine(line) {
+			ms = append(ms, SearchMatch(line, mi[0], mi[1], ln))
+		}
+	}
+	return ms
+}
+
+// searchTextAll returns the matches of tf in the whole text, which can
+// span lines -- each is shown with the line that it starts on
+func searchTextAll(src []byte, tf TextFinder) []giv.FileSearchMatch {
+	var ms []giv.FileSearchMatch
+	ln, ls := 0, 0 // line of the last match, and where it starts
+	for _, mi := range tf.FindText(src) {
+		st, ed := mi[0], mi[1]
+		for {
+			i := bytes.IndexByte(src[ls:st], '\n')
+			if i < 0 {
+				break
+			}
+			ls += i + 1
+			ln++
+		}
+		le := len(src)
+		if i := bytes.IndexByte(src[ls:], '\n'); i >= 0 {
+			le = ls + i
+		}
+		line := bytes.TrimSuffix(src[ls:le], []byte("\r"))
+		lst, led := st-ls, ed-ls
+		if lst > len(line) {
+			lst = len(line)
+		}
+		if led > len(line) {
+			led = len(line)
+		}
+		mt := SearchMatch(line, lst, led, ln)
+		if nl := bytes.Count(src[st:ed], []byte("\n")); nl > 0 {
+			els := st + bytes.LastIndexByte(src[st:ed], '\n') + 1
+			mt.Reg.End = giv.TextPos{Ln: ln + nl, Ch: utf8.RuneCount(src[els:ed])}
+		}
+		ms = append(ms, mt)
+	}
+	return ms
+}
+
+//////////////////////////////////////////////////////////////////////////
+//  Ignore rules
+
+// IgnoreRule is one pattern from a .gitignore file
+type IgnoreRule struct {
+	Base     string   `desc:"slash-separated directory of the .gitignore file, relative to the search root -- empty for the root"`
+	Segs     []string `desc:"path segments of the pattern, where ** matches any number of them"`
+	Neg      bool     `desc:"pattern started with ! -- matching paths are not ignored after all"`
+	DirOnly  bool     `desc:"pattern ended with / -- only matches directories"`
+	Anchored bool     `desc:"pattern has a / in it, so is matched against the path relative to Base, instead of just the name"`
+}
+
+// ParseIgnore parses the patterns of a .gitignore file in given directory,
+// slash-separated relative to the search root
+func ParseIgnore(base string, src []byte) []IgnoreRule {
+	var rules []IgnoreRule
+	for _, ln := range strings.Split(string(src), "\n") {
+		ln = strings.TrimRight(ln, " \t\r")
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		r := IgnoreRule{Base: base}
+		if strings.HasPrefix(ln, "!") {
+			r.Neg = true
+			ln = ln[1:]
+		}
+		ln = strings.TrimPrefix(ln, `\`)
+		if strings.HasSuffix(ln, "/") {
+			r.DirOnly = true
+			ln = strings.TrimRight(ln, "/")
+		}
+		if strings.Contains(ln, "/") {
+			r.Anchored = true
+			ln = strings.TrimPrefix(ln, "/")
+		}
+		if ln == "" {
+			continue
+		}
+		r.Segs = strings.Split(ln, "/")
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// Match returns true if the rule matches given path, slash-separated
+// relative to the search root
+func (r *IgnoreRule) Match(rel string, isDir bool) bool {
+	if r.DirOnly && !isDir {
+		return false
+	}
+	if r.Base != "" {
+		if !strings.HasPrefix(rel, r.Base+"/") {
+			return false
+		}
+		rel = rel[len(r.Base)+1:]
+	}
+	if !r.Anchored {
+		return globSegs(r.Segs, []string{path.Base(rel)})
+	}
+	return globSegs(r.Segs, strings.Split(rel, "/"))
+}
+
+// globSegs returns true if the path segments match the pattern segments,
+// where ** matches any number of segments
+func globSegs(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			if len(pat) == 1 {
+				return len(segs) > 0
+			}
+			for i := 0; i <= len(segs); i++ {
+				if globSegs(pat[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], segs[0]); !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// IgnoreMatch returns true if the path, slash-separated relative to the
+// search root, is ignored by the rules -- the last rule that matches it
+// decides, as in git
+func IgnoreMatch(rules []IgnoreRule, rel string, isDir bool) bool {
+	ign := false
+	for i := range rules {
+		if rules[i].Match(rel, isDir) {
+			ign = !rules[i].Neg
+		}
+	}
+	return ign
+}
+
+// searchDirRules returns the rules of the .gitignore files in the
+// directories above the one at rel, slash-separated relative to the root,
+// from the root down, for a search that starts there
+func searchDirRules(root, rel string) []IgnoreRule {
+	if rel == "" {
+		return nil
+	}
+	var rules []IgnoreRule
+	segs := strings.Split(rel, "/")
+	for i := range segs {
+		base := strings.Join(segs[:i], "/")
+		if src, err := ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(base), ".gitignore")); err == nil {
+			rules = append(rules, ParseIgnore(base, src)...)
+		}
+	}
+	return rules
+}
+
+// SearchGlobs are the globs that a project search is restricted to: files
+// must match one of the includes, if there are any, and none of the
+// excludes -- they are matched as the patterns of a .gitignore file at the
+// project root, so *.go matches in any folder, and vendor/** only at the
+// top
+type SearchGlobs struct {
+	Incl []IgnoreRule `desc:"a file must match one of these, if any"`
+	Excl []IgnoreRule `desc:"a file must match none of these"`
+}
+
+// ParseSearchGlobs parses globs separated by spaces or commas, where those
+// starting with ! are excludes, e.g., *.go !vendor/**
+func ParseSearchGlobs(globs string) SearchGlobs {
+	var sg SearchGlobs
+	fs := strings.FieldsFunc(globs, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	for _, r := range ParseIgnore("", []byte(strings.Join(fs, "\n"))) {
+		if r.Neg {
+			r.Neg = false
+			sg.Excl = append(sg.Excl, r)
+		} else {
+			sg.Incl = append(sg.Incl, r)
+		}
+	}
+	return sg
+}
+
+// Match returns true if the file at given path, slash-separated relative
+// to the project root, is included by the globs -- it is not if one of
+// its directories is excluded
+func (sg *SearchGlobs) Match(rel string) bool {
+	if sg.skip(rel) {
+		return false
+	}
+	for i, c := range rel {
+		if c == '/' && sg.SkipDir(rel[:i]) {
+			return false
+		}
+	}
+	if len(sg.Incl) == 0 {
+		return true
+	}
+	for i := range sg.Incl {
+		if sg.Incl[i].Match(rel, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipDir returns true if the directory at given path, slash-separated
+// relative to the project root, is excluded by the globs, with all of the
+// files within it, as it is by both !vendor and !vendor/**
+func (sg *SearchGlobs) SkipDir(rel string) bool {
+	for _, r := range sg.Excl {
+		if n := len(r.Segs); n > 1 && r.Segs[n-1] == "**" {
+			r.Segs = r.Segs[:n-1]
+		}
+		if r.Match(rel, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// skip returns true if the file matches one of the excludes
+func (sg *SearchGlobs) skip(rel string) bool {
+	for i := range sg.Excl {
+		if sg.Excl[i].Match(rel, false) {
+			return true
+		}
+	}
+	return false
+}
+
+//////////////////////////////////////////////////////////////////////////
+//  Project search
+
+// ProjSearchOpts are the options for a ProjSearch
+type ProjSearchOpts struct {
+	Root       string            `desc:"root directory of the project"`
+	Loc        FindLoc           `desc:"where to search -- FindLocFile is not handled here, as it only searches the active buffer"`
+	ActiveDir  string            `desc:"directory of the active file, for FindLocDir"`
+	Dir        string            `desc:"directory whose files, and those of the directories within it, are searched, for FindLocSubdir"`
+	Langs      LangNames         `desc:"only search files of these languages, if any"`
+	Globs      SearchGlobs       `desc:"only search files matching these globs"`
+	Bufs       map[string][]byte `desc:"text of the open buffers with unsaved changes, by path, searched instead of their files -- for FindLocOpen, all of the open buffers, which are all that is searched"`
+	Cands      map[string]bool   `desc:"if not nil, only these files, by path relative to the root, and the open buffers with unsaved changes, are searched -- the files that can have matches, from the project index, so the directories are not walked"`
+	MaxMatches int               `desc:"stop after this many matches, if > 0"`
+}
+
+// ProjSearchResults are the matches in one file from a ProjSearch
+type ProjSearchResults struct {
+	Path    string                `desc:"full path of the file"`
+	RelPath string                `desc:"path of the file relative to the project root"`
+	Matches []giv.FileSearchMatch `desc:"the matches, in order"`
+}
+
+// searchFile is a file to be searched by the ProjSearch workers
+type searchFile struct {
+	path, rel string
+}
+
+// ProjSearch searches the files of the project for the matches of m, in
+// parallel: one goroutine walks the directories, skipping those ignored by
+// .gitignore files, and a worker per cpu searches the files it finds,
+// large ones mapped into memory -- found is called with the results for
+// each file with matches as they arrive, in no particular order, from the
+// calling goroutine -- it stops when cancel returns true, or MaxMatches is
+// reached (trunc) -- returns the number of files searched and matches found
+func ProjSearch(opts *ProjSearchOpts, m SearchMatcher, cancel func() bool, found func(res ProjSearchResults)) (nfiles, nmatches int, trunc bool) {
+	var stop int32
+	stopped := func() bool {
+		if atomic.LoadInt32(&stop) != 0 {
+			return true
+		}
+		if cancel != nil && cancel() {
+			atomic.StoreInt32(&stop, 1)
+			return true
+		}
+		return false
+	}
+	files := make(chan searchFile, 256)
+	results := make(chan ProjSearchResults, 64)
+	go func() {
+		defer close(files)
+		switch {
+		case opts.Cands != nil && opts.Loc != FindLocOpen:
+			searchCands(opts, files, stopped)
+		case opts.Loc == FindLocDir:
+			searchWalk(opts, opts.ActiveDir, "", nil, false, files, stopped)
+		case opts.Loc == FindLocSubdir:
+			rel, err := filepath.Rel(opts.Root, opts.Dir)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				return
+			}
+			rel = filepath.ToSlash(rel)
+			if rel == "." {
+				rel = ""
+			}
+			searchWalk(opts, opts.Dir, rel, searchDirRules(opts.Root, rel), true, files, stopped)
+		case opts.Loc == FindLocOpen:
+			searchOpen(opts, files, stopped)
+		default:
+			searchWalk(opts, opts.Root, "", nil, true, files, stopped)
+		}
+	}()
+	var nf int64
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sf := range files {
+				if atomic.LoadInt32(&stop) != 0 {
+					continue // drain
+				}
+				atomic.AddInt64(&nf, 1)
+				if ms := searchFileMatches(opts, sf.path, m); len(ms) > 0 {
+					results <- ProjSearchResults{Path: sf.path, RelPath: sf.rel, Matches: ms}
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	for res := range results {
+		if stopped() {
+			continue // drain
+		}
+		if opts.MaxMatches > 0 && nmatches+len(res.Matches) > opts.MaxMatches {
+			res.Matches = res.Matches[:opts.MaxMatches-nmatches]
+			trunc = true
+			atomic.StoreInt32(&stop, 1)
+		}
+		nmatches += len(res.Matches)
+		if len(res.Matches) > 0 {
+			found(res)
+		}
+	}
+	return int(atomic.LoadInt64(&nf)), nmatches, trunc
+}
+
+// searchWalk sends the files to search in given directory to files, going
+// down into its subdirectories if recurse, with the ignore rules of its
+// parents, to which those of its .gitignore are added
+func searchWalk(opts *ProjSearchOpts, dir, rel string, rules []IgnoreRule, recurse bool, files chan<- searchFile, stopped func() bool) {
+	if stopped() {
+		return
+	}
+	if recurse {
+		if src, err := ioutil.ReadFile(filepath.Join(dir, ".gitignore")); err == nil {
+			rules = append(rules[:len(rules):len(rules)], ParseIgnore(rel, src)...)
+		}
+	}
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, fi := range fis {
+		nm := fi.Name()
+		frel := nm
+		if rel != "" {
+			frel = rel + "/" + nm
+		}
+		if fi.IsDir() {
+			if recurse && !SearchSkipDirs[nm] && frel != filepath.ToSlash(ProjIndexDir) && !IgnoreMatch(rules, frel, true) && !opts.Globs.SkipDir(frel) {
+				searchWalk(opts, filepath.Join(dir, nm), frel, rules, true, files, stopped)
+			}
+			continue
+		}
+		if !fi.Mode().IsRegular() || (strings.HasPrefix(nm, "#") && strings.HasSuffix(nm, "#")) {
+			continue
+		}
+		if opts.Loc == FindLocNotTop && rel == "" {
+			continue
+		}
+		if !LangNamesMatchFilename(nm, opts.Langs) || IgnoreMatch(rules, frel, false) {
+			continue
+		}
+		fpath := filepath.Join(dir, nm)
+		if r, err := filepath.Rel(opts.Root, fpath); err == nil {
+			frel = filepath.ToSlash(r)
+		}
+		if !opts.Globs.Match(frel) {
+			continue
+		}
+		if stopped() {
+			return
+		}
+		files <- searchFile{fpath, frel}
+	}
+}
+
+// searchOpen sends the open buffers to be searched, in the order of their
+// paths, for FindLocOpen
+func searchOpen(opts *ProjSearchOpts, files chan<- searchFile, stopped func() bool) {
+	paths := make([]string, 0, len(opts.Bufs))
+	for fpath := range opts.Bufs {
+		paths = append(paths, fpath)
+	}
+	sort.Strings(paths)
+	for _, fpath := range paths {
+		frel := filepath.Base(fpath)
+		if r, err := filepath.Rel(opts.Root, fpath); err == nil && !strings.HasPrefix(r, "..") {
+			frel = filepath.ToSlash(r)
+		}
+		if !LangNamesMatchFilename(fpath, opts.Langs) || !opts.Globs.Match(frel) {
+			continue
+		}
+		if stopped() {
+			return
+		}
+		files <- searchFile{fpath, frel}
+	}
+}
+
+// searchFileMatches returns the matches in the file at given path, or in
+// its buffer if it is open with unsaved changes
+func searchFileMatches(opts *ProjSearchOpts, fpath string, m SearchMatcher) []giv.FileSearchMatch {
+	if src, has := opts.Bufs[fpath]; has {
+		return SearchText(src, m)
+	}
+	src, done, err := SearchReadFile(fpath)
+	if err != nil {
+		return nil
+	}
+	defer done()
+	return SearchText(src, m)
+}
+
+// SearchBufs returns the text of the open buffers with unsaved changes, by
+// path, to be searched instead of their files -- or of all of the open
+// buffers, if all
+func (ge *Gide) SearchBufs(all bool) map[string][]byte {
+	bufs := make(map[string][]byte)
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil && (all || ond.Buf.IsChanged()) {
+			bufs[string(ond.FPath)] = ond.Buf.LinesToBytesCopy()
+		}
+	}
+	return bufs
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/goki/gi/giv"
+)
+
+func TestBMSearcher(t *testing.T) {
+	tests := []struct {
+		pat, s string
+		fold   bool
+		want   int
+	}{
+		{"needle", "haystack with a needle in it", false, 16},
+		{"needle", "haystack with a Needle in it", false, -1},
+		{"needle", "haystack with a NeEdLe in it", true, 16},
+		{"aab", "aaaab", false, 2},
+		{"x", "", false, -1},
+		{"", "abc", false, -1},
+	}
+	for _, tt := range tests {
+		if got := NewBMSearcher([]byte(tt.pat), tt.fold).Index([]byte(tt.s)); got != tt.want {
+			t.Errorf("Index(%q, %q, %v) = %v, want %v", tt.pat, tt.s, tt.fold, got, tt.want)
+		}
+	}
+	ms := NewBMSearcher([]byte("aa"), false).FindLine([]byte("aaaaa"))
+	if want := [][]int{{0, 2}, {2, 4}}; !reflect.DeepEqual(ms, want) {
+		t.Errorf("FindLine = %v, want %v", ms, want)
+	}
+}
+
+func TestSearchText(t *testing.T) {
+	src := []byte("func Foo() {\r\n\treturn foo < 1\n}\n")
+	m, _ := NewSearchMatcher("foo", true, false)
+	ms := SearchText(src, m)
+	if len(ms) != 2 {
+		t.Fatalf("SearchText: %d matches, want 2", len(ms))
+	}
+	if st, ed := ms[1].Reg.Start, ms[1].Reg.End; st.Ln != 1 || st.Ch != 8 || ed.Ch != 11 {
+		t.Errorf("SearchText region = %v-%v", st, ed)
+	}
+	if got, want := string(ms[1].Text), "\treturn <mark>foo</mark> &lt; 1"; got != want {
+		t.Errorf("SearchText text = %q, want %q", got, want)
+	}
+	if got, want := SearchMatchPlain(ms[1]), "\treturn foo < 1"; got != want {
+		t.Errorf("SearchMatchPlain = %q, want %q", got, want)
+	}
+	if ms := SearchText([]byte("foo\x00bar"), m); len(ms) != 0 {
+		t.Errorf("SearchText binary: %v", ms)
+	}
+
+	m, err := NewSearchMatcher(`^\s+(\w+) foo`, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ms := SearchText(src, m); len(ms) != 1 || ms[0].Reg.Start.Ln != 1 {
+		t.Errorf("SearchText regexp: %v", ms)
+	}
+	if _, err := NewSearchMatcher("(", false, true); err == nil {
+		t.Errorf("NewSearchMatcher: bad regexp is ok")
+	}
+	m, _ = NewSearchMatcher("ÉTÉ", true, false)
+	if ms := SearchText([]byte("un été chaud"), m); len(ms) != 1 || ms[0].Reg.Start.Ch != 3 || ms[0].Reg.End.Ch != 6 {
+		t.Errorf("SearchText unicode ignore case: %v", ms)
+	}
+}
+
+func TestSearchTextWholeWord(t *testing.T) {
+	src := []byte("foo foobar _foo foo_ (foo) été-foo\nfoo")
+	m, _ := NewFindMatcher("foo", false, false, true, false)
+	ms := SearchText(src, m)
+	var got []giv.TextPos
+	for _, mt := range ms {
+		got = append(got, mt.Reg.Start)
+	}
+	want := []giv.TextPos{{0, 0}, {0, 22}, {0, 31}, {1, 0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("whole word = %v, want %v", got, want)
+	}
+	if !IsWholeWord([]byte("été"), 0, len("été")) || IsWholeWord([]byte("étéx"), 0, len("été")) {
+		t.Errorf("IsWholeWord unicode")
+	}
+}
+
+func TestSearchTextMultiLine(t *testing.T) {
+	src := []byte("func Foo() {\r\n\treturn foo < 1\n}\nfunc Bar() {}\n")
+	m, err := NewFindMatcher(`\{\s*return (.*?)\}`, false, true, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms := SearchText(src, m)
+	if len(ms) != 1 {
+		t.Fatalf("multi-line: %d matches, want 1", len(ms))
+	}
+	if st, ed := ms[0].Reg.Start, ms[0].Reg.End; st != (giv.TextPos{0, 11}) || ed != (giv.TextPos{2, 1}) {
+		t.Errorf("multi-line region = %v-%v", st, ed)
+	}
+	if got, want := SearchMatchPlain(ms[0]), "func Foo() {"; got != want {
+		t.Errorf("multi-line text = %q, want %q", got, want)
+	}
+	m, _ = NewFindMatcher(`^func (\w+)`, true, true, false, true)
+	ms = SearchText(src, m)
+	if len(ms) != 2 || ms[1].Reg.Start != (giv.TextPos{3, 0}) || ms[1].Reg.End != (giv.TextPos{3, 8}) {
+		t.Errorf("multi-line ^: %v", ms)
+	}
+	m, _ = NewFindMatcher(`foo\s+<`, true, true, true, true)
+	if ms = SearchText(src, m); len(ms) != 1 || ms[0].Reg.Start != (giv.TextPos{1, 8}) {
+		t.Errorf("multi-line whole word: %v", ms)
+	}
+}
+
+func TestIgnoreMatch(t *testing.T) {
+	rules := ParseIgnore("", []byte("# comment\n*.o\n/build/\nlogs/**/*.log\n!keep.o\n"))
+	rules = append(rules, ParseIgnore("sub", []byte("gen/\n"))...)
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"a.o", false, true},
+		{"x/y/a.o", false, true},
+		{"x/keep.o", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"x/build", true, false},
+		{"logs/a.log", false, true},
+		{"logs/1/2/a.log", false, true},
+		{"sub/gen", true, true},
+		{"sub/x/gen", true, true},
+		{"gen", true, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := IgnoreMatch(rules, tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("IgnoreMatch(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestSearchGlobs(t *testing.T) {
+	sg := ParseSearchGlobs("*.go !vendor/** !*_test.go")
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"main.go", true},
+		{"a/b/c.go", true},
+		{"a/b/c_test.go", false},
+		{"vendor/x/y.go", false},
+		{"a/vendor/y.go", true},
+		{"README.md", false},
+	}
+	for _, tt := range tests {
+		if got := sg.Match(tt.rel); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+	if !sg.SkipDir("vendor") || sg.SkipDir("a") {
+		t.Errorf("SkipDir vendor, a = %v, %v, want true, false", sg.SkipDir("vendor"), sg.SkipDir("a"))
+	}
+	if sg = ParseSearchGlobs(""); !sg.Match("a/b.txt") {
+		t.Errorf("no globs did not match")
+	}
+}
+
+func TestProjSearch(t *testing.T) {
+	root, err := ioutil.TempDir("", "gide-search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	files := map[string]string{
+		".gitignore":     "ignored/\n*.gen\n",
+		"a/x.gen":        "find me\n",
+		"top.go":         "package top // find me\n",
+		"a/a.go":         "package a\n\n// find me, and find me again\n",
+		"a/b/b.txt":      "nothing here\n",
+		"ignored/i.go":   "package i // find me\n",
+		".git/HEAD":      "find me\n",
+		"a/open.go":      "package a\n",
+		"a/b/binary.dat": "find me\x00",
+	}
+	for p, txt := range files {
+		fp := filepath.Join(root, filepath.FromSlash(p))
+		os.MkdirAll(filepath.Dir(fp), 0755)
+		if err := ioutil.WriteFile(fp, []byte(txt), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bufs := map[string][]byte{filepath.Join(root, "a", "open.go"): []byte("package a // find me, unsaved\n")}
+	m, _ := NewSearchMatcher("find me", false, false)
+	search := func(opts *ProjSearchOpts) ([]string, int, int, bool) {
+		var rels []string
+		nf, nm, trunc := ProjSearch(opts, m, nil, func(res ProjSearchResults) {
+			rels = append(rels, res.RelPath)
+		})
+		sort.Strings(rels)
+		return rels, nf, nm, trunc
+	}
+	rels, nf, nm, trunc := search(&ProjSearchOpts{Root: root, Bufs: bufs})
+	if want := []string{"a/a.go", "a/open.go", "top.go"}; !reflect.DeepEqual(rels, want) || nm != 4 || nf != 6 || trunc {
+		t.Errorf("ProjSearch = %v, %d files, %d matches, %v, want %v, 6, 4, false", rels, nf, nm, trunc, want)
+	}
+	rels, _, _, _ = search(&ProjSearchOpts{Root: root, Loc: FindLocNotTop})
+	if want := []string{"a/a.go"}; !reflect.DeepEqual(rels, want) {
+		t.Errorf("ProjSearch not top = %v, want %v", rels, want)
+	}
+	rels, _, _, _ = search(&ProjSearchOpts{Root: root, Loc: FindLocDir, ActiveDir: filepath.Join(root, "ignored")})
+	if want := []string{"ignored/i.go"}; !reflect.DeepEqual(rels, want) {
+		t.Errorf("ProjSearch dir = %v, want %v", rels, want)
+	}
+	rels, _, _, _ = search(&ProjSearchOpts{Root: root, Loc: FindLocSubdir, Dir: filepath.Join(root, "a"), Bufs: bufs})
+	if want := []string{"a/a.go", "a/open.go"}; !reflect.DeepEqual(rels, want) {
+		t.Errorf("ProjSearch subdir = %v, want %v", rels, want)
+	}
+	rels, _, _, _ = search(&ProjSearchOpts{Root: root, Globs: ParseSearchGlobs("*.go, !a/**"), Bufs: bufs})
+	if want := []string{"top.go"}; !reflect.DeepEqual(rels, want) {
+		t.Errorf("ProjSearch globs = %v, want %v", rels, want)
+	}
+	obufs := map[string][]byte{filepath.Join(root, "top.go"): []byte("find me\n"), filepath.Join(root, "a", "b", "b.txt"): []byte("find me\n")}
+	rels, _, _, _ = search(&ProjSearchOpts{Root: root, Loc: FindLocOpen, Globs: ParseSearchGlobs("!b"), Bufs: obufs})
+	if want := []string{"top.go"}; !reflect.DeepEqual(rels, want) {
+		t.Errorf("ProjSearch open = %v, want %v", rels, want)
+	}
+	_, _, nm, trunc = search(&ProjSearchOpts{Root: root, MaxMatches: 2})
+	if nm != 2 || !trunc {
+		t.Errorf("ProjSearch max = %d, %v, want 2, true", nm, trunc)
+	}
+	n := 0
+	ProjSearch(&ProjSearchOpts{Root: root}, m, func() bool { return true }, func(res ProjSearchResults) { n++ })
+	if n != 0 {
+		t.Errorf("ProjSearch cancelled found %d", n)
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/window"
+	"github.com/goki/ki"
+)
+
+// RecoveryDirName is the name of the directory in the GoGi prefs directory
+// where the unsaved contents of open files are written periodically, to
+// recover them if gide does not exit cleanly
+var RecoveryDirName = "gide_recover"
+
+// RecoveryFile is the unsaved contents of an open file, written periodically
+// so they can be recovered after a crash
+type RecoveryFile struct {
+	Path string    `desc:"full path of the file"`
+	Time time.Time `desc:"when the contents were written"`
+	Pid  int       `desc:"process id of the gide that wrote them -- they are only recovered when it is no longer running"`
+	Text string    `desc:"the unsaved contents of the file"`
+}
+
+// RecoveryFileName returns the name of the file in given directory that the
+// unsaved contents of the file at given path are written to
+func RecoveryFileName(dir, fpath string) string {
+	h := sha256.Sum256([]byte(fpath))
+	return filepath.Join(dir, hex.EncodeToString(h[:8])+".json")
+}
+
+// SaveRecovery writes given recovery file to given directory
+func SaveRecovery(dir string, rf *RecoveryFile) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(rf)
+	if err != nil {
+		return err
+	}
+	fnm := RecoveryFileName(dir, rf.Path)
+	tmp := fnm + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fnm) // so a crash while writing leaves the last one
+}
+
+// RemoveRecovery removes the recovery file for the file at given path from
+// given directory, if any
+func RemoveRecovery(dir, fpath string) {
+	os.Remove(RecoveryFileName(dir, fpath))
+}
+
+// ListRecovery returns the recovery files in given directory, sorted by
+// path -- those that can not be read are skipped
+func ListRecovery(dir string) []*RecoveryFile {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var rfs []*RecoveryFile
+	for _, fi := range fis {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".json" {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			continue
+		}
+		rf := &RecoveryFile{}
+		if json.Unmarshal(b, rf) != nil || rf.Path == "" {
+			continue
+		}
+		rfs = append(rfs, rf)
+	}
+	sort.Slice(rfs, func(i, j int) bool {
+		return rfs[i].Path < rfs[j].Path
+	})
+	return rfs
+}
+
+// Stale returns true if the gide that wrote the recovery file is no longer
+// running, so its contents can be recovered
+func (rf *RecoveryFile) Stale() bool {
+	if rf.Pid == os.Getpid() {
+		return false
+	}
+	p, err := os.FindProcess(rf.Pid)
+	if err != nil {
+		return true
+	}
+	return p.Signal(syscall.Signal(0)) != nil
+}
+
+// InDir returns true if the file of the recovery file is in given directory
+func (rf *RecoveryFile) InDir(dir string) bool {
+	rp, err := filepath.Rel(dir, rf.Path)
+	return err == nil && rp != ".." && !strings.HasPrefix(rp, ".."+string(filepath.Separator))
+}
+
+// RecoveryDir returns the directory that recovery files are written to
+func RecoveryDir() string {
+	return filepath.Join(oswin.TheApp.AppPrefsDir(), RecoveryDirName)
+}
+
+// RecoverySave writes the unsaved contents of all the open files that have
+// changed since they were last written, so they can be recovered after a
+// crash -- it must be called on the GUI thread, where the contents are
+// copied, and they are written in the background
+func (ge *Gide) RecoverySave() {
+	var rfs []*RecoveryFile
+	for _, ond := range ge.OpenNodes {
+		tb := ond.Buf
+		if tb == nil || tb.Filename == "" || !tb.IsChanged() {
+			continue
+		}
+		rfs = append(rfs, &RecoveryFile{Path: string(tb.Filename), Time: time.Now(), Pid: os.Getpid(), Text: string(tb.LinesToBytesCopy())})
+	}
+	if len(rfs) == 0 {
+		return
+	}
+	ge.recoverMu.Lock()
+	gen := ge.recoverGen
+	ge.recoverMu.Unlock()
+	go ge.recoveryWrite(RecoveryDir(), gen, rfs)
+}
+
+// recoveryWrite writes given recovery files to given directory, skipping
+// those whose contents were already written -- none are written if any
+// recovery file has been removed since they were copied, at generation gen,
+// as they could be from before it was saved
+func (ge *Gide) recoveryWrite(dir string, gen int, rfs []*RecoveryFile) {
+	ge.recoverMu.Lock()
+	defer ge.recoverMu.Unlock()
+	if ge.recoverGen != gen {
+		return
+	}
+	if ge.recovered == nil {
+		ge.recovered = make(map[string]string)
+	}
+	for _, rf := range rfs {
+		hash := UndoHash([]byte(rf.Text))
+		if ge.recovered[rf.Path] == hash {
+			continue
+		}
+		if err := SaveRecovery(dir, rf); err == nil {
+			ge.recovered[rf.Path] = hash
+		}
+	}
+}
+
+// RecoveryRemove removes the recovery file for given buffer, which has been
+// saved or closed
+func (ge *Gide) RecoveryRemove(tb *giv.TextBuf) {
+	if tb.Filename == "" {
+		return
+	}
+	fpath := string(tb.Filename)
+	ge.recoverMu.Lock()
+	defer ge.recoverMu.Unlock()
+	ge.recoverGen++
+	if _, has := ge.recovered[fpath]; !has {
+		return
+	}
+	delete(ge.recovered, fpath)
+	RemoveRecovery(RecoveryDir(), fpath)
+}
+
+// StartRecovery starts writing the unsaved contents of the open files every
+// RecoverySecs (see Editor prefs), and offers to recover the unsaved
+// contents of files in the project left by a gide that did not exit cleanly
+func (ge *Gide) StartRecovery() {
+	ge.recoverStop = make(chan struct{})
+	go func(stop chan struct{}) {
+		tick := time.NewTicker(time.Second)
+		defer tick.Stop()
+		last := time.Now()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-tick.C:
+				ge.RunOnGui(func() {
+					if ge.recoverStop != stop { // stopped since
+						return
+					}
+					secs := ge.Prefs.Editor.RecoverySecs
+					if secs > 0 && now.Sub(last) >= time.Duration(secs)*time.Second {
+						ge.RecoverySave()
+						last = now
+					}
+				})
+			}
+		}
+	}(ge.recoverStop)
+	ge.RecoveryCheck()
+}
+
+// StopRecovery stops writing the unsaved contents of the open files, and
+// removes the ones written, as gide is exiting cleanly
+func (ge *Gide) StopRecovery() {
+	if ge.recoverStop != nil {
+		close(ge.recoverStop)
+		ge.recoverStop = nil
+	}
+	ge.recoverMu.Lock()
+	defer ge.recoverMu.Unlock()
+	ge.recoverGen++
+	for fpath := range ge.recovered {
+		RemoveRecovery(RecoveryDir(), fpath)
+	}
+	ge.recovered = nil
+}
+
+// RecoveryCheck offers to recover the unsaved contents of each file in the
+// project left by a gide that did not exit cleanly, showing the differences
+// from the file on disk in the Diff tab
+func (ge *Gide) RecoveryCheck() {
+	if ge.ProjRoot == "" {
+		return
+	}
+	var rfs []*RecoveryFile
+	for _, rf := range ListRecovery(RecoveryDir()) {
+		if rf.InDir(string(ge.ProjRoot)) && rf.Stale() {
+			rfs = append(rfs, rf)
+		}
+	}
+	ge.recoveryPrompt(rfs)
+}
+
+// recoveryPrompt offers to recover the first of given recovery files, and
+// then the rest
+func (ge *Gide) recoveryPrompt(rfs []*RecoveryFile) {
+	if len(rfs) == 0 {
+		return
+	}
+	rf := rfs[0]
+	_, fnm := filepath.Split(rf.Path)
+	disk, _ := ioutil.ReadFile(rf.Path)
+	disk = ConvertLineEnds(disk, LineEndsLF) // as the recovered text has them
+	ge.DiffVersions(fnm+" (on disk)", disk, fnm+" (recovered)", []byte(rf.Text))
+	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "Recover Unsaved Changes",
+		Prompt: fmt.Sprintf("gide did not exit cleanly, and there are unsaved changes to <b>%v</b> from %v -- the Diff tab shows them against the file on disk.  Recover them into the file (save it then to keep them), discard them, or decide the next time the project is opened?", rf.Path, rf.Time.Format("2006-01-02 15:04:05"))},
+		[]string{"Recover", "Discard", "Later"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			switch sig {
+			case 0:
+				ge.Recover(rf)
+			case 1:
+				RemoveRecovery(RecoveryDir(), rf.Path)
+			}
+			ge.recoveryPrompt(rfs[1:])
+		})
+}
+
+// Recover opens the file of given recovery file and replaces its contents
+// with the recovered ones, as an undoable edit, leaving it unsaved
+func (ge *Gide) Recover(rf *RecoveryFile) {
+	tv, _, ok := ge.ViewFile(gi.FileName(rf.Path))
+	if !ok || tv.Buf == nil {
+		ge.SetStatus("Could not open file to recover: " + rf.Path)
+		return
+	}
+	tb := tv.Buf
+	if nl := len(tb.Lines); nl > 0 {
+		tb.DeleteText(giv.TextPos{}, giv.TextPos{Ln: nl - 1, Ch: len(tb.Lines[nl-1])}, true, true)
+	}
+	tb.InsertText(giv.TextPos{}, []byte(rf.Text), true, true)
+	tv.SetCursorShow(giv.TextPos{})
+	RemoveRecovery(RecoveryDir(), rf.Path) // written again while unsaved
+	ge.SetStatus("Recovered unsaved changes to: " + rf.Path)
+}
+
+// WindowFocusEvent saves all the open files with unsaved changes when the
+// window loses the focus, if SaveOnFocusLoss is on in the Editor prefs
+func (ge *Gide) WindowFocusEvent() {
+	ge.ConnectEvent(oswin.WindowEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		gee := recv.Embed(KiT_Gide).(*Gide)
+		we := d.(*window.Event)
+		if we.Action != window.DeFocus || !gee.Prefs.Editor.SaveOnFocusLoss {
+			return
+		}
+		if nch := gee.NChangedFiles(); nch > 0 {
+			gee.SaveAllOpenNodes()
+			gee.SetStatus(fmt.Sprintf("Saved %d files on losing focus", nch))
+		}
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRecoveryFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-recover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	rdir := filepath.Join(dir, "recover")
+	tm := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	for _, fp := range []string{"/proj/b.go", "/proj/a.go"} {
+		rf := &RecoveryFile{Path: fp, Time: tm, Pid: 1, Text: "package " + filepath.Base(fp) + "\n"}
+		if err := SaveRecovery(rdir, rf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rf := &RecoveryFile{Path: "/proj/a.go", Time: tm, Pid: 1, Text: "package a\n\nfunc A() {}\n"}
+	if err := SaveRecovery(rdir, rf); err != nil {
+		t.Fatal(err)
+	}
+	rfs := ListRecovery(rdir)
+	if len(rfs) != 2 {
+		t.Fatalf("list: %d files, want 2", len(rfs))
+	}
+	if rfs[0].Path != "/proj/a.go" || rfs[0].Text != rf.Text || !rfs[0].Time.Equal(tm) {
+		t.Errorf("list: got %v, want %v", rfs[0], rf)
+	}
+	RemoveRecovery(rdir, "/proj/a.go")
+	rfs = ListRecovery(rdir)
+	if len(rfs) != 1 || rfs[0].Path != "/proj/b.go" {
+		t.Errorf("after remove: %v, want only /proj/b.go", rfs)
+	}
+}
+
+func TestRecoveryInDir(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		want      bool
+	}{
+		{"/proj/a.go", "/proj", true},
+		{"/proj/sub/a.go", "/proj", true},
+		{"/projx/a.go", "/proj", false},
+		{"/other/a.go", "/proj", false},
+	}
+	for _, tt := range tests {
+		rf := &RecoveryFile{Path: filepath.FromSlash(tt.path)}
+		if got := rf.InDir(filepath.FromSlash(tt.dir)); got != tt.want {
+			t.Errorf("%v in %v: %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestRecoveryStale(t *testing.T) {
+	rf := &RecoveryFile{Pid: os.Getpid()}
+	if rf.Stale() {
+		t.Errorf("own process is stale")
+	}
+	if runtime.GOOS == "windows" {
+		return
+	}
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skip("can not run true:", err)
+	}
+	rf.Pid = cmd.Process.Pid
+	if !rf.Stale() {
+		t.Errorf("exited process %d is not stale", rf.Pid)
+	}
+}
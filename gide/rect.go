@@ -0,0 +1,164 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/giv"
+)
+
+// TextRect is a rectangular (column) region of text: the columns from StCh
+// up to EdCh on each of the lines from StLn through EdLn
+type TextRect struct {
+	StLn, EdLn int
+	StCh, EdCh int
+}
+
+// RectFromCorners returns the rectangle with given opposite corners
+func RectFromCorners(a, b giv.TextPos) TextRect {
+	r := TextRect{StLn: a.Ln, EdLn: b.Ln, StCh: a.Ch, EdCh: b.Ch}
+	if r.StLn > r.EdLn {
+		r.StLn, r.EdLn = r.EdLn, r.StLn
+	}
+	if r.StCh > r.EdCh {
+		r.StCh, r.EdCh = r.EdCh, r.StCh
+	}
+	return r
+}
+
+// LineReg returns the region of the rectangle on given line of given text,
+// limited to the length of the line
+func (r *TextRect) LineReg(lines [][]rune, ln int) giv.TextRegion {
+	ll := len(lines[ln])
+	st, ed := r.StCh, r.EdCh
+	if st > ll {
+		st = ll
+	}
+	if ed > ll {
+		ed = ll
+	}
+	return giv.TextRegion{Start: giv.TextPos{Ln: ln, Ch: st}, End: giv.TextPos{Ln: ln, Ch: ed}}
+}
+
+// Text returns the text of the rectangle in given lines, one string per
+// line, padded with spaces to the width of the rectangle
+func (r *TextRect) Text(lines [][]rune) []string {
+	var txt []string
+	w := r.EdCh - r.StCh
+	for ln := r.StLn; ln <= r.EdLn && ln < len(lines); ln++ {
+		reg := r.LineReg(lines, ln)
+		s := string(lines[ln][reg.Start.Ch:reg.End.Ch])
+		if n := len([]rune(s)); n < w {
+			s += strings.Repeat(" ", w-n)
+		}
+		txt = append(txt, s)
+	}
+	return txt
+}
+
+// RectFromSel returns the rectangle with the start and end of the selection
+// in given view as its corners
+func RectFromSel(tv *giv.TextView) (TextRect, bool) {
+	if tv == nil || tv.Buf == nil || !tv.HasSelection() {
+		return TextRect{}, false
+	}
+	return RectFromCorners(tv.SelectReg.Start, tv.SelectReg.End), true
+}
+
+// RectSelect turns the selection in the active view into a rectangular
+// selection, with the corners at the start and end of the selection: it is
+// selected on each line, with a cursor there, so that typing replaces the
+// text in the rectangle on every line, and delete deletes it
+func (ge *Gide) RectSelect() {
+	tv := ge.ActiveTextView()
+	r, ok := RectFromSel(tv)
+	if !ok {
+		ge.SetStatus("Select from one corner of the rectangle to the other")
+		return
+	}
+	mc := ge.CursorsFor(tv)
+	mc.Regs = nil
+	ln := tv.CursorPos.Ln
+	for l := r.StLn; l <= r.EdLn; l++ {
+		if l != ln {
+			mc.Add(r.LineReg(tv.Buf.Lines, l))
+		}
+	}
+	reg := r.LineReg(tv.Buf.Lines, ln)
+	updt := tv.UpdateStart()
+	tv.SetCursorShow(reg.End)
+	tv.SelectReg = reg
+	tv.UpdateEnd(updt)
+	mc.Last = reg.End
+	ge.ShowCursors(tv)
+}
+
+// RectCopy copies the text in the rectangle with its corners at the start
+// and end of the selection in the active view, for RectYank
+func (ge *Gide) RectCopy() {
+	tv := ge.ActiveTextView()
+	r, ok := RectFromSel(tv)
+	if !ok {
+		ge.SetStatus("Select from one corner of the rectangle to the other")
+		return
+	}
+	ge.RectBuf = r.Text(tv.Buf.Lines)
+	ge.SetStatus(fmt.Sprintf("Copied rectangle of %d lines", len(ge.RectBuf)))
+}
+
+// RectKill deletes the text in the rectangle with its corners at the start
+// and end of the selection in the active view, saving it for RectYank
+func (ge *Gide) RectKill() {
+	tv := ge.ActiveTextView()
+	r, ok := RectFromSel(tv)
+	if !ok {
+		ge.SetStatus("Select from one corner of the rectangle to the other")
+		return
+	}
+	ge.RectBuf = r.Text(tv.Buf.Lines)
+	tv.SelectReset()
+	for ln := r.StLn; ln <= r.EdLn; ln++ {
+		reg := r.LineReg(tv.Buf.Lines, ln)
+		if reg.Start != reg.End {
+			tv.Buf.DeleteText(reg.Start, reg.End, true, true)
+		}
+	}
+	tv.SetCursorShow(giv.TextPos{Ln: r.StLn, Ch: r.LineReg(tv.Buf.Lines, r.StLn).Start.Ch})
+	ge.SetStatus(fmt.Sprintf("Killed rectangle of %d lines", len(ge.RectBuf)))
+}
+
+// RectYank inserts the last copied or killed rectangle with its top left
+// corner at the cursor in the active view, on that and the following lines
+// -- short lines are padded with spaces, and lines are added at the end as
+// needed
+func (ge *Gide) RectYank() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	if len(ge.RectBuf) == 0 {
+		ge.SetStatus("No rectangle has been copied or killed")
+		return
+	}
+	tb := tv.Buf
+	cp := tv.CursorPos
+	for i, s := range ge.RectBuf {
+		ln := cp.Ln + i
+		if ln >= len(tb.Lines) {
+			end := giv.TextPos{Ln: len(tb.Lines) - 1, Ch: len(tb.Lines[len(tb.Lines)-1])}
+			tb.InsertText(end, []byte("\n"), true, true)
+		}
+		ch := cp.Ch
+		if ll := len(tb.Lines[ln]); ll < ch {
+			s = strings.Repeat(" ", ch-ll) + s
+			ch = ll
+		}
+		tb.InsertText(giv.TextPos{Ln: ln, Ch: ch}, []byte(s), true, true)
+	}
+	tv.SetCursorShow(cp)
+	ge.SetStatus(fmt.Sprintf("Inserted rectangle of %d lines", len(ge.RectBuf)))
+}
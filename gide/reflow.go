@@ -0,0 +1,282 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/goki/gi/giv"
+)
+
+// CommentBlock returns the range of lines [st, ed) of the comment containing
+// line ln, and the prefix of its first line: the indentation, comment marker
+// and spaces after it -- for a line comment with given marker, e.g., //, it
+// is the run of lines with the same indentation and marker, and for a /* */
+// block, the lines between the /* and */ lines, which are kept as they are,
+// with any * starting them as the marker -- ok is false if not in a comment
+func CommentBlock(lines []string, ln int, marker string) (st, ed int, pfx string, ok bool) {
+	if ln < 0 || ln >= len(lines) {
+		return
+	}
+	marker = strings.TrimSpace(marker)
+	ind := leadingSpace(lines[ln])
+	isCmt := func(l string) bool {
+		return marker != "" && leadingSpace(l) == ind && strings.HasPrefix(l[len(ind):], marker)
+	}
+	if isCmt(lines[ln]) {
+		for st = ln; st > 0 && isCmt(lines[st-1]); st-- {
+		}
+		for ed = ln + 1; ed < len(lines) && isCmt(lines[ed]); ed++ {
+		}
+		return st, ed, commentLinePrefix(lines[st], ind+marker), true
+	}
+	for st = ln; st >= 0; st-- {
+		l := lines[st]
+		if strings.Contains(l, "*/") && st < ln {
+			return 0, 0, "", false
+		}
+		if strings.Contains(l, "/*") {
+			break
+		}
+	}
+	if st < 0 {
+		return 0, 0, "", false
+	}
+	for ed = ln; ed < len(lines) && (ed == st || !strings.Contains(lines[ed], "*/")); ed++ {
+		if ed > st && strings.Contains(lines[ed], "/*") {
+			return 0, 0, "", false
+		}
+	}
+	if ed == len(lines) || strings.Contains(lines[st], "*/") {
+		return 0, 0, "", false
+	}
+	st++
+	if st >= ed {
+		return 0, 0, "", false
+	}
+	ind = leadingSpace(lines[st])
+	if strings.HasPrefix(lines[st][len(ind):], "*") {
+		return st, ed, commentLinePrefix(lines[st], ind+"*"), true
+	}
+	return st, ed, ind, true
+}
+
+// commentLinePrefix returns lead, the start of given comment line up to and
+// including its marker, with the spaces after it in the line
+func commentLinePrefix(line, lead string) string {
+	rest := line[len(lead):]
+	return lead + rest[:len(rest)-len(strings.TrimLeft(rest, " "))]
+}
+
+// commentListRe matches a list item at the start of comment text
+var commentListRe = regexp.MustCompile(`^([-*+]|\d+[.)])\s+\S`)
+
+// TextWidth returns the width of given text in columns, counting tabs as
+// moving to the next multiple of tabSize
+func TextWidth(s string, tabSize int) int {
+	w := 0
+	for _, r := range s {
+		if r == '\t' && tabSize > 0 {
+			w += tabSize - w%tabSize
+		} else {
+			w++
+		}
+	}
+	return w
+}
+
+// ReflowComment returns given lines of a comment block, whose first line
+// has given prefix, rewrapped to fit within width columns -- paragraphs are
+// separated by blank comment lines, list items (-, *, 1. etc) start new
+// ones and are wrapped with a hanging indent, and lines indented beyond the
+// prefix, e.g., code examples, are kept as they are
+func ReflowComment(lines []string, pfx string, width, tabSize int) []string {
+	lead := strings.TrimRight(pfx, " ")
+	if strings.TrimSpace(lead) == "" { // just indentation
+		lead = pfx
+	}
+	nsp := len(pfx) - len(lead)
+	var out []string
+	var words []string
+	hang := ""
+	flush := func() {
+		ln := ""
+		for _, w := range words {
+			switch {
+			case ln == "":
+				ln = w
+			case TextWidth(pfx+ln+" "+w, tabSize) > width:
+				out = append(out, pfx+ln)
+				ln = hang + w
+			default:
+				ln += " " + w
+			}
+		}
+		if ln != "" {
+			out = append(out, pfx+ln)
+		}
+		words = nil
+	}
+	for _, l := range lines {
+		t := strings.TrimLeft(l, " \t")
+		if strings.HasPrefix(l, lead) {
+			t = l[len(lead):]
+			for i := 0; i < nsp && strings.HasPrefix(t, " "); i++ {
+				t = t[1:]
+			}
+		}
+		switch {
+		case strings.TrimSpace(t) == "":
+			flush()
+			hang = ""
+			out = append(out, strings.TrimRight(lead, " \t"))
+		case commentListRe.MatchString(t):
+			flush()
+			hang = strings.Repeat(" ", strings.IndexAny(t, " \t")+1)
+			words = strings.Fields(t)
+		case hang != "" && strings.HasPrefix(t, hang) && strings.TrimSpace(t[:len(hang)+1]) != "":
+			words = append(words, strings.Fields(t)...)
+		case t[0] == ' ' || t[0] == '\t':
+			flush()
+			hang = ""
+			out = append(out, l)
+		default:
+			if hang != "" {
+				flush()
+				hang = ""
+			}
+			words = append(words, strings.Fields(t)...)
+		}
+	}
+	flush()
+	return out
+}
+
+// docStubRes match declarations that doc comment stubs are made for, with
+// the indentation and name as the first two groups
+var docStubRes = []*regexp.Regexp{
+	regexp.MustCompile(`^(\s*)func\s+(?:\([^)]*\)\s*)?(\w+)`),
+	regexp.MustCompile(`^(\s*)(?:type|var|const)\s+(\w+)`),
+	regexp.MustCompile(`^(\s*)(?:export\s+)?(?:default\s+)?(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:function\*?|fn|class|struct|interface|enum|trait)\s+(\w+)`),
+}
+
+// docStubPyRe matches a Python function or class, with the indentation,
+// def or class, name and any parameters
+var docStubPyRe = regexp.MustCompile(`^(\s*)(?:async\s+)?(def|class)\s+(\w+)\s*(?:\(([^)]*)\)?)?`)
+
+// DocStub returns the lines of a doc comment skeleton for the declaration on
+// given line, for a language with given line comment marker, named for it as
+// godoc and similar tools expect -- after is true if the comment goes after
+// the line, as a Python docstring, and otherwise it goes before it -- col is
+// where the description is to be typed in the first line -- ok is false if
+// the line is not a declaration
+func DocStub(line, marker, unit string) (stub []string, after bool, col int, ok bool) {
+	marker = strings.TrimSpace(marker)
+	if m := docStubPyRe.FindStringSubmatch(line); m != nil && marker == "#" {
+		ind := m[1] + unit
+		stub = []string{ind + `"""` + m[3] + " "}
+		col = len(stub[0])
+		var args []string
+		if m[2] == "def" {
+			for _, p := range strings.Split(m[4], ",") {
+				p = strings.TrimSpace(p)
+				if i := strings.IndexAny(p, ":="); i >= 0 {
+					p = strings.TrimSpace(p[:i])
+				}
+				p = strings.TrimLeft(p, "*")
+				if p != "" && p != "self" && p != "cls" && p != "/" {
+					args = append(args, p)
+				}
+			}
+		}
+		if len(args) > 0 {
+			stub = append(stub, "", ind+"Args:")
+			for _, a := range args {
+				stub = append(stub, ind+unit+a+": ")
+			}
+		}
+		stub = append(stub, ind+`"""`)
+		return stub, true, col, true
+	}
+	if marker == "" {
+		marker = "//"
+	}
+	for _, re := range docStubRes {
+		if m := re.FindStringSubmatch(line); m != nil {
+			s := m[1] + marker + " " + m[2] + " "
+			return []string{s}, false, len(s), true
+		}
+	}
+	return nil, false, 0, false
+}
+
+// ReflowCommentAtCursor rewraps the comment at the cursor in the active view
+// to fit within the fill column of the editor preferences, keeping its
+// comment markers and indentation (see ReflowComment)
+func (ge *Gide) ReflowCommentAtCursor() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil || tv.IsInactive() {
+		return false
+	}
+	tb := tv.Buf
+	a := make([]string, len(tb.Lines))
+	for i, l := range tb.Lines {
+		a[i] = string(l)
+	}
+	ir := IndentRulesFor(tb)
+	st, ed, pfx, ok := CommentBlock(a, tv.CursorPos.Ln, ir.Comment)
+	if !ok {
+		ge.SetStatus("Reflow: cursor is not in a comment")
+		return false
+	}
+	width := ge.Prefs.Editor.FillColumn
+	if width <= 0 {
+		width = 80
+	}
+	out := ReflowComment(a[st:ed], pfx, width, tb.Opts.TabSize)
+	if strings.Join(out, "\n") == strings.Join(a[st:ed], "\n") {
+		return true
+	}
+	le := LineEdit{St: st, Ed: ed, Lines: out}
+	rst, red, txt := le.Region(a)
+	tb.DeleteText(rst, red, true, true)
+	tb.InsertText(rst, []byte(txt), true, true)
+	tv.SetCursorShow(giv.TextPos{Ln: st})
+	return true
+}
+
+// DocStubAtCursor inserts a doc comment skeleton for the declaration at the
+// cursor in the active view, named for it, e.g., // Name for a Go function,
+// and puts the cursor where its description goes (see DocStub)
+func (ge *Gide) DocStubAtCursor() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil || tv.IsInactive() {
+		return false
+	}
+	tb := tv.Buf
+	ln := tv.CursorPos.Ln
+	if ln >= len(tb.Lines) {
+		return false
+	}
+	ir := IndentRulesFor(tb)
+	stub, after, col, ok := DocStub(string(tb.Lines[ln]), ir.Comment, ir.Unit)
+	if !ok {
+		ge.SetStatus("Doc comment: no declaration at cursor")
+		return false
+	}
+	txt := strings.Join(stub, "\n")
+	pos := giv.TextPos{Ln: ln}
+	if after {
+		pos.Ch = len(tb.Lines[ln])
+		txt = "\n" + txt
+		ln++
+	} else {
+		txt += "\n"
+	}
+	tb.InsertText(pos, []byte(txt), true, true)
+	tv.SetCursorShow(giv.TextPos{Ln: ln, Ch: len([]rune(stub[0][:col]))})
+	return true
+}
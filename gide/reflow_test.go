@@ -0,0 +1,98 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommentBlock(t *testing.T) {
+	lines := strings.Split(`package p
+
+	// first line of a comment
+	// that continues
+func f() {
+	/*
+	 * block
+	 */
+	x := 1 // trailing
+}`, "\n")
+	tests := []struct {
+		ln     int
+		st, ed int
+		pfx    string
+		ok     bool
+	}{
+		{2, 2, 4, "\t// ", true},
+		{3, 2, 4, "\t// ", true},
+		{5, 6, 7, "\t * ", true},
+		{6, 6, 7, "\t * ", true},
+		{8, 0, 0, "", false},
+		{0, 0, 0, "", false},
+	}
+	for _, tt := range tests {
+		st, ed, pfx, ok := CommentBlock(lines, tt.ln, "// ")
+		if st != tt.st || ed != tt.ed || pfx != tt.pfx || ok != tt.ok {
+			t.Errorf("CommentBlock line %d: got %d, %d, %q, %v, want %d, %d, %q, %v", tt.ln, st, ed, pfx, ok, tt.st, tt.ed, tt.pfx, tt.ok)
+		}
+	}
+}
+
+func TestReflowComment(t *testing.T) {
+	in := []string{
+		"\t// ReflowComment returns the lines",
+		"\t// rewrapped to fit.",
+		"\t//",
+		"\t// - a list item that is long enough to wrap around",
+		"\t// - another",
+		"\t//",
+		"\t//\tcode := example()",
+		"\t// after the code, a paragraph that is also rather long",
+	}
+	want := []string{
+		"\t// ReflowComment returns the lines rewrapped",
+		"\t// to fit.",
+		"\t//",
+		"\t// - a list item that is long enough to wrap",
+		"\t//   around",
+		"\t// - another",
+		"\t//",
+		"\t//\tcode := example()",
+		"\t// after the code, a paragraph that is also",
+		"\t// rather long",
+	}
+	got := ReflowComment(in, "\t// ", 48, 4)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReflowComment:\n%v\nwant:\n%v", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if again := ReflowComment(got, "\t// ", 48, 4); !reflect.DeepEqual(again, want) {
+		t.Errorf("ReflowComment is not stable:\n%v", strings.Join(again, "\n"))
+	}
+}
+
+func TestDocStub(t *testing.T) {
+	tests := []struct {
+		line, marker string
+		stub         []string
+		after        bool
+		col          int
+		ok           bool
+	}{
+		{"func (ge *Gide) Foo(x int) {", "// ", []string{"// Foo "}, false, 7, true},
+		{"\ttype Bar[T any] struct {", "// ", []string{"\t// Bar "}, false, 8, true},
+		{"export async function load(url) {", "// ", []string{"// load "}, false, 8, true},
+		{"    def run(self, n, *args, key=None):", "# ", []string{`        """run `, "", "        Args:", "            n: ", "            args: ", "            key: ", `        """`}, true, 15, true},
+		{"class Thing:", "# ", []string{`    """Thing `, `    """`}, true, 13, true},
+		{"x := 1", "// ", nil, false, 0, false},
+	}
+	for _, tt := range tests {
+		stub, after, col, ok := DocStub(tt.line, tt.marker, "    ")
+		if !reflect.DeepEqual(stub, tt.stub) || after != tt.after || col != tt.col || ok != tt.ok {
+			t.Errorf("DocStub(%q): got %q, %v, %d, %v, want %q, %v, %d, %v", tt.line, stub, after, col, ok, tt.stub, tt.after, tt.col, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// RefItem is one reference to a symbol
+type RefItem struct {
+	Reg  giv.TextRegion `desc:"region of the reference within the file"`
+	Text string         `desc:"text of the line containing the reference, for context"`
+}
+
+// RefFileResults are the references found in one file
+type RefFileResults struct {
+	Path    string    `desc:"full path to the file"`
+	RelPath string    `desc:"path relative to the project root, for display"`
+	Items   []RefItem `desc:"references in the file, in line order"`
+}
+
+// WordAtPos returns the identifier-like word in given line that contains (or
+// ends at) given char position, along with its start and end positions
+func WordAtPos(line []rune, ch int) (string, int, int) {
+	if ch > len(line) {
+		ch = len(line)
+	}
+	st := ch
+	for st > 0 && completeIsWordRune(line[st-1]) {
+		st--
+	}
+	ed := ch
+	for ed < len(line) && completeIsWordRune(line[ed]) {
+		ed++
+	}
+	return string(line[st:ed]), st, ed
+}
+
+// refFileLines returns the lines of given file, caching them in given map
+func refFileLines(fpath string, cache map[string][][]rune) [][]rune {
+	if lns, ok := cache[fpath]; ok {
+		return lns
+	}
+	var lns [][]rune
+	b, err := ioutil.ReadFile(fpath)
+	if err == nil {
+		for _, l := range bytes.Split(b, []byte("\n")) {
+			lns = append(lns, bytes.Runes(l))
+		}
+	}
+	cache[fpath] = lns
+	return lns
+}
+
+// refRelPath returns the path relative to root if within it
+func refRelPath(root, fpath string) string {
+	if rp, err := filepath.Rel(root, fpath); err == nil && !strings.HasPrefix(rp, "..") {
+		return rp
+	}
+	return fpath
+}
+
+// RefsFromLocations groups the given LSP locations by file, sorted by path
+// and then position, with the text of each line for context
+func RefsFromLocations(locs []LspLocation, root string) []RefFileResults {
+	cache := make(map[string][][]rune)
+	byfile := make(map[string]*RefFileResults)
+	for _, lc := range locs {
+		fp := LspPath(lc.URI)
+		fr, ok := byfile[fp]
+		if !ok {
+			fr = &RefFileResults{Path: fp, RelPath: refRelPath(root, fp)}
+			byfile[fp] = fr
+		}
+		reg := giv.TextRegion{Start: giv.TextPos{Ln: lc.Range.Start.Line, Ch: lc.Range.Start.Character}, End: giv.TextPos{Ln: lc.Range.End.Line, Ch: lc.Range.End.Character}}
+		txt := ""
+		if lns := refFileLines(fp, cache); reg.Start.Ln < len(lns) {
+			txt = strings.TrimSpace(string(lns[reg.Start.Ln]))
+		}
+		fr.Items = append(fr.Items, RefItem{Reg: reg, Text: txt})
+	}
+	return refsSorted(byfile)
+}
+
+// RefsFromSearch returns the whole-word matches of given word within the
+// given project search results, grouped by file -- this is the fallback for
+// finding references when there is no language server
+func RefsFromSearch(res []FileSearchResults, word, root string) []RefFileResults {
+	cache := make(map[string][][]rune)
+	byfile := make(map[string]*RefFileResults)
+	wsz := len([]rune(word))
+	for _, fs := range res {
+		fp := string(fs.Node.FPath)
+		lns := refFileLines(fp, cache)
+		for _, mt := range fs.Matches {
+			st := mt.Reg.Start
+			if st.Ln >= len(lns) {
+				continue
+			}
+			lr := lns[st.Ln]
+			if st.Ch > 0 && st.Ch <= len(lr) && completeIsWordRune(lr[st.Ch-1]) {
+				continue
+			}
+			if ed := st.Ch + wsz; ed < len(lr) && completeIsWordRune(lr[ed]) {
+				continue
+			}
+			fr, ok := byfile[fp]
+			if !ok {
+				fr = &RefFileResults{Path: fp, RelPath: refRelPath(root, fp)}
+				byfile[fp] = fr
+			}
+			reg := giv.TextRegion{Start: st, End: giv.TextPos{Ln: st.Ln, Ch: st.Ch + wsz}}
+			fr.Items = append(fr.Items, RefItem{Reg: reg, Text: strings.TrimSpace(string(lr))})
+		}
+	}
+	return refsSorted(byfile)
+}
+
+func refsSorted(byfile map[string]*RefFileResults) []RefFileResults {
+	res := make([]RefFileResults, 0, len(byfile))
+	for _, fr := range byfile {
+		sort.Slice(fr.Items, func(i, j int) bool {
+			a, b := fr.Items[i].Reg.Start, fr.Items[j].Reg.Start
+			if a.Ln == b.Ln {
+				return a.Ch < b.Ch
+			}
+			return a.Ln < b.Ln
+		})
+		res = append(res, *fr)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].RelPath < res[j].RelPath
+	})
+	return res
+}
+
+// RefsView is a widget that displays all the references to a symbol, grouped
+// by file, in a TextView with links to each one.
+type RefsView struct {
+	gi.Layout
+	Gide    *Gide            `json:"-" xml:"-" desc:"parent gide project"`
+	Symbol  string           `json:"-" xml:"-" desc:"symbol that references are shown for"`
+	Results []RefFileResults `json:"-" xml:"-" desc:"current results, by file"`
+}
+
+var KiT_RefsView = kit.Types.AddType(&RefsView{}, RefsViewProps)
+
+// NItems returns the total number of references in the current results
+func (rv *RefsView) NItems() int {
+	n := 0
+	for _, fr := range rv.Results {
+		n += len(fr.Items)
+	}
+	return n
+}
+
+// ShowResults renders the current results into the results buffer
+func (rv *RefsView) ShowResults() {
+	tbuf, _ := rv.Gide.FindOrMakeCmdBuf("Refs", true)
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	for _, fr := range rv.Results {
+		lstr := fmt.Sprintf(`%v: %v`, fr.RelPath, len(fr.Items))
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte(fmt.Sprintf(`<b>%v</b>`, lstr)))
+		for _, it := range fr.Items {
+			ln := it.Reg.Start.Ln + 1
+			ch := it.Reg.Start.Ch + 1
+			ech := it.Reg.End.Ch + 1
+			fnstr := fmt.Sprintf("%v:%d:%d", fr.RelPath, ln, ch)
+			txt := html.EscapeString(it.Text)
+			lstr = fmt.Sprintf(`	%v: %s`, fnstr, txt)
+			outlns = append(outlns, []byte(lstr))
+			mstr := fmt.Sprintf(`	<a href="ref:///%v#L%vC%v-L%vC%v">%v</a>: %s`, fr.Path, ln, ch, ln, ech, fnstr, txt)
+			outmus = append(outmus, []byte(mstr))
+		}
+		outlns = append(outlns, []byte(""))
+		outmus = append(outmus, []byte(""))
+	}
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	tbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+	lbl := rv.SymbolLabel()
+	lbl.SetText(fmt.Sprintf("References to <b>%v</b>: %d in %d files", html.EscapeString(rv.Symbol), rv.NItems(), len(rv.Results)))
+}
+
+// NextRef shows next reference
+func (rv *RefsView) NextRef() {
+	tv := rv.TextView()
+	ok := tv.CursorNextLink(true) // wrap
+	if ok {
+		tv.OpenLinkAt(tv.CursorPos)
+	}
+}
+
+// PrevRef shows previous reference
+func (rv *RefsView) PrevRef() {
+	tv := rv.TextView()
+	ok := tv.CursorPrevLink(true) // wrap
+	if ok {
+		tv.OpenLinkAt(tv.CursorPos)
+	}
+}
+
+// OpenRefURL opens given ref:/// url from the references list
+func (rv *RefsView) OpenRefURL(ur string, rtv *giv.TextView) bool {
+	ge := rv.Gide
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("RefsView OpenRefURL parse err: %v\n", err)
+		return false
+	}
+	fpath := up.Path[1:] // has double //
+	pos := up.Fragment
+	etv, _, ok := ge.LinkViewFile(gi.FileName(fpath))
+	if !ok {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Open File at Link", Prompt: fmt.Sprintf("Could not find or open file path in project: %v", fpath)}, true, false, nil, nil)
+		return false
+	}
+	if pos == "" {
+		return true
+	}
+	reg := giv.TextRegion{}
+	if reg.FromString(pos) {
+		etv.HighlightRegion(reg)
+		etv.SetCursorShow(reg.Start)
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (rv *RefsView) UpdateView(ge *Gide) {
+	rv.Gide = ge
+	mods, updt := rv.StdRefsConfig()
+	rv.ConfigToolbar()
+	tvly := rv.TextViewLay()
+	rv.Gide.ConfigOutputTextView(tvly)
+	if mods {
+		rv.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (rv *RefsView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "refsbar")
+	config.Add(gi.KiT_Layout, "refstext")
+	return config
+}
+
+// StdRefsConfig configures a standard setup of the overall layout -- returns
+// mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (rv *RefsView) StdRefsConfig() (mods, updt bool) {
+	rv.Lay = gi.LayoutVert
+	rv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := rv.StdConfig()
+	mods, updt = rv.ConfigChildren(config, false)
+	return
+}
+
+// RefsBar returns the refs toolbar
+func (rv *RefsView) RefsBar() *gi.ToolBar {
+	tbi, ok := rv.ChildByName("refsbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// SymbolLabel returns the label showing the symbol in the toolbar
+func (rv *RefsView) SymbolLabel() *gi.Label {
+	tb := rv.RefsBar()
+	if tb == nil {
+		return nil
+	}
+	lbi, ok := tb.ChildByName("symbol", 0)
+	if !ok {
+		return nil
+	}
+	return lbi.(*gi.Label)
+}
+
+// TextViewLay returns the refs results TextView layout
+func (rv *RefsView) TextViewLay() *gi.Layout {
+	tvi, ok := rv.ChildByName("refstext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the refs results TextView
+func (rv *RefsView) TextView() *giv.TextView {
+	tvly := rv.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (rv *RefsView) ConfigToolbar() {
+	tb := rv.RefsBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	lbl := tb.AddNewChild(gi.KiT_Label, "symbol").(*gi.Label)
+	lbl.SetStretchMaxWidth()
+
+	next := tb.AddNewChild(gi.KiT_Action, "next").(*gi.Action)
+	next.SetIcon("widget-wedge-down")
+	next.Tooltip = "go to next reference"
+	next.ActionSig.Connect(rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		rvv, _ := recv.Embed(KiT_RefsView).(*RefsView)
+		rvv.NextRef()
+	})
+
+	prev := tb.AddNewChild(gi.KiT_Action, "prev").(*gi.Action)
+	prev.SetIcon("widget-wedge-up")
+	prev.Tooltip = "go to previous reference"
+	prev.ActionSig.Connect(rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		rvv, _ := recv.Embed(KiT_RefsView).(*RefsView)
+		rvv.PrevRef()
+	})
+}
+
+var RefsViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
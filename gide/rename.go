@@ -0,0 +1,159 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+)
+
+// GoRename runs gorename to rename the identifier at given byte offset in
+// given file to newName -- if preview is true, nothing is changed and the
+// diff of the changes is returned, otherwise the files are rewritten
+func GoRename(dir, fpath string, offset int, newName string, preview bool) ([]byte, error) {
+	args := []string{"-offset", fmt.Sprintf("%v:#%d", fpath, offset), "-to", newName}
+	if preview {
+		args = append(args, "-d")
+	}
+	cmd := exec.Command("gorename", args...)
+	cmd.Dir = dir
+	CmdBuildEnv.SetCmdEnv(cmd)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("gorename: %v %s", err, out)
+	}
+	return out, nil
+}
+
+// Rename renames the identifier at the cursor in the active view across the
+// whole project: prompts for the new name, then shows a preview of all the
+// changes, which are applied and saved if accepted -- uses the language
+// server if available, or gorename for Go files
+func (ge *Gide) Rename() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.CursorPos.Ln >= len(tv.Buf.Lines) {
+		return
+	}
+	word, _, _ := WordAtPos(tv.Buf.Lines[tv.CursorPos.Ln], tv.CursorPos.Ch)
+	if word == "" {
+		ge.SetStatus("No symbol at cursor to rename")
+		return
+	}
+	gi.StringPromptDialog(ge.Viewport, word, "new name",
+		gi.DlgOpts{Title: "Rename Symbol", Prompt: fmt.Sprintf("Rename <b>%v</b> everywhere in the project to:", word)},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dlg := send.(*gi.Dialog)
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			nm := gi.StringPromptDialogValue(dlg)
+			if nm == "" || nm == word {
+				return
+			}
+			ge.RenameTo(nm)
+		})
+}
+
+// RenameTo renames the identifier at the cursor in the active view to given
+// name, after showing a preview of the changes -- see Rename
+func (ge *Gide) RenameTo(newName string) {
+	if lc, tv, fpath := ge.LspClientForActive(); lc != nil {
+		we, err := lc.Rename(fpath, tv.CursorPos.Ln, tv.CursorPos.Ch, newName)
+		if err == nil && we != nil && len(we.Changes) > 0 {
+			ge.RenamePreview(newName, ge.WorkspaceEditDiff(we), func() {
+				ge.OpStart("rename to " + newName)
+				tbs, err := ge.ApplyWorkspaceEdit(we)
+				ge.RenameSave(tbs)
+				ge.OpEnd(true)
+				if err != nil {
+					gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Rename Incomplete", Prompt: err.Error()}, true, false, nil, nil)
+				}
+			})
+			return
+		}
+		if err != nil {
+			ge.SetStatus(fmt.Sprintf("Language server could not rename: %v", err))
+		}
+	}
+	tv := ge.ActiveTextView()
+	fpath := string(tv.Buf.Filename)
+	if filepath.Ext(fpath) != ".go" {
+		ge.SetStatus("Rename needs a language server for this file -- see LangServers in Preferences")
+		return
+	}
+	ge.SaveAllOpenNodes() // gorename works on the files on disk
+	off := TextPosByteOffset(tv.Buf.Lines, tv.CursorPos)
+	root := string(ge.ProjRoot)
+	dif, err := GoRename(root, fpath, off, newName, true)
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Rename Failed", Prompt: err.Error()}, true, false, nil, nil)
+		return
+	}
+	ge.RenamePreview(newName, dif, func() {
+		fps := DiffFilePaths(dif)
+		sizes := make([]int64, len(fps))
+		ge.OpStart("rename to " + newName)
+		for i, fp := range fps {
+			sizes[i] = fileSize(fp)
+			if tb, inProj, err := ge.BufForFile(gi.FileName(fp)); err == nil && inProj {
+				ge.OpTouch(tb) // opened, so it is reverted to the renamed version below
+			}
+		}
+		if _, err := GoRename(root, fpath, off, newName, false); err != nil {
+			ge.OpEnd(true) // nothing changed
+			gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Rename Failed", Prompt: err.Error()}, true, false, nil, nil)
+			return
+		}
+		for i, fp := range fps {
+			ge.AuditWrite("gorename", fp, sizes[i], "rename to "+newName)
+		}
+		for _, ond := range ge.OpenNodes {
+			ond.Buf.Revert()
+			ge.AuditOpen(ond.Buf)
+		}
+		ge.OpEnd(true)
+		ge.SetStatus("Renamed to: " + newName)
+	})
+}
+
+// RenameSave saves the given buffers after a rename
+func (ge *Gide) RenameSave(tbs []*giv.TextBuf) {
+	for _, tb := range tbs {
+		ge.AuditNote(tb, "rename")
+		tb.Save()
+		ge.BufSaved(tb)
+	}
+	ge.SetStatus(fmt.Sprintf("Rename changed %d files", len(tbs)))
+}
+
+// RenamePreview shows the given diff of the changes for a rename, calling
+// apply if accepted
+func (ge *Gide) RenamePreview(newName string, dif []byte, apply func()) {
+	dlg := gi.NewStdDialog(gi.DlgOpts{Title: "Rename Preview", Prompt: fmt.Sprintf("These changes will be made to rename to <b>%v</b> -- all changed files will be saved", newName)}, true, true)
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	ly := frame.InsertNewChild(gi.KiT_Layout, prIdx+1, "diff-lay").(*gi.Layout)
+	ly.SetProp("width", units.NewValue(80, units.Ch))
+	ly.SetProp("height", units.NewValue(30, units.Em))
+	dtv := ge.ConfigOutputTextView(ly)
+	dtv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+	dtb := &giv.TextBuf{}
+	dtb.InitName(dtb, "rename-diff")
+	dtb.SetText(dif)
+	dtv.SetBuf(dtb)
+	dlg.DialogSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.DialogAccepted) {
+			apply()
+		}
+	})
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, ge.Viewport, nil)
+}
@@ -0,0 +1,638 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// ReplaceHunk is one line changed by a replace in the project, or the lines
+// of matches that span lines, which can be turned on or off before the
+// replace is applied
+type ReplaceHunk struct {
+	Ln  int    `desc:"line number, 0-based -- the first line, for matches that span lines"`
+	Old string `desc:"the line before the replace -- the lines, with newlines, for matches that span lines"`
+	New string `desc:"the line after it -- or lines, which can be fewer or more than before"`
+	On  bool   `desc:"apply this change"`
+}
+
+// ReplaceFile is the changes that a replace in the project makes to one file
+type ReplaceFile struct {
+	Path     string        `desc:"full path of the file"`
+	RelPath  string        `desc:"path of the file relative to the project root"`
+	Lines    []string      `desc:"lines of the file when the changes were previewed -- they are not applied if it has changed since"`
+	LineEnds LineEnds      `desc:"line endings of the file on disk, which it is written with"`
+	Hunks    []ReplaceHunk `desc:"the changed lines"`
+}
+
+// NOn returns the number of changes that are on
+func (rf *ReplaceFile) NOn() int {
+	n := 0
+	for _, h := range rf.Hunks {
+		if h.On {
+			n++
+		}
+	}
+	return n
+}
+
+// ReplaceFunc returns the replacement for the match at given positions in
+// a line, as returned by SearchMatcher.FindLine -- or in the whole text, as
+// returned by TextFinder.FindText
+type ReplaceFunc func(line []byte, mi []int) []byte
+
+// NewReplacer returns the function for the replacement of the matches of m
+// with given replace string -- if m has a regexp, $1, ${1}, $name and
+// ${name} in it are expanded to the text of the groups of the match, and
+// for a StructMatcher, $X to what wildcard X matched -- and if
+// preserveCase, the replacement is changed to the case of the match, as in
+// PreserveCase
+func NewReplacer(m SearchMatcher, repl string, preserveCase bool) ReplaceFunc {
+	re := SearchRegexp(m)
+	sm, _ := m.(*StructMatcher)
+	rb := []byte(repl)
+	return func(line []byte, mi []int) []byte {
+		r := rb
+		switch {
+		case sm != nil:
+			r = sm.Expand(rb, line, mi)
+		case re != nil:
+			r = re.Expand(nil, rb, line, mi)
+		}
+		if preserveCase {
+			r = []byte(PreserveCase(string(line[mi[0]:mi[1]]), string(r)))
+		}
+		return r
+	}
+}
+
+// PreserveCase returns the replacement repl in the case of the text match
+// that it replaces: all upper case if that is, e.g., FOO -> BAR, all lower
+// case if that is, foo -> bar, and with an upper case first letter if that
+// has one, Foo -> Bar -- otherwise, e.g., for fOo, repl is as it is
+func PreserveCase(match, repl string) string {
+	nup, nlow := 0, 0
+	for _, r := range match {
+		switch {
+		case unicode.IsUpper(r):
+			nup++
+		case unicode.IsLower(r):
+			nlow++
+		}
+	}
+	first, _ := utf8.DecodeRuneInString(match)
+	switch {
+	case nup > 1 && nlow == 0:
+		return strings.ToUpper(repl)
+	case nlow > 0 && nup == 0:
+		return strings.ToLower(repl)
+	case unicode.IsUpper(first) && nup == 1:
+		rr, sz := utf8.DecodeRuneInString(repl)
+		return string(unicode.ToUpper(rr)) + repl[sz:]
+	}
+	return repl
+}
+
+// ReplaceLines returns the changes that replacing each match of m in given
+// lines with repl of it makes, a hunk for each changed line, all on -- for
+// a TextFinder, a hunk has all the lines of the matches that span them
+func ReplaceLines(lines []string, m SearchMatcher, repl ReplaceFunc) []ReplaceHunk {
+	if tf, ok := m.(TextFinder); ok {
+		return replaceText(lines, tf, repl)
+	}
+	var hs []ReplaceHunk
+	for ln, l := range lines {
+		lb := []byte(l)
+		ms := m.FindLine(lb)
+		if len(ms) == 0 {
+			continue
+		}
+		var nb bytes.Buffer
+		st := 0
+		for _, mi := range ms {
+			nb.Write(lb[st:mi[0]])
+			nb.Write(repl(lb, mi))
+			st = mi[1]
+		}
+		nb.Write(lb[st:])
+		if nl := nb.String(); nl != l {
+			hs = append(hs, ReplaceHunk{Ln: ln, Old: l, New: nl, On: true})
+		}
+	}
+	return hs
+}
+
+// replaceText returns the changes that replacing each match of tf in the
+// text of given lines makes, a hunk for the lines of each match, or of
+// matches on the same lines
+func replaceText(lines []string, tf TextFinder, repl ReplaceFunc) []ReplaceHunk {
+	txt := []byte(strings.Join(lines, "\n"))
+	lst := make([]int, len(lines)) // where each line starts
+	for i, of := 1, 0; i < len(lines); i++ {
+		of += len(lines[i-1]) + 1
+		lst[i] = of
+	}
+	lineOf := func(pos int) int {
+		return sort.Search(len(lst), func(i int) bool { return lst[i] > pos }) - 1
+	}
+	var hs []ReplaceHunk
+	var nb bytes.Buffer
+	hst, hed := -1, -1 // lines of the hunk so far
+	at := 0            // text before this is in nb
+	flush := func() {
+		if hst < 0 {
+			return
+		}
+		ed := len(txt)
+		if hed+1 < len(lst) {
+			ed = lst[hed+1] - 1
+		}
+		nb.Write(txt[at:ed])
+		old := string(txt[lst[hst]:ed])
+		if nw := nb.String(); nw != old {
+			hs = append(hs, ReplaceHunk{Ln: hst, Old: old, New: nw, On: true})
+		}
+		nb.Reset()
+		hst = -1
+	}
+	for _, mi := range tf.FindText(txt) {
+		sl, el := lineOf(mi[0]), lineOf(mi[1]-1)
+		if hst >= 0 && sl > hed {
+			flush()
+		}
+		if hst < 0 {
+			hst, hed = sl, el
+			at = lst[sl]
+		} else if el > hed {
+			hed = el
+		}
+		nb.Write(txt[at:mi[0]])
+		nb.Write(repl(txt, mi))
+		at = mi[1]
+	}
+	flush()
+	return hs
+}
+
+// ReplaceEdits returns the line edits that make the changes that are on
+func ReplaceEdits(hunks []ReplaceHunk) []LineEdit {
+	var eds []LineEdit
+	for _, h := range hunks {
+		if h.On {
+			eds = append(eds, LineEdit{St: h.Ln, Ed: h.Ln + strings.Count(h.Old, "\n") + 1, Lines: strings.Split(h.New, "\n")})
+		}
+	}
+	return eds
+}
+
+// ReplaceFileLines returns the lines of the file at given path, with LF
+// line endings, and the line endings it has
+func ReplaceFileLines(path string) ([]string, LineEnds, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, LineEndsLF, err
+	}
+	le, _ := DetectLineEnds(src)
+	return strings.Split(string(ConvertLineEnds(src, LineEndsLF)), "\n"), le, nil
+}
+
+// ReplaceFileFor returns the changes that replacing each match of m with
+// repl of it makes to the file at given path -- or its buffer, if it is
+// open -- for a StructMatcher, an error if the file would not parse after
+// them
+func (ge *Gide) ReplaceFileFor(path, relPath string, m SearchMatcher, repl ReplaceFunc) (*ReplaceFile, error) {
+	rf := &ReplaceFile{Path: path, RelPath: relPath}
+	if ond, ok := ge.OpenNodeByPath(path); ok && ond.Buf != nil {
+		rf.Lines = bufStrings(ond.Buf)
+	} else {
+		var err error
+		if rf.Lines, rf.LineEnds, err = ReplaceFileLines(path); err != nil {
+			return nil, err
+		}
+	}
+	rf.Hunks = ReplaceLines(rf.Lines, m, repl)
+	if _, ok := m.(*StructMatcher); ok && len(rf.Hunks) > 0 {
+		if err := StructCheck(path, ApplyLineEdits(rf.Lines, ReplaceEdits(rf.Hunks))); err != nil {
+			return nil, fmt.Errorf("%v would not parse after the replace: %v", relPath, err)
+		}
+	}
+	return rf, nil
+}
+
+// ReplacePreview shows the changes that replacing the matches listed in the
+// Find panel with the replace string makes, in the Replace panel, as a diff
+// of each changed line, where each one can be turned off before they are
+// applied
+func (ge *Gide) ReplacePreview() {
+	fvi, _, ok := ge.MainTabByName("Find")
+	if !ok {
+		ge.SetStatus("Find something first, then preview the replace of what was found")
+		return
+	}
+	fv := fvi.Embed(KiT_FindView).(*FindView)
+	fp := fv.Params()
+	m, rfn, err := fv.Replacer()
+	if err != nil {
+		ge.SetStatus(fmt.Sprintf("Replace: %v", err))
+		return
+	}
+	fv.Mu.Lock()
+	res := append([]ProjSearchResults(nil), fv.Results...)
+	fv.Mu.Unlock()
+	var rfs []*ReplaceFile
+	var bad []string
+	for _, r := range res {
+		rf, err := ge.ReplaceFileFor(r.Path, r.RelPath, m, rfn)
+		if err != nil {
+			bad = append(bad, err.Error())
+			continue
+		}
+		if len(rf.Hunks) > 0 {
+			rfs = append(rfs, rf)
+		}
+	}
+	tbuf, _ := ge.FindOrMakeCmdBuf("Replace", true)
+	rvi, _ := ge.FindOrMakeMainTab("Replace", KiT_ReplaceView, true) // sel
+	rv := rvi.Embed(KiT_ReplaceView).(*ReplaceView)
+	rv.UpdateView(ge)
+	rtv := rv.TextView()
+	rtv.SetInactive()
+	rtv.SetBuf(tbuf)
+	rv.Name = fmt.Sprintf("replace %q with %q", fp.Find, fp.Replace)
+	rv.Files = rfs
+	rv.ShowResults()
+	if len(bad) > 0 {
+		ge.SetStatus(fmt.Sprintf("Could not replace in %d files: %v", len(bad), bad[0]))
+	}
+	ge.FocusOnPanel(MainTabsIdx)
+}
+
+// replaceApply is a file to be changed by ReplaceApply
+type replaceApply struct {
+	rf    *ReplaceFile
+	tb    *giv.TextBuf
+	after []string
+	tmp   string
+	size  int64
+}
+
+// ReplaceApply makes the changes that are on in given files, as an
+// operation of given name that can be undone as a unit -- open files are
+// changed in their buffers, and the others are written to disk -- nothing
+// is changed if any of the files has changed since the changes were
+// previewed, or can not be written, which is returned as the error
+func (ge *Gide) ReplaceApply(name string, rfs []*ReplaceFile) (nch, nfiles int, err error) {
+	var aps []*replaceApply
+	var bad []string
+	for _, rf := range rfs {
+		eds := ReplaceEdits(rf.Hunks)
+		if len(eds) == 0 {
+			continue
+		}
+		ap := &replaceApply{rf: rf, after: ApplyLineEdits(rf.Lines, eds)}
+		aps = append(aps, ap)
+		if ond, ok := ge.OpenNodeByPath(rf.Path); ok && ond.Buf != nil {
+			ap.tb = ond.Buf
+			if OpLinesHash(bufStrings(ap.tb)) != OpLinesHash(rf.Lines) {
+				bad = append(bad, rf.RelPath+": changed since the preview")
+			}
+			continue
+		}
+		lines, _, err := ReplaceFileLines(rf.Path)
+		if err != nil || OpLinesHash(lines) != OpLinesHash(rf.Lines) {
+			bad = append(bad, rf.RelPath+": changed since the preview")
+			continue
+		}
+		if ap.tmp, err = replaceWriteTemp(rf.Path, ConvertLineEnds([]byte(strings.Join(ap.after, "\n")), rf.LineEnds)); err != nil {
+			bad = append(bad, fmt.Sprintf("%v: %v", rf.RelPath, err))
+		}
+		ap.size = fileSize(rf.Path)
+	}
+	if len(bad) > 0 {
+		for _, ap := range aps {
+			if ap.tmp != "" {
+				os.Remove(ap.tmp)
+			}
+		}
+		return 0, 0, fmt.Errorf("nothing was changed, as some of the files can not be:\n%v", strings.Join(bad, "\n"))
+	}
+	ge.OpStart(name)
+	for _, ap := range aps {
+		if ap.tb != nil {
+			ge.OpTouch(ap.tb)
+			opApply(ap.tb, ReplaceEdits(ap.rf.Hunks))
+			ge.AuditNote(ap.tb, "replace")
+		} else {
+			if rerr := os.Rename(ap.tmp, ap.rf.Path); rerr != nil {
+				os.Remove(ap.tmp)
+				bad = append(bad, fmt.Sprintf("%v: %v", ap.rf.RelPath, rerr))
+				continue
+			}
+			ge.OpFile(ap.rf.Path, ap.rf.Lines, ap.after)
+			ge.AuditWrite("replace", ap.rf.Path, ap.size, name)
+		}
+		nch += ap.rf.NOn()
+		nfiles++
+	}
+	ge.OpEnd(false)
+	if len(bad) > 0 {
+		err = fmt.Errorf("some of the files could not be written:\n%v", strings.Join(bad, "\n"))
+	}
+	return
+}
+
+// replaceWriteTemp writes given contents to a new temporary file in the
+// directory of the file at given path, with its permissions, for renaming
+// over it -- returns the temporary file's path
+func replaceWriteTemp(path string, data []byte) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".replace")
+	if err != nil {
+		return "", err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, fi.Mode())
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// OpenReplaceURL toggles or views the change in given replace:/// url from
+// the Replace panel -- delegates to ReplaceView
+func (ge *Gide) OpenReplaceURL(ur string, rtv *giv.TextView) bool {
+	rvk, ok := rtv.ParentByType(KiT_ReplaceView, true)
+	if !ok {
+		return false
+	}
+	rv := rvk.(*ReplaceView)
+	return rv.OpenReplaceURL(ur, rtv)
+}
+
+// ReplaceView is a widget that previews the changes of a replace in the
+// project, as a diff of each changed line with a link to turn it on or off,
+// and applies those that are on
+type ReplaceView struct {
+	gi.Layout
+	Gide  *Gide          `json:"-" xml:"-" desc:"parent gide project"`
+	Name  string         `json:"-" xml:"-" desc:"name of the replace, for the operations history"`
+	Files []*ReplaceFile `json:"-" xml:"-" desc:"the changes to each file"`
+}
+
+var KiT_ReplaceView = kit.Types.AddType(&ReplaceView{}, ReplaceViewProps)
+
+// ShowResults renders the changes into the results buffer
+func (rv *ReplaceView) ShowResults() {
+	tbuf, _ := rv.Gide.FindOrMakeCmdBuf("Replace", true)
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	nh, non := 0, 0
+	for _, rf := range rv.Files {
+		nh += len(rf.Hunks)
+		non += rf.NOn()
+	}
+	lstr := fmt.Sprintf("%v: %d changes in %d files, %d to apply", rv.Name, nh, len(rv.Files), non)
+	if len(rv.Files) == 0 {
+		lstr = rv.Name + ": nothing to change -- find something first"
+	}
+	outlns = append(outlns, []byte(lstr))
+	outmus = append(outmus, []byte(fmt.Sprintf(`<b>%v</b>`, html.EscapeString(lstr))))
+	chk := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	for fi, rf := range rv.Files {
+		non := rf.NOn()
+		fchk := chk(non == len(rf.Hunks))
+		lstr = fmt.Sprintf("%v %v: %d of %d changes", fchk, rf.RelPath, non, len(rf.Hunks))
+		outlns = append(outlns, []byte(""), []byte(lstr))
+		outmus = append(outmus, []byte(""), []byte(fmt.Sprintf(`<a href="replace:///file?f=%d">%v</a> <b>%v</b>: %d of %d changes`, fi, fchk, html.EscapeString(rf.RelPath), non, len(rf.Hunks))))
+		for hi, h := range rf.Hunks {
+			hchk := chk(h.On)
+			lnstr := fmt.Sprintf("%d", h.Ln+1)
+			pad := strings.Repeat(" ", len(lnstr))
+			olds := strings.Split(h.Old, "\n")
+			lstr = fmt.Sprintf("	%v %v: - %v", hchk, lnstr, olds[0])
+			outlns = append(outlns, []byte(lstr))
+			outmus = append(outmus, []byte(fmt.Sprintf(`	<a href="replace:///hunk?f=%d&h=%d">%v</a> <a href="replace:///view?f=%d&h=%d">%v</a>: - %v`, fi, hi, hchk, fi, hi, lnstr, html.EscapeString(olds[0]))))
+			for _, ol := range olds[1:] {
+				lstr = fmt.Sprintf("	    %v  - %v", pad, ol)
+				outlns = append(outlns, []byte(lstr))
+				outmus = append(outmus, []byte(html.EscapeString(lstr)))
+			}
+			for _, nl := range strings.Split(h.New, "\n") {
+				lstr = fmt.Sprintf("	    %v  + %v", pad, nl)
+				outlns = append(outlns, []byte(lstr))
+				outmus = append(outmus, []byte(html.EscapeString(lstr)))
+			}
+		}
+	}
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	tbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+}
+
+// OpenReplaceURL toggles a change, or all of the changes in a file, or
+// views a change, from given replace:/// url
+func (rv *ReplaceView) OpenReplaceURL(ur string, rtv *giv.TextView) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("ReplaceView OpenReplaceURL parse err: %v\n", err)
+		return false
+	}
+	q := up.Query()
+	fi, err := strconv.Atoi(q.Get("f"))
+	if err != nil || fi < 0 || fi >= len(rv.Files) {
+		return false
+	}
+	rf := rv.Files[fi]
+	hi, _ := strconv.Atoi(q.Get("h"))
+	if hi < 0 || hi >= len(rf.Hunks) {
+		return false
+	}
+	switch up.Path[1:] { // has double //
+	case "file":
+		on := rf.NOn() < len(rf.Hunks)
+		for i := range rf.Hunks {
+			rf.Hunks[i].On = on
+		}
+		rv.ShowResults()
+	case "hunk":
+		rf.Hunks[hi].On = !rf.Hunks[hi].On
+		rv.ShowResults()
+	case "view":
+		tv, _, ok := rv.Gide.LinkViewFile(gi.FileName(rf.Path))
+		if !ok {
+			return false
+		}
+		tv.SetCursorShow(giv.TextPos{Ln: rf.Hunks[hi].Ln})
+	default:
+		return false
+	}
+	return true
+}
+
+// SelectAll turns all of the changes on, or all off if they are all on
+func (rv *ReplaceView) SelectAll() {
+	all := true
+	for _, rf := range rv.Files {
+		if rf.NOn() < len(rf.Hunks) {
+			all = false
+			break
+		}
+	}
+	for _, rf := range rv.Files {
+		for i := range rf.Hunks {
+			rf.Hunks[i].On = !all
+		}
+	}
+	rv.ShowResults()
+}
+
+// Apply makes the changes that are on, which can be undone with Undo
+// Operation
+func (rv *ReplaceView) Apply() {
+	ge := rv.Gide
+	nch, nf, err := ge.ReplaceApply(rv.Name, rv.Files)
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Replace Failed", Prompt: html.EscapeString(err.Error())}, true, false, nil, nil)
+		if nf == 0 {
+			return
+		}
+	}
+	rv.Files = nil
+	rv.ShowResults()
+	ge.SetStatus(fmt.Sprintf("Replaced %d in %d files -- Undo Operation undoes it", nch, nf))
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (rv *ReplaceView) UpdateView(ge *Gide) {
+	rv.Gide = ge
+	mods, updt := rv.StdReplaceConfig()
+	rv.ConfigToolbar()
+	tvly := rv.TextViewLay()
+	rv.Gide.ConfigOutputTextView(tvly)
+	if mods {
+		rv.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (rv *ReplaceView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "replacebar")
+	config.Add(gi.KiT_Layout, "replacetext")
+	return config
+}
+
+// StdReplaceConfig configures a standard setup of the overall layout --
+// returns mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (rv *ReplaceView) StdReplaceConfig() (mods, updt bool) {
+	rv.Lay = gi.LayoutVert
+	rv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := rv.StdConfig()
+	mods, updt = rv.ConfigChildren(config, false)
+	return
+}
+
+// ReplaceBar returns the replace toolbar
+func (rv *ReplaceView) ReplaceBar() *gi.ToolBar {
+	tbi, ok := rv.ChildByName("replacebar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// TextViewLay returns the replace preview TextView layout
+func (rv *ReplaceView) TextViewLay() *gi.Layout {
+	tvi, ok := rv.ChildByName("replacetext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the replace preview TextView
+func (rv *ReplaceView) TextView() *giv.TextView {
+	tvly := rv.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (rv *ReplaceView) ConfigToolbar() {
+	tb := rv.ReplaceBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	apply := tb.AddNewChild(gi.KiT_Action, "apply").(*gi.Action)
+	apply.SetText("Apply")
+	apply.Tooltip = "make the changes that are checked: open files are changed in their buffers, and the others on disk, all or none of them -- Undo Operation undoes them all"
+	apply.ActionSig.Connect(rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		rvv, _ := recv.Embed(KiT_ReplaceView).(*ReplaceView)
+		rvv.Apply()
+	})
+
+	all := tb.AddNewChild(gi.KiT_Action, "select-all").(*gi.Action)
+	all.SetText("Select All")
+	all.Tooltip = "check all of the changes, or none if they are all checked"
+	all.ActionSig.Connect(rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		rvv, _ := recv.Embed(KiT_ReplaceView).(*ReplaceView)
+		rvv.SelectAll()
+	})
+
+	refresh := tb.AddNewChild(gi.KiT_Action, "refresh").(*gi.Action)
+	refresh.SetText("Refresh")
+	refresh.Tooltip = "preview the replace again, of the current results in the Find panel, with the current replace string"
+	refresh.ActionSig.Connect(rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		rvv, _ := recv.Embed(KiT_ReplaceView).(*ReplaceView)
+		rvv.Gide.ReplacePreview()
+	})
+}
+
+var ReplaceViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReplaceLines(t *testing.T) {
+	lines := []string{"foo := Foo(foo)", "bar", "x.foo = 1"}
+	m, _ := NewSearchMatcher("foo", false, false)
+	hs := ReplaceLines(lines, m, func(line []byte, mi []int) []byte { return []byte("baz") })
+	want := []ReplaceHunk{
+		{Ln: 0, Old: "foo := Foo(foo)", New: "baz := Foo(baz)", On: true},
+		{Ln: 2, Old: "x.foo = 1", New: "x.baz = 1", On: true},
+	}
+	if !reflect.DeepEqual(hs, want) {
+		t.Fatalf("ReplaceLines = %v, want %v", hs, want)
+	}
+	hs[0].On = false
+	got := ApplyLineEdits(lines, ReplaceEdits(hs))
+	if want := []string{"foo := Foo(foo)", "bar", "x.baz = 1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplyLineEdits = %v, want %v", got, want)
+	}
+	rf := &ReplaceFile{Hunks: hs}
+	if rf.NOn() != 1 {
+		t.Errorf("NOn = %d, want 1", rf.NOn())
+	}
+	if hs := ReplaceLines(lines, m, func(line []byte, mi []int) []byte { return line[mi[0]:mi[1]] }); len(hs) != 0 {
+		t.Errorf("ReplaceLines no change: %v", hs)
+	}
+}
+
+func TestPreserveCase(t *testing.T) {
+	tests := []struct{ match, repl, want string }{
+		{"foo", "bar", "bar"},
+		{"Foo", "bar", "Bar"},
+		{"FOO", "bar", "BAR"},
+		{"fooBar", "bazQux", "bazQux"},
+		{"FooBar", "bazQux", "bazQux"},
+		{"F", "bar", "Bar"},
+		{"foo", "Bar", "bar"},
+		{"42", "bar", "bar"},
+		{"Éte", "été", "Été"},
+	}
+	for _, tt := range tests {
+		if got := PreserveCase(tt.match, tt.repl); got != tt.want {
+			t.Errorf("PreserveCase(%q, %q) = %q, want %q", tt.match, tt.repl, got, tt.want)
+		}
+	}
+}
+
+func TestNewReplacer(t *testing.T) {
+	lines := []string{"SetFoo(x, y)", "setFoo(a, b) // SETFOO"}
+	m, err := NewSearchMatcher(`(\w+)Foo\((\w+), (\w+)\)`, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs := ReplaceLines(lines, m, NewReplacer(m, "${1}Bar($3, $2)", false))
+	if len(hs) != 2 || hs[0].New != "SetBar(y, x)" || hs[1].New != "setBar(b, a) // SETFOO" {
+		t.Errorf("regexp: %v", hs)
+	}
+	m, _ = NewSearchMatcher("foo", true, false)
+	hs = ReplaceLines(lines, m, NewReplacer(m, "bar", true))
+	if len(hs) != 2 || hs[0].New != "SetBar(x, y)" || hs[1].New != "setBar(a, b) // SETBAR" {
+		t.Errorf("preserve case: %v", hs)
+	}
+	m, _ = NewSearchMatcher("foo", false, false)
+	if hs = ReplaceLines([]string{"foo"}, m, NewReplacer(m, "$1", false)); len(hs) != 1 || hs[0].New != "$1" {
+		t.Errorf("literal: %v", hs)
+	}
+	m, _ = NewFindMatcher(`(\w+)Foo`, false, true, true, false)
+	if hs = ReplaceLines([]string{"SetFoo SetFooX"}, m, NewReplacer(m, "${1}Bar", false)); len(hs) != 1 || hs[0].New != "SetBar SetFooX" {
+		t.Errorf("whole word regexp: %v", hs)
+	}
+}
+
+func TestReplaceMultiLine(t *testing.T) {
+	lines := []string{"a := 1", "if a {", "\tb()", "}", "c := 2 // if c {", "}"}
+	m, err := NewFindMatcher(`if (\w+) \{\n(.*?)\n?\}`, false, true, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs := ReplaceLines(lines, m, NewReplacer(m, "when $1 {$2}", false))
+	want := []ReplaceHunk{{Ln: 1, Old: "if a {\n\tb()\n}", New: "when a {\tb()}", On: true}, {Ln: 4, Old: "c := 2 // if c {\n}", New: "c := 2 // when c {}", On: true}}
+	if !reflect.DeepEqual(hs, want) {
+		t.Fatalf("ReplaceLines = %+v, want %+v", hs, want)
+	}
+	after := ApplyLineEdits(lines, ReplaceEdits(hs))
+	if wa := []string{"a := 1", "when a {\tb()}", "c := 2 // when c {}"}; !reflect.DeepEqual(after, wa) {
+		t.Errorf("applied = %q, want %q", after, wa)
+	}
+	m, _ = NewFindMatcher(`\}$`, false, true, false, true)
+	hs = ReplaceLines(lines, m, NewReplacer(m, "}\n", false))
+	if len(hs) != 2 || hs[0].Ln != 3 || hs[0].New != "}\n" || hs[1].Ln != 5 {
+		t.Errorf("adding lines: %+v", hs)
+	}
+}
+
+func TestReplaceFileLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-replace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fp := filepath.Join(dir, "a.txt")
+	ioutil.WriteFile(fp, []byte("one\r\ntwo\r\n"), 0600)
+	lines, le, err := ReplaceFileLines(fp)
+	if err != nil || le != LineEndsCRLF || !reflect.DeepEqual(lines, []string{"one", "two", ""}) {
+		t.Fatalf("ReplaceFileLines = %q, %v, %v", lines, le, err)
+	}
+	tmp, err := replaceWriteTemp(fp, ConvertLineEnds([]byte(strings.Join([]string{"one", "2", ""}, "\n")), le))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(tmp); err != nil || fi.Mode().Perm() != 0600 || filepath.Dir(tmp) != dir {
+		t.Errorf("replaceWriteTemp: %v, %v", tmp, err)
+	}
+	os.Rename(tmp, fp)
+	if src, _ := ioutil.ReadFile(fp); string(src) != "one\r\n2\r\n" {
+		t.Errorf("written = %q", src)
+	}
+}
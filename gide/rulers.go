@@ -0,0 +1,81 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"sort"
+
+	"github.com/goki/gi/giv"
+)
+
+// RulerClass is the css class of the text beyond the last ruler, when it is
+// highlighted (see HiLongLines in the editor preferences)
+var RulerClass = "err"
+
+// RulerSpans returns the spans of given line, with tabs of given size, at
+// the columns of given rulers, which are underlined, so the rulers show as
+// vertical lines through the lines that reach them, and, if hiLong, the span
+// of the text beyond the last ruler, with RulerClass -- columns start at 0,
+// so a ruler at 80 is just after the 80th column
+func RulerSpans(line string, rulers []int, tabSize int, hiLong bool) (over, under []HiSpan) {
+	if len(rulers) == 0 {
+		return nil, nil
+	}
+	if tabSize <= 0 {
+		tabSize = 4
+	}
+	last := 0
+	for _, r := range rulers {
+		if r > last {
+			last = r
+		}
+	}
+	col, i := 0, 0 // column, and index in runes
+	for _, r := range line {
+		nc := col + 1
+		if r == '\t' {
+			nc = (col/tabSize + 1) * tabSize
+		}
+		for _, rc := range rulers {
+			if col <= rc && rc < nc {
+				under = append(under, HiSpan{i, i + 1, ""})
+				break
+			}
+		}
+		if hiLong && col >= last {
+			if n := len(over); n > 0 && over[n-1].Ed == i {
+				over[n-1].Ed++
+			} else {
+				over = append(over, HiSpan{i, i + 1, RulerClass})
+			}
+		}
+		col = nc
+		i++
+	}
+	return over, under
+}
+
+// RulersFor returns the columns of the rulers for given buffer: those set
+// for its language, or else in the editor preferences, in order
+func (ge *Gide) RulersFor(tb *giv.TextBuf) []int {
+	rs := ge.Prefs.Editor.Rulers
+	if ls := LangsForFilename(string(tb.Filename)); len(ls) > 0 && len(ls[0].Rulers) > 0 {
+		rs = ls[0].Rulers
+	}
+	var out []int
+	for _, r := range rs {
+		if r > 0 {
+			out = append(out, r)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+// LineDecoFor returns how the lines of given buffer are decorated: with the
+// whitespace shown for it (see WsShowFor), and its rulers (see RulersFor)
+func (ge *Gide) LineDecoFor(tb *giv.TextBuf) LineDeco {
+	return LineDeco{Ws: ge.WsShowFor(tb), Rulers: ge.RulersFor(tb), HiLong: ge.Prefs.Editor.HiLongLines, TabSize: tb.Opts.TabSize}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRulerSpans(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		rulers []int
+		hiLong bool
+		over   []HiSpan
+		under  []HiSpan
+	}{
+		{"none", "0123456789", nil, true, nil, nil},
+		{"short", "0123", []int{5, 8}, true, nil, nil},
+		{"two", "0123456789", []int{5, 8}, false, nil, []HiSpan{{5, 6, ""}, {8, 9, ""}}},
+		{"long", "0123456789", []int{5, 8}, true, []HiSpan{{8, 10, "err"}}, []HiSpan{{5, 6, ""}, {8, 9, ""}}},
+		{"tab", "\tab", []int{2}, true, []HiSpan{{1, 3, "err"}}, []HiSpan{{0, 1, ""}}},
+	}
+	for _, tt := range tests {
+		over, under := RulerSpans(tt.line, tt.rulers, 4, tt.hiLong)
+		if !reflect.DeepEqual(over, tt.over) {
+			t.Errorf("%v: spans %v, want %v", tt.name, over, tt.over)
+		}
+		if !reflect.DeepEqual(under, tt.under) {
+			t.Errorf("%v: underlined %v, want %v", tt.name, under, tt.under)
+		}
+	}
+}
+
+func TestLineDecoDecorate(t *testing.T) {
+	ld := LineDeco{Ws: WsShowWhitespace, Rulers: []int{4}, HiLong: true, TabSize: 4}
+	disp, over, under := ld.Decorate("a b c ")
+	if disp != "a·b·c·" {
+		t.Errorf("got %q", disp)
+	}
+	want := []HiSpan{{1, 2, "c"}, {3, 4, "c"}, {5, 6, "err"}, {4, 6, "err"}}
+	if !reflect.DeepEqual(over, want) {
+		t.Errorf("spans %v, want %v", over, want)
+	}
+	if !reflect.DeepEqual(under, []HiSpan{{4, 5, ""}}) {
+		t.Errorf("underlined %v", under)
+	}
+}
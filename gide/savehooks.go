@@ -0,0 +1,106 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+)
+
+// SaveHooks are the cleanups done to files before they are saved -- set in
+// the editor preferences, globally and for each project, and optionally for
+// each language in Edit Langs
+type SaveHooks struct {
+	Set       bool `desc:"use these save hooks for files of this language, instead of those of the editor preferences"`
+	TrimTrail bool `desc:"strip the trailing whitespace of the lines changed since the file was opened or last saved -- only those, to keep diffs clean"`
+	FinalNL   bool `desc:"end the file with exactly one newline, removing any blank lines at the end"`
+	Format    bool `desc:"format the file with the formatter for its language (see Formatter)"`
+}
+
+// SaveHooks returns the save hooks of the editor preferences
+func (pf *EditorPrefs) SaveHooks() SaveHooks {
+	return SaveHooks{TrimTrail: pf.TrimOnSave, FinalNL: pf.FinalNLOnSave, Format: pf.FormatOnSave}
+}
+
+// SaveHooksFor returns the save hooks for given buffer: those set for its
+// language, or else those of the editor preferences of the project
+func (ge *Gide) SaveHooksFor(tb *giv.TextBuf) SaveHooks {
+	if ls := LangsForFilename(string(tb.Filename)); len(ls) > 0 && ls[0].OnSave.Set {
+		return ls[0].OnSave
+	}
+	return ge.Prefs.Editor.SaveHooks()
+}
+
+// trailTrimLen returns the length of given line, in runes, without its
+// trailing whitespace
+func trailTrimLen(l string) int {
+	return len([]rune(strings.TrimRightFunc(l, unicode.IsSpace)))
+}
+
+// TrimChangedLines returns the indexes of the lines of cur, changed from
+// base, that have trailing whitespace -- all of those in cur if there is no
+// base, e.g., for a new file
+func TrimChangedLines(base []string, hasBase bool, cur []string) []int {
+	same := make([]bool, len(cur))
+	if hasBase {
+		for _, m := range diffMatches(base, cur) {
+			same[m[1]] = true
+		}
+	}
+	var lns []int
+	for i, l := range cur {
+		if !same[i] && trailTrimLen(l) < len([]rune(l)) {
+			lns = append(lns, i)
+		}
+	}
+	return lns
+}
+
+// FinalNLFix returns the last line of given lines that is not blank, and
+// whether the text after it needs to be replaced by a single newline, for
+// the text to end with exactly one -- not for text that is all blank
+func FinalNLFix(lines []string) (int, bool) {
+	k := len(lines) - 1
+	for k >= 0 && strings.TrimSpace(lines[k]) == "" {
+		k--
+	}
+	if k < 0 {
+		return k, false
+	}
+	return k, !(k == len(lines)-2 && lines[k+1] == "")
+}
+
+// SaveCleanup does the trimming and final newline save hooks for given
+// buffer, as undoable edits, before it is saved -- formatting is done by
+// FormatOnSave
+func (ge *Gide) SaveCleanup(tb *giv.TextBuf) {
+	sh := ge.SaveHooksFor(tb)
+	if !sh.TrimTrail && !sh.FinalNL {
+		return
+	}
+	lines := make([]string, len(tb.Lines))
+	for i, l := range tb.Lines {
+		lines[i] = string(l)
+	}
+	if sh.TrimTrail {
+		base, ok := ge.diskBase(tb)
+		for _, ln := range TrimChangedLines(strings.Split(string(base), "\n"), ok, lines) {
+			n := trailTrimLen(lines[ln])
+			tb.DeleteText(giv.TextPos{Ln: ln, Ch: n}, giv.TextPos{Ln: ln, Ch: len(tb.Lines[ln])}, true, true)
+			lines[ln] = string(tb.Lines[ln])
+		}
+	}
+	if sh.FinalNL {
+		if k, fix := FinalNLFix(lines); fix {
+			st := giv.TextPos{Ln: k, Ch: len(tb.Lines[k])}
+			if last := len(tb.Lines) - 1; last > k {
+				tb.DeleteText(st, giv.TextPos{Ln: last, Ch: len(tb.Lines[last])}, true, true)
+			}
+			tb.InsertText(st, []byte("\n"), true, true)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrimChangedLines(t *testing.T) {
+	base := []string{"a  ", "b", "c\t", "d"}
+	cur := []string{"a  ", "b ", "new  ", "c\t", "d", "e \t"}
+	if got, want := TrimChangedLines(base, true, cur), []int{1, 2, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changed: got %v, want %v", got, want)
+	}
+	if got, want := TrimChangedLines(nil, false, cur), []int{0, 1, 2, 3, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("no base: got %v, want %v", got, want)
+	}
+	if got := TrimChangedLines(base, true, base); len(got) != 0 {
+		t.Errorf("unchanged: got %v", got)
+	}
+}
+
+func TestFinalNLFix(t *testing.T) {
+	cases := []struct {
+		lines []string
+		k     int
+		fix   bool
+	}{
+		{[]string{"a", "b", ""}, 1, false},
+		{[]string{"a", "b"}, 1, true},
+		{[]string{"a", "b", "", "", ""}, 1, true},
+		{[]string{"a", "b", "  ", ""}, 1, true},
+		{[]string{"a", "b", " "}, 1, true},
+		{[]string{""}, -1, false},
+		{[]string{"", " "}, -1, false},
+	}
+	for _, c := range cases {
+		if k, fix := FinalNLFix(c.lines); k != c.k || fix != c.fix {
+			t.Errorf("%q: got %v %v, want %v %v", c.lines, k, fix, c.k, c.fix)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+)
+
+// UnsavedChoice is what the user chose to do with the unsaved files in the
+// UnsavedDialog
+type UnsavedChoice int
+
+const (
+	// UnsavedCancel cancels the command that needed the files saved
+	UnsavedCancel UnsavedChoice = iota
+
+	// UnsavedSaved is when the selected files have been saved, and any others
+	// are left as they are
+	UnsavedSaved
+
+	// UnsavedDiscard is when none of the files are to be saved
+	UnsavedDiscard
+)
+
+// UnsavedDiff returns the unsaved changes in given buffer, as a unified diff
+// from its file on disk, decoded from the encoding of the buffer and with
+// its line endings (see diskText), and the number of lines inserted and
+// deleted
+func (ge *Gide) UnsavedDiff(tb *giv.TextBuf) (dif []byte, ins, del int) {
+	disk, _, _ := ge.diskText(tb) // a new file is all inserted
+	dtb := &giv.TextBuf{}
+	dtb.InitName(dtb, "disk-version")
+	dtb.SetText(disk)
+	cur := tb.LinesToBytesCopy()
+	ins, del = DiffStat(DiffRows(strings.Split(string(disk), "\n"), strings.Split(string(cur), "\n")))
+	return dtb.DiffBufsUnified(tb, 2), ins, del
+}
+
+// SaveOpenNode saves given open file node to its current file name, as
+// SaveAllOpenNodes does for each of them
+func (ge *Gide) SaveOpenNode(ond *giv.FileNode) {
+	ge.EditorConfigSave(ond.Buf)
+	ge.SaveCleanup(ond.Buf)
+	ge.FormatOnSave(ond.Buf)
+	ge.SaveBuf(ond.Buf, func(err error) {
+		ge.RunPostCmdsFileNode(ond)
+		ge.RunGenerators(ond.Buf)
+	})
+}
+
+// UnsavedDialog shows a single dialog listing all of the open files with
+// unsaved changes, each with the number of lines changed and a button to
+// preview its diff from the version on disk, and a checkbox to pick whether
+// it is saved -- the user can save the selected ones, save all, discard all,
+// or (if cancelOpt) cancel -- done is called with the choice, on the gui,
+// after any saving has finished (including any that was already running,
+// see WhenSaved), unless there are no unsaved files, in which case it is
+// called with UnsavedSaved right away -- returns true if the dialog was
+// shown
+func (ge *Gide) UnsavedDialog(title, prompt string, cancelOpt bool, done func(ch UnsavedChoice)) bool {
+	var onds []*giv.FileNode
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil && ond.Buf.IsChanged() {
+			onds = append(onds, ond)
+		}
+	}
+	if len(onds) == 0 {
+		if done != nil {
+			done(UnsavedSaved)
+		}
+		return false
+	}
+	finish := func(ch UnsavedChoice) {
+		if done == nil {
+			return
+		}
+		if ch == UnsavedCancel {
+			done(ch)
+			return
+		}
+		ge.WhenSaved(func() { done(ch) })
+	}
+
+	dlg := gi.NewStdDialog(gi.DlgOpts{Title: title, Prompt: fmt.Sprintf("%v -- there are <b>%v</b> opened files with <b>unsaved changes</b>:", prompt, len(onds))}, false, false)
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	idx := prIdx + 1
+
+	dtb := &giv.TextBuf{}
+	dtb.InitName(dtb, "unsaved-diff")
+	cbs := make([]*gi.CheckBox, len(onds))
+	for i, ond := range onds {
+		tb := ond.Buf
+		dif, ins, del := ge.UnsavedDiff(tb)
+		fr := frame.InsertNewChild(gi.KiT_Layout, idx, fmt.Sprintf("file-%d", i)).(*gi.Layout)
+		idx++
+		fr.Lay = gi.LayoutHoriz
+		fr.SetStretchMaxWidth()
+		cb := fr.AddNewChild(gi.KiT_CheckBox, "save").(*gi.CheckBox)
+		cb.SetText(html.EscapeString(refRelPath(string(ge.ProjRoot), string(tb.Filename))))
+		cb.SetChecked(true)
+		cb.Tooltip = "save this file -- uncheck to discard its changes"
+		cbs[i] = cb
+		lbl := fr.AddNewChild(gi.KiT_Label, "stat").(*gi.Label)
+		lbl.SetText(fmt.Sprintf("  +%d -%d", ins, del))
+		lbl.SetStretchMaxWidth()
+		db := fr.AddNewChild(gi.KiT_Button, "diff").(*gi.Button)
+		db.SetText("Diff")
+		db.Tooltip = "show the unsaved changes in this file below"
+		db.ButtonSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.ButtonClicked) {
+				dtb.SetText(dif)
+			}
+		})
+		if i == 0 {
+			dtb.SetText(dif)
+		}
+	}
+
+	ly := frame.InsertNewChild(gi.KiT_Layout, idx, "diff-lay").(*gi.Layout)
+	idx++
+	ly.SetProp("width", units.NewValue(80, units.Ch))
+	ly.SetProp("height", units.NewValue(20, units.Em))
+	dtv := ge.ConfigOutputTextView(ly)
+	dtv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+	dtv.SetBuf(dtb)
+
+	bb := frame.InsertNewChild(gi.KiT_Layout, idx, "unsaved-buttons").(*gi.Layout)
+	bb.Lay = gi.LayoutHoriz
+	bb.SetStretchMaxWidth()
+	button := func(nm, tip string, fun func()) {
+		b := bb.AddNewChild(gi.KiT_Button, nm).(*gi.Button)
+		b.SetText(nm)
+		b.Tooltip = tip
+		b.ButtonSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.ButtonClicked) {
+				fun()
+			}
+		})
+	}
+	button("Save Selected", "save the checked files, leaving the changes in the others unsaved", func() {
+		dlg.Accept()
+		for i, ond := range onds {
+			if cbs[i].IsChecked() {
+				ge.SaveOpenNode(ond)
+			}
+		}
+		finish(UnsavedSaved)
+	})
+	button("Save All", "save all of the files", func() {
+		dlg.Accept()
+		for _, ond := range onds {
+			ge.SaveOpenNode(ond)
+		}
+		finish(UnsavedSaved)
+	})
+	button("Discard All", "do not save any of the files", func() {
+		dlg.Accept()
+		finish(UnsavedDiscard)
+	})
+	if cancelOpt {
+		button("Cancel", "do not save anything, and cancel the command", func() {
+			dlg.Cancel()
+			finish(UnsavedCancel)
+		})
+	}
+
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, ge.Viewport, nil)
+	return true
+}
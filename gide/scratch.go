@@ -0,0 +1,437 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// ScratchDirName is the name of the directory in the app prefs directory
+// that scratch buffers are kept in
+var ScratchDirName = "scratch"
+
+// ScratchSaveDelay is how long after the last edit of a scratch buffer it
+// is written to its file
+var ScratchSaveDelay = time.Second
+
+// ScratchDir returns the directory that scratch buffers are kept in
+func ScratchDir() string {
+	return filepath.Join(oswin.TheApp.AppPrefsDir(), ScratchDirName)
+}
+
+// ScratchExt returns the extension of the file of a scratch buffer in given
+// language: its first extension starting with a . -- .txt if none
+func ScratchExt(ln LangName) string {
+	if lr, _, ok := AvailLangs.LangByName(ln); ok {
+		for _, e := range lr.Exts {
+			if strings.HasPrefix(e, ".") {
+				return e
+			}
+		}
+	}
+	return ".txt"
+}
+
+// ScratchNames returns the names of the files of the scratch buffers in
+// given directory, sorted by their numbers
+func ScratchNames(dir string) []string {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var nms []string
+	for _, fi := range fis {
+		if _, ok := scratchNum(fi.Name()); ok && !fi.IsDir() {
+			nms = append(nms, fi.Name())
+		}
+	}
+	sort.Slice(nms, func(i, j int) bool {
+		a, _ := scratchNum(nms[i])
+		b, _ := scratchNum(nms[j])
+		return a < b
+	})
+	return nms
+}
+
+// scratchNum returns the number of the scratch buffer of given file name,
+// e.g., 3 for scratch-3.go
+func scratchNum(fnm string) (int, bool) {
+	base := strings.TrimSuffix(fnm, filepath.Ext(fnm))
+	if !strings.HasPrefix(base, "scratch-") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(base, "scratch-"))
+	return n, err == nil && n > 0
+}
+
+// ScratchNewName returns the file name for a new scratch buffer with given
+// extension, numbered after those of given existing ones
+func ScratchNewName(nms []string, ext string) string {
+	mx := 0
+	for _, nm := range nms {
+		if n, ok := scratchNum(nm); ok && n > mx {
+			mx = n
+		}
+	}
+	return fmt.Sprintf("scratch-%d%s", mx+1, ext)
+}
+
+// ScratchLabel returns the label of the tab of the scratch buffer with
+// given file name
+func ScratchLabel(fnm string) string {
+	n, _ := scratchNum(fnm)
+	return fmt.Sprintf("Scratch %d", n)
+}
+
+// NewScratch opens a new scratch buffer in given language, for notes,
+// pasted logs and quick experiments: it is not a file of the project, but
+// is kept in the prefs directory across sessions until it is deleted, and
+// can be saved as a file with Save As
+func (ge *Gide) NewScratch(lang LangName) {
+	dir := ScratchDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		ge.SetStatus("Could not make the scratch directory: " + err.Error())
+		return
+	}
+	fnm := ScratchNewName(ScratchNames(dir), ScratchExt(lang))
+	if err := ioutil.WriteFile(filepath.Join(dir, fnm), nil, 0644); err != nil {
+		ge.SetStatus("Could not make the scratch buffer: " + err.Error())
+		return
+	}
+	ge.ViewScratch(fnm)
+}
+
+// OpenScratch shows the scratch buffers kept from this and earlier
+// sessions, to open one
+func (ge *Gide) OpenScratch() {
+	nms := ScratchNames(ScratchDir())
+	if len(nms) == 0 {
+		ge.SetStatus("There are no scratch buffers -- make one with New Scratch Buffer")
+		return
+	}
+	lbs := make([]string, len(nms))
+	for i, nm := range nms {
+		lbs[i] = ScratchLabel(nm) + ": " + nm
+		if b, err := ioutil.ReadFile(filepath.Join(ScratchDir(), nm)); err == nil && len(b) > 0 {
+			lbs[i] += "  " + KillSummary(string(b), 40)
+		}
+	}
+	gi.StringsChooserPopup(lbs, lbs[len(lbs)-1], ge, func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		ge.ViewScratch(nms[ac.Data.(int)])
+	})
+}
+
+// ViewScratch shows the scratch buffer of given file name, in the scratch
+// directory, in its tab
+func (ge *Gide) ViewScratch(fnm string) {
+	svi, _ := ge.FindOrMakeMainTab(ScratchLabel(fnm), KiT_ScratchView, true) // sel
+	sv := svi.Embed(KiT_ScratchView).(*ScratchView)
+	sv.UpdateView(ge)
+	if sv.Buf == nil || filepath.Base(string(sv.Buf.Filename)) != fnm {
+		sv.Open(filepath.Join(ScratchDir(), fnm))
+	}
+	ge.FocusOnPanel(MainTabsIdx)
+	sv.TextView().GrabFocus()
+}
+
+// ScratchView is a widget with a scratch buffer: an editable buffer that
+// is not a file of the project, kept in the scratch directory, which is
+// written as it is edited
+type ScratchView struct {
+	gi.Layout
+	Gide  *Gide        `json:"-" xml:"-" desc:"parent gide project"`
+	Buf   *giv.TextBuf `json:"-" xml:"-" desc:"the scratch buffer"`
+	saveT *time.Timer
+	mu    sync.Mutex
+}
+
+var KiT_ScratchView = kit.Types.AddType(&ScratchView{}, ScratchViewProps)
+
+// Open opens the scratch buffer of given file in the view
+func (sv *ScratchView) Open(path string) {
+	ge := sv.Gide
+	tb := &giv.TextBuf{}
+	tb.InitName(tb, "scratch-buf")
+	tb.Autosave = false
+	giv.FileNodeHiStyle = Prefs.HiStyle
+	if err := tb.Open(gi.FileName(path)); err != nil {
+		ge.SetStatus("Could not open the scratch buffer: " + err.Error())
+		return
+	}
+	ge.ConfigTextBuf(tb)
+	tb.TextBufSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		svv, _ := recv.Embed(KiT_ScratchView).(*ScratchView)
+		switch giv.TextBufSignals(sig) {
+		case giv.TextBufInsert, giv.TextBufDelete:
+			svv.SaveLater()
+		case giv.TextBufMarkUpdt:
+			svv.Gide.HiMarkupBuf(svv.Buf)
+		}
+	})
+	sv.Buf = tb
+	sv.TextView().SetBuf(tb)
+	sv.SetInfo()
+}
+
+// SaveLater writes the buffer to its file ScratchSaveDelay after the last
+// edit
+func (sv *ScratchView) SaveLater() {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	if sv.saveT != nil {
+		sv.saveT.Stop()
+	}
+	sv.saveT = time.AfterFunc(ScratchSaveDelay, func() { sv.Save() })
+}
+
+// stopSave cancels writing the buffer after the last edit
+func (sv *ScratchView) stopSave() {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	if sv.saveT != nil {
+		sv.saveT.Stop()
+		sv.saveT = nil
+	}
+}
+
+// Save writes the buffer to its file in the scratch directory
+func (sv *ScratchView) Save() error {
+	tb := sv.Buf
+	if tb == nil {
+		return nil
+	}
+	err := ioutil.WriteFile(string(tb.Filename), tb.LinesToBytesCopy(), 0644)
+	if err == nil {
+		tb.ClearChanged()
+	}
+	return err
+}
+
+// SetLang sets the language of the buffer, which is kept in a file with an
+// extension of the language, for highlighting and indenting it as such
+func (sv *ScratchView) SetLang(lang LangName) {
+	tb := sv.Buf
+	if tb == nil {
+		return
+	}
+	opath := string(tb.Filename)
+	npath := strings.TrimSuffix(opath, filepath.Ext(opath)) + ScratchExt(lang)
+	if npath == opath {
+		return
+	}
+	sv.stopSave()
+	if err := ioutil.WriteFile(npath, tb.LinesToBytesCopy(), 0644); err != nil {
+		sv.Gide.SetStatus("Could not change the language: " + err.Error())
+		return
+	}
+	os.Remove(opath)
+	sv.Open(npath)
+}
+
+// SaveAs saves the buffer as given file, which is then opened in the
+// editor, and deletes the scratch buffer -- the user is asked before an
+// existing file is overwritten, and a file that is open in the editor is
+// not written, as that would lose its buffer
+func (sv *ScratchView) SaveAs(filename gi.FileName) {
+	tb := sv.Buf
+	if tb == nil {
+		return
+	}
+	ge := sv.Gide
+	fnm, err := filepath.Abs(string(filename))
+	if err != nil {
+		fnm = string(filename)
+	}
+	if _, open := ge.OpenNodeByPath(fnm); open {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "File is Open", Prompt: fmt.Sprintf("%v is open in the editor -- close it first, or save the scratch buffer as another file", fnm)}, true, false, nil, nil)
+		return
+	}
+	fi, err := os.Stat(fnm)
+	if err != nil {
+		sv.saveAs(fnm, nil)
+		return
+	}
+	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "File Exists", Prompt: fmt.Sprintf("%v already exists -- overwrite it with the scratch buffer? This can not be undone.", fnm)},
+		[]string{"Overwrite", "Cancel"}, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == 0 {
+				svv, _ := recv.Embed(KiT_ScratchView).(*ScratchView)
+				svv.saveAs(fnm, fi)
+			}
+		})
+}
+
+// saveAs writes the buffer to given file, with given info if it exists,
+// keeping its mode, and records it in the audit log -- see SaveAs
+func (sv *ScratchView) saveAs(fnm string, fi os.FileInfo) {
+	ge := sv.Gide
+	perm := os.FileMode(0644)
+	var before int64
+	if fi != nil {
+		perm = fi.Mode().Perm()
+		before = fi.Size()
+	}
+	if err := ioutil.WriteFile(fnm, sv.Buf.LinesToBytesCopy(), perm); err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Save Scratch Buffer", Prompt: err.Error()}, true, false, nil, nil)
+		return
+	}
+	ge.AuditWrite("scratch save as", fnm, before, "")
+	sv.remove()
+	ge.Files.UpdateNewFile(fnm)
+	if _, _, ok := ge.ViewFile(gi.FileName(fnm)); !ok {
+		ge.SetStatus("Scratch buffer saved as " + fnm + ", outside of the project")
+	}
+}
+
+// Delete deletes the scratch buffer, and its file
+func (sv *ScratchView) Delete() {
+	tb := sv.Buf
+	if tb == nil {
+		return
+	}
+	gi.ChoiceDialog(sv.Gide.Viewport, gi.DlgOpts{Title: "Delete Scratch Buffer", Prompt: fmt.Sprintf("Delete %v? This can not be undone.", filepath.Base(string(tb.Filename)))},
+		[]string{"Delete", "Cancel"}, sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == 0 {
+				svv, _ := recv.Embed(KiT_ScratchView).(*ScratchView)
+				svv.remove()
+			}
+		})
+}
+
+// remove removes the file of the buffer and the tab of the view
+func (sv *ScratchView) remove() {
+	sv.stopSave()
+	ge := sv.Gide
+	os.Remove(string(sv.Buf.Filename))
+	if _, idx, ok := ge.MainTabByName(ScratchLabel(filepath.Base(string(sv.Buf.Filename)))); ok {
+		ge.MainTabs().DeleteTabIndex(idx, true)
+	}
+}
+
+// SetInfo shows the file and language of the buffer
+func (sv *ScratchView) SetInfo() {
+	fnm := string(sv.Buf.Filename)
+	lang := "plain text"
+	if ls := LangsForFilename(fnm); len(ls) > 0 {
+		lang = ls[0].Name
+	}
+	sv.InfoLabel().SetText(fmt.Sprintf("%v -- %v -- kept in %v until deleted", filepath.Base(fnm), lang, filepath.Dir(fnm)))
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (sv *ScratchView) UpdateView(ge *Gide) {
+	sv.Gide = ge
+	sv.Lay = gi.LayoutVert
+	sv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "scratchbar")
+	config.Add(gi.KiT_Layout, "scratchtext")
+	mods, updt := sv.ConfigChildren(config, false)
+	sv.ConfigToolbar()
+	ly := sv.KnownChild(1).(*gi.Layout)
+	if !ly.HasChildren() {
+		tv := ge.ConfigOutputTextView(ly)
+		tv.SetProp("white-space", gi.WhiteSpacePre)
+		tv.SetProp("tab-size", ge.Prefs.Editor.TabSize)
+		tv.SetInactiveState(false)
+	}
+	if mods {
+		sv.UpdateEnd(updt)
+	}
+}
+
+// ScratchBar returns the scratch toolbar
+func (sv *ScratchView) ScratchBar() *gi.ToolBar {
+	tbi, ok := sv.ChildByName("scratchbar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// InfoLabel returns the label with the file and language, in the toolbar
+func (sv *ScratchView) InfoLabel() *gi.Label {
+	tb := sv.ScratchBar()
+	if tb == nil {
+		return nil
+	}
+	lbi, ok := tb.ChildByName("info", 3)
+	if !ok {
+		return nil
+	}
+	return lbi.(*gi.Label)
+}
+
+// TextView returns the text view of the buffer
+func (sv *ScratchView) TextView() *giv.TextView {
+	return sv.KnownChild(1).KnownChild(0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (sv *ScratchView) ConfigToolbar() {
+	tb := sv.ScratchBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	act := func(nm, txt, tip string, fun func(svv *ScratchView)) {
+		ac := tb.AddNewChild(gi.KiT_Action, nm).(*gi.Action)
+		ac.SetText(txt)
+		ac.Tooltip = tip
+		ac.ActionSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_ScratchView).(*ScratchView)
+			fun(svv)
+		})
+	}
+	act("lang", "Language...", "set the language of the buffer, for highlighting and indenting it", func(svv *ScratchView) {
+		giv.CallMethod(svv, "SetLang", svv.Viewport)
+	})
+	act("saveas", "Save As...", "save the buffer as a file, which is then opened in the editor, and delete the scratch buffer", func(svv *ScratchView) {
+		giv.CallMethod(svv, "SaveAs", svv.Viewport)
+	})
+	act("delete", "Delete", "delete the scratch buffer, and its file in the prefs directory", func(svv *ScratchView) { svv.Delete() })
+
+	lbl := tb.AddNewChild(gi.KiT_Label, "info").(*gi.Label)
+	lbl.SetStretchMaxWidth()
+}
+
+var ScratchViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+	"CallMethods": ki.PropSlice{
+		{"SetLang", ki.Props{
+			"label": "Language",
+			"Args": ki.PropSlice{
+				{"Language", ki.Props{}},
+			},
+		}},
+		{"SaveAs", ki.Props{
+			"label": "Save As",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{}},
+			},
+		}},
+	},
+}
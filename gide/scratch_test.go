@@ -0,0 +1,59 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScratchNames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-scratch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, nm := range []string{"scratch-10.md", "scratch-2.go", "scratch-1.txt", "notes.txt", "scratch-x.txt", "scratch-0.txt", "scratch-"} {
+		ioutil.WriteFile(filepath.Join(dir, nm), nil, 0644)
+	}
+	os.Mkdir(filepath.Join(dir, "scratch-3"), 0755)
+	nms := ScratchNames(dir)
+	if want := []string{"scratch-1.txt", "scratch-2.go", "scratch-10.md"}; !reflect.DeepEqual(nms, want) {
+		t.Errorf("got %v, want %v", nms, want)
+	}
+	if got := ScratchNewName(nms, ".py"); got != "scratch-11.py" {
+		t.Errorf("new name: got %v", got)
+	}
+	if got := ScratchNewName(nil, ".txt"); got != "scratch-1.txt" {
+		t.Errorf("first new name: got %v", got)
+	}
+	if got := ScratchNames(filepath.Join(dir, "none")); got != nil {
+		t.Errorf("no dir: got %v", got)
+	}
+}
+
+func TestScratchLabel(t *testing.T) {
+	if got := ScratchLabel("scratch-12.go"); got != "Scratch 12" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestScratchExt(t *testing.T) {
+	save := AvailLangs
+	defer func() { AvailLangs = save }()
+	AvailLangs = Langs{
+		{Name: "Go", Exts: []string{".go"}},
+		{Name: "Makefile", Exts: []string{"Makefile", ".mk"}},
+		{Name: "Dockerfile", Exts: []string{"Dockerfile"}},
+	}
+	for ln, want := range map[LangName]string{"Go": ".go", "Makefile": ".mk", "Dockerfile": ".txt", "": ".txt", "Nope": ".txt"} {
+		if got := ScratchExt(ln); got != want {
+			t.Errorf("%v: got %v, want %v", ln, got, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package gide
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// SearchMmapMin is the size of the smallest file that is mapped into memory
+// to be searched, instead of being read
+var SearchMmapMin int64 = 64 * 1024
+
+// SearchReadFile returns the contents of the file at given path for
+// searching, mapped into memory if it is at least SearchMmapMin in size --
+// done must be called when finished with them
+func SearchReadFile(path string) (src []byte, done func(), err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	sz := fi.Size()
+	if sz >= SearchMmapMin && int64(int(sz)) == sz {
+		if src, err = syscall.Mmap(int(f.Fd()), 0, int(sz), syscall.PROT_READ, syscall.MAP_SHARED); err == nil {
+			return src, func() { syscall.Munmap(src) }, nil
+		}
+	}
+	src = make([]byte, sz)
+	n, err := io.ReadFull(f, src)
+	if err == io.ErrUnexpectedEOF { // shrank since the stat
+		err = nil
+	}
+	return src[:n], func() {}, err
+}
@@ -0,0 +1,18 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+)
+
+// SearchReadFile returns the contents of the file at given path for
+// searching -- done must be called when finished with them -- files are
+// not mapped into memory on windows, where they could not be changed or
+// removed while mapped
+func SearchReadFile(path string) (src []byte, done func(), err error) {
+	src, err = ioutil.ReadFile(path)
+	return src, func() {}, err
+}
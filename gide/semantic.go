@@ -0,0 +1,168 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"log"
+	"strings"
+
+	"github.com/goki/gi/giv"
+)
+
+// LspSemanticTokenTypes are the semantic token types that gide asks servers for
+var LspSemanticTokenTypes = []string{"namespace", "type", "class", "enum", "interface", "struct", "typeParameter", "parameter", "variable", "property", "enumMember", "event", "function", "method", "macro", "keyword", "modifier", "comment", "string", "number", "regexp", "operator", "decorator", "label"}
+
+// LspSemanticTokenModifiers are the semantic token modifiers that gide asks
+// servers for
+var LspSemanticTokenModifiers = []string{"declaration", "definition", "readonly", "static", "deprecated", "abstract", "async", "modification", "documentation", "defaultLibrary"}
+
+// SemanticClasses are the css classes of the highlighting styles used for
+// each semantic token type, so that they get the colors of the current
+// style -- tokens of types not listed here keep their lexical highlighting
+var SemanticClasses = map[string]string{
+	"namespace":     "nn",
+	"type":          "nc",
+	"class":         "nc",
+	"enum":          "nc",
+	"interface":     "nc",
+	"struct":        "nc",
+	"typeParameter": "vc",
+	"parameter":     "na",
+	"variable":      "nv",
+	"property":      "py",
+	"enumMember":    "no",
+	"function":      "nf",
+	"method":        "nf",
+	"macro":         "nd",
+	"decorator":     "nd",
+	"label":         "nl",
+}
+
+// SemanticModClasses are the css classes used for a semantic token type with
+// a given modifier, in place of the one for the type alone, e.g., constants
+// (readonly variables) and builtins (defaultLibrary functions and types)
+var SemanticModClasses = map[string]string{
+	"variable readonly":       "no",
+	"property readonly":       "no",
+	"function defaultLibrary": "nb",
+	"type defaultLibrary":     "kt",
+	"variable defaultLibrary": "nb",
+}
+
+// SemanticClass returns the css class for a semantic token of given type and
+// modifiers -- empty if its lexical highlighting is kept
+func SemanticClass(typ string, mods []string) string {
+	for _, m := range mods {
+		if cls, ok := SemanticModClasses[typ+" "+m]; ok {
+			return cls
+		}
+	}
+	return SemanticClasses[typ]
+}
+
+// SemanticSpans returns the spans of given semantic tokens, by line, with
+// the classes for them -- tokens that span lines are cut at the line ends
+func SemanticSpans(toks []LspSemanticToken, lines []string) map[int][]HiSpan {
+	sps := make(map[int][]HiSpan)
+	for _, tk := range toks {
+		cls := SemanticClass(tk.Type, tk.Mods)
+		if cls == "" || tk.Line >= len(lines) {
+			continue
+		}
+		ed := tk.Char + tk.Len
+		if n := len([]rune(lines[tk.Line])); ed > n {
+			ed = n
+		}
+		if ed > tk.Char {
+			sps[tk.Line] = append(sps[tk.Line], HiSpan{tk.Char, ed, cls})
+		}
+	}
+	return sps
+}
+
+// SemState is the semantic highlighting of a buffer, from its language
+// server, which is requested in the background whenever it is edited
+type SemState struct {
+	Text    string           `desc:"text of the buffer that the spans are for"`
+	Spans   map[int][]HiSpan `desc:"semantic spans by line"`
+	Pending bool             `desc:"a request is in progress"`
+	NoSem   bool             `desc:"the server does not provide semantic tokens"`
+}
+
+// SemanticMarkupBuf gets the semantic highlighting of given buffer, with
+// lexical highlighting in hs, from the language server, if it is not for its
+// current text, in the background -- it is applied by hiOverlay, and when it
+// arrives, if the text has not changed in the meantime -- must be called
+// with hiMu locked
+func (ge *Gide) SemanticMarkupBuf(tb *giv.TextBuf, hs *HiState, lines []string) {
+	if !ge.Prefs.Editor.SemanticHi {
+		delete(ge.semStates, tb)
+		return
+	}
+	if ge.semStates == nil {
+		ge.semStates = make(map[*giv.TextBuf]*SemState)
+	}
+	ss := ge.semStates[tb]
+	if ss == nil {
+		ss = &SemState{}
+		ge.semStates[tb] = ss
+	}
+	if ss.NoSem {
+		return
+	}
+	text := strings.Join(lines, "\n")
+	if ss.Spans != nil && ss.Text == text {
+		return
+	}
+	if ss.Pending {
+		return // rechecked when it is done
+	}
+	if ge.PowerLow() {
+		return // requested again by powerResume
+	}
+	ss.Pending = true
+	go ge.semRequest(tb, ss, text)
+}
+
+// semRequest gets the semantic tokens for given buffer, which had given
+// text, from its language server, and applies them if it still has it --
+// otherwise, it requests them again for the current text
+func (ge *Gide) semRequest(tb *giv.TextBuf, ss *SemState, text string) {
+	var toks []LspSemanticToken
+	var err error
+	lc := ge.LspClientForBuf(tb)
+	noSem := lc == nil
+	if !noSem {
+		if _, ok := lc.SemanticLegend(); ok {
+			toks, err = lc.SemanticTokens(string(tb.Filename))
+		} else {
+			noSem = true
+		}
+	}
+	ge.hiMu.Lock()
+	defer ge.hiMu.Unlock()
+	ss.Pending = false
+	if noSem {
+		ss.NoSem = true
+		return
+	}
+	if err != nil {
+		log.Printf("gide.SemanticTokens: %v\n", err)
+		return
+	}
+	lines := strings.Split(text, "\n")
+	ss.Text = text
+	ss.Spans = SemanticSpans(toks, lines)
+	hs := ge.hiStates[tb]
+	if hs == nil || hs.Tokenize == nil || strings.Join(hs.Text, "\n") != text {
+		if hs != nil && hs.Tokenize != nil { // edited since: get them for the current text
+			ss.Pending = true
+			go ge.semRequest(tb, ss, strings.Join(hs.Text, "\n"))
+		}
+		return
+	}
+	ge.hiOverlay(tb, hs, lines, ge.LineDecoFor(tb))
+	tb.RefreshViews()
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSemanticSpans(t *testing.T) {
+	lines := []string{"const max = 10", "func f(n int) int { return max + n }"}
+	toks := []LspSemanticToken{
+		{Line: 0, Char: 6, Len: 3, Type: "variable", Mods: []string{"definition", "readonly"}},
+		{Line: 1, Char: 5, Len: 1, Type: "function"},
+		{Line: 1, Char: 7, Len: 1, Type: "parameter"},
+		{Line: 1, Char: 9, Len: 3, Type: "type", Mods: []string{"defaultLibrary"}},
+		{Line: 1, Char: 20, Len: 6, Type: "keyword"},
+		{Line: 1, Char: 34, Len: 10, Type: "parameter"},
+		{Line: 5, Char: 0, Len: 1, Type: "variable"},
+	}
+	got := SemanticSpans(toks, lines)
+	want := map[int][]HiSpan{
+		0: {{6, 9, "no"}},
+		1: {{5, 6, "nf"}, {7, 8, "na"}, {9, 12, "kt"}, {34, 36, "na"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SemanticSpans: got %v, want %v", got, want)
+	}
+}
+
+func TestHiMarkupLine(t *testing.T) {
+	line := "f(a<b)"
+	base := []HiSpan{{0, 1, "nx"}, {2, 3, "nx"}, {3, 4, "o"}, {4, 5, "nx"}}
+	over := []HiSpan{{0, 1, "nf"}, {2, 3, "na"}}
+	got := string(HiMarkupLine(line, base, over, nil))
+	want := `<span class="nf">f</span>(<span class="na">a</span><span class="o">&lt;</span><span class="nx">b</span>)`
+	if got != want {
+		t.Errorf("HiMarkupLine:\ngot  %v\nwant %v", got, want)
+	}
+}
@@ -0,0 +1,545 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/histyle"
+)
+
+// ServeMaxFiles is the most files that gide serve lists in the file tree
+var ServeMaxFiles = 20000
+
+// ServeMaxSize is the largest file that gide serve shows or searches, in
+// bytes
+var ServeMaxSize int64 = 4 << 20
+
+// ServeMaxHits is the most matches that gide serve returns for a search
+var ServeMaxHits = 1000
+
+// ServeTokenCookie is the name of the cookie with the access token of gide
+// serve, set when the page is first opened with the token in its url
+var ServeTokenCookie = "gide_token"
+
+// Server serves a project to a browser, read-only or with limited editing,
+// over the highlighting, search and symbol engines of gide, without a
+// display -- for a quick look at a project on a remote box, see Serve
+type Server struct {
+	Root  string            `desc:"root directory of the project served"`
+	Edit  bool              `desc:"allow saving edits to existing files"`
+	Token string            `desc:"access token, needed in the url of the first request, then kept in a cookie -- none if empty"`
+	Style histyle.StyleName `desc:"highlighting style of the files shown"`
+	Syms  *SymIndex         `desc:"index of the symbols of the project, for symbol search"`
+	mux   *http.ServeMux
+}
+
+// NewServer returns a new server for given project root, allowing edits if
+// edit is true, with given access token -- its symbol index is built in the
+// background -- the root is used with its symbolic links resolved, as are
+// the paths of the files served (see ServeResolve)
+func NewServer(root string, edit bool, token string) *Server {
+	if rr, err := filepath.EvalSymlinks(root); err == nil {
+		root = rr
+	}
+	sv := &Server{Root: filepath.Clean(root), Edit: edit, Token: token, Style: Prefs.HiStyle, Syms: NewSymIndex(root)}
+	sv.Syms.Start()
+	sv.mux = http.NewServeMux()
+	sv.mux.HandleFunc("/", sv.serveIndex)
+	sv.mux.HandleFunc("/api/files", sv.serveFiles)
+	sv.mux.HandleFunc("/api/file", sv.serveFile)
+	sv.mux.HandleFunc("/api/search", sv.serveSearch)
+	sv.mux.HandleFunc("/api/syms", sv.serveSyms)
+	sv.mux.HandleFunc("/api/save", sv.serveSave)
+	return sv
+}
+
+// ServeToken returns a new random access token
+func ServeToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalln(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Serve serves the project at given root to browsers at given address,
+// e.g., localhost:8080, allowing edits if edit is true -- the url to open,
+// with a new access token, is printed, and it runs until it fails
+func Serve(root, addr string, edit bool) error {
+	Prefs.Defaults()
+	histyle.Init()
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(root); err != nil || !fi.IsDir() {
+		return fmt.Errorf("gide serve: not a directory: %v", root)
+	}
+	sv := NewServer(root, edit, ServeToken())
+	host := addr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+	mode := "read-only"
+	if edit {
+		mode = "editing"
+	}
+	fmt.Printf("gide serving %v (%v) at:\n  http://%v/?token=%v\n", root, mode, host, sv.Token)
+	return http.ListenAndServe(addr, sv)
+}
+
+// ServePath returns the full path of the file at given slash-separated
+// path relative to given root -- an error if it is outside of the root,
+// or within a directory that is not served
+func ServePath(root, rel string) (string, error) {
+	rel = strings.TrimPrefix(filepath.ToSlash(rel), "/")
+	if rel == "" {
+		return "", fmt.Errorf("no path")
+	}
+	if !servePathOk(rel) {
+		return "", fmt.Errorf("path not served: %v", rel)
+	}
+	return filepath.Join(root, filepath.FromSlash(rel)), nil
+}
+
+// servePathOk returns true if none of the elements of given slash-separated
+// relative path are empty, . or .., or start with .
+func servePathOk(rel string) bool {
+	for _, d := range strings.Split(rel, "/") {
+		if d == "" || d == "." || d == ".." || strings.HasPrefix(d, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// ServeResolve returns the real path of the file at given full path from
+// ServePath, with all of its symbolic links resolved -- an error if that is
+// not within the real path of given root, or is within a directory that is
+// not served, e.g., through a link pointing out of the root -- the error is
+// an os.IsNotExist one if the file does not exist
+func ServeResolve(root, path string) (string, error) {
+	rroot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(rroot, real)
+	if err != nil || !servePathOk(filepath.ToSlash(rel)) {
+		return "", fmt.Errorf("path not served: %v", path)
+	}
+	return real, nil
+}
+
+// serveDirOk returns true if the directory of given name is served: not
+// those skipped by SymIndex
+func serveDirOk(nm string) bool {
+	return !SymIndexSkipDirs[nm] && !strings.HasPrefix(nm, ".") && !strings.HasPrefix(nm, "_")
+}
+
+// ServeFiles returns the slash-separated paths, relative to given root, of
+// the files within it that are served, sorted, up to max of them -- files
+// and directories starting with . are not, nor those skipped by SymIndex
+func ServeFiles(root string, max int) []string {
+	var fs []string
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if len(fs) >= max {
+			return filepath.SkipDir
+		}
+		nm := info.Name()
+		if info.IsDir() {
+			if path != root && !serveDirOk(nm) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || strings.HasPrefix(nm, ".") || strings.HasSuffix(nm, "~") {
+			return nil
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			fs = append(fs, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(fs)
+	return fs
+}
+
+// ServeIsText returns true if given file contents look like text: there
+// are no NUL bytes near its start
+func ServeIsText(src []byte) bool {
+	if len(src) > 8000 {
+		src = src[:8000]
+	}
+	return bytes.IndexByte(src, 0) < 0
+}
+
+// ServeFileHTML returns the html of given source of the file of given name,
+// highlighted in given formats, with line numbers
+func ServeFileHTML(fname, src string, fm HiFormats) string {
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	var spans [][]HiSpan
+	if lx := HiLexerFor(fname, lines[0]); lx != nil {
+		hs := &HiState{Tokenize: ChromaTokenizer(lx)}
+		hs.Update(lines)
+		spans = hs.Spans
+	}
+	return HiHTML(lines, spans, fm, 1)
+}
+
+// ServeHit is a match of a text search of gide serve
+type ServeHit struct {
+	Path string `json:"path"`
+	Ln   int    `json:"ln"`
+	Ch   int    `json:"ch"`
+	Len  int    `json:"len"`
+	Text string `json:"text"`
+}
+
+// ServeSym is a symbol found by a symbol search of gide serve
+type ServeSym struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+	Path   string `json:"path"`
+	Ln     int    `json:"ln"`
+}
+
+// authOk returns true if given request has the access token of the server,
+// in its url or its cookie -- one in the url is kept in the cookie
+func (sv *Server) authOk(w http.ResponseWriter, r *http.Request) bool {
+	if sv.Token == "" {
+		return true
+	}
+	eq := func(t string) bool {
+		return subtle.ConstantTimeCompare([]byte(t), []byte(sv.Token)) == 1
+	}
+	if t := r.URL.Query().Get("token"); t != "" && eq(t) {
+		http.SetCookie(w, &http.Cookie{Name: ServeTokenCookie, Value: sv.Token, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+		return true
+	}
+	if c, err := r.Cookie(ServeTokenCookie); err == nil && eq(c.Value) {
+		return true
+	}
+	return false
+}
+
+// ServeHTTP serves given request, if it has the access token
+func (sv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !sv.authOk(w, r) {
+		http.Error(w, "gide serve: open the url with the token printed at startup", http.StatusUnauthorized)
+		return
+	}
+	sv.mux.ServeHTTP(w, r)
+}
+
+// serveJSON writes given value as the json response
+func serveJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// rel returns the path of given full path relative to the root, slash
+// separated
+func (sv *Server) rel(path string) string {
+	rel, err := filepath.Rel(sv.Root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// serveIndex serves the page of the browser ui
+func (sv *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(serveIndexHTML))
+}
+
+// serveFiles serves the files of the project, with the name of the project
+// and whether edits are allowed
+func (sv *Server) serveFiles(w http.ResponseWriter, r *http.Request) {
+	serveJSON(w, map[string]interface{}{"name": filepath.Base(sv.Root), "edit": sv.Edit, "files": ServeFiles(sv.Root, ServeMaxFiles)})
+}
+
+// readFile returns the contents of the file at the path of given request,
+// with its real full path, or writes the error response -- only regular
+// files within the root are read, even through symbolic links
+func (sv *Server) readFile(w http.ResponseWriter, r *http.Request) (string, []byte, bool) {
+	path, err := ServePath(sv.Root, r.FormValue("path"))
+	if err == nil {
+		path, err = ServeResolve(sv.Root, path)
+	}
+	if os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return "", nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return "", nil, false
+	}
+	fi, err := os.Lstat(path)
+	if err != nil || !fi.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return "", nil, false
+	}
+	if fi.Size() > ServeMaxSize {
+		http.Error(w, fmt.Sprintf("file too large to show: %v bytes", fi.Size()), http.StatusRequestEntityTooLarge)
+		return "", nil, false
+	}
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return "", nil, false
+	}
+	return path, src, true
+}
+
+// serveFile serves the highlighted html and text of a file
+func (sv *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	path, src, ok := sv.readFile(w, r)
+	if !ok {
+		return
+	}
+	res := map[string]interface{}{"path": sv.rel(path), "text": ServeIsText(src)}
+	if ServeIsText(src) {
+		res["html"] = ServeFileHTML(path, string(src), HiStyleFormats(sv.Style))
+		res["src"] = string(src)
+	}
+	serveJSON(w, res)
+}
+
+// serveSearch serves the matches of a text search of the files of the
+// project, with giv.FileSearch, as in Find
+func (sv *Server) serveSearch(w http.ResponseWriter, r *http.Request) {
+	find := r.FormValue("q")
+	ic := r.FormValue("ic") != ""
+	hits := []ServeHit{}
+	if find == "" {
+		serveJSON(w, hits)
+		return
+	}
+	for _, rel := range ServeFiles(sv.Root, ServeMaxFiles) {
+		if len(hits) >= ServeMaxHits {
+			break
+		}
+		path := filepath.Join(sv.Root, filepath.FromSlash(rel))
+		if fi, err := os.Stat(path); err != nil || fi.Size() > ServeMaxSize {
+			continue
+		}
+		src, err := ioutil.ReadFile(path)
+		if err != nil || !ServeIsText(src) {
+			continue
+		}
+		cnt, matches := giv.FileSearch(path, []byte(find), ic)
+		if cnt == 0 {
+			continue
+		}
+		lines := strings.Split(string(src), "\n")
+		for _, mt := range matches {
+			st := mt.Reg.Start
+			if st.Ln >= len(lines) || len(hits) >= ServeMaxHits {
+				continue
+			}
+			hits = append(hits, ServeHit{Path: rel, Ln: st.Ln + 1, Ch: st.Ch, Len: len([]rune(find)), Text: strings.TrimRight(lines[st.Ln], "\r")})
+		}
+	}
+	serveJSON(w, hits)
+}
+
+// serveSyms serves the symbols of the project matching a pattern, from its
+// symbol index, as in Symbol Search
+func (sv *Server) serveSyms(w http.ResponseWriter, r *http.Request) {
+	syms := []ServeSym{}
+	if pat := r.FormValue("q"); pat != "" {
+		for _, s := range sv.Syms.Search(pat, 200) {
+			syms = append(syms, ServeSym{Name: s.Name, Kind: s.Kind, Detail: s.Detail, Path: sv.rel(s.File), Ln: s.Ln + 1})
+		}
+	}
+	serveJSON(w, syms)
+}
+
+// serveSave saves the edited text of an existing file, if edits are
+// allowed, keeping its permissions, and re-indexes its symbols
+func (sv *Server) serveSave(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "save needs a POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if !sv.Edit {
+		http.Error(w, "gide serve is read-only: run it with -edit to save edits", http.StatusForbidden)
+		return
+	}
+	path, src, ok := sv.readFile(w, r)
+	if !ok {
+		return
+	}
+	if !ServeIsText(src) {
+		http.Error(w, "only text files can be edited", http.StatusForbidden)
+		return
+	}
+	fi, err := os.Lstat(path)
+	if err != nil || !fi.Mode().IsRegular() {
+		http.Error(w, "only regular files can be edited", http.StatusForbidden)
+		return
+	}
+	txt := []byte(r.FormValue("src"))
+	if err := ioutil.WriteFile(path, txt, fi.Mode().Perm()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if SymIndexFileOk(path) {
+		sv.Syms.IndexFile(path, txt)
+	}
+	serveJSON(w, map[string]interface{}{"path": sv.rel(path), "size": len(txt)})
+}
+
+// serveIndexHTML is the page of the browser ui of gide serve: a filterable
+// list of the files, the highlighted view of the selected one, with an
+// editor when edits are allowed, and text and symbol search
+const serveIndexHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gide</title>
+<style>
+body { margin: 0; font-family: sans-serif; font-size: 14px; display: flex; height: 100vh; }
+#side { width: 28em; display: flex; flex-direction: column; border-right: 1px solid #ccc; }
+#side input { margin: 4px; padding: 4px; }
+#list { flex: 1; overflow: auto; font-family: monospace; }
+#list div { padding: 1px 6px; cursor: pointer; white-space: nowrap; }
+#list div:hover { background: #eef; }
+#list .dim { color: #888; }
+#main { flex: 1; display: flex; flex-direction: column; min-width: 0; }
+#bar { padding: 6px; border-bottom: 1px solid #ccc; }
+#bar button { margin-left: 8px; }
+#view { flex: 1; overflow: auto; }
+#view pre { margin: 0; padding: 6px; min-height: 100%; box-sizing: border-box; }
+#edit { flex: 1; font-family: monospace; font-size: 13px; display: none; }
+</style>
+</head>
+<body>
+<div id="side">
+<input id="filter" placeholder="filter files">
+<input id="search" placeholder="search text (Enter)">
+<input id="syms" placeholder="search symbols (Enter)">
+<div id="list"></div>
+</div>
+<div id="main">
+<div id="bar"><b id="name"></b> <span id="path"></span>
+<button id="editb" hidden>Edit</button><button id="saveb" hidden>Save</button><button id="cancelb" hidden>Cancel</button>
+<span id="status"></span></div>
+<div id="view"></div>
+<textarea id="edit" spellcheck="false"></textarea>
+</div>
+<script>
+var files = [], cur = null, edit = false;
+function $(id) { return document.getElementById(id); }
+function get(url) { return fetch(url).then(function(r) { if (!r.ok) { return r.text().then(function(t) { throw t; }); } return r.json(); }); }
+function status(s) { $("status").textContent = s; }
+function row(txt, dim, fn) {
+	var d = document.createElement("div");
+	d.textContent = txt;
+	if (dim) { var s = document.createElement("span"); s.className = "dim"; s.textContent = "  " + dim; d.appendChild(s); }
+	d.onclick = fn;
+	$("list").appendChild(d);
+}
+function showFiles() {
+	var f = $("filter").value.toLowerCase();
+	$("list").innerHTML = "";
+	files.filter(function(p) { return p.toLowerCase().indexOf(f) >= 0; }).slice(0, 2000).forEach(function(p) {
+		row(p, "", function() { open(p, 0); });
+	});
+}
+function editing(on) {
+	$("edit").style.display = on ? "block" : "none";
+	$("view").style.display = on ? "none" : "block";
+	$("editb").hidden = on || !edit || !cur || !cur.text;
+	$("saveb").hidden = $("cancelb").hidden = !on;
+}
+function open(p, ln) {
+	get("/api/file?path=" + encodeURIComponent(p)).then(function(f) {
+		cur = f;
+		$("path").textContent = f.path;
+		$("view").innerHTML = f.text ? f.html : "<pre>(binary file)</pre>";
+		editing(false);
+		status("");
+		if (ln > 0) {
+			var lh = parseFloat(getComputedStyle($("view").querySelector("pre")).lineHeight) || 16;
+			$("view").scrollTop = (ln - 5) * lh;
+		}
+		location.hash = f.path + (ln > 0 ? ":" + ln : "");
+	}).catch(status);
+}
+$("filter").oninput = showFiles;
+$("search").onkeydown = function(e) {
+	if (e.key != "Enter") { return; }
+	var q = $("search").value;
+	if (!q) { showFiles(); return; }
+	get("/api/search?ic=1&q=" + encodeURIComponent(q)).then(function(hs) {
+		$("list").innerHTML = "";
+		hs.forEach(function(h) { row(h.path + ":" + h.ln, h.text.trim(), function() { open(h.path, h.ln); }); });
+		status(hs.length + " matches");
+	}).catch(status);
+};
+$("syms").onkeydown = function(e) {
+	if (e.key != "Enter") { return; }
+	var q = $("syms").value;
+	if (!q) { showFiles(); return; }
+	get("/api/syms?q=" + encodeURIComponent(q)).then(function(ss) {
+		$("list").innerHTML = "";
+		ss.forEach(function(s) { row(s.name, s.kind + "  " + s.path + ":" + s.ln, function() { open(s.path, s.ln); }); });
+		status(ss.length + " symbols");
+	}).catch(status);
+};
+$("editb").onclick = function() { $("edit").value = cur.src; editing(true); };
+$("cancelb").onclick = function() { editing(false); };
+$("saveb").onclick = function() {
+	var fd = new URLSearchParams();
+	fd.set("path", cur.path);
+	fd.set("src", $("edit").value);
+	fetch("/api/save", {method: "POST", body: fd}).then(function(r) {
+		if (!r.ok) { return r.text().then(function(t) { throw t; }); }
+		open(cur.path, 0);
+		status("saved");
+	}).catch(status);
+};
+get("/api/files").then(function(r) {
+	files = r.files;
+	edit = r.edit;
+	$("name").textContent = r.name + (edit ? "" : " (read-only)");
+	document.title = r.name + " - gide";
+	history.replaceState(null, "", "/" + location.hash);
+	showFiles();
+	var h = decodeURIComponent(location.hash.slice(1));
+	if (h) { var i = h.lastIndexOf(":"); if (i > 0) { open(h.slice(0, i), +h.slice(i + 1)); } else { open(h, 0); } }
+}).catch(status);
+</script>
+</body>
+</html>
+`
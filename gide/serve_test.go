@@ -0,0 +1,236 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServePath(t *testing.T) {
+	root := filepath.FromSlash("/proj")
+	for _, rel := range []string{"main.go", "/main.go", "cmd/gide/gide.go"} {
+		got, err := ServePath(root, rel)
+		want := filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(rel, "/")))
+		if err != nil || got != want {
+			t.Errorf("%v: got %v, %v, want %v", rel, got, err, want)
+		}
+	}
+	for _, rel := range []string{"", "..", "../etc/passwd", "a/../../b", "a//b", "./a", ".git/config", "a/.env"} {
+		if got, err := ServePath(root, rel); err == nil {
+			t.Errorf("%v: got %v, want an error", rel, got)
+		}
+	}
+}
+
+func TestServeResolve(t *testing.T) {
+	dir := serveTestDir(t)
+	defer os.RemoveAll(dir)
+	out, err := ioutil.TempDir("", "gide-serve-out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(out)
+	ioutil.WriteFile(filepath.Join(out, "secret.txt"), []byte("secret\n"), 0644)
+	links := map[string]string{
+		"in.go":      filepath.Join(dir, "main.go"),
+		"out.txt":    filepath.Join(out, "secret.txt"),
+		"outdir":     out,
+		"git-config": filepath.Join(dir, ".git", "config"),
+	}
+	for nm, to := range links {
+		if err := os.Symlink(to, filepath.Join(dir, nm)); err != nil {
+			t.Skipf("symbolic links not supported: %v", err)
+		}
+	}
+	if got, err := ServeResolve(dir, filepath.Join(dir, "in.go")); err != nil || filepath.Base(got) != "main.go" {
+		t.Errorf("link within the root: got %v, %v", got, err)
+	}
+	for _, rel := range []string{"out.txt", "outdir/secret.txt", "git-config"} {
+		if got, err := ServeResolve(dir, filepath.Join(dir, filepath.FromSlash(rel))); err == nil || os.IsNotExist(err) {
+			t.Errorf("%v: got %v, %v, want not served", rel, got, err)
+		}
+	}
+	if _, err := ServeResolve(dir, filepath.Join(dir, "nope.go")); !os.IsNotExist(err) {
+		t.Errorf("missing file: got %v", err)
+	}
+
+	esv := NewServer(dir, true, "tok")
+	esv.Syms.Wait()
+	req := httptest.NewRequest("POST", "/api/save", strings.NewReader(url.Values{"path": {"out.txt"}, "src": {"pwned\n"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: ServeTokenCookie, Value: "tok"})
+	rec := httptest.NewRecorder()
+	esv.ServeHTTP(rec, req)
+	if b, _ := ioutil.ReadFile(filepath.Join(out, "secret.txt")); rec.Code != http.StatusForbidden || string(b) != "secret\n" {
+		t.Errorf("save through a link out of the root: got %v, file is %q", rec.Code, b)
+	}
+}
+
+// serveTestDir returns a new project directory for the tests of gide serve
+func serveTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "gide-serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for nm, s := range map[string]string{
+		"main.go":             "package main\n\nfunc main() {}\n",
+		"README.md":           "# proj\n",
+		"sub/util.go":         "package sub\n\nfunc Helper() int { return 1 }\n",
+		"sub/data.bin":        "\x00\x01\x02",
+		"sub/old.go~":         "backup\n",
+		".git/config":         "[core]\n",
+		"vendor/x/x.go":       "package x\n",
+		"_scratch/a.txt":      "a\n",
+		"sub/.hidden":         "secret\n",
+		"sub/deep/more/n.txt": "n\n",
+	} {
+		fn := filepath.Join(dir, filepath.FromSlash(nm))
+		os.MkdirAll(filepath.Dir(fn), 0755)
+		if err := ioutil.WriteFile(fn, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestServeFiles(t *testing.T) {
+	dir := serveTestDir(t)
+	defer os.RemoveAll(dir)
+	want := []string{"README.md", "main.go", "sub/data.bin", "sub/deep/more/n.txt", "sub/util.go"}
+	if got := ServeFiles(dir, 100); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := ServeFiles(dir, 2); len(got) != 2 {
+		t.Errorf("max 2: got %v", got)
+	}
+}
+
+func TestServeIsText(t *testing.T) {
+	if !ServeIsText([]byte("package main\n")) || ServeIsText([]byte("ab\x00cd")) {
+		t.Error("wrong")
+	}
+	if !ServeIsText(append([]byte(strings.Repeat("a", 9000)), 0)) {
+		t.Error("a NUL after the start should not count")
+	}
+}
+
+func TestServer(t *testing.T) {
+	dir := serveTestDir(t)
+	defer os.RemoveAll(dir)
+	sv := NewServer(dir, false, "tok")
+	sv.Syms.Wait()
+	ts := httptest.NewServer(sv)
+	defer ts.Close()
+
+	get := func(path string, cookie bool) (int, string) {
+		req, _ := http.NewRequest("GET", ts.URL+path, nil)
+		if cookie {
+			req.AddCookie(&http.Cookie{Name: ServeTokenCookie, Value: "tok"})
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+	if code, _ := get("/api/files", false); code != http.StatusUnauthorized {
+		t.Errorf("no token: got %v", code)
+	}
+	if code, _ := get("/api/files?token=bad", false); code != http.StatusUnauthorized {
+		t.Errorf("bad token: got %v", code)
+	}
+	resp, err := http.Get(ts.URL + "/?token=tok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(resp.Cookies()) != 1 || resp.Cookies()[0].Value != "tok" {
+		t.Errorf("token in url: got %v, cookies %v", resp.StatusCode, resp.Cookies())
+	}
+
+	code, body := get("/api/files", true)
+	var fl struct {
+		Name  string
+		Edit  bool
+		Files []string
+	}
+	if err := json.Unmarshal([]byte(body), &fl); err != nil || code != http.StatusOK {
+		t.Fatalf("files: %v %v %v", code, err, body)
+	}
+	if fl.Name != filepath.Base(dir) || fl.Edit || len(fl.Files) != 5 {
+		t.Errorf("files: got %+v", fl)
+	}
+
+	code, body = get("/api/file?path=sub/util.go", true)
+	var f struct {
+		Path string
+		Text bool
+		HTML string
+		Src  string
+	}
+	if err := json.Unmarshal([]byte(body), &f); err != nil || code != http.StatusOK {
+		t.Fatalf("file: %v %v %v", code, err, body)
+	}
+	if f.Path != "sub/util.go" || !f.Text || !strings.Contains(f.Src, "Helper") || !strings.HasPrefix(f.HTML, "<pre") {
+		t.Errorf("file: got %+v", f)
+	}
+	if code, body = get("/api/file?path=sub/data.bin", true); code != http.StatusOK || strings.Contains(body, `"html"`) {
+		t.Errorf("binary file: got %v %v", code, body)
+	}
+	for _, p := range []string{"../" + filepath.Base(dir) + "/main.go", ".git/config", "sub/.hidden"} {
+		if code, _ = get("/api/file?path="+url.QueryEscape(p), true); code != http.StatusForbidden {
+			t.Errorf("%v: got %v", p, code)
+		}
+	}
+	if code, _ = get("/api/file?path=nope.go", true); code != http.StatusNotFound {
+		t.Errorf("missing file: got %v", code)
+	}
+
+	code, body = get("/api/syms?q=Helper", true)
+	var ss []ServeSym
+	if err := json.Unmarshal([]byte(body), &ss); err != nil || len(ss) != 1 || ss[0].Path != "sub/util.go" || ss[0].Ln != 3 {
+		t.Errorf("syms: got %v %v", code, body)
+	}
+
+	save := func(sv *Server, path, src string) int {
+		req := httptest.NewRequest("POST", "/api/save", strings.NewReader(url.Values{"path": {path}, "src": {src}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: ServeTokenCookie, Value: sv.Token})
+		rec := httptest.NewRecorder()
+		sv.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := save(sv, "main.go", "changed\n"); code != http.StatusForbidden {
+		t.Errorf("read-only save: got %v", code)
+	}
+	esv := NewServer(dir, true, "tok")
+	esv.Syms.Wait()
+	if code := save(esv, "sub/util.go", "package sub\n\nfunc Other() {}\n"); code != http.StatusOK {
+		t.Errorf("save: got %v", code)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, "sub", "util.go")); !strings.Contains(string(b), "Other") {
+		t.Errorf("save: file is %q", b)
+	}
+	if len(esv.Syms.Search("Other", 10)) != 1 || len(esv.Syms.Search("Helper", 10)) != 0 {
+		t.Error("save: symbols not re-indexed")
+	}
+	if code := save(esv, "new.go", "package main\n"); code != http.StatusNotFound {
+		t.Errorf("save of a new file: got %v", code)
+	}
+	if code := save(esv, "sub/data.bin", "text"); code != http.StatusForbidden {
+		t.Errorf("save of a binary file: got %v", code)
+	}
+}
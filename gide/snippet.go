@@ -0,0 +1,247 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/gi/svg"
+)
+
+// SnippetFontSize is the font size of the code in snippet images, in pixels
+var SnippetFontSize float32 = 14
+
+// SnippetScale is how much snippets saved as PNG are scaled up from their
+// size in pixels, so they stay sharp on high-dpi screens
+var SnippetScale float32 = 2
+
+// SnippetTabSize is the number of columns the tabs of snippets are expanded
+// to
+var SnippetTabSize = 4
+
+// sizes of the parts of a snippet image, in ems of its font size
+const (
+	snippetCharW  = 0.6  // width of a character of the monospace font
+	snippetLineH  = 1.45 // height of a line
+	snippetPad    = 1.5  // padding around the code
+	snippetChrome = 2.25 // height of the title bar of the window chrome
+	snippetRadius = 0.5  // radius of the corners of the window
+)
+
+// snippetDots are the colors of the close, minimize and maximize buttons of
+// the window chrome of snippets
+var snippetDots = []string{"#ff5f56", "#ffbd2e", "#27c93f"}
+
+// snf returns given number formatted for svg, to two decimals
+func snf(v float32) string {
+	return strconv.FormatFloat(math.Round(float64(v)*100)/100, 'f', -1, 64)
+}
+
+// snippetWord is a run of non-space characters of a line of a snippet, at
+// given column, in given class
+type snippetWord struct {
+	Col int
+	Cls string
+	Txt string
+}
+
+// snippetWords returns the runs of non-space characters of given line, with
+// their columns with tabs expanded, in the classes of given spans, and the
+// column of the end of the line
+func snippetWords(line string, spans []HiSpan) ([]snippetWord, int) {
+	var ws []snippetWord
+	col := 0
+	hiLineSpans(line, spans, func(cls, s string) {
+		var cur []rune
+		st := col
+		flush := func() {
+			if len(cur) > 0 {
+				ws = append(ws, snippetWord{Col: st, Cls: cls, Txt: string(cur)})
+				cur = nil
+			}
+		}
+		for _, r := range s {
+			switch r {
+			case '\t':
+				flush()
+				col += SnippetTabSize - col%SnippetTabSize
+			case ' ':
+				flush()
+				col++
+			default:
+				if len(cur) == 0 {
+					st = col
+				}
+				cur = append(cur, r)
+				col++
+			}
+		}
+		flush()
+	})
+	return ws, col
+}
+
+// SnippetSVG returns an svg image of given lines, highlighted by given spans
+// in given formats, with line numbers starting at first, or none if first is
+// 0, the indentation they all have removed, and optionally the chrome of a
+// window around them, with given title -- for sharing code as an image
+func SnippetSVG(lines []string, spans [][]HiSpan, fm HiFormats, first int, chrome bool, title string) string {
+	fs := SnippetFontSize
+	cw, lh, pad := fs*snippetCharW, fs*snippetLineH, fs*snippetPad
+	words := make([][]snippetWord, len(lines))
+	ind, cols := -1, 0
+	for i, l := range lines {
+		var sps []HiSpan
+		if i < len(spans) {
+			sps = spans[i]
+		}
+		ws, ed := snippetWords(l, sps)
+		words[i] = ws
+		if len(ws) == 0 {
+			continue
+		}
+		if ind < 0 || ws[0].Col < ind {
+			ind = ws[0].Col
+		}
+		if ed > cols {
+			cols = ed
+		}
+	}
+	if ind < 0 {
+		ind = 0
+	}
+	cols -= ind
+	lnw := 0
+	if first > 0 {
+		lnw = len(fmt.Sprint(first+len(lines)-1)) + 2
+	}
+	top := float32(0)
+	if chrome {
+		top = fs * snippetChrome
+	}
+	w := 2*pad + float32(cols+lnw)*cw
+	h := top + 2*pad + float32(len(lines))*lh
+	base := fm.Of("")
+	if base.Bg == "" {
+		base.Bg = "#ffffff"
+	}
+	if base.Color == "" {
+		base.Color = "#000000"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n", snf(w), snf(h), snf(w), snf(h))
+	fmt.Fprintf(&b, `<rect x="0" y="0" width="%s" height="%s" rx="%s" ry="%s" fill="%s"/>`+"\n", snf(w), snf(h), snf(fs*snippetRadius), snf(fs*snippetRadius), base.Bg)
+	fmt.Fprintf(&b, `<g font-family="monospace" font-size="%s" fill="%s">`+"\n", snf(fs), base.Color)
+	text := func(x, y float32, f HiFormat, s string) {
+		fmt.Fprintf(&b, `<text x="%s" y="%s"`, snf(x), snf(y))
+		if f.Color != "" && f.Color != base.Color {
+			fmt.Fprintf(&b, ` fill="%s"`, f.Color)
+		}
+		if f.Bold {
+			b.WriteString(` font-weight="bold"`)
+		}
+		if f.Italic {
+			b.WriteString(` font-style="italic"`)
+		}
+		if f.Underline {
+			b.WriteString(` text-decoration="underline"`)
+		}
+		fmt.Fprintf(&b, `>%s</text>`+"\n", html.EscapeString(s))
+	}
+	if chrome {
+		cy := top / 2
+		for i, c := range snippetDots {
+			fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="%s" fill="%s"/>`+"\n", snf(pad+float32(i)*fs*1.25), snf(cy), snf(fs*0.4), c)
+		}
+		if title != "" {
+			tx := (w - float32(len([]rune(title)))*cw) / 2
+			if lo := pad + fs*3.5; tx < lo {
+				tx = lo
+			}
+			text(tx, cy+fs*0.35, HiFormat{Color: fm.Of("ln").Color}, title)
+		}
+	}
+	lnf := fm.Of("ln")
+	for i, ws := range words {
+		y := top + pad + float32(i)*lh
+		by := y + (lh+fs*0.7)/2 // baseline
+		if first > 0 {
+			text(pad, by, lnf, fmt.Sprintf("%*d", lnw-2, first+i))
+		}
+		for _, wd := range ws {
+			x := pad + float32(wd.Col-ind+lnw)*cw
+			f := fm.Of(wd.Cls)
+			if f.Bg != "" && f.Bg != base.Bg {
+				fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n", snf(x), snf(y), snf(float32(len([]rune(wd.Txt)))*cw), snf(lh), f.Bg)
+			}
+			text(x, by, f, wd.Txt)
+		}
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return b.String()
+}
+
+// SnippetPNG renders given svg image with gi's svg renderer, scaled by
+// SnippetScale, and saves it as a PNG file of given path
+func SnippetPNG(src string, path string) error {
+	sv := &svg.SVG{}
+	sv.InitName(sv, "snippet")
+	if err := sv.ReadXML(strings.NewReader(src)); err != nil {
+		return err
+	}
+	sv.Norm = true
+	sv.Resize(image.Point{int(sv.ViewBox.Size.X * SnippetScale), int(sv.ViewBox.Size.Y * SnippetScale)})
+	sv.FullRender2DTree()
+	return sv.SavePNG(path)
+}
+
+// ExportSnippet saves the selected text of the active view, or the cursor
+// line, as an image with its syntax highlighting in given highlighting
+// style, as PNG or SVG by the extension of given file name, optionally with
+// line numbers and the chrome of a window around it, titled with the name of
+// the file -- for sharing code on social media or in docs
+func (ge *Gide) ExportSnippet(filename gi.FileName, style histyle.StyleName, lineNos, chrome bool) error {
+	lines, spans, first, ok := ge.hiSelLines()
+	if !ok {
+		return nil
+	}
+	if !lineNos {
+		first = 0
+	}
+	if style == "" {
+		style = Prefs.HiStyle
+	}
+	title := ""
+	if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil && tv.Buf.Filename != "" {
+		title = filepath.Base(string(tv.Buf.Filename))
+	}
+	src := SnippetSVG(lines, spans, HiStyleFormats(style), first, chrome, title)
+	fnm := string(filename)
+	var err error
+	switch strings.ToLower(filepath.Ext(fnm)) {
+	case ".svg":
+		err = ioutil.WriteFile(fnm, []byte(src), 0644)
+	case ".png":
+		err = SnippetPNG(src, fnm)
+	default:
+		err = fmt.Errorf("the file name must end in .png or .svg: %v", fnm)
+	}
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Export Snippet", Prompt: err.Error()}, true, false, nil, nil)
+		return err
+	}
+	ge.SetStatus(fmt.Sprintf("Exported %v lines as an image to %v", len(lines), fnm))
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestSnippetWords(t *testing.T) {
+	ws, ed := snippetWords("\tx := a<b // c d", []HiSpan{{St: 1, Ed: 2, Cls: "n"}, {St: 9, Ed: 15, Cls: "c1"}})
+	want := []snippetWord{{4, "n", "x"}, {6, "", ":="}, {9, "", "a<b"}, {13, "c1", "//"}, {16, "c1", "c"}, {18, "", "d"}}
+	if len(ws) != len(want) || ed != 19 {
+		t.Fatalf("got %+v %v", ws, ed)
+	}
+	for i := range ws {
+		if ws[i] != want[i] {
+			t.Errorf("word %v: got %+v, want %+v", i, ws[i], want[i])
+		}
+	}
+}
+
+func TestSnippetSVG(t *testing.T) {
+	fm := HiFormats{"bg": {Color: "#111111", Bg: "#eeeeee"}, "k": {Color: "#0000ff", Bold: true}}
+	lines := []string{"\tif a < b {", "\t\treturn", "\t}"}
+	spans := [][]HiSpan{{{St: 1, Ed: 3, Cls: "k"}}, {{St: 2, Ed: 8, Cls: "k"}}, nil}
+	src := SnippetSVG(lines, spans, fm, 9, true, "main.go")
+	d := xml.NewDecoder(strings.NewReader(src))
+	for {
+		if _, err := d.Token(); err != nil {
+			if err.Error() != "EOF" {
+				t.Fatalf("invalid svg: %v\n%s", err, src)
+			}
+			break
+		}
+	}
+	for _, s := range []string{`fill="#eeeeee"`, `fill="#0000ff" font-weight="bold">if</text>`, `>a</text>`, `>&lt;</text>`, `> 9</text>`, `>11</text>`, `>main.go</text>`, `<circle`} {
+		if !strings.Contains(src, s) {
+			t.Errorf("missing %q in:\n%s", s, src)
+		}
+	}
+	// indentation removed: "if" is right after the line numbers
+	if !strings.Contains(src, `<text x="54.6" y=`) {
+		t.Errorf("indentation not removed:\n%s", src)
+	}
+	if src2 := SnippetSVG(lines, spans, fm, 0, false, ""); strings.Contains(src2, "<circle") || strings.Contains(src2, "> 9</text>") {
+		t.Errorf("chrome or line numbers without them:\n%s", src2)
+	}
+}
@@ -0,0 +1,602 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// Snippet is a template of text that is inserted by typing its prefix and
+// then Tab, written in the TextMate / LSP snippet syntax: $1, $2 are tab
+// stops visited in order by Tab, ${1:text} is a tab stop with placeholder
+// text, selected when visited, $0 is where the cursor ends up, ${1|a,b|}
+// inserts the first choice, and $TM_FILENAME etc are variables -- a tab stop
+// used more than once is edited at all of its places together
+type Snippet struct {
+	Prefix string    `desc:"word that is expanded into the snippet when Tab is pressed just after it"`
+	Desc   string    `desc:"brief description"`
+	Langs  LangNames `desc:"language(s) that the snippet applies to -- leave empty if it applies to any"`
+	Body   string    `width:"60" desc:"text of the snippet, with tab stops etc -- lines after the first are indented like the line of the prefix, and a tab at the start of a line is one level of indentation"`
+}
+
+// Label satisfies the Labeler interface
+func (sn Snippet) Label() string {
+	return sn.Prefix
+}
+
+// LangMatch returns true if the given languages match those of the snippet,
+// or snippet has no language restrictions
+func (sn *Snippet) LangMatch(langs LangNames) bool {
+	if len(sn.Langs) == 0 {
+		return true
+	}
+	for _, sln := range sn.Langs {
+		for _, lnm := range langs {
+			if sln == lnm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Snippets is a list of snippets, for all languages
+type Snippets []Snippet
+
+var KiT_Snippets = kit.Types.AddType(&Snippets{}, SnippetsProps)
+
+// AvailSnippets are available snippets.  can be loaded / saved / edited with
+// preferences.  This is set to StdSnippets at startup.
+var AvailSnippets Snippets
+
+func init() {
+	AvailSnippets.CopyFrom(StdSnippets)
+}
+
+// SnippetByPrefix returns the first snippet with given prefix for given
+// languages
+func (lt *Snippets) SnippetByPrefix(prefix string, langs LangNames) (*Snippet, bool) {
+	for i := range *lt {
+		sn := &((*lt)[i])
+		if sn.Prefix == prefix && sn.LangMatch(langs) {
+			return sn, true
+		}
+	}
+	return nil, false
+}
+
+// PrefsSnippetsFileName is the name of the preferences file in App prefs
+// directory for saving / loading the default AvailSnippets
+var PrefsSnippetsFileName = "snippets_prefs.json"
+
+// OpenJSON opens snippets from a JSON-formatted file.
+func (lt *Snippets) OpenJSON(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	*lt = make(Snippets, 0, 20) // reset
+	return json.Unmarshal(b, lt)
+}
+
+// SaveJSON saves snippets to a JSON-formatted file.
+func (lt *Snippets) SaveJSON(filename gi.FileName) error {
+	b, err := json.MarshalIndent(lt, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		gi.PromptDialog(nil, gi.DlgOpts{Title: "Could not Save to File", Prompt: err.Error()}, true, false, nil, nil)
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenPrefs opens Snippets from App standard prefs directory, using PrefsSnippetsFileName
+func (lt *Snippets) OpenPrefs() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PrefsSnippetsFileName)
+	AvailSnippetsChanged = false
+	return lt.OpenJSON(gi.FileName(pnm))
+}
+
+// SavePrefs saves Snippets to App standard prefs directory, using PrefsSnippetsFileName
+func (lt *Snippets) SavePrefs() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PrefsSnippetsFileName)
+	AvailSnippetsChanged = false
+	return lt.SaveJSON(gi.FileName(pnm))
+}
+
+// CopyFrom copies snippets from given other list
+func (lt *Snippets) CopyFrom(cp Snippets) {
+	*lt = make(Snippets, 0, len(cp)) // reset
+	b, err := json.Marshal(cp)
+	if err != nil {
+		fmt.Printf("json err: %v\n", err.Error())
+	}
+	json.Unmarshal(b, lt)
+}
+
+// AvailSnippetsChanged is used to update toolbars via following menu, toolbar
+// props update methods -- not accurate if editing any other list but works
+// for now..
+var AvailSnippetsChanged = false
+
+// SnippetsProps define the ToolBar and MenuBar for TableView of Snippets
+var SnippetsProps = ki.Props{
+	"MainMenu": ki.PropSlice{
+		{"AppMenu", ki.BlankProp{}},
+		{"File", ki.PropSlice{
+			{"OpenPrefs", ki.Props{}},
+			{"SavePrefs", ki.Props{
+				"shortcut": "Command+S",
+				"updtfunc": giv.ActionUpdateFunc(func(sni interface{}, act *gi.Action) {
+					act.SetActiveState(AvailSnippetsChanged && sni.(*Snippets) == &AvailSnippets)
+				}),
+			}},
+			{"sep-file", ki.BlankProp{}},
+			{"OpenJSON", ki.Props{
+				"label":    "Open from file",
+				"desc":     "You can save and open snippets to / from files to share, experiment, transfer, etc",
+				"shortcut": "Command+O",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"SaveJSON", ki.Props{
+				"label": "Save to file",
+				"desc":  "You can save and open snippets to / from files to share, experiment, transfer, etc",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+		}},
+		{"Edit", "Copy Cut Paste Dupe"},
+		{"Window", "Windows"},
+	},
+	"ToolBar": ki.PropSlice{
+		{"SavePrefs", ki.Props{
+			"desc": "saves Snippets to App standard prefs directory, in file snippets_prefs.json, which will be loaded automatically at startup)",
+			"icon": "file-save",
+			"updtfunc": giv.ActionUpdateFunc(func(sni interface{}, act *gi.Action) {
+				act.SetActiveState(AvailSnippetsChanged && sni.(*Snippets) == &AvailSnippets)
+			}),
+		}},
+		{"sep-file", ki.BlankProp{}},
+		{"OpenJSON", ki.Props{
+			"label": "Open from file",
+			"icon":  "file-open",
+			"desc":  "You can save and open snippets to / from files to share, experiment, transfer, etc",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+		{"SaveJSON", ki.Props{
+			"label": "Save to file",
+			"icon":  "file-save",
+			"desc":  "You can save and open snippets to / from files to share, experiment, transfer, etc",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+	},
+}
+
+// StdSnippets is the original compiled-in set of standard snippets.
+var StdSnippets = Snippets{
+	{"func", "function", LangNames{"Go"}, "func ${1:name}(${2}) ${3:error} {\n\t$0\n}"},
+	{"meth", "method", LangNames{"Go"}, "func (${1:r} *${2:Type}) ${3:name}(${4}) {\n\t$0\n}"},
+	{"iferr", "return error if not nil", LangNames{"Go"}, "if err != nil {\n\treturn ${1:err}\n}\n$0"},
+	{"for", "for loop over an index", LangNames{"Go"}, "for ${1:i} := 0; $1 < ${2:n}; $1++ {\n\t$0\n}"},
+	{"forr", "for loop over a range", LangNames{"Go"}, "for ${1:_}, ${2:v} := range ${3:list} {\n\t$0\n}"},
+	{"st", "struct type", LangNames{"Go"}, "// ${1:Name} $2\ntype $1 struct {\n\t$0\n}"},
+	{"test", "test function", LangNames{"Go"}, "func Test${1:Name}(t *testing.T) {\n\t$0\n}"},
+	{"main", "main package and function", LangNames{"Go"}, "package main\n\nfunc main() {\n\t$0\n}"},
+	{"def", "function", LangNames{"Python"}, "def ${1:name}(${2}):\n\t${0:pass}"},
+	{"class", "class", LangNames{"Python"}, "class ${1:Name}(${2:object}):\n\tdef __init__(self${3}):\n\t\t${0:pass}"},
+	{"ifmain", "run as a script", LangNames{"Python"}, "if __name__ == \"__main__\":\n\t${0:main()}"},
+	{"for", "for loop over an index", LangNames{"C", "C++"}, "for (int ${1:i} = 0; $1 < ${2:n}; $1++) {\n\t$0\n}"},
+	{"inc", "include header", LangNames{"C", "C++"}, "#include <${1:stdio}.h>\n$0"},
+}
+
+// SnippetVars returns the values of the snippet variables for given file
+// and 0-based line
+func SnippetVars(fpath string, ln int) map[string]string {
+	base := filepath.Base(fpath)
+	return map[string]string{
+		"TM_FILENAME":      base,
+		"TM_FILENAME_BASE": strings.TrimSuffix(base, filepath.Ext(base)),
+		"TM_DIRECTORY":     filepath.Dir(fpath),
+		"TM_FILEPATH":      fpath,
+		"TM_LINE_NUMBER":   strconv.Itoa(ln + 1),
+		"CURRENT_YEAR":     strconv.Itoa(time.Now().Year()),
+	}
+}
+
+// SnippetStop is one place of a tab stop in the text of an expanded snippet,
+// as rune offsets from its start
+type SnippetStop struct {
+	Idx    int
+	St, Ed int
+}
+
+// snippetParser parses the body of a snippet into its text and tab stops
+type snippetParser struct {
+	rs     []rune
+	i      int
+	vars   map[string]string
+	mirror map[int]string
+	out    []rune
+	stops  []SnippetStop
+}
+
+// ParseSnippet returns the text of given snippet body, with placeholders,
+// choices and variables (from vars) filled in, and the places of its tab
+// stops in it, in order of their end -- the other places of a tab stop with
+// a placeholder get a copy of it, and text that is not valid snippet syntax
+// is left as it is
+func ParseSnippet(body string, vars map[string]string) (string, []SnippetStop) {
+	sp := &snippetParser{rs: []rune(body), vars: vars}
+	sp.parse(false)
+	mirror := make(map[int]string)
+	for _, s := range sp.stops {
+		if _, has := mirror[s.Idx]; !has && s.Ed > s.St {
+			mirror[s.Idx] = string(sp.out[s.St:s.Ed])
+		}
+	}
+	if len(mirror) == 0 {
+		return string(sp.out), sp.stops
+	}
+	sp = &snippetParser{rs: []rune(body), vars: vars, mirror: mirror}
+	sp.parse(false)
+	return string(sp.out), sp.stops
+}
+
+// parse parses text up to the end, or the } closing a placeholder if inner
+func (sp *snippetParser) parse(inner bool) {
+	for sp.i < len(sp.rs) {
+		r := sp.rs[sp.i]
+		switch {
+		case r == '\\' && sp.i+1 < len(sp.rs) && strings.ContainsRune(`$}\`, sp.rs[sp.i+1]):
+			sp.out = append(sp.out, sp.rs[sp.i+1])
+			sp.i += 2
+		case r == '}' && inner:
+			sp.i++
+			return
+		case r == '$':
+			sp.i++
+			sp.dollar()
+		default:
+			sp.out = append(sp.out, r)
+			sp.i++
+		}
+	}
+}
+
+// name parses the number of a tab stop or name of a variable
+func (sp *snippetParser) name() string {
+	st := sp.i
+	if sp.i < len(sp.rs) && sp.rs[sp.i] >= '0' && sp.rs[sp.i] <= '9' {
+		for sp.i < len(sp.rs) && sp.rs[sp.i] >= '0' && sp.rs[sp.i] <= '9' {
+			sp.i++
+		}
+		return string(sp.rs[st:sp.i])
+	}
+	for sp.i < len(sp.rs) {
+		r := sp.rs[sp.i]
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (sp.i > st && r >= '0' && r <= '9')) {
+			break
+		}
+		sp.i++
+	}
+	return string(sp.rs[st:sp.i])
+}
+
+// dollar parses the tab stop or variable after a $
+func (sp *snippetParser) dollar() {
+	st := sp.i
+	braced := sp.i < len(sp.rs) && sp.rs[sp.i] == '{'
+	if braced {
+		sp.i++
+	}
+	nm := sp.name()
+	if nm == "" || (braced && sp.i >= len(sp.rs)) {
+		sp.i = st
+		sp.out = append(sp.out, '$')
+		return
+	}
+	idx, err := strconv.Atoi(nm)
+	isStop := err == nil
+	start := len(sp.out)
+	nstops := len(sp.stops)
+	val, hasVal := sp.vars[nm]
+	if isStop {
+		val = sp.mirror[idx]
+	}
+	if !braced {
+		sp.out = append(sp.out, []rune(val)...)
+		if isStop {
+			sp.stops = append(sp.stops, SnippetStop{Idx: idx, St: start, Ed: len(sp.out)})
+		}
+		return
+	}
+	switch sp.rs[sp.i] {
+	case '}':
+		sp.i++
+		sp.out = append(sp.out, []rune(val)...)
+	case ':':
+		sp.i++
+		sp.parse(true)
+		if !isStop && hasVal && val != "" { // default only used for empty variable
+			sp.out = append(sp.out[:start], []rune(val)...)
+			sp.stops = sp.stops[:nstops]
+		}
+	case '|':
+		sp.i++
+		var choice []rune
+		first := true
+	choices:
+		for sp.i < len(sp.rs) {
+			r := sp.rs[sp.i]
+			sp.i++
+			switch {
+			case r == '\\' && sp.i < len(sp.rs):
+				if first {
+					choice = append(choice, sp.rs[sp.i])
+				}
+				sp.i++
+			case r == ',':
+				first = false
+			case r == '|':
+				break choices
+			default:
+				if first {
+					choice = append(choice, r)
+				}
+			}
+		}
+		if sp.i < len(sp.rs) && sp.rs[sp.i] == '}' {
+			sp.i++
+		}
+		sp.out = append(sp.out, choice...)
+	default:
+		sp.i = st
+		sp.out = append(sp.out, '$')
+		return
+	}
+	if isStop {
+		sp.stops = append(sp.stops, SnippetStop{Idx: idx, St: start, Ed: len(sp.out)})
+	}
+}
+
+// SnippetStopOrder returns the places of the tab stops grouped by stop, in
+// the order they are visited: $1, $2, etc, and then $0 -- which is added
+// at the end of the text (of length n runes) if there isn't one
+func SnippetStopOrder(stops []SnippetStop, n int) [][]SnippetStop {
+	byIdx := make(map[int][]SnippetStop)
+	var idxs []int
+	for _, s := range stops {
+		if _, has := byIdx[s.Idx]; !has {
+			idxs = append(idxs, s.Idx)
+		}
+		byIdx[s.Idx] = append(byIdx[s.Idx], s)
+	}
+	sort.Slice(idxs, func(i, j int) bool {
+		if idxs[i] == 0 || idxs[j] == 0 {
+			return idxs[j] == 0 && idxs[i] != 0
+		}
+		return idxs[i] < idxs[j]
+	})
+	grp := make([][]SnippetStop, 0, len(idxs)+1)
+	for _, idx := range idxs {
+		occ := byIdx[idx]
+		sort.Slice(occ, func(i, j int) bool { return occ[i].St < occ[j].St })
+		grp = append(grp, occ)
+	}
+	if _, has := byIdx[0]; !has {
+		grp = append(grp, []SnippetStop{{Idx: 0, St: n, Ed: n}})
+	}
+	return grp
+}
+
+// SnippetIndent returns given snippet body with the lines after the first
+// indented by ind, and the tabs at the start of lines converted to unit
+func SnippetIndent(body, ind, unit string) string {
+	lns := strings.Split(body, "\n")
+	for i, l := range lns {
+		n := len(l) - len(strings.TrimLeft(l, "\t"))
+		l = strings.Repeat(unit, n) + l[n:]
+		if i > 0 && l != "" {
+			l = ind + l
+		}
+		lns[i] = l
+	}
+	return strings.Join(lns, "\n")
+}
+
+// SnippetSession is the expanded snippet whose tab stops are being visited
+// with Tab
+type SnippetSession struct {
+	View  *giv.TextView      `desc:"the view the snippet was expanded in"`
+	Stops [][]giv.TextRegion `desc:"places of each tab stop, in the order visited, ending with $0 -- updated for edits"`
+	Cur   int                `desc:"index in Stops of the current tab stop"`
+}
+
+// In returns true if the session is in given view, with the cursor in one
+// of its tab stops
+func (ss *SnippetSession) In(tv *giv.TextView) bool {
+	if ss.View != tv || len(ss.Stops) == 0 {
+		return false
+	}
+	p := tv.CursorPos
+	for _, occ := range ss.Stops {
+		for _, r := range occ {
+			if !textPosLess(p, r.Start) && !textPosLess(r.End, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ExpandSnippet expands the snippet whose prefix is just before the cursor
+// in the active view, for the language of its file, and selects its first
+// tab stop -- returns false if there is no such snippet
+func (ge *Gide) ExpandSnippet() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil || tv.IsInactive() || !tv.HasFocus() || tv.HasSelection() || ge.Cursors.Has(tv) {
+		return false
+	}
+	pos := tv.CursorPos
+	if pos.Ln >= len(tv.Buf.Lines) {
+		return false
+	}
+	line := tv.Buf.Lines[pos.Ln]
+	wd, st, ed := WordAtPos(line, pos.Ch)
+	if wd == "" || ed != pos.Ch {
+		return false
+	}
+	fpath := string(tv.Buf.Filename)
+	sn, ok := AvailSnippets.SnippetByPrefix(wd, LangNamesForFilename(fpath))
+	if !ok {
+		return false
+	}
+	ir := IndentRulesFor(tv.Buf)
+	body := SnippetIndent(sn.Body, leadingSpace(string(line)), ir.Unit)
+	txt, stops := ParseSnippet(body, SnippetVars(fpath, pos.Ln))
+	spos := giv.TextPos{Ln: pos.Ln, Ch: st}
+	tv.Buf.DeleteText(spos, pos, true, true)
+	tv.Buf.InsertText(spos, []byte(txt), true, true)
+	grp := SnippetStopOrder(stops, len([]rune(txt)))
+	ss := &ge.Snippet
+	ss.View = tv
+	ss.Stops = make([][]giv.TextRegion, len(grp))
+	for i, occ := range grp {
+		for _, s := range occ {
+			r := giv.TextRegion{Start: TextPosMove(tv.Buf.Lines, spos, s.St), End: TextPosMove(tv.Buf.Lines, spos, s.Ed)}
+			ss.Stops[i] = append(ss.Stops[i], r)
+		}
+	}
+	ge.snippetGo(0)
+	return true
+}
+
+// SnippetTab moves to the next tab stop of the snippet being edited in the
+// active view, if the cursor is in one of its stops, or else expands the
+// snippet whose prefix is before the cursor -- returns false if neither,
+// so that Tab does its usual thing
+func (ge *Gide) SnippetTab() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil || tv.IsInactive() || !tv.HasFocus() {
+		return false
+	}
+	if ge.Snippet.In(tv) {
+		ge.snippetGo(ge.Snippet.Cur + 1)
+		return true
+	}
+	ge.SnippetEnd()
+	return ge.ExpandSnippet()
+}
+
+// SnippetBackTab moves to the previous tab stop of the snippet being edited
+// in the active view -- returns false if the cursor is not in one of its stops
+func (ge *Gide) SnippetBackTab() bool {
+	tv := ge.ActiveTextView()
+	if tv.Buf == nil || !ge.Snippet.In(tv) {
+		return false
+	}
+	if ge.Snippet.Cur > 0 {
+		ge.snippetGo(ge.Snippet.Cur - 1)
+	}
+	return true
+}
+
+// snippetGo selects tab stop i of the snippet, with cursors at its other
+// places, or puts the cursor at $0 and ends the snippet if it is the last
+func (ge *Gide) snippetGo(i int) {
+	ss := &ge.Snippet
+	tv := ss.View
+	if ge.Cursors.Has(tv) {
+		ge.ClearCursors()
+	}
+	if i >= len(ss.Stops)-1 {
+		p := ss.Stops[len(ss.Stops)-1][0].Start
+		ge.SnippetEnd()
+		tv.SelectReset()
+		tv.SetCursorShow(p)
+		return
+	}
+	ss.Cur = i
+	occ := ss.Stops[i]
+	mc := ge.CursorsFor(tv)
+	mc.Regs = nil
+	for _, r := range occ[1:] {
+		mc.Add(r)
+	}
+	reg := occ[0]
+	updt := tv.UpdateStart()
+	tv.SetCursorShow(reg.End)
+	tv.SelectReg = reg
+	tv.UpdateEnd(updt)
+	mc.Last = reg.End
+	ge.ShowCursors(tv)
+	ge.SetStatus(fmt.Sprintf("Snippet tab stop %d of %d -- Tab for next, Shift+Tab for previous", i+1, len(ss.Stops)-1))
+}
+
+// SnippetEnd ends the snippet being edited, if any
+func (ge *Gide) SnippetEnd() {
+	ss := &ge.Snippet
+	if ss.View != nil && ge.Cursors.Has(ss.View) {
+		ge.ClearCursors()
+	}
+	ss.View = nil
+	ss.Stops = nil
+	ss.Cur = 0
+}
+
+// SnippetEdit updates the places of the tab stops of the snippet being
+// edited for given edit in given buffer -- text inserted at the start of
+// the current stop goes into it -- called for all edits
+func (ge *Gide) SnippetEdit(tb *giv.TextBuf, tbe *giv.TextBufEdit) {
+	ss := &ge.Snippet
+	if tbe == nil || ss.View == nil || ss.View.Buf != tb {
+		return
+	}
+	ce := cursorEdit{Delete: tbe.Delete, St: tbe.Reg.Start, Ed: tbe.Reg.End}
+	for i, occ := range ss.Stops {
+		for j, r := range occ {
+			nr := ce.AdjustReg(r)
+			if i == ss.Cur && !ce.Delete && r.Start == ce.St {
+				nr.Start = r.Start
+			}
+			occ[j] = nr
+		}
+	}
+}
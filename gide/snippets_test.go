@@ -0,0 +1,54 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSnippet(t *testing.T) {
+	vars := map[string]string{"TM_FILENAME": "a.go", "EMPTY": ""}
+	tests := []struct {
+		body  string
+		txt   string
+		stops []SnippetStop
+	}{
+		{"x$1y$0", "xy", []SnippetStop{{1, 1, 1}, {0, 2, 2}}},
+		{"f(${1:a}, ${2:b})", "f(a, b)", []SnippetStop{{1, 2, 3}, {2, 5, 6}}},
+		{"${1:a ${2:b}}", "a b", []SnippetStop{{2, 2, 3}, {1, 0, 3}}},
+		{"for ${1:i} := 0; $1 < n; $1++", "for i := 0; i < n; i++", []SnippetStop{{1, 4, 5}, {1, 12, 13}, {1, 19, 20}}},
+		{"${1|one,two|}", "one", []SnippetStop{{1, 0, 3}}},
+		{"// $TM_FILENAME ${EMPTY:def} ${TM_FILENAME:def}", "// a.go def a.go", nil},
+		{`cost \$5 $ {x} \}`, "cost $5 $ {x} }", nil},
+		{"${1:unclosed", "unclosed", []SnippetStop{{1, 0, 8}}},
+		{"${", "${", nil},
+	}
+	for _, ts := range tests {
+		txt, stops := ParseSnippet(ts.body, vars)
+		if txt != ts.txt || !reflect.DeepEqual(stops, ts.stops) {
+			t.Errorf("ParseSnippet(%q) = %q %v, want %q %v", ts.body, txt, stops, ts.txt, ts.stops)
+		}
+	}
+}
+
+func TestSnippetStopOrder(t *testing.T) {
+	stops := []SnippetStop{{2, 5, 5}, {0, 3, 3}, {1, 4, 4}, {1, 1, 1}}
+	want := [][]SnippetStop{{{1, 1, 1}, {1, 4, 4}}, {{2, 5, 5}}, {{0, 3, 3}}}
+	if got := SnippetStopOrder(stops, 9); !reflect.DeepEqual(got, want) {
+		t.Errorf("SnippetStopOrder: %v, want %v", got, want)
+	}
+	want = [][]SnippetStop{{{1, 1, 1}}, {{0, 9, 9}}}
+	if got := SnippetStopOrder([]SnippetStop{{1, 1, 1}}, 9); !reflect.DeepEqual(got, want) {
+		t.Errorf("SnippetStopOrder no $0: %v, want %v", got, want)
+	}
+}
+
+func TestSnippetIndent(t *testing.T) {
+	got := SnippetIndent("def f():\n\tpass\n\n", "  ", "    ")
+	if want := "def f():\n      pass\n\n"; got != want {
+		t.Errorf("SnippetIndent: %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+)
+
+// SpellTextExts are the extensions of the files whose full text is spell
+// checked as it is typed, rather than just the comments and strings, as in
+// code -- the code in Markdown files is left out
+var SpellTextExts = []string{".md", ".markdown", ".txt", ".text", ".rst"}
+
+// SpellFullText returns true if the full text of given file is spell
+// checked (see SpellTextExts)
+func SpellFullText(fname string) bool {
+	ext := strings.ToLower(filepath.Ext(fname))
+	for _, e := range SpellTextExts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// SpellClassOk returns true if text highlighted with given css class is
+// spell checked in code: comments and strings, but not preprocessor
+// directives, #! lines, escapes, interpolations, regexps, characters or
+// symbols
+func SpellClassOk(cls string) bool {
+	switch cls {
+	case "cp", "cpf", "ch", "sa", "sc", "se", "si", "sr", "ss", "sx":
+		return false
+	}
+	return strings.HasPrefix(cls, "c") || strings.HasPrefix(cls, "s")
+}
+
+// SpellMaskLine returns the runes of given line of code, highlighted with
+// given spans, with those that are not to be spell checked masked with
+// DocMaskRune (see SpellClassOk)
+func SpellMaskLine(line string, spans []HiSpan) []rune {
+	rs := []rune(line)
+	keep := make([]bool, len(rs))
+	for _, sp := range spans {
+		if !SpellClassOk(sp.Cls) {
+			continue
+		}
+		for i := sp.St; i < sp.Ed && i < len(rs); i++ {
+			keep[i] = true
+		}
+	}
+	for i := range rs {
+		if !keep[i] {
+			rs[i] = DocMaskRune
+		}
+	}
+	return rs
+}
+
+// SpellState is the spell checking of a buffer as it is typed, which only
+// checks the lines whose text to check has changed since the last time
+type SpellState struct {
+	Checked map[string][]HiSpan `desc:"unknown words in each of the masked lines checked, by the line"`
+	Bad     [][]HiSpan          `desc:"unknown words in each line of the buffer"`
+}
+
+// Update updates the unknown words for given masked lines of the buffer,
+// calling check for those lines that have not been checked before, which
+// returns the unknown words in them -- returns the number of lines checked
+func (ss *SpellState) Update(masked [][]rune, check func(lines [][]rune) []DocIssue) int {
+	chk := make(map[string][]HiSpan, len(masked))
+	var todo [][]rune
+	var tkeys []string
+	for _, ml := range masked {
+		k := string(ml)
+		if _, ok := chk[k]; ok {
+			continue
+		}
+		if sps, ok := ss.Checked[k]; ok {
+			chk[k] = sps
+			continue
+		}
+		chk[k] = nil
+		todo = append(todo, ml)
+		tkeys = append(tkeys, k)
+	}
+	if len(todo) > 0 {
+		for _, is := range check(todo) {
+			if is.Ln >= 0 && is.Ln < len(tkeys) {
+				k := tkeys[is.Ln]
+				chk[k] = append(chk[k], HiSpan{St: is.St, Ed: is.Ed})
+			}
+		}
+	}
+	ss.Checked = chk
+	ss.Bad = make([][]HiSpan, len(masked))
+	for i, ml := range masked {
+		ss.Bad[i] = chk[string(ml)]
+	}
+	return len(todo)
+}
+
+// spellInitOnce initializes the spell checker the first time a buffer is
+// checked
+var spellInitOnce sync.Once
+
+// SpellMarkupBuf spell checks given buffer, with lexical highlighting in hs,
+// incrementally from the last time, for hiOverlay to underline the unknown
+// words -- only comments and strings are checked in code, and the full text
+// in Markdown and text files -- words in the project dictionary are known --
+// must be called with hiMu locked
+func (ge *Gide) SpellMarkupBuf(tb *giv.TextBuf, hs *HiState, lines []string) {
+	if !ge.Prefs.Editor.SpellCheck {
+		delete(ge.spellStates, tb)
+		return
+	}
+	fname := string(tb.Filename)
+	var masked [][]rune
+	ext := strings.ToLower(filepath.Ext(fname))
+	switch {
+	case ext == ".md" || ext == ".markdown":
+		masked = DocMaskMarkdown([]byte(strings.Join(lines, "\n")))
+	case SpellFullText(fname):
+		masked = make([][]rune, len(lines))
+		for i, l := range lines {
+			masked[i] = []rune(l)
+		}
+	default:
+		masked = make([][]rune, len(lines))
+		for i, l := range lines {
+			var sps []HiSpan
+			if i < len(hs.Spans) {
+				sps = hs.Spans[i]
+			}
+			masked[i] = SpellMaskLine(l, sps)
+		}
+	}
+	if ge.spellStates == nil {
+		ge.spellStates = make(map[*giv.TextBuf]*SpellState)
+	}
+	ss := ge.spellStates[tb]
+	if ss == nil {
+		ss = &SpellState{}
+		ge.spellStates[tb] = ss
+	}
+	spellInitOnce.Do(func() { gi.InitSpell() })
+	ss.Update(masked, ge.spellUnknown)
+}
+
+// spellUnknown returns the unknown words in given masked lines, leaving out
+// those in the project dictionary
+func (ge *Gide) spellUnknown(lines [][]rune) []DocIssue {
+	var iss []DocIssue
+	for _, is := range DocSpelling(lines) {
+		if !ge.SpellInDict(is.Word) {
+			iss = append(iss, is)
+		}
+	}
+	return iss
+}
+
+// SpellInDict returns true if given word is in the project dictionary, as
+// it is or in lower case
+func (ge *Gide) SpellInDict(word string) bool {
+	lw := strings.ToLower(word)
+	for _, w := range ge.Prefs.Dict {
+		if w == word || w == lw {
+			return true
+		}
+	}
+	return false
+}
+
+// SpellAddToDict adds given word to the project dictionary, so it is no
+// longer underlined as misspelled, in any of the open files
+func (ge *Gide) SpellAddToDict(word string) {
+	if ge.SpellInDict(word) {
+		return
+	}
+	ge.Prefs.Dict = append(ge.Prefs.Dict, word)
+	sort.Strings(ge.Prefs.Dict)
+	ge.Prefs.Changed = true
+	ge.SpellRecheck()
+}
+
+// SpellRecheck spell checks all of the open files again, e.g., after a
+// word is added to a dictionary
+func (ge *Gide) SpellRecheck() {
+	ge.hiMu.Lock()
+	ge.spellStates = nil
+	ge.hiMu.Unlock()
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil {
+			ge.HiMarkupBuf(ond.Buf)
+			ond.Buf.RefreshViews()
+		}
+	}
+}
+
+// SpellMenuAtCursor pops up a menu just below the word at the cursor in the
+// active view, if it is underlined as misspelled, with the suggested
+// corrections to replace it with, and options to add it to the dictionary
+// of the project or of all projects
+func (ge *Gide) SpellMenuAtCursor() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.IsInactive() {
+		return
+	}
+	tb := tv.Buf
+	cp := tv.CursorPos
+	var bad HiSpan
+	found := false
+	ge.hiMu.Lock()
+	if ss := ge.spellStates[tb]; ss != nil && cp.Ln < len(ss.Bad) && cp.Ln < len(tb.Lines) {
+		for _, sp := range ss.Bad[cp.Ln] {
+			if sp.St <= cp.Ch && cp.Ch <= sp.Ed && sp.Ed <= len(tb.Lines[cp.Ln]) {
+				bad, found = sp, true
+				break
+			}
+		}
+	}
+	ge.hiMu.Unlock()
+	if !found {
+		ge.SetStatus("Spelling: no misspelled word at the cursor")
+		return
+	}
+	word := string(tb.Lines[cp.Ln][bad.St:bad.Ed])
+	st := giv.TextPos{Ln: cp.Ln, Ch: bad.St}
+	ed := giv.TextPos{Ln: cp.Ln, Ch: bad.Ed}
+	var sugs []string
+	if iss := DocSpelling([][]rune{[]rune(word)}); len(iss) > 0 {
+		sugs = iss[0].Suggests
+	}
+
+	var m gi.Menu
+	if len(sugs) == 0 {
+		m.AddAction(gi.ActOpts{Label: "(no suggestions)"}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {})
+	}
+	for _, sg := range sugs {
+		sg := sg
+		m.AddAction(gi.ActOpts{Label: sg}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tb.DeleteText(st, ed, true, true)
+			tb.InsertText(st, []byte(sg), true, true)
+		})
+	}
+	m.AddSeparator("sep-dict")
+	m.AddAction(gi.ActOpts{Label: "Add to Project Dictionary"}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gee, _ := recv.Embed(KiT_Gide).(*Gide)
+		gee.SpellAddToDict(word)
+		gee.SetStatus(fmt.Sprintf("Added %q to the project dictionary", word))
+	})
+	m.AddAction(gi.ActOpts{Label: "Add to Dictionary"}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gee, _ := recv.Embed(KiT_Gide).(*Gide)
+		gi.LearnWord(word)
+		gee.SpellRecheck()
+		gee.SetStatus(fmt.Sprintf("Added %q to the dictionary for all projects", word))
+	})
+	cpos := tv.CharStartPos(st).ToPoint()
+	gi.PopupMenu(m, cpos.X, cpos.Y+int(tv.LineHeight), tv.Viewport, "spell-menu")
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpellMaskLine(t *testing.T) {
+	line := `x := "helo wrld" // a coment`
+	spans := []HiSpan{{0, 1, "nx"}, {2, 4, "o"}, {5, 16, "s2"}, {17, 28, "c1"}}
+	got := strings.Replace(string(SpellMaskLine(line, spans)), string(DocMaskRune), "_", -1)
+	want := `_____"helo wrld"_// a coment`
+	if got != want {
+		t.Errorf("SpellMaskLine: got %q, want %q", got, want)
+	}
+	if !SpellClassOk("cm") || SpellClassOk("cp") || SpellClassOk("se") || SpellClassOk("nf") {
+		t.Errorf("SpellClassOk: wrong classes")
+	}
+}
+
+func TestSpellStateUpdate(t *testing.T) {
+	var checked []string
+	check := func(lines [][]rune) []DocIssue {
+		var iss []DocIssue
+		for i, l := range lines {
+			checked = append(checked, string(l))
+			if st := strings.Index(string(l), "teh"); st >= 0 {
+				iss = append(iss, DocIssue{Ln: i, St: st, Ed: st + 3, Word: "teh"})
+			}
+		}
+		return iss
+	}
+	mask := func(lines ...string) [][]rune {
+		ml := make([][]rune, len(lines))
+		for i, l := range lines {
+			ml[i] = []rune(l)
+		}
+		return ml
+	}
+	ss := &SpellState{}
+	if n := ss.Update(mask("teh cat", "", "a dog", ""), check); n != 3 {
+		t.Errorf("first update checked %d lines, want 3", n)
+	}
+	want := [][]HiSpan{{{0, 3, ""}}, nil, nil, nil}
+	if !reflect.DeepEqual(ss.Bad, want) {
+		t.Errorf("Bad: got %v, want %v", ss.Bad, want)
+	}
+	checked = nil
+	if n := ss.Update(mask("", "teh cat", "a dog", "so teh"), check); n != 1 || !reflect.DeepEqual(checked, []string{"so teh"}) {
+		t.Errorf("second update checked %v, want just the new line", checked)
+	}
+	want = [][]HiSpan{nil, {{0, 3, ""}}, nil, {{3, 6, ""}}}
+	if !reflect.DeepEqual(ss.Bad, want) {
+		t.Errorf("Bad: got %v, want %v", ss.Bad, want)
+	}
+}
+
+func TestHiMarkupLineUnder(t *testing.T) {
+	line := "// teh end"
+	base := []HiSpan{{0, 10, "c1"}}
+	got := string(HiMarkupLine(line, base, nil, []HiSpan{{3, 6, ""}}))
+	want := `<span class="c1">// </span><u><span class="c1">teh</span></u><span class="c1"> end</span>`
+	if got != want {
+		t.Errorf("HiMarkupLine:\ngot  %v\nwant %v", got, want)
+	}
+}
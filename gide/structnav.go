@@ -0,0 +1,543 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goki/gi/giv"
+)
+
+// SynPos is a position in text, for structural navigation: 0-based line and
+// rune column
+type SynPos struct {
+	Ln, Ch int
+}
+
+// Before returns true if the position is before given one
+func (p SynPos) Before(o SynPos) bool {
+	return p.Ln < o.Ln || (p.Ln == o.Ln && p.Ch < o.Ch)
+}
+
+// SynRange is a range of text, for expanding the selection by syntax: from
+// St up to, not including, Ed
+type SynRange struct {
+	St, Ed SynPos
+}
+
+// Contains returns true if the range contains given one
+func (r SynRange) Contains(o SynRange) bool {
+	return !o.St.Before(r.St) && !r.Ed.Before(o.Ed)
+}
+
+// Defun is a function, or other top-level block, as the first and last of
+// its lines
+type Defun struct {
+	St, Ed int
+}
+
+// goPosMap converts the byte offsets of Go source to positions
+type goPosMap struct {
+	src    []byte
+	starts []int
+}
+
+// newGoPosMap returns the position map of given source
+func newGoPosMap(src []byte) *goPosMap {
+	pm := &goPosMap{src: src, starts: []int{0}}
+	for i, b := range src {
+		if b == '\n' {
+			pm.starts = append(pm.starts, i+1)
+		}
+	}
+	return pm
+}
+
+// Pos returns the position of given byte offset
+func (pm *goPosMap) Pos(off int) SynPos {
+	if off > len(pm.src) {
+		off = len(pm.src)
+	}
+	ln := sort.Search(len(pm.starts), func(i int) bool { return pm.starts[i] > off }) - 1
+	return SynPos{Ln: ln, Ch: utf8.RuneCount(pm.src[pm.starts[ln]:off])}
+}
+
+// parseGo parses given Go source, returning the file even if it has errors,
+// as far as it could be parsed
+func parseGo(src []byte) (*token.FileSet, *ast.File) {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, "", src, parser.ParseComments)
+	return fset, f
+}
+
+// GoDefuns returns the functions and methods of given Go source -- false if
+// it could not be parsed at all
+func GoDefuns(src []byte) ([]Defun, bool) {
+	fset, f := parseGo(src)
+	if f == nil {
+		return nil, false
+	}
+	var ds []Defun
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok {
+			ds = append(ds, Defun{St: fset.Position(fd.Pos()).Line - 1, Ed: fset.Position(fd.End()).Line - 1})
+		}
+	}
+	return ds, true
+}
+
+// lineIndent returns the indentation of given line, in runes, and whether
+// it is blank
+func lineIndent(l string) (int, bool) {
+	n := 0
+	for _, r := range l {
+		if r != ' ' && r != '\t' {
+			return n, false
+		}
+		n++
+	}
+	return n, true
+}
+
+// defunCloser returns true if given unindented line closes the block above
+// it, e.g., a closing brace, rather than starting a new one
+func defunCloser(l string) bool {
+	return strings.HasPrefix(l, "}") || strings.HasPrefix(l, ")") || strings.HasPrefix(l, "]") || l == "end" || strings.HasPrefix(l, "end ")
+}
+
+// IndentDefuns returns the top-level blocks of given lines, by their
+// indentation, for languages without a parser: unindented lines followed by
+// indented ones, through the last indented line or the closing line after
+// them, e.g., a closing brace
+func IndentDefuns(lines []string) []Defun {
+	var ds []Defun
+	for i := 0; i < len(lines); i++ {
+		if ind, blank := lineIndent(lines[i]); blank || ind > 0 || defunCloser(lines[i]) {
+			continue
+		}
+		ed := i
+		j := i + 1
+		for ; j < len(lines); j++ {
+			ind, blank := lineIndent(lines[j])
+			if blank {
+				continue
+			}
+			if ind == 0 {
+				break
+			}
+			ed = j
+		}
+		if j < len(lines) && ed > i && defunCloser(lines[j]) {
+			ed = j
+		}
+		if ed > i {
+			ds = append(ds, Defun{St: i, Ed: ed})
+			i = ed
+		}
+	}
+	return ds
+}
+
+// DefunNext returns the first line of the first defun that starts after
+// given line, or -1 if none
+func DefunNext(ds []Defun, ln int) int {
+	for _, d := range ds {
+		if d.St > ln {
+			return d.St
+		}
+	}
+	return -1
+}
+
+// DefunPrev returns the first line of the last defun that starts before
+// given line, or -1 if none
+func DefunPrev(ds []Defun, ln int) int {
+	for i := len(ds) - 1; i >= 0; i-- {
+		if ds[i].St < ln {
+			return ds[i].St
+		}
+	}
+	return -1
+}
+
+// DefunBegin returns the first line of the defun containing given line, or
+// of the one before it if the position is already at its start, atStart, or
+// not in one -- -1 if none
+func DefunBegin(ds []Defun, ln int, atStart bool) int {
+	for _, d := range ds {
+		if d.St <= ln && ln <= d.Ed && (ln > d.St || !atStart) {
+			return d.St
+		}
+	}
+	return DefunPrev(ds, ln)
+}
+
+// DefunEnd returns the last line of the defun containing given line, or of
+// the one after it if the position is already at its end, atEnd, or not in
+// one -- -1 if none
+func DefunEnd(ds []Defun, ln int, atEnd bool) int {
+	for _, d := range ds {
+		if d.St <= ln && ln <= d.Ed && (ln < d.Ed || !atEnd) {
+			return d.Ed
+		}
+	}
+	for _, d := range ds {
+		if d.St > ln {
+			return d.Ed
+		}
+	}
+	return -1
+}
+
+// GoSynRanges returns the ranges of the syntax nodes of given Go source,
+// and of the insides of their brackets and quotes -- false if it could not
+// be parsed at all
+func GoSynRanges(src []byte) ([]SynRange, bool) {
+	fset, f := parseGo(src)
+	if f == nil {
+		return nil, false
+	}
+	pm := newGoPosMap(src)
+	tf := fset.File(f.Pos())
+	off := func(p token.Pos) int { return tf.Offset(p) }
+	var rs []SynRange
+	add := func(st, ed token.Pos) {
+		if st.IsValid() && ed.IsValid() && st < ed {
+			rs = append(rs, SynRange{St: pm.Pos(off(st)), Ed: pm.Pos(off(ed))})
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		if _, ok := n.(*ast.File); ok {
+			return true
+		}
+		add(n.Pos(), n.End())
+		switch x := n.(type) {
+		case *ast.BlockStmt:
+			add(x.Lbrace+1, x.Rbrace)
+		case *ast.CallExpr:
+			add(x.Lparen+1, x.Rparen)
+		case *ast.CompositeLit:
+			add(x.Lbrace+1, x.Rbrace)
+		case *ast.ParenExpr:
+			add(x.Lparen+1, x.Rparen)
+		case *ast.FieldList:
+			if x.Opening.IsValid() && x.Closing.IsValid() {
+				add(x.Opening+1, x.Closing)
+			}
+		case *ast.IndexExpr:
+			add(x.Lbrack+1, x.Rbrack)
+		case *ast.BasicLit:
+			if x.Kind == token.STRING || x.Kind == token.CHAR {
+				add(x.Pos()+1, x.End()-1)
+			}
+		case *ast.GenDecl:
+			if x.Lparen.IsValid() {
+				add(x.Lparen+1, x.Rparen)
+			}
+		}
+		return true
+	})
+	return rs, true
+}
+
+// synBrackets are the pairs of brackets matched by TextSynRanges
+var synBrackets = map[rune]rune{'(': ')', '[': ']', '{': '}'}
+
+// TextSynRanges returns the ranges of the structure of given lines, for
+// languages without a parser: the trimmed lines, the blocks of lines
+// indented at least as much as each line, and the insides of brackets and
+// quotes, with and without them
+func TextSynRanges(lines []string) []SynRange {
+	var rs []SynRange
+	type open struct {
+		r   rune
+		pos SynPos
+	}
+	var stack []open
+	for ln, l := range lines {
+		rl := []rune(l)
+		ind, blank := lineIndent(l)
+		if blank {
+			continue
+		}
+		rs = append(rs, SynRange{St: SynPos{ln, ind}, Ed: SynPos{ln, len([]rune(strings.TrimRightFunc(l, unicode.IsSpace)))}})
+		if ind > 0 {
+			st, ed := ln, ln
+			for st > 0 {
+				if i, b := lineIndent(lines[st-1]); !b && i < ind {
+					break
+				}
+				st--
+			}
+			for ed < len(lines)-1 {
+				if i, b := lineIndent(lines[ed+1]); !b && i < ind {
+					break
+				}
+				ed++
+			}
+			if ed > st {
+				rs = append(rs, SynRange{St: SynPos{st, 0}, Ed: SynPos{ed, len([]rune(lines[ed]))}})
+			}
+		}
+		var quote rune
+		qst := 0
+		for i, r := range rl {
+			switch {
+			case quote != 0:
+				if r == '\\' {
+					continue
+				}
+				if r == quote && (i == 0 || rl[i-1] != '\\') {
+					rs = append(rs, SynRange{St: SynPos{ln, qst + 1}, Ed: SynPos{ln, i}}, SynRange{St: SynPos{ln, qst}, Ed: SynPos{ln, i + 1}})
+					quote = 0
+				}
+			case r == '"' || r == '\'' || r == '`':
+				quote, qst = r, i
+			case synBrackets[r] != 0:
+				stack = append(stack, open{r, SynPos{ln, i}})
+			default:
+				for n := len(stack) - 1; n >= 0; n-- {
+					if synBrackets[stack[n].r] != r {
+						continue
+					}
+					op := stack[n].pos
+					rs = append(rs, SynRange{St: SynPos{op.Ln, op.Ch + 1}, Ed: SynPos{ln, i}}, SynRange{St: op, Ed: SynPos{ln, i + 1}})
+					stack = stack[:n]
+					break
+				}
+			}
+		}
+	}
+	return rs
+}
+
+// synWordRune returns true if given rune is part of a word, for selecting
+// the word at the cursor
+func synWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// SynWordAt returns the range of the word at given position in given lines,
+// and false if there is none
+func SynWordAt(lines []string, pos SynPos) (SynRange, bool) {
+	if pos.Ln < 0 || pos.Ln >= len(lines) {
+		return SynRange{}, false
+	}
+	rl := []rune(lines[pos.Ln])
+	st, ed := pos.Ch, pos.Ch
+	for st > 0 && st <= len(rl) && synWordRune(rl[st-1]) {
+		st--
+	}
+	for ed < len(rl) && synWordRune(rl[ed]) {
+		ed++
+	}
+	if st >= ed {
+		return SynRange{}, false
+	}
+	return SynRange{St: SynPos{pos.Ln, st}, Ed: SynPos{pos.Ln, ed}}, true
+}
+
+// SynExpand returns the smallest of given ranges that contains given
+// selection and is bigger than it, in given lines, or false if none is
+func SynExpand(rs []SynRange, sel SynRange, lines []string) (SynRange, bool) {
+	starts := make([]int, len(lines)+1)
+	for i, l := range lines {
+		starts[i+1] = starts[i] + utf8.RuneCountInString(l) + 1
+	}
+	off := func(p SynPos) int {
+		if p.Ln >= len(lines) {
+			return starts[len(lines)]
+		}
+		return starts[p.Ln] + p.Ch
+	}
+	size := off(sel.Ed) - off(sel.St)
+	var best SynRange
+	bsz := -1
+	for _, r := range rs {
+		sz := off(r.Ed) - off(r.St)
+		if sz <= size || !r.Contains(sel) {
+			continue
+		}
+		if bsz < 0 || sz < bsz {
+			best, bsz = r, sz
+		}
+	}
+	return best, bsz >= 0
+}
+
+// synSelState is the state of expanding the selection by syntax: the
+// selections it was expanded from, for shrinking it back, and the one it was
+// expanded to last, to start over when the selection is changed otherwise
+type synSelState struct {
+	tv    *giv.TextView
+	stack []giv.TextRegion
+	last  giv.TextRegion
+}
+
+// bufLines returns the lines of given buffer as strings
+func bufLines(tb *giv.TextBuf) []string {
+	lines := make([]string, len(tb.Lines))
+	for i, l := range tb.Lines {
+		lines[i] = string(l)
+	}
+	return lines
+}
+
+// BufDefuns returns the functions of given buffer: from go/parser for Go
+// files, and otherwise the top-level blocks by indentation
+func (ge *Gide) BufDefuns(tb *giv.TextBuf) []Defun {
+	if LangNamesMatchFilename(string(tb.Filename), LangNames{"Go"}) {
+		if ds, ok := GoDefuns(tb.LinesToBytesCopy()); ok {
+			return ds
+		}
+	}
+	return IndentDefuns(bufLines(tb))
+}
+
+// defunGo moves the cursor of the active view to the start or end of given
+// line, if it is a line, reporting that there is no function otherwise
+func (ge *Gide) defunGo(tv *giv.TextView, ln int, end bool) {
+	if ln < 0 {
+		ge.SetStatus("No function there")
+		return
+	}
+	pos := giv.TextPos{Ln: ln}
+	if end {
+		pos.Ch = len(tv.Buf.Lines[ln])
+	}
+	tv.SetCursorShow(pos)
+}
+
+// NextFunc moves the cursor to the start of the next function, in Go, or
+// the next top-level block by indentation in other languages
+func (ge *Gide) NextFunc() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	ge.defunGo(tv, DefunNext(ge.BufDefuns(tv.Buf), tv.CursorPos.Ln), false)
+}
+
+// PrevFunc moves the cursor to the start of the previous function, in Go,
+// or the previous top-level block by indentation in other languages
+func (ge *Gide) PrevFunc() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	ge.defunGo(tv, DefunPrev(ge.BufDefuns(tv.Buf), tv.CursorPos.Ln), false)
+}
+
+// BeginDefun moves the cursor to the start of the function it is in, or of
+// the previous one if it is already there or not in one
+func (ge *Gide) BeginDefun() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	ge.defunGo(tv, DefunBegin(ge.BufDefuns(tv.Buf), tv.CursorPos.Ln, tv.CursorPos.Ch == 0), false)
+}
+
+// EndDefun moves the cursor to the end of the function it is in, or of the
+// next one if it is already there or not in one
+func (ge *Gide) EndDefun() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	pos := tv.CursorPos
+	atEnd := pos.Ln < len(tv.Buf.Lines) && pos.Ch >= len(tv.Buf.Lines[pos.Ln])
+	ge.defunGo(tv, DefunEnd(ge.BufDefuns(tv.Buf), pos.Ln, atEnd), true)
+}
+
+// synRegion returns the selection of given view, or its cursor position if
+// nothing is selected
+func synRegion(tv *giv.TextView) giv.TextRegion {
+	if tv.HasSelection() {
+		return giv.TextRegion{Start: tv.SelectReg.Start, End: tv.SelectReg.End}
+	}
+	return giv.TextRegion{Start: tv.CursorPos, End: tv.CursorPos}
+}
+
+// synSelect selects given region in given view
+func synSelect(tv *giv.TextView, reg giv.TextRegion) {
+	updt := tv.UpdateStart()
+	tv.SetCursorShow(reg.End)
+	tv.SelectReg = reg
+	tv.UpdateEnd(updt)
+}
+
+// ExpandSel expands the selection of the active view, or selects from the
+// cursor, to the smallest enclosing syntax node, for Go, or word, brackets,
+// quotes, line or indented block in other languages -- ShrinkSel goes back
+func (ge *Gide) ExpandSel() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	lines := bufLines(tv.Buf)
+	cur := synRegion(tv)
+	sel := SynRange{St: SynPos{cur.Start.Ln, cur.Start.Ch}, Ed: SynPos{cur.End.Ln, cur.End.Ch}}
+	var rs []SynRange
+	ok := false
+	if LangNamesMatchFilename(string(tv.Buf.Filename), LangNames{"Go"}) {
+		rs, ok = GoSynRanges([]byte(strings.Join(lines, "\n")))
+	}
+	if !ok {
+		rs = TextSynRanges(lines)
+	}
+	if w, ok := SynWordAt(lines, sel.St); ok {
+		rs = append(rs, w)
+	}
+	last := len(lines) - 1
+	rs = append(rs, SynRange{Ed: SynPos{last, len([]rune(lines[last]))}})
+	r, ok := SynExpand(rs, sel, lines)
+	if !ok {
+		return
+	}
+	ss := ge.synSel
+	if ss == nil || ss.tv != tv || !synSameReg(ss.last, cur) {
+		ss = &synSelState{tv: tv}
+		ge.synSel = ss
+	}
+	ss.stack = append(ss.stack, cur)
+	ss.last = giv.TextRegion{Start: giv.TextPos{Ln: r.St.Ln, Ch: r.St.Ch}, End: giv.TextPos{Ln: r.Ed.Ln, Ch: r.Ed.Ch}}
+	synSelect(tv, ss.last)
+}
+
+// synSameReg returns true if given regions cover the same text
+func synSameReg(a, b giv.TextRegion) bool {
+	return a.Start == b.Start && a.End == b.End
+}
+
+// ShrinkSel shrinks the selection of the active view back to what it was
+// before the last ExpandSel
+func (ge *Gide) ShrinkSel() {
+	tv := ge.ActiveTextView()
+	ss := ge.synSel
+	if tv == nil || ss == nil || ss.tv != tv || len(ss.stack) == 0 || !synSameReg(ss.last, synRegion(tv)) {
+		ge.synSel = nil
+		return
+	}
+	n := len(ss.stack) - 1
+	prev := ss.stack[n]
+	ss.stack = ss.stack[:n]
+	ss.last = prev
+	if prev.Start == prev.End {
+		tv.SelectReset()
+		tv.SetCursorShow(prev.Start)
+		return
+	}
+	synSelect(tv, prev)
+}
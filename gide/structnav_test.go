@@ -0,0 +1,135 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var structNavGo = `package p
+
+// A does a
+func A() {
+	x := f("héllo", b[1])
+	if x {
+		return
+	}
+}
+
+type T struct{}
+
+func (t *T) M() int { return 1 }
+`
+
+func TestGoDefuns(t *testing.T) {
+	ds, ok := GoDefuns([]byte(structNavGo))
+	if !ok || !reflect.DeepEqual(ds, []Defun{{3, 8}, {12, 12}}) {
+		t.Fatalf("got %v %v", ds, ok)
+	}
+	if got := DefunNext(ds, 3); got != 12 {
+		t.Errorf("next: got %v", got)
+	}
+	if got := DefunPrev(ds, 12); got != 3 {
+		t.Errorf("prev: got %v", got)
+	}
+	if got := DefunPrev(ds, 3); got != -1 {
+		t.Errorf("prev first: got %v", got)
+	}
+	if got := DefunBegin(ds, 5, false); got != 3 {
+		t.Errorf("begin: got %v", got)
+	}
+	if got := DefunBegin(ds, 12, true); got != 3 {
+		t.Errorf("begin at start: got %v", got)
+	}
+	if got := DefunBegin(ds, 10, false); got != 3 {
+		t.Errorf("begin between: got %v", got)
+	}
+	if got := DefunEnd(ds, 5, false); got != 8 {
+		t.Errorf("end: got %v", got)
+	}
+	if got := DefunEnd(ds, 8, true); got != 12 {
+		t.Errorf("end at end: got %v", got)
+	}
+	if got := DefunEnd(ds, 1, false); got != 8 {
+		t.Errorf("end before: got %v", got)
+	}
+}
+
+func TestIndentDefuns(t *testing.T) {
+	py := strings.Split("import os\n\ndef a():\n    x = 1\n\n    return x\n\nclass B:\n    def m(self):\n        pass\nprint(a())", "\n")
+	if ds := IndentDefuns(py); !reflect.DeepEqual(ds, []Defun{{2, 5}, {7, 9}}) {
+		t.Errorf("python: got %v", ds)
+	}
+	c := strings.Split("int a(void)\n{\n  return 1;\n}\nint b(void) {\n  return 2;\n}", "\n")
+	if ds := IndentDefuns(c); !reflect.DeepEqual(ds, []Defun{{1, 3}, {4, 6}}) {
+		t.Errorf("c: got %v", ds)
+	}
+}
+
+// synExpandAll expands from given position in given lines with given
+// ranges until the whole text, returning the text of each selection
+func synExpandAll(rs []SynRange, lines []string, pos SynPos) []string {
+	last := len(lines) - 1
+	rs = append(rs, SynRange{Ed: SynPos{last, len([]rune(lines[last]))}})
+	if w, ok := SynWordAt(lines, pos); ok {
+		rs = append(rs, w)
+	}
+	sel := SynRange{St: pos, Ed: pos}
+	var out []string
+	for {
+		r, ok := SynExpand(rs, sel, lines)
+		if !ok {
+			return out
+		}
+		var b strings.Builder
+		for ln := r.St.Ln; ln <= r.Ed.Ln; ln++ {
+			rl := []rune(lines[ln])
+			st, ed := 0, len(rl)
+			if ln == r.St.Ln {
+				st = r.St.Ch
+			}
+			if ln == r.Ed.Ln {
+				ed = r.Ed.Ch
+			}
+			if ln > r.St.Ln {
+				b.WriteByte('\n')
+			}
+			b.WriteString(string(rl[st:ed]))
+		}
+		out = append(out, b.String())
+		sel = r
+	}
+}
+
+func TestGoSynExpand(t *testing.T) {
+	rs, ok := GoSynRanges([]byte(structNavGo))
+	if !ok {
+		t.Fatal("not parsed")
+	}
+	lines := strings.Split(structNavGo, "\n")
+	got := synExpandAll(rs, lines, SynPos{4, 10}) // in héllo
+	want := []string{`héllo`, `"héllo"`, `"héllo", b[1]`, `f("héllo", b[1])`, `x := f("héllo", b[1])`}
+	if len(got) < len(want)+2 || !reflect.DeepEqual(got[:len(want)], want) {
+		t.Fatalf("got %q", got)
+	}
+	if got[len(got)-1] != structNavGo {
+		t.Errorf("last: got %q", got[len(got)-1])
+	}
+	got = synExpandAll(rs, lines, SynPos{4, 19}) // in 1 of b[1]
+	if want := []string{"1", "b[1]"}; !reflect.DeepEqual(got[:2], want) {
+		t.Errorf("index: got %q", got[:2])
+	}
+}
+
+func TestTextSynExpand(t *testing.T) {
+	lines := strings.Split("def a():\n    x = f(1, 'it''s')\n    return x", "\n")
+	got := synExpandAll(TextSynRanges(lines), lines, SynPos{1, 15}) // in it
+	want := []string{"it", "'it'", "1, 'it''s'", "(1, 'it''s')", "x = f(1, 'it''s')", "    x = f(1, 'it''s')\n    return x", strings.Join(lines, "\n")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q,\nwant %q", got, want)
+	}
+}
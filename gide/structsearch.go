@@ -0,0 +1,486 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// structWild and structWildList are the prefixes of the identifiers that
+// the $X and $*X wildcards of a structural pattern are parsed as
+const (
+	structWild     = "gideWild_"
+	structWildList = "gideWildList_"
+)
+
+// StructMatcher matches a Go pattern syntactically, in the syntax trees of
+// Go source, whatever its formatting: the pattern is an expression, one or
+// more statements, or declarations, where $X matches any one expression,
+// statement or other node, and $*X any list of them, e.g., the arguments of
+// a call or the statements of a block -- a wildcard that is used more than
+// once must match the same code each time, except for $_, which matches
+// anything -- it is a TextFinder, with a group for each wildcard, in order
+// of their names, whose text is the source that it matched
+type StructMatcher struct {
+	Pattern string     `desc:"the pattern, as given"`
+	Names   []string   `desc:"names of the wildcards, other than _, in order -- the groups of each match"`
+	pats    []ast.Node // the pattern's nodes -- more than one for a sequence of statements
+	seq     bool       // the pattern is a sequence of statements
+	idents  []string   // identifiers of the pattern, other than wildcards
+}
+
+// structWildcards returns the pattern with its wildcards replaced by the
+// identifiers that they are parsed as, and their names
+func structWildcards(pat string) (string, []string) {
+	var sb strings.Builder
+	set := make(map[string]bool)
+	for i := 0; i < len(pat); i++ {
+		if pat[i] != '$' {
+			sb.WriteByte(pat[i])
+			continue
+		}
+		pfx := structWild
+		j := i + 1
+		if j < len(pat) && pat[j] == '*' {
+			pfx = structWildList
+			j++
+		}
+		st := j
+		for j < len(pat) && isIdentByte(pat[j]) {
+			j++
+		}
+		if j == st {
+			sb.WriteByte('$')
+			continue
+		}
+		nm := pat[st:j]
+		sb.WriteString(pfx + nm)
+		if nm != "_" {
+			set[nm] = true
+		}
+		i = j - 1
+	}
+	nms := make([]string, 0, len(set))
+	for nm := range set {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	return sb.String(), nms
+}
+
+// isIdentByte returns true if b is an ASCII letter, digit or _
+func isIdentByte(b byte) bool {
+	return b == '_' || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
+
+// goFragmentPrefix is what Go statements are wrapped in to be parsed
+const goFragmentPrefix = "package p\nfunc _() {\n"
+
+// parseGoFragment parses given Go source as an expression, statements, or
+// declarations -- returns their nodes, and the offset in the source parsed
+// of the start of src
+func parseGoFragment(fset *token.FileSet, src string) ([]ast.Node, int, error) {
+	if x, err := parser.ParseExprFrom(fset, "", src, 0); err == nil {
+		return []ast.Node{x}, 0, nil
+	}
+	f, err := parser.ParseFile(fset, "", goFragmentPrefix+src+"\n}\n", 0)
+	if err == nil {
+		body := f.Decls[0].(*ast.FuncDecl).Body.List
+		ns := make([]ast.Node, len(body))
+		for i, s := range body {
+			ns[i] = s
+		}
+		return ns, len(goFragmentPrefix), nil
+	}
+	dpfx := "package p\n"
+	f, derr := parser.ParseFile(fset, "", dpfx+src, 0)
+	if derr != nil || len(f.Decls) == 0 {
+		return nil, 0, err
+	}
+	ns := make([]ast.Node, len(f.Decls))
+	for i, d := range f.Decls {
+		ns[i] = d
+	}
+	return ns, len(dpfx), nil
+}
+
+// NewStructMatcher returns the matcher of given Go pattern, as in
+// StructMatcher -- an error if it does not parse
+func NewStructMatcher(pat string) (*StructMatcher, error) {
+	src, nms := structWildcards(pat)
+	pats, _, err := parseGoFragment(token.NewFileSet(), src)
+	if err != nil {
+		return nil, fmt.Errorf("Go pattern does not parse: %v", err)
+	}
+	if len(pats) == 0 {
+		return nil, fmt.Errorf("Go pattern is empty")
+	}
+	sm := &StructMatcher{Pattern: pat, Names: nms, pats: pats}
+	if len(pats) > 1 {
+		if _, ok := pats[0].(ast.Stmt); ok {
+			sm.seq = true
+		} else {
+			return nil, fmt.Errorf("Go pattern must be one declaration")
+		}
+	}
+	if es, ok := pats[0].(*ast.ExprStmt); ok && !sm.seq {
+		sm.pats[0] = es.X // matches the expression anywhere
+	}
+	set := make(map[string]bool)
+	for _, p := range pats {
+		ast.Inspect(p, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && !strings.HasPrefix(id.Name, structWild) && !strings.HasPrefix(id.Name, structWildList) && id.Name != "_" {
+				set[id.Name] = true
+			}
+			return true
+		})
+	}
+	for id := range set {
+		sm.idents = append(sm.idents, id)
+	}
+	sort.Strings(sm.idents)
+	return sm, nil
+}
+
+// Literals returns the identifiers of the pattern, other than wildcards,
+// which the text of each match contains
+func (sm *StructMatcher) Literals() []string {
+	return sm.idents
+}
+
+// Match returns true if src has all of the identifiers of the pattern, so
+// it can have matches -- it is not parsed here
+func (sm *StructMatcher) Match(src []byte) bool {
+	for _, id := range sm.idents {
+		if !bytes.Contains(src, []byte(id)) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindLine returns the positions of the matches in given fragment of Go
+// source, e.g., the text of a match, and of their groups
+func (sm *StructMatcher) FindLine(line []byte) [][]int {
+	fset := token.NewFileSet()
+	ns, base, err := parseGoFragment(fset, string(line))
+	if err != nil {
+		return nil
+	}
+	return sm.find(fset, ns, base)
+}
+
+// FindText returns the positions of the matches in given Go source file,
+// and of their groups -- none if it does not parse
+func (sm *StructMatcher) FindText(src []byte) [][]int {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil
+	}
+	return sm.find(fset, []ast.Node{f}, 0)
+}
+
+// structBind is what a wildcard matched: a node, or a list of them
+type structBind struct {
+	name  string
+	nodes []ast.Node
+	list  bool
+}
+
+// structState is the state of matching a pattern: what its wildcards
+// matched so far
+type structState struct {
+	binds []structBind
+}
+
+// find returns the positions of the matches in given nodes, in order, and
+// not overlapping, and of their groups -- base is the offset in the source
+// parsed of the start of the source that the positions are in
+func (sm *StructMatcher) find(fset *token.FileSet, roots []ast.Node, base int) [][]int {
+	var ms [][]int
+	end := token.NoPos // of the last match
+	add := func(st *structState, from, to token.Pos) {
+		mi := []int{fset.Position(from).Offset - base, fset.Position(to).Offset - base}
+		for _, nm := range sm.Names {
+			gs, ge := -1, -1
+			for _, b := range st.binds {
+				if b.name == nm && len(b.nodes) > 0 {
+					gs = fset.Position(b.nodes[0].Pos()).Offset - base
+					ge = fset.Position(b.nodes[len(b.nodes)-1].End()).Offset - base
+					break
+				}
+			}
+			mi = append(mi, gs, ge)
+		}
+		ms = append(ms, mi)
+		end = to
+	}
+	for _, root := range roots {
+		ast.Inspect(root, func(n ast.Node) bool {
+			if n == nil || n.Pos() < end {
+				return n != nil && n.End() > end // inside the last match
+			}
+			if _, isf := n.(*ast.File); isf {
+				return true
+			}
+			if !sm.seq {
+				st := &structState{}
+				if st.node(sm.pats[0], n) {
+					add(st, n.Pos(), n.End())
+					return false
+				}
+				return true
+			}
+			var list []ast.Stmt
+			switch b := n.(type) {
+			case *ast.BlockStmt:
+				list = b.List
+			case *ast.CaseClause:
+				list = b.Body
+			case *ast.CommClause:
+				list = b.Body
+			default:
+				return true
+			}
+			ns := make([]ast.Node, len(list))
+			for i, s := range list {
+				ns[i] = s
+			}
+			for i := 0; i < len(ns); i++ {
+				if ns[i].Pos() < end {
+					continue
+				}
+				for j := len(ns); j > i; j-- { // longest first
+					st := &structState{}
+					if st.list(sm.pats, ns[i:j]) {
+						add(st, ns[i].Pos(), ns[j-1].End())
+						i = j - 1
+						break
+					}
+				}
+			}
+			return true
+		})
+	}
+	return ms
+}
+
+// wildName returns the name of the wildcard that given pattern node is,
+// with whether it is a list wildcard -- empty if it is not one
+func wildName(p ast.Node) (string, bool) {
+	switch pn := p.(type) {
+	case *ast.Ident:
+		switch {
+		case strings.HasPrefix(pn.Name, structWildList):
+			return strings.TrimPrefix(pn.Name, structWildList), true
+		case strings.HasPrefix(pn.Name, structWild):
+			return strings.TrimPrefix(pn.Name, structWild), false
+		}
+	case *ast.ExprStmt:
+		return wildName(pn.X)
+	case *ast.Field:
+		if len(pn.Names) == 0 && pn.Tag == nil {
+			return wildName(pn.Type)
+		}
+	}
+	return "", false
+}
+
+// bind records that the wildcard of given name matched given nodes --
+// false if it matched other code before
+func (st *structState) bind(name string, nodes []ast.Node, list bool) bool {
+	if name == "_" {
+		return true
+	}
+	for _, b := range st.binds {
+		if b.name != name {
+			continue
+		}
+		if len(b.nodes) != len(nodes) {
+			return false
+		}
+		for i := range nodes {
+			if !(&structState{}).node(b.nodes[i], nodes[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	st.binds = append(st.binds, structBind{name: name, nodes: nodes, list: list})
+	return true
+}
+
+// node returns true if pattern node p matches node n
+func (st *structState) node(p, n ast.Node) bool {
+	pv, nv := reflect.ValueOf(p), reflect.ValueOf(n)
+	if !pv.IsValid() || pv.IsNil() || !nv.IsValid() || nv.IsNil() {
+		return (!pv.IsValid() || pv.IsNil()) && (!nv.IsValid() || nv.IsNil())
+	}
+	if nm, list := wildName(p); nm != "" {
+		if list {
+			return false // only in lists
+		}
+		return st.bind(nm, []ast.Node{n}, false)
+	}
+	if pv.Type() != nv.Type() {
+		return false
+	}
+	return st.fields(pv.Elem(), nv.Elem())
+}
+
+var (
+	structPosType     = reflect.TypeOf(token.NoPos)
+	structNodeType    = reflect.TypeOf((*ast.Node)(nil)).Elem()
+	structCommentType = reflect.TypeOf((*ast.CommentGroup)(nil))
+	structObjType     = reflect.TypeOf((*ast.Object)(nil))
+	structScopeType   = reflect.TypeOf((*ast.Scope)(nil))
+)
+
+// fields returns true if the fields of the pattern struct p match those of
+// n, which is of the same type -- positions, comments and objects are not
+// compared, other than whether there is a ... in a call
+func (st *structState) fields(p, n reflect.Value) bool {
+	for i := 0; i < p.NumField(); i++ {
+		pf, nf := p.Field(i), n.Field(i)
+		switch ft := pf.Type(); {
+		case ft == structPosType:
+			if p.Type().Field(i).Name == "Ellipsis" && (pf.Interface().(token.Pos).IsValid() != nf.Interface().(token.Pos).IsValid()) {
+				return false
+			}
+		case ft == structCommentType || ft == structObjType || ft == structScopeType:
+		case !st.value(pf, nf):
+			return false
+		}
+	}
+	return true
+}
+
+// value returns true if the pattern value p matches n, of the same type
+func (st *structState) value(p, n reflect.Value) bool {
+	switch p.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if p.IsNil() || n.IsNil() {
+			return p.IsNil() && n.IsNil()
+		}
+		if p.Type().Implements(structNodeType) {
+			return st.node(p.Interface().(ast.Node), n.Interface().(ast.Node))
+		}
+		return st.value(p.Elem(), n.Elem())
+	case reflect.Slice:
+		if p.Type().Elem().Implements(structNodeType) {
+			return st.list(structNodes(p), structNodes(n))
+		}
+		if p.Len() != n.Len() {
+			return false
+		}
+		for i := 0; i < p.Len(); i++ {
+			if !st.value(p.Index(i), n.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		return st.fields(p, n)
+	}
+	return p.Interface() == n.Interface()
+}
+
+// structNodes returns the nodes of a slice of them
+func structNodes(v reflect.Value) []ast.Node {
+	ns := make([]ast.Node, v.Len())
+	for i := range ns {
+		ns[i] = v.Index(i).Interface().(ast.Node)
+	}
+	return ns
+}
+
+// list returns true if the pattern nodes ps match the nodes ns, where a
+// list wildcard matches any number of them
+func (st *structState) list(ps, ns []ast.Node) bool {
+	if len(ps) == 0 {
+		return len(ns) == 0
+	}
+	nb := len(st.binds)
+	if nm, list := wildName(ps[0]); list {
+		for i := 0; i <= len(ns); i++ {
+			if st.bind(nm, ns[:i:i], true) && st.list(ps[1:], ns[i:]) {
+				return true
+			}
+			st.binds = st.binds[:nb]
+		}
+		return false
+	}
+	if len(ns) > 0 && st.node(ps[0], ns[0]) && st.list(ps[1:], ns[1:]) {
+		return true
+	}
+	st.binds = st.binds[:nb]
+	return false
+}
+
+// Expand returns the replacement template with $X, ${X} and $*X in it
+// replaced with the source that the wildcard X matched in the match mi in
+// src -- $$ is a $
+func (sm *StructMatcher) Expand(tmpl, src []byte, mi []int) []byte {
+	var out []byte
+	for i := 0; i < len(tmpl); i++ {
+		if tmpl[i] != '$' {
+			out = append(out, tmpl[i])
+			continue
+		}
+		j := i + 1
+		if j < len(tmpl) && tmpl[j] == '$' {
+			out = append(out, '$')
+			i = j
+			continue
+		}
+		if j < len(tmpl) && tmpl[j] == '*' {
+			j++
+		}
+		var nm string
+		if j < len(tmpl) && tmpl[j] == '{' {
+			k := bytes.IndexByte(tmpl[j:], '}')
+			if k < 0 {
+				out = append(out, '$')
+				continue
+			}
+			nm = string(tmpl[j+1 : j+k])
+			j += k + 1
+		} else {
+			st := j
+			for j < len(tmpl) && isIdentByte(tmpl[j]) {
+				j++
+			}
+			nm = string(tmpl[st:j])
+		}
+		gi := sort.SearchStrings(sm.Names, nm)
+		if nm == "" || gi >= len(sm.Names) || sm.Names[gi] != nm {
+			out = append(out, '$')
+			continue
+		}
+		if gs, ge := mi[2+2*gi], mi[3+2*gi]; gs >= 0 && ge >= gs {
+			out = append(out, src[gs:ge]...)
+		}
+		i = j - 1
+	}
+	return out
+}
+
+// StructCheck returns an error if the Go source of given file, after a
+// structural replace, does not parse, so it is not applied
+func StructCheck(fname string, lines []string) error {
+	if !strings.HasSuffix(fname, ".go") {
+		return nil
+	}
+	_, err := parser.ParseFile(token.NewFileSet(), fname, strings.Join(lines, "\n"), 0)
+	return err
+}
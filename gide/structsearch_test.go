@@ -0,0 +1,103 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// structMatches returns the text of the matches of pattern pat in src
+func structMatches(t *testing.T, pat, src string) []string {
+	t.Helper()
+	sm, err := NewStructMatcher(pat)
+	if err != nil {
+		t.Fatalf("NewStructMatcher(%q): %v", pat, err)
+	}
+	var txts []string
+	for _, mi := range sm.FindText([]byte(src)) {
+		txts = append(txts, src[mi[0]:mi[1]])
+	}
+	return txts
+}
+
+const structSrc = `package p
+
+func f() error {
+	if err := g(); err != nil {
+		return err
+	}
+	if err != nil { return fmt.Errorf("f: %v", err) }
+	if err != nil {
+		log.Println(err)
+	}
+	x := a + b
+	y := a + a
+	fmt.Println(x,
+		y)
+	fmt.Println()
+	return nil
+}
+`
+
+func TestStructMatcher(t *testing.T) {
+	tests := []struct {
+		pat  string
+		want []string
+	}{
+		{"if err != nil { return $*X }", []string{"if err != nil { return fmt.Errorf(\"f: %v\", err) }"}},
+		{"if $C { $*B }", []string{"if err != nil { return fmt.Errorf(\"f: %v\", err) }", "if err != nil {\n\t\tlog.Println(err)\n\t}"}},
+		{"$X + $X", []string{"a + a"}},
+		{"$X + $Y", []string{"a + b", "a + a"}},
+		{"fmt.Println($*A)", []string{"fmt.Println(x,\n\t\ty)", "fmt.Println()"}},
+		{"fmt.Println($A)", nil},
+		{"$x := $_; $y := $_", []string{"x := a + b\n\ty := a + a"}},
+		{"fmt.Println($*_); return $_", []string{"fmt.Println()\n\treturn nil"}},
+	}
+	for _, tt := range tests {
+		if got := structMatches(t, tt.pat, structSrc); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("matches of %q = %q, want %q", tt.pat, got, tt.want)
+		}
+	}
+	if _, err := NewStructMatcher("if {"); err == nil {
+		t.Errorf("NewStructMatcher of a bad pattern: no error")
+	}
+	sm, _ := NewStructMatcher("fmt.Println($*A)")
+	if got, want := sm.Literals(), []string{"Println", "fmt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Literals = %q, want %q", got, want)
+	}
+	if sm.Match([]byte("package p\nfunc f() { fmt.Print() }")) {
+		t.Errorf("Match of source without Println")
+	}
+	if ms := sm.FindText([]byte("fmt.Println(")); ms != nil {
+		t.Errorf("FindText of source that does not parse = %v", ms)
+	}
+	if ms := sm.FindLine([]byte("fmt.Println(a, b)")); len(ms) != 1 || ms[0][2] != 12 || ms[0][3] != 16 {
+		t.Errorf("FindLine = %v", ms)
+	}
+}
+
+func TestStructReplace(t *testing.T) {
+	lines := strings.Split(structSrc, "\n")
+	sm, _ := NewStructMatcher("fmt.Println($*A)")
+	hs := ReplaceLines(lines, sm, NewReplacer(sm, "log.Print(\"$$\", $*A)", false))
+	if len(hs) != 2 {
+		t.Fatalf("ReplaceLines = %+v, want 2 hunks", hs)
+	}
+	if want := "\tlog.Print(\"$\", x,\n\t\ty)"; hs[0].New != want {
+		t.Errorf("replace of the call = %q, want %q", hs[0].New, want)
+	}
+	if want := "\tlog.Print(\"$\", )"; hs[1].New != want {
+		t.Errorf("replace of the empty call = %q, want %q", hs[1].New, want)
+	}
+	got := ApplyLineEdits(lines, ReplaceEdits(hs))
+	if err := StructCheck("f.go", got); err != nil {
+		t.Errorf("StructCheck of the replace: %v", err)
+	}
+	if err := StructCheck("f.go", []string{"package p", "func f() { log.Print(, ) }"}); err == nil {
+		t.Errorf("StructCheck of source that does not parse: no error")
+	}
+}
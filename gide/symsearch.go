@@ -0,0 +1,388 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+)
+
+// ProjSym is a symbol in a file of the project
+type ProjSym struct {
+	Name   string `desc:"name of the symbol, with those of its parents, e.g., Type.Method"`
+	Kind   string `desc:"kind of symbol, e.g., func, struct, method"`
+	Detail string `desc:"more about the symbol, e.g., the signature of a function"`
+	File   string `desc:"full path of the file of the symbol"`
+	Ln     int    `desc:"line of the name of the symbol, 0-based"`
+	Ch     int    `desc:"char position of the name within the line, 0-based"`
+}
+
+// SymIndexMaxSize is the largest file that is indexed by SymIndex, in bytes
+var SymIndexMaxSize int64 = 1 << 20
+
+// SymIndexSkipDirs are the directories that are not indexed by SymIndex,
+// along with those starting with . or _
+var SymIndexSkipDirs = map[string]bool{"vendor": true, "node_modules": true, "testdata": true}
+
+// SymIndex is an index of the symbols in the Go files of a project, from
+// go/parser, used for symbol search when no language server provides it --
+// it is built in the background, from its cache in ProjIndexDir, where only
+// the files that have changed since are parsed again, and kept up to date
+// as files are saved
+type SymIndex struct {
+	Root  string               `desc:"root directory of the index"`
+	Files map[string][]ProjSym `desc:"symbols of each file, by full path"`
+	Mods  map[string]int64     `desc:"modification time of each file when it was indexed by Build, in unix nanoseconds"`
+	Built bool                 `desc:"the whole root has been indexed"`
+	Mu    sync.Mutex           `desc:"mutex protecting the index"`
+	dirty bool                 // changed since it was cached
+	done  chan struct{}
+	start sync.Once
+}
+
+// symIndexCache is the cached form of a SymIndex
+type symIndexCache struct {
+	Root  string
+	Files map[string][]ProjSym
+	Mods  map[string]int64
+}
+
+// NewSymIndex returns a new, empty index for given root
+func NewSymIndex(root string) *SymIndex {
+	return &SymIndex{Root: root, Files: map[string][]ProjSym{}, Mods: map[string]int64{}, done: make(chan struct{})}
+}
+
+// SymIndexFileOk returns true if given file is one that SymIndex indexes
+func SymIndexFileOk(fname string) bool {
+	return filepath.Ext(fname) == ".go"
+}
+
+// IndexFile indexes, or re-indexes, given file, with given contents
+func (si *SymIndex) IndexFile(fname string, src []byte) {
+	syms, _ := GoOutline(src) // partial on errors
+	flat := OutlineFlat(syms)
+	ps := make([]ProjSym, len(flat))
+	for i, s := range flat {
+		ps[i] = ProjSym{Name: s.Name, Kind: s.Kind, Detail: s.Detail, File: fname, Ln: s.Ln, Ch: s.Ch}
+	}
+	si.Mu.Lock()
+	si.Files[fname] = ps
+	si.dirty = true
+	si.Mu.Unlock()
+}
+
+// Build indexes all the Go files within the root that have changed since
+// they were indexed, removes those that are gone, and marks the index as
+// built -- returns the number of files indexed
+func (si *SymIndex) Build() int {
+	n := 0
+	seen := make(map[string]bool)
+	filepath.Walk(si.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		nm := info.Name()
+		if info.IsDir() {
+			if path != si.Root && (SymIndexSkipDirs[nm] || strings.HasPrefix(nm, ".") || strings.HasPrefix(nm, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !SymIndexFileOk(nm) || info.Size() > SymIndexMaxSize {
+			return nil
+		}
+		seen[path] = true
+		mod := info.ModTime().UnixNano()
+		si.Mu.Lock()
+		_, has := si.Files[path]
+		same := has && si.Mods[path] == mod
+		si.Mu.Unlock()
+		if same {
+			return nil
+		}
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		si.IndexFile(path, src)
+		si.Mu.Lock()
+		si.Mods[path] = mod
+		si.Mu.Unlock()
+		n++
+		return nil
+	})
+	si.Mu.Lock()
+	for fname := range si.Files {
+		if !seen[fname] {
+			delete(si.Files, fname)
+			delete(si.Mods, fname)
+			si.dirty = true
+		}
+	}
+	if !si.Built {
+		si.Built = true
+		close(si.done)
+	}
+	si.Mu.Unlock()
+	return n
+}
+
+// Start starts building the index in the background, from its cache if
+// there is one, unless it has already been started
+func (si *SymIndex) Start() {
+	si.start.Do(func() {
+		go func() {
+			si.LoadCache()
+			si.Build()
+			si.SaveCache()
+		}()
+	})
+}
+
+// CachePath returns the path of the file that the index is cached in
+func (si *SymIndex) CachePath() string {
+	return filepath.Join(si.Root, ProjIndexDir, "syms.gob")
+}
+
+// SaveCache writes the index to its cache file, if it has changed since it
+// was read from it or last written
+func (si *SymIndex) SaveCache() error {
+	si.Mu.Lock()
+	defer si.Mu.Unlock()
+	if !si.dirty {
+		return nil
+	}
+	if err := projCacheWrite(si.CachePath(), &symIndexCache{Root: si.Root, Files: si.Files, Mods: si.Mods}); err != nil {
+		return err
+	}
+	si.dirty = false
+	return nil
+}
+
+// LoadCache reads the index from its cache file, if it is for this root
+// -- Build then only indexes the files that have changed since
+func (si *SymIndex) LoadCache() error {
+	var sc symIndexCache
+	if err := projCacheRead(si.CachePath(), &sc); err != nil {
+		return err
+	}
+	if sc.Root != si.Root {
+		return fmt.Errorf("symbol index cache is for another root: %v", si.CachePath())
+	}
+	si.Mu.Lock()
+	defer si.Mu.Unlock()
+	if sc.Files != nil {
+		si.Files = sc.Files
+	}
+	if sc.Mods != nil {
+		si.Mods = sc.Mods
+	}
+	return nil
+}
+
+// Wait waits until the index is built
+func (si *SymIndex) Wait() {
+	<-si.done
+}
+
+// Search returns the symbols whose names match given pattern fuzzily (see
+// FuzzyScore), best first, up to max of them -- symbols of equal score are
+// sorted by name, then file
+func (si *SymIndex) Search(pat string, max int) []ProjSym {
+	type scored struct {
+		ProjSym
+		score int
+	}
+	var res []scored
+	si.Mu.Lock()
+	for _, ps := range si.Files {
+		for _, s := range ps {
+			if sc, ok := FuzzyScore(pat, s.Name); ok {
+				res = append(res, scored{s, sc})
+			}
+		}
+	}
+	si.Mu.Unlock()
+	sort.Slice(res, func(i, j int) bool {
+		ri, rj := res[i], res[j]
+		switch {
+		case ri.score != rj.score:
+			return ri.score > rj.score
+		case ri.Name != rj.Name:
+			return ri.Name < rj.Name
+		}
+		return ri.File < rj.File
+	})
+	if max > 0 && len(res) > max {
+		res = res[:max]
+	}
+	out := make([]ProjSym, len(res))
+	for i, r := range res {
+		out[i] = r.ProjSym
+	}
+	return out
+}
+
+// SymSearchMax is the maximum number of matches offered by SymbolSearch
+var SymSearchMax = 40
+
+// SymIndexStart starts building the symbol index of the project in the
+// background, so it is ready for the first search -- not for single files,
+// and not while background work is reduced (see PowerLow), when it is built
+// on the first search instead
+func (ge *Gide) SymIndexStart() {
+	if ge.SingleFile || ge.IsEmpty() || ge.ProjRoot == "" {
+		return
+	}
+	si := NewSymIndex(string(ge.ProjRoot))
+	ge.symIdx = si
+	if !ge.PowerLow() {
+		si.Start()
+	}
+}
+
+// SymIndexFile updates the symbol index for given file -- called whenever a
+// file is saved
+func (ge *Gide) SymIndexFile(fname string) {
+	si := ge.symIdx
+	if si == nil || !SymIndexFileOk(fname) || !strings.HasPrefix(fname, si.Root) {
+		return
+	}
+	if src, err := ioutil.ReadFile(fname); err == nil {
+		si.IndexFile(fname, src)
+	}
+}
+
+// SymbolSearchSyms returns the symbols of the project matching given
+// pattern, best first, from the language server of the active file if it
+// provides workspace symbols, and otherwise from the symbol index of the
+// project, waiting for it to be built if needed
+func (ge *Gide) SymbolSearchSyms(pat string) []ProjSym {
+	if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil {
+		if lc := ge.LspClientForBuf(tv.Buf); lc != nil {
+			if prov := lc.Caps["workspaceSymbolProvider"]; prov != nil && prov != false {
+				if syms, err := lc.WorkspaceSymbols(pat); err == nil {
+					return LspProjSyms(pat, syms, SymSearchMax)
+				}
+			}
+		}
+	}
+	si := ge.symIdx
+	if si == nil {
+		return nil
+	}
+	si.Mu.Lock()
+	built := si.Built
+	si.Mu.Unlock()
+	if !built {
+		ge.SetStatus("Indexing the symbols of the project...")
+		si.Start()
+		si.Wait()
+	}
+	return si.Search(pat, SymSearchMax)
+}
+
+// LspProjSyms returns the symbols from a language server, with their
+// container names as part of their names, sorted by how well they match
+// given pattern, up to max of them -- those that do not match are kept
+// last, as servers can match in their own ways
+func LspProjSyms(pat string, syms []LspSymbolInformation, max int) []ProjSym {
+	ps := make([]ProjSym, len(syms))
+	scores := make([]int, len(syms))
+	for i, s := range syms {
+		nm := s.Name
+		if s.ContainerName != "" && !strings.HasPrefix(nm, s.ContainerName+".") {
+			nm = s.ContainerName + "." + nm
+		}
+		st := s.Location.Range.Start
+		ps[i] = ProjSym{Name: nm, Kind: LspSymbolKind(s.Kind), File: LspPath(s.Location.URI), Ln: st.Line, Ch: st.Character}
+		sc, ok := FuzzyScore(pat, nm)
+		if !ok {
+			sc = -1 << 20
+		}
+		scores[i] = sc
+	}
+	idx := make([]int, len(ps))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return scores[idx[i]] > scores[idx[j]]
+	})
+	out := make([]ProjSym, len(idx))
+	for i, ix := range idx {
+		out[i] = ps[ix]
+	}
+	if max > 0 && len(out) > max {
+		out = out[:max]
+	}
+	return out
+}
+
+// SymbolSearch prompts for (part of) the name of a type, function or
+// method anywhere in the project, and jumps to it -- the name is matched
+// fuzzily, e.g., gesv for Gide.SaveActiveView, and if more than one symbol
+// matches, they are offered in a menu, best first, with their files
+func (ge *Gide) SymbolSearch() {
+	gi.StringPromptDialog(ge.Viewport, "", "symbol name, e.g., gesv for Gide.SaveActiveView",
+		gi.DlgOpts{Title: "Search Project Symbols", Prompt: "Jump to the symbol anywhere in the project that best matches the name -- its chars only need to be in order"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			gee, _ := recv.Embed(KiT_Gide).(*Gide)
+			pat := strings.TrimSpace(gi.StringPromptDialogValue(send.(*gi.Dialog)))
+			if pat == "" {
+				return
+			}
+			go gee.symbolSearchShow(pat)
+		})
+}
+
+// symbolSearchShow searches for given pattern, and jumps to the match, or
+// offers the matches in a menu
+func (ge *Gide) symbolSearchShow(pat string) {
+	ms := ge.SymbolSearchSyms(pat)
+	switch {
+	case len(ms) == 0:
+		ge.SetStatus("No symbol in the project matches " + pat)
+	case len(ms) == 1 || strings.EqualFold(ms[0].Name, pat):
+		ge.SymbolOpen(ms[0])
+	default:
+		nms := make([]string, len(ms))
+		for i, s := range ms {
+			nms[i] = fmt.Sprintf("%v  (%v)  %v:%d", s.Name, s.Kind, ge.Files.RelPath(gi.FileName(s.File)), s.Ln+1)
+		}
+		gi.StringsChooserPopup(nms, nms[0], ge.ActiveTextView(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			ac := send.(*gi.Action)
+			ge.SymbolOpen(ms[ac.Data.(int)])
+		})
+	}
+}
+
+// SymbolOpen opens the file of given symbol, and moves the cursor to it,
+// highlighting its name
+func (ge *Gide) SymbolOpen(s ProjSym) bool {
+	tv, _, ok := ge.LinkViewFile(gi.FileName(s.File))
+	if !ok {
+		ge.SetStatus("Could not open " + s.File)
+		return false
+	}
+	nm := s.Name[strings.LastIndex(s.Name, ".")+1:]
+	st := giv.TextPos{Ln: s.Ln, Ch: s.Ch}
+	tv.HighlightRegion(giv.TextRegion{Start: st, End: giv.TextPos{Ln: s.Ln, Ch: s.Ch + utf8.RuneCountInString(nm)}})
+	tv.SetCursorShow(st)
+	return true
+}
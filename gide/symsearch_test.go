@@ -0,0 +1,122 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSymIndex(t *testing.T) {
+	root, err := ioutil.TempDir("", "gide-symidx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	files := map[string]string{
+		"main.go":            "package main\n\nfunc main() {}\n\ntype Server struct{}\n\nfunc (s *Server) ServeHTTP() {}\n",
+		"lib/lib.go":         "package lib\n\n// SaveAll saves\nfunc SaveAll() error { return nil }\n",
+		"lib/notes.txt":      "func NotGo() {}\n",
+		"vendor/v/v.go":      "package v\n\nfunc SaveVendored() {}\n",
+		".git/x.go":          "package x\n\nfunc SaveHidden() {}\n",
+		"lib/broken/bad.go":  "package bad\n\nfunc SaveBroken() {}\n\nfunc (\n",
+		"lib/testdata/td.go": "package td\n\nfunc SaveTestdata() {}\n",
+	}
+	for fn, src := range files {
+		fp := filepath.Join(root, filepath.FromSlash(fn))
+		os.MkdirAll(filepath.Dir(fp), 0755)
+		if err := ioutil.WriteFile(fp, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	si := NewSymIndex(root)
+	if n := si.Build(); n != 3 {
+		t.Errorf("expected 3 files indexed, got %d", n)
+	}
+	si.Wait()
+
+	ms := si.Search("save", 0)
+	var nms []string
+	for _, m := range ms {
+		nms = append(nms, m.Name)
+	}
+	if len(ms) != 2 || ms[0].Name != "SaveAll" || ms[1].Name != "SaveBroken" {
+		t.Errorf("save: %v", nms)
+	}
+	if ms[0].File != filepath.Join(root, "lib", "lib.go") || ms[0].Ln != 3 || ms[0].Ch != 5 {
+		t.Errorf("SaveAll: %+v", ms[0])
+	}
+	ms = si.Search("shttp", 0)
+	if len(ms) != 1 || ms[0].Name != "Server.ServeHTTP" || ms[0].Kind != "method" {
+		t.Errorf("shttp: %+v", ms)
+	}
+	if ms := si.Search("s", 2); len(ms) != 2 {
+		t.Errorf("max: %+v", ms)
+	}
+
+	lib := filepath.Join(root, "lib", "lib.go")
+	si.IndexFile(lib, []byte("package lib\n\nfunc LoadAll() {}\n"))
+	if ms := si.Search("saveall", 0); len(ms) != 0 {
+		t.Errorf("reindexed file still has SaveAll: %+v", ms)
+	}
+	if ms := si.Search("loadall", 0); len(ms) != 1 {
+		t.Errorf("reindexed file has no LoadAll: %+v", ms)
+	}
+}
+
+func TestSymIndexCache(t *testing.T) {
+	root, err := ioutil.TempDir("", "gide-symcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	main := filepath.Join(root, "main.go")
+	lib := filepath.Join(root, "lib.go")
+	ioutil.WriteFile(main, []byte("package main\n\nfunc SaveAll() {}\n"), 0644)
+	ioutil.WriteFile(lib, []byte("package main\n\nfunc LoadAll() {}\n"), 0644)
+	si := NewSymIndex(root)
+	si.Build()
+	if err := si.SaveCache(); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Remove(lib)
+	cs := NewSymIndex(root)
+	if err := cs.LoadCache(); err != nil {
+		t.Fatal(err)
+	}
+	if n := cs.Build(); n != 0 {
+		t.Errorf("Build from the cache indexed %d files, want 0", n)
+	}
+	if ms := cs.Search("saveall", 0); len(ms) != 1 || ms[0].File != main {
+		t.Errorf("saveall from the cache: %+v", ms)
+	}
+	if ms := cs.Search("loadall", 0); len(ms) != 0 {
+		t.Errorf("removed file still indexed: %+v", ms)
+	}
+	if NewSymIndex(filepath.Join(root, "sub")).LoadCache() == nil {
+		t.Errorf("LoadCache of another root")
+	}
+}
+
+func TestLspProjSyms(t *testing.T) {
+	syms := []LspSymbolInformation{
+		{Name: "Other", Kind: 12, Location: LspLocation{URI: "file:///a.go"}},
+		{Name: "Save", Kind: 6, ContainerName: "Gide", Location: LspLocation{URI: "file:///b.go", Range: LspRange{Start: LspPosition{Line: 4, Character: 2}}}},
+		{Name: "save", Kind: 12, Location: LspLocation{URI: "file:///c.go"}},
+	}
+	ps := LspProjSyms("save", syms, 0)
+	if len(ps) != 3 || ps[0].Name != "save" || ps[1].Name != "Gide.Save" || ps[2].Name != "Other" {
+		t.Fatalf("order: %+v", ps)
+	}
+	if ps[1].File != "/b.go" || ps[1].Ln != 4 || ps[1].Ch != 2 || ps[1].Kind != "method" {
+		t.Errorf("Gide.Save: %+v", ps[1])
+	}
+	if ps := LspProjSyms("save", syms, 1); len(ps) != 1 {
+		t.Errorf("max: %+v", ps)
+	}
+}
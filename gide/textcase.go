@@ -0,0 +1,207 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/kit"
+)
+
+// TextCase is a case or identifier style that text can be converted to
+type TextCase int
+
+const (
+	// TextCaseUpper is UPPER CASE
+	TextCaseUpper TextCase = iota
+
+	// TextCaseLower is lower case
+	TextCaseLower
+
+	// TextCaseTitle is Title Case, with the first letter of each word upper
+	// case and the others lower case
+	TextCaseTitle
+
+	// TextCaseCamel is camelCase, for identifiers
+	TextCaseCamel
+
+	// TextCasePascal is PascalCase, for identifiers, e.g., exported Go names
+	TextCasePascal
+
+	// TextCaseSnake is snake_case, for identifiers
+	TextCaseSnake
+
+	// TextCaseKebab is kebab-case, for identifiers, e.g., in css or urls
+	TextCaseKebab
+
+	TextCaseN
+)
+
+//go:generate stringer -type=TextCase
+
+var KiT_TextCase = kit.Enums.AddEnumAltLower(TextCaseN, false, nil, "TextCase")
+
+func (ev TextCase) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *TextCase) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// IdentWords returns the words of given identifier, in any style: they are
+// separated by _ and -, and by changes from lower to upper case, with runs
+// of upper case letters kept together as acronyms, e.g., HTTPServer_id is
+// HTTP, Server, id
+func IdentWords(id string) []string {
+	var wds []string
+	rs := []rune(id)
+	st := 0
+	for i := 0; i <= len(rs); i++ {
+		if i == len(rs) || rs[i] == '_' || rs[i] == '-' {
+			if i > st {
+				wds = append(wds, string(rs[st:i]))
+			}
+			st = i + 1
+			continue
+		}
+		if i == st || !unicode.IsUpper(rs[i]) {
+			continue
+		}
+		prv := rs[i-1]
+		nxtLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+		if unicode.IsLower(prv) || unicode.IsDigit(prv) || (unicode.IsUpper(prv) && nxtLower) {
+			wds = append(wds, string(rs[st:i]))
+			st = i
+		}
+	}
+	return wds
+}
+
+// titleWord returns given word with its first letter upper case and the
+// others lower case
+func titleWord(w string) string {
+	rs := []rune(strings.ToLower(w))
+	if len(rs) > 0 {
+		rs[0] = unicode.ToUpper(rs[0])
+	}
+	return string(rs)
+}
+
+var (
+	caseIdentRe = regexp.MustCompile(`[\p{L}\p{N}]+(?:[_-]+[\p{L}\p{N}]+)*`)
+	caseWordRe  = regexp.MustCompile(`[\p{L}\p{N}]+(?:'[\p{L}\p{N}]+)*`)
+)
+
+// ToTextCase returns given text converted to given case -- the identifier
+// styles apply to each identifier-like run of letters, digits, _ and - in
+// the text, keeping any _ at their start or end, and the rest of the text
+func ToTextCase(s string, tc TextCase) string {
+	switch tc {
+	case TextCaseUpper:
+		return strings.ToUpper(s)
+	case TextCaseLower:
+		return strings.ToLower(s)
+	case TextCaseTitle:
+		return caseWordRe.ReplaceAllStringFunc(s, titleWord)
+	}
+	return caseIdentRe.ReplaceAllStringFunc(s, func(id string) string {
+		wds := IdentWords(id)
+		for i, w := range wds {
+			switch {
+			case tc == TextCaseSnake || tc == TextCaseKebab:
+				wds[i] = strings.ToLower(w)
+			case tc == TextCaseCamel && i == 0:
+				wds[i] = strings.ToLower(w)
+			default:
+				wds[i] = titleWord(w)
+			}
+		}
+		switch tc {
+		case TextCaseSnake:
+			return strings.Join(wds, "_")
+		case TextCaseKebab:
+			return strings.Join(wds, "-")
+		}
+		return strings.Join(wds, "")
+	})
+}
+
+// ConvertCase converts the selection in the active view, or else the word
+// at the cursor, to given case or identifier style, and selects it
+func (ge *Gide) ConvertCase(tc TextCase) bool {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || tv.IsInactive() {
+		return false
+	}
+	tb := tv.Buf
+	var st, ed giv.TextPos
+	var txt string
+	if sel := tv.Selection(); sel != nil {
+		st, ed = sel.Reg.Start, sel.Reg.End
+		txt = string(sel.ToBytes())
+	} else {
+		if tv.CursorPos.Ln >= len(tb.Lines) {
+			return false
+		}
+		wd, ws, we := WordAtPos(tb.Lines[tv.CursorPos.Ln], tv.CursorPos.Ch)
+		if wd == "" {
+			ge.SetStatus("No word at the cursor to convert")
+			return false
+		}
+		st, ed = giv.TextPos{Ln: tv.CursorPos.Ln, Ch: ws}, giv.TextPos{Ln: tv.CursorPos.Ln, Ch: we}
+		txt = wd
+	}
+	out := ToTextCase(txt, tc)
+	if out == txt {
+		return false
+	}
+	tv.SelectReset()
+	tb.DeleteText(st, ed, true, true)
+	tb.InsertText(st, []byte(out), true, true)
+	reg := giv.TextRegion{Start: st, End: TextPosMove(tb.Lines, st, len([]rune(out)))}
+	updt := tv.UpdateStart()
+	tv.SetCursorShow(reg.End)
+	tv.SelectReg = reg
+	tv.UpdateEnd(updt)
+	return true
+}
+
+// CaseUpper converts the selection, or the word at the cursor, to UPPER CASE
+func (ge *Gide) CaseUpper() {
+	ge.ConvertCase(TextCaseUpper)
+}
+
+// CaseLower converts the selection, or the word at the cursor, to lower case
+func (ge *Gide) CaseLower() {
+	ge.ConvertCase(TextCaseLower)
+}
+
+// CaseTitle converts the selection, or the word at the cursor, to Title Case
+func (ge *Gide) CaseTitle() {
+	ge.ConvertCase(TextCaseTitle)
+}
+
+// CaseCamel converts the identifiers in the selection, or at the cursor, to
+// camelCase
+func (ge *Gide) CaseCamel() {
+	ge.ConvertCase(TextCaseCamel)
+}
+
+// CasePascal converts the identifiers in the selection, or at the cursor,
+// to PascalCase
+func (ge *Gide) CasePascal() {
+	ge.ConvertCase(TextCasePascal)
+}
+
+// CaseSnake converts the identifiers in the selection, or at the cursor, to
+// snake_case
+func (ge *Gide) CaseSnake() {
+	ge.ConvertCase(TextCaseSnake)
+}
+
+// CaseKebab converts the identifiers in the selection, or at the cursor, to
+// kebab-case
+func (ge *Gide) CaseKebab() {
+	ge.ConvertCase(TextCaseKebab)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIdentWords(t *testing.T) {
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"fooBar", []string{"foo", "Bar"}},
+		{"HTTPServer_id", []string{"HTTP", "Server", "id"}},
+		{"get-user-ID", []string{"get", "user", "ID"}},
+		{"utf8Decode", []string{"utf8", "Decode"}},
+		{"__x__", []string{"x"}},
+		{"already", []string{"already"}},
+	}
+	for _, tt := range tests {
+		if got := IdentWords(tt.id); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestToTextCase(t *testing.T) {
+	tests := []struct {
+		in   string
+		tc   TextCase
+		want string
+	}{
+		{"Hello wOrld", TextCaseUpper, "HELLO WORLD"},
+		{"Hello wOrld", TextCaseLower, "hello world"},
+		{"hello wOrld, it's", TextCaseTitle, "Hello World, It's"},
+		{"user_name", TextCaseCamel, "userName"},
+		{"HTTPServer", TextCaseCamel, "httpServer"},
+		{"user-id", TextCasePascal, "UserId"},
+		{"userName := getHTTPResponse(x)", TextCaseSnake, "user_name := get_http_response(x)"},
+		{"fontSize: 12px", TextCaseKebab, "font-size: 12px"},
+		{"_privateVar", TextCaseSnake, "_private_var"},
+		{"a - b", TextCaseCamel, "a - b"},
+	}
+	for _, tt := range tests {
+		if got := ToTextCase(tt.in, tt.tc); got != tt.want {
+			t.Errorf("%q to %v: got %q, want %q", tt.in, tt.tc, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,582 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// TodoParams are parameters for the TODO / FIXME comment scanner
+type TodoParams struct {
+	Tags       []string  `desc:"comment tags to look for, e.g., TODO, FIXME, HACK -- matched as whole words within comments"`
+	IgnoreCase bool      `desc:"ignore case when matching tags"`
+	Langs      LangNames `desc:"languages for files to scan -- leave empty for all files"`
+}
+
+// Defaults sets the standard set of tags
+func (tp *TodoParams) Defaults() {
+	tp.Tags = []string{"TODO", "FIXME", "HACK"}
+	tp.IgnoreCase = true
+}
+
+// TodoItem is one tagged comment found in a file
+type TodoItem struct {
+	Tag  string `desc:"tag that was found, as listed in the params"`
+	Ln   int    `desc:"line number, 0-based"`
+	Ch   int    `desc:"char position of the tag within the line, 0-based"`
+	Text string `desc:"text of the comment following the tag"`
+}
+
+// TodoFileResults are the tagged comments found in one file
+type TodoFileResults struct {
+	Node  *giv.FileNode
+	Items []TodoItem
+}
+
+// TodoCommentMarks are the comment markers used when the language of a file
+// does not specify one
+var TodoCommentMarks = []string{"//", "/*", "#", "--", ";", "<!--", "%"}
+
+// TodoInComment returns true if the text before a tag contains a comment
+// marker, or is a continuation line of a block comment
+func TodoInComment(pre []byte, marks []string) bool {
+	for _, cm := range marks {
+		if bytes.Contains(pre, []byte(cm)) {
+			return true
+		}
+	}
+	tp := bytes.TrimSpace(pre)
+	return len(tp) == 1 && tp[0] == '*'
+}
+
+func todoIsWordRune(r byte) bool {
+	return r == '_' || unicode.IsLetter(rune(r)) || unicode.IsDigit(rune(r))
+}
+
+// TodoScanLines returns all the tagged comments in given lines of text --
+// tags must be whole words, followed by a colon, paren, space or end of line,
+// and preceded on the line by one of the given comment markers -- the first
+// occurrence of a tag on a line that is one of those is its item, e.g., not
+// one in a string before the comment.
+func TodoScanLines(lines [][]byte, tags []string, ignoreCase bool, marks []string) []TodoItem {
+	var items []TodoItem
+	for ln, lb := range lines {
+		sl := lb
+		if ignoreCase {
+			sl = bytes.ToLower(lb)
+		}
+		for _, tag := range tags {
+			if tag == "" {
+				continue
+			}
+			tb := []byte(tag)
+			if ignoreCase {
+				tb = bytes.ToLower(tb)
+			}
+			if i := todoTagIndex(sl, lb, tb, marks); i >= 0 {
+				items = append(items, TodoItem{Tag: tag, Ln: ln, Ch: i, Text: todoText(lb[i+len(tb):])})
+				break // one per line
+			}
+		}
+	}
+	return items
+}
+
+// todoTagIndex returns the index of the first occurrence of tag tb in line
+// sl, which is lb in lower case when ignoring case, that is a tagged
+// comment, or -1 if there is none
+func todoTagIndex(sl, lb, tb []byte, marks []string) int {
+	for st := 0; st < len(sl); {
+		i := bytes.Index(sl[st:], tb)
+		if i < 0 {
+			return -1
+		}
+		i += st
+		st = i + 1
+		if i > 0 && todoIsWordRune(sl[i-1]) {
+			continue
+		}
+		ei := i + len(tb)
+		if ei < len(sl) {
+			nc := sl[ei]
+			if nc != ':' && nc != '(' && nc != ' ' && nc != '\t' {
+				continue
+			}
+		}
+		if TodoInComment(lb[:i], marks) {
+			return i
+		}
+	}
+	return -1
+}
+
+// todoText returns the text of a tagged comment, from the text after its tag
+func todoText(txt []byte) string {
+	if len(txt) > 0 && txt[0] == '(' { // e.g., TODO(name):
+		if ci := bytes.IndexByte(txt, ')'); ci >= 0 {
+			txt = txt[ci+1:]
+		}
+	}
+	txt = bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(txt), []byte(":")))
+	txt = bytes.TrimSuffix(txt, []byte("*/"))
+	txt = bytes.TrimSuffix(txt, []byte("-->"))
+	return string(bytes.TrimSpace(txt))
+}
+
+// TodoMarksForFilename returns the comment markers to use for given file
+func TodoMarksForFilename(fname string) []string {
+	ls := LangsForFilename(fname)
+	var marks []string
+	for _, l := range ls {
+		if l.Comment != "" {
+			marks = append(marks, l.Comment)
+		}
+	}
+	if len(marks) == 0 {
+		return TodoCommentMarks
+	}
+	return append(marks, "/*")
+}
+
+// TodoMaxLine is the longest line of a file that is scanned for tagged
+// comments, in bytes -- reading a file stops with an error at a longer one
+var TodoMaxLine = 16 << 20
+
+// TodoSource is a file to be scanned for tagged comments: a copy of the
+// lines of its buffer, if it is open, and otherwise its path, to read it
+// from disk -- so that it can be scanned in the background
+type TodoSource struct {
+	Node  *giv.FileNode
+	Path  string
+	Name  string
+	Lines [][]byte
+}
+
+// NewTodoSource returns the source of given file node for scanning -- it
+// copies the lines of its buffer, so it must be called on the GUI
+// goroutine, where the buffer is edited
+func NewTodoSource(fn *giv.FileNode) TodoSource {
+	src := TodoSource{Node: fn, Path: string(fn.FPath), Name: fn.Nm}
+	if fn.IsOpen() && fn.Buf != nil {
+		src.Lines = bytes.Split(fn.Buf.LinesToBytesCopy(), []byte("\n"))
+	}
+	return src
+}
+
+// TodoReadLines returns the lines of the file at given path, and any error
+// reading it, e.g., a line longer than TodoMaxLine -- with the lines before
+// the error
+func TodoReadLines(path string) ([][]byte, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	var lines [][]byte
+	scan := bufio.NewScanner(fp)
+	scan.Buffer(make([]byte, 0, 64*1024), TodoMaxLine)
+	for scan.Scan() {
+		lines = append(lines, append([]byte(nil), scan.Bytes()...))
+	}
+	if err := scan.Err(); err != nil {
+		return lines, fmt.Errorf("%v: line %d: %v", path, len(lines)+1, err)
+	}
+	return lines, nil
+}
+
+// Scan returns the tagged comments of the source, reading it from disk if
+// it is not open, with any error reading it -- the items of the lines read
+// before an error are still returned
+func (src *TodoSource) Scan(tp *TodoParams) ([]TodoItem, error) {
+	lines, err := src.Lines, error(nil)
+	if lines == nil {
+		lines, err = TodoReadLines(src.Path)
+	}
+	return TodoScanLines(lines, tp.Tags, tp.IgnoreCase, TodoMarksForFilename(src.Name)), err
+}
+
+// TodoFileOk returns true if given file node should be scanned for todos
+func TodoFileOk(fn *giv.FileNode, tp *TodoParams) bool {
+	if fn.IsDir() || fn.IsExec() || fn.Info.Kind == "octet-stream" || fn.IsAutoSave() {
+		return false
+	}
+	return LangNamesMatchFilename(fn.Nm, tp.Langs)
+}
+
+// TodoTreeSources returns the files to scan for tagged comments, starting
+// at given node (only within open directories, as in FileTreeSearch) -- it
+// walks the file tree, and copies the lines of open buffers, so it must be
+// called on the GUI goroutine
+func TodoTreeSources(start *giv.FileNode, tp *TodoParams) []TodoSource {
+	var srcs []TodoSource
+	start.FuncDownMeFirst(0, start, func(k ki.Ki, level int, d interface{}) bool {
+		sfn := k.Embed(giv.KiT_FileNode).(*giv.FileNode)
+		if sfn.IsDir() && !sfn.IsOpen() {
+			return false // don't go down into closed directories!
+		}
+		if TodoFileOk(sfn, tp) {
+			srcs = append(srcs, NewTodoSource(sfn))
+		}
+		return true
+	})
+	return srcs
+}
+
+// TodoScanSources returns the tagged comments of given files, sorted by file
+// path, with the errors reading any of them -- it only reads the files, so
+// it can be run in the background
+func TodoScanSources(srcs []TodoSource, tp *TodoParams) ([]TodoFileResults, []error) {
+	res := make([]TodoFileResults, 0)
+	var errs []error
+	for i := range srcs {
+		items, err := srcs[i].Scan(tp)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if len(items) > 0 {
+			res = append(res, TodoFileResults{srcs[i].Node, items})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Node.FPath < res[j].Node.FPath
+	})
+	return res, errs
+}
+
+// TodoView is a widget that displays the TODO / FIXME etc comments found in
+// the project, grouped by file, in a TextView with links to each one.
+type TodoView struct {
+	gi.Layout
+	Gide    *Gide             `json:"-" xml:"-" desc:"parent gide project"`
+	Results []TodoFileResults `json:"-" xml:"-" desc:"current results, by file"`
+	ResMu   sync.Mutex        `json:"-" xml:"-" view:"-" desc:"mutex protecting results -- scanning is done in the background"`
+}
+
+var KiT_TodoView = kit.Types.AddType(&TodoView{}, TodoViewProps)
+
+// Params returns the todo params
+func (tv *TodoView) Params() *TodoParams {
+	return &tv.Gide.Prefs.Todo
+}
+
+// Rescan rescans the entire project in the background, and displays the
+// results when done -- the files to scan, and the text of open buffers, are
+// collected here, before scanning them
+func (tv *TodoView) Rescan() {
+	ge := tv.Gide
+	root := ge.Files.Embed(giv.KiT_FileNode).(*giv.FileNode)
+	tp := *tv.Params()
+	tp.Tags = append([]string(nil), tp.Tags...)
+	srcs := TodoTreeSources(root, &tp)
+	ge.SetStatus("Scanning project for " + strings.Join(tp.Tags, ", ") + "...")
+	go func() {
+		res, errs := TodoScanSources(srcs, &tp)
+		for _, err := range errs {
+			log.Printf("gide.TodoView.Rescan: %v\n", err)
+		}
+		tv.ResMu.Lock()
+		tv.Results = res
+		tv.ResMu.Unlock()
+		tv.ShowResults()
+		msg := fmt.Sprintf("Found %d todo items in %d files", tv.NItems(), len(res))
+		if len(errs) > 0 {
+			msg += fmt.Sprintf(" -- could not read all of %d files: %v", len(errs), errs[0])
+		}
+		ge.SetStatus(msg)
+	}()
+}
+
+// RescanFile rescans just the given file, updating the results for it --
+// called whenever a file is saved
+func (tv *TodoView) RescanFile(fn *giv.FileNode) {
+	tp := tv.Params()
+	var items []TodoItem
+	if TodoFileOk(fn, tp) {
+		src := NewTodoSource(fn)
+		var err error
+		if items, err = src.Scan(tp); err != nil {
+			log.Printf("gide.TodoView.RescanFile: %v\n", err)
+			tv.Gide.SetStatus(fmt.Sprintf("Could not read all of %v: %v", fn.Nm, err))
+		}
+	}
+	tv.ResMu.Lock()
+	fi := -1
+	for i, fr := range tv.Results {
+		if fr.Node == fn || fr.Node.FPath == fn.FPath {
+			fi = i
+			break
+		}
+	}
+	switch {
+	case fi >= 0 && len(items) > 0:
+		tv.Results[fi].Items = items
+	case fi >= 0:
+		tv.Results = append(tv.Results[:fi], tv.Results[fi+1:]...)
+	case len(items) > 0:
+		tv.Results = append(tv.Results, TodoFileResults{fn, items})
+		sort.Slice(tv.Results, func(i, j int) bool {
+			return tv.Results[i].Node.FPath < tv.Results[j].Node.FPath
+		})
+	}
+	tv.ResMu.Unlock()
+	tv.ShowResults()
+}
+
+// NItems returns the total number of items in the current results
+func (tv *TodoView) NItems() int {
+	tv.ResMu.Lock()
+	defer tv.ResMu.Unlock()
+	n := 0
+	for _, fr := range tv.Results {
+		n += len(fr.Items)
+	}
+	return n
+}
+
+// ShowResults renders the current results into the results buffer
+func (tv *TodoView) ShowResults() {
+	tbuf, _ := tv.Gide.FindOrMakeCmdBuf("Todo", true)
+
+	tv.ResMu.Lock()
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	for _, fr := range tv.Results {
+		fp := fr.Node.Info.Path
+		fn := fr.Node.MyRelPath()
+		lstr := fmt.Sprintf(`%v: %v`, fn, len(fr.Items))
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte(fmt.Sprintf(`<b>%v</b>`, lstr)))
+		for _, it := range fr.Items {
+			ln := it.Ln + 1
+			ch := it.Ch + 1
+			ech := ch + len(it.Tag)
+			fnstr := fmt.Sprintf("%v:%d:%d", fn, ln, ch)
+			txt := html.EscapeString(it.Text)
+			lstr = fmt.Sprintf(`	%v: %v %s`, fnstr, it.Tag, txt)
+			outlns = append(outlns, []byte(lstr))
+			mstr := fmt.Sprintf(`	<a href="todo:///%v#L%vC%v-L%vC%v">%v</a>: <b>%v</b> %s`, fp, ln, ch, ln, ech, fnstr, it.Tag, txt)
+			outmus = append(outmus, []byte(mstr))
+		}
+		outlns = append(outlns, []byte(""))
+		outmus = append(outmus, []byte(""))
+	}
+	tv.ResMu.Unlock()
+
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	tbuf.AppendTextMarkup(ltxt, mtxt, false, true) // no save undo, yes signal
+}
+
+// NextTodo shows next todo item
+func (tv *TodoView) NextTodo() {
+	ttv := tv.TextView()
+	ok := ttv.CursorNextLink(true) // wrap
+	if ok {
+		ttv.OpenLinkAt(ttv.CursorPos)
+	}
+}
+
+// PrevTodo shows previous todo item
+func (tv *TodoView) PrevTodo() {
+	ttv := tv.TextView()
+	ok := ttv.CursorPrevLink(true) // wrap
+	if ok {
+		ttv.OpenLinkAt(ttv.CursorPos)
+	}
+}
+
+// OpenTodoURL opens given todo:/// url from the todo list
+func (tv *TodoView) OpenTodoURL(ur string, ttv *giv.TextView) bool {
+	ge := tv.Gide
+	up, err := url.Parse(ur)
+	if err != nil {
+		log.Printf("TodoView OpenTodoURL parse err: %v\n", err)
+		return false
+	}
+	fpath := up.Path[1:] // has double //
+	pos := up.Fragment
+	etv, _, ok := ge.LinkViewFile(gi.FileName(fpath))
+	if !ok {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Open File at Link", Prompt: fmt.Sprintf("Could not find or open file path in project: %v", fpath)}, true, false, nil, nil)
+		return false
+	}
+	if pos == "" {
+		return true
+	}
+	reg := giv.TextRegion{}
+	if reg.FromString(pos) {
+		etv.HighlightRegion(reg)
+		etv.SetCursorShow(reg.Start)
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// UpdateView updates view with current settings
+func (tv *TodoView) UpdateView(ge *Gide) {
+	tv.Gide = ge
+	mods, updt := tv.StdTodoConfig()
+	tv.ConfigToolbar()
+	tf := tv.TagsText()
+	tf.SetText(strings.Join(tv.Params().Tags, " "))
+	ib := tv.IgnoreBox()
+	ib.SetChecked(tv.Params().IgnoreCase)
+	tvly := tv.TextViewLay()
+	tv.Gide.ConfigOutputTextView(tvly)
+	if mods {
+		tv.UpdateEnd(updt)
+	}
+}
+
+// StdConfig returns a TypeAndNameList for configuring a standard Frame
+// -- can modify as desired before calling ConfigChildren on Frame using this
+func (tv *TodoView) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "todobar")
+	config.Add(gi.KiT_Layout, "todotext")
+	return config
+}
+
+// StdTodoConfig configures a standard setup of the overall layout -- returns
+// mods, updt from ConfigChildren and does NOT call UpdateEnd
+func (tv *TodoView) StdTodoConfig() (mods, updt bool) {
+	tv.Lay = gi.LayoutVert
+	tv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := tv.StdConfig()
+	mods, updt = tv.ConfigChildren(config, false)
+	return
+}
+
+// TodoBar returns the todo toolbar
+func (tv *TodoView) TodoBar() *gi.ToolBar {
+	tbi, ok := tv.ChildByName("todobar", 0)
+	if !ok {
+		return nil
+	}
+	return tbi.(*gi.ToolBar)
+}
+
+// TagsText returns the tags textfield in toolbar
+func (tv *TodoView) TagsText() *gi.TextField {
+	tb := tv.TodoBar()
+	if tb == nil {
+		return nil
+	}
+	tfi, ok := tb.ChildByName("tags", 1)
+	if !ok {
+		return nil
+	}
+	return tfi.(*gi.TextField)
+}
+
+// IgnoreBox returns the ignore case checkbox in toolbar
+func (tv *TodoView) IgnoreBox() *gi.CheckBox {
+	tb := tv.TodoBar()
+	if tb == nil {
+		return nil
+	}
+	tfi, ok := tb.ChildByName("ignore-case", 2)
+	if !ok {
+		return nil
+	}
+	return tfi.(*gi.CheckBox)
+}
+
+// TextViewLay returns the todo results TextView layout
+func (tv *TodoView) TextViewLay() *gi.Layout {
+	tvi, ok := tv.ChildByName("todotext", 1)
+	if !ok {
+		return nil
+	}
+	return tvi.(*gi.Layout)
+}
+
+// TextView returns the todo results TextView
+func (tv *TodoView) TextView() *giv.TextView {
+	tvly := tv.TextViewLay()
+	if tvly == nil {
+		return nil
+	}
+	return tvly.KnownChild(0).(*giv.TextView)
+}
+
+// ConfigToolbar adds toolbar.
+func (tv *TodoView) ConfigToolbar() {
+	tb := tv.TodoBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+
+	rescan := tb.AddNewChild(gi.KiT_Action, "rescan").(*gi.Action)
+	rescan.SetText("Rescan:")
+	rescan.Tooltip = "Rescan all project files for the given comment tags. Only open folders in file browser will be scanned -- adjust those to scope the scan"
+	rescan.ActionSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv, _ := recv.Embed(KiT_TodoView).(*TodoView)
+		tvv.Rescan()
+	})
+
+	tags := tb.AddNewChild(gi.KiT_TextField, "tags").(*gi.TextField)
+	tags.SetStretchMaxWidth()
+	tags.Tooltip = "Comment tags to look for, separated by spaces -- hit enter to rescan"
+	tags.TextFieldSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			tvv, _ := recv.Embed(KiT_TodoView).(*TodoView)
+			tf := send.(*gi.TextField)
+			tvv.Params().Tags = strings.Fields(strings.Replace(tf.Text(), ",", " ", -1))
+			tvv.Rescan()
+		}
+	})
+
+	ic := tb.AddNewChild(gi.KiT_CheckBox, "ignore-case").(*gi.CheckBox)
+	ic.SetText("Ignore Case")
+	ic.ButtonSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			tvv, _ := recv.Embed(KiT_TodoView).(*TodoView)
+			cb := send.(*gi.CheckBox)
+			tvv.Params().IgnoreCase = cb.IsChecked()
+		}
+	})
+
+	next := tb.AddNewChild(gi.KiT_Action, "next").(*gi.Action)
+	next.SetIcon("widget-wedge-down")
+	next.Tooltip = "go to next item"
+	next.ActionSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv, _ := recv.Embed(KiT_TodoView).(*TodoView)
+		tvv.NextTodo()
+	})
+
+	prev := tb.AddNewChild(gi.KiT_Action, "prev").(*gi.Action)
+	prev.SetIcon("widget-wedge-up")
+	prev.Tooltip = "go to previous item"
+	prev.ActionSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv, _ := recv.Embed(KiT_TodoView).(*TodoView)
+		tvv.PrevTodo()
+	})
+}
+
+var TodoViewProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTodoScanLines(t *testing.T) {
+	lines := [][]byte{
+		[]byte(`s := "TODO" // TODO: fix this`),
+		[]byte(`x := TODOS // FIXME(bob): and this`),
+		[]byte(`// todo in lower case`),
+		[]byte(`no tags here`),
+	}
+	got := TodoScanLines(lines, []string{"TODO", "FIXME"}, false, []string{"//"})
+	want := []TodoItem{
+		{Tag: "TODO", Ln: 0, Ch: 15, Text: "fix this"},
+		{Tag: "FIXME", Ln: 1, Ch: 14, Text: "and this"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TodoScanLines = %+v, want %+v", got, want)
+	}
+	if got := TodoScanLines(lines[2:], []string{"TODO"}, true, []string{"//"}); len(got) != 1 || got[0].Text != "in lower case" {
+		t.Errorf("TodoScanLines ignore case = %+v", got)
+	}
+}
+
+func TestTodoReadLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-todo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "long.go")
+	long := "// " + strings.Repeat("x", 100*1024)
+	if err := ioutil.WriteFile(fn, []byte(long+"\n// TODO: after\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lines, err := TodoReadLines(fn)
+	if err != nil || len(lines) != 2 {
+		t.Fatalf("TodoReadLines = %d lines, %v -- want 2, no error", len(lines), err)
+	}
+	defer func(max int) { TodoMaxLine = max }(TodoMaxLine)
+	TodoMaxLine = 1024
+	src := TodoSource{Path: fn, Name: "long.go"}
+	if _, err := src.Scan(&TodoParams{Tags: []string{"TODO"}}); err == nil {
+		t.Errorf("Scan of a line longer than TodoMaxLine: no error")
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki"
+)
+
+// TourStep is one step of a tour: a short text about one part of the
+// window, shown next to it
+type TourStep struct {
+	ID     string `desc:"name of the step, after which the steps of other tours can be inserted (see Tour.After)"`
+	Title  string `desc:"title of the step"`
+	Text   string `desc:"text of the step, in html markup -- {Name} is replaced with the key sequence of the Name key function (KeyFunName) in the active key map, e.g., {BufSelect}"`
+	Target string `desc:"part of the window that the step is about, which gets the focus and next to which the step is shown: files, editor, editor2, tabs, vistabs, toolbar or statusbar, or tab:Label for the main tab with that label -- the whole window if empty"`
+	Cmd    string `desc:"command that is run when the step is shown: the name of a Gide method in its menus or toolbar, e.g., ViewOpenNodeName -- arguments, if any, are prompted for"`
+}
+
+// Tour is an interactive tour of gide, shown step by step, for new users --
+// tours are written in JSON, in the tours folder of the prefs directory, or
+// added by RegisterTour, and can add their steps to other tours
+type Tour struct {
+	Name    string     `desc:"name of the tour"`
+	Desc    string     `desc:"brief description"`
+	Extends string     `desc:"name of a tour that the steps of this one are added to, instead of being a tour of their own, e.g., to show a plugin or the setup of a site in the standard tour"`
+	After   string     `desc:"ID of the step of the Extends tour after which the steps are inserted -- at its end if empty or not found"`
+	Steps   []TourStep `desc:"the steps of the tour"`
+}
+
+// Label satisfies the Labeler interface
+func (tr *Tour) Label() string {
+	return tr.Name
+}
+
+// Tours is a list of tours
+type Tours []*Tour
+
+// AvailTours are the available tours: StdTours, then those that are
+// registered and those in the TourDirName folder of the prefs directory
+var AvailTours Tours
+
+// TourDirName is the name of the folder in the prefs directory with the
+// tours, in JSON files, one tour per file
+var TourDirName = "tours"
+
+func init() {
+	for _, tr := range StdTours {
+		ctr := *tr // steps are added to the copy
+		AvailTours.Add(&ctr)
+	}
+}
+
+// TourByName returns the tour with given name
+func (ts Tours) TourByName(name string) (*Tour, bool) {
+	for _, tr := range ts {
+		if tr.Name == name {
+			return tr, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of the tours
+func (ts Tours) Names() []string {
+	nms := make([]string, len(ts))
+	for i, tr := range ts {
+		nms[i] = tr.Name
+	}
+	return nms
+}
+
+// Add adds given tour to the list, replacing the one of the same name, or,
+// if it Extends another tour in the list, inserts its steps into that one,
+// after the step with the After ID -- returns an error if it extends a
+// tour that is not in the list
+func (ts *Tours) Add(tr *Tour) error {
+	if tr.Extends == "" {
+		for i, ot := range *ts {
+			if ot.Name == tr.Name {
+				(*ts)[i] = tr
+				return nil
+			}
+		}
+		*ts = append(*ts, tr)
+		return nil
+	}
+	et, ok := ts.TourByName(tr.Extends)
+	if !ok {
+		return fmt.Errorf("tour %v: no tour named %v to extend", tr.Name, tr.Extends)
+	}
+	at := len(et.Steps)
+	for i, st := range et.Steps {
+		if tr.After != "" && st.ID == tr.After {
+			at = i + 1
+			break
+		}
+	}
+	steps := make([]TourStep, 0, len(et.Steps)+len(tr.Steps))
+	steps = append(steps, et.Steps[:at]...)
+	steps = append(steps, tr.Steps...)
+	et.Steps = append(steps, et.Steps[at:]...)
+	return nil
+}
+
+// OpenJSON opens a tour from a JSON-formatted file
+func (tr *Tour) OpenJSON(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, tr); err != nil {
+		return fmt.Errorf("tour %v: %v", filename, err)
+	}
+	return nil
+}
+
+// OpenDir adds the tours in the .json files in given folder, in the order
+// of their names, so the tours that extend others can be named to come
+// after them -- returns the first error
+func (ts *Tours) OpenDir(dir string) error {
+	fns, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	sort.Strings(fns)
+	var rerr error
+	for _, fn := range fns {
+		tr := &Tour{}
+		err := tr.OpenJSON(gi.FileName(fn))
+		if err == nil {
+			err = ts.Add(tr)
+		}
+		if err != nil && rerr == nil {
+			rerr = err
+		}
+	}
+	return rerr
+}
+
+// RegisterTour adds given tour to AvailTours -- for the tours of plugins,
+// called from their init functions
+func RegisterTour(tr *Tour) {
+	if err := AvailTours.Add(tr); err != nil {
+		log.Println(err)
+	}
+}
+
+// OpenTours adds the tours in the TourDirName folder of the prefs directory
+// to AvailTours -- called by InitPrefs
+func OpenTours() {
+	pdir := oswin.TheApp.AppPrefsDir()
+	if err := AvailTours.OpenDir(filepath.Join(pdir, TourDirName)); err != nil {
+		log.Println(err)
+	}
+}
+
+var tourKeyRe = regexp.MustCompile(`\{([A-Za-z0-9]+)\}`)
+
+// TourText returns given text of a tour step, with the {Name} key function
+// references replaced by what keys returns for them -- those for which it
+// returns "" are left as they are
+func TourText(text string, keys func(name string) string) string {
+	return tourKeyRe.ReplaceAllStringFunc(text, func(s string) string {
+		if k := keys(s[1 : len(s)-1]); k != "" {
+			return k
+		}
+		return s
+	})
+}
+
+// TourKey returns the key sequence of the key function of given name, e.g.,
+// BufSelect, in the active key map, for TourText
+func TourKey(name string) string {
+	var kf KeyFuns
+	if err := kf.FromString("KeyFun" + name); err != nil {
+		return ""
+	}
+	ks := ChordForFun(kf)
+	if ks.Key1 == "" {
+		return "<i>(no key)</i>"
+	}
+	return "<b>" + strings.TrimSpace(ks.String()) + "</b>"
+}
+
+// TourTargets are the panels for the targets of the tour steps
+var TourTargets = map[string]int{
+	"files":   FileTreeIdx,
+	"editor":  TextView1Idx,
+	"editor2": TextView2Idx,
+	"tabs":    MainTabsIdx,
+	"vistabs": VisTabsIdx,
+}
+
+// tourRun is a tour being taken in a gide window
+type tourRun struct {
+	tour *Tour
+	step int
+	dlg  *gi.Dialog
+}
+
+// TakeTour starts the tour, asking which one if there are several
+func (ge *Gide) TakeTour() {
+	switch len(AvailTours) {
+	case 0:
+		ge.SetStatus("No tours available")
+	case 1:
+		ge.StartTour(AvailTours[0].Name)
+	default:
+		nms := AvailTours.Names()
+		gi.StringsChooserPopup(nms, nms[0], ge, func(recv, send ki.Ki, sig int64, data interface{}) {
+			ac := send.(*gi.Action)
+			ge.StartTour(nms[ac.Data.(int)])
+		})
+	}
+}
+
+// StartTour starts the tour of given name in AvailTours
+func (ge *Gide) StartTour(name string) {
+	tr, ok := AvailTours.TourByName(name)
+	if !ok || len(tr.Steps) == 0 {
+		ge.SetStatus("No tour named " + name)
+		return
+	}
+	ge.TourEnd()
+	ge.tour = &tourRun{tour: tr}
+	ge.TourShow(0)
+}
+
+// tourTarget returns the widget of given target of a tour step, focusing
+// it -- the whole window if not found
+func (ge *Gide) tourTarget(tg string) gi.Node2D {
+	switch {
+	case tg == "toolbar":
+		if tb := ge.ToolBar(); tb != nil {
+			return tb
+		}
+	case tg == "statusbar":
+		if sb := ge.StatusBar(); sb != nil {
+			return sb
+		}
+	case strings.HasPrefix(tg, "tab:"):
+		if w, _, ok := ge.SelectMainTabByName(strings.TrimPrefix(tg, "tab:")); ok {
+			ge.FocusOnPanel(MainTabsIdx)
+			return w
+		}
+	default:
+		if idx, ok := TourTargets[tg]; ok && ge.FocusOnPanel(idx) {
+			if sv := ge.SplitView(); sv != nil && idx < len(sv.Kids) {
+				return sv.Kids[idx].(gi.Node2D)
+			}
+		}
+	}
+	return ge
+}
+
+// TourShow shows given step of the current tour, next to its target, with
+// buttons to go back and forth -- the dialog is not modal, so the step can
+// be tried out while it is shown
+func (ge *Gide) TourShow(step int) {
+	tr := ge.tour
+	if tr == nil || step < 0 || step >= len(tr.tour.Steps) {
+		ge.TourEnd()
+		return
+	}
+	if tr.dlg != nil {
+		tr.dlg.Cancel()
+		tr.dlg = nil
+	}
+	tr.step = step
+	st := tr.tour.Steps[step]
+	tw := ge.tourTarget(st.Target)
+	if st.Cmd != "" {
+		giv.CallMethod(ge, st.Cmd, ge.Viewport)
+	}
+
+	title := fmt.Sprintf("%v (%d of %d): %v", tr.tour.Name, step+1, len(tr.tour.Steps), st.Title)
+	dlg := gi.NewStdDialog(gi.DlgOpts{Title: title, Prompt: TourText(st.Text, TourKey)}, false, false)
+	dlg.Modal = false
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	bb := frame.InsertNewChild(gi.KiT_Layout, prIdx+1, "tour-buttons").(*gi.Layout)
+	bb.Lay = gi.LayoutHoriz
+	bb.SetStretchMaxWidth()
+	addBut := func(name, label, tip string, to int) {
+		b := bb.AddNewChild(gi.KiT_Button, name).(*gi.Button)
+		b.SetText(label)
+		b.Tooltip = tip
+		b.ButtonSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.ButtonClicked) {
+				return
+			}
+			gee, _ := recv.Embed(KiT_Gide).(*Gide)
+			gee.TourShow(to)
+		})
+	}
+	if step > 0 {
+		addBut("back", "Back", "previous step", step-1)
+	}
+	if step < len(tr.tour.Steps)-1 {
+		addBut("next", "Next", "next step", step+1)
+		addBut("end", "End Tour", "end the tour -- it can be taken again from the Help menu", -1)
+	} else {
+		addBut("end", "Done", "end the tour -- it can be taken again from the Help menu", -1)
+	}
+	dlg.UpdateEndNoSig(true)
+	tr.dlg = dlg
+	wb := tw.AsNode2D().WinBBox
+	dlg.Open(wb.Min.X+wb.Dx()/4, wb.Min.Y+wb.Dy()/4, ge.Viewport, nil)
+}
+
+// TourEnd ends the current tour, if any, and records that the tour has
+// been taken, so it is no longer offered
+func (ge *Gide) TourEnd() {
+	if ge.tour == nil {
+		return
+	}
+	if ge.tour.dlg != nil {
+		ge.tour.dlg.Cancel()
+	}
+	ge.tour = nil
+	if !Prefs.TourDone && Kiosk == nil {
+		Prefs.TourDone = true
+		Prefs.Save()
+	}
+}
+
+// OfferTour suggests the tour to new users, in the status bar, until it has
+// been taken once
+func (ge *Gide) OfferTour() {
+	if Prefs.TourDone || len(AvailTours) == 0 {
+		return
+	}
+	ge.SetStatus("New to gide?  Take the tour, in the Help menu")
+}
+
+// StdTours are the standard tours
+var StdTours = Tours{
+	{Name: "Gide Tour", Desc: "the main parts of gide, for new users", Steps: []TourStep{
+		{ID: "welcome", Title: "Welcome", Text: "Gide is organized around a <b>project</b>: a folder of files, with its settings saved in a .gide file.  This tour shows the main parts of the window -- you can try each one out while its step is shown."},
+		{ID: "tree", Title: "Files", Target: "files", Text: "The file tree shows the files of the project.  Click a file to open it in the active editor, and use the context menu for new files, renaming, version control, etc.  {NextPanel} and {PrevPanel} move between the panels."},
+		{ID: "buffers", Title: "Editors", Target: "editor", Text: "Open files are edited in buffers, shown in two editors side by side.  {BufSelect} switches the buffer of the active editor, {BufClone} shows it in the other editor, {BufSave} saves it and {BufClose} closes it.  {PaneSplitH} and {PaneSplitV} split the editors further."},
+		{ID: "commands", Title: "Commands", Target: "toolbar", Text: "The toolbar and menus have the commands, and {ExecCmd} runs a command on the active buffer, e.g., to build, test or use version control -- their output goes to tabs below the editors.  {BuildProj} builds the project and {RunProj} runs it."},
+		{ID: "search", Title: "Search", Target: "toolbar", Text: "Find, in the toolbar, searches the open buffer, or the whole project, and its results show in the Find tab, where they can be replaced too.  In the editor, {GotoDef} goes to the definition of the symbol at the cursor and {FindRefs} finds its references."},
+		{ID: "keymap", Title: "Key Maps", Target: "statusbar", Text: "The keys are set by key maps, chosen in the Preferences (in the app menu), which also has Edit Key Maps to customize them -- the keys in this tour are those of the active key map.  Messages, like this tour's offer, show in the status bar here."},
+	}},
+}
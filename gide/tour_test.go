@@ -0,0 +1,72 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tourIDs(tr *Tour) string {
+	ids := ""
+	for _, st := range tr.Steps {
+		ids += st.ID
+	}
+	return ids
+}
+
+func TestToursAdd(t *testing.T) {
+	var ts Tours
+	ts.Add(&Tour{Name: "Base", Steps: []TourStep{{ID: "a"}, {ID: "b"}, {ID: "c"}}})
+	ts.Add(&Tour{Name: "Plugin", Extends: "Base", After: "a", Steps: []TourStep{{ID: "x"}, {ID: "y"}}})
+	ts.Add(&Tour{Name: "Site", Extends: "Base", Steps: []TourStep{{ID: "z"}}})
+	ts.Add(&Tour{Name: "Other", Extends: "Base", After: "nope", Steps: []TourStep{{ID: "w"}}})
+	if len(ts) != 1 {
+		t.Fatalf("got %d tours, want 1", len(ts))
+	}
+	if got := tourIDs(ts[0]); got != "axybczw" {
+		t.Errorf("got steps %v", got)
+	}
+	if err := ts.Add(&Tour{Name: "Lost", Extends: "Missing"}); err == nil {
+		t.Errorf("no error for missing tour")
+	}
+	ts.Add(&Tour{Name: "Base", Steps: []TourStep{{ID: "n"}}})
+	if len(ts) != 1 || tourIDs(ts[0]) != "n" {
+		t.Errorf("not replaced: %v", tourIDs(ts[0]))
+	}
+}
+
+func TestToursOpenDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-tours")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "1-base.json"), []byte(`{"Name": "Base", "Steps": [{"ID": "a"}, {"ID": "b"}]}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "2-ext.json"), []byte(`{"Name": "Ext", "Extends": "Base", "After": "a", "Steps": [{"ID": "x", "Target": "files"}]}`), 0644)
+	var ts Tours
+	if err := ts.OpenDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || tourIDs(ts[0]) != "axb" || ts[0].Steps[1].Target != "files" {
+		t.Errorf("got %v tours, steps %v", len(ts), tourIDs(ts[0]))
+	}
+}
+
+func TestTourText(t *testing.T) {
+	keys := func(name string) string {
+		if name == "BufSelect" {
+			return "<b>Control+X b</b>"
+		}
+		return ""
+	}
+	got := TourText("{BufSelect} switches, {Unknown} stays, {not a key} too", keys)
+	want := "<b>Control+X b</b> switches, {Unknown} stays, {not a key} too"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+)
+
+// TrackerPrefs are the settings of the issue tracker of a project, for
+// filing issues for TODO comments -- all of them can be left empty for the
+// GitHub or GitLab repository of the git remote of the project
+type TrackerPrefs struct {
+	Kind   string   `desc:"github or gitlab -- empty for that of the git remote, by its host: github.com, or a host with github or gitlab in its name"`
+	Repo   string   `desc:"owner/name of the GitHub repository, or path of the GitLab project -- empty for that of the git remote"`
+	API    string   `desc:"url of the API, e.g., for GitHub Enterprise or a self-hosted GitLab -- empty for the default for the host"`
+	Labels []string `desc:"labels given to the issues filed for TODO comments"`
+}
+
+// TrackerTokenEnv is the environment variable with the access token for the
+// issue tracker -- if it is not set, GITHUB_TOKEN or GITLAB_TOKEN is used,
+// by the kind of tracker -- tokens are not kept in the project prefs, which
+// may be shared
+var TrackerTokenEnv = "GIDE_TRACKER_TOKEN"
+
+// TrackerTimeout is how long filing an issue can take
+var TrackerTimeout = 30 * time.Second
+
+// IssueTracker files issues in a GitHub repository or GitLab project
+type IssueTracker struct {
+	Kind  string `desc:"github or gitlab"`
+	API   string `desc:"url of the API, without a trailing /"`
+	Repo  string `desc:"owner/name of the repository, or path of the project"`
+	Token string `desc:"access token"`
+}
+
+// TrackerFromWeb returns the kind, API url and repository of the tracker of
+// the repository with given web url, as returned by RemoteWebURL -- false
+// if the host is not known to be GitHub or GitLab
+func TrackerFromWeb(web string) (kind, api, repo string, ok bool) {
+	u, err := url.Parse(web)
+	if err != nil || u.Host == "" {
+		return "", "", "", false
+	}
+	host := strings.ToLower(u.Host)
+	repo = strings.Trim(u.Path, "/")
+	switch {
+	case host == "github.com":
+		return "github", "https://api.github.com", repo, true
+	case strings.Contains(host, "github"):
+		return "github", "https://" + u.Host + "/api/v3", repo, true // enterprise
+	case strings.Contains(host, "gitlab"):
+		return "gitlab", "https://" + u.Host + "/api/v4", repo, true
+	}
+	return "", "", "", false
+}
+
+// File files an issue with given title, body and labels, returning its
+// number and web url
+func (it *IssueTracker) File(title, body string, labels []string) (int, string, error) {
+	var ur string
+	var req map[string]interface{}
+	switch it.Kind {
+	case "github":
+		ur = it.API + "/repos/" + it.Repo + "/issues"
+		req = map[string]interface{}{"title": title, "body": body}
+		if len(labels) > 0 {
+			req["labels"] = labels
+		}
+	case "gitlab":
+		ur = it.API + "/projects/" + url.PathEscape(it.Repo) + "/issues"
+		req = map[string]interface{}{"title": title, "description": body}
+		if len(labels) > 0 {
+			req["labels"] = strings.Join(labels, ",")
+		}
+	default:
+		return 0, "", fmt.Errorf("unknown kind of issue tracker: %q -- use github or gitlab", it.Kind)
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		return 0, "", err
+	}
+	hr, err := http.NewRequest("POST", ur, bytes.NewReader(b))
+	if err != nil {
+		return 0, "", err
+	}
+	hr.Header.Set("Content-Type", "application/json")
+	if it.Token != "" {
+		if it.Kind == "gitlab" {
+			hr.Header.Set("PRIVATE-TOKEN", it.Token)
+		} else {
+			hr.Header.Set("Authorization", "token "+it.Token)
+		}
+	}
+	cl := &http.Client{Timeout: TrackerTimeout}
+	resp, err := cl.Do(hr)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	rb, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, "", err
+	}
+	if resp.StatusCode/100 != 2 {
+		msg := strings.TrimSpace(string(rb))
+		var er struct {
+			Message interface{} `json:"message"`
+		}
+		if json.Unmarshal(rb, &er) == nil && er.Message != nil {
+			msg = fmt.Sprint(er.Message)
+		}
+		return 0, "", fmt.Errorf("filing issue: %v: %v", resp.Status, msg)
+	}
+	var is struct {
+		Number  int    `json:"number"`
+		IID     int    `json:"iid"`
+		HTMLURL string `json:"html_url"`
+		WebURL  string `json:"web_url"`
+	}
+	if err := json.Unmarshal(rb, &is); err != nil {
+		return 0, "", fmt.Errorf("filing issue: bad response: %v", err)
+	}
+	if it.Kind == "gitlab" {
+		return is.IID, is.WebURL, nil
+	}
+	return is.Number, is.HTMLURL, nil
+}
+
+// TodoCommentLine returns a comment line with given tag, e.g., TODO, and
+// text, at given indentation, with given line comment marker, or block
+// comment markers if there is none -- ref, e.g., #12, is put after the tag
+// in parentheses, if not empty
+func TodoCommentLine(indent, line, blockSt, blockEd, tag, ref, text string) string {
+	t := tag
+	if ref != "" {
+		t += "(" + ref + ")"
+	}
+	t += ": " + text
+	if strings.TrimSpace(line) == "" && blockSt != "" {
+		return indent + strings.TrimRight(blockSt, " ") + " " + t + " " + strings.TrimLeft(blockEd, " ")
+	}
+	if line == "" {
+		line = "// "
+	}
+	if !strings.HasSuffix(line, " ") {
+		line += " "
+	}
+	return indent + line + t
+}
+
+// TodoLinkLine returns given line with ref put after the first occurrence
+// of given tag followed by a colon, e.g., TODO: becomes TODO(#12): -- false
+// if it has none
+func TodoLinkLine(line, tag, ref string) (string, bool) {
+	i := strings.Index(line, tag+":")
+	if i < 0 {
+		return line, false
+	}
+	i += len(tag)
+	return line[:i] + "(" + ref + ")" + line[i:], true
+}
+
+// IssueTracker returns the issue tracker of the project, from the Tracker
+// project prefs, filled in from the git remote, with the token from the
+// environment (see TrackerTokenEnv)
+func (ge *Gide) IssueTracker() (*IssueTracker, error) {
+	if err := OfflineCheck("filing issues"); err != nil {
+		return nil, err
+	}
+	tp := ge.Prefs.Tracker
+	it := &IssueTracker{Kind: strings.ToLower(tp.Kind), API: strings.TrimSuffix(tp.API, "/"), Repo: strings.Trim(tp.Repo, "/")}
+	if it.Kind == "" || it.API == "" || it.Repo == "" {
+		remote, err := gitOut(string(ge.ProjRoot), "remote", "get-url", "origin")
+		if err != nil {
+			return nil, fmt.Errorf("no issue tracker set in the project prefs, and no git remote: %v", err)
+		}
+		web, ok := RemoteWebURL(remote)
+		if !ok {
+			return nil, fmt.Errorf("no issue tracker set in the project prefs, and the git remote is not a web repository: %v", remote)
+		}
+		kind, api, repo, ok := TrackerFromWeb(web)
+		if !ok {
+			return nil, fmt.Errorf("no issue tracker set in the project prefs, and the git remote is not on GitHub or GitLab: %v", web)
+		}
+		if it.Kind == "" {
+			it.Kind = kind
+		}
+		if it.API == "" {
+			it.API = api
+		}
+		if it.Repo == "" {
+			it.Repo = repo
+		}
+	}
+	it.Token = os.Getenv(TrackerTokenEnv)
+	if it.Token == "" {
+		it.Token = os.Getenv(strings.ToUpper(it.Kind) + "_TOKEN")
+	}
+	if it.Token == "" {
+		return nil, fmt.Errorf("no access token for the issue tracker: set %v or %v_TOKEN", TrackerTokenEnv, strings.ToUpper(it.Kind))
+	}
+	return it, nil
+}
+
+// InsertTodo inserts a TODO comment with given text above the cursor line
+// in the active view, at its indentation
+func (ge *Gide) InsertTodo(text string) {
+	ge.insertTodo(text, false)
+}
+
+// InsertTodoIssue inserts a TODO comment with given text above the cursor
+// line in the active view, and files an issue for it in the issue tracker
+// of the project, in the background, putting the number of the issue in
+// the comment once it is filed, e.g., TODO(#12): -- see Tracker in the
+// project prefs
+func (ge *Gide) InsertTodoIssue(text string) {
+	ge.insertTodo(text, true)
+}
+
+// insertTodo inserts a TODO comment, and files an issue for it if file
+func (ge *Gide) insertTodo(text string, file bool) {
+	text = strings.TrimSpace(text)
+	tv := ge.ActiveTextView()
+	if text == "" || tv == nil || tv.Buf == nil || tv.IsInactive() {
+		return
+	}
+	var it *IssueTracker
+	if file {
+		var err error
+		if it, err = ge.IssueTracker(); err != nil {
+			ge.SetStatus("Could not file an issue: " + err.Error())
+			return
+		}
+	}
+	tb := tv.Buf
+	ln := tv.CursorPos.Ln
+	if ln >= len(tb.Lines) {
+		return
+	}
+	cur := string(tb.Lines[ln])
+	indent := cur[:len(cur)-len(strings.TrimLeftFunc(cur, unicode.IsSpace))]
+	line, bst, bed := CommentMarkers(string(tb.Filename))
+	cmt := TodoCommentLine(indent, line, bst, bed, "TODO", "", text)
+	tb.InsertText(giv.TextPos{Ln: ln}, []byte(cmt+"\n"), true, true)
+	tv.SetCursorShow(giv.TextPos{Ln: ln, Ch: len([]rune(cmt))})
+	if !file {
+		return
+	}
+	fpath := string(tb.Filename)
+	body := fmt.Sprintf("Filed from a TODO comment at %v", CodeRef(string(ge.ProjRoot), fpath, ln, ln))
+	labels := ge.Prefs.Tracker.Labels
+	ge.SetStatus("Filing an issue for the TODO...")
+	go func() {
+		num, ur, err := it.File(text, body, labels)
+		if err != nil {
+			ge.SetStatus("Could not file an issue: " + err.Error())
+			return
+		}
+		ref := fmt.Sprintf("#%d", num)
+		if !ge.todoLink(tb, ln, cmt, ref) {
+			ge.SetStatus(fmt.Sprintf("Filed issue %v, but could not find the TODO comment to put it in: %v", ref, ur))
+			return
+		}
+		ge.SetStatus(fmt.Sprintf("Filed issue %v: %v", ref, ur))
+	}()
+}
+
+// todoLink puts given issue ref in given TODO comment line, inserted at
+// given line of given buffer, looking for it nearest to there, as the lines
+// may have moved with edits since
+func (ge *Gide) todoLink(tb *giv.TextBuf, ln int, cmt, ref string) bool {
+	nl := len(tb.Lines)
+	for d := 0; d < nl; d++ {
+		for _, l := range []int{ln - d, ln + d} {
+			if l < 0 || l >= nl || string(tb.Lines[l]) != cmt {
+				continue
+			}
+			nw, ok := TodoLinkLine(cmt, "TODO", ref)
+			if !ok {
+				return false
+			}
+			tb.DeleteText(giv.TextPos{Ln: l}, giv.TextPos{Ln: l, Ch: len(tb.Lines[l])}, true, true)
+			tb.InsertText(giv.TextPos{Ln: l}, []byte(nw), true, true)
+			return true
+		}
+	}
+	return false
+}
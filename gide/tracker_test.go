@@ -0,0 +1,103 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrackerFromWeb(t *testing.T) {
+	cases := []struct {
+		web, kind, api, repo string
+		ok                   bool
+	}{
+		{"https://github.com/goki/gide", "github", "https://api.github.com", "goki/gide", true},
+		{"https://github.acme.com/team/app", "github", "https://github.acme.com/api/v3", "team/app", true},
+		{"https://gitlab.com/grp/sub/proj", "gitlab", "https://gitlab.com/api/v4", "grp/sub/proj", true},
+		{"https://bitbucket.org/me/repo", "", "", "", false},
+	}
+	for _, c := range cases {
+		kind, api, repo, ok := TrackerFromWeb(c.web)
+		if kind != c.kind || api != c.api || repo != c.repo || ok != c.ok {
+			t.Errorf("%v: got %q %q %q %v", c.web, kind, api, repo, ok)
+		}
+	}
+}
+
+func TestIssueTrackerFile(t *testing.T) {
+	var path, auth string
+	var req map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		auth = r.Header.Get("Authorization") + r.Header.Get("PRIVATE-TOKEN")
+		req = nil
+		json.NewDecoder(r.Body).Decode(&req)
+		w.WriteHeader(http.StatusCreated)
+		if r.Header.Get("PRIVATE-TOKEN") != "" {
+			w.Write([]byte(`{"iid": 7, "web_url": "https://gitlab.com/grp/proj/-/issues/7"}`))
+		} else {
+			w.Write([]byte(`{"number": 12, "html_url": "https://github.com/goki/gide/issues/12"}`))
+		}
+	}))
+	defer srv.Close()
+
+	it := &IssueTracker{Kind: "github", API: srv.URL, Repo: "goki/gide", Token: "tok"}
+	num, ur, err := it.File("fix it", "body", []string{"todo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if num != 12 || ur != "https://github.com/goki/gide/issues/12" {
+		t.Errorf("github: got %v %v", num, ur)
+	}
+	if path != "/repos/goki/gide/issues" || auth != "token tok" || req["title"] != "fix it" || req["body"] != "body" {
+		t.Errorf("github request: %v %v %v", path, auth, req)
+	}
+
+	it = &IssueTracker{Kind: "gitlab", API: srv.URL, Repo: "grp/proj", Token: "tok"}
+	num, ur, err = it.File("fix it", "body", []string{"todo", "debt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if num != 7 || ur != "https://gitlab.com/grp/proj/-/issues/7" {
+		t.Errorf("gitlab: got %v %v", num, ur)
+	}
+	if path != "/projects/grp%2Fproj/issues" || auth != "tok" || req["description"] != "body" || req["labels"] != "todo,debt" {
+		t.Errorf("gitlab request: %v %v %v", path, auth, req)
+	}
+
+	fail := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "Bad credentials"}`))
+	}))
+	defer fail.Close()
+	it = &IssueTracker{Kind: "github", API: fail.URL, Repo: "goki/gide"}
+	if _, _, err := it.File("x", "", nil); err == nil {
+		t.Errorf("no error for a failed request")
+	}
+}
+
+func TestTodoCommentLine(t *testing.T) {
+	cases := []struct {
+		indent, line, bst, bed, ref, want string
+	}{
+		{"\t", "// ", "/* ", " */", "", "\t// TODO: fix it"},
+		{"    ", "#", "", "", "#3", "    # TODO(#3): fix it"},
+		{"", "", "<!-- ", " -->", "", "<!-- TODO: fix it -->"},
+	}
+	for _, c := range cases {
+		if got := TodoCommentLine(c.indent, c.line, c.bst, c.bed, "TODO", c.ref, "fix it"); got != c.want {
+			t.Errorf("%q: got %q, want %q", c.line, got, c.want)
+		}
+	}
+	if got, ok := TodoLinkLine("\t// TODO: fix it", "TODO", "#12"); !ok || got != "\t// TODO(#12): fix it" {
+		t.Errorf("TodoLinkLine: got %q %v", got, ok)
+	}
+	if _, ok := TodoLinkLine("// nothing", "TODO", "#12"); ok {
+		t.Errorf("TodoLinkLine: linked a line with no TODO")
+	}
+}
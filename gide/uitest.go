@@ -0,0 +1,556 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki"
+)
+
+// UI test scripts drive a gide window the way a user would, for end-to-end
+// tests of gide and of plugins: each line of a script is a step, an op and
+// its arg, e.g.:
+//
+//   open main.go
+//   keys Control+C @
+//   type "hello\n"
+//   expect-line 3 hello
+//   snapshot after-hello
+//
+// Lines starting with # are comments -- an arg in double quotes is
+// unquoted as a Go string, for leading spaces and escapes such as \n --
+// scripts are run with gide -uitest script, which exits with status 1 if a
+// step fails -- gide needs a display, so on a CI machine run it under a
+// virtual one, e.g., xvfb-run gide -uitest script
+
+// UIStep is one step of a UI test script
+type UIStep struct {
+	Ln  int    `desc:"line of the step in the script, starting at 1"`
+	Op  string `desc:"op of the step -- one of UIOps"`
+	Arg string `desc:"arg of the op, unquoted"`
+}
+
+// UIOp is an op for the steps of UI test scripts
+type UIOp struct {
+	Desc  string                              `desc:"what the op does, with its arg"`
+	Check func(arg string) error              `desc:"checks the arg when the script is parsed -- nil if any arg is ok"`
+	Run   func(d *UIDriver, arg string) error `desc:"runs the step -- an error fails the script"`
+}
+
+// UIOps are the ops for UI test script steps, by name -- plugins can add
+// their own with RegisterUIOp
+var UIOps = map[string]*UIOp{
+	"open": {Desc: "views given file, relative to the project root", Check: uiCheckNonEmpty,
+		Run: func(d *UIDriver, arg string) error { return d.Open(arg) }},
+	"keys": {Desc: "sends the key chords given, separated by spaces, e.g., Control+X Control+S", Check: func(arg string) error { _, err := ParseUIChords(arg); return err },
+		Run: func(d *UIDriver, arg string) error { return d.Keys(arg) }},
+	"type": {Desc: "types given text, as key presses, into the focused widget", Check: uiCheckNonEmpty,
+		Run: func(d *UIDriver, arg string) error { d.Type(arg); return nil }},
+	"call": {Desc: "calls given method of the gide window, as from its menus, e.g., Outline", Check: uiCheckNonEmpty,
+		Run: func(d *UIDriver, arg string) error { return d.Call(arg) }},
+	"wait": {Desc: "waits for given duration, e.g., 500ms", Check: func(arg string) error { _, err := time.ParseDuration(arg); return err },
+		Run: func(d *UIDriver, arg string) error { dur, _ := time.ParseDuration(arg); time.Sleep(dur); return nil }},
+	"expect-text": {Desc: "checks that the active view contains given text", Check: uiCheckNonEmpty,
+		Run: func(d *UIDriver, arg string) error { return d.ExpectText(arg) }},
+	"expect-line": {Desc: "checks that given line of the active view, starting at 1, is the text after it", Check: func(arg string) error { _, _, err := uiLineArg(arg); return err },
+		Run: func(d *UIDriver, arg string) error { ln, txt, _ := uiLineArg(arg); return d.ExpectLine(ln, txt) }},
+	"expect-cursor": {Desc: "checks that the cursor of the active view is at given line:char, as in the status bar", Check: func(arg string) error { _, err := ParseUICursor(arg); return err },
+		Run: func(d *UIDriver, arg string) error { pos, _ := ParseUICursor(arg); return d.ExpectCursor(pos) }},
+	"expect-status": {Desc: "checks that the status bar contains given text", Check: uiCheckNonEmpty,
+		Run: func(d *UIDriver, arg string) error { return d.ExpectStatus(arg) }},
+	"expect-widget": {Desc: "checks that there is a widget of given name in the window, e.g., a tab such as Outline", Check: uiCheckNonEmpty,
+		Run: func(d *UIDriver, arg string) error { return d.ExpectWidget(arg) }},
+	"snapshot": {Desc: "compares the state of the window (see UISnapshot) to the one saved under given name next to the script, saving it if there is none", Check: uiCheckNonEmpty,
+		Run: func(d *UIDriver, arg string) error { return d.CheckSnapshot(arg) }},
+	"dump-tree": {Desc: "writes the widget tree of the window, to given depth, to the log, to find the names of widgets",
+		Run: func(d *UIDriver, arg string) error { n, _ := strconv.Atoi(arg); d.Logf("%v", d.Tree(n)); return nil }},
+}
+
+// RegisterUIOp adds given op for UI test scripts -- for plugins, to test
+// their own features
+func RegisterUIOp(name string, op *UIOp) {
+	UIOps[name] = op
+}
+
+func uiCheckNonEmpty(arg string) error {
+	if arg == "" {
+		return fmt.Errorf("needs an arg")
+	}
+	return nil
+}
+
+// uiLineArg parses the arg of expect-line: a line number and the text of
+// the line, which can be empty
+func uiLineArg(arg string) (int, string, error) {
+	fs := strings.SplitN(arg, " ", 2)
+	ln, err := strconv.Atoi(fs[0])
+	if err != nil || ln < 1 {
+		return 0, "", fmt.Errorf("needs a line number starting at 1, then the text of the line")
+	}
+	txt := ""
+	if len(fs) == 2 {
+		if txt, err = uiUnquote(fs[1]); err != nil {
+			return 0, "", err
+		}
+	}
+	return ln, txt, nil
+}
+
+// uiUnquote unquotes given arg as a Go string if it is in double quotes
+func uiUnquote(arg string) (string, error) {
+	if !strings.HasPrefix(arg, `"`) {
+		return arg, nil
+	}
+	s, err := strconv.Unquote(arg)
+	if err != nil {
+		return "", fmt.Errorf("bad quoted string: %v", arg)
+	}
+	return s, nil
+}
+
+// ParseUIScript parses given UI test script, checking that its ops exist
+// and their args are valid -- the errors of all of its steps are returned
+func ParseUIScript(src string) ([]UIStep, error) {
+	var steps []UIStep
+	var errs []string
+	for i, ln := range strings.Split(src, "\n") {
+		ln = strings.TrimSpace(ln)
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		st := UIStep{Ln: i + 1}
+		fs := strings.SplitN(ln, " ", 2)
+		st.Op = fs[0]
+		if len(fs) == 2 {
+			st.Arg = strings.TrimSpace(fs[1])
+		}
+		op, ok := UIOps[st.Op]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%d: unknown op: %v", st.Ln, st.Op))
+			continue
+		}
+		if st.Op != "expect-line" { // its text is unquoted by itself
+			arg, err := uiUnquote(st.Arg)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%d: %v: %v", st.Ln, st.Op, err))
+				continue
+			}
+			st.Arg = arg
+		}
+		if op.Check != nil {
+			if err := op.Check(st.Arg); err != nil {
+				errs = append(errs, fmt.Sprintf("%d: %v: %v", st.Ln, st.Op, err))
+				continue
+			}
+		}
+		steps = append(steps, st)
+	}
+	if len(errs) > 0 {
+		return steps, fmt.Errorf("%v", strings.Join(errs, "\n"))
+	}
+	return steps, nil
+}
+
+// UIChord is a key chord to send, e.g., Control+Shift+A or UpArrow
+type UIChord struct {
+	Mods []string `desc:"modifiers: Shift, Control, Alt or Meta"`
+	Rune rune     `desc:"rune of the key, or 0 for a named key"`
+	Code string   `desc:"name of a key without a rune, e.g., ReturnEnter or UpArrow -- see UIKeyCodes"`
+}
+
+// UIKeyCodes are the named keys that can be in UI test chords
+var UIKeyCodes = map[string]key.Codes{
+	"ReturnEnter": key.CodeReturnEnter,
+	"Escape":      key.CodeEscape,
+	"Tab":         key.CodeTab,
+	"Backspace":   key.CodeDeleteBackspace,
+	"Delete":      key.CodeDeleteForward,
+	"UpArrow":     key.CodeUpArrow,
+	"DownArrow":   key.CodeDownArrow,
+	"LeftArrow":   key.CodeLeftArrow,
+	"RightArrow":  key.CodeRightArrow,
+	"Home":        key.CodeHome,
+	"End":         key.CodeEnd,
+	"PageUp":      key.CodePageUp,
+	"PageDown":    key.CodePageDown,
+	"F1":          key.CodeF1,
+	"F2":          key.CodeF2,
+	"F3":          key.CodeF3,
+	"F4":          key.CodeF4,
+	"F5":          key.CodeF5,
+	"F6":          key.CodeF6,
+	"F7":          key.CodeF7,
+	"F8":          key.CodeF8,
+	"F9":          key.CodeF9,
+	"F10":         key.CodeF10,
+	"F11":         key.CodeF11,
+	"F12":         key.CodeF12,
+}
+
+// UIKeyMods are the modifiers that can be in UI test chords
+var UIKeyMods = map[string]key.Modifiers{
+	"Shift":   key.Shift,
+	"Control": key.Control,
+	"Alt":     key.Alt,
+	"Meta":    key.Meta,
+}
+
+// ParseUIChords parses given key chords, separated by spaces, as they are
+// written in key maps, e.g., Control+X Control+S -- a key of + is written
+// as Shift++
+func ParseUIChords(s string) ([]UIChord, error) {
+	var chs []UIChord
+	for _, cs := range strings.Fields(s) {
+		ch := UIChord{}
+		k := cs
+		for {
+			i := strings.Index(k, "+")
+			if i <= 0 || i == len(k)-1 {
+				break
+			}
+			mod := k[:i]
+			if _, ok := UIKeyMods[mod]; !ok {
+				return nil, fmt.Errorf("unknown modifier %v in %v", mod, cs)
+			}
+			ch.Mods = append(ch.Mods, mod)
+			k = k[i+1:]
+		}
+		switch {
+		case utf8.RuneCountInString(k) == 1:
+			ch.Rune, _ = utf8.DecodeRuneInString(k)
+		case UIKeyCodes[k] != 0:
+			ch.Code = k
+		default:
+			return nil, fmt.Errorf("unknown key %v in %v", k, cs)
+		}
+		chs = append(chs, ch)
+	}
+	if len(chs) == 0 {
+		return nil, fmt.Errorf("needs key chords")
+	}
+	return chs, nil
+}
+
+// ParseUICursor parses a cursor position as line:char, with the line
+// starting at 1 and the char at 0, as shown in the status bar
+func ParseUICursor(s string) (giv.TextPos, error) {
+	var ln, ch int
+	if n, err := fmt.Sscanf(s, "%d:%d", &ln, &ch); n != 2 || err != nil || ln < 1 || ch < 0 {
+		return giv.TextPos{}, fmt.Errorf("needs a position as line:char, e.g., 12:4")
+	}
+	return giv.TextPos{Ln: ln - 1, Ch: ch}, nil
+}
+
+// UISnapshot is the state of a gide window that UI tests compare to a saved
+// one
+type UISnapshot struct {
+	File   string   `desc:"file in the active view, relative to the project root"`
+	Cursor string   `desc:"cursor position in the active view, as line:char"`
+	Text   string   `desc:"text of the active view"`
+	Status string   `desc:"text of the status bar"`
+	Tabs   []string `desc:"names of the tabs open in the main tabs panel, sorted"`
+}
+
+// UIDriver drives a gide window the way a user would, and queries its
+// state, for UI tests -- it can be used from Go tests and plugins, as well
+// as by UI test scripts
+type UIDriver struct {
+	Gide      *Gide         `desc:"gide window being driven"`
+	Dir       string        `desc:"directory of the snapshots -- that of the script"`
+	StepDelay time.Duration `desc:"time to wait after each key, and each step, for the window to update"`
+	Update    bool          `desc:"save snapshots over the ones that differ, instead of failing -- set with the GIDE_UITEST_UPDATE environment variable"`
+	Log       []string      `desc:"what the steps logged, e.g., the widget tree"`
+}
+
+// NewUIDriver returns a new driver for given gide window
+func NewUIDriver(ge *Gide) *UIDriver {
+	return &UIDriver{Gide: ge, StepDelay: 20 * time.Millisecond, Update: os.Getenv("GIDE_UITEST_UPDATE") != ""}
+}
+
+// Logf adds to the log of the driver
+func (d *UIDriver) Logf(format string, args ...interface{}) {
+	d.Log = append(d.Log, fmt.Sprintf(format, args...))
+}
+
+// Open views given file, relative to the project root
+func (d *UIDriver) Open(fname string) error {
+	ge := d.Gide
+	if !filepath.IsAbs(fname) {
+		fname = filepath.Join(string(ge.ProjRoot), fname)
+	}
+	if _, _, ok := ge.ViewFile(gi.FileName(fname)); !ok {
+		return fmt.Errorf("could not open %v", fname)
+	}
+	return nil
+}
+
+// SendChord sends given key chord to the window, as if it was pressed
+func (d *UIDriver) SendChord(ch UIChord) error {
+	win := d.Gide.ParentWindow()
+	if win == nil {
+		return fmt.Errorf("gide window is not open")
+	}
+	ke := key.ChordEvent{}
+	ke.SetTime()
+	ke.Action = key.Press
+	ke.Rune = ch.Rune
+	ke.Code = UIKeyCodes[ch.Code]
+	for _, m := range ch.Mods {
+		ke.Modifiers |= 1 << uint32(UIKeyMods[m])
+	}
+	win.SendEventSignal(&ke, false)
+	time.Sleep(d.StepDelay)
+	return nil
+}
+
+// Keys sends given key chords, separated by spaces, e.g., Control+X Control+S
+func (d *UIDriver) Keys(s string) error {
+	chs, err := ParseUIChords(s)
+	if err != nil {
+		return err
+	}
+	for _, ch := range chs {
+		if err := d.SendChord(ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Type types given text, as key presses -- new lines and tabs are sent as
+// the Return and Tab keys
+func (d *UIDriver) Type(txt string) {
+	for _, r := range txt {
+		ch := UIChord{Rune: r}
+		switch r {
+		case '\n':
+			ch = UIChord{Code: "ReturnEnter"}
+		case '\t':
+			ch = UIChord{Code: "Tab"}
+		}
+		d.SendChord(ch)
+	}
+}
+
+// Call calls given method of the gide window, as from its menus -- methods
+// with args prompt for them
+func (d *UIDriver) Call(method string) error {
+	ge := d.Gide
+	if !reflect.ValueOf(ge).MethodByName(method).IsValid() {
+		return fmt.Errorf("no method %v in the menus of gide", method)
+	}
+	giv.CallMethod(ge, method, ge.Viewport)
+	return nil
+}
+
+// ActiveText returns the text of the active view, and an error if there is
+// no file in it
+func (d *UIDriver) ActiveText() (string, error) {
+	tv := d.Gide.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return "", fmt.Errorf("no file in the active view")
+	}
+	return string(tv.Buf.LinesToBytesCopy()), nil
+}
+
+// ExpectText checks that the active view contains given text
+func (d *UIDriver) ExpectText(txt string) error {
+	all, err := d.ActiveText()
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(all, txt) {
+		return fmt.Errorf("active view does not contain %q", txt)
+	}
+	return nil
+}
+
+// ExpectLine checks that given line of the active view, starting at 1, is
+// given text
+func (d *UIDriver) ExpectLine(ln int, txt string) error {
+	all, err := d.ActiveText()
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(all, "\n")
+	if ln > len(lines) {
+		return fmt.Errorf("active view has only %d lines, not %d", len(lines), ln)
+	}
+	if lines[ln-1] != txt {
+		return fmt.Errorf("line %d is %q, not %q", ln, lines[ln-1], txt)
+	}
+	return nil
+}
+
+// ExpectCursor checks that the cursor of the active view is at given position
+func (d *UIDriver) ExpectCursor(pos giv.TextPos) error {
+	tv := d.Gide.ActiveTextView()
+	if tv == nil {
+		return fmt.Errorf("no active view")
+	}
+	if tv.CursorPos != pos {
+		return fmt.Errorf("cursor is at %d:%d, not %d:%d", tv.CursorPos.Ln+1, tv.CursorPos.Ch, pos.Ln+1, pos.Ch)
+	}
+	return nil
+}
+
+// Status returns the text of the status bar
+func (d *UIDriver) Status() string {
+	if lbl := d.Gide.StatusLabel(); lbl != nil {
+		return lbl.Text
+	}
+	return ""
+}
+
+// ExpectStatus checks that the status bar contains given text
+func (d *UIDriver) ExpectStatus(txt string) error {
+	if st := d.Status(); !strings.Contains(st, txt) {
+		return fmt.Errorf("status is %q, without %q", st, txt)
+	}
+	return nil
+}
+
+// Widget returns the first widget of given name in the window
+func (d *UIDriver) Widget(name string) (ki.Ki, bool) {
+	var fk ki.Ki
+	d.Gide.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, data interface{}) bool {
+		if fk != nil {
+			return false
+		}
+		if k.Name() == name {
+			fk = k
+			return false
+		}
+		return true
+	})
+	return fk, fk != nil
+}
+
+// ExpectWidget checks that there is a widget of given name in the window
+func (d *UIDriver) ExpectWidget(name string) error {
+	if _, ok := d.Widget(name); !ok {
+		return fmt.Errorf("no widget named %v", name)
+	}
+	return nil
+}
+
+// Tree returns the widget tree of the window, to given depth (0 for all),
+// with the name and type of each widget, indented by depth
+func (d *UIDriver) Tree(depth int) string {
+	var sb strings.Builder
+	d.Gide.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, data interface{}) bool {
+		if depth > 0 && level >= depth {
+			return false
+		}
+		sb.WriteString(fmt.Sprintf("%v%v (%v)\n", strings.Repeat("  ", level), k.Name(), k.Type().Name()))
+		return true
+	})
+	return sb.String()
+}
+
+// Snapshot returns the current state of the window
+func (d *UIDriver) Snapshot() *UISnapshot {
+	ge := d.Gide
+	sn := &UISnapshot{Status: d.Status()}
+	if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil {
+		sn.File = ge.Files.RelPath(tv.Buf.Filename)
+		sn.Cursor = fmt.Sprintf("%d:%d", tv.CursorPos.Ln+1, tv.CursorPos.Ch)
+		sn.Text = string(tv.Buf.LinesToBytesCopy())
+	}
+	if mt := ge.MainTabs(); mt != nil {
+		for _, k := range mt.Frame().Kids {
+			sn.Tabs = append(sn.Tabs, k.Name())
+		}
+		sort.Strings(sn.Tabs)
+	}
+	return sn
+}
+
+// CheckSnapshot compares the state of the window to the snapshot of given
+// name in the snapshot directory, saving it if there is none, or if the
+// driver is set to update them
+func (d *UIDriver) CheckSnapshot(name string) error {
+	got, err := json.MarshalIndent(d.Snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	fname := filepath.Join(d.Dir, name+".snap.json")
+	want, err := ioutil.ReadFile(fname)
+	if err != nil || d.Update {
+		if err := ioutil.WriteFile(fname, got, 0644); err != nil {
+			return err
+		}
+		d.Logf("saved snapshot %v", fname)
+		return nil
+	}
+	if string(got) == string(want) {
+		return nil
+	}
+	gl := strings.Split(string(got), "\n")
+	wl := strings.Split(string(want), "\n")
+	for i := 0; i < len(gl) || i < len(wl); i++ {
+		var g, w string
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if g != w {
+			return fmt.Errorf("snapshot differs from %v at line %d:\n got: %v\nwant: %v", fname, i+1, g, w)
+		}
+	}
+	return nil
+}
+
+// Run runs given steps, stopping at the first one that fails, whose error
+// is returned with its line
+func (d *UIDriver) Run(steps []UIStep) error {
+	for _, st := range steps {
+		if err := UIOps[st.Op].Run(d, st.Arg); err != nil {
+			return fmt.Errorf("%d: %v: %v", st.Ln, st.Op, err)
+		}
+		time.Sleep(d.StepDelay)
+	}
+	return nil
+}
+
+// RunUIScript runs the UI test script in given file on given gide window,
+// logging its steps as they run -- snapshots are kept next to the script
+func RunUIScript(ge *Gide, fname string) error {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return err
+	}
+	steps, err := ParseUIScript(string(b))
+	if err != nil {
+		return fmt.Errorf("%v:%v", fname, err)
+	}
+	d := NewUIDriver(ge)
+	d.Dir = filepath.Dir(fname)
+	err = d.Run(steps)
+	for _, l := range d.Log {
+		fmt.Println(l)
+	}
+	if err != nil {
+		return fmt.Errorf("%v:%v", fname, err)
+	}
+	fmt.Printf("%v: %d steps passed\n", fname, len(steps))
+	return nil
+}
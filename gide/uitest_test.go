@@ -0,0 +1,83 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/giv"
+)
+
+func TestParseUIScript(t *testing.T) {
+	src := `# edit and check
+open main.go
+keys Control+C @
+type "  hi\n"
+expect-line 2 "  hi"
+expect-line 3
+expect-cursor 3:0
+wait 10ms
+`
+	steps, err := ParseUIScript(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 7 {
+		t.Fatalf("got %d steps: %+v", len(steps), steps)
+	}
+	if st := steps[2]; st.Ln != 4 || st.Op != "type" || st.Arg != "  hi\n" {
+		t.Errorf("type step: %+v", st)
+	}
+	if ln, txt, err := uiLineArg(steps[3].Arg); err != nil || ln != 2 || txt != "  hi" {
+		t.Errorf("expect-line: %v %q %v", ln, txt, err)
+	}
+	if ln, txt, err := uiLineArg(steps[4].Arg); err != nil || ln != 3 || txt != "" {
+		t.Errorf("expect-line empty: %v %q %v", ln, txt, err)
+	}
+
+	_, err = ParseUIScript("bogus x\nkeys Control+Nope\nwait soon\nexpect-cursor 0:1\nopen\ntype \"bad\n")
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	if n := len(strings.Split(err.Error(), "\n")); n != 6 {
+		t.Errorf("expected 6 errors, got %d:\n%v", n, err)
+	}
+}
+
+func TestParseUIChords(t *testing.T) {
+	chs, err := ParseUIChords("Control+X Control+Shift+s UpArrow Shift++ +")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []UIChord{
+		{Mods: []string{"Control"}, Rune: 'X'},
+		{Mods: []string{"Control", "Shift"}, Rune: 's'},
+		{Code: "UpArrow"},
+		{Mods: []string{"Shift"}, Rune: '+'},
+		{Rune: '+'},
+	}
+	if !reflect.DeepEqual(chs, want) {
+		t.Errorf("got %+v\nwant %+v", chs, want)
+	}
+	for _, bad := range []string{"", "Hyper+A", "Control+", "Bogus"} {
+		if _, err := ParseUIChords(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestParseUICursor(t *testing.T) {
+	pos, err := ParseUICursor("12:4")
+	if err != nil || pos != (giv.TextPos{Ln: 11, Ch: 4}) {
+		t.Errorf("got %v %v", pos, err)
+	}
+	for _, bad := range []string{"0:1", "3", "a:b", "2:-1"} {
+		if _, err := ParseUICursor(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
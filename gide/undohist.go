@@ -0,0 +1,218 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+)
+
+// UndoHistDirName is the name of the directory in the GoGi prefs directory
+// where the undo histories of files are stored, by the hash of the contents
+// of the file that they end at
+var UndoHistDirName = "gide_undo"
+
+// UndoHistMaxFiles is the maximum number of undo histories that are stored
+// -- the least recently saved ones are removed beyond that
+var UndoHistMaxFiles = 500
+
+// UndoEdit is one edit in the undo history of a file
+type UndoEdit struct {
+	Delete bool        `desc:"true if text was deleted, otherwise inserted"`
+	St     giv.TextPos `desc:"start of the text"`
+	Ed     giv.TextPos `desc:"end of the text, before it was deleted or after it was inserted"`
+	Text   string      `desc:"the text deleted or inserted"`
+}
+
+// UndoEditFrom returns the edit for given edit of a buffer
+func UndoEditFrom(tbe *giv.TextBufEdit) UndoEdit {
+	return UndoEdit{Delete: tbe.Delete, St: tbe.Reg.Start, Ed: tbe.Reg.End, Text: string(tbe.ToBytes())}
+}
+
+// UndoEdits is a sequence of edits, oldest first
+type UndoEdits []UndoEdit
+
+// Add adds given edit, merging it into the last one when it continues it
+// within the same line, e.g., typing or backspacing a word -- keeps at most
+// max edits, dropping the oldest ones
+func (ue *UndoEdits) Add(ed UndoEdit, max int) {
+	if n := len(*ue); n > 0 && !strings.Contains(ed.Text, "\n") {
+		lst := &(*ue)[n-1]
+		switch {
+		case lst.Delete || ed.Delete:
+			if lst.Delete && ed.Delete && !strings.Contains(lst.Text, "\n") {
+				switch {
+				case ed.Ed == lst.St: // backspace
+					lst.St = ed.St
+					lst.Text = ed.Text + lst.Text
+					return
+				case ed.St == lst.St: // forward delete
+					lst.Ed.Ch += ed.Ed.Ch - ed.St.Ch
+					lst.Text += ed.Text
+					return
+				}
+			}
+		case ed.St == lst.Ed && !strings.Contains(lst.Text, "\n"):
+			lst.Ed = ed.Ed
+			lst.Text += ed.Text
+			return
+		}
+	}
+	*ue = append(*ue, ed)
+	if max > 0 && len(*ue) > max {
+		*ue = (*ue)[len(*ue)-max:]
+	}
+}
+
+// UndoHash returns the hash of given file contents, which its undo history
+// is stored by
+func UndoHash(txt []byte) string {
+	h := sha256.Sum256(txt)
+	return hex.EncodeToString(h[:])
+}
+
+// SaveUndoHist saves given undo history in given directory, for the file
+// contents with given hash, and removes the oldest histories beyond
+// UndoHistMaxFiles
+func SaveUndoHist(dir, hash string, ue UndoEdits) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(ue)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, hash+".json"), b, 0600); err != nil {
+		return err
+	}
+	PruneUndoHist(dir, UndoHistMaxFiles)
+	return nil
+}
+
+// OpenUndoHist returns the undo history saved in given directory for the
+// file contents with given hash, if any
+func OpenUndoHist(dir, hash string) (UndoEdits, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, hash+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var ue UndoEdits
+	err = json.Unmarshal(b, &ue)
+	return ue, err
+}
+
+// PruneUndoHist removes the least recently saved undo histories in given
+// directory beyond max
+func PruneUndoHist(dir string, max int) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil || len(fis) <= max {
+		return
+	}
+	sort.Slice(fis, func(i, j int) bool {
+		return fis[i].ModTime().After(fis[j].ModTime())
+	})
+	for _, fi := range fis[max:] {
+		os.Remove(filepath.Join(dir, fi.Name()))
+	}
+}
+
+// UndoHistState is the undo history of an open file across sessions
+type UndoHistState struct {
+	Hist      UndoEdits `desc:"edits from previous sessions, which can still be undone"`
+	Hash      string    `desc:"hash of the contents of the buffer after Hist -- they can be undone when it has these contents"`
+	Session   UndoEdits `desc:"edits in this session, since the file was opened or last saved"`
+	Replaying bool      `desc:"true while an edit from Hist is being undone, so it is not recorded"`
+}
+
+// UndoHistDir returns the directory that undo histories are stored in
+func UndoHistDir() string {
+	return filepath.Join(oswin.TheApp.AppPrefsDir(), UndoHistDirName)
+}
+
+// UndoHistOpen loads the undo history of given buffer, just opened from its
+// file, saved by previous sessions for its contents, so the edits made in
+// them can still be undone (see UndoPrevSession) -- not if the history is
+// turned off for the project, with an UndoHistory of 0 in its Editor prefs
+func (ge *Gide) UndoHistOpen(tb *giv.TextBuf) {
+	if ge.undoHists == nil {
+		ge.undoHists = make(map[*giv.TextBuf]*UndoHistState)
+	}
+	if ge.Prefs.Editor.UndoHistory <= 0 || tb.Filename == "" {
+		delete(ge.undoHists, tb)
+		return
+	}
+	us := &UndoHistState{Hash: UndoHash(tb.LinesToBytesCopy())}
+	us.Hist, _ = OpenUndoHist(UndoHistDir(), us.Hash)
+	ge.undoHists[tb] = us
+}
+
+// UndoHistEdit records given edit to given buffer in its undo history
+func (ge *Gide) UndoHistEdit(tb *giv.TextBuf, tbe *giv.TextBufEdit) {
+	us := ge.undoHists[tb]
+	if us == nil || tbe == nil || us.Replaying {
+		return
+	}
+	us.Session.Add(UndoEditFrom(tbe), ge.Prefs.Editor.UndoHistory)
+}
+
+// UndoHistSaved stores the undo history of given buffer, just saved, for
+// its contents, so its edits can be undone after it is closed and opened
+// again
+func (ge *Gide) UndoHistSaved(tb *giv.TextBuf) error {
+	us := ge.undoHists[tb]
+	if us == nil {
+		return nil
+	}
+	for _, ed := range us.Session {
+		us.Hist.Add(ed, ge.Prefs.Editor.UndoHistory)
+	}
+	us.Session = nil
+	us.Hash = UndoHash(tb.LinesToBytesCopy())
+	if len(us.Hist) == 0 {
+		return nil
+	}
+	return SaveUndoHist(UndoHistDir(), us.Hash, us.Hist)
+}
+
+// UndoPrevSession undoes the last edit to the file in the active view that
+// was made in a previous session, before it was last opened -- the edits
+// made since it was opened must be undone first, with Undo
+func (ge *Gide) UndoPrevSession() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	tb := tv.Buf
+	us := ge.undoHists[tb]
+	if us == nil || len(us.Hist) == 0 {
+		ge.SetStatus("No more edits from previous sessions to undo")
+		return
+	}
+	if UndoHash(tb.LinesToBytesCopy()) != us.Hash {
+		ge.SetStatus("Undo the edits made since the file was opened first")
+		return
+	}
+	ed := us.Hist[len(us.Hist)-1]
+	us.Replaying = true
+	if ed.Delete {
+		tb.InsertText(ed.St, []byte(ed.Text), true, true)
+	} else {
+		tb.DeleteText(ed.St, ed.Ed, true, true)
+	}
+	us.Replaying = false
+	us.Hist = us.Hist[:len(us.Hist)-1]
+	us.Hash = UndoHash(tb.LinesToBytesCopy())
+	tv.SetCursorShow(ed.St)
+	ge.SetStatus("Undid an edit from a previous session")
+}
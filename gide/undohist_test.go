@@ -0,0 +1,90 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/goki/gi/giv"
+)
+
+func undoIns(ln, st, ed int, txt string) UndoEdit {
+	return UndoEdit{St: giv.TextPos{Ln: ln, Ch: st}, Ed: giv.TextPos{Ln: ln, Ch: ed}, Text: txt}
+}
+
+func undoDel(ln, st, ed int, txt string) UndoEdit {
+	ue := undoIns(ln, st, ed, txt)
+	ue.Delete = true
+	return ue
+}
+
+func TestUndoEditsAdd(t *testing.T) {
+	var ue UndoEdits
+	ue.Add(undoIns(0, 0, 1, "a"), 0)
+	ue.Add(undoIns(0, 1, 2, "b"), 0)
+	ue.Add(undoIns(0, 2, 3, "c"), 0)
+	want := UndoEdits{undoIns(0, 0, 3, "abc")}
+	if !reflect.DeepEqual(ue, want) {
+		t.Errorf("typing: %v, want %v", ue, want)
+	}
+	ue.Add(undoDel(0, 2, 3, "c"), 0)
+	ue.Add(undoDel(0, 1, 2, "b"), 0)
+	want = append(want, undoDel(0, 1, 3, "bc"))
+	if !reflect.DeepEqual(ue, want) {
+		t.Errorf("backspace: %v, want %v", ue, want)
+	}
+	ue.Add(undoDel(0, 1, 2, "x"), 0)
+	want[1] = undoDel(0, 1, 4, "bcx")
+	if !reflect.DeepEqual(ue, want) {
+		t.Errorf("forward delete: %v, want %v", ue, want)
+	}
+	nl := UndoEdit{St: giv.TextPos{Ln: 0, Ch: 1}, Ed: giv.TextPos{Ln: 1, Ch: 0}, Text: "\n"}
+	ue.Add(nl, 0)
+	ue.Add(undoIns(1, 0, 1, "d"), 0)
+	want = append(want, nl, undoIns(1, 0, 1, "d"))
+	if !reflect.DeepEqual(ue, want) {
+		t.Errorf("new line: %v, want %v", ue, want)
+	}
+	ue.Add(undoIns(5, 0, 1, "e"), 2)
+	want = UndoEdits{undoIns(1, 0, 1, "d"), undoIns(5, 0, 1, "e")}
+	if !reflect.DeepEqual(ue, want) {
+		t.Errorf("max: %v, want %v", ue, want)
+	}
+}
+
+func TestUndoHistStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-undo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	h := UndoHash([]byte("package main\n"))
+	if h == UndoHash([]byte("package gide\n")) || len(h) != 64 {
+		t.Errorf("UndoHash: %v", h)
+	}
+	ue := UndoEdits{undoIns(0, 8, 12, "main"), undoDel(1, 0, 2, "x\n")}
+	if err := SaveUndoHist(dir, h, ue); err != nil {
+		t.Fatal(err)
+	}
+	got, err := OpenUndoHist(dir, h)
+	if err != nil || !reflect.DeepEqual(got, ue) {
+		t.Errorf("OpenUndoHist = %v, %v, want %v", got, err, ue)
+	}
+	if _, err := OpenUndoHist(dir, UndoHash(nil)); err == nil {
+		t.Errorf("OpenUndoHist of unknown contents: no error")
+	}
+	for i := 0; i < 5; i++ {
+		ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.json", i)), nil, 0644)
+	}
+	PruneUndoHist(dir, 3)
+	if fis, _ := ioutil.ReadDir(dir); len(fis) != 3 {
+		t.Errorf("PruneUndoHist: %d left, want 3", len(fis))
+	}
+}
@@ -930,3 +930,74 @@ func (vv *VersCtrlValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc
 		}
 	})
 }
+
+//////////////////////////////////////////////////////////////////////////////////////
+//  SnippetsView
+
+// SnippetsView opens a view of a snippets table
+func SnippetsView(pt *Snippets) {
+	winm := "gide-snippets"
+	width := 800
+	height := 800
+	win := gi.NewWindow2D(winm, "Gide Snippets", width, height, true)
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+	mfr.Lay = gi.LayoutVert
+
+	title := mfr.AddNewChild(gi.KiT_Label, "title").(*gi.Label)
+	title.SetText("Available Snippets: Can duplicate an existing (using Ctxt Menu) as starting point for new one -- type the prefix and then Tab to expand a snippet")
+	title.SetProp("width", units.NewValue(30, units.Ch)) // need for wrap
+	title.SetStretchMaxWidth()
+	title.SetProp("white-space", gi.WhiteSpaceNormal) // wrap
+
+	tv := mfr.AddNewChild(giv.KiT_TableView, "tv").(*giv.TableView)
+	tv.Viewport = vp
+	tv.SetSlice(pt, nil)
+	tv.SetStretchMaxWidth()
+	tv.SetStretchMaxHeight()
+
+	AvailSnippetsChanged = false
+	tv.ViewSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		AvailSnippetsChanged = true
+	})
+
+	mmen := win.MainMenu
+	giv.MainMenuView(pt, win, mmen)
+
+	inClosePrompt := false
+	win.OSWin.SetCloseReqFunc(func(w oswin.Window) {
+		if !AvailSnippetsChanged || pt != &AvailSnippets { // only for main avail map..
+			win.Close()
+			return
+		}
+		if inClosePrompt {
+			return
+		}
+		inClosePrompt = true
+		gi.ChoiceDialog(vp, gi.DlgOpts{Title: "Save Snippets Before Closing?",
+			Prompt: "Do you want to save any changes to snippets file before closing, or Cancel the close and do a Save to a different file?"},
+			[]string{"Save and Close", "Discard and Close", "Cancel"},
+			win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				switch sig {
+				case 0:
+					pt.SavePrefs()
+					fmt.Printf("Preferences Saved to %v\n", PrefsSnippetsFileName)
+					win.Close()
+				case 1:
+					pt.OpenPrefs() // revert
+					win.Close()
+				case 2:
+					inClosePrompt = false
+					// default is to do nothing, i.e., cancel
+				}
+			})
+	})
+
+	win.MainMenuUpdated()
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+}
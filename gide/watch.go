@@ -0,0 +1,240 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki"
+)
+
+// WatchInterval is how often the files of the open buffers are checked for
+// changes made outside of gide
+var WatchInterval = 2 * time.Second
+
+// diskState is the version of the file of an open buffer that it was opened
+// or last saved as -- the common ancestor of the buffer and the file, for
+// merging changes made to the file on disk into the buffer
+type diskState struct {
+	Text  []byte    `desc:"text of the file, as in the buffer: UTF-8 with LF line endings"`
+	Mod   time.Time `desc:"modification time of the file"`
+	Size  int64     `desc:"size of the file"`
+	Asked time.Time `desc:"modification time of the file that was last asked about, so it is only asked about once"`
+}
+
+// diskText returns the text of the file of given buffer, as it would be in
+// the buffer: transcoded to UTF-8, with LF line endings
+func (ge *Gide) diskText(tb *giv.TextBuf) ([]byte, os.FileInfo, error) {
+	fpath := string(tb.Filename)
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if enc := ge.EncodingFor(tb); enc != EncodingUTF8 {
+		if raw, err = DecodeText(raw, enc); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ConvertLineEnds(raw, LineEndsLF), fi, nil
+}
+
+// WatchSync records given text of the file of given buffer, on disk now, as
+// the version it is based on -- after it is opened, saved or merged
+func (ge *Gide) WatchSync(tb *giv.TextBuf, txt []byte) {
+	if tb.Filename == "" {
+		return
+	}
+	fi, err := os.Stat(string(tb.Filename))
+	if err != nil {
+		return
+	}
+	ge.watchMu.Lock()
+	if ge.diskStates == nil {
+		ge.diskStates = make(map[*giv.TextBuf]*diskState)
+	}
+	ge.diskStates[tb] = &diskState{Text: txt, Mod: fi.ModTime(), Size: fi.Size()}
+	ge.watchMu.Unlock()
+	tb.Info.InitFile(string(tb.Filename)) // so it is not seen as changed on disk
+}
+
+// WatchOpened records the text of given buffer, just opened from its file,
+// as the version it is based on
+func (ge *Gide) WatchOpened(tb *giv.TextBuf) {
+	ge.WatchSync(tb, tb.LinesToBytesCopy())
+}
+
+// WatchSaved records the text of the file of given buffer, just saved, as
+// the version it is based on
+func (ge *Gide) WatchSaved(tb *giv.TextBuf) {
+	if txt, _, err := ge.diskText(tb); err == nil {
+		ge.WatchSync(tb, txt)
+	}
+}
+
+// diskBase returns the version of the file that given buffer is based on
+func (ge *Gide) diskBase(tb *giv.TextBuf) ([]byte, bool) {
+	ge.watchMu.Lock()
+	defer ge.watchMu.Unlock()
+	ds, ok := ge.diskStates[tb]
+	if !ok {
+		return nil, false
+	}
+	return ds.Text, true
+}
+
+// WatchReload reloads given buffer from its file
+func (ge *Gide) WatchReload(tb *giv.TextBuf) {
+	ge.ConfigTextBuf(tb)
+	tb.Revert()
+	ge.BufOpened(tb)
+	ge.SetStatus("Reloaded from disk: " + string(tb.Filename))
+}
+
+// WatchCheck checks the files of the open buffers for changes made outside
+// of gide: buffers without unsaved changes are reloaded, and for those with
+// them, the user is asked whether to merge the changes on disk into them,
+// reload them, or keep them -- buffers being saved in the background are
+// skipped.  It must be called on the GUI thread: the files are checked in
+// the background, and those that have changed are handled back on the GUI
+// thread, by watchChanged.
+func (ge *Gide) WatchCheck() {
+	var tbs []*giv.TextBuf
+	var fpaths []string
+	for _, ond := range ge.OpenNodes {
+		tb := ond.Buf
+		if tb == nil || tb.Filename == "" || ge.IsDiffSaving(tb) {
+			continue
+		}
+		tbs = append(tbs, tb)
+		fpaths = append(fpaths, string(tb.Filename))
+	}
+	if len(tbs) == 0 {
+		return
+	}
+	go func() {
+		for i, tb := range tbs {
+			if fi := ge.watchStat(tb, fpaths[i]); fi != nil {
+				tb := tb
+				ge.RunOnGui(func() { ge.watchChanged(tb, fi) })
+			}
+		}
+	}()
+}
+
+// watchStat returns the info of given file of given buffer if it has been
+// modified since the buffer was opened or last saved, and not yet asked
+// about -- nil otherwise
+func (ge *Gide) watchStat(tb *giv.TextBuf, fpath string) os.FileInfo {
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		return nil
+	}
+	ge.watchMu.Lock()
+	defer ge.watchMu.Unlock()
+	ds := ge.diskStates[tb]
+	if ds == nil || (fi.ModTime().Equal(ds.Mod) && fi.Size() == ds.Size) || fi.ModTime().Equal(ds.Asked) {
+		return nil
+	}
+	return fi
+}
+
+// watchChanged compares the file of given buffer, modified on disk as given
+// by fi, with the version the buffer is based on, and reloads the buffer or
+// asks what to do about the changes -- on the GUI thread
+func (ge *Gide) watchChanged(tb *giv.TextBuf, fi os.FileInfo) {
+	if ge.OpenNodes.ByBuf(tb) == nil || ge.IsDiffSaving(tb) { // closed or saving since
+		return
+	}
+	base, ok := ge.diskBase(tb)
+	if !ok {
+		return
+	}
+	disk, _, err := ge.diskText(tb)
+	if err != nil {
+		return
+	}
+	if bytes.Equal(disk, base) { // touched, not changed
+		ge.WatchSync(tb, base)
+		return
+	}
+	if !tb.IsChanged() {
+		ge.WatchReload(tb)
+		return
+	}
+	ge.watchMu.Lock()
+	ds := ge.diskStates[tb]
+	asked := ds == nil || fi.ModTime().Equal(ds.Asked)
+	if !asked {
+		ds.Asked = fi.ModTime()
+	}
+	ge.watchMu.Unlock()
+	if !asked {
+		ge.externalPrompt(tb, disk)
+	}
+}
+
+// externalPrompt asks what to do about the changes on disk to the file of
+// given buffer, which has unsaved changes
+func (ge *Gide) externalPrompt(tb *giv.TextBuf, disk []byte) {
+	fnm := filepath.Base(string(tb.Filename))
+	gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "File Changed on Disk",
+		Prompt: fmt.Sprintf("<b>%v</b> has been changed on disk, and has unsaved changes here.  Merge the changes on disk into it, shown side by side with your changes and the version they both started from in the Merge tab; reload it from disk, losing your changes; or keep it as it is, to save over the changes on disk?", fnm)},
+		[]string{"Merge", "Reload", "Keep Mine"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			switch sig {
+			case 0:
+				ge.MergeExternal(tb, disk)
+			case 1:
+				ge.WatchReload(tb)
+			case 2:
+				ge.WatchSync(tb, disk)
+			}
+		})
+}
+
+// StartWatch starts checking the files of the open buffers for changes made
+// outside of gide every WatchInterval
+func (ge *Gide) StartWatch() {
+	ge.watchStop = make(chan struct{})
+	go func(stop chan struct{}) {
+		tick := time.NewTicker(WatchInterval)
+		defer tick.Stop()
+		n := 0
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				n++
+				if ge.PowerLow() && n%PowerSlowdown != 0 {
+					continue
+				}
+				ge.RunOnGui(ge.WatchCheck)
+				if n%ProjIndexScanEvery == 0 {
+					ge.ProjIndexRescan()
+				}
+			}
+		}
+	}(ge.watchStop)
+}
+
+// StopWatch stops checking the files of the open buffers for changes
+func (ge *Gide) StopWatch() {
+	if ge.watchStop != nil {
+		close(ge.watchStop)
+		ge.watchStop = nil
+	}
+}
@@ -0,0 +1,402 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// Welcome is the startup project chooser that is shown when Gide is launched
+// without any project or path to open: it lists the recent projects (with
+// pinned ones on top), and has actions for opening, creating, or cloning
+// projects.  Once a project is opened, the Welcome window is closed.
+type Welcome struct {
+	gi.Frame
+}
+
+var KiT_Welcome = kit.Types.AddType(&Welcome{}, nil)
+
+func init() {
+	kit.Types.SetProps(KiT_Welcome, WelcomeProps)
+}
+
+// IsPinned returns true if given path is pinned
+func IsPinned(path string) bool {
+	for _, pp := range PinnedPaths {
+		if pp == path {
+			return true
+		}
+	}
+	return false
+}
+
+// TogglePinPath pins given path if not already pinned, or otherwise unpins
+// it -- saves the pinned paths and returns true if now pinned
+func TogglePinPath(path string) bool {
+	for i, pp := range PinnedPaths {
+		if pp == path {
+			PinnedPaths = append(PinnedPaths[:i], PinnedPaths[i+1:]...)
+			SavePinnedPaths()
+			return false
+		}
+	}
+	PinnedPaths = append(PinnedPaths, path)
+	SavePinnedPaths()
+	return true
+}
+
+// WelcomePaths returns the list of paths to show in the Welcome window --
+// the pinned paths first, followed by the other saved paths in recency order,
+// filtered by the current project group filter
+func WelcomePaths() []string {
+	pl := make([]string, 0, len(PinnedPaths)+len(SavedPaths))
+	pl = append(pl, PinnedPaths...)
+	for _, sp := range SavedPaths {
+		if !IsPinned(sp) {
+			pl = append(pl, sp)
+		}
+	}
+	return Prefs.ProjGroups.FilterPaths(pl)
+}
+
+// Done closes the Welcome window -- called after a project has been opened
+func (wl *Welcome) Done() {
+	win := wl.ParentWindow()
+	if win != nil {
+		win.Close()
+	}
+}
+
+// OpenRecent opens a recently-used project or path
+func (wl *Welcome) OpenRecent(filename gi.FileName) {
+	ext := strings.ToLower(filepath.Ext(string(filename)))
+	if ext == ".gide" {
+		wl.OpenProj(filename)
+	} else {
+		wl.OpenPath(filename)
+	}
+}
+
+// OpenProj opens given .gide project file in a new Gide window
+func (wl *Welcome) OpenProj(filename gi.FileName) {
+	_, ge := OpenGideProj(string(filename))
+	if ge != nil {
+		wl.Done()
+	}
+}
+
+// OpenPath opens a new Gide project for given file or directory path
+func (wl *Welcome) OpenPath(path gi.FileName) {
+	if _, _, _, ok := ProjPathParse(string(path)); !ok {
+		gi.PromptDialog(wl.Viewport, gi.DlgOpts{Title: "Couldn't Open Path", Prompt: fmt.Sprintf("Could not open path: %v", path)}, true, false, nil, nil)
+		return
+	}
+	NewGideProjPath(string(path))
+	wl.Done()
+}
+
+// NewProj creates a new project at given path, making a new folder in that
+// path, and opens it in a new Gide window
+func (wl *Welcome) NewProj(path gi.FileName, folder string, mainLang LangName, versCtrl VersCtrlName) {
+	np := filepath.Join(string(path), folder)
+	err := os.MkdirAll(np, 0775)
+	if err != nil {
+		gi.PromptDialog(wl.Viewport, gi.DlgOpts{Title: "Couldn't Make Folder", Prompt: fmt.Sprintf("Could not make folder for project at: %v, err: %v", np, err)}, true, false, nil, nil)
+		return
+	}
+	_, ge := NewGideProjPath(np)
+	if mainLang != "" {
+		ge.Prefs.MainLang = mainLang
+	}
+	if versCtrl != "" {
+		ge.Prefs.VersCtrl = versCtrl
+	}
+	wl.Done()
+}
+
+// NewFile creates a new file at given path (if it does not already exist),
+// and opens it in a lightweight single-file Gide window
+func (wl *Welcome) NewFile(filename gi.FileName) {
+	fp, err := os.OpenFile(string(filename), os.O_RDONLY|os.O_CREATE, 0664)
+	if err != nil {
+		gi.PromptDialog(wl.Viewport, gi.DlgOpts{Title: "Couldn't Make File", Prompt: fmt.Sprintf("Could not make new file at: %v, err: %v", filename, err)}, true, false, nil, nil)
+		return
+	}
+	fp.Close()
+	fnm, _ := filepath.Abs(string(filename))
+	NewGideFile(fnm)
+	wl.Done()
+}
+
+// CloneRepo clones the git repository at given url into given parent folder,
+// optionally with given depth (> 0 = shallow) and branch, and opens it as a
+// new project when done
+func (wl *Welcome) CloneRepo(repoURL string, parentFolder gi.FileName, depth int, branch string) {
+	if repoURL == "" {
+		return
+	}
+	cp := &CloneParams{URL: repoURL, Parent: string(parentFolder), Depth: depth, Branch: branch}
+	wl.SetStatus(fmt.Sprintf("Cloning %v into %v...", repoURL, cp.Path()))
+	cp.CloneGui(wl.Viewport, wl.This(), wl.RunOnGui, func(line string) {
+		wl.SetStatus(line)
+	}, func(path string) {
+		NewGideProjPath(path)
+		wl.Done()
+	})
+}
+
+// FilterGroup shows only the recent projects in given group (ProjGroupAll
+// shows all), saving the preferences
+func (wl *Welcome) FilterGroup(group string) {
+	Prefs.ProjGroups.SetFilter(group)
+	Prefs.Save()
+	wl.ConfigRecents()
+}
+
+// RunOnGui runs given function on the goroutine of the events of the
+// window, as Gide.RunOnGui does
+func (wl *Welcome) RunOnGui(fun func()) {
+	runOnWinGui(wl.ParentWindow(), fun)
+}
+
+func (wl *Welcome) ConnectEvents2D() {
+	if wl.HasAnyScroll() {
+		wl.LayoutScrollEvents()
+	}
+	wl.ConnectEvent(oswin.CustomEventType, gi.RegPri, runGuiFunc)
+}
+
+// SetStatus sets the status line at the bottom of the window
+func (wl *Welcome) SetStatus(msg string) {
+	sli, ok := wl.ChildByName("status", 3)
+	if !ok {
+		return
+	}
+	sli.(*gi.Label).SetText(msg)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GUI config
+
+// StdConfig returns a TypeAndNameList for configuring the Welcome frame
+func (wl *Welcome) StdConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Label, "title")
+	config.Add(gi.KiT_ToolBar, "toolbar")
+	config.Add(gi.KiT_Frame, "recents")
+	config.Add(gi.KiT_Label, "status")
+	return config
+}
+
+// Config configures the Welcome frame
+func (wl *Welcome) Config() {
+	wl.Lay = gi.LayoutVert
+	wl.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := wl.StdConfig()
+	mods, updt := wl.ConfigChildren(config, false)
+	if !mods {
+		updt = wl.UpdateStart()
+	}
+	title := wl.KnownChild(0).(*gi.Label)
+	title.SetText(`<large><b>Welcome to Gide</b></large><br>Open a recent project (click the star to pin it to the top, or the circle to tag it with a group), or use the toolbar to open, create, or clone one.`)
+	title.SetProp("white-space", gi.WhiteSpaceNormal)
+	title.SetStretchMaxWidth()
+
+	tb := wl.KnownChild(1).(*gi.ToolBar)
+	if !tb.HasChildren() {
+		tb.SetStretchMaxWidth()
+		giv.ToolBarView(wl, wl.Viewport, tb)
+	}
+
+	st := wl.KnownChild(3).(*gi.Label)
+	st.SetStretchMaxWidth()
+	wl.ConfigRecents()
+	wl.UpdateEnd(updt)
+}
+
+// ConfigRecents configures the list of recent projects
+func (wl *Welcome) ConfigRecents() {
+	rf := wl.KnownChild(2).(*gi.Frame)
+	updt := rf.UpdateStart()
+	rf.Lay = gi.LayoutVert
+	rf.SetStretchMaxWidth()
+	rf.SetStretchMaxHeight()
+	rf.DeleteChildren(true)
+	for i, rp := range WelcomePaths() {
+		path := rp
+		rl := rf.AddNewChild(gi.KiT_Layout, fmt.Sprintf("recent-%v", i)).(*gi.Layout)
+		rl.Lay = gi.LayoutHoriz
+		rl.SetStretchMaxWidth()
+		pin := rl.AddNewChild(gi.KiT_Action, "pin").(*gi.Action)
+		if IsPinned(path) {
+			pin.SetText("★")
+			pin.Tooltip = "unpin this project"
+		} else {
+			pin.SetText("☆")
+			pin.Tooltip = "pin this project to the top of the list"
+		}
+		pin.ActionSig.Connect(wl.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			wll, _ := recv.Embed(KiT_Welcome).(*Welcome)
+			TogglePinPath(path)
+			wll.ConfigRecents()
+		})
+		grp := rl.AddNewChild(gi.KiT_Action, "group").(*gi.Action)
+		if gp := Prefs.ProjGroups.PathGroup(path); gp != nil {
+			grp.SetText("●")
+			grp.SetProp("color", gp.Color)
+			grp.Tooltip = "group: " + gp.Name + " -- click to change"
+		} else {
+			grp.SetText("○")
+			grp.Tooltip = "tag this project with a group"
+		}
+		grp.ActionSig.Connect(wl.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			wll, _ := recv.Embed(KiT_Welcome).(*Welcome)
+			ProjGroupChooser(path, send, func() {
+				wll.ConfigRecents()
+			})
+		})
+		open := rl.AddNewChild(gi.KiT_Action, "open").(*gi.Action)
+		open.SetText(Prefs.ProjGroups.Label(path))
+		open.Tooltip = "open this project"
+		open.SetStretchMaxWidth()
+		open.SetProp("text-align", gi.AlignLeft)
+		open.ActionSig.Connect(wl.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			wll, _ := recv.Embed(KiT_Welcome).(*Welcome)
+			wll.OpenRecent(gi.FileName(path))
+		})
+	}
+	rf.UpdateEnd(updt)
+}
+
+var WelcomeProps = ki.Props{
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+	"#title": ki.Props{
+		"max-width":        -1,
+		"horizontal-align": gi.AlignCenter,
+		"vertical-align":   gi.AlignTop,
+	},
+	"#recents": ki.Props{
+		"padding": units.NewValue(1, units.Em),
+	},
+	"ToolBar": ki.PropSlice{
+		{"FilterGroup", ki.Props{
+			"label":        "Group",
+			"icon":         "search",
+			"desc":         "only show recent projects in the given group",
+			"submenu-func": giv.SubMenuFunc(GideProjGroupFilters),
+			"Args": ki.PropSlice{
+				{"Group", ki.Props{}},
+			},
+		}},
+		{"OpenPath", ki.Props{
+			"label": "Open Path...",
+			"icon":  "file-open",
+			"desc":  "open a gide project for a file or directory (projects are just directories with relevant files)",
+			"Args": ki.PropSlice{
+				{"Path", ki.Props{}},
+			},
+		}},
+		{"OpenProj", ki.Props{
+			"label": "Open Project...",
+			"icon":  "file-open",
+			"desc":  "open a gide project .gide file",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".gide",
+				}},
+			},
+		}},
+		{"NewProj", ki.Props{
+			"label": "New Project...",
+			"icon":  "new",
+			"desc":  "Create a new project -- select a path for the parent folder, and a folder name for the new project -- all Gide projects are basically folders with files.  You can also specify the main language and version control system for the project.",
+			"Args": ki.PropSlice{
+				{"Parent Folder", ki.Props{
+					"dirs-only": true, // todo: support
+				}},
+				{"Folder", ki.Props{
+					"width": 60,
+				}},
+				{"Main Lang", ki.Props{}},
+				{"Version Ctrl", ki.Props{}},
+			},
+		}},
+		{"NewFile", ki.Props{
+			"label": "New File...",
+			"icon":  "new",
+			"desc":  "Create a new file and open it by itself in a lightweight window, without a project",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"width": 60,
+				}},
+			},
+		}},
+		{"CloneRepo", ki.Props{
+			"label": "Clone...",
+			"icon":  "download",
+			"desc":  "clone a git repository from given URL into given parent folder, and open it as a new project",
+			"Args": ki.PropSlice{
+				{"Repository URL", ki.Props{
+					"width": 60,
+				}},
+				{"Parent Folder", ki.Props{
+					"dirs-only": true, // todo: support
+				}},
+				{"Depth", ki.Props{
+					"desc": "if > 0, make a shallow clone with history truncated to this many commits",
+				}},
+				{"Branch", ki.Props{
+					"desc": "branch (or tag) to check out -- leave empty for the default branch",
+				}},
+			},
+		}},
+	},
+}
+
+// WelcomeWindow opens the Welcome startup project chooser window
+func WelcomeWindow() (*gi.Window, *Welcome) {
+	winm := "gide-welcome"
+	if win, found := gi.AllWindows.FindName(winm); found {
+		mfr := win.SetMainFrame()
+		wl := mfr.KnownChild(0).Embed(KiT_Welcome).(*Welcome)
+		win.OSWin.Raise()
+		return win, wl
+	}
+
+	width := 800
+	height := 600
+	win := gi.NewWindow2D(winm, "Welcome to Gide", width, height, true) // true = pixel sizes
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+	wl := mfr.AddNewChild(KiT_Welcome, "welcome").(*Welcome)
+	wl.Viewport = vp
+	wl.Config()
+
+	win.OSWin.SetCloseCleanFunc(func(w oswin.Window) {
+		if gi.MainWindows.Len() <= 1 {
+			go oswin.TheApp.Quit() // nothing else open, so quit
+		}
+	})
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	return win, wl
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/kit"
+)
+
+// WsShow is what is shown of the whitespace of a file: indent guides, which
+// are faint vertical lines at each indent level of the indentation, and
+// whitespace, where spaces are shown as dots, tabs underlined and trailing
+// whitespace highlighted as an error -- for spotting indentation bugs, e.g.,
+// in Python and YAML
+type WsShow int
+
+const (
+	// WsShowDefault uses the IndentGuides and ShowWhitespace editor
+	// preferences -- for a language, it uses those of the editor
+	WsShowDefault WsShow = iota
+
+	// WsShowNone shows neither indent guides nor whitespace
+	WsShowNone
+
+	// WsShowGuides shows indent guides
+	WsShowGuides
+
+	// WsShowWhitespace shows spaces, tabs and trailing whitespace
+	WsShowWhitespace
+
+	// WsShowAll shows indent guides and whitespace
+	WsShowAll
+
+	WsShowN
+)
+
+//go:generate stringer -type=WsShow
+
+var KiT_WsShow = kit.Enums.AddEnumAltLower(WsShowN, false, nil, "WsShow")
+
+func (ev WsShow) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *WsShow) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// Guides returns true if indent guides are shown
+func (ev WsShow) Guides() bool {
+	return ev == WsShowGuides || ev == WsShowAll
+}
+
+// Whitespace returns true if spaces, tabs and trailing whitespace are shown
+func (ev WsShow) Whitespace() bool {
+	return ev == WsShowWhitespace || ev == WsShowAll
+}
+
+// Shows returns true if anything is shown
+func (ev WsShow) Shows() bool {
+	return ev.Guides() || ev.Whitespace()
+}
+
+// WsShow returns what is shown of the whitespace of files according to the
+// IndentGuides and ShowWhitespace preferences
+func (pf *EditorPrefs) WsShow() WsShow {
+	switch {
+	case pf.IndentGuides && pf.ShowWhitespace:
+		return WsShowAll
+	case pf.IndentGuides:
+		return WsShowGuides
+	case pf.ShowWhitespace:
+		return WsShowWhitespace
+	}
+	return WsShowNone
+}
+
+var (
+	// WsGuideGlyph is the glyph that indent guides are drawn with, in place
+	// of the space at each indent level of the indentation
+	WsGuideGlyph = '│'
+
+	// WsSpaceGlyph is the glyph that spaces are shown as
+	WsSpaceGlyph = '·'
+
+	// WsClass is the css class of the indent guide and space glyphs, so they
+	// are faint in the current highlighting style
+	WsClass = "c"
+
+	// WsTrailClass is the css class of trailing whitespace
+	WsTrailClass = "err"
+)
+
+// WsDecorate returns given line of text with the whitespace shown in given
+// way: the spaces at each indent level of the indentation, of given tab
+// size, replaced by indent guides, and the others by dots, with the spans
+// of those glyphs and of trailing whitespace, to give them their classes,
+// and the spans of the tabs, which keep their width and are underlined --
+// the line keeps its number of runes, so positions in it are unchanged
+func WsDecorate(line string, ws WsShow, tabSize int) (disp string, over, under []HiSpan) {
+	if !ws.Shows() {
+		return line, nil, nil
+	}
+	if tabSize <= 0 {
+		tabSize = 4
+	}
+	rs := []rune(line)
+	ind, icol := 0, 0 // indentation, in runes and columns
+	for ; ind < len(rs) && (rs[ind] == ' ' || rs[ind] == '\t'); ind++ {
+		if rs[ind] == '\t' {
+			icol = (icol/tabSize + 1) * tabSize
+		} else {
+			icol++
+		}
+	}
+	trail := len(rs)
+	for trail > ind && (rs[trail-1] == ' ' || rs[trail-1] == '\t') {
+		trail--
+	}
+	if ind == len(rs) { // blank
+		trail = 0
+	}
+	col := 0
+	for i, r := range rs {
+		if r != ' ' && r != '\t' {
+			col++
+			continue
+		}
+		cls := ""
+		switch {
+		case ws.Whitespace() && i >= trail:
+			cls = WsTrailClass
+		case r == ' ' && ws.Guides() && i < ind && col%tabSize == 0 && col+tabSize <= icol:
+			rs[i], cls = WsGuideGlyph, WsClass
+		case r == ' ' && ws.Whitespace():
+			rs[i], cls = WsSpaceGlyph, WsClass
+		}
+		if cls == WsTrailClass && r == ' ' {
+			rs[i] = WsSpaceGlyph
+		}
+		if cls != "" {
+			over = append(over, HiSpan{i, i + 1, cls})
+		}
+		if r == '\t' {
+			if ws.Whitespace() {
+				under = append(under, HiSpan{i, i + 1, ""})
+			}
+			col = (col/tabSize + 1) * tabSize
+		} else {
+			col++
+		}
+	}
+	return string(rs), over, under
+}
+
+// wsShowConfig returns what is shown of the whitespace of given buffer by
+// the setting for its language, or else the editor preferences
+func (ge *Gide) wsShowConfig(tb *giv.TextBuf) WsShow {
+	if ls := LangsForFilename(string(tb.Filename)); len(ls) > 0 && ls[0].Whitespace != WsShowDefault {
+		return ls[0].Whitespace
+	}
+	return ge.Prefs.Editor.WsShow()
+}
+
+// WsShowFor returns what is shown of the whitespace of given buffer: as
+// toggled by ToggleWhitespace, or else as set for its language (see
+// Whitespace in Edit Langs), or else the editor preferences
+func (ge *Gide) WsShowFor(tb *giv.TextBuf) WsShow {
+	if ge.wsShow != WsShowDefault {
+		return ge.wsShow
+	}
+	return ge.wsShowConfig(tb)
+}
+
+// ToggleWhitespace hides the indent guides and whitespace if they are shown
+// in the active view, or shows them if not, in all open files, for this
+// session -- showing what is set for the language of the file, or the
+// editor preferences, if that is anything, and otherwise both
+func (ge *Gide) ToggleWhitespace() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	switch {
+	case ge.WsShowFor(tv.Buf).Shows():
+		ge.wsShow = WsShowNone
+		ge.SetStatus("Indent guides and whitespace hidden")
+	case ge.wsShowConfig(tv.Buf).Shows():
+		ge.wsShow = WsShowDefault
+		ge.SetStatus("Indent guides and whitespace shown as set in the preferences")
+	default:
+		ge.wsShow = WsShowAll
+		ge.SetStatus("Indent guides and whitespace shown")
+	}
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil {
+			ge.HiMarkupBuf(ond.Buf)
+			ond.Buf.RefreshViews()
+		}
+	}
+}
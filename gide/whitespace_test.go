@@ -0,0 +1,44 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWsDecorate(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		ws    WsShow
+		disp  string
+		over  []HiSpan
+		under []HiSpan
+	}{
+		{"none", "    x = 1  ", WsShowNone, "    x = 1  ", nil, nil},
+		{"guides", "        x = 1", WsShowGuides, "│   │   x = 1", []HiSpan{{0, 1, "c"}, {4, 5, "c"}}, nil},
+		{"partial level", "      x", WsShowGuides, "│     x", []HiSpan{{0, 1, "c"}}, nil},
+		{"whitespace", "  x y\t", WsShowWhitespace, "··x·y\t", []HiSpan{{0, 1, "c"}, {1, 2, "c"}, {3, 4, "c"}, {5, 6, "err"}}, []HiSpan{{5, 6, ""}}},
+		{"all", "    a b ", WsShowAll, "│···a·b·", []HiSpan{{0, 1, "c"}, {1, 2, "c"}, {2, 3, "c"}, {3, 4, "c"}, {5, 6, "c"}, {7, 8, "err"}}, nil},
+		{"tab indent", "\t    x", WsShowGuides, "\t│   x", []HiSpan{{1, 2, "c"}}, nil},
+		{"blank", "   ", WsShowWhitespace, "···", []HiSpan{{0, 1, "err"}, {1, 2, "err"}, {2, 3, "err"}}, nil},
+	}
+	for _, tt := range tests {
+		disp, over, under := WsDecorate(tt.line, tt.ws, 4)
+		if disp != tt.disp {
+			t.Errorf("%v: got %q, want %q", tt.name, disp, tt.disp)
+		}
+		if len([]rune(disp)) != len([]rune(tt.line)) {
+			t.Errorf("%v: %d runes, not %d", tt.name, len([]rune(disp)), len([]rune(tt.line)))
+		}
+		if !reflect.DeepEqual(over, tt.over) {
+			t.Errorf("%v: spans %v, want %v", tt.name, over, tt.over)
+		}
+		if !reflect.DeepEqual(under, tt.under) {
+			t.Errorf("%v: underlined %v, want %v", tt.name, under, tt.under)
+		}
+	}
+}